@@ -0,0 +1,60 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package linalg
+
+import "sync/atomic"
+
+// Allocator provides scratch memory for temporaries that blas and lapack
+// packages need internally: LAPACK workspaces, pivot arrays and copies of
+// arguments that must not be overwritten. Contents of returned buffers are
+// undefined. Every buffer is handed back with Free exactly once when the
+// operation that requested it returns, so an allocator may reuse memory.
+// Implementations must be safe for concurrent use.
+type Allocator interface {
+	Float64s(n int) []float64
+	Complex128s(n int) []complex128
+	Int32s(n int) []int32
+	// Return buffer obtained from Float64s, Complex128s or Int32s.
+	Free(buf interface{})
+}
+
+// Default allocator, plain make() with garbage collected buffers.
+type heapAllocator struct{}
+
+func (heapAllocator) Float64s(n int) []float64       { return make([]float64, n) }
+func (heapAllocator) Complex128s(n int) []complex128 { return make([]complex128, n) }
+func (heapAllocator) Int32s(n int) []int32           { return make([]int32, n) }
+func (heapAllocator) Free(buf interface{})           {}
+
+type allocatorBox struct {
+	a Allocator
+}
+
+var allocator atomic.Value
+
+func init() {
+	allocator.Store(allocatorBox{heapAllocator{}})
+}
+
+// Set allocator for internal temporaries and return the previous one.
+// Nil restores the default Go heap allocator. Operations already running
+// keep using the allocator they started with.
+func SetAllocator(a Allocator) Allocator {
+	if a == nil {
+		a = heapAllocator{}
+	}
+	return allocator.Swap(allocatorBox{a}).(allocatorBox).a
+}
+
+// Return current allocator for internal temporaries.
+func GetAllocator() Allocator {
+	return allocator.Load().(allocatorBox).a
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,140 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/arrowadapt package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+// Converters between column-major matrices of this project and Apache Arrow
+// Float64 arrays and record batches.
+//
+// Each column of a column-major matrix is a contiguous run of doubles, which
+// is exactly the values buffer of an Arrow Float64 array. Column and Record
+// therefore wrap the matrix storage without copying; the arrays share
+// storage with the matrix and must not outlive changes the caller does not
+// intend to publish. In the other direction FromRecord hands the values of
+// adjacent, null free columns to matrix.FloatNew, which uses them as the
+// matrix storage, and copies only when the columns are scattered in memory
+// or contain nulls, which become NaN.
+//
+// Arrays allocated by Arrow from non-Go memory (eg. the cgo allocator) must
+// be kept retained for as long as a matrix created from them is in use.
+package arrowadapt
+
+import (
+	"fmt"
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Return Float64 array over the elements of x; x is not copied.
+func Float64Array(x []float64) *array.Float64 {
+	buffers := []*memory.Buffer{nil, memory.NewBufferBytes(arrow.Float64Traits.CastToBytes(x))}
+	data := array.NewData(arrow.PrimitiveTypes.Float64, len(x), buffers, nil, 0, 0)
+	defer data.Release()
+	return array.NewFloat64Data(data)
+}
+
+// Return Float64 array view of column j of A. The array is a slice at offset
+// j*ld of a buffer over all of the storage of A, so that FromRecord finds
+// the columns of a contiguous matrix adjacent and does not copy them.
+func Column(A *matrix.FloatMatrix, j int) *array.Float64 {
+	if A.Rows() == 0 {
+		return Float64Array(nil)
+	}
+	buffers := []*memory.Buffer{nil, memory.NewBufferBytes(arrow.Float64Traits.CastToBytes(A.FloatArray()))}
+	data := array.NewData(arrow.PrimitiveTypes.Float64, A.Rows(), buffers, nil, 0, j*A.LeadingIndex())
+	defer data.Release()
+	return array.NewFloat64Data(data)
+}
+
+// Return record batch with the columns of A as non-nullable Float64 fields.
+// Field names are taken from names; missing names default to c0, c1, ...
+// The columns share storage with A.
+func Record(A *matrix.FloatMatrix, names []string) arrow.Record {
+	cols := make([]arrow.Array, A.Cols())
+	fields := make([]arrow.Field, A.Cols())
+	for j := range cols {
+		name := fmt.Sprintf("c%d", j)
+		if j < len(names) && names[j] != "" {
+			name = names[j]
+		}
+		fields[j] = arrow.Field{Name: name, Type: arrow.PrimitiveTypes.Float64}
+		cols[j] = Column(A, j)
+	}
+	return array.NewRecord(arrow.NewSchema(fields, nil), cols, int64(A.Rows()))
+}
+
+// Return column vector of the values of a. Null values become NaN. The
+// vector is created with matrix.FloatNew on the values of a if a has no
+// nulls.
+func FromFloat64(a *array.Float64) *matrix.FloatMatrix {
+	if a.NullN() == 0 {
+		return matrix.FloatNew(a.Len(), 1, a.Float64Values())
+	}
+	return matrix.FloatNew(a.Len(), 1, values(a, make([]float64, a.Len())))
+}
+
+// Return matrix with the columns of rec, which must all be of type Float64,
+// and the column names.
+func FromRecord(rec arrow.Record) (*matrix.FloatMatrix, []string, error) {
+	rows, cols := int(rec.NumRows()), int(rec.NumCols())
+	arrays := make([]*array.Float64, cols)
+	names := make([]string, cols)
+	for j := range arrays {
+		a, ok := rec.Column(j).(*array.Float64)
+		if !ok || a.DataType().ID() != arrow.FLOAT64 {
+			return nil, nil, linalg.NewError(linalg.ErrType,
+				fmt.Sprintf("FromRecord: column %q not float64", rec.ColumnName(j)))
+		}
+		arrays[j] = a
+		names[j] = rec.ColumnName(j)
+	}
+	if cols == 0 || rows == 0 {
+		return matrix.FloatZeros(rows, cols), names, nil
+	}
+	if data := adjacent(arrays, rows); data != nil {
+		return matrix.FloatNew(rows, cols, data), names, nil
+	}
+	data := make([]float64, rows*cols)
+	for j, a := range arrays {
+		values(a, data[j*rows:(j+1)*rows])
+	}
+	return matrix.FloatNew(rows, cols, data), names, nil
+}
+
+// Copy values of a to v with nulls as NaN and return v.
+func values(a *array.Float64, v []float64) []float64 {
+	copy(v, a.Float64Values())
+	if a.NullN() > 0 {
+		for i := range v {
+			if a.IsNull(i) {
+				v[i] = math.NaN()
+			}
+		}
+	}
+	return v
+}
+
+// Return column-major array spanning the columns if they are null free and
+// follow each other in memory, otherwise nil.
+func adjacent(arrays []*array.Float64, rows int) []float64 {
+	first := arrays[0].Float64Values()
+	if cap(first) < rows*len(arrays) {
+		return nil
+	}
+	data := first[:rows*len(arrays)]
+	for j, a := range arrays {
+		if a.NullN() > 0 || &a.Float64Values()[0] != &data[j*rows] {
+			return nil
+		}
+	}
+	return data
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,52 @@
+package arrowadapt
+
+import (
+	"github.com/apache/arrow/go/v14/arrow"
+	"github.com/apache/arrow/go/v14/arrow/array"
+	"github.com/apache/arrow/go/v14/arrow/memory"
+	"github.com/nvcook42/matrix"
+	"math"
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	A := matrix.FloatNew(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	rec := Record(A, []string{"x"})
+	if rec.NumRows() != 2 || rec.NumCols() != 3 || rec.ColumnName(0) != "x" || rec.ColumnName(2) != "c2" {
+		t.Fatalf("record shape or names wrong\n")
+	}
+	// columns are views of A
+	A.SetAt(1, 2, 10)
+	if v := rec.Column(2).(*array.Float64).Value(1); v != 10 {
+		t.Logf("column not shared: %v\n", v)
+		t.Fail()
+	}
+	B, names, err := FromRecord(rec)
+	if err != nil || !B.Equal(A) || names[1] != "c1" {
+		t.Logf("round trip: %v, %v, %v\n", B, names, err)
+		t.Fail()
+	}
+	// writes through the Arrow buffer are seen by A and by B, which
+	// FromRecord made from the adjacent columns without copying
+	rec.Column(1).(*array.Float64).Float64Values()[0] = 7
+	if A.GetAt(0, 1) != 7 || B.GetAt(0, 1) != 7 {
+		t.Logf("write through Arrow buffer not shared:\n%v\n%v\n", A, B)
+		t.Fail()
+	}
+}
+
+func TestNulls(t *testing.T) {
+	values := arrow.Float64Traits.CastToBytes([]float64{1, 2, 3})
+	// element 1 is null
+	valid := memory.NewBufferBytes([]byte{0x5})
+	data := array.NewData(arrow.PrimitiveTypes.Float64, 3, []*memory.Buffer{valid, memory.NewBufferBytes(values)}, nil, 1, 0)
+	x := FromFloat64(array.NewFloat64Data(data))
+	if x.GetAt(0, 0) != 1 || !math.IsNaN(x.GetAt(1, 0)) || x.GetAt(2, 0) != 3 {
+		t.Logf("x: %v\n", x)
+		t.Fail()
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
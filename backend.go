@@ -0,0 +1,94 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package linalg
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Optional backend extensions reported in Backend.Extensions.
+const (
+	ExtBatched = "batched" // batched GEMM entry points
+	ExtGemm3m  = "gemm3m"  // zgemm3m complex GEMM
+	ExtGemmt   = "gemmt"   // dgemmt triangular GEMM
+	ExtILP64   = "ILP64"   // 64-bit integer interface
+	ExtInt8    = "int8"    // cblas_gemm_s8u8s32 integer GEMM
+)
+
+// Description of the native BLAS/LAPACK library the program is linked with.
+type Backend struct {
+	// Library name, eg. "OpenBLAS", "MKL", "BLIS", "reference" or "none".
+	Name string
+	// Library version if it can be queried, empty otherwise.
+	Version string
+	// Threading model: "sequential", "pthreads", "openmp" or "unknown".
+	Threading string
+	// Number of threads the library uses; 0 if not known.
+	Threads int
+	// Available extensions, see ExtBatched, ExtGemm3m, ExtGemmt, ExtILP64
+	// and ExtInt8.
+	Extensions []string
+}
+
+// Test if backend provides extension ext.
+func (b Backend) Has(ext string) bool {
+	for _, e := range b.Extensions {
+		if strings.EqualFold(e, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b Backend) String() string {
+	s := b.Name
+	if b.Version != "" {
+		s += " " + b.Version
+	}
+	s += fmt.Sprintf(" (%s", b.Threading)
+	if b.Threads > 0 {
+		s += fmt.Sprintf(", %d threads", b.Threads)
+	}
+	s += ")"
+	if len(b.Extensions) > 0 {
+		s += " [" + strings.Join(b.Extensions, " ") + "]"
+	}
+	return s
+}
+
+var (
+	backendOnce  sync.Once
+	backendProbe func() Backend
+	backend      Backend
+)
+
+// Register function that inspects the linked native library. Called by
+// the blas package at initialization; applications do not need to call it.
+func RegisterBackend(probe func() Backend) {
+	backendProbe = probe
+}
+
+// Return description of the active BLAS/LAPACK backend. The library is
+// probed once on first call; subsequent calls return the cached result and
+// are safe for concurrent use. If no cgo package of linalg is linked into
+// the program Name is "none".
+func BackendInfo() Backend {
+	backendOnce.Do(func() {
+		if backendProbe == nil {
+			backend = Backend{Name: "none", Threading: "unknown"}
+			return
+		}
+		backend = backendProbe()
+	})
+	return backend
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
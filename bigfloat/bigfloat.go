@@ -0,0 +1,196 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/bigfloat package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+// Multiple-precision matrix arithmetic with math/big.
+//
+// Matrix elements are big.Float values with a common precision in bits,
+// rounded to nearest even. The package is meant for the few operations
+// that need more than double precision, such as residuals in iterative
+// refinement (see lapack.RefineBig), not for bulk computation.
+package bigfloat
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math/big"
+	"strings"
+)
+
+// Column-major multiple-precision matrix.
+type Matrix struct {
+	rows, cols int
+	prec       uint
+	elements   []big.Float
+}
+
+// Create a new rows*cols zero matrix with prec bits of mantissa.
+func Zeros(rows, cols int, prec uint) *Matrix {
+	A := &Matrix{rows, cols, prec, make([]big.Float, rows*cols)}
+	for k := range A.elements {
+		A.elements[k].SetPrec(prec)
+	}
+	return A
+}
+
+// Convert float matrix to matrix with prec bits of mantissa. Conversion is
+// exact for prec >= 53.
+func FromFloat(A *matrix.FloatMatrix, prec uint) *Matrix {
+	B := Zeros(A.Rows(), A.Cols(), prec)
+	for j := 0; j < B.cols; j++ {
+		for i := 0; i < B.rows; i++ {
+			B.elements[j*B.rows+i].SetFloat64(A.GetAt(i, j))
+		}
+	}
+	return B
+}
+
+// Convert to a new float matrix, rounding elements to nearest double.
+func (A *Matrix) Float() *matrix.FloatMatrix {
+	B := matrix.FloatZeros(A.rows, A.cols)
+	Br := B.FloatArray()
+	for k := range A.elements {
+		Br[k], _ = A.elements[k].Float64()
+	}
+	return B
+}
+
+// Return number of rows.
+func (A *Matrix) Rows() int {
+	return A.rows
+}
+
+// Return number of columns.
+func (A *Matrix) Cols() int {
+	return A.cols
+}
+
+// Return number of rows and columns.
+func (A *Matrix) Size() (int, int) {
+	return A.rows, A.cols
+}
+
+// Return precision in bits.
+func (A *Matrix) Prec() uint {
+	return A.prec
+}
+
+// Return element at (i, j). The element is stored in A, changes to it
+// change A.
+func (A *Matrix) GetAt(i, j int) *big.Float {
+	return &A.elements[j*A.rows+i]
+}
+
+// Set element at (i, j), rounding val to the precision of A.
+func (A *Matrix) SetAt(i, j int, val *big.Float) {
+	A.elements[j*A.rows+i].Set(val)
+}
+
+// Return a copy of A.
+func (A *Matrix) Copy() *Matrix {
+	B := Zeros(A.rows, A.cols, A.prec)
+	for k := range B.elements {
+		B.elements[k].Set(&A.elements[k])
+	}
+	return B
+}
+
+func (A *Matrix) String() string {
+	s := make([]string, 0, A.rows)
+	for i := 0; i < A.rows; i++ {
+		row := make([]string, A.cols)
+		for j := 0; j < A.cols; j++ {
+			row[j] = A.GetAt(i, j).Text('g', 20)
+		}
+		s = append(s, "["+strings.Join(row, " ")+"]")
+	}
+	return strings.Join(s, "\n")
+}
+
+// Return sum A + B with the larger of the two precisions.
+func Plus(A, B *Matrix) (*Matrix, error) {
+	if err := checkSame("Plus", A, B); err != nil {
+		return nil, err
+	}
+	C := Zeros(A.rows, A.cols, maxPrec(A, B))
+	for k := range C.elements {
+		C.elements[k].Add(&A.elements[k], &B.elements[k])
+	}
+	return C, nil
+}
+
+// Return difference A - B with the larger of the two precisions.
+func Minus(A, B *Matrix) (*Matrix, error) {
+	if err := checkSame("Minus", A, B); err != nil {
+		return nil, err
+	}
+	C := Zeros(A.rows, A.cols, maxPrec(A, B))
+	for k := range C.elements {
+		C.elements[k].Sub(&A.elements[k], &B.elements[k])
+	}
+	return C, nil
+}
+
+// Return matrix product A*B with the larger of the two precisions.
+func Times(A, B *Matrix) (*Matrix, error) {
+	if A.cols != B.rows {
+		return nil, linalg.NewError(linalg.ErrShape, "Times: dimensions do not match")
+	}
+	C := Zeros(A.rows, B.cols, maxPrec(A, B))
+	for j := 0; j < B.cols; j++ {
+		for i := 0; i < A.rows; i++ {
+			dot(C.GetAt(i, j), A, B, i, j)
+		}
+	}
+	return C, nil
+}
+
+// Return residual B - A*X with precision prec. Each element is computed
+// from exact products summed at precision prec, so the residual is
+// accurate even when it is much smaller than B.
+func Residual(A, X, B *Matrix, prec uint) (*Matrix, error) {
+	if A.cols != X.rows || A.rows != B.rows || X.cols != B.cols {
+		return nil, linalg.NewError(linalg.ErrShape, "Residual: dimensions do not match")
+	}
+	R := Zeros(B.rows, B.cols, prec)
+	for j := 0; j < B.cols; j++ {
+		for i := 0; i < B.rows; i++ {
+			r := R.GetAt(i, j)
+			dot(r, A, X, i, j)
+			r.Sub(B.GetAt(i, j), r)
+		}
+	}
+	return R, nil
+}
+
+// Set z to row i of A times column j of B. Products are exact; the sum is
+// rounded to the precision of z.
+func dot(z *big.Float, A, B *Matrix, i, j int) {
+	var p big.Float
+	p.SetPrec(A.prec + B.prec)
+	z.SetFloat64(0)
+	for k := 0; k < A.cols; k++ {
+		p.Mul(A.GetAt(i, k), B.GetAt(k, j))
+		z.Add(z, &p)
+	}
+}
+
+func maxPrec(A, B *Matrix) uint {
+	if A.prec > B.prec {
+		return A.prec
+	}
+	return B.prec
+}
+
+func checkSame(name string, A, B *Matrix) error {
+	if A.rows != B.rows || A.cols != B.cols {
+		return linalg.NewError(linalg.ErrShape, name+": dimensions do not match")
+	}
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,47 @@
+package bigfloat
+
+import (
+	"github.com/nvcook42/matrix"
+	"math/big"
+	"testing"
+)
+
+func TestResidual(t *testing.T) {
+	// x = 1 + 2^-60 is not a double; the residual with x rounded to double
+	// is lost in double precision but exact here
+	A := FromFloat(matrix.FloatNew(1, 1, []float64{3}), 128)
+	B := Zeros(1, 1, 128)
+	x := new(big.Float).SetPrec(128).SetFloat64(1)
+	x.Add(x, new(big.Float).SetMantExp(big.NewFloat(1), -60))
+	B.GetAt(0, 0).Mul(x, big.NewFloat(3))
+	X := FromFloat(B.Float(), 128)
+	X.GetAt(0, 0).Quo(X.GetAt(0, 0), big.NewFloat(3))
+	X = FromFloat(X.Float(), 128)
+	R, err := Residual(A, X, B, 128)
+	if err != nil {
+		t.Fatalf("Residual: %v\n", err)
+	}
+	want := new(big.Float).SetMantExp(big.NewFloat(3), -60)
+	if R.GetAt(0, 0).Cmp(want) != 0 {
+		t.Logf("residual %v, want %v\n", R, want)
+		t.Fail()
+	}
+}
+
+func TestTimes(t *testing.T) {
+	A := FromFloat(matrix.FloatNew(2, 2, []float64{1, 2, 3, 4}), 64)
+	I := FromFloat(matrix.FloatIdentity(2), 64)
+	C, err := Times(A, I)
+	if err != nil || !C.Float().Equal(A.Float()) {
+		t.Logf("A*I:\n%v\n", C)
+		t.Fail()
+	}
+	D, _ := Minus(A, A)
+	if D.Float().Max() != 0 {
+		t.Fail()
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
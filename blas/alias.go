@@ -0,0 +1,152 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/blas package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package blas
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"unsafe"
+)
+
+// Gemm, Syrk, Herk and Trsm return an error wrapping linalg.ErrAlias if the
+// elements of the output matrix they use overlap those of an input matrix,
+// as when C is A or a view of the same array. The library routines would
+// read partly overwritten input. The check compares the addresses of the
+// elements in the underlying arrays; it is skipped with option
+// linalg.BoolOpt("checkalias", false) by callers who know the overlap is safe.
+
+// Elements of the array of a matrix argument used by a routine: rows by
+// cols elements from offset with leading index ld.
+type region struct {
+	base, size             uintptr
+	offset, rows, cols, ld int
+}
+
+func arrayRegion(A matrix.Matrix, offset, rows, cols, ld int) region {
+	r := region{offset: offset, rows: rows, cols: cols, ld: ld}
+	switch a := A.(type) {
+	case *matrix.FloatMatrix:
+		if arr := a.FloatArray(); len(arr) > 0 {
+			r.base, r.size = uintptr(unsafe.Pointer(&arr[0])), unsafe.Sizeof(arr[0])
+		}
+	case *matrix.ComplexMatrix:
+		if arr := a.ComplexArray(); len(arr) > 0 {
+			r.base, r.size = uintptr(unsafe.Pointer(&arr[0])), unsafe.Sizeof(arr[0])
+		}
+	}
+	return r
+}
+
+func (r region) empty() bool {
+	return r.base == 0 || r.rows <= 0 || r.cols <= 0
+}
+
+// Addresses of the first element and one past the last element.
+func (r region) bounds() (uintptr, uintptr) {
+	first := r.base + uintptr(r.offset)*r.size
+	last := r.base + uintptr(r.offset+(r.cols-1)*r.ld+r.rows)*r.size
+	return first, last
+}
+
+// True if regions a and b have a common element. Regions with the same
+// leading index in the same array are compared by rows and columns, so
+// that disjoint blocks of a matrix are not reported; otherwise any overlap
+// of the address ranges is reported.
+func overlaps(a, b region) bool {
+	if a.empty() || b.empty() {
+		return false
+	}
+	a0, a1 := a.bounds()
+	b0, b1 := b.bounds()
+	if a1 <= b0 || b1 <= a0 {
+		return false
+	}
+	d := int(b.base) - int(a.base)
+	if a.size != b.size || a.ld != b.ld || d%int(a.size) != 0 {
+		return true
+	}
+	// position of the first elements as row and column of a's array
+	ra, ca := rowCol(a.offset, a.ld)
+	rb, cb := rowCol(b.offset+d/int(a.size), a.ld)
+	if ra+a.rows > a.ld || rb+b.rows > a.ld {
+		return true
+	}
+	return ra < rb+b.rows && rb < ra+a.rows && ca < cb+b.cols && cb < ca+a.cols
+}
+
+// Row and column of element k of an array with leading index ld.
+func rowCol(k, ld int) (int, int) {
+	r, c := k%ld, k/ld
+	if r < 0 {
+		r, c = r+ld, c-1
+	}
+	return r, c
+}
+
+// True unless overlap checks are disabled with option "checkalias".
+func checkAliasing(opts []linalg.Option) bool {
+	return linalg.GetBoolOpt("checkalias", true, opts...)
+}
+
+// Check that C does not overlap A or B of Gemm.
+func gemmAlias(A, B, C matrix.Matrix, ind *linalg.IndexOpts, params *linalg.Parameters, opts []linalg.Option) error {
+	if !checkAliasing(opts) {
+		return nil
+	}
+	ra, ca := ind.M, ind.K
+	if params.TransA != linalg.PNoTrans {
+		ra, ca = ca, ra
+	}
+	rb, cb := ind.K, ind.N
+	if params.TransB != linalg.PNoTrans {
+		rb, cb = cb, rb
+	}
+	Cr := arrayRegion(C, ind.OffsetC, ind.M, ind.N, ind.LDc)
+	if overlaps(Cr, arrayRegion(A, ind.OffsetA, ra, ca, ind.LDa)) {
+		return onError(linalg.ErrAlias, "Gemm: C overlaps A")
+	}
+	if overlaps(Cr, arrayRegion(B, ind.OffsetB, rb, cb, ind.LDb)) {
+		return onError(linalg.ErrAlias, "Gemm: C overlaps B")
+	}
+	return nil
+}
+
+// Check that C does not overlap A of Syrk or Herk.
+func syrkAlias(name string, A, C matrix.Matrix, ind *linalg.IndexOpts, params *linalg.Parameters, opts []linalg.Option) error {
+	if !checkAliasing(opts) {
+		return nil
+	}
+	ra, ca := ind.N, ind.K
+	if params.Trans != linalg.PNoTrans {
+		ra, ca = ca, ra
+	}
+	if overlaps(arrayRegion(C, ind.OffsetC, ind.N, ind.N, ind.LDc),
+		arrayRegion(A, ind.OffsetA, ra, ca, ind.LDa)) {
+		return onError(linalg.ErrAlias, name+": C overlaps A")
+	}
+	return nil
+}
+
+// Check that B does not overlap A of Trsm.
+func trsmAlias(A, B matrix.Matrix, ind *linalg.IndexOpts, params *linalg.Parameters, opts []linalg.Option) error {
+	if !checkAliasing(opts) {
+		return nil
+	}
+	na := ind.M
+	if params.Side != linalg.PLeft {
+		na = ind.N
+	}
+	if overlaps(arrayRegion(B, ind.OffsetB, ind.M, ind.N, ind.LDb),
+		arrayRegion(A, ind.OffsetA, na, na, ind.LDa)) {
+		return onError(linalg.ErrAlias, "Trsm: B overlaps A")
+	}
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -16,6 +16,7 @@ import (
 
 // See function Nrm2.
 func Nrm2Complex(X *matrix.ComplexMatrix, opts ...linalg.Option) (v float64, err error) {
+	defer guard("Nrm2Complex", &err, opts...)()
 	v = 0.0
 	ind := linalg.GetIndexOpts(opts...)
 	err = check_level1_func(ind, fnrm2, X, nil)
@@ -26,7 +27,7 @@ func Nrm2Complex(X *matrix.ComplexMatrix, opts ...linalg.Option) (v float64, err
 		return
 	}
 	Xa := X.ComplexArray()
-	v = dznrm2(ind.Nx, Xa[ind.OffsetX:], ind.IncX)
+	v = znrm2(ind.Nx, Xa[ind.OffsetX:], ind.IncX)
 	return
 }
 
@@ -34,6 +35,7 @@ func Nrm2Complex(X *matrix.ComplexMatrix, opts ...linalg.Option) (v float64, err
 
 // See function Asum.
 func AsumComplex(X *matrix.ComplexMatrix, opts ...linalg.Option) (v float64, err error) {
+	defer guard("AsumComplex", &err, opts...)()
 	v = 0.0
 	ind := linalg.GetIndexOpts(opts...)
 	err = check_level1_func(ind, fasum, X, nil)
@@ -52,6 +54,7 @@ func AsumComplex(X *matrix.ComplexMatrix, opts ...linalg.Option) (v float64, err
 
 // See function Dot.
 func DotuComplex(X, Y *matrix.ComplexMatrix, opts ...linalg.Option) (v complex128, err error) {
+	defer guard("DotuComplex", &err, opts...)()
 	v = 0.0
 	ind := linalg.GetIndexOpts(opts...)
 	err = check_level1_func(ind, fdot, X, Y)
@@ -71,6 +74,7 @@ func DotuComplex(X, Y *matrix.ComplexMatrix, opts ...linalg.Option) (v complex12
 
 // See function Dotc.
 func DotcComplex(X, Y *matrix.ComplexMatrix, opts ...linalg.Option) (v complex128, err error) {
+	defer guard("DotcComplex", &err, opts...)()
 	v = 0.0
 	ind := linalg.GetIndexOpts(opts...)
 	err = check_level1_func(ind, fdot, X, Y)
@@ -86,6 +90,602 @@ func DotcComplex(X, Y *matrix.ComplexMatrix, opts ...linalg.Option) (v complex12
 	return
 }
 
+// See function Scal.
+func ScalComplex(X *matrix.ComplexMatrix, alpha complex128, opts ...linalg.Option) (err error) {
+	defer guard("ScalComplex", &err, opts...)()
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level1_func(ind, fscal, X, nil)
+	if err != nil {
+		return
+	}
+	if ind.Nx == 0 {
+		return
+	}
+	Xa := X.ComplexArray()
+	zscal(ind.Nx, alpha, Xa[ind.OffsetX:], ind.IncX)
+	return
+}
+
+// See function Axpy.
+func AxpyComplex(X, Y *matrix.ComplexMatrix, alpha complex128, opts ...linalg.Option) (err error) {
+	defer guard("AxpyComplex", &err, opts...)()
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level1_func(ind, faxpy, X, Y)
+	if err != nil {
+		return
+	}
+	if ind.Nx == 0 {
+		return
+	}
+	Xa := X.ComplexArray()
+	Ya := Y.ComplexArray()
+	zaxpy(ind.Nx, alpha, Xa[ind.OffsetX:], ind.IncX, Ya[ind.OffsetY:], ind.IncY)
+	return
+}
+
+// ---------------------------------------------------------------------------------
+// BLAS LEVEL 2
+// ---------------------------------------------------------------------------------
+
+// See function Gemv.
+func GemvComplex(A, X, Y *matrix.ComplexMatrix, alpha, beta complex128, opts ...linalg.Option) (err error) {
+	defer guard("GemvComplex", &err, opts...)()
+
+	var params *linalg.Parameters
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, fgemv, X, Y, A, params)
+	if err != nil {
+		return
+	}
+	if ind.M == 0 && params.Trans == linalg.PNoTrans {
+		return
+	}
+	if ind.N == 0 && params.Trans != linalg.PNoTrans {
+		return
+	}
+	Xa := X.ComplexArray()
+	Ya := Y.ComplexArray()
+	Aa := A.ComplexArray()
+	if params.Trans == linalg.PNoTrans && ind.N == 0 {
+		zscal(ind.M, beta, Ya[ind.OffsetY:], ind.IncY)
+	} else if params.Trans != linalg.PNoTrans && ind.M == 0 {
+		zscal(ind.N, beta, Ya[ind.OffsetY:], ind.IncY)
+	} else {
+		trans := linalg.ParamString(params.Trans)
+		zgemv(trans, ind.M, ind.N, alpha, Aa[ind.OffsetA:],
+			ind.LDa, Xa[ind.OffsetX:], ind.IncX, beta, Ya[ind.OffsetY:], ind.IncY)
+	}
+	return
+}
+
+// See function Gbmv.
+func GbmvComplex(A, X, Y *matrix.ComplexMatrix, alpha, beta complex128, opts ...linalg.Option) (err error) {
+	defer guard("GbmvComplex", &err, opts...)()
+
+	var params *linalg.Parameters
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, fgbmv, X, Y, A, params)
+	if err != nil {
+		return
+	}
+	if ind.M == 0 && ind.N == 0 {
+		return
+	}
+	Xa := X.ComplexArray()
+	Ya := Y.ComplexArray()
+	Aa := A.ComplexArray()
+	if params.Trans == linalg.PNoTrans && ind.N == 0 {
+		zscal(ind.M, beta, Ya[ind.OffsetY:], ind.IncY)
+	} else if params.Trans != linalg.PNoTrans && ind.M == 0 {
+		zscal(ind.N, beta, Ya[ind.OffsetY:], ind.IncY)
+	} else {
+		trans := linalg.ParamString(params.Trans)
+		zgbmv(trans, ind.M, ind.N, ind.Kl, ind.Ku,
+			alpha, Aa[ind.OffsetA:], ind.LDa, Xa[ind.OffsetX:], ind.IncX,
+			beta, Ya[ind.OffsetY:], ind.IncY)
+	}
+	return
+}
+
+// See function Hemv.
+func HemvComplex(A, X, Y *matrix.ComplexMatrix, alpha, beta complex128, opts ...linalg.Option) (err error) {
+	defer guard("HemvComplex", &err, opts...)()
+
+	var params *linalg.Parameters
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, fsymv, X, Y, A, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	Xa := X.ComplexArray()
+	Ya := Y.ComplexArray()
+	Aa := A.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	zhemv(uplo, ind.N, alpha, Aa[ind.OffsetA:], ind.LDa, Xa[ind.OffsetX:], ind.IncX,
+		beta, Ya[ind.OffsetY:], ind.IncY)
+	return
+}
+
+// See function Hbmv.
+func HbmvComplex(A, X, Y *matrix.ComplexMatrix, alpha, beta complex128, opts ...linalg.Option) (err error) {
+	defer guard("HbmvComplex", &err, opts...)()
+
+	var params *linalg.Parameters
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, fsbmv, X, Y, A, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	Xa := X.ComplexArray()
+	Ya := Y.ComplexArray()
+	Aa := A.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	zhbmv(uplo, ind.N, ind.K, alpha, Aa[ind.OffsetA:], ind.LDa, Xa[ind.OffsetX:],
+		ind.IncX, beta, Ya[ind.OffsetY:], ind.IncY)
+	return
+}
+
+// See function Hpmv.
+func HpmvComplex(A, X, Y *matrix.ComplexMatrix, alpha, beta complex128, opts ...linalg.Option) (err error) {
+	defer guard("HpmvComplex", &err, opts...)()
+
+	var params *linalg.Parameters
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, fspmv, X, Y, A, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	Xa := X.ComplexArray()
+	Ya := Y.ComplexArray()
+	Aa := A.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	zhpmv(uplo, ind.N, alpha, Aa[ind.OffsetA:], Xa[ind.OffsetX:], ind.IncX,
+		beta, Ya[ind.OffsetY:], ind.IncY)
+	return
+}
+
+// See function Geru.
+func GeruComplex(X, Y, A *matrix.ComplexMatrix, alpha complex128, opts ...linalg.Option) (err error) {
+	defer guard("GeruComplex", &err, opts...)()
+
+	var params *linalg.Parameters
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, fger, X, Y, A, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 || ind.M == 0 {
+		return
+	}
+	Xa := X.ComplexArray()
+	Ya := Y.ComplexArray()
+	Aa := A.ComplexArray()
+	zgeru(ind.M, ind.N, alpha, Xa[ind.OffsetX:], ind.IncX,
+		Ya[ind.OffsetY:], ind.IncY, Aa[ind.OffsetA:], ind.LDa)
+	return
+}
+
+// See function Gerc. Ger on complex matrices is Gerc.
+func GercComplex(X, Y, A *matrix.ComplexMatrix, alpha complex128, opts ...linalg.Option) (err error) {
+	defer guard("GercComplex", &err, opts...)()
+
+	var params *linalg.Parameters
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, fger, X, Y, A, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 || ind.M == 0 {
+		return
+	}
+	Xa := X.ComplexArray()
+	Ya := Y.ComplexArray()
+	Aa := A.ComplexArray()
+	zgerc(ind.M, ind.N, alpha, Xa[ind.OffsetX:], ind.IncX,
+		Ya[ind.OffsetY:], ind.IncY, Aa[ind.OffsetA:], ind.LDa)
+	return
+}
+
+// See function Her. Alpha is real.
+func HerComplex(X, A *matrix.ComplexMatrix, alpha float64, opts ...linalg.Option) (err error) {
+	defer guard("HerComplex", &err, opts...)()
+
+	var params *linalg.Parameters
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, fsyr, X, nil, A, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	Xa := X.ComplexArray()
+	Aa := A.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	zher(uplo, ind.N, alpha, Xa[ind.OffsetX:], ind.IncX, Aa[ind.OffsetA:], ind.LDa)
+	return
+}
+
+// See function Her2.
+func Her2Complex(X, Y, A *matrix.ComplexMatrix, alpha complex128, opts ...linalg.Option) (err error) {
+	defer guard("Her2Complex", &err, opts...)()
+
+	var params *linalg.Parameters
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, fsyr2, X, Y, A, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	Xa := X.ComplexArray()
+	Ya := Y.ComplexArray()
+	Aa := A.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	zher2(uplo, ind.N, alpha, Xa[ind.OffsetX:], ind.IncX, Ya[ind.OffsetY:], ind.IncY,
+		Aa[ind.OffsetA:], ind.LDa)
+	return
+}
+
+// See function Hpr. Alpha is real.
+func HprComplex(X, A *matrix.ComplexMatrix, alpha float64, opts ...linalg.Option) (err error) {
+	defer guard("HprComplex", &err, opts...)()
+
+	var params *linalg.Parameters
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, fspr, X, nil, A, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	Xa := X.ComplexArray()
+	Aa := A.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	zhpr(uplo, ind.N, alpha, Xa[ind.OffsetX:], ind.IncX, Aa[ind.OffsetA:])
+	return
+}
+
+// See function Hpr2.
+func Hpr2Complex(X, Y, A *matrix.ComplexMatrix, alpha complex128, opts ...linalg.Option) (err error) {
+	defer guard("Hpr2Complex", &err, opts...)()
+
+	var params *linalg.Parameters
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, fdspr2, X, Y, A, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	Xa := X.ComplexArray()
+	Ya := Y.ComplexArray()
+	Aa := A.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	zhpr2(uplo, ind.N, alpha, Xa[ind.OffsetX:], ind.IncX,
+		Ya[ind.OffsetY:], ind.IncY, Aa[ind.OffsetA:])
+	return
+}
+
+// ---------------------------------------------------------------------------------
+// BLAS LEVEL 3
+// ---------------------------------------------------------------------------------
+
+// See function Gemm.
+func GemmComplex(A, B, C *matrix.ComplexMatrix, alpha, beta complex128, opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("blas.Gemm")
+	defer op.Finish(&err)
+	defer guard("GemmComplex", &err, opts...)()
+
+	params, e := linalg.GetParameters(opts...)
+	if e != nil {
+		err = e
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level3_func(ind, fgemm, A, B, C, params)
+	if err != nil {
+		return
+	}
+	op.SetDims(ind.M, ind.N, ind.K)
+	if ind.M == 0 || ind.N == 0 {
+		return
+	}
+	if err = gemmAlias(A, B, C, ind, params, opts); err != nil {
+		return
+	}
+	Aa := A.ComplexArray()
+	Ba := B.ComplexArray()
+	Ca := C.ComplexArray()
+	transB := linalg.ParamString(params.TransB)
+	transA := linalg.ParamString(params.TransA)
+	dispatchZgemm(op, transA, transB, ind.M, ind.N, ind.K, alpha,
+		Aa[ind.OffsetA:], ind.LDa, Ba[ind.OffsetB:], ind.LDb, beta,
+		Ca[ind.OffsetC:], ind.LDc, opts...)
+	return
+}
+
+// See function Symm.
+func SymmComplex(A, B, C *matrix.ComplexMatrix, alpha, beta complex128, opts ...linalg.Option) (err error) {
+	defer guard("SymmComplex", &err, opts...)()
+
+	params, e := linalg.GetParameters(opts...)
+	if e != nil {
+		err = e
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level3_func(ind, fsymm, A, B, C, params)
+	if err != nil {
+		return
+	}
+	if ind.M == 0 || ind.N == 0 {
+		return
+	}
+	Aa := A.ComplexArray()
+	Ba := B.ComplexArray()
+	Ca := C.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	side := linalg.ParamString(params.Side)
+	zsymm(side, uplo, ind.M, ind.N, alpha, Aa[ind.OffsetA:], ind.LDa,
+		Ba[ind.OffsetB:], ind.LDb, beta, Ca[ind.OffsetC:], ind.LDc)
+	return
+}
+
+// See function Hemm.
+func HemmComplex(A, B, C *matrix.ComplexMatrix, alpha, beta complex128, opts ...linalg.Option) (err error) {
+	defer guard("HemmComplex", &err, opts...)()
+
+	params, e := linalg.GetParameters(opts...)
+	if e != nil {
+		err = e
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level3_func(ind, fsymm, A, B, C, params)
+	if err != nil {
+		return
+	}
+	if ind.M == 0 || ind.N == 0 {
+		return
+	}
+	Aa := A.ComplexArray()
+	Ba := B.ComplexArray()
+	Ca := C.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	side := linalg.ParamString(params.Side)
+	zhemm(side, uplo, ind.M, ind.N, alpha, Aa[ind.OffsetA:], ind.LDa,
+		Ba[ind.OffsetB:], ind.LDb, beta, Ca[ind.OffsetC:], ind.LDc)
+	return
+}
+
+// See function Syrk.
+func SyrkComplex(A, C *matrix.ComplexMatrix, alpha, beta complex128, opts ...linalg.Option) (err error) {
+	defer guard("SyrkComplex", &err, opts...)()
+
+	params, e := linalg.GetParameters(opts...)
+	if e != nil {
+		err = e
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level3_func(ind, fsyrk, A, nil, C, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	if err = syrkAlias("Syrk", A, C, ind, params, opts); err != nil {
+		return
+	}
+	Aa := A.ComplexArray()
+	Ca := C.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	trans := linalg.ParamString(params.Trans)
+	zsyrk(uplo, trans, ind.N, ind.K, alpha, Aa[ind.OffsetA:], ind.LDa, beta,
+		Ca[ind.OffsetC:], ind.LDc)
+	return
+}
+
+// See function Herk. Beta is real.
+func HerkComplex(A, C *matrix.ComplexMatrix, alpha complex128, beta float64, opts ...linalg.Option) (err error) {
+	defer guard("HerkComplex", &err, opts...)()
+
+	params, e := linalg.GetParameters(opts...)
+	if e != nil {
+		err = e
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level3_func(ind, fsyrk, A, nil, C, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	if err = syrkAlias("Herk", A, C, ind, params, opts); err != nil {
+		return
+	}
+	Aa := A.ComplexArray()
+	Ca := C.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	trans := linalg.ParamString(params.Trans)
+	zherk(uplo, trans, ind.N, ind.K, alpha, Aa[ind.OffsetA:], ind.LDa, beta,
+		Ca[ind.OffsetC:], ind.LDc)
+	return
+}
+
+// See function Syr2k.
+func Syr2kComplex(A, B, C *matrix.ComplexMatrix, alpha, beta complex128, opts ...linalg.Option) (err error) {
+	defer guard("Syr2kComplex", &err, opts...)()
+
+	params, e := linalg.GetParameters(opts...)
+	if e != nil {
+		err = e
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level3_func(ind, fsyr2k, A, B, C, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	Aa := A.ComplexArray()
+	Ba := B.ComplexArray()
+	Ca := C.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	trans := linalg.ParamString(params.Trans)
+	zsyr2k(uplo, trans, ind.N, ind.K, alpha, Aa[ind.OffsetA:], ind.LDa,
+		Ba[ind.OffsetB:], ind.LDb, beta, Ca[ind.OffsetC:], ind.LDc)
+	return
+}
+
+// See function Her2k. Beta is real.
+func Her2kComplex(A, B, C *matrix.ComplexMatrix, alpha complex128, beta float64, opts ...linalg.Option) (err error) {
+	defer guard("Her2kComplex", &err, opts...)()
+
+	params, e := linalg.GetParameters(opts...)
+	if e != nil {
+		err = e
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level3_func(ind, fsyr2k, A, B, C, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	Aa := A.ComplexArray()
+	Ba := B.ComplexArray()
+	Ca := C.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	trans := linalg.ParamString(params.Trans)
+	zher2k(uplo, trans, ind.N, ind.K, alpha, Aa[ind.OffsetA:], ind.LDa,
+		Ba[ind.OffsetB:], ind.LDb, beta, Ca[ind.OffsetC:], ind.LDc)
+	return
+}
+
+// See function Trmm.
+func TrmmComplex(A, B *matrix.ComplexMatrix, alpha complex128, opts ...linalg.Option) (err error) {
+	defer guard("TrmmComplex", &err, opts...)()
+
+	params, e := linalg.GetParameters(opts...)
+	if e != nil {
+		err = e
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level3_func(ind, ftrmm, A, B, nil, params)
+	if err != nil {
+		return
+	}
+	if ind.M == 0 || ind.N == 0 {
+		return
+	}
+	Aa := A.ComplexArray()
+	Ba := B.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	transA := linalg.ParamString(params.TransA)
+	side := linalg.ParamString(params.Side)
+	diag := linalg.ParamString(params.Diag)
+	ztrmm(side, uplo, transA, diag, ind.M, ind.N, alpha,
+		Aa[ind.OffsetA:], ind.LDa, Ba[ind.OffsetB:], ind.LDb)
+	return
+}
+
+// See function Trsm.
+func TrsmComplex(A, B *matrix.ComplexMatrix, alpha complex128, opts ...linalg.Option) (err error) {
+	defer guard("TrsmComplex", &err, opts...)()
+
+	params, e := linalg.GetParameters(opts...)
+	if e != nil {
+		err = e
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level3_func(ind, ftrsm, A, B, nil, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 || ind.M == 0 {
+		return
+	}
+	if err = trsmAlias(A, B, ind, params, opts); err != nil {
+		return
+	}
+	Aa := A.ComplexArray()
+	Ba := B.ComplexArray()
+	uplo := linalg.ParamString(params.Uplo)
+	transA := linalg.ParamString(params.TransA)
+	side := linalg.ParamString(params.Side)
+	diag := linalg.ParamString(params.Diag)
+	ztrsm(side, uplo, transA, diag, ind.M, ind.N, alpha,
+		Aa[ind.OffsetA:], ind.LDa, Ba[ind.OffsetB:], ind.LDb)
+	return
+}
+
 // Local Variables:
 // tab-width: 4
 // End:
@@ -25,7 +25,7 @@ func Nrm2Float(X *matrix.FloatMatrix, opts ...linalg.Option) (v float64) {
 		return
 	}
 	Xa := X.FloatArray()
-	v = dnrm2(ind.Nx, Xa[ind.OffsetX:], ind.IncX)
+	v = nrm2(ind.Nx, Xa[ind.OffsetX:], ind.IncX)
 	return
 }
 
@@ -66,6 +66,7 @@ func DotFloat(X, Y *matrix.FloatMatrix, opts ...linalg.Option) (v float64) {
 
 // See function Swap.
 func SwapFloat(X, Y *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	defer guard("SwapFloat", &err, opts...)()
 	ind := linalg.GetIndexOpts(opts...)
 	err = check_level1_func(ind, fswap, X, Y)
 	if err != nil {
@@ -82,6 +83,7 @@ func SwapFloat(X, Y *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
 
 // See function Copy.
 func CopyFloat(X, Y *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	defer guard("CopyFloat", &err, opts...)()
 	ind := linalg.GetIndexOpts(opts...)
 	err = check_level1_func(ind, fcopy, X, Y)
 	if err != nil {
@@ -101,6 +103,7 @@ func CopyFloat(X, Y *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
 
 // See function Scal.
 func ScalFloat(X *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (err error) {
+	defer guard("ScalFloat", &err, opts...)()
 	ind := linalg.GetIndexOpts(opts...)
 	err = check_level1_func(ind, fscal, X, nil)
 	if err != nil {
@@ -116,6 +119,7 @@ func ScalFloat(X *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (err
 
 // See function Axpy.
 func AxpyFloat(X, Y *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (err error) {
+	defer guard("AxpyFloat", &err, opts...)()
 	ind := linalg.GetIndexOpts(opts...)
 	err = check_level1_func(ind, faxpy, X, Y)
 	if err != nil {
@@ -136,6 +140,7 @@ func AxpyFloat(X, Y *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (
 
 // See function Gemv.
 func GemvFloat(A, X, Y *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.Option) (err error) {
+	defer guard("GemvFloat", &err, opts...)()
 
 	var params *linalg.Parameters
 	params, err = linalg.GetParameters(opts...)
@@ -170,6 +175,7 @@ func GemvFloat(A, X, Y *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.
 
 // See function Gbmv.
 func GbmvFloat(A, X, Y *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.Option) (err error) {
+	defer guard("GbmvFloat", &err, opts...)()
 
 	var params *linalg.Parameters
 	params, err = linalg.GetParameters(opts...)
@@ -203,6 +209,7 @@ func GbmvFloat(A, X, Y *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.
 
 // See function Symv.
 func SymvFloat(A, X, Y *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.Option) (err error) {
+	defer guard("SymvFloat", &err, opts...)()
 
 	var params *linalg.Parameters
 	params, err = linalg.GetParameters(opts...)
@@ -228,6 +235,7 @@ func SymvFloat(A, X, Y *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.
 
 // See function Sbmv.
 func SbmvFloat(A, X, Y *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.Option) (err error) {
+	defer guard("SbmvFloat", &err, opts...)()
 
 	var params *linalg.Parameters
 	params, err = linalg.GetParameters(opts...)
@@ -253,6 +261,7 @@ func SbmvFloat(A, X, Y *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.
 
 // See function Trmv.
 func TrmvFloat(A, X *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	defer guard("TrmvFloat", &err, opts...)()
 
 	var params *linalg.Parameters
 	params, err = linalg.GetParameters(opts...)
@@ -279,6 +288,7 @@ func TrmvFloat(A, X *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
 
 // See function Tbmv.
 func TbmvFloat(A, X *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	defer guard("TbmvFloat", &err, opts...)()
 
 	var params *linalg.Parameters
 	params, err = linalg.GetParameters(opts...)
@@ -305,6 +315,7 @@ func TbmvFloat(A, X *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
 
 // See function Trsv.
 func TrsvFloat(A, X *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	defer guard("TrsvFloat", &err, opts...)()
 
 	var params *linalg.Parameters
 	params, err = linalg.GetParameters(opts...)
@@ -331,6 +342,7 @@ func TrsvFloat(A, X *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
 
 // See function Tbsv.
 func TbsvFloat(A, X *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	defer guard("TbsvFloat", &err, opts...)()
 
 	var params *linalg.Parameters
 	params, err = linalg.GetParameters(opts...)
@@ -357,6 +369,7 @@ func TbsvFloat(A, X *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
 
 // See function Ger.
 func GerFloat(X, Y, A *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (err error) {
+	defer guard("GerFloat", &err, opts...)()
 
 	var params *linalg.Parameters
 	params, err = linalg.GetParameters(opts...)
@@ -382,6 +395,7 @@ func GerFloat(X, Y, A *matrix.FloatMatrix, alpha float64, opts ...linalg.Option)
 
 // See function Syr.
 func SyrFloat(X, A *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (err error) {
+	defer guard("SyrFloat", &err, opts...)()
 
 	var params *linalg.Parameters
 	params, err = linalg.GetParameters(opts...)
@@ -405,6 +419,7 @@ func SyrFloat(X, A *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (e
 
 // See function Syr2.
 func Syr2Float(X, Y, A *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (err error) {
+	defer guard("Syr2Float", &err, opts...)()
 
 	var params *linalg.Parameters
 	params, err = linalg.GetParameters(opts...)
@@ -428,12 +443,91 @@ func Syr2Float(X, Y, A *matrix.FloatMatrix, alpha float64, opts ...linalg.Option
 	return
 }
 
+// See function Spmv.
+func SpmvFloat(A, X, Y *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.Option) (err error) {
+	defer guard("SpmvFloat", &err, opts...)()
+
+	var params *linalg.Parameters
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, fspmv, X, Y, A, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	Xa := X.FloatArray()
+	Ya := Y.FloatArray()
+	Aa := A.FloatArray()
+	uplo := linalg.ParamString(params.Uplo)
+	dspmv(uplo, ind.N, alpha, Aa[ind.OffsetA:], Xa[ind.OffsetX:], ind.IncX,
+		beta, Ya[ind.OffsetY:], ind.IncY)
+	return
+}
+
+// See function Spr.
+func SprFloat(X, A *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (err error) {
+	defer guard("SprFloat", &err, opts...)()
+
+	var params *linalg.Parameters
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, fspr, X, nil, A, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	Xa := X.FloatArray()
+	Aa := A.FloatArray()
+	uplo := linalg.ParamString(params.Uplo)
+	dspr(uplo, ind.N, alpha, Xa[ind.OffsetX:], ind.IncX, Aa[ind.OffsetA:])
+	return
+}
+
+// See function Spr2.
+func Spr2Float(X, Y, A *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (err error) {
+	defer guard("Spr2Float", &err, opts...)()
+
+	var params *linalg.Parameters
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, fdspr2, X, Y, A, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	Xa := X.FloatArray()
+	Ya := Y.FloatArray()
+	Aa := A.FloatArray()
+	uplo := linalg.ParamString(params.Uplo)
+	dspr2(uplo, ind.N, alpha, Xa[ind.OffsetX:], ind.IncX,
+		Ya[ind.OffsetY:], ind.IncY, Aa[ind.OffsetA:])
+	return
+}
+
 // ---------------------------------------------------------------------------------
 // BLAS LEVEL 3
 // ---------------------------------------------------------------------------------
 
 // See function Gemm.
 func GemmFloat(A, B, C *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("blas.Gemm")
+	defer op.Finish(&err)
+	defer guard("GemmFloat", &err, opts...)()
 
 	params, e := linalg.GetParameters(opts...)
 	if e != nil {
@@ -445,23 +539,28 @@ func GemmFloat(A, B, C *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.
 	if err != nil {
 		return
 	}
+	op.SetDims(ind.M, ind.N, ind.K)
 	if ind.M == 0 || ind.N == 0 {
 		return
 	}
+	if err = gemmAlias(A, B, C, ind, params, opts); err != nil {
+		return
+	}
 	Aa := A.FloatArray()
 	Ba := B.FloatArray()
 	Ca := C.FloatArray()
 	transB := linalg.ParamString(params.TransB)
 	transA := linalg.ParamString(params.TransA)
 	//diag := linalg.ParamString(params.Diag)
-	dgemm(transA, transB, ind.M, ind.N, ind.K, alpha,
+	err = dispatchDgemm(op, transA, transB, ind.M, ind.N, ind.K, alpha,
 		Aa[ind.OffsetA:], ind.LDa, Ba[ind.OffsetB:], ind.LDb, beta,
-		Ca[ind.OffsetC:], ind.LDc)
+		Ca[ind.OffsetC:], ind.LDc, opts...)
 	return
 }
 
 // See function Symm.
 func SymmFloat(A, B, C *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.Option) (err error) {
+	defer guard("SymmFloat", &err, opts...)()
 
 	params, e := linalg.GetParameters(opts...)
 	if e != nil {
@@ -489,6 +588,7 @@ func SymmFloat(A, B, C *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.
 
 // See function Syrk.
 func SyrkFloat(A, C *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.Option) (err error) {
+	defer guard("SyrkFloat", &err, opts...)()
 
 	params, e := linalg.GetParameters(opts...)
 	if e != nil {
@@ -503,6 +603,9 @@ func SyrkFloat(A, C *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.Opt
 	if ind.N == 0 {
 		return
 	}
+	if err = syrkAlias("Syrk", A, C, ind, params, opts); err != nil {
+		return
+	}
 	Aa := A.FloatArray()
 	Ca := C.FloatArray()
 	uplo := linalg.ParamString(params.Uplo)
@@ -516,6 +619,7 @@ func SyrkFloat(A, C *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.Opt
 
 // See function Syrk2.
 func Syr2kFloat(A, B, C *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.Option) (err error) {
+	defer guard("Syr2kFloat", &err, opts...)()
 
 	params, e := linalg.GetParameters(opts...)
 	if e != nil {
@@ -543,6 +647,7 @@ func Syr2kFloat(A, B, C *matrix.FloatMatrix, alpha, beta float64, opts ...linalg
 
 // See function Trmm.
 func TrmmFloat(A, B *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (err error) {
+	defer guard("TrmmFloat", &err, opts...)()
 
 	params, e := linalg.GetParameters(opts...)
 	if e != nil {
@@ -570,6 +675,7 @@ func TrmmFloat(A, B *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (
 
 // See function Trsm.
 func TrsmFloat(A, B *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (err error) {
+	defer guard("TrsmFloat", &err, opts...)()
 
 	params, e := linalg.GetParameters(opts...)
 	if e != nil {
@@ -584,6 +690,9 @@ func TrsmFloat(A, B *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (
 	if ind.N == 0 || ind.M == 0 {
 		return
 	}
+	if err = trsmAlias(A, B, ind, params, opts); err != nil {
+		return
+	}
 	Aa := A.FloatArray()
 	Ba := B.FloatArray()
 	uplo := linalg.ParamString(params.Uplo)
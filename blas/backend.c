@@ -0,0 +1,116 @@
+/*
+ * Runtime inspection of the linked BLAS library. Vendor specific query
+ * functions are looked up with dlsym so that the package links against
+ * any BLAS implementation.
+ */
+
+#define _GNU_SOURCE
+#include <dlfcn.h>
+#include <stdio.h>
+#include <string.h>
+#include "backend.h"
+
+static void *sym(const char *name)
+{
+    return dlsym(RTLD_DEFAULT, name);
+}
+
+int backend_has_symbol(const char *name)
+{
+    return sym(name) != NULL;
+}
+
+/* Fill buf with library identification. Returns one of BACKEND_* constants. */
+int backend_query(char *buf, int len, int *parallel, int *threads)
+{
+    char *(*openblas_config)(void) = sym("openblas_get_config");
+    void (*mkl_version)(char *, int) = sym("MKL_Get_Version_String");
+    const char *(*blis_version)(void) = sym("bli_info_get_version_str");
+
+    buf[0] = '\0';
+    *parallel = -1;
+    *threads = 0;
+    if (openblas_config) {
+        int (*get_parallel)(void) = sym("openblas_get_parallel");
+        int (*get_threads)(void) = sym("openblas_get_num_threads");
+        snprintf(buf, len, "%s", openblas_config());
+        if (get_parallel)
+            *parallel = get_parallel();
+        if (get_threads)
+            *threads = get_threads();
+        return BACKEND_OPENBLAS;
+    }
+    if (mkl_version) {
+        int (*max_threads)(void) = sym("MKL_Get_Max_Threads");
+        mkl_version(buf, len);
+        if (max_threads)
+            *threads = max_threads();
+        return BACKEND_MKL;
+    }
+    if (blis_version) {
+        snprintf(buf, len, "%s", blis_version());
+        return BACKEND_BLIS;
+    }
+    if (sym("ATL_buildinfo"))
+        return BACKEND_ATLAS;
+    return BACKEND_UNKNOWN;
+}
+
+typedef void (*dgemmt_func)(char *, char *, char *, int *, int *,
+    double *, double *, int *, double *, int *, double *, double *, int *);
+
+/* Call dgemmt_ of MKL or OpenBLAS if the library has it. Returns 0 without
+ * doing anything if it does not. */
+int backend_dgemmt(char *uplo, char *transa, char *transb, int *n, int *k,
+    double *alpha, double *A, int *lda, double *B, int *ldb,
+    double *beta, double *C, int *ldc)
+{
+    dgemmt_func f = (dgemmt_func)sym("dgemmt_");
+    if (!f)
+        return 0;
+    f(uplo, transa, transb, n, k, alpha, A, lda, B, ldb, beta, C, ldc);
+    return 1;
+}
+
+typedef void (*zgemm3m_func)(char *, char *, int *, int *, int *,
+    void *, void *, int *, void *, int *, void *, void *, int *);
+
+/* Call zgemm3m_ of MKL or OpenBLAS if the library has it. Returns 0 without
+ * doing anything if it does not. */
+int backend_zgemm3m(char *transa, char *transb, int *m, int *n, int *k,
+    void *alpha, void *A, int *lda, void *B, int *ldb,
+    void *beta, void *C, int *ldc)
+{
+    zgemm3m_func f = (zgemm3m_func)sym("zgemm3m_");
+    if (!f)
+        return 0;
+    f(transa, transb, m, n, k, alpha, A, lda, B, ldb, beta, C, ldc);
+    return 1;
+}
+
+/* CBLAS enumeration values of cblas.h */
+#define CBLAS_COL_MAJOR  102
+#define CBLAS_NO_TRANS   111
+#define CBLAS_TRANS      112
+#define CBLAS_FIX_OFFSET 171
+
+typedef void (*gemm_s8u8s32_func)(int, int, int, int, int, int, int,
+    float, const void *, int, signed char, const void *, int, signed char,
+    float, int *, int, const int *);
+
+/* Compute C := (op(A) + ao)*(op(B) + bo) for signed A and unsigned B with
+ * cblas_gemm_s8u8s32 of MKL if the library has it. Returns 0 without doing
+ * anything if it does not. */
+int backend_gemm_s8u8s32(int transa, int transb, int m, int n, int k,
+    const signed char *A, int lda, signed char ao,
+    const unsigned char *B, int ldb, signed char bo, int *C, int ldc)
+{
+    gemm_s8u8s32_func f = (gemm_s8u8s32_func)sym("cblas_gemm_s8u8s32");
+    int co = 0;
+    if (!f)
+        return 0;
+    f(CBLAS_COL_MAJOR, transa ? CBLAS_TRANS : CBLAS_NO_TRANS,
+        transb ? CBLAS_TRANS : CBLAS_NO_TRANS, CBLAS_FIX_OFFSET,
+        m, n, k, 1.0f, A, lda, ao, B, ldb, bo, 0.0f, C, ldc, &co);
+    return 1;
+}
@@ -0,0 +1,188 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/blas package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package blas
+
+// #cgo linux LDFLAGS: -ldl
+// #include <stdlib.h>
+// #include "backend.h"
+import "C"
+import (
+	"github.com/nvcook42/linalg"
+	"runtime"
+	"strings"
+	"unsafe"
+)
+
+func init() {
+	linalg.RegisterBackend(probeBackend)
+}
+
+func hasSymbol(name string) bool {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	return C.backend_has_symbol(cname) != 0
+}
+
+// Inspect the linked library. Called once by linalg.BackendInfo.
+func probeBackend() linalg.Backend {
+	var buf [256]C.char
+	var parallel, threads C.int
+	b := linalg.Backend{Threading: "unknown"}
+
+	kind := C.backend_query(&buf[0], C.int(len(buf)), &parallel, &threads)
+	info := C.GoString(&buf[0])
+	switch kind {
+	case C.BACKEND_OPENBLAS:
+		// config string is "OpenBLAS 0.3.21 DYNAMIC_ARCH ..."
+		b.Name = "OpenBLAS"
+		if f := strings.Fields(info); len(f) > 1 {
+			b.Version = f[1]
+		}
+		if strings.Contains(info, "USE64BITINT") {
+			b.Extensions = append(b.Extensions, linalg.ExtILP64)
+		}
+	case C.BACKEND_MKL:
+		b.Name = "MKL"
+		b.Version = strings.TrimSpace(info)
+	case C.BACKEND_BLIS:
+		b.Name = "BLIS"
+		b.Version = info
+	case C.BACKEND_ATLAS:
+		b.Name = "ATLAS"
+	default:
+		if runtime.GOOS == "darwin" {
+			b.Name = "Accelerate"
+		} else {
+			b.Name = "reference"
+			b.Threading = "sequential"
+		}
+	}
+	switch parallel {
+	case 0:
+		b.Threading = "sequential"
+	case 1:
+		b.Threading = "pthreads"
+	case 2:
+		b.Threading = "openmp"
+	}
+	b.Threads = int(threads)
+	if b.Threading == "sequential" && b.Threads == 0 {
+		b.Threads = 1
+	}
+	if hasSymbol("cblas_dgemm_batch") || hasSymbol("dgemm_batch_") {
+		b.Extensions = append(b.Extensions, linalg.ExtBatched)
+	}
+	if hasSymbol("zgemm3m_") {
+		b.Extensions = append(b.Extensions, linalg.ExtGemm3m)
+	}
+	if hasSymbol("dgemmt_") {
+		b.Extensions = append(b.Extensions, linalg.ExtGemmt)
+	}
+	if hasSymbol("cblas_gemm_s8u8s32") {
+		b.Extensions = append(b.Extensions, linalg.ExtInt8)
+	}
+	return b
+}
+
+// Compute the uplo triangle of C := alpha*op(A)*op(B) + beta*C with the
+// library's dgemmt. Returns false, with C unchanged, if the library does
+// not provide it.
+func nativeDgemmt(uplo, transA, transB string, N, K int, alpha float64, A []float64, lda int,
+	B []float64, ldb int, beta float64, C []float64, ldc int) bool {
+
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	ctransA := C.CString(transA)
+	defer C.free(unsafe.Pointer(ctransA))
+	ctransB := C.CString(transB)
+	defer C.free(unsafe.Pointer(ctransB))
+
+	// protect against index out of bounds panics
+	var aptr, bptr *float64 = nil, nil
+	if len(A) > 0 {
+		aptr = &A[0]
+	}
+	if len(B) > 0 {
+		bptr = &B[0]
+	}
+	return C.backend_dgemmt(cuplo, ctransA, ctransB,
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.int)(unsafe.Pointer(&K)),
+		(*C.double)(unsafe.Pointer(&alpha)),
+		(*C.double)(unsafe.Pointer(aptr)),
+		(*C.int)(unsafe.Pointer(&lda)),
+		(*C.double)(unsafe.Pointer(bptr)),
+		(*C.int)(unsafe.Pointer(&ldb)),
+		(*C.double)(unsafe.Pointer(&beta)),
+		(*C.double)(unsafe.Pointer(&C[0])),
+		(*C.int)(unsafe.Pointer(&ldc))) != 0
+}
+
+// Compute C := alpha*op(A)*op(B) + beta*C with the library's zgemm3m,
+// which forms each complex product with three real multiplications.
+// Returns false, with C unchanged, if the library does not provide it.
+func nativeZgemm3m(transA, transB string, M, N, K int, alpha complex128, A []complex128, lda int,
+	B []complex128, ldb int, beta complex128, C []complex128, ldc int) bool {
+
+	ctransA := C.CString(transA)
+	defer C.free(unsafe.Pointer(ctransA))
+	ctransB := C.CString(transB)
+	defer C.free(unsafe.Pointer(ctransB))
+
+	// protect against index out of bounds panics
+	var aptr, bptr *complex128 = nil, nil
+	if len(A) > 0 {
+		aptr = &A[0]
+	}
+	if len(B) > 0 {
+		bptr = &B[0]
+	}
+	return C.backend_zgemm3m(ctransA, ctransB,
+		(*C.int)(unsafe.Pointer(&M)),
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.int)(unsafe.Pointer(&K)),
+		unsafe.Pointer(&alpha),
+		unsafe.Pointer(aptr),
+		(*C.int)(unsafe.Pointer(&lda)),
+		unsafe.Pointer(bptr),
+		(*C.int)(unsafe.Pointer(&ldb)),
+		unsafe.Pointer(&beta),
+		unsafe.Pointer(&C[0]),
+		(*C.int)(unsafe.Pointer(&ldc))) != 0
+}
+
+// Compute C := (op(A) + ao)*(op(B) + bo) with the library's
+// cblas_gemm_s8u8s32, which takes B unsigned. Returns false, with C
+// unchanged, if the library does not provide it.
+func nativeGemmS8U8S32(transA, transB bool, M, N, K int, A []int8, lda int, ao int8,
+	B []uint8, ldb int, bo int8, C []int32, ldc int) bool {
+
+	var ta, tb C.int
+	if transA {
+		ta = 1
+	}
+	if transB {
+		tb = 1
+	}
+	// protect against index out of bounds panics
+	var aptr *int8 = nil
+	var bptr *uint8 = nil
+	if len(A) > 0 {
+		aptr = &A[0]
+	}
+	if len(B) > 0 {
+		bptr = &B[0]
+	}
+	return C.backend_gemm_s8u8s32(ta, tb, C.int(M), C.int(N), C.int(K),
+		(*C.schar)(unsafe.Pointer(aptr)), C.int(lda), C.schar(ao),
+		(*C.uchar)(unsafe.Pointer(bptr)), C.int(ldb), C.schar(bo),
+		(*C.int)(unsafe.Pointer(&C[0])), C.int(ldc)) != 0
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
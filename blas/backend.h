@@ -0,0 +1,22 @@
+#ifndef BACKEND_H
+#define BACKEND_H
+
+#define BACKEND_UNKNOWN  0
+#define BACKEND_OPENBLAS 1
+#define BACKEND_MKL      2
+#define BACKEND_BLIS     3
+#define BACKEND_ATLAS    4
+
+extern int backend_has_symbol(const char *name);
+extern int backend_query(char *buf, int len, int *parallel, int *threads);
+extern int backend_dgemmt(char *uplo, char *transa, char *transb, int *n, int *k,
+    double *alpha, double *A, int *lda, double *B, int *ldb,
+    double *beta, double *C, int *ldc);
+extern int backend_zgemm3m(char *transa, char *transb, int *m, int *n, int *k,
+    void *alpha, void *A, int *lda, void *B, int *ldb,
+    void *beta, void *C, int *ldc);
+extern int backend_gemm_s8u8s32(int transa, int transb, int m, int n, int k,
+    const signed char *A, int lda, signed char ao,
+    const unsigned char *B, int ldb, signed char bo, int *C, int ldc);
+
+#endif
@@ -0,0 +1,248 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/blas package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package blas
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+/*
+ Batch of general matrix-matrix products over contiguous buffers. (L3)
+
+ PURPOSE
+ Computes for i = 0, ..., batch-1
+  C[i] := alpha*op(A[i])*op(B[i]) + beta*C[i]
+ as Gemm does, where A[i] is the matrix starting at element
+ offsetA + i*strideA of the storage of A, and B[i] and C[i] likewise.
+ A stack of matrices stored back to back, eg. a tensor of shape
+ batch by k by m in column-major order, is multiplied without building a
+ matrix header per element. A stride of zero uses the same A or B for
+ every product.
+
+ A, B and C are buffers; their shape does not define the products, so m,
+ n and k must be given as options.
+
+ ARGUMENTS
+  A         float or complex matrix, the storage of the A[i]
+  B         float or complex matrix, the storage of the B[i]
+  C         float or complex matrix, the storage of the C[i]
+  alpha     number (float or complex singleton matrix)
+  beta      number (float or complex singleton matrix)
+  batch     nonnegative integer, number of products
+
+ OPTIONS
+  transA    PNoTrans, PTrans or PConjTrans
+  transB    PNoTrans, PTrans or PConjTrans
+  m         nonnegative integer, rows of op(A[i]) and C[i]
+  n         nonnegative integer, columns of op(B[i]) and C[i]
+  k         nonnegative integer, columns of op(A[i]) and rows of op(B[i])
+  ldA       positive integer.  Default rows of A[i], m or k if transposed.
+  ldB       positive integer.  Default rows of B[i], k or n if transposed.
+  ldC       positive integer.  Default m.
+  strideA   nonnegative integer.  Default ldA times columns of A[i].
+  strideB   nonnegative integer.  Default ldB times columns of B[i].
+  strideC   positive integer, at least ldC*n if batch > 1.
+            Default ldC*n.
+  offsetA   nonnegative integer
+  offsetB   nonnegative integer
+  offsetC   nonnegative integer;
+  kernel    as for Gemm, chosen for each product.
+*/
+func GemmStridedBatched(A, B, C matrix.Matrix, alpha, beta matrix.Scalar, batch int, opts ...linalg.Option) (err error) {
+	defer guard("GemmStridedBatched", &err, opts...)()
+	if err = writable("GemmStridedBatched", C); err != nil {
+		return
+	}
+	A, B = matops.Readable(A), matops.Readable(B)
+	if !matrix.EqualTypes(A, B, C) {
+		return onError(linalg.ErrType, "Parameters not of same type")
+	}
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
+		}
+		return GemmStridedBatchedFloat(A.(*matrix.FloatMatrix), B.(*matrix.FloatMatrix),
+			C.(*matrix.FloatMatrix), a, b, batch, opts...)
+	case *matrix.ComplexMatrix:
+		a, b, e := complexScalars(alpha, beta)
+		if e != nil {
+			return e
+		}
+		return GemmStridedBatchedComplex(A.(*matrix.ComplexMatrix), B.(*matrix.ComplexMatrix),
+			C.(*matrix.ComplexMatrix), a, b, batch, opts...)
+	default:
+		return onError(linalg.ErrType, "Unknown type, not implemented")
+	}
+}
+
+// See function GemmStridedBatched.
+func GemmStridedBatchedFloat(A, B, C *matrix.FloatMatrix, alpha, beta float64, batch int,
+	opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("blas.GemmStridedBatched")
+	defer op.Finish(&err)
+	defer guard("GemmStridedBatchedFloat", &err, opts...)()
+
+	ind, transA, transB, sA, sB, sC, err := checkBatchArgs(op, A, B, C, batch, opts...)
+	if err != nil || batch == 0 || ind.M == 0 || ind.N == 0 {
+		return
+	}
+	Aa := A.FloatArray()
+	Ba := B.FloatArray()
+	Ca := C.FloatArray()
+	for i := 0; i < batch; i++ {
+		err = dispatchDgemm(op, transA, transB, ind.M, ind.N, ind.K, alpha,
+			Aa[ind.OffsetA+i*sA:], ind.LDa, Ba[ind.OffsetB+i*sB:], ind.LDb, beta,
+			Ca[ind.OffsetC+i*sC:], ind.LDc, opts...)
+		if err != nil {
+			return
+		}
+	}
+	return
+}
+
+// See function GemmStridedBatched.
+func GemmStridedBatchedComplex(A, B, C *matrix.ComplexMatrix, alpha, beta complex128, batch int,
+	opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("blas.GemmStridedBatched")
+	defer op.Finish(&err)
+	defer guard("GemmStridedBatchedComplex", &err, opts...)()
+
+	ind, transA, transB, sA, sB, sC, err := checkBatchArgs(op, A, B, C, batch, opts...)
+	if err != nil || batch == 0 || ind.M == 0 || ind.N == 0 {
+		return
+	}
+	Aa := A.ComplexArray()
+	Ba := B.ComplexArray()
+	Ca := C.ComplexArray()
+	for i := 0; i < batch; i++ {
+		zgemm(transA, transB, ind.M, ind.N, ind.K, alpha,
+			Aa[ind.OffsetA+i*sA:], ind.LDa, Ba[ind.OffsetB+i*sB:], ind.LDb, beta,
+			Ca[ind.OffsetC+i*sC:], ind.LDc)
+	}
+	return
+}
+
+// Check arguments of GemmStridedBatched for matrices A, B and C and return
+// index options, transpose parameters and strides.
+func checkBatchArgs(op *linalg.Op, A, B, C matrix.Matrix, batch int, opts ...linalg.Option) (
+	ind *linalg.IndexOpts, transA, transB string, sA, sB, sC int, err error) {
+	params, err := linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind = linalg.GetIndexOpts(opts...)
+	sA, sB, sC, err = checkStridedBatch(ind, params, A.NumElements(), B.NumElements(),
+		C.NumElements(), batch, opts...)
+	if err != nil {
+		return
+	}
+	op.SetDims(batch, ind.M, ind.N, ind.K)
+	transA = linalg.ParamString(params.TransA)
+	transB = linalg.ParamString(params.TransB)
+	return
+}
+
+// Check arguments of GemmStridedBatched for buffers of sizeA, sizeB and
+// sizeC elements, set defaults of ind and return the strides.
+func checkStridedBatch(ind *linalg.IndexOpts, pars *linalg.Parameters, sizeA, sizeB, sizeC int,
+	batch int, opts ...linalg.Option) (sA, sB, sC int, err error) {
+
+	if err = checkIndexRange(ind); err != nil {
+		return
+	}
+	if batch < 0 || batch > linalg.MaxIndex {
+		return 0, 0, 0, onError(linalg.ErrParameter, "batch illegal, <0 or too large")
+	}
+	if ind.M < 0 || ind.N < 0 || ind.K < 0 {
+		return 0, 0, 0, onError(linalg.ErrParameter, "m, n and k must be given")
+	}
+	if ind.OffsetA < 0 || ind.OffsetB < 0 || ind.OffsetC < 0 {
+		return 0, 0, 0, onError(linalg.ErrParameter, "offset illegal, <0")
+	}
+	// rows and columns of the stored A[i] and B[i]
+	ra, ca := ind.M, ind.K
+	if pars.TransA != linalg.PNoTrans {
+		ra, ca = ind.K, ind.M
+	}
+	rb, cb := ind.K, ind.N
+	if pars.TransB != linalg.PNoTrans {
+		rb, cb = ind.N, ind.K
+	}
+	if ind.LDa == 0 {
+		ind.LDa = max(1, ra)
+	}
+	if ind.LDb == 0 {
+		ind.LDb = max(1, rb)
+	}
+	if ind.LDc == 0 {
+		ind.LDc = max(1, ind.M)
+	}
+	if ind.LDa < max(1, ra) {
+		return 0, 0, 0, onError(linalg.ErrParameter, "inconsistent ldA")
+	}
+	if ind.LDb < max(1, rb) {
+		return 0, 0, 0, onError(linalg.ErrParameter, "inconsistent ldB")
+	}
+	if ind.LDc < max(1, ind.M) {
+		return 0, 0, 0, onError(linalg.ErrParameter, "inconsistent ldC")
+	}
+	sA = linalg.GetIntOpt("strideA", ind.LDa*ca, opts...)
+	sB = linalg.GetIntOpt("strideB", ind.LDb*cb, opts...)
+	sC = linalg.GetIntOpt("strideC", ind.LDc*ind.N, opts...)
+	if sA < 0 || sB < 0 {
+		return 0, 0, 0, onError(linalg.ErrParameter, "strideA or strideB illegal, <0")
+	}
+	// offsets of the last element of the batch must not overflow
+	for _, s := range []int{sA, sB, sC} {
+		if s > 0 && batch > 1 && batch-1 > math.MaxInt/4/s {
+			return 0, 0, 0, onError(linalg.ErrParameter, "stride out of range")
+		}
+	}
+	// the C[i] must not overlap
+	if batch > 1 && sC < ind.LDc*ind.N {
+		return 0, 0, 0, onError(linalg.ErrParameter, "strideC illegal, C[i] overlap")
+	}
+	if ind.K == 0 {
+		// A[i] and B[i] are empty and not referenced
+		sA, sB = 0, 0
+	}
+	if batch == 0 || ind.M == 0 || ind.N == 0 {
+		return
+	}
+	// extent of the last element of the batch
+	extent := func(off, stride, ld, rows, cols int) int {
+		if rows == 0 || cols == 0 {
+			return 0
+		}
+		return off + (batch-1)*stride + (cols-1)*ld + rows
+	}
+	if ind.K > 0 {
+		if sizeA < extent(ind.OffsetA, sA, ind.LDa, ra, ca) {
+			return 0, 0, 0, onError(linalg.ErrShape, fmt.Sprintf("sizeA < %d",
+				extent(ind.OffsetA, sA, ind.LDa, ra, ca)))
+		}
+		if sizeB < extent(ind.OffsetB, sB, ind.LDb, rb, cb) {
+			return 0, 0, 0, onError(linalg.ErrShape, fmt.Sprintf("sizeB < %d",
+				extent(ind.OffsetB, sB, ind.LDb, rb, cb)))
+		}
+	}
+	if sizeC < extent(ind.OffsetC, sC, ind.LDc, ind.M, ind.N) {
+		return 0, 0, 0, onError(linalg.ErrShape, fmt.Sprintf("sizeC < %d",
+			extent(ind.OffsetC, sC, ind.LDc, ind.M, ind.N)))
+	}
+	return
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -39,6 +39,14 @@ extern int izamax_(int *n, void *x, int *incx);
 extern int idamaxsub_(int *n, double *x, int *incx, int *result);
 extern int izamaxsub_(int *n, void *x, int *incx, int *result);
 
+extern void drotg_(double *a, double *b, double *c, double *s);
+extern void drotmg_(double *d1, double *d2, double *x1, double *y1,
+    double *param);
+extern void drot_(int *n, double *x, int *incx, double *y, int *incy,
+    double *c, double *s);
+extern void drotm_(int *n, double *x, int *incx, double *y, int *incy,
+    double *param);
+
 
 /* BLAS 2 prototypes */
 extern void dgemv_(char* trans, int *m, int *n, double *alpha,
@@ -104,6 +112,16 @@ extern void dtpmv_(char *uplo, char *transa, char *diag, int *n, double *Ap,
 		   double *x, int *incx);
 extern void dtpsv_(char *uplo, char *transa, char *diag, int *n, double *Ap,
 		   double *x, int *incx);
+extern void zhpmv_(char *uplo, int *n, void *alpha, void *Ap, void *x,
+		   int *incx, void *beta, void *y, int *incy);
+extern void zhpr_(char *uplo, int *n, double *alpha, void *x,
+		  int *incx, void *ap);
+extern void zhpr2_(char *uplo, int *n, void *alpha, void *x,
+		   int *incx, void *y, int *incy, void *ap);
+extern void ztpmv_(char *uplo, char *transa, char *diag, int *n, void *Ap,
+		   void *x, int *incx);
+extern void ztpsv_(char *uplo, char *transa, char *diag, int *n, void *Ap,
+		   void *x, int *incx);
 
 /* BLAS 3 prototypes */
 extern void dgemm_(char *transa, char *transb, int *m, int *n, int *k,
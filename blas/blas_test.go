@@ -7,9 +7,15 @@
 package blas
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
 	"github.com/nvcook42/matrix"
+	"math"
+	"math/cmplx"
+	"strings"
 	"testing"
 )
 
@@ -137,6 +143,617 @@ func _TestPanic(t *testing.T) {
 	ScalFloat(X, 2.0, &linalg.IOpt{"offset", -1})
 }
 
+func TestGemmKernels(t *testing.T) {
+	A := matrix.FloatNew(3, 2, []float64{1, 2, 3, 4, 5, 6})
+	B := matrix.FloatNew(3, 4, []float64{1, 0, 2, 0, 1, 0, 3, 1, 1, 2, 2, 2})
+	for _, trans := range []linalg.Option{linalg.OptNoTrans, linalg.OptTrans} {
+		var Ag *matrix.FloatMatrix = A
+		if trans == linalg.OptNoTrans {
+			Ag = A.Transpose()
+		}
+		Cg := matrix.FloatWithValue(2, 4, 1.0)
+		Cn := Cg.Copy()
+		opts := []linalg.Option{linalg.IntOpt("transA", trans.Int())}
+		GemmFloat(Ag, B, Cg, 2.0, 0.5, append(opts, linalg.StringOpt("kernel", KernelGo))...)
+		GemmFloat(Ag, B, Cn, 2.0, 0.5, append(opts, linalg.StringOpt("kernel", KernelNative))...)
+		if !Cg.Equal(Cn) {
+			t.Logf("go:\n%v\nnative:\n%v\n", Cg, Cn)
+			t.Fail()
+		}
+	}
+	if k, _, _ := selectKernel(1e12); k != KernelNative {
+		t.Logf("no GPU registered, got %s\n", k)
+		t.Fail()
+	}
+	if _, _, err := selectKernel(1.0, linalg.StringOpt("kernel", KernelGPU)); err == nil {
+		t.Fail()
+	}
+}
+
+func TestRotm(t *testing.T) {
+	d1, _, x1, P := Rotmg(1.0, 1.0, 3.0, 4.0)
+	X := matrix.FloatNew(2, 1, []float64{3, 1})
+	Y := matrix.FloatNew(2, 1, []float64{4, 2})
+	if err := Rotm(X, Y, P); err != nil {
+		t.Fatalf("Rotm: %v\n", err)
+	}
+	t.Logf("P=%v X=%v Y=%v\n", P, X, Y)
+	if math.Abs(Y.GetAt(0, 0)) > 1e-14 || math.Abs(X.GetAt(0, 0)-x1) > 1e-14 ||
+		math.Abs(math.Sqrt(d1)*math.Abs(x1)-5.0) > 1e-14 {
+		t.Fail()
+	}
+	c, s, r, _ := Rotg(3.0, 4.0)
+	X = matrix.FloatNew(1, 1, []float64{3})
+	Y = matrix.FloatNew(1, 1, []float64{4})
+	if err := Rot(X, Y, c, s); err != nil {
+		t.Fatalf("Rot: %v\n", err)
+	}
+	if math.Abs(X.GetAt(0, 0)-r) > 1e-14 || math.Abs(Y.GetAt(0, 0)) > 1e-14 {
+		t.Logf("Rot: c=%v s=%v r=%v X=%v Y=%v\n", c, s, r, X, Y)
+		t.Fail()
+	}
+	if err := Rotm(X, Y, P[:4]); err == nil {
+		t.Logf("short P accepted\n")
+		t.Fail()
+	}
+}
+
+func TestIamax(t *testing.T) {
+	X := matrix.FloatNew(6, 1, []float64{-7, 1, 2, -0.5, 3, 9})
+	k, err := Iamax(X)
+	if err != nil || k != 5 {
+		t.Logf("Iamax: k=%d err=%v\n", k, err)
+		t.Fail()
+	}
+	// elements 1, -0.5 and 9 with offset 1 and stride 2
+	k, _ = Iamax(X, &linalg.IOpt{"offset", 1}, &linalg.IOpt{"inc", 2})
+	if k != 2 {
+		t.Logf("Iamax strided: k=%d\n", k)
+		t.Fail()
+	}
+	k, _ = Iamin(X, &linalg.IOpt{"offset", 1}, &linalg.IOpt{"inc", 2})
+	if k != 1 {
+		t.Logf("Iamin strided: k=%d\n", k)
+		t.Fail()
+	}
+	Z := matrix.ComplexNew(3, 1, []complex128{complex(3, 3), complex(-1, 0.5), complex(0, -5)})
+	if k, _ = Iamax(Z); k != 0 {
+		t.Logf("Iamax complex: k=%d\n", k)
+		t.Fail()
+	}
+	if k, _ = Iamin(Z); k != 1 {
+		t.Logf("Iamin complex: k=%d\n", k)
+		t.Fail()
+	}
+	if k, _ = Iamax(X, &linalg.IOpt{"n", 0}); k != -1 {
+		t.Logf("Iamax n=0: k=%d\n", k)
+		t.Fail()
+	}
+}
+
+func TestAsum(t *testing.T) {
+	Z := matrix.ComplexNew(3, 1, []complex128{complex(1, -2), complex(-3, 0.5), complex(0, 4)})
+	if v := Asum(Z).Float(); v != 10.5 {
+		t.Logf("Asum complex: %v\n", v)
+		t.Fail()
+	}
+	if v := Asum(Z, &linalg.IOpt{"inc", 2}).Float(); v != 7.0 {
+		t.Logf("Asum complex strided: %v\n", v)
+		t.Fail()
+	}
+	X := matrix.FloatVector([]float64{1, -2, 3})
+	if v := Asum(X, &linalg.IOpt{"n", 0}).Float(); v != 0.0 {
+		t.Logf("Asum n=0: %v\n", v)
+		t.Fail()
+	}
+	if v := Asum(X, &linalg.IOpt{"offset", 5}).Float(); !math.IsNaN(v) {
+		t.Logf("Asum bad offset: %v\n", v)
+		t.Fail()
+	}
+}
+
+func TestNrm2Scaling(t *testing.T) {
+	// agrees with the linked dnrm2 for moderate values
+	X := matrix.FloatVector([]float64{3, -4, 1e-3, 12, 0.5})
+	if v := Nrm2(X).Float(); math.Abs(v-dnrm2(5, X.FloatArray(), 1)) > 1e-14*v {
+		t.Logf("Nrm2: %v, dnrm2 %v\n", v, dnrm2(5, X.FloatArray(), 1))
+		t.Fail()
+	}
+	big, small := 0x1p1000, 0x1p-1060
+	cases := []struct {
+		x    []float64
+		want float64
+	}{
+		{[]float64{3 * big, 4 * big}, 5 * big},
+		{[]float64{3 * small, 4 * small}, 5 * small},
+		{[]float64{big, 1, small}, big},
+		{[]float64{1e-170, 1e-170, 1}, 1},
+		{[]float64{1e-160, 1e-160}, math.Sqrt2 * 1e-160},
+		{[]float64{math.MaxFloat64, 0}, math.MaxFloat64},
+		{[]float64{1, math.Inf(-1)}, math.Inf(1)},
+		{[]float64{math.Inf(1), math.NaN()}, math.NaN()},
+		{[]float64{big, math.NaN(), small}, math.NaN()},
+	}
+	for _, c := range cases {
+		v := Nrm2(matrix.FloatVector(c.x)).Float()
+		ok := math.Abs(v-c.want) <= 4e-16*c.want || math.IsNaN(v) && math.IsNaN(c.want)
+		ok = ok || math.IsInf(c.want, 1) && math.IsInf(v, 1)
+		if !ok {
+			t.Logf("Nrm2(%v) = %v, want %v\n", c.x, v, c.want)
+			t.Fail()
+		}
+	}
+	Z := matrix.ComplexNew(2, 1, []complex128{complex(3*big, 0), complex(0, -4*big)})
+	if v := Nrm2(Z).Float(); math.Abs(v-5*big) > 4e-16*5*big {
+		t.Logf("Nrm2 complex: %v\n", v)
+		t.Fail()
+	}
+}
+
+func TestBandMatrix(t *testing.T) {
+	A := matrix.FloatNew(4, 4, []float64{
+		4, 1, 0, 0,
+		2, 5, 1, 0,
+		0, 2, 6, 1,
+		0, 0, 2, 7})
+	X := matrix.FloatVector([]float64{1, -1, 2, 0.5})
+	Y0 := matrix.FloatZeros(4, 1)
+	Gemv(A, X, Y0, matrix.FScalar(1.0), matrix.FScalar(0.0))
+	B, _ := matops.NewBandMatrix(A, 1, 1)
+	Y := matrix.FloatZeros(4, 1)
+	if err := Gbmv(B, X, Y, matrix.FScalar(1.0), matrix.FScalar(0.0)); err != nil {
+		t.Fatalf("Gbmv: %v\n", err)
+	}
+	if !closeTo(Y, Y0) {
+		t.Logf("Gbmv: %v, Gemv %v\n", Y, Y0)
+		t.Fail()
+	}
+	// upper triangle as triangular band matrix; solve and multiply back
+	U, _ := matops.NewBandMatrix(A, 0, 1)
+	Z := X.Copy()
+	if err := Tbsv(U, Z); err != nil {
+		t.Fatalf("Tbsv: %v\n", err)
+	}
+	if err := Tbmv(U, Z); err != nil {
+		t.Fatalf("Tbmv: %v\n", err)
+	}
+	if !closeTo(Z, X) {
+		t.Logf("Tbmv(Tbsv(X)): %v\n", Z)
+		t.Fail()
+	}
+	if err := Tbmv(B, Z); err == nil {
+		t.Logf("Tbmv accepted band matrix with kl and ku\n")
+		t.Fail()
+	}
+}
+
+// Return true if elements of A and B differ less than 1e-12.
+func closeTo(A, B *matrix.FloatMatrix) bool {
+	a, b := A.FloatArray(), B.FloatArray()
+	for i := range a {
+		if math.Abs(a[i]-b[i]) > 1e-12 {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+func TestPacked(t *testing.T) {
+	A := matrix.FloatNew(3, 3, []float64{
+		4, 1, 2,
+		1, 5, 3,
+		2, 3, 6})
+	// lower triangle columnwise
+	Ap := matrix.FloatVector([]float64{4, 1, 2, 5, 3, 6})
+	X := matrix.FloatVector([]float64{1, -1, 2})
+	Y0 := matrix.FloatZeros(3, 1)
+	Symv(A, X, Y0, matrix.FScalar(1.0), matrix.FScalar(0.0))
+	Y := matrix.FloatZeros(3, 1)
+	if err := Spmv(Ap, X, Y, matrix.FScalar(1.0), matrix.FScalar(0.0)); err != nil {
+		t.Fatalf("Spmv: %v\n", err)
+	}
+	if !closeTo(Y, Y0) {
+		t.Logf("Spmv: %v, Symv %v\n", Y, Y0)
+		t.Fail()
+	}
+	Z := X.Copy()
+	if err := Tpsv(Ap, Z); err != nil {
+		t.Fatalf("Tpsv: %v\n", err)
+	}
+	if err := Tpmv(Ap, Z); err != nil {
+		t.Fatalf("Tpmv: %v\n", err)
+	}
+	if !closeTo(Z, X) {
+		t.Logf("Tpmv(Tpsv(X)): %v\n", Z)
+		t.Fail()
+	}
+	// rank-2 update of the packed lower triangle
+	if err := Spr2(X, X, Ap, matrix.FScalar(0.5)); err != nil {
+		t.Fatalf("Spr2: %v\n", err)
+	}
+	if err := Spr(X, Ap, matrix.FScalar(-1.0)); err != nil {
+		t.Fatalf("Spr: %v\n", err)
+	}
+	if !closeTo(Ap, matrix.FloatVector([]float64{4, 1, 2, 5, 3, 6})) {
+		t.Logf("Spr2 and Spr: %v\n", Ap)
+		t.Fail()
+	}
+	if err := Spmv(matrix.FloatZeros(5, 1), X, Y, matrix.FScalar(1.0), matrix.FScalar(0.0)); err == nil {
+		t.Logf("Spmv accepted A of 5 elements\n")
+		t.Fail()
+	}
+}
+
+func TestGerc(t *testing.T) {
+	X := matrix.ComplexNew(2, 1, []complex128{complex(1, 1), complex(0, 2)})
+	Y := matrix.ComplexNew(2, 1, []complex128{complex(2, -1), complex(1, 3)})
+	Yc := matrix.ComplexNew(2, 1, []complex128{complex(2, 1), complex(1, -3)})
+	A := matrix.ComplexZeros(2, 2)
+	Au := matrix.ComplexZeros(2, 2)
+	alpha := matrix.CScalar(complex(0.5, -1))
+	if err := Gerc(X, Y, A, alpha); err != nil {
+		t.Fatalf("Gerc: %v\n", err)
+	}
+	// X*Y^H = X*conj(Y)^T
+	if err := Geru(X, Yc, Au, alpha); err != nil {
+		t.Fatalf("Geru: %v\n", err)
+	}
+	a, au := A.ComplexArray(), Au.ComplexArray()
+	for i := range a {
+		if cmplx.Abs(a[i]-au[i]) > 1e-14 {
+			t.Logf("Gerc: %v, Geru with conj(Y): %v\n", A, Au)
+			t.Fail()
+			break
+		}
+	}
+}
+
+func TestGemmt(t *testing.T) {
+	A := matrix.FloatNew(3, 2, []float64{1, 2, 3, -1, 0.5, 2})
+	C0 := matrix.FloatZeros(3, 3)
+	Gemm(A, A, C0, matrix.FScalar(2.0), matrix.FScalar(0.0), linalg.OptTransB)
+	for _, uplo := range []linalg.Option{linalg.OptLower, linalg.OptUpper} {
+		C := matrix.FloatWithValue(3, 3, 7.0)
+		err := Gemmt(A, A, C, matrix.FScalar(2.0), matrix.FScalar(0.0), linalg.OptTransB, uplo)
+		if err != nil {
+			t.Fatalf("Gemmt: %v\n", err)
+		}
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				inside := i >= j
+				if uplo == linalg.OptUpper {
+					inside = i <= j
+				}
+				if inside && math.Abs(C.GetAt(i, j)-C0.GetAt(i, j)) > 1e-14 ||
+					!inside && C.GetAt(i, j) != 7.0 {
+					t.Logf("Gemmt %v: %v, Gemm %v\n", uplo, C, C0)
+					t.Fail()
+					return
+				}
+			}
+		}
+	}
+}
+
+func TestGemmStridedBatched(t *testing.T) {
+	// three 2 by 3 times 3 by 2 products, B shared with stride 0
+	Aa := make([]float64, 3*6)
+	for i := range Aa {
+		Aa[i] = float64(i%7) - 2.5
+	}
+	A := matrix.FloatVector(Aa)
+	B := matrix.FloatNew(3, 2, []float64{1, 0, 2, -1, 1, 0.5})
+	C := matrix.FloatZeros(4, 3)
+	err := GemmStridedBatched(A, B, C, matrix.FScalar(1.0), matrix.FScalar(0.0), 3,
+		linalg.IntOpt("m", 2), linalg.IntOpt("n", 2), linalg.IntOpt("k", 3),
+		linalg.IntOpt("strideB", 0))
+	if err != nil {
+		t.Fatalf("GemmStridedBatched: %v\n", err)
+	}
+	for i := 0; i < 3; i++ {
+		Ai := matrix.FloatNew(2, 3, Aa[6*i:6*i+6])
+		C0 := matrix.FloatZeros(2, 2)
+		Gemm(Ai, B, C0, matrix.FScalar(1.0), matrix.FScalar(0.0))
+		Ci := matrix.FloatNew(2, 2, C.FloatArray()[4*i:4*i+4])
+		if !closeTo(Ci, C0) {
+			t.Logf("product %d: %v, Gemm %v\n", i, Ci, C0)
+			t.Fail()
+		}
+	}
+	err = GemmStridedBatched(A, B, C, matrix.FScalar(1.0), matrix.FScalar(0.0), 4,
+		linalg.IntOpt("m", 2), linalg.IntOpt("n", 2), linalg.IntOpt("k", 3))
+	if err == nil {
+		t.Logf("GemmStridedBatched accepted batch beyond buffers\n")
+		t.Fail()
+	}
+}
+
+func TestGemmFloat32(t *testing.T) {
+	// long inner product whose float32 partial sums would lose the 1s
+	k := 4096
+	A := make([]float32, k)
+	B := make([]float32, k)
+	for l := range A {
+		A[l], B[l] = 1.0, 1.0
+	}
+	A[0], B[0] = 1<<24, 1.0
+	C := []float32{0.5}
+	err := GemmFloat32(A, B, C, 1.0, 2.0, linalg.IntOpt("m", 1), linalg.IntOpt("n", 1),
+		linalg.IntOpt("k", k))
+	if err != nil {
+		t.Fatalf("GemmFloat32: %v\n", err)
+	}
+	if want := float32(1<<24 + k - 1 + 1); C[0] != want {
+		t.Logf("GemmFloat32: %v, want %v\n", C[0], want)
+		t.Fail()
+	}
+	if err = GemmFloat32(A, B, C, 1.0, 0.0, linalg.IntOpt("m", 2), linalg.IntOpt("n", 1),
+		linalg.IntOpt("k", k)); err == nil {
+		t.Logf("GemmFloat32 accepted short C\n")
+		t.Fail()
+	}
+}
+
+func TestGemm3m(t *testing.T) {
+	A := matrix.ComplexNew(2, 3, []complex128{1 + 2i, -1i, 3, 0.5 - 1i, 2i, 1 + 1i})
+	B := matrix.ComplexNew(3, 2, []complex128{2, 1 - 1i, -1 + 1i, 4i, 0.5, 3 - 2i})
+	C0 := matrix.ComplexZeros(2, 2)
+	C1 := matrix.ComplexZeros(2, 2)
+	alpha, beta := matrix.CScalar(1-1i), matrix.CScalar(0)
+	if err := Gemm(A, B, C0, alpha, beta, linalg.BoolOpt("gemm3m", false)); err != nil {
+		t.Fatalf("Gemm: %v\n", err)
+	}
+	if err := Gemm(A, B, C1, alpha, beta, linalg.BoolOpt("gemm3m", true)); err != nil {
+		t.Fatalf("Gemm 3m: %v\n", err)
+	}
+	for k, c := range C1.ComplexArray() {
+		if cmplx.Abs(c-C0.ComplexArray()[k]) > 1e-13 {
+			t.Logf("3m: %v\nzgemm: %v\n", C1, C0)
+			t.Fail()
+		}
+	}
+	if useGemm3m(8, 8, 8) || !useGemm3m(8, 8, 8, linalg.BoolOpt("gemm3m", true)) {
+		t.Fail()
+	}
+}
+
+func TestGemmInt8(t *testing.T) {
+	// A 2 by 3 with zero point 1, B 3 by 2 transposed with zero point -2
+	A := []int8{1, 4, -3, 2, 127, -128}
+	B := []int8{0, 1, -2, 5, 3, -1}
+	opts := []linalg.Option{linalg.IntOpt("m", 2), linalg.IntOpt("n", 2), linalg.IntOpt("k", 3),
+		linalg.IntOpt("zeroA", 1), linalg.IntOpt("zeroB", -2), linalg.OptTransB}
+	// (A - 1) = [0 -4 126; 3 1 -129], (B^T + 2) = [2 3; 0 7; 5 1]
+	want := []int32{630, -639, 98, -113}
+	C := []int32{1, 1, 1, 1}
+	if err := GemmInt8(A, B, C, 0, opts...); err != nil {
+		t.Fatalf("GemmInt8: %v\n", err)
+	}
+	for i := range C {
+		if C[i] != want[i] {
+			t.Logf("C: %v, want %v\n", C, want)
+			t.Fail()
+		}
+	}
+	if err := GemmInt8(A, B, C, 2, opts...); err != nil || C[3] != -339 {
+		t.Logf("C: %v, err %v\n", C, err)
+		t.Fail()
+	}
+	Cf := []float32{1, 1, 1, 1}
+	if err := GemmInt8Scaled(A, B, Cf, 0.5, 1.0, opts...); err != nil || Cf[0] != 316 || Cf[2] != 50 {
+		t.Logf("Cf: %v, err %v\n", Cf, err)
+		t.Fail()
+	}
+	err := GemmInt8(A, B, C, 0, append(opts, linalg.IntOpt("zeroA", 200))...)
+	if !errors.Is(err, linalg.ErrParameter) {
+		t.Fail()
+	}
+}
+
+func TestScalarArgs(t *testing.T) {
+	A := matrix.ComplexNew(2, 2, []complex128{1 + 1i, 2, -1i, 3 - 2i})
+	X := matrix.ComplexVector([]complex128{1, 1i})
+	Y0 := matrix.ComplexVector([]complex128{1, 2})
+	Y1 := matrix.ComplexVector([]complex128{1, 2})
+	if err := Gemv(A, X, Y0, matrix.CScalar(2-1i), matrix.FScalar(0.5)); err != nil {
+		t.Fatalf("Gemv: %v\n", err)
+	}
+	if err := GemvComplex(A, X, Y1, 2-1i, 0.5); err != nil {
+		t.Fatalf("GemvComplex: %v\n", err)
+	}
+	for k, c := range Y1.ComplexArray() {
+		if cmplx.Abs(c-Y0.ComplexArray()[k]) > 1e-14 {
+			t.Logf("Gemv: %v\nGemvComplex: %v\n", Y0, Y1)
+			t.Fail()
+		}
+	}
+	// X*Y^T + Y*X^T uses Y
+	Xf := matrix.FloatVector([]float64{1, 2})
+	Yf := matrix.FloatVector([]float64{3, -1})
+	Af := matrix.FloatZeros(2, 2)
+	if err := Syr2(Xf, Yf, Af, matrix.FScalar(1.0)); err != nil || Af.GetAt(1, 0) != 5 {
+		t.Logf("Syr2: %v, err %v\n", Af, err)
+		t.Fail()
+	}
+	err := Scal(Xf, matrix.CScalar(1i))
+	if !errors.Is(err, linalg.ErrParameter) {
+		t.Logf("complex alpha for float matrix: %v\n", err)
+		t.Fail()
+	}
+	// CScalar with a zero imaginary part is still complex
+	Xc := matrix.ComplexVector([]complex128{1 + 1i, -2})
+	Yc := matrix.ComplexVector([]complex128{1, 1i})
+	if err := Scal(Xc, matrix.CScalar(2)); err != nil {
+		t.Fatalf("Scal: %v\n", err)
+	}
+	if err := Axpy(Xc, Yc, matrix.CScalar(1i)); err != nil {
+		t.Fatalf("Axpy: %v\n", err)
+	}
+	for k, c := range []complex128{-1 + 2i, -3i} {
+		if cmplx.Abs(Yc.ComplexArray()[k]-c) > 1e-14 {
+			t.Logf("Axpy: %v, want %v\n", Yc.ComplexArray(), c)
+			t.Fail()
+		}
+	}
+	err = Scal(Xf, matrix.CScalar(2))
+	if !errors.Is(err, linalg.ErrParameter) {
+		t.Logf("CScalar(2) alpha for float matrix: %v\n", err)
+		t.Fail()
+	}
+}
+
+func FuzzBlas(f *testing.F) {
+	// gemv with lda smaller than the rows, complex axpy with a too short Y
+	// and gemm with a huge offsetA
+	f.Add([]byte{5, 0, 4, 3, 3, 1, 4, 1, 5, 2})
+	f.Add([]byte{0, 1, 6, 1, 2, 1, 0, 0})
+	f.Add([]byte{3, 0, 3, 3, 3, 3, 3, 3, 14, 0xfe, 0xff, 0xff, 0xff, 0x1f})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		Fuzz(data)
+	})
+}
+
+func TestFuzzCall(t *testing.T) {
+	// X has 6 elements and Y 2; n defaults to the length of X
+	err := FuzzCall("axpy", [][2]int{{6, 1}, {2, 1}}, false)
+	if !errors.Is(err, linalg.ErrShape) {
+		t.Logf("axpy: %v\n", err)
+		t.Fail()
+	}
+	err = FuzzCall("gemm", [][2]int{{2, 2}, {2, 2}, {2, 2}}, true,
+		linalg.IntOpt("offsetC", linalg.MaxIndex*4))
+	if !errors.Is(err, linalg.ErrParameter) {
+		t.Logf("gemm: %v\n", err)
+		t.Fail()
+	}
+	for _, name := range FuzzRoutines() {
+		FuzzCall(name, [][2]int{{3, 3}, {3, 3}, {3, 3}}, false, linalg.IntOpt("n", -2))
+	}
+	// only the call with the FuzzCall option lets panics through
+	call := func(opts ...linalg.Option) (err error) {
+		defer guard("Call", &err, opts...)()
+		panic("out of range")
+	}
+	if err = call(); !errors.Is(err, linalg.ErrParameter) {
+		t.Errorf("guard without option: %v\n", err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("guard with option: no panic\n")
+			}
+		}()
+		call(noRecover)
+	}()
+}
+
+func TestAlias(t *testing.T) {
+	A := matrix.FloatNew(2, 2, []float64{2, 1, 0, 3})
+	B := matrix.FloatNew(2, 2, []float64{1, 2, 3, 4})
+	one, zero := matrix.FScalar(1.0), matrix.FScalar(0.0)
+	if err := Gemm(A, B, A, one, zero); !errors.Is(err, linalg.ErrAlias) {
+		t.Errorf("Gemm(A, B, A): %v\n", err)
+	}
+	if err := Syrk(A, A, one, zero); !errors.Is(err, linalg.ErrAlias) {
+		t.Errorf("Syrk(A, A): %v\n", err)
+	}
+	if err := Trsm(A, A, one); !errors.Is(err, linalg.ErrAlias) {
+		t.Errorf("Trsm(A, A): %v\n", err)
+	}
+	// rows of one array with the same leading index do not overlap
+	X := matrix.FloatNew(4, 2, []float64{1, 1, 0, 0, 1, 1, 0, 0})
+	err := Gemm(X, B, X, one, zero, linalg.IntOpt("m", 2), linalg.IntOpt("lda", 4),
+		linalg.IntOpt("ldc", 4), linalg.IntOpt("offsetc", 2))
+	if err != nil {
+		t.Errorf("Gemm on disjoint rows: %v\n", err)
+	}
+	if X.GetAt(2, 0) != 3 || X.GetAt(3, 1) != 7 {
+		t.Errorf("Gemm on disjoint rows: %v\n", X)
+	}
+	if err := Gemm(A, B, A, one, zero, linalg.BoolOpt("checkalias", false)); err != nil {
+		t.Errorf("Gemm(A, B, A) with checkalias false: %v\n", err)
+	}
+}
+
+// Inconsistent options that slip past the index checks and index a slice
+// out of range return an error from guard instead of panicking.
+func TestGuard(t *testing.T) {
+	A := matrix.FloatNew(3, 2, []float64{1, 1, 1, 2, 2, 2})
+	X := matrix.FloatVector([]float64{1, 1})
+	Y := matrix.FloatVector([]float64{0, 0, 0})
+	// offsetA is checked only when both m and n are nonzero
+	opts := []linalg.Option{linalg.OptConjTrans, linalg.IntOpt("m", 0), linalg.IntOpt("offseta", 100)}
+	err := Gemv(A, X, Y, matrix.FScalar(1.0), matrix.FScalar(0.0), opts...)
+	if !errors.Is(err, linalg.ErrParameter) {
+		t.Errorf("Gemv with offsetA out of range: %v\n", err)
+	}
+	// with PanicOnError(true) the panic goes through
+	func() {
+		PanicOnError(true)
+		defer PanicOnError(false)
+		defer func() {
+			if r := recover(); r == nil {
+				t.Errorf("PanicOnError(true): no panic\n")
+			}
+		}()
+		Gemv(A, X, Y, matrix.FScalar(1.0), matrix.FScalar(0.0), opts...)
+	}()
+}
+
+// Illegal arguments the native library reports to XERBLA are returned as
+// errors naming the routine and the argument.
+func TestXerbla(t *testing.T) {
+	call := func() (err error) {
+		defer guard("Gemv", &err)()
+		A, X, Y := make([]float64, 4), make([]float64, 2), make([]float64, 2)
+		// M = -1 is argument 2 of dgemv
+		dgemv("N", -1, 2, 1.0, A, 2, X, 1, 0.0, Y, 1)
+		return
+	}
+	err := call()
+	if !errors.Is(err, linalg.ErrParameter) ||
+		!strings.Contains(strings.ToUpper(err.Error()), "PARAMETER 2 TO DGEMV") {
+		t.Errorf("dgemv with M < 0: %v\n", err)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	defer SetProfile(DefaultProfile())
+	var buf bytes.Buffer
+	p := Profile{GoMaxFlops: 1000, GPUMinFlops: 1e9}
+	if err := WriteProfile(&buf, p); err != nil {
+		t.Fatalf("WriteProfile: %v\n", err)
+	}
+	if q, err := ReadProfile(&buf); err != nil || q != p || GetProfile() != p {
+		t.Errorf("ReadProfile: %v, %v\n", q, err)
+	}
+	if q := TuneProfile(4); q.GPUMinFlops != p.GPUMinFlops || q.GoMaxFlops > 2*4*4*4 {
+		t.Errorf("TuneProfile: %v\n", q)
+	}
+}
+
+func TestKron(t *testing.T) {
+	A := matrix.FloatVector([]float64{1, 2})
+	B := matrix.FloatNew(1, 2, []float64{3, 4})
+	C := matrix.FloatZeros(2, 2)
+	if err := Kron(A, B, C, matrix.FScalar(1.0)); err != nil || C.GetAt(1, 1) != 8 {
+		t.Errorf("Kron: %v %v\n", err, C)
+	}
+	// product in rows 1 and 2 of 3 by 2 C
+	C = matrix.FloatZeros(3, 2)
+	err := Kron(A, B, C, matrix.FScalar(1.0), linalg.IntOpt("ldc", 3), linalg.IntOpt("offsetc", 1))
+	if err != nil || !C.Equal(matrix.FloatNew(3, 2, []float64{0, 3, 6, 0, 4, 8})) {
+		t.Errorf("Kron with ldC and offsetC: %v %v\n", err, C)
+	}
+	err = Kron(A, B, C, matrix.FScalar(1.0), linalg.IntOpt("ldc", 3), linalg.IntOpt("offsetc", 2))
+	if !errors.Is(err, linalg.ErrShape) {
+		t.Errorf("Kron with offsetC past C: %v\n", err)
+	}
+	if err = Kron(A, B, C, matrix.FScalar(1.0)); !errors.Is(err, linalg.ErrShape) {
+		t.Errorf("Kron with 3 by 2 C: %v\n", err)
+	}
+}
+
 // Local Variables:
 // tab-width: 4
 // End:
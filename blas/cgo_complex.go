@@ -184,8 +184,8 @@ func zgbmv(transA string, M int, N int, KL int, KU int,
 	C.zgbmv_(ctransA,
 		(*C.int)(unsafe.Pointer(&M)),
 		(*C.int)(unsafe.Pointer(&N)),
-		(*C.int)(unsafe.Pointer(&KU)),
 		(*C.int)(unsafe.Pointer(&KL)),
+		(*C.int)(unsafe.Pointer(&KU)),
 		(unsafe.Pointer(&alpha)),
 		(unsafe.Pointer(&A[0])),
 		(*C.int)(unsafe.Pointer(&lda)),
@@ -388,11 +388,10 @@ func zher2(uplo string, N int, alpha complex128,
 
 }
 
-/**
 // For triangular packed matrix A and vector X compute
 // X = A * X, X = A.T * X
 func ztpmv(uplo, transA, diag string,
-		N int, Ap []complex128, X []complex128, incX int) {
+	N int, Ap []complex128, X []complex128, incX int) {
 
 	cuplo := C.CString(uplo)
 	defer C.free(unsafe.Pointer(cuplo))
@@ -403,16 +402,15 @@ func ztpmv(uplo, transA, diag string,
 
 	C.ztpmv_(cuplo, ctransA, cdiag,
 		(*C.int)(unsafe.Pointer(&N)),
-		(unsafe.Pointer(&Ap[0])), 
+		(unsafe.Pointer(&Ap[0])),
 		(unsafe.Pointer(&X[0])),
 		(*C.int)(unsafe.Pointer(&incX)))
 }
 
-
-// For triangular matrix A and vector X solve
+// For triangular packed matrix A and vector X solve
 // X = inv(A) * X or X = inv(A.T) * X
 func ztpsv(uplo, transA, diag string,
-		N int, Ap []complex128, X []complex128, incX int) {
+	N int, Ap []complex128, X []complex128, incX int) {
 
 	cuplo := C.CString(uplo)
 	defer C.free(unsafe.Pointer(cuplo))
@@ -423,24 +421,24 @@ func ztpsv(uplo, transA, diag string,
 
 	C.ztpsv_(cuplo, ctransA, cdiag,
 		(*C.int)(unsafe.Pointer(&N)),
-		(unsafe.Pointer(&Ap[0])), 
+		(unsafe.Pointer(&Ap[0])),
 		(unsafe.Pointer(&X[0])),
 		(*C.int)(unsafe.Pointer(&incX)))
 }
 
-// For symmetric packed matrix  and vector X solve
+// For hermitian packed matrix A and vector X compute
 // Y = alpha * A * X + beta * Y
-func zspmv(uplo string, N int, alpha complex128,
+func zhpmv(uplo string, N int, alpha complex128,
 	Ap []complex128, X []complex128, incX int, beta complex128,
 	Y []complex128, incY int) {
 
 	cuplo := C.CString(uplo)
 	defer C.free(unsafe.Pointer(cuplo))
 
-	C.zspmv_(cuplo,
+	C.zhpmv_(cuplo,
 		(*C.int)(unsafe.Pointer(&N)),
 		(unsafe.Pointer(&alpha)),
-		(unsafe.Pointer(&Ap[0])), 
+		(unsafe.Pointer(&Ap[0])),
 		(unsafe.Pointer(&X[0])),
 		(*C.int)(unsafe.Pointer(&incX)),
 		(unsafe.Pointer(&beta)),
@@ -449,28 +447,28 @@ func zspmv(uplo string, N int, alpha complex128,
 
 }
 
-func zspr(uplo string, N int, alpha complex128,
-		X []complex128, incX int, Ap []complex128) {
+func zhpr(uplo string, N int, alpha float64,
+	X []complex128, incX int, Ap []complex128) {
 
 	cuplo := C.CString(uplo)
 	defer C.free(unsafe.Pointer(cuplo))
 
-	C.zspr_(cuplo,
+	C.zhpr_(cuplo,
 		(*C.int)(unsafe.Pointer(&N)),
-		(unsafe.Pointer(&alpha)),
+		(*C.double)(unsafe.Pointer(&alpha)),
 		(unsafe.Pointer(&X[0])),
 		(*C.int)(unsafe.Pointer(&incX)),
 		(unsafe.Pointer(&Ap[0])))
 
 }
 
-func zspr2(uplo string, N int, alpha complex128,
+func zhpr2(uplo string, N int, alpha complex128,
 	X []complex128, incX int, Y []complex128, incY int, Ap []complex128) {
 
 	cuplo := C.CString(uplo)
 	defer C.free(unsafe.Pointer(cuplo))
 
-	C.zspr2_(cuplo,
+	C.zhpr2_(cuplo,
 		(*C.int)(unsafe.Pointer(&N)),
 		(unsafe.Pointer(&alpha)),
 		(unsafe.Pointer(&X[0])),
@@ -480,7 +478,6 @@ func zspr2(uplo string, N int, alpha complex128,
 		(unsafe.Pointer(&Ap[0])))
 
 }
-**/
 
 // ===========================================================================
 // BLAS level 3
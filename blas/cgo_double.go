@@ -95,24 +95,24 @@ func dscal(N int, alpha float64, X []float64, incX int) {
 		(*C.int)(unsafe.Pointer(&incX)))
 }
 
-/* ------------------------------------------------------------------
- * left out for the time being ....
-
-func drotg(a, b, c, d *float64) {
+// void drotg_(double *a, double *b, double *c, double *s);
+func drotg(a, b, c, s *float64) {
 	C.drotg_((*C.double)(unsafe.Pointer(a)),
 		(*C.double)(unsafe.Pointer(b)),
 		(*C.double)(unsafe.Pointer(c)),
-		(*C.double)(unsafe.Pointer(d)))
+		(*C.double)(unsafe.Pointer(s)))
 }
 
-func drotmg(d1, d2, b1 *float64, b2 float64, P []float64) {
+// void drotmg_(double *d1, double *d2, double *x1, double *y1, double *param);
+func drotmg(d1, d2, x1 *float64, y1 float64, P []float64) {
 	C.drotmg_((*C.double)(unsafe.Pointer(d1)),
 		(*C.double)(unsafe.Pointer(d2)),
-		(*C.double)(unsafe.Pointer(b1)),
-		(*C.double)(unsafe.Pointer(&b2)),
+		(*C.double)(unsafe.Pointer(x1)),
+		(*C.double)(unsafe.Pointer(&y1)),
 		(*C.double)(unsafe.Pointer(&P[0])))
 }
 
+// void drot_(int *n, double *x, int *incx, double *y, int *incy, double *c, double *s);
 func drot(N int, X []float64, incX int, Y []float64, incY int, c, s float64) {
 	C.drot_((*C.int)(unsafe.Pointer(&N)),
 		(*C.double)(unsafe.Pointer(&X[0])),
@@ -123,15 +123,15 @@ func drot(N int, X []float64, incX int, Y []float64, incY int, c, s float64) {
 		(*C.double)(unsafe.Pointer(&s)))
 }
 
+// void drotm_(int *n, double *x, int *incx, double *y, int *incy, double *param);
 func drotm(N int, X []float64, incX int, Y []float64, incY int, P []float64) {
 	C.drotm_((*C.int)(unsafe.Pointer(&N)),
 		(*C.double)(unsafe.Pointer(&X[0])),
 		(*C.int)(unsafe.Pointer(&incX)),
 		(*C.double)(unsafe.Pointer(&Y[0])),
 		(*C.int)(unsafe.Pointer(&incY)),
-		(*C.double)(unsafe.Pointer(&Y[0])))
+		(*C.double)(unsafe.Pointer(&P[0])))
 }
-*/
 
 // ===========================================================================
 // BLAS level 2
@@ -175,8 +175,8 @@ func dgbmv(transA string, M int, N int, KL int, KU int,
 	C.dgbmv_(ctransA,
 		(*C.int)(unsafe.Pointer(&M)),
 		(*C.int)(unsafe.Pointer(&N)),
-		(*C.int)(unsafe.Pointer(&KU)),
 		(*C.int)(unsafe.Pointer(&KL)),
+		(*C.int)(unsafe.Pointer(&KU)),
 		(*C.double)(unsafe.Pointer(&alpha)),
 		(*C.double)(unsafe.Pointer(&A[0])),
 		(*C.int)(unsafe.Pointer(&lda)),
@@ -0,0 +1,261 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/blas package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package blas
+
+import (
+	"encoding/json"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kernels for the 'kernel' option of Gemm and GemmFloat. Only float Gemm
+// and the products built on it (GemmStridedBatched, GemmInt8) dispatch;
+// complex Gemm and the other level 3 routines always call the linked
+// library and ignore the option.
+const (
+	KernelAuto   = "auto"   // choose by problem size with the active Profile
+	KernelGo     = "go"     // pure Go loops, no cgo call overhead
+	KernelNative = "native" // the linked BLAS library
+	KernelGPU    = "gpu"    // the backend registered with RegisterGPU
+)
+
+// Problem size thresholds for automatic kernel selection, in floating
+// point operations (2*m*n*k for Gemm). Problems of at most GoMaxFlops run
+// in pure Go, where the cgo call and the library's setup cost more than
+// the arithmetic. Problems of at least GPUMinFlops run on the GPU backend
+// if one is registered, where the transfer cost is amortized. Everything
+// in between uses the native BLAS.
+//
+// The defaults are conservative. TuneProfile measures the Go and native
+// crossover on the target machine; save its result with WriteProfile and
+// install it at start up with ReadProfile. GPUMinFlops is not measured and
+// is set by the user for the registered backend.
+type Profile struct {
+	GoMaxFlops  float64 `json:"go_max_flops"`
+	GPUMinFlops float64 `json:"gpu_min_flops"`
+}
+
+// Double precision GEMM on a GPU, with the argument conventions of dgemm.
+// Implementations copy the operands to the device and C back.
+type GPU interface {
+	Name() string
+	Dgemm(transA, transB string, M, N, K int, alpha float64, A []float64, lda int,
+		B []float64, ldb int, beta float64, C []float64, ldc int) error
+}
+
+var (
+	dispatchMu sync.RWMutex
+	profile    = DefaultProfile()
+	gpu        GPU
+)
+
+// Return default profile: products of up to 16x16 matrices in Go and of
+// at least 1024x1024 matrices on the GPU.
+func DefaultProfile() Profile {
+	return Profile{GoMaxFlops: 2 * 16 * 16 * 16, GPUMinFlops: 2 * 1024 * 1024 * 1024}
+}
+
+// Set the profile used for automatic kernel selection.
+func SetProfile(p Profile) {
+	dispatchMu.Lock()
+	defer dispatchMu.Unlock()
+	profile = p
+}
+
+// Return the active profile.
+func GetProfile() Profile {
+	dispatchMu.RLock()
+	defer dispatchMu.RUnlock()
+	return profile
+}
+
+// Read profile as JSON object with fields go_max_flops and gpu_min_flops,
+// as written by WriteProfile or by hand, and make it active. Missing fields
+// keep their default values.
+func ReadProfile(r io.Reader) (Profile, error) {
+	p := DefaultProfile()
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return p, linalg.NewError(linalg.ErrParameter, "ReadProfile: "+err.Error())
+	}
+	SetProfile(p)
+	return p, nil
+}
+
+// Write profile p as JSON object read by ReadProfile.
+func WriteProfile(w io.Writer, p Profile) error {
+	return json.NewEncoder(w).Encode(p)
+}
+
+/*
+ Measure the float products for which the Go kernel is faster than the
+ native BLAS on this machine and return the active profile with GoMaxFlops
+ set to the largest such product. Square products of order 1, 2, ...,
+ maxn are timed with both kernels until the native one wins; each timing
+ runs for about 10 milliseconds. GPUMinFlops is returned unchanged.
+ The profile is not installed, see SetProfile and WriteProfile.
+*/
+func TuneProfile(maxn int) Profile {
+	p := GetProfile()
+	p.GoMaxFlops = 0.0
+	for n := 1; n <= maxn; n++ {
+		A := matrix.FloatWithValue(n, n, 1.0)
+		B := matrix.FloatWithValue(n, n, 1.0)
+		C := matrix.FloatZeros(n, n)
+		if timeGemm(A, B, C, KernelGo) > timeGemm(A, B, C, KernelNative) {
+			break
+		}
+		p.GoMaxFlops = 2.0 * float64(n) * float64(n) * float64(n)
+	}
+	return p
+}
+
+// Return mean time of GemmFloat on A, B and C with kernel.
+func timeGemm(A, B, C *matrix.FloatMatrix, kernel string) time.Duration {
+	opt := linalg.StringOpt("kernel", kernel)
+	start := time.Now()
+	reps := 0
+	for reps == 0 || time.Since(start) < 10*time.Millisecond {
+		GemmFloat(A, B, C, 1.0, 0.0, opt)
+		reps++
+	}
+	return time.Since(start) / time.Duration(reps)
+}
+
+// Register GPU backend; nil removes the registered backend.
+func RegisterGPU(g GPU) {
+	dispatchMu.Lock()
+	defer dispatchMu.Unlock()
+	gpu = g
+}
+
+// Return kernel to use for a problem of flops operations, honoring the
+// 'kernel' option, and the GPU backend if that is chosen. Requesting the
+// GPU kernel without a registered backend is an error.
+func selectKernel(flops float64, opts ...linalg.Option) (string, GPU, error) {
+	dispatchMu.RLock()
+	p, g := profile, gpu
+	dispatchMu.RUnlock()
+	switch k := strings.ToLower(linalg.GetStringOpt("kernel", KernelAuto, opts...)); k {
+	case KernelGo, KernelNative:
+		return k, nil, nil
+	case KernelGPU:
+		if g == nil {
+			return "", nil, onError(linalg.ErrParameter, "kernel: no GPU backend registered")
+		}
+		return k, g, nil
+	case KernelAuto:
+	default:
+		return "", nil, onError(linalg.ErrParameter, "kernel: unknown kernel "+k)
+	}
+	switch {
+	case flops <= p.GoMaxFlops:
+		return KernelGo, nil, nil
+	case g != nil && flops >= p.GPUMinFlops:
+		return KernelGPU, g, nil
+	}
+	return KernelNative, nil, nil
+}
+
+// Compute C := alpha*op(A)*op(B) + beta*C with the kernel chosen by
+// selectKernel. Arguments are as for dgemm and have been checked.
+func dispatchDgemm(op *linalg.Op, transA, transB string, M, N, K int, alpha float64, A []float64, lda int,
+	B []float64, ldb int, beta float64, C []float64, ldc int, opts ...linalg.Option) error {
+	kernel, g, err := selectKernel(2*float64(M)*float64(N)*float64(K), opts...)
+	if err != nil {
+		return err
+	}
+	switch kernel {
+	case KernelGo:
+		op.SetBackend(KernelGo)
+		goDgemm(transA[0] != 'N', transB[0] != 'N', M, N, K, alpha, A, lda, B, ldb, beta, C, ldc)
+	case KernelGPU:
+		op.SetBackend(g.Name())
+		return g.Dgemm(transA, transB, M, N, K, alpha, A, lda, B, ldb, beta, C, ldc)
+	default:
+		dgemm(transA, transB, M, N, K, alpha, A, lda, B, ldb, beta, C, ldc)
+	}
+	return nil
+}
+
+// Smallest m, n and k for which complex Gemm uses zgemm3m automatically.
+// The 3M scheme saves a quarter of the multiplications but adds matrix
+// additions that dominate for small or thin products.
+const gemm3mMinDim = 128
+
+// Test if complex product of size m, n, k should use zgemm3m: as the
+// 'gemm3m' option says if given, otherwise if the backend provides it and
+// the product is large in all dimensions.
+func useGemm3m(M, N, K int, opts ...linalg.Option) bool {
+	if o := linalg.GetOption("gemm3m", opts...); o != nil {
+		return o.Bool()
+	}
+	return M >= gemm3mMinDim && N >= gemm3mMinDim && K >= gemm3mMinDim &&
+		linalg.BackendInfo().Has(linalg.ExtGemm3m)
+}
+
+// Compute complex C := alpha*op(A)*op(B) + beta*C with zgemm3m if
+// useGemm3m says so and the library has it, otherwise with zgemm.
+func dispatchZgemm(op *linalg.Op, transA, transB string, M, N, K int, alpha complex128, A []complex128, lda int,
+	B []complex128, ldb int, beta complex128, C []complex128, ldc int, opts ...linalg.Option) {
+	if useGemm3m(M, N, K, opts...) {
+		if nativeZgemm3m(transA, transB, M, N, K, alpha, A, lda, B, ldb, beta, C, ldc) {
+			op.SetBackend(linalg.ExtGemm3m)
+			return
+		}
+		op.Warn("gemm3m: not provided by the backend, using zgemm")
+	}
+	zgemm(transA, transB, M, N, K, alpha, A, lda, B, ldb, beta, C, ldc)
+}
+
+// Pure Go dgemm for small problems.
+func goDgemm(transA, transB bool, M, N, K int, alpha float64, A []float64, lda int,
+	B []float64, ldb int, beta float64, C []float64, ldc int) {
+	for j := 0; j < N; j++ {
+		c := C[j*ldc : j*ldc+M]
+		if beta == 0.0 {
+			for i := range c {
+				c[i] = 0.0
+			}
+		} else if beta != 1.0 {
+			for i := range c {
+				c[i] *= beta
+			}
+		}
+		if alpha == 0.0 {
+			continue
+		}
+		for l := 0; l < K; l++ {
+			// b = alpha*op(B)[l, j]
+			b := B[j*ldb+l]
+			if transB {
+				b = B[l*ldb+j]
+			}
+			b *= alpha
+			if b == 0.0 {
+				continue
+			}
+			if !transA {
+				a := A[l*lda : l*lda+M]
+				for i := range c {
+					c[i] += b * a[i]
+				}
+			} else {
+				for i := range c {
+					c[i] += b * A[i*lda+l]
+				}
+			}
+		}
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -18,4 +18,22 @@
 // arguments (dimensions and options) have the same meaning as in
 // the BLAS definition.  Default values of the dimension arguments
 // are derived from the matrix sizes.
+//
+// Functions with float or complex arguments, eg. Gemv, take alpha and beta
+// as matrix.Scalar and call the typed function for the matrix type, eg.
+// GemvFloat with float64 or GemvComplex with complex128 scalars, which can
+// also be called directly. Real scalars are accepted for complex matrices;
+// complex scalars for float matrices are an error. The scalars are read as
+// linalg.Scalar, which can also be passed as alpha and beta, and missing
+// or NaN scalars are errors wrapping linalg.ErrParameter.
+//
+// The package replaces the library error handler XERBLA. An illegal
+// argument detected by the native library is returned as an error wrapping
+// linalg.ErrParameter instead of terminating the program. Panics caused by
+// arguments inconsistent with their options are likewise returned as errors,
+// unless PanicOnError(true) is in effect.
+//
+// Gemm, Syrk, Herk and Trsm return an error wrapping linalg.ErrAlias if
+// the output matrix shares elements with an input matrix, eg. Gemm(A, B, A).
+// Option linalg.BoolOpt("checkalias", false) skips the check.
 package blas
@@ -0,0 +1,202 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/blas package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package blas
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"sort"
+)
+
+// Largest number of elements of a matrix allocated by FuzzCall.
+const fuzzMaxElements = 1 << 20
+
+// Wrappers called by FuzzCall with argument matrices m[0], m[1], m[2].
+var fuzzRoutines = map[string]func(m []matrix.Matrix, opts ...linalg.Option) error{
+	"scal": func(m []matrix.Matrix, opts ...linalg.Option) error { return Scal(m[0], fuzzAlpha, opts...) },
+	"axpy": func(m []matrix.Matrix, opts ...linalg.Option) error { return Axpy(m[0], m[1], fuzzAlpha, opts...) },
+	"swap": func(m []matrix.Matrix, opts ...linalg.Option) error { return Swap(m[0], m[1], opts...) },
+	"copy": func(m []matrix.Matrix, opts ...linalg.Option) error { return Copy(m[0], m[1], opts...) },
+	"iamax": func(m []matrix.Matrix, opts ...linalg.Option) error {
+		_, err := Iamax(m[0], opts...)
+		return err
+	},
+	"gemv": func(m []matrix.Matrix, opts ...linalg.Option) error {
+		return Gemv(m[0], m[1], m[2], fuzzAlpha, fuzzBeta, opts...)
+	},
+	"gbmv": func(m []matrix.Matrix, opts ...linalg.Option) error {
+		return Gbmv(m[0], m[1], m[2], fuzzAlpha, fuzzBeta, opts...)
+	},
+	"hemv": func(m []matrix.Matrix, opts ...linalg.Option) error {
+		return Hemv(m[0], m[1], m[2], fuzzAlpha, fuzzBeta, opts...)
+	},
+	"hbmv": func(m []matrix.Matrix, opts ...linalg.Option) error {
+		return Hbmv(m[0], m[1], m[2], fuzzAlpha, fuzzBeta, opts...)
+	},
+	"hpmv": func(m []matrix.Matrix, opts ...linalg.Option) error {
+		return Hpmv(m[0], m[1], m[2], fuzzAlpha, fuzzBeta, opts...)
+	},
+	"trmv": func(m []matrix.Matrix, opts ...linalg.Option) error { return Trmv(m[0], m[1], opts...) },
+	"tbmv": func(m []matrix.Matrix, opts ...linalg.Option) error { return Tbmv(m[0], m[1], opts...) },
+	"tpmv": func(m []matrix.Matrix, opts ...linalg.Option) error { return Tpmv(m[0], m[1], opts...) },
+	"trsv": func(m []matrix.Matrix, opts ...linalg.Option) error { return Trsv(m[0], m[1], opts...) },
+	"tbsv": func(m []matrix.Matrix, opts ...linalg.Option) error { return Tbsv(m[0], m[1], opts...) },
+	"tpsv": func(m []matrix.Matrix, opts ...linalg.Option) error { return Tpsv(m[0], m[1], opts...) },
+	"geru": func(m []matrix.Matrix, opts ...linalg.Option) error { return Geru(m[0], m[1], m[2], fuzzAlpha, opts...) },
+	"gerc": func(m []matrix.Matrix, opts ...linalg.Option) error { return Gerc(m[0], m[1], m[2], fuzzAlpha, opts...) },
+	"her":  func(m []matrix.Matrix, opts ...linalg.Option) error { return Her(m[0], m[1], fuzzAlpha, opts...) },
+	"her2": func(m []matrix.Matrix, opts ...linalg.Option) error { return Her2(m[0], m[1], m[2], fuzzAlpha, opts...) },
+	"hpr":  func(m []matrix.Matrix, opts ...linalg.Option) error { return Hpr(m[0], m[1], fuzzAlpha, opts...) },
+	"hpr2": func(m []matrix.Matrix, opts ...linalg.Option) error { return Hpr2(m[0], m[1], m[2], fuzzAlpha, opts...) },
+	"gemm": func(m []matrix.Matrix, opts ...linalg.Option) error {
+		return Gemm(m[0], m[1], m[2], fuzzAlpha, fuzzBeta, opts...)
+	},
+	"symm": func(m []matrix.Matrix, opts ...linalg.Option) error {
+		return Symm(m[0], m[1], m[2], fuzzAlpha, fuzzBeta, opts...)
+	},
+	"hemm": func(m []matrix.Matrix, opts ...linalg.Option) error {
+		return Hemm(m[0], m[1], m[2], fuzzAlpha, fuzzBeta, opts...)
+	},
+	"syrk": func(m []matrix.Matrix, opts ...linalg.Option) error {
+		return Syrk(m[0], m[1], fuzzAlpha, fuzzBeta, opts...)
+	},
+	"herk": func(m []matrix.Matrix, opts ...linalg.Option) error {
+		return Herk(m[0], m[1], fuzzAlpha, fuzzBeta, opts...)
+	},
+	"syr2k": func(m []matrix.Matrix, opts ...linalg.Option) error {
+		return Syr2k(m[0], m[1], m[2], fuzzAlpha, fuzzBeta, opts...)
+	},
+	"her2k": func(m []matrix.Matrix, opts ...linalg.Option) error {
+		return Her2k(m[0], m[1], m[2], fuzzAlpha, fuzzBeta, opts...)
+	},
+	"trmm": func(m []matrix.Matrix, opts ...linalg.Option) error { return Trmm(m[0], m[1], fuzzAlpha, opts...) },
+	"trsm": func(m []matrix.Matrix, opts ...linalg.Option) error { return Trsm(m[0], m[1], fuzzAlpha, opts...) },
+	"gemmt": func(m []matrix.Matrix, opts ...linalg.Option) error {
+		return Gemmt(m[0], m[1], m[2], fuzzAlpha, fuzzBeta, opts...)
+	},
+	"kron": func(m []matrix.Matrix, opts ...linalg.Option) error { return Kron(m[0], m[1], m[2], fuzzAlpha, opts...) },
+}
+
+var (
+	fuzzAlpha = matrix.FScalar(1.0)
+	fuzzBeta  = matrix.FScalar(0.5)
+	// names of fuzzRoutines in order, for selecting one with a byte
+	fuzzNames = sortedFuzzNames()
+	// option names for Fuzz, selected with a byte
+	fuzzOptNames = []string{"m", "n", "k", "kl", "ku", "lda", "ldb", "ldc", "inc", "incx", "incy",
+		"offset", "offsetx", "offsety", "offseta", "offsetb", "offsetc", "nx", "ny",
+		"trans", "transa", "transb", "uplo", "diag", "side"}
+)
+
+func sortedFuzzNames() []string {
+	names := make([]string, 0, len(fuzzRoutines))
+	for name := range fuzzRoutines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Return names of the wrappers FuzzCall can call.
+func FuzzRoutines() []string {
+	return append([]string{}, fuzzNames...)
+}
+
+/*
+ Call wrapper name (lower case, as "gemv", see FuzzRoutines) with newly
+ allocated matrices of sizes shapes and options opts.
+
+ Shapes give rows and columns of the matrix arguments in the order of the
+ wrapper's signature; missing shapes are 0 by 0. Matrices are complex if
+ cplx is set, and hold small nonzero values. Scalar arguments are 1.0 and
+ 0.5.
+
+ Any shapes and options must give a nil or a linalg error, never a panic
+ or a write outside the argument matrices. Panics of the wrapper are not
+ turned into errors during the call, so that a fuzzer reports them; other
+ calls of the package, also concurrent ones, are not affected.
+*/
+func FuzzCall(name string, shapes [][2]int, cplx bool, opts ...linalg.Option) error {
+	call, ok := fuzzRoutines[name]
+	if !ok {
+		return onError(linalg.ErrParameter, "FuzzCall: unknown routine "+name)
+	}
+	if len(shapes) > 3 {
+		return onError(linalg.ErrParameter, "FuzzCall: more than 3 shapes")
+	}
+	m := make([]matrix.Matrix, 3)
+	for i := range m {
+		var r, c int
+		if i < len(shapes) {
+			r, c = shapes[i][0], shapes[i][1]
+		}
+		if r < 0 || c < 0 || (c > 0 && r > fuzzMaxElements/c) {
+			return onError(linalg.ErrShape, fmt.Sprintf("FuzzCall: shape %dx%d", r, c))
+		}
+		if cplx {
+			A := matrix.ComplexZeros(r, c)
+			for k := range A.ComplexArray() {
+				A.ComplexArray()[k] = complex(float64(k%7+1), float64(k%3))
+			}
+			m[i] = A
+		} else {
+			A := matrix.FloatZeros(r, c)
+			for k := range A.FloatArray() {
+				A.FloatArray()[k] = float64(k%7 + 1)
+			}
+			m[i] = A
+		}
+	}
+	err := call(m, append(opts[:len(opts):len(opts)], noRecover)...)
+	if e := (*linalg.Error)(nil); err != nil && !errors.As(err, &e) {
+		panic(fmt.Sprintf("FuzzCall %s: error %v is not a linalg error", name, err))
+	}
+	return err
+}
+
+/*
+ Decode a call from fuzzer input and run it with FuzzCall.
+
+ Byte 0 selects the routine, bit 0 of byte 1 complex matrices, bytes 2
+ to 7 rows and columns of three matrices modulo 32, and the rest options:
+ a byte selecting an index or parameter name and a varint value, repeated.
+ Short input is padded with zeros. Use as
+
+	func FuzzBlas(f *testing.F) {
+		f.Fuzz(func(t *testing.T, data []byte) { blas.Fuzz(data) })
+	}
+*/
+func Fuzz(data []byte) error {
+	head := make([]byte, 8)
+	copy(head, data)
+	name := fuzzNames[int(head[0])%len(fuzzNames)]
+	shapes := make([][2]int, 3)
+	for i := range shapes {
+		shapes[i] = [2]int{int(head[2+2*i] % 32), int(head[3+2*i] % 32)}
+	}
+	var opts []linalg.Option
+	var rest []byte
+	if len(data) > 8 {
+		rest = data[8:]
+	}
+	for len(rest) > 1 {
+		v, n := binary.Varint(rest[1:])
+		if n <= 0 {
+			break
+		}
+		opts = append(opts, linalg.IntOpt(fuzzOptNames[int(rest[0])%len(fuzzOptNames)], int(v)))
+		rest = rest[1+n:]
+	}
+	return FuzzCall(name, shapes, head[1]&1 != 0, opts...)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,164 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/blas package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package blas
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+)
+
+/*
+ General matrix-matrix product updating one triangle of the result. (L3)
+
+ PURPOSE
+ Computes the lower or upper triangle of
+  C := alpha*op(A)*op(B) + beta*C
+ with op(X) = X or X^T as in Gemm and C n by n; the other triangle of C
+ is not referenced. Use it when the product is known to be symmetric,
+ eg. A*D*A^T, to save about half the flops of Gemm.
+
+ The library's dgemmt is used if it provides one (MKL, OpenBLAS 0.3.22 and
+ later; see linalg.ExtGemmt), otherwise a pure Go loop.
+
+ ARGUMENTS
+  A         float matrix, n*k
+  B         float matrix, k*n
+  C         float matrix, n*n
+  alpha     number (float or complex singleton matrix)
+  beta      number (float or complex singleton matrix)
+
+ OPTIONS
+  uplo      PLower or PUpper
+  transA    PNoTrans or PTrans
+  transB    PNoTrans or PTrans
+  n         integer.  If negative, the default value is used.
+  k         integer.  If negative, the default value is used.
+  ldA       nonnegative integer.  If zero, the default value is used.
+  ldB       nonnegative integer.  If zero, the default value is used.
+  ldC       nonnegative integer.  If zero, the default value is used.
+  offsetA   nonnegative integer
+  offsetB   nonnegative integer
+  offsetC   nonnegative integer;
+*/
+func Gemmt(A, B, C matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
+	defer guard("Gemmt", &err, opts...)()
+	if err = writable("Gemmt", C); err != nil {
+		return
+	}
+	A, B = matops.Readable(A), matops.Readable(B)
+	if !matrix.EqualTypes(A, B, C) {
+		return onError(linalg.ErrType, "Parameters not of same type")
+	}
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
+		}
+		return GemmtFloat(A.(*matrix.FloatMatrix), B.(*matrix.FloatMatrix), C.(*matrix.FloatMatrix),
+			a, b, opts...)
+	case *matrix.ComplexMatrix:
+		return onError(linalg.ErrNotImplemented, "Gemmt not implemented for complx.Matrix")
+	default:
+		return onError(linalg.ErrType, "Unknown type, not implemented")
+	}
+}
+
+// See function Gemmt.
+func GemmtFloat(A, B, C *matrix.FloatMatrix, alpha, beta float64, opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("blas.Gemmt")
+	defer op.Finish(&err)
+	defer guard("GemmtFloat", &err, opts...)()
+
+	params, e := linalg.GetParameters(opts...)
+	if e != nil {
+		err = e
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	if ind.N >= 0 && ind.M < 0 {
+		ind.M = ind.N
+	}
+	err = check_level3_func(ind, fgemm, A, B, C, params)
+	if err != nil {
+		return
+	}
+	if ind.M != ind.N {
+		return onError(linalg.ErrShape, "Gemmt: product not square")
+	}
+	op.SetDims(ind.N, ind.N, ind.K)
+	if ind.N == 0 {
+		return
+	}
+	Aa := A.FloatArray()
+	Ba := B.FloatArray()
+	Ca := C.FloatArray()
+	uplo := linalg.ParamString(params.Uplo)
+	transA := linalg.ParamString(params.TransA)
+	transB := linalg.ParamString(params.TransB)
+	if nativeDgemmt(uplo, transA, transB, ind.N, ind.K, alpha,
+		Aa[ind.OffsetA:], ind.LDa, Ba[ind.OffsetB:], ind.LDb, beta,
+		Ca[ind.OffsetC:], ind.LDc) {
+		op.SetBackend(KernelNative)
+		return
+	}
+	op.SetBackend(KernelGo)
+	goDgemmt(params.Uplo == linalg.PLower, transA[0] != 'N', transB[0] != 'N',
+		ind.N, ind.K, alpha, Aa[ind.OffsetA:], ind.LDa, Ba[ind.OffsetB:], ind.LDb,
+		beta, Ca[ind.OffsetC:], ind.LDc)
+	return
+}
+
+// Pure Go dgemmt, the lower (or upper) triangle of goDgemm.
+func goDgemmt(lower, transA, transB bool, N, K int, alpha float64, A []float64, lda int,
+	B []float64, ldb int, beta float64, C []float64, ldc int) {
+	for j := 0; j < N; j++ {
+		i0, i1 := 0, j+1
+		if lower {
+			i0, i1 = j, N
+		}
+		c := C[j*ldc+i0 : j*ldc+i1]
+		if beta == 0.0 {
+			for i := range c {
+				c[i] = 0.0
+			}
+		} else if beta != 1.0 {
+			for i := range c {
+				c[i] *= beta
+			}
+		}
+		if alpha == 0.0 {
+			continue
+		}
+		for l := 0; l < K; l++ {
+			// b = alpha*op(B)[l, j]
+			b := B[j*ldb+l]
+			if transB {
+				b = B[l*ldb+j]
+			}
+			b *= alpha
+			if b == 0.0 {
+				continue
+			}
+			if !transA {
+				a := A[l*lda+i0 : l*lda+i1]
+				for i := range c {
+					c[i] += b * a[i]
+				}
+			} else {
+				for i := range c {
+					c[i] += b * A[(i0+i)*lda+l]
+				}
+			}
+		}
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -7,9 +7,12 @@
 package blas
 
 import (
-	"errors"
+	"fmt"
 	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/internal/xerbla"
+	"github.com/nvcook42/linalg/matops"
 	"github.com/nvcook42/matrix"
+	"runtime"
 )
 
 type funcNum int
@@ -70,28 +73,95 @@ func max(a, b int) int {
 
 var panicOnError bool = false
 
+// Option FuzzCall adds to the options of the wrapper it calls: guard does
+// not recover panics of the call.
+type noRecoverOpt struct {
+	linalg.Option
+}
+
+var noRecover linalg.Option = &noRecoverOpt{linalg.BoolOpt("norecover", true)}
+
+// True if opts hold the option of FuzzCall.
+func noRecoverSet(opts []linalg.Option) bool {
+	for _, o := range opts {
+		if _, ok := o.(*noRecoverOpt); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func PanicOnError(flag bool) {
 	panicOnError = flag
 }
 
-func onError(msg string) error {
+func onError(kind error, msg string) error {
+	err := linalg.NewError(kind, msg)
 	if panicOnError {
-		panic(msg)
+		panic(err)
 	}
-	return errors.New(msg)
+	return err
+}
+
+// Return error if any output argument is a read-only view.
+func writable(name string, mats ...matrix.Matrix) error {
+	if matops.IsReadOnly(mats...) {
+		return onError(linalg.ErrReadOnly, name+": output argument is read-only")
+	}
+	return nil
+}
+
+// Guard an exported function against failures in the native library. Use as
+//
+//	defer guard("Name", &err, opts...)()
+//
+// at the start of a function with named error result. The goroutine is locked
+// to its OS thread for the duration of the call so that XERBLA reports can be
+// collected, and runtime panics (eg. out of range slice indexes on arguments
+// inconsistent with options) are returned as errors unless panicOnError is set.
+// Faults inside the native code itself cannot be recovered.
+func guard(name string, err *error, opts ...linalg.Option) func() {
+	norecover := noRecoverSet(opts)
+	runtime.LockOSThread()
+	xerbla.Clear()
+	return func() {
+		defer runtime.UnlockOSThread()
+		if r := recover(); r != nil {
+			if panicOnError || norecover {
+				panic(r)
+			}
+			*err = linalg.NewError(linalg.ErrParameter, fmt.Sprintf("%s: %v", name, r))
+			return
+		}
+		if rname, info := xerbla.Last(); info != 0 && *err == nil {
+			*err = onError(linalg.ErrParameter,
+				fmt.Sprintf("%s: parameter %d to %s had an illegal value", name, info, rname))
+		}
+	}
+}
+
+// Reject index options out of the range of the native library.
+func checkIndexRange(ind *linalg.IndexOpts) error {
+	if err := linalg.CheckIndexOpts(ind); err != nil {
+		return onError(linalg.ErrParameter, err.Error())
+	}
+	return nil
 }
 
 func check_level1_func(ind *linalg.IndexOpts, fn funcNum, X, Y matrix.Matrix) error {
+	if err := checkIndexRange(ind); err != nil {
+		return err
+	}
 
 	nX, nY := 0, 0
 	// this is adapted from cvxopt:blas.c python blas interface
 	switch fn {
 	case fnrm2, fasum, fiamax, fscal, fset:
 		if ind.IncX <= 0 {
-			return onError("incX illegal, <=0")
+			return onError(linalg.ErrParameter, "incX illegal, <=0")
 		}
 		if ind.OffsetX < 0 {
-			return onError("offsetX illegal, <0")
+			return onError(linalg.ErrParameter, "offsetX illegal, <0")
 		}
 		sizeX := X.NumElements()
 		if sizeX >= ind.OffsetX+1 {
@@ -99,19 +169,19 @@ func check_level1_func(ind *linalg.IndexOpts, fn funcNum, X, Y matrix.Matrix) er
 			nX = 1 + (sizeX-ind.OffsetX-1)/ind.IncX
 		}
 		if sizeX < ind.OffsetX+1+(ind.Nx-1)*abs(ind.IncX) {
-			return onError("X size error")
+			return onError(linalg.ErrShape, "X size error")
 		}
 		if ind.Nx < 0 {
 			ind.Nx = nX
 		}
 
-	case fdot, fswap, fcopy, faxpy, faxpby:
+	case fdot, fswap, fcopy, faxpy, faxpby, frot, frotm:
 		// vector X
 		if ind.IncX <= 0 {
-			return onError("incX illegal, <=0")
+			return onError(linalg.ErrParameter, "incX illegal, <=0")
 		}
 		if ind.OffsetX < 0 {
-			return onError("offsetX illegal, <0")
+			return onError(linalg.ErrParameter, "offsetX illegal, <0")
 		}
 		sizeX := X.NumElements()
 		if sizeX >= ind.OffsetX+1 {
@@ -119,17 +189,17 @@ func check_level1_func(ind *linalg.IndexOpts, fn funcNum, X, Y matrix.Matrix) er
 			nX = 1 + (sizeX-ind.OffsetX-1)/ind.IncX
 		}
 		if sizeX < ind.OffsetX+1+(ind.Nx-1)*abs(ind.IncX) {
-			return onError("X size error")
+			return onError(linalg.ErrShape, "X size error")
 		}
 		if ind.Nx < 0 {
 			ind.Nx = nX
 		}
 		// vector Y
 		if ind.IncY <= 0 {
-			return onError("incY illegal, <=0")
+			return onError(linalg.ErrParameter, "incY illegal, <=0")
 		}
 		if ind.OffsetY < 0 {
-			return onError("offsetY illegal, <0")
+			return onError(linalg.ErrParameter, "offsetY illegal, <0")
 		}
 		sizeY := Y.NumElements()
 		if sizeY >= ind.OffsetY+1 {
@@ -141,20 +211,27 @@ func check_level1_func(ind *linalg.IndexOpts, fn funcNum, X, Y matrix.Matrix) er
 		}
 		if sizeY < ind.OffsetY+1+(ind.Ny-1)*abs(ind.IncY) {
 			//fmt.Printf("sizeY=%d, inds: %#v\n", sizeY, ind)
-			return onError("Y size error")
+			return onError(linalg.ErrShape, "Y size error")
+		}
+		// n elements of both vectors are referenced
+		if sizeY < ind.OffsetY+1+(ind.Nx-1)*abs(ind.IncY) {
+			return onError(linalg.ErrShape, "Y size error")
 		}
 
-	case frotg, frotmg, frot, frotm:
+	case frotg, frotmg:
 	}
 	return nil
 }
 
 func check_level2_func(ind *linalg.IndexOpts, fn funcNum, X, Y, A matrix.Matrix, pars *linalg.Parameters) error {
+	if err := checkIndexRange(ind); err != nil {
+		return err
+	}
 	if ind.IncX <= 0 {
-		return onError("incX")
+		return onError(linalg.ErrParameter, "incX")
 	}
 	if ind.IncY <= 0 {
-		return onError("incY")
+		return onError(linalg.ErrParameter, "incY")
 	}
 
 	sizeA := A.NumElements()
@@ -172,33 +249,33 @@ func check_level2_func(ind *linalg.IndexOpts, fn funcNum, X, Y, A matrix.Matrix,
 			arows = max(1, A.Rows())
 		}
 		if ind.OffsetA < 0 {
-			return onError("offsetA")
+			return onError(linalg.ErrParameter, "offsetA")
 		}
 		if ind.N > 0 && ind.M > 0 &&
 			sizeA < ind.OffsetA+(ind.N-1)*arows+ind.M {
-			return onError("sizeA")
+			return onError(linalg.ErrShape, "sizeA")
 		}
 		if ind.OffsetX < 0 {
-			return onError("offsetX")
+			return onError(linalg.ErrParameter, "offsetX")
 		}
 		if ind.OffsetY < 0 {
-			return onError("offsetY")
+			return onError(linalg.ErrParameter, "offsetY")
 		}
 		sizeX := X.NumElements()
 		sizeY := Y.NumElements()
 		if pars.Trans == linalg.PNoTrans {
 			if ind.N > 0 && sizeX < ind.OffsetX+(ind.N-1)*abs(ind.IncX)+1 {
-				return onError("sizeX")
+				return onError(linalg.ErrShape, "sizeX")
 			}
 			if ind.M > 0 && sizeY < ind.OffsetY+(ind.M-1)*abs(ind.IncY)+1 {
-				return onError("sizeY")
+				return onError(linalg.ErrShape, "sizeY")
 			}
 		} else {
 			if ind.M > 0 && sizeX < ind.OffsetX+(ind.M-1)*abs(ind.IncX)+1 {
-				return onError("sizeX")
+				return onError(linalg.ErrShape, "sizeX")
 			}
 			if ind.N > 0 && sizeY < ind.OffsetY+(ind.N-1)*abs(ind.IncY)+1 {
-				return onError("sizeY")
+				return onError(linalg.ErrShape, "sizeY")
 			}
 		}
 	case fger:
@@ -217,27 +294,27 @@ func check_level2_func(ind *linalg.IndexOpts, fn funcNum, X, Y, A matrix.Matrix,
 				arows = max(1, A.Rows())
 			}
 			if ind.LDa < max(1, ind.M) {
-				return onError("ldA")
+				return onError(linalg.ErrParameter, "ldA")
 			}
 			if ind.OffsetA < 0 {
-				return onError("offsetA")
+				return onError(linalg.ErrParameter, "offsetA")
 			}
 			if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.M {
-				return onError("sizeA")
+				return onError(linalg.ErrShape, "sizeA")
 			}
 			if ind.OffsetX < 0 {
-				return onError("offsetX")
+				return onError(linalg.ErrParameter, "offsetX")
 			}
 			if ind.OffsetY < 0 {
-				return onError("offsetY")
+				return onError(linalg.ErrParameter, "offsetY")
 			}
 			sizeX := X.NumElements()
 			if sizeX < ind.OffsetX+(ind.M-1)*abs(ind.IncX)+1 {
-				return onError("sizeX")
+				return onError(linalg.ErrShape, "sizeX")
 			}
 			sizeY := Y.NumElements()
 			if sizeY < ind.OffsetY+(ind.N-1)*abs(ind.IncY)+1 {
-				return onError("sizeY")
+				return onError(linalg.ErrShape, "sizeY")
 			}
 		}
 	case fgbmv: // general banded
@@ -248,50 +325,50 @@ func check_level2_func(ind *linalg.IndexOpts, fn funcNum, X, Y, A matrix.Matrix,
 			ind.N = A.Cols()
 		}
 		if ind.Kl < 0 {
-			return onError("kl")
+			return onError(linalg.ErrParameter, "kl")
 		}
 		if ind.Ku < 0 {
 			ind.Ku = A.Rows() - 1 - ind.Kl
 		}
 		if ind.Ku < 0 {
-			return onError("ku")
+			return onError(linalg.ErrParameter, "ku")
 		}
 		if ind.LDa == 0 {
 			ind.LDa = max(1, A.LeadingIndex())
 			arows = max(1, A.Rows())
 		}
 		if ind.LDa < ind.Kl+ind.Ku+1 {
-			return onError("ldA")
+			return onError(linalg.ErrParameter, "ldA")
 		}
 		if ind.OffsetA < 0 {
-			return onError("offsetA")
+			return onError(linalg.ErrParameter, "offsetA")
 		}
 		sizeA := A.NumElements()
 		if ind.N > 0 && ind.M > 0 &&
 			sizeA < ind.OffsetA+(ind.N-1)*arows+ind.Kl+ind.Ku+1 {
-			return onError("sizeA")
+			return onError(linalg.ErrShape, "sizeA")
 		}
 		if ind.OffsetX < 0 {
-			return onError("offsetX")
+			return onError(linalg.ErrParameter, "offsetX")
 		}
 		if ind.OffsetY < 0 {
-			return onError("offsetY")
+			return onError(linalg.ErrParameter, "offsetY")
 		}
 		sizeX := X.NumElements()
 		sizeY := Y.NumElements()
 		if pars.Trans == linalg.PNoTrans {
 			if ind.N > 0 && sizeX < ind.OffsetX+(ind.N-1)*abs(ind.IncX)+1 {
-				return onError("sizeX")
+				return onError(linalg.ErrShape, "sizeX")
 			}
 			if ind.N > 0 && sizeY < ind.OffsetY+(ind.M-1)*abs(ind.IncY)+1 {
-				return onError("sizeY")
+				return onError(linalg.ErrShape, "sizeY")
 			}
 		} else {
 			if ind.N > 0 && sizeX < ind.OffsetX+(ind.M-1)*abs(ind.IncX)+1 {
-				return onError("sizeX")
+				return onError(linalg.ErrShape, "sizeX")
 			}
 			if ind.N > 0 && sizeY < ind.OffsetY+(ind.N-1)*abs(ind.IncY)+1 {
-				return onError("sizeY")
+				return onError(linalg.ErrShape, "sizeY")
 			}
 		}
 	case ftrmv, ftrsv:
@@ -299,7 +376,7 @@ func check_level2_func(ind *linalg.IndexOpts, fn funcNum, X, Y, A matrix.Matrix,
 		// ftrsv = triangular solve
 		if ind.N < 0 {
 			if A.Rows() != A.Cols() {
-				return onError("A not square")
+				return onError(linalg.ErrShape, "A not square")
 			}
 			ind.N = A.Rows()
 		}
@@ -309,18 +386,18 @@ func check_level2_func(ind *linalg.IndexOpts, fn funcNum, X, Y, A matrix.Matrix,
 				arows = max(1, A.Rows())
 			}
 			if ind.LDa < max(1, ind.N) {
-				return onError("ldA")
+				return onError(linalg.ErrParameter, "ldA")
 			}
 			if ind.OffsetA < 0 {
-				return onError("offsetA")
+				return onError(linalg.ErrParameter, "offsetA")
 			}
 			sizeA := A.NumElements()
 			if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.N {
-				return onError("sizeA")
+				return onError(linalg.ErrShape, "sizeA")
 			}
 			sizeX := X.NumElements()
 			if sizeX < ind.OffsetX+(ind.N-1)*abs(ind.IncX)+1 {
-				return onError("sizeX")
+				return onError(linalg.ErrShape, "sizeX")
 			}
 		}
 	case ftbmv, ftbsv, fsbmv:
@@ -340,23 +417,23 @@ func check_level2_func(ind *linalg.IndexOpts, fn funcNum, X, Y, A matrix.Matrix,
 				arows = max(1, A.Rows())
 			}
 			if ind.LDa < ind.K+1 {
-				return onError("ldA")
+				return onError(linalg.ErrParameter, "ldA")
 			}
 			if ind.OffsetA < 0 {
-				return onError("offsetA")
+				return onError(linalg.ErrParameter, "offsetA")
 			}
 			sizeA := A.NumElements()
 			if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.K+1 {
-				return onError("sizeA")
+				return onError(linalg.ErrShape, "sizeA")
 			}
 			sizeX := X.NumElements()
 			if sizeX < ind.OffsetX+(ind.N-1)*abs(ind.IncX)+1 {
-				return onError("sizeX")
+				return onError(linalg.ErrShape, "sizeX")
 			}
 			if Y != nil {
 				sizeY := Y.NumElements()
 				if sizeY < ind.OffsetY+(ind.N-1)*abs(ind.IncY)+1 {
-					return onError("sizeY")
+					return onError(linalg.ErrShape, "sizeY")
 				}
 			}
 		}
@@ -366,7 +443,7 @@ func check_level2_func(ind *linalg.IndexOpts, fn funcNum, X, Y, A matrix.Matrix,
 		// fsyr2 = symmetric rank-2 update
 		if ind.N < 0 {
 			if A.Rows() != A.Cols() {
-				return onError("A not square")
+				return onError(linalg.ErrShape, "A not square")
 			}
 			ind.N = A.Rows()
 		}
@@ -377,43 +454,93 @@ func check_level2_func(ind *linalg.IndexOpts, fn funcNum, X, Y, A matrix.Matrix,
 				arows = max(1, A.Rows())
 			}
 			if ind.LDa < max(1, ind.N) {
-				return onError("ldA")
+				return onError(linalg.ErrParameter, "ldA")
 			}
 			if ind.OffsetA < 0 {
-				return onError("offsetA")
+				return onError(linalg.ErrParameter, "offsetA")
 			}
 			sizeA := A.NumElements()
 			if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.N {
-				return onError("sizeA")
+				return onError(linalg.ErrShape, "sizeA")
 			}
 			if ind.OffsetX < 0 {
-				return onError("offsetX")
+				return onError(linalg.ErrParameter, "offsetX")
 			}
 			sizeX := X.NumElements()
 			if sizeX < ind.OffsetX+(ind.N-1)*abs(ind.IncX)+1 {
-				return onError("sizeX")
+				return onError(linalg.ErrShape, "sizeX")
 			}
 			if Y != nil {
 				if ind.OffsetY < 0 {
-					return onError("offsetY")
+					return onError(linalg.ErrParameter, "offsetY")
 				}
 				sizeY := Y.NumElements()
 				if sizeY < ind.OffsetY+(ind.N-1)*abs(ind.IncY)+1 {
-					return onError("sizeY")
+					return onError(linalg.ErrShape, "sizeY")
 				}
 			}
 		}
 	case fspr, fdspr2, ftpsv, fspmv, ftpmv:
+		// fspr = symmetric packed rank update
+		// fdspr2 = symmetric packed rank-2 update
 		// ftpsv = triangular packed solve
 		// fspmv = symmetric packed product
 		// ftpmv = triangular packed
+		if ind.OffsetA < 0 {
+			return onError(linalg.ErrParameter, "offsetA")
+		}
+		sizeA := A.NumElements()
+		if ind.N < 0 {
+			// A holds n*(n+1)/2 elements after offset
+			ind.N = packedOrder(sizeA - ind.OffsetA)
+			if ind.N < 0 {
+				return onError(linalg.ErrShape, "A not packed triangular")
+			}
+		}
+		if ind.N > 0 {
+			if sizeA < ind.OffsetA+ind.N*(ind.N+1)/2 {
+				return onError(linalg.ErrShape, "sizeA")
+			}
+			if ind.OffsetX < 0 {
+				return onError(linalg.ErrParameter, "offsetX")
+			}
+			sizeX := X.NumElements()
+			if sizeX < ind.OffsetX+(ind.N-1)*abs(ind.IncX)+1 {
+				return onError(linalg.ErrShape, "sizeX")
+			}
+			if Y != nil {
+				if ind.OffsetY < 0 {
+					return onError(linalg.ErrParameter, "offsetY")
+				}
+				sizeY := Y.NumElements()
+				if sizeY < ind.OffsetY+(ind.N-1)*abs(ind.IncY)+1 {
+					return onError(linalg.ErrShape, "sizeY")
+				}
+			}
+		}
 	}
 	return nil
 }
 
+// Return order n of a packed triangular matrix of size elements,
+// n*(n+1)/2 = size, or -1 if size is not of that form.
+func packedOrder(size int) int {
+	n := 0
+	for n*(n+1)/2 < size {
+		n++
+	}
+	if n*(n+1)/2 != size {
+		return -1
+	}
+	return n
+}
+
 func check_level3_func(ind *linalg.IndexOpts, fn funcNum, A, B, C matrix.Matrix,
 	pars *linalg.Parameters) (err error) {
 
+	if err = checkIndexRange(ind); err != nil {
+		return
+	}
 	// defaults for these
 	arows := ind.LDa
 	brows := ind.LDb
@@ -446,11 +573,11 @@ func check_level3_func(ind *linalg.IndexOpts, fn funcNum, A, B, C matrix.Matrix,
 			}
 			if pars.TransB == linalg.PNoTrans && ind.K != B.Rows() ||
 				pars.TransB != linalg.PNoTrans && ind.K != B.Cols() {
-				return onError("dimensions of A and B do not match")
+				return onError(linalg.ErrShape, "dimensions of A and B do not match")
 			}
 		}
 		if ind.OffsetA < 0 {
-			return onError("offsetA illegal, <0")
+			return onError(linalg.ErrParameter, "offsetA illegal, <0")
 		}
 		if ind.LDa == 0 {
 			ind.LDa = max(1, A.LeadingIndex())
@@ -459,19 +586,19 @@ func check_level3_func(ind *linalg.IndexOpts, fn funcNum, A, B, C matrix.Matrix,
 		if ind.K > 0 {
 			if (pars.TransA == linalg.PNoTrans && ind.LDa < max(1, ind.M)) ||
 				(pars.TransA != linalg.PNoTrans && ind.LDa < max(1, ind.K)) {
-				return onError("inconsistent ldA")
+				return onError(linalg.ErrParameter, "inconsistent ldA")
 			}
 			sizeA := A.NumElements()
 			if (pars.TransA == linalg.PNoTrans &&
 				sizeA < ind.OffsetA+(ind.K-1)*arows+ind.M) ||
 				(pars.TransA != linalg.PNoTrans &&
 					sizeA < ind.OffsetA+(ind.M-1)*arows+ind.K) {
-				return onError("sizeA")
+				return onError(linalg.ErrShape, "sizeA")
 			}
 		}
 		// B matrix
 		if ind.OffsetB < 0 {
-			return onError("offsetB illegal, <0")
+			return onError(linalg.ErrParameter, "offsetB illegal, <0")
 		}
 		if ind.LDb == 0 {
 			ind.LDb = max(1, B.LeadingIndex())
@@ -480,43 +607,43 @@ func check_level3_func(ind *linalg.IndexOpts, fn funcNum, A, B, C matrix.Matrix,
 		if ind.K > 0 {
 			if (pars.TransB == linalg.PNoTrans && ind.LDb < max(1, ind.K)) ||
 				(pars.TransB != linalg.PNoTrans && ind.LDb < max(1, ind.N)) {
-				return onError("inconsistent ldB")
+				return onError(linalg.ErrParameter, "inconsistent ldB")
 			}
 			sizeB := B.NumElements()
 			if (pars.TransB == linalg.PNoTrans &&
 				sizeB < ind.OffsetB+(ind.N-1)*brows+ind.K) ||
 				(pars.TransB != linalg.PNoTrans &&
 					sizeB < ind.OffsetB+(ind.K-1)*brows+ind.N) {
-				return onError("sizeB")
+				return onError(linalg.ErrShape, "sizeB")
 			}
 		}
 		// C matrix
 		if ind.OffsetC < 0 {
-			return onError("offsetC illegal, <0")
+			return onError(linalg.ErrParameter, "offsetC illegal, <0")
 		}
 		if ind.LDc == 0 {
 			ind.LDc = max(1, C.LeadingIndex())
 			crows = max(1, C.Rows())
 		}
 		if ind.LDc < max(1, ind.M) {
-			return onError("inconsistent ldC")
+			return onError(linalg.ErrParameter, "inconsistent ldC")
 		}
 		sizeC := C.NumElements()
 		if sizeC < ind.OffsetC+(ind.N-1)*crows+ind.M {
-			return onError("sizeC")
+			return onError(linalg.ErrShape, "sizeC")
 		}
 
 	case fsymm, ftrmm, ftrsm:
 		if ind.M < 0 {
 			ind.M = B.Rows()
 			if pars.Side == linalg.PLeft && (ind.M != A.Rows() || ind.M != A.Cols()) {
-				return onError("dimensions of A and B do not match")
+				return onError(linalg.ErrShape, "dimensions of A and B do not match")
 			}
 		}
 		if ind.N < 0 {
 			ind.N = B.Cols()
 			if pars.Side == linalg.PRight && (ind.N != A.Rows() || ind.N != A.Cols()) {
-				return onError("dimensions of A and B do not match")
+				return onError(linalg.ErrShape, "dimensions of A and B do not match")
 			}
 		}
 		if ind.M == 0 || ind.N == 0 {
@@ -524,52 +651,52 @@ func check_level3_func(ind *linalg.IndexOpts, fn funcNum, A, B, C matrix.Matrix,
 		}
 		// check A
 		if ind.OffsetB < 0 {
-			return onError("offsetB illegal, <0")
+			return onError(linalg.ErrParameter, "offsetB illegal, <0")
 		}
 		if ind.LDa == 0 {
 			ind.LDa = max(1, A.LeadingIndex())
 			arows = max(1, A.Rows())
 		}
 		if pars.Side == linalg.PLeft && ind.LDa < max(1, ind.M) || ind.LDa < max(1, ind.N) {
-			return onError("ldA")
+			return onError(linalg.ErrParameter, "ldA")
 		}
 		sizeA := A.NumElements()
 		if (pars.Side == linalg.PLeft && sizeA < ind.OffsetA+(ind.M-1)*arows+ind.M) ||
 			(pars.Side == linalg.PRight && sizeA < ind.OffsetA+(ind.N-1)*arows+ind.N) {
-			return onError("sizeA")
+			return onError(linalg.ErrShape, "sizeA")
 		}
 
 		if B != nil {
 			if ind.OffsetB < 0 {
-				return onError("offsetB illegal, <0")
+				return onError(linalg.ErrParameter, "offsetB illegal, <0")
 			}
 			if ind.LDb == 0 {
 				ind.LDb = max(1, B.LeadingIndex())
 				brows = max(1, B.Rows())
 			}
 			if ind.LDb < max(1, ind.M) {
-				return onError("ldB")
+				return onError(linalg.ErrParameter, "ldB")
 			}
 			sizeB := B.NumElements()
 			if sizeB < ind.OffsetB+(ind.N-1)*brows+ind.M {
-				return onError("sizeB")
+				return onError(linalg.ErrShape, "sizeB")
 			}
 		}
 
 		if C != nil {
 			if ind.OffsetC < 0 {
-				return onError("offsetC illegal, <0")
+				return onError(linalg.ErrParameter, "offsetC illegal, <0")
 			}
 			if ind.LDc == 0 {
 				ind.LDc = max(1, C.LeadingIndex())
 				crows = max(1, C.Rows())
 			}
 			if ind.LDc < max(1, ind.M) {
-				return onError("ldC")
+				return onError(linalg.ErrParameter, "ldC")
 			}
 			sizeC := C.NumElements()
 			if sizeC < ind.OffsetC+(ind.N-1)*crows+ind.M {
-				return onError("sizeC")
+				return onError(linalg.ErrShape, "sizeC")
 			}
 		}
 	case fsyrk:
@@ -596,47 +723,47 @@ func check_level3_func(ind *linalg.IndexOpts, fn funcNum, A, B, C matrix.Matrix,
 			arows = max(1, A.Rows())
 		}
 		if ind.OffsetA < 0 {
-			return onError("offsetA")
+			return onError(linalg.ErrParameter, "offsetA")
 		}
 		if ind.K > 0 {
 			if (pars.Trans == linalg.PNoTrans && ind.LDa < max(1, ind.N)) ||
 				(pars.Trans != linalg.PNoTrans && ind.LDa < max(1, ind.K)) {
-				return onError("inconsistent ldA")
+				return onError(linalg.ErrParameter, "inconsistent ldA")
 			}
 			sizeA := A.NumElements()
 			if (pars.Trans == linalg.PNoTrans &&
 				sizeA < ind.OffsetA+(ind.K-1)*arows+ind.N) ||
 				(pars.TransA != linalg.PNoTrans &&
 					sizeA < ind.OffsetA+(ind.N-1)*arows+ind.K) {
-				return onError("sizeA")
+				return onError(linalg.ErrShape, "sizeA")
 			}
 		}
 
 		if ind.OffsetC < 0 {
-			return onError("offsetC illegal, <0")
+			return onError(linalg.ErrParameter, "offsetC illegal, <0")
 		}
 		if ind.LDc == 0 {
 			ind.LDc = max(1, C.LeadingIndex())
 			crows = max(1, C.Rows())
 		}
 		if ind.LDc < max(1, ind.N) {
-			return onError("ldC")
+			return onError(linalg.ErrParameter, "ldC")
 		}
 		sizeC := C.NumElements()
 		if sizeC < ind.OffsetC+(ind.N-1)*crows+ind.N {
-			return onError("sizeC")
+			return onError(linalg.ErrShape, "sizeC")
 		}
 	case fsyr2k:
 		if ind.N < 0 {
 			if pars.Trans == linalg.PNoTrans {
 				ind.N = A.Rows()
 				if ind.N != B.Rows() {
-					return onError("dimensions of A and B do not match")
+					return onError(linalg.ErrShape, "dimensions of A and B do not match")
 				}
 			} else {
 				ind.N = A.Cols()
 				if ind.N != B.Cols() {
-					return onError("dimensions of A and B do not match")
+					return onError(linalg.ErrShape, "dimensions of A and B do not match")
 				}
 			}
 		}
@@ -647,12 +774,12 @@ func check_level3_func(ind *linalg.IndexOpts, fn funcNum, A, B, C matrix.Matrix,
 			if pars.Trans == linalg.PNoTrans {
 				ind.K = A.Cols()
 				if ind.K != B.Cols() {
-					return onError("dimensions of A and B do not match")
+					return onError(linalg.ErrShape, "dimensions of A and B do not match")
 				}
 			} else {
 				ind.K = A.Rows()
 				if ind.K != B.Rows() {
-					return onError("dimensions of A and B do not match")
+					return onError(linalg.ErrShape, "dimensions of A and B do not match")
 				}
 			}
 		}
@@ -663,18 +790,18 @@ func check_level3_func(ind *linalg.IndexOpts, fn funcNum, A, B, C matrix.Matrix,
 		if ind.K > 0 {
 			if (pars.Trans == linalg.PNoTrans && ind.LDa < max(1, ind.N)) ||
 				(pars.Trans != linalg.PNoTrans && ind.LDa < max(1, ind.K)) {
-				return onError("inconsistent ldA")
+				return onError(linalg.ErrParameter, "inconsistent ldA")
 			}
 			sizeA := A.NumElements()
 			if (pars.Trans == linalg.PNoTrans &&
 				sizeA < ind.OffsetA+(ind.K-1)*arows+ind.N) ||
 				(pars.TransA != linalg.PNoTrans &&
 					sizeA < ind.OffsetA+(ind.N-1)*arows+ind.K) {
-				return onError("sizeA")
+				return onError(linalg.ErrShape, "sizeA")
 			}
 		}
 		if ind.OffsetB < 0 {
-			return onError("offsetB illegal, <0")
+			return onError(linalg.ErrParameter, "offsetB illegal, <0")
 		}
 		if ind.LDb == 0 {
 			ind.LDb = max(1, B.LeadingIndex())
@@ -683,29 +810,29 @@ func check_level3_func(ind *linalg.IndexOpts, fn funcNum, A, B, C matrix.Matrix,
 		if ind.K > 0 {
 			if (pars.Trans == linalg.PNoTrans && ind.LDb < max(1, ind.N)) ||
 				(pars.Trans != linalg.PNoTrans && ind.LDb < max(1, ind.K)) {
-				return onError("ldB")
+				return onError(linalg.ErrParameter, "ldB")
 			}
 			sizeB := B.NumElements()
 			if (pars.Trans == linalg.PNoTrans &&
 				sizeB < ind.OffsetB+(ind.K-1)*brows+ind.N) ||
 				(pars.Trans != linalg.PNoTrans &&
 					sizeB < ind.OffsetB+(ind.N-1)*brows+ind.K) {
-				return onError("sizeB")
+				return onError(linalg.ErrShape, "sizeB")
 			}
 		}
 		if ind.OffsetC < 0 {
-			return onError("offsetC illegal, <0")
+			return onError(linalg.ErrParameter, "offsetC illegal, <0")
 		}
 		if ind.LDc == 0 {
 			ind.LDc = max(1, C.LeadingIndex())
 			crows = max(1, C.Rows())
 		}
 		if ind.LDc < max(1, ind.N) {
-			return onError("ldC")
+			return onError(linalg.ErrParameter, "ldC")
 		}
 		sizeC := C.NumElements()
 		if sizeC < ind.OffsetC+(ind.N-1)*crows+ind.N {
-			return onError("sizeC")
+			return onError(linalg.ErrShape, "sizeC")
 		}
 	}
 	err = nil
@@ -0,0 +1,228 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/blas package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package blas
+
+import (
+	"github.com/nvcook42/linalg"
+	"math"
+)
+
+/*
+ General matrix-matrix product of quantized int8 matrices with int32
+ accumulation. (L3)
+
+ PURPOSE
+ Computes
+  C := (op(A) - zeroA)*(op(B) - zeroB) + beta*C
+ for int8 matrices A and B and int32 matrix C stored in column-major order
+ in slices, where zeroA and zeroB are the zero points of the quantization
+ of A and B. Products and sums are exact in int32 for k up to 33025, the
+ largest k for which k*255*255 fits; larger k may wrap around.
+
+ The product is computed in pure Go or by the native library's
+ cblas_gemm_s8u8s32, if it has one (linalg.ExtInt8), as chosen by the
+ kernel option and Profile for Gemm. The native kernel requires zeroB
+ zero and zeroA not -128 and converts B to unsigned; other problems fall
+ back to Go. The slices carry no shape, so m, n and k must be given as
+ options.
+
+ ARGUMENTS
+  A         int8 slice, storage of m*k matrix, k*m if transposed
+  B         int8 slice, storage of k*n matrix, n*k if transposed
+  C         int32 slice, storage of m*n matrix
+  beta      int32
+
+ OPTIONS
+  transA    PNoTrans or PTrans
+  transB    PNoTrans or PTrans
+  m         nonnegative integer
+  n         nonnegative integer
+  k         nonnegative integer
+  ldA       positive integer.  Default rows of stored A, m or k if transposed.
+  ldB       positive integer.  Default rows of stored B, k or n if transposed.
+  ldC       positive integer.  Default m.
+  offsetA   nonnegative integer
+  offsetB   nonnegative integer
+  offsetC   nonnegative integer;
+  zeroA     integer in [-128, 127].  Default 0.
+  zeroB     integer in [-128, 127].  Default 0.
+  kernel    "auto" (default), "go" or "native".
+*/
+func GemmInt8(A, B []int8, C []int32, beta int32, opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("blas.GemmInt8")
+	defer op.Finish(&err)
+	defer guard("GemmInt8", &err, opts...)()
+
+	q, err := checkInt8(len(A), len(B), len(C), opts...)
+	if err != nil {
+		return
+	}
+	op.SetDims(q.ind.M, q.ind.N, q.ind.K)
+	if q.ind.M == 0 || q.ind.N == 0 {
+		return
+	}
+	ind := q.ind
+	Cs := C[ind.OffsetC:]
+	if beta == 0 {
+		return q.dispatch(op, A[ind.OffsetA:], B[ind.OffsetB:], Cs, ind.LDc, opts...)
+	}
+	acc := make([]int32, ind.M*ind.N)
+	if err = q.dispatch(op, A[ind.OffsetA:], B[ind.OffsetB:], acc, ind.M, opts...); err != nil {
+		return
+	}
+	for j := 0; j < ind.N; j++ {
+		for i := 0; i < ind.M; i++ {
+			Cs[j*ind.LDc+i] = acc[j*ind.M+i] + beta*Cs[j*ind.LDc+i]
+		}
+	}
+	return
+}
+
+/*
+ General matrix-matrix product of quantized int8 matrices with float32
+ result. (L3)
+
+ PURPOSE
+ Computes
+  C := scale*(op(A) - zeroA)*(op(B) - zeroB) + beta*C
+ for int8 matrices A and B and float32 matrix C, the dequantized product
+ of matrices quantized with a common scale, the product of the scales of
+ A and B. The integer product is formed as by GemmInt8 and scaled once
+ in float64. Arguments and options are as for GemmInt8.
+*/
+func GemmInt8Scaled(A, B []int8, C []float32, scale, beta float64, opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("blas.GemmInt8Scaled")
+	defer op.Finish(&err)
+	defer guard("GemmInt8Scaled", &err, opts...)()
+
+	q, err := checkInt8(len(A), len(B), len(C), opts...)
+	if err != nil {
+		return
+	}
+	ind := q.ind
+	op.SetDims(ind.M, ind.N, ind.K)
+	if ind.M == 0 || ind.N == 0 {
+		return
+	}
+	if _, err = floatValue("scale", linalg.FloatScalar(scale)); err != nil {
+		return
+	}
+	if _, err = floatValue("beta", linalg.FloatScalar(beta)); err != nil {
+		return
+	}
+	acc := make([]int32, ind.M*ind.N)
+	if err = q.dispatch(op, A[ind.OffsetA:], B[ind.OffsetB:], acc, ind.M, opts...); err != nil {
+		return
+	}
+	Cs := C[ind.OffsetC:]
+	for j := 0; j < ind.N; j++ {
+		for i := 0; i < ind.M; i++ {
+			v := scale * float64(acc[j*ind.M+i])
+			if beta != 0.0 {
+				v += beta * float64(Cs[j*ind.LDc+i])
+			}
+			Cs[j*ind.LDc+i] = float32(v)
+		}
+	}
+	return
+}
+
+// Checked arguments of a quantized product.
+type int8Gemm struct {
+	ind            *linalg.IndexOpts
+	transA, transB bool
+	za, zb         int32
+}
+
+// Check arguments of GemmInt8 for slices of sizeA, sizeB and sizeC
+// elements.
+func checkInt8(sizeA, sizeB, sizeC int, opts ...linalg.Option) (*int8Gemm, error) {
+	params, err := linalg.GetParameters(opts...)
+	if err != nil {
+		return nil, err
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	if _, _, _, err = checkStridedBatch(ind, params, sizeA, sizeB, sizeC, 1); err != nil {
+		return nil, err
+	}
+	za := linalg.GetIntOpt("zeroA", 0, opts...)
+	zb := linalg.GetIntOpt("zeroB", 0, opts...)
+	if za < math.MinInt8 || za > math.MaxInt8 || zb < math.MinInt8 || zb > math.MaxInt8 {
+		return nil, onError(linalg.ErrParameter, "zeroA or zeroB not in int8 range")
+	}
+	return &int8Gemm{ind, params.TransA != linalg.PNoTrans, params.TransB != linalg.PNoTrans,
+		int32(za), int32(zb)}, nil
+}
+
+// Compute C := (op(A) - za)*(op(B) - zb) with the kernel selected by the
+// kernel option; A and B start at their offsets, C has leading index ldc.
+func (q *int8Gemm) dispatch(op *linalg.Op, A, B []int8, C []int32, ldc int, opts ...linalg.Option) error {
+	ind := q.ind
+	M, N, K := ind.M, ind.N, ind.K
+	kernel, _, err := selectKernel(2*float64(M)*float64(N)*float64(K), opts...)
+	if err != nil {
+		return err
+	}
+	if kernel == KernelGPU {
+		return onError(linalg.ErrNotImplemented, "no GPU kernel for int8 products")
+	}
+	if kernel == KernelNative && q.zb == 0 && q.za != math.MinInt8 && K > 0 &&
+		linalg.BackendInfo().Has(linalg.ExtInt8) {
+		// B + 128 is unsigned and B = (B + 128) - 128
+		rb, cb := K, N
+		if q.transB {
+			rb, cb = N, K
+		}
+		Bu := make([]uint8, ind.LDb*(cb-1)+rb)
+		for i := range Bu {
+			Bu[i] = uint8(int32(B[i]) + 128)
+		}
+		if nativeGemmS8U8S32(q.transA, q.transB, M, N, K, A, ind.LDa, int8(-q.za),
+			Bu, ind.LDb, math.MinInt8, C, ldc) {
+			return nil
+		}
+	}
+	op.SetBackend(KernelGo)
+	gemmInt8(q.transA, q.transB, M, N, K, A, ind.LDa, q.za, B, ind.LDb, q.zb, C, ldc)
+	return nil
+}
+
+// Pure Go quantized gemm, with the loop order of goDgemm.
+func gemmInt8(transA, transB bool, M, N, K int, A []int8, lda int, za int32,
+	B []int8, ldb int, zb int32, C []int32, ldc int) {
+	for j := 0; j < N; j++ {
+		c := C[j*ldc : j*ldc+M]
+		for i := range c {
+			c[i] = 0
+		}
+		for l := 0; l < K; l++ {
+			// b = op(B)[l, j] - zb
+			b := int32(B[j*ldb+l])
+			if transB {
+				b = int32(B[l*ldb+j])
+			}
+			b -= zb
+			if b == 0 {
+				continue
+			}
+			if !transA {
+				a := A[l*lda : l*lda+M]
+				for i := range c {
+					c[i] += (int32(a[i]) - za) * b
+				}
+			} else {
+				for i := range c {
+					c[i] += (int32(A[i*lda+l]) - za) * b
+				}
+			}
+		}
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,158 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/blas package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package blas
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+)
+
+/*
+ Kronecker product update.
+
+ Kron(A, B, C, alpha=1.0)
+
+ COMPUTES
+  C := alpha*kron(A, B) + C
+
+ If A is m*n and B is p*q then C is mp*nq. Column j*q+l of C equals
+ kron(A[:,j], B[:,l]) which is the p*m outer product B[:,l]*A[:,j]^T stored
+ column-wise. Each column of C is computed with one rank-1 update.
+
+ ARGUMENTS
+  A         float or complex m*n matrix
+  B         float or complex p*q matrix. Must have the same type as A.
+  C         float or complex mp*nq matrix. Must have the same type as A.
+  alpha     number (float or complex singleton matrix)
+
+ OPTIONS
+  ldA       positive integer, ldA >= max(1,m). If zero the default value is used.
+  ldB       positive integer, ldB >= max(1,p). If zero the default value is used.
+  ldC       positive integer, ldC >= max(1,m*p). If zero the default value is used.
+  offsetA   nonnegative integer
+  offsetB   nonnegative integer
+  offsetC   nonnegative integer
+
+ Sizes m, n, p and q are those of A and B. Without ldC and offsetC, C
+ must be mp*nq; with them the product updates the mp*nq block of C
+ starting at element offsetC.
+*/
+func Kron(A, B, C matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
+	defer guard("Kron", &err, opts...)()
+	if err = writable("Kron", C); err != nil {
+		return
+	}
+	A, B = matops.Readable(A), matops.Readable(B)
+	if !matrix.EqualTypes(A, B, C) {
+		return onError(linalg.ErrType, "Parameters not of same type")
+	}
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
+		}
+		return KronFloat(A.(*matrix.FloatMatrix), B.(*matrix.FloatMatrix), C.(*matrix.FloatMatrix),
+			a, opts...)
+	case *matrix.ComplexMatrix:
+		a, e := complexValue("alpha", alpha)
+		if e != nil {
+			return e
+		}
+		return KronComplex(A.(*matrix.ComplexMatrix), B.(*matrix.ComplexMatrix), C.(*matrix.ComplexMatrix),
+			a, opts...)
+	default:
+		return onError(linalg.ErrType, "Unknown type, not implemented")
+	}
+}
+
+// See function Kron.
+func KronFloat(A, B, C *matrix.FloatMatrix, alpha float64, opts ...linalg.Option) (err error) {
+	defer guard("KronFloat", &err, opts...)()
+	ind := linalg.GetIndexOpts(opts...)
+	m, n, p, q, err := kronIndex(ind, A, B, C)
+	if err != nil || m == 0 || n == 0 || p == 0 || q == 0 {
+		return
+	}
+	Aa := A.FloatArray()[ind.OffsetA:]
+	Ba := B.FloatArray()[ind.OffsetB:]
+	Ca := C.FloatArray()[ind.OffsetC:]
+	for j := 0; j < n; j++ {
+		for l := 0; l < q; l++ {
+			dger(p, m, alpha, Ba[l*ind.LDb:], 1, Aa[j*ind.LDa:], 1, Ca[(j*q+l)*ind.LDc:], p)
+		}
+	}
+	return
+}
+
+// See function Kron.
+func KronComplex(A, B, C *matrix.ComplexMatrix, alpha complex128, opts ...linalg.Option) (err error) {
+	defer guard("KronComplex", &err, opts...)()
+	ind := linalg.GetIndexOpts(opts...)
+	m, n, p, q, err := kronIndex(ind, A, B, C)
+	if err != nil || m == 0 || n == 0 || p == 0 || q == 0 {
+		return
+	}
+	Aa := A.ComplexArray()[ind.OffsetA:]
+	Ba := B.ComplexArray()[ind.OffsetB:]
+	Ca := C.ComplexArray()[ind.OffsetC:]
+	for j := 0; j < n; j++ {
+		for l := 0; l < q; l++ {
+			zgeru(p, m, alpha, Ba[l*ind.LDb:], 1, Aa[j*ind.LDa:], 1, Ca[(j*q+l)*ind.LDc:], p)
+		}
+	}
+	return
+}
+
+// Sizes of A and B, checking that C holds their Kronecker product and that
+// index options are consistent. Sets default leading indexes in ind.
+func kronIndex(ind *linalg.IndexOpts, A, B, C matrix.Matrix) (m, n, p, q int, err error) {
+	if err = checkIndexRange(ind); err != nil {
+		return
+	}
+	m, n = A.Size()
+	p, q = B.Size()
+	// with ldC or offsetC the product may be a block of a larger C
+	if ind.LDc == 0 && ind.OffsetC == 0 && (C.Rows() != m*p || C.Cols() != n*q) {
+		err = onError(linalg.ErrShape, "Kron: size of C does not match")
+		return
+	}
+	if ind.OffsetA < 0 || ind.OffsetB < 0 || ind.OffsetC < 0 {
+		err = onError(linalg.ErrParameter, "Kron: offset illegal, <0")
+		return
+	}
+	if err = kronMatrix("A", A, &ind.LDa, ind.OffsetA, m, n); err != nil {
+		return
+	}
+	if err = kronMatrix("B", B, &ind.LDb, ind.OffsetB, p, q); err != nil {
+		return
+	}
+	err = kronMatrix("C", C, &ind.LDc, ind.OffsetC, m*p, n*q)
+	return
+}
+
+// Check leading index *ld, set to default if zero, and size of rows*cols
+// argument X at offset off.
+func kronMatrix(name string, X matrix.Matrix, ld *int, off, rows, cols int) error {
+	xrows := *ld
+	if *ld == 0 {
+		*ld = max(1, X.LeadingIndex())
+		xrows = max(1, X.Rows())
+	}
+	if *ld < max(1, rows) {
+		return onError(linalg.ErrParameter, "Kron: inconsistent ld"+name)
+	}
+	if rows > 0 && cols > 0 && X.NumElements() < off+(cols-1)*xrows+rows {
+		return onError(linalg.ErrShape, "Kron: size"+name)
+	}
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
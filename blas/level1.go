@@ -9,12 +9,14 @@ package blas
 import (
 	//"errors"
 	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
 	"github.com/nvcook42/matrix"
 	"math"
-	"math/cmplx"
 )
 
-// Returns the Euclidean norm of a vector (returns ||x||_2). 
+// Returns the Euclidean norm of a vector (returns ||x||_2), or NaN for
+// invalid arguments. Computed in Go without intermediate overflow or
+// underflow for any input, see nrm2.go; zero for an empty vector.
 //
 // ARGUMENTS
 //  X         float or complex matrix
@@ -27,6 +29,7 @@ import (
 //  offset    nonnegative integer
 //
 func Nrm2(X matrix.Matrix, opts ...linalg.Option) (v matrix.Scalar) {
+	X = matops.Readable(X)
 	v = matrix.FScalar(math.NaN())
 	ind := linalg.GetIndexOpts(opts...)
 	err := check_level1_func(ind, fnrm2, X, nil)
@@ -34,22 +37,24 @@ func Nrm2(X matrix.Matrix, opts ...linalg.Option) (v matrix.Scalar) {
 		return
 	}
 	if ind.Nx == 0 {
+		v = matrix.FScalar(0.0)
 		return
 	}
 	switch X.(type) {
 	case *matrix.ComplexMatrix:
 		Xa := X.(*matrix.ComplexMatrix).ComplexArray()
-		v = matrix.FScalar(dznrm2(ind.Nx, Xa[ind.OffsetX:], ind.IncX))
+		v = matrix.FScalar(znrm2(ind.Nx, Xa[ind.OffsetX:], ind.IncX))
 	case *matrix.FloatMatrix:
 		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		v = matrix.FScalar(dnrm2(ind.Nx, Xa[ind.OffsetX:], ind.IncX))
+		v = matrix.FScalar(nrm2(ind.Nx, Xa[ind.OffsetX:], ind.IncX))
 	default:
 		//err = onError("not implemented for parameter types", )
 	}
 	return
 }
 
-// Returns ||Re x||_1 + ||Im x||_1.
+// Returns ||Re x||_1 + ||Im x||_1, or NaN for invalid arguments. The sum
+// over an empty vector is zero, as with AsumFloat and AsumComplex.
 //
 // ARGUMENTS
 //  X       float or complex matrix
@@ -62,6 +67,7 @@ func Nrm2(X matrix.Matrix, opts ...linalg.Option) (v matrix.Scalar) {
 //  offset  nonnegative integer
 //
 func Asum(X matrix.Matrix, opts ...linalg.Option) (v matrix.Scalar) {
+	X = matops.Readable(X)
 	v = matrix.FScalar(math.NaN())
 	ind := linalg.GetIndexOpts(opts...)
 	err := check_level1_func(ind, fasum, X, nil)
@@ -69,6 +75,7 @@ func Asum(X matrix.Matrix, opts ...linalg.Option) (v matrix.Scalar) {
 		return
 	}
 	if ind.Nx == 0 {
+		v = matrix.FScalar(0.0)
 		return
 	}
 	switch X.(type) {
@@ -84,6 +91,75 @@ func Asum(X matrix.Matrix, opts ...linalg.Option) (v matrix.Scalar) {
 	return
 }
 
+// Returns the index of the element of largest absolute value in a vector,
+// |x_k| for real and |Re x_k| + |Im x_k| for complex X. The index k is
+// counted in elements of the vector, that is, the element is
+// X[offset+k*inc]. Returns the first such index and -1 if n is zero.
+//
+// ARGUMENTS
+//  X       float or complex matrix
+//
+// OPTIONS
+//  n       integer.  If n<0, the default value of n is used.
+//          The default value is equal to n = 1+(len(x)-offset-1)/inc or 0 if
+//          len(x) > offset+1
+//  inc     positive integer
+//  offset  nonnegative integer
+//
+func Iamax(X matrix.Matrix, opts ...linalg.Option) (k int, err error) {
+	defer guard("Iamax", &err, opts...)()
+	X = matops.Readable(X)
+	k = -1
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level1_func(ind, fiamax, X, nil)
+	if err != nil || ind.Nx == 0 {
+		return
+	}
+	switch X := X.(type) {
+	case *matrix.ComplexMatrix:
+		k = izamax(ind.Nx, X.ComplexArray()[ind.OffsetX:], ind.IncX) - 1
+	case *matrix.FloatMatrix:
+		k = idamax(ind.Nx, X.FloatArray()[ind.OffsetX:], ind.IncX) - 1
+	default:
+		err = onError(linalg.ErrType, "not implemented for parameter types")
+	}
+	return
+}
+
+// Returns the index of the element of smallest absolute value in a
+// vector. BLAS has no such routine; this is computed in Go with the
+// conventions, arguments and options of Iamax.
+func Iamin(X matrix.Matrix, opts ...linalg.Option) (k int, err error) {
+	defer guard("Iamin", &err, opts...)()
+	X = matops.Readable(X)
+	k = -1
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level1_func(ind, fiamax, X, nil)
+	if err != nil || ind.Nx == 0 {
+		return
+	}
+	var abs func(i int) float64
+	switch X := X.(type) {
+	case *matrix.ComplexMatrix:
+		Xa := X.ComplexArray()[ind.OffsetX:]
+		abs = func(i int) float64 { return math.Abs(real(Xa[i])) + math.Abs(imag(Xa[i])) }
+	case *matrix.FloatMatrix:
+		Xa := X.FloatArray()[ind.OffsetX:]
+		abs = func(i int) float64 { return math.Abs(Xa[i]) }
+	default:
+		err = onError(linalg.ErrType, "not implemented for parameter types")
+		return
+	}
+	k = 0
+	amin := abs(0)
+	for i := 1; i < ind.Nx; i++ {
+		if v := abs(i * ind.IncX); v < amin {
+			k, amin = i, v
+		}
+	}
+	return
+}
+
 // Returns Y = X^T*Y for real or complex X, Y.
 //
 // ARGUMENTS
@@ -101,6 +177,7 @@ func Asum(X matrix.Matrix, opts ...linalg.Option) (v matrix.Scalar) {
 //  offsety   nonnegative integer, [default=0]
 //
 func Dotu(X, Y matrix.Matrix, opts ...linalg.Option) (v matrix.Scalar) {
+	X, Y = matops.Readable(X), matops.Readable(Y)
 	v = matrix.FScalar(math.NaN())
 	//cv = cmplx.NaN()
 	ind := linalg.GetIndexOpts(opts...)
@@ -113,7 +190,7 @@ func Dotu(X, Y matrix.Matrix, opts ...linalg.Option) (v matrix.Scalar) {
 	}
 	sameType := matrix.EqualTypes(X, Y)
 	if !sameType {
-		err = onError("arrays not of same type")
+		err = onError(linalg.ErrType, "arrays not of same type")
 		return
 	}
 	switch X.(type) {
@@ -148,6 +225,7 @@ func Dotu(X, Y matrix.Matrix, opts ...linalg.Option) (v matrix.Scalar) {
 //  offsety   nonnegative integer [default=0]
 //
 func Dot(X, Y matrix.Matrix, opts ...linalg.Option) (v matrix.Scalar) {
+	X, Y = matops.Readable(X), matops.Readable(Y)
 	v = matrix.FScalar(math.NaN())
 	//cv = cmplx.NaN()
 	ind := linalg.GetIndexOpts(opts...)
@@ -160,7 +238,7 @@ func Dot(X, Y matrix.Matrix, opts ...linalg.Option) (v matrix.Scalar) {
 	}
 	sameType := matrix.EqualTypes(X, Y)
 	if !sameType {
-		err = onError("arrays not of same type")
+		err = onError(linalg.ErrType, "arrays not of same type")
 		return
 	}
 	switch X.(type) {
@@ -196,6 +274,10 @@ func Dot(X, Y matrix.Matrix, opts ...linalg.Option) (v matrix.Scalar) {
 //  offsety   nonnegative integer;
 //
 func Swap(X, Y matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Swap", &err, opts...)()
+	if err = writable("Swap", X, Y); err != nil {
+		return
+	}
 	ind := linalg.GetIndexOpts(opts...)
 	err = check_level1_func(ind, fswap, X, Y)
 	if err != nil {
@@ -205,12 +287,12 @@ func Swap(X, Y matrix.Matrix, opts ...linalg.Option) (err error) {
 		return
 	}
 	//if ind.Nx != ind.Ny {
-	//	err = onError("arrays have unequal default lengths")
+	//	err = onError(linalg.ErrParameter, "arrays have unequal default lengths")
 	//	return
 	//}
 	sameType := matrix.EqualTypes(X, Y)
 	if !sameType {
-		err = onError("arrays not same type")
+		err = onError(linalg.ErrType, "arrays not same type")
 		return
 	}
 	switch X.(type) {
@@ -223,7 +305,7 @@ func Swap(X, Y matrix.Matrix, opts ...linalg.Option) (err error) {
 		Ya := Y.(*matrix.FloatMatrix).FloatArray()
 		dswap(ind.Nx, Xa[ind.OffsetX:], ind.IncX, Ya[ind.OffsetY:], ind.IncY)
 	default:
-		err = onError("not implemented for parameter types")
+		err = onError(linalg.ErrType, "not implemented for parameter types")
 	}
 	return
 }
@@ -244,6 +326,11 @@ func Swap(X, Y matrix.Matrix, opts ...linalg.Option) (err error) {
 //  offsety   nonnegative integer;
 //
 func Copy(X, Y matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Copy", &err, opts...)()
+	if err = writable("Copy", Y); err != nil {
+		return
+	}
+	X = matops.Readable(X)
 	ind := linalg.GetIndexOpts(opts...)
 	err = check_level1_func(ind, fcopy, X, Y)
 	if err != nil {
@@ -254,7 +341,7 @@ func Copy(X, Y matrix.Matrix, opts ...linalg.Option) (err error) {
 	}
 	sameType := matrix.EqualTypes(X, Y)
 	if !sameType {
-		err = onError("arrays not same type")
+		err = onError(linalg.ErrType, "arrays not same type")
 		return
 	}
 	switch X.(type) {
@@ -267,7 +354,7 @@ func Copy(X, Y matrix.Matrix, opts ...linalg.Option) (err error) {
 		Ya := Y.(*matrix.FloatMatrix).FloatArray()
 		dcopy(ind.Nx, Xa[ind.OffsetX:], ind.IncX, Ya[ind.OffsetY:], ind.IncY)
 	default:
-		err = onError("not implemented for parameter types")
+		err = onError(linalg.ErrType, "not implemented for parameter types")
 	}
 	return
 }
@@ -287,30 +374,29 @@ func Copy(X, Y matrix.Matrix, opts ...linalg.Option) (err error) {
 //  offset    nonnegative integer, default = 0
 //
 func Scal(X matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level1_func(ind, fscal, X, nil)
-	if err != nil {
+	defer guard("Scal", &err, opts...)()
+	if err = writable("Scal", X); err != nil {
 		return
 	}
-	if ind.Nx == 0 {
-		return
+	if !matrix.EqualTypes(X) {
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch X.(type) {
-	case *matrix.ComplexMatrix:
-		Xa := X.(*matrix.ComplexMatrix).ComplexArray()
-		cval := alpha.Complex()
-		zscal(ind.Nx, cval, Xa[ind.OffsetX:], ind.IncX)
 	case *matrix.FloatMatrix:
-		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		rval := alpha.Float()
-		if math.IsNaN(rval) {
-			return onError("alpha not float value")
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
+		}
+		return ScalFloat(X.(*matrix.FloatMatrix), a, opts...)
+	case *matrix.ComplexMatrix:
+		a, e := complexValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		dscal(ind.Nx, rval, Xa[ind.OffsetX:], ind.IncX)
+		return ScalComplex(X.(*matrix.ComplexMatrix), a, opts...)
 	default:
-		err = onError("not implemented for parameter types")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 // Calculate Y := alpha * X + Y. Y is set to new values.
@@ -334,41 +420,139 @@ func Scal(X matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err erro
 //   offsety   nonnegative integer;
 //
 func Axpy(X, Y matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
+	defer guard("Axpy", &err, opts...)()
+	if err = writable("Axpy", Y); err != nil {
+		return
+	}
+	X = matops.Readable(X)
+	if !matrix.EqualTypes(X, Y) {
+		return onError(linalg.ErrType, "Parameters not of same type")
+	}
+	switch X.(type) {
+	case *matrix.FloatMatrix:
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
+		}
+		return AxpyFloat(X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix), a, opts...)
+	case *matrix.ComplexMatrix:
+		a, e := complexValue("alpha", alpha)
+		if e != nil {
+			return e
+		}
+		return AxpyComplex(X.(*matrix.ComplexMatrix), Y.(*matrix.ComplexMatrix), a, opts...)
+	default:
+		return onError(linalg.ErrType, "Unknown type, not implemented")
+	}
+}
+
+// Constructs a Givens plane rotation (c, s) with
+//
+//	[ c  s ] [ a ]   [ r ]
+//	[-s  c ] [ b ] = [ 0 ]
+//
+// and returns c, s, r and z, where z encodes the rotation as in drotg.
+func Rotg(a, b float64) (c, s, r, z float64) {
+	drotg(&a, &b, &c, &s)
+	return c, s, a, b
+}
+
+// Applies a plane rotation to vectors X and Y
+// (x_i, y_i := c*x_i + s*y_i, c*y_i - s*x_i).
+//
+// ARGUMENTS
+//  X         float matrix
+//  Y         float matrix
+//  c, s      rotation, e.g. from Rotg
+//
+// OPTIONS
+//  n         integer.  If n<0, the default value of n is used.
+//            The default value is equal to 1+(len(x)-offsetx-1)/incx
+//            or 0 if  len(x) >= offsetx+1
+//  incx      nonzero integer
+//  incy      nonzero integer
+//  offsetx   nonnegative integer
+//  offsety   nonnegative integer;
+//
+func Rot(X, Y matrix.Matrix, c, s float64, opts ...linalg.Option) (err error) {
+	defer guard("Rot", &err, opts...)()
+	if err = writable("Rot", X, Y); err != nil {
+		return
+	}
 	ind := linalg.GetIndexOpts(opts...)
-	err = check_level1_func(ind, faxpy, X, Y)
+	err = check_level1_func(ind, frot, X, Y)
 	if err != nil {
 		return
 	}
 	if ind.Nx == 0 {
 		return
 	}
-	sameType := matrix.EqualTypes(X, Y)
-	if !sameType {
-		err = onError("arrays not same type")
+	Xm, okx := X.(*matrix.FloatMatrix)
+	Ym, oky := Y.(*matrix.FloatMatrix)
+	if !okx || !oky {
+		return onError(linalg.ErrType, "Rot: not implemented for parameter types")
+	}
+	drot(ind.Nx, Xm.FloatArray()[ind.OffsetX:], ind.IncX,
+		Ym.FloatArray()[ind.OffsetY:], ind.IncY, c, s)
+	return
+}
+
+// Constructs a modified Givens transformation H that zeros the second
+// component of the vector (sqrt(d1)*x1, sqrt(d2)*y1), as in drotmg.
+// Returns the updated scaling factors d1, d2, the updated x1 and parameter
+// P of H for Rotm:
+//
+//	P[0] = -1:  H = [ P[1] P[3] ; P[2] P[4] ]
+//	P[0] =  0:  H = [ 1    P[3] ; P[2] 1    ]
+//	P[0] =  1:  H = [ P[1] 1    ; -1   P[4] ]
+//	P[0] = -2:  H = identity
+//
+func Rotmg(d1, d2, x1, y1 float64) (rd1, rd2, rx1 float64, P []float64) {
+	P = make([]float64, 5)
+	drotmg(&d1, &d2, &x1, y1, P)
+	return d1, d2, x1, P
+}
+
+// Applies a modified Givens transformation H to vectors X and Y
+// ([x_i; y_i] := H*[x_i; y_i]).
+//
+// ARGUMENTS
+//  X         float matrix
+//  Y         float matrix
+//  P         array of 5 elements encoding H, as returned by Rotmg
+//
+// OPTIONS
+//  n         integer.  If n<0, the default value of n is used.
+//            The default value is equal to 1+(len(x)-offsetx-1)/incx
+//            or 0 if  len(x) >= offsetx+1
+//  incx      nonzero integer
+//  incy      nonzero integer
+//  offsetx   nonnegative integer
+//  offsety   nonnegative integer;
+//
+func Rotm(X, Y matrix.Matrix, P []float64, opts ...linalg.Option) (err error) {
+	defer guard("Rotm", &err, opts...)()
+	if err = writable("Rotm", X, Y); err != nil {
 		return
 	}
-	switch X.(type) {
-	case *matrix.ComplexMatrix:
-		Xa := X.(*matrix.ComplexMatrix).ComplexArray()
-		Ya := Y.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		if cmplx.IsNaN(aval) {
-			return onError("alpha not complex value")
-		}
-		zaxpy(ind.Nx, aval, Xa[ind.OffsetX:],
-			ind.IncX, Ya[ind.OffsetY:], ind.IncY)
-	case *matrix.FloatMatrix:
-		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		Ya := Y.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		if math.IsNaN(aval) {
-			return onError("alpha not float value")
-		}
-		daxpy(ind.Nx, aval, Xa[ind.OffsetX:],
-			ind.IncX, Ya[ind.OffsetY:], ind.IncY)
-	default:
-		err = onError("not implemented for parameter types")
+	if len(P) < 5 {
+		return onError(linalg.ErrShape, "Rotm: P must have 5 elements")
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level1_func(ind, frotm, X, Y)
+	if err != nil {
+		return
+	}
+	if ind.Nx == 0 {
+		return
+	}
+	Xm, okx := X.(*matrix.FloatMatrix)
+	Ym, oky := Y.(*matrix.FloatMatrix)
+	if !okx || !oky {
+		return onError(linalg.ErrType, "Rotm: not implemented for parameter types")
 	}
+	drotm(ind.Nx, Xm.FloatArray()[ind.OffsetX:], ind.IncX,
+		Ym.FloatArray()[ind.OffsetY:], ind.IncY, P)
 	return
 }
 
@@ -9,9 +9,8 @@ package blas
 import (
 	//"errors"
 	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
 	"github.com/nvcook42/matrix"
-	"math"
-	"math/cmplx"
 )
 
 /*
@@ -45,63 +44,32 @@ import (
   offsety   nonnegative integer
 */
 func Gemv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	var params *linalg.Parameters
-	params, err = linalg.GetParameters(opts...)
-	if err != nil {
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level2_func(ind, fgemv, X, Y, A, params)
-	if err != nil {
+	defer guard("Gemv", &err, opts...)()
+	if err = writable("Gemv", Y); err != nil {
 		return
 	}
+	A, X = matops.Readable(A), matops.Readable(X)
 	if !matrix.EqualTypes(A, X, Y) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
-	switch X.(type) {
+	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		Ya := Y.(*matrix.FloatMatrix).FloatArray()
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		bval := beta.Float()
-		if math.IsNaN(aval) || math.IsNaN(bval) {
-			return onError("alpha or beta not a number")
-		}
-		if params.Trans == linalg.PNoTrans && ind.N == 0 {
-			dscal(ind.M, bval, Ya[ind.OffsetY:], ind.IncY)
-		} else if params.Trans == linalg.PTrans && ind.M == 0 {
-			dscal(ind.N, bval, Ya[ind.OffsetY:], ind.IncY)
-		} else {
-			trans := linalg.ParamString(params.Trans)
-			dgemv(trans, ind.M, ind.N, aval, Aa[ind.OffsetA:],
-				ind.LDa, Xa[ind.OffsetX:], ind.IncX, bval,
-				Ya[ind.OffsetY:], ind.IncY)
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
+		return GemvFloat(A.(*matrix.FloatMatrix), X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix),
+			a, b, opts...)
 	case *matrix.ComplexMatrix:
-		Xa := X.(*matrix.ComplexMatrix).ComplexArray()
-		Ya := Y.(*matrix.ComplexMatrix).ComplexArray()
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		bval := beta.Complex()
-		if cmplx.IsNaN(aval) || cmplx.IsNaN(bval) {
-			return onError("alpha or beta not a number")
-		}
-		if params.Trans == linalg.PNoTrans && ind.N == 0 {
-			zscal(ind.M, bval, Ya[ind.OffsetY:], ind.IncY)
-		} else if params.Trans == linalg.PTrans && ind.M == 0 {
-			zscal(ind.N, bval, Ya[ind.OffsetY:], ind.IncY)
-		} else {
-			trans := linalg.ParamString(params.Trans)
-			zgemv(trans, ind.M, ind.N, aval, Aa[ind.OffsetA:],
-				ind.LDa, Xa[ind.OffsetX:], ind.IncX, bval,
-				Ya[ind.OffsetY:], ind.IncY)
+		a, b, e := complexScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
+		return GemvComplex(A.(*matrix.ComplexMatrix), X.(*matrix.ComplexMatrix), Y.(*matrix.ComplexMatrix),
+			a, b, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -118,11 +86,13 @@ func Gemv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Optio
 
 
  ARGUMENTS
-   X         float n*1 matrix.  
-   Y         float m*1 matrix
-   A         float m*n matrix.
-   alpha     number (float). 
-   beta      number (float).
+   X         float or complex n*1 matrix.
+   Y         float or complex m*1 matrix
+   A         float or complex matrix in band storage, see
+             matops.BandStorage, or *matops.BandMatrix, which sets
+             options m, n, kl and ku.
+   alpha     number (float or complex).
+   beta      number (float or complex).
 
  OPTIONS
    trans     NoTrans or Trans
@@ -139,49 +109,35 @@ func Gemv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Optio
 
 */
 func Gbmv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	var params *linalg.Parameters
-	params, err = linalg.GetParameters(opts...)
-	if err != nil {
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level2_func(ind, fgbmv, X, Y, A, params)
-	if err != nil {
+	defer guard("Gbmv", &err, opts...)()
+	if err = writable("Gbmv", Y); err != nil {
 		return
 	}
-	if ind.M == 0 && ind.N == 0 {
+	if A, opts, err = bandArgs("Gbmv", A, opts, false); err != nil {
 		return
 	}
+	A, X = matops.Readable(A), matops.Readable(X)
 	if !matrix.EqualTypes(A, X, Y) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
-	switch X.(type) {
+	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		Ya := Y.(*matrix.FloatMatrix).FloatArray()
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		bval := beta.Float()
-		if math.IsNaN(aval) || math.IsNaN(bval) {
-			return onError("alpha or beta not a number")
-		}
-		if params.Trans == linalg.PNoTrans && ind.N == 0 {
-			dscal(ind.M, bval, Ya[ind.OffsetY:], ind.IncY)
-		} else if params.Trans == linalg.PTrans && ind.M == 0 {
-			dscal(ind.N, bval, Ya[ind.OffsetY:], ind.IncY)
-		} else {
-			trans := linalg.ParamString(params.Trans)
-			dgbmv(trans, ind.M, ind.N, ind.Kl, ind.Ku,
-				aval, Aa[ind.OffsetA:], ind.LDa, Xa[ind.OffsetX:], ind.IncX,
-				bval, Ya[ind.OffsetY:], ind.IncY)
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
+		return GbmvFloat(A.(*matrix.FloatMatrix), X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix),
+			a, b, opts...)
 	case *matrix.ComplexMatrix:
-		return onError("Not implemented yet for complx.Matrix")
+		a, b, e := complexScalars(alpha, beta)
+		if e != nil {
+			return e
+		}
+		return GbmvComplex(A.(*matrix.ComplexMatrix), X.(*matrix.ComplexMatrix), Y.(*matrix.ComplexMatrix),
+			a, b, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -209,43 +165,27 @@ func Gbmv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Optio
   offsety   nonnegative integer
 */
 func Symv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	var params *linalg.Parameters
-	params, err = linalg.GetParameters(opts...)
-	if err != nil {
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level2_func(ind, fsymv, X, Y, A, params)
-	if err != nil {
-		return
-	}
-	if ind.N == 0 {
+	defer guard("Symv", &err, opts...)()
+	if err = writable("Symv", Y); err != nil {
 		return
 	}
+	A, X = matops.Readable(A), matops.Readable(X)
 	if !matrix.EqualTypes(A, X, Y) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
-	switch X.(type) {
+	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		Ya := Y.(*matrix.FloatMatrix).FloatArray()
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		bval := beta.Float()
-		if math.IsNaN(aval) || math.IsNaN(bval) {
-			return onError("alpha or beta not a number")
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		dsymv(uplo, ind.N, aval, Aa[ind.OffsetA:], ind.LDa,
-			Xa[ind.OffsetX:], ind.IncX,
-			bval, Ya[ind.OffsetY:], ind.IncY)
+		return SymvFloat(A.(*matrix.FloatMatrix), X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix),
+			a, b, opts...)
 	case *matrix.ComplexMatrix:
-		return onError("Symv not possible for ComplexMatrix")
+		return onError(linalg.ErrType, "Symv not possible for complx.Matrix")
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -276,76 +216,32 @@ func Symv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Optio
   offsety   nonnegative integer
 */
 func Hemv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	var params *linalg.Parameters
-	params, err = linalg.GetParameters(opts...)
-	if err != nil {
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level2_func(ind, fsymv, X, Y, A, params)
-	if err != nil {
-		return
-	}
-	if ind.N == 0 {
+	defer guard("Hemv", &err, opts...)()
+	if err = writable("Hemv", Y); err != nil {
 		return
 	}
+	A, X = matops.Readable(A), matops.Readable(X)
 	if !matrix.EqualTypes(A, X, Y) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
-	switch X.(type) {
+	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		Ya := Y.(*matrix.FloatMatrix).FloatArray()
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		bval := beta.Float()
-		if math.IsNaN(aval) || math.IsNaN(bval) {
-			return onError("alpha or beta not a number")
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		dsymv(uplo, ind.N, aval, Aa[ind.OffsetA:], ind.LDa,
-			Xa[ind.OffsetX:], ind.IncX,
-			bval, Ya[ind.OffsetY:], ind.IncY)
-
+		return SymvFloat(A.(*matrix.FloatMatrix), X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix),
+			a, b, opts...)
 	case *matrix.ComplexMatrix:
-		Xa := X.(*matrix.ComplexMatrix).ComplexArray()
-		Ya := Y.(*matrix.ComplexMatrix).ComplexArray()
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		/*
-			if alpha != nil {
-				aval = alpha.ComplexValue()
-				if cmplx.IsNaN(aval) {
-					if ! math.IsNaN(alpha.FloatValue()) {
-						aval = complex(alpha.FloatValue(), 0)
-					} else {
-						return onError("alpha not a number")
-					}
-				}
-			}
-		*/
-		bval := beta.Complex()
-		/*
-			if beta != nil {
-				bval := beta.ComplexValue()
-				if cmplx.IsNaN(bval) {
-					if ! math.IsNaN(beta.FloatValue()) {
-						bval = complex(beta.FloatValue(), 0)
-					} else {
-						return onError("beta not a number")
-					}
-				}
-			}
-		*/
-		uplo := linalg.ParamString(params.Uplo)
-		zhemv(uplo, ind.N, aval, Aa[ind.OffsetA:], ind.LDa,
-			Xa[ind.OffsetX:], ind.IncX,
-			bval, Ya[ind.OffsetY:], ind.IncY)
+		a, b, e := complexScalars(alpha, beta)
+		if e != nil {
+			return e
+		}
+		return HemvComplex(A.(*matrix.ComplexMatrix), X.(*matrix.ComplexMatrix), Y.(*matrix.ComplexMatrix),
+			a, b, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -359,6 +255,8 @@ func Hemv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Optio
 
  ARGUMENTS
    A         float or complex n*n matrix
+             or *matops.BandMatrix with kl or ku zero, which sets
+             options n, k and uplo.
    X         float or complex n*1 matrix
    Y         float or complex n*1 matrix
    alpha     number (float or complex singleton matrix)
@@ -378,43 +276,30 @@ func Hemv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Optio
 
 */
 func Sbmv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	var params *linalg.Parameters
-	params, err = linalg.GetParameters(opts...)
-	if err != nil {
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level2_func(ind, fsbmv, X, Y, A, params)
-	if err != nil {
+	defer guard("Sbmv", &err, opts...)()
+	if err = writable("Sbmv", Y); err != nil {
 		return
 	}
-	if ind.N == 0 {
+	if A, opts, err = bandArgs("Sbmv", A, opts, true); err != nil {
 		return
 	}
+	A, X = matops.Readable(A), matops.Readable(X)
 	if !matrix.EqualTypes(A, X, Y) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
-	switch X.(type) {
+	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		Ya := Y.(*matrix.FloatMatrix).FloatArray()
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		bval := beta.Float()
-		if math.IsNaN(aval) || math.IsNaN(bval) {
-			return onError("alpha or beta not a number")
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		dsbmv(uplo, ind.N, ind.K, aval, Aa[ind.OffsetA:], ind.LDa,
-			Xa[ind.OffsetX:], ind.IncX, bval, Ya[ind.OffsetY:], ind.IncY)
-
+		return SbmvFloat(A.(*matrix.FloatMatrix), X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix),
+			a, b, opts...)
 	case *matrix.ComplexMatrix:
-		return onError("Sbmv not possible for complx.Matrix")
+		return onError(linalg.ErrType, "Sbmv not possible for complx.Matrix")
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -425,6 +310,8 @@ func Sbmv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Optio
 
  ARGUMENTS
   A         float or complex n*n matrix
+            or *matops.BandMatrix with kl or ku zero, which sets
+            options n, k and uplo.
   X         float or complex n*1 matrix
   Y         float or complex n*1 matrix
   alpha     number (float or complex singleton matrix)
@@ -445,53 +332,35 @@ func Sbmv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Optio
 
 */
 func Hbmv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	var params *linalg.Parameters
-	params, err = linalg.GetParameters(opts...)
-	if err != nil {
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level2_func(ind, fsbmv, X, Y, A, params)
-	if err != nil {
+	defer guard("Hbmv", &err, opts...)()
+	if err = writable("Hbmv", Y); err != nil {
 		return
 	}
-	if ind.N == 0 {
+	if A, opts, err = bandArgs("Hbmv", A, opts, true); err != nil {
 		return
 	}
+	A, X = matops.Readable(A), matops.Readable(X)
 	if !matrix.EqualTypes(A, X, Y) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
-	switch X.(type) {
+	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		Ya := Y.(*matrix.FloatMatrix).FloatArray()
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		bval := beta.Float()
-		if math.IsNaN(aval) || math.IsNaN(bval) {
-			return onError("alpha or beta not a number")
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		dsbmv(uplo, ind.N, ind.K, aval, Aa[ind.OffsetA:], ind.LDa,
-			Xa[ind.OffsetX:], ind.IncX, bval, Ya[ind.OffsetY:], ind.IncY)
-
+		return SbmvFloat(A.(*matrix.FloatMatrix), X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix),
+			a, b, opts...)
 	case *matrix.ComplexMatrix:
-		Xa := X.(*matrix.ComplexMatrix).ComplexArray()
-		Ya := Y.(*matrix.ComplexMatrix).ComplexArray()
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		bval := beta.Complex()
-		uplo := linalg.ParamString(params.Uplo)
-		zhbmv(uplo, ind.N, ind.K, aval, Aa[ind.OffsetA:], ind.LDa,
-			Xa[ind.OffsetX:], ind.IncX, bval, Ya[ind.OffsetY:], ind.IncY)
-		//zhbmv(uplo, ind.N, aval, Aa[ind.OffsetA:], ind.LDa,
-		//	Xa[ind.OffsetX:], ind.IncX,
-		//	bval, Ya[ind.OffsetY:], ind.IncY)
+		a, b, e := complexScalars(alpha, beta)
+		if e != nil {
+			return e
+		}
+		return HbmvComplex(A.(*matrix.ComplexMatrix), X.(*matrix.ComplexMatrix), Y.(*matrix.ComplexMatrix),
+			a, b, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -525,6 +394,11 @@ func Hbmv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Optio
 
 */
 func Trmv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Trmv", &err, opts...)()
+	if err = writable("Trmv", X); err != nil {
+		return
+	}
+	A = matops.Readable(A)
 
 	var params *linalg.Parameters
 	params, err = linalg.GetParameters(opts...)
@@ -537,7 +411,7 @@ func Trmv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
 		return
 	}
 	if !matrix.EqualTypes(A, X) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch X.(type) {
 	case *matrix.FloatMatrix:
@@ -549,9 +423,9 @@ func Trmv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
 		dtrmv(uplo, trans, diag, ind.N,
 			Aa[ind.OffsetA:], ind.LDa, Xa[ind.OffsetX:], ind.IncX)
 	case *matrix.ComplexMatrix:
-		return onError("Not implemented yet for complx.Matrix")
+		return onError(linalg.ErrNotImplemented, "Not implemented yet for complx.Matrix")
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
 	return
 }
@@ -571,6 +445,8 @@ func Trmv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
 
  ARGUMENTS
   A         float or complex matrix
+            or *matops.BandMatrix with kl or ku zero, which sets
+            options n, k and uplo.
   X         float or complex  matrix.  Must have the same type as A.
 
  OPTIONS
@@ -587,10 +463,18 @@ func Trmv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
 
 */
 func Tbmv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Tbmv", &err, opts...)()
+	if err = writable("Tbmv", X); err != nil {
+		return
+	}
+	if A, opts, err = bandArgs("Tbmv", A, opts, true); err != nil {
+		return
+	}
+	A = matops.Readable(A)
 
 	var params *linalg.Parameters
 	if !matrix.EqualTypes(A, X) {
-		err = onError("Parameters not of same type")
+		err = onError(linalg.ErrType, "Parameters not of same type")
 		return
 	}
 	params, err = linalg.GetParameters(opts...)
@@ -615,9 +499,15 @@ func Tbmv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
 		dtbmv(uplo, trans, diag, ind.N, ind.K,
 			Aa[ind.OffsetA:], ind.LDa, Xa[ind.OffsetX:], ind.IncX)
 	case *matrix.ComplexMatrix:
-		return onError("Not implemented yet for ComplexMatrix")
+		Xa := X.(*matrix.ComplexMatrix).ComplexArray()
+		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
+		uplo := linalg.ParamString(params.Uplo)
+		trans := linalg.ParamString(params.Trans)
+		diag := linalg.ParamString(params.Diag)
+		ztbmv(uplo, trans, diag, ind.N, ind.K,
+			Aa[ind.OffsetA:], ind.LDa, Xa[ind.OffsetX:], ind.IncX)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
 	return
 }
@@ -651,10 +541,15 @@ func Tbmv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
   offsetx   nonnegative integer
 */
 func Trsv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Trsv", &err, opts...)()
+	if err = writable("Trsv", X); err != nil {
+		return
+	}
+	A = matops.Readable(A)
 
 	var params *linalg.Parameters
 	if !matrix.EqualTypes(A, X) {
-		err = onError("Parameters not of same type")
+		err = onError(linalg.ErrType, "Parameters not of same type")
 		return
 	}
 	params, err = linalg.GetParameters(opts...)
@@ -679,9 +574,9 @@ func Trsv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
 		dtrsv(uplo, trans, diag, ind.N,
 			Aa[ind.OffsetA:], ind.LDa, Xa[ind.OffsetX:], ind.IncX)
 	case *matrix.ComplexMatrix:
-		return onError("Not implemented yet for complx.Matrix")
+		return onError(linalg.ErrNotImplemented, "Not implemented yet for complx.Matrix")
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
 	return
 }
@@ -701,6 +596,8 @@ PURPOSE
 
  ARGUMENTS
   A         float or complex m*k matrix.
+            or *matops.BandMatrix with kl or ku zero, which sets
+            options n, k and uplo.
   X         float or complex k*1 matrix. Must have the same type as A.
 
  OPTIONS
@@ -716,10 +613,18 @@ PURPOSE
   offsetx   nonnegative integer;
 */
 func Tbsv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Tbsv", &err, opts...)()
+	if err = writable("Tbsv", X); err != nil {
+		return
+	}
+	if A, opts, err = bandArgs("Tbsv", A, opts, true); err != nil {
+		return
+	}
+	A = matops.Readable(A)
 
 	var params *linalg.Parameters
 	if !matrix.EqualTypes(A, X) {
-		err = onError("Parameters not of same type")
+		err = onError(linalg.ErrType, "Parameters not of same type")
 		return
 	}
 	params, err = linalg.GetParameters(opts...)
@@ -744,9 +649,15 @@ func Tbsv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
 		dtbsv(uplo, trans, diag, ind.N, ind.K,
 			Aa[ind.OffsetA:], ind.LDa, Xa[ind.OffsetX:], ind.IncX)
 	case *matrix.ComplexMatrix:
-		return onError("Not implemented yet for complx.Matrix")
+		Xa := X.(*matrix.ComplexMatrix).ComplexArray()
+		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
+		uplo := linalg.ParamString(params.Uplo)
+		trans := linalg.ParamString(params.Trans)
+		diag := linalg.ParamString(params.Diag)
+		ztbsv(uplo, trans, diag, ind.N, ind.K,
+			Aa[ind.OffsetA:], ind.LDa, Xa[ind.OffsetX:], ind.IncX)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
 	return
 }
@@ -779,51 +690,32 @@ func Tbsv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
 
 */
 func Ger(X, Y, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	var params *linalg.Parameters
-	if !matrix.EqualTypes(A, X, Y) {
-		err = onError("Parameters not of same type")
+	defer guard("Ger", &err, opts...)()
+	if err = writable("Ger", A); err != nil {
 		return
 	}
-	params, err = linalg.GetParameters(opts...)
-	if err != nil {
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level2_func(ind, fger, X, Y, A, params)
-	if err != nil {
-		return
-	}
-	if ind.N == 0 || ind.M == 0 {
-		return
+	X, Y = matops.Readable(X), matops.Readable(Y)
+	if !matrix.EqualTypes(X, Y, A) {
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch X.(type) {
 	case *matrix.FloatMatrix:
-		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		Ya := Y.(*matrix.FloatMatrix).FloatArray()
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		if math.IsNaN(aval) {
-			return onError("alpha not a number")
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		dger(ind.M, ind.N, aval, Xa[ind.OffsetX:], ind.IncX,
-			Ya[ind.OffsetY:], ind.IncY, Aa[ind.OffsetA:], ind.LDa)
-
+		return GerFloat(X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix), A.(*matrix.FloatMatrix),
+			a, opts...)
 	case *matrix.ComplexMatrix:
-		Xa := X.(*matrix.ComplexMatrix).ComplexArray()
-		Ya := Y.(*matrix.ComplexMatrix).ComplexArray()
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		if cmplx.IsNaN(aval) {
-			return onError("alpha not a number")
+		a, e := complexValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		zgerc(ind.M, ind.N, aval, Xa[ind.OffsetX:], ind.IncX,
-			Ya[ind.OffsetY:], ind.IncY, Aa[ind.OffsetA:], ind.LDa)
-
+		return GercComplex(X.(*matrix.ComplexMatrix), Y.(*matrix.ComplexMatrix), A.(*matrix.ComplexMatrix),
+			a, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -835,6 +727,8 @@ func Ger(X, Y, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err
  COMPUTES
   A := A + alpha*X*Y^T with A m*n, real or complex.
 
+ Y is not conjugated; for the conjugated update A + alpha*X*Y^H use Gerc.
+
  ARGUMENTS
   X         float or complex matrix.
   Y         float or complex matrix. Must have the same type as X.
@@ -854,50 +748,91 @@ func Ger(X, Y, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err
 
 */
 func Geru(X, Y, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	var params *linalg.Parameters
-	params, err = linalg.GetParameters(opts...)
-	if err != nil {
+	defer guard("Geru", &err, opts...)()
+	if err = writable("Geru", A); err != nil {
 		return
 	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level2_func(ind, fger, X, Y, A, params)
-	if err != nil {
-		return
+	X, Y = matops.Readable(X), matops.Readable(Y)
+	if !matrix.EqualTypes(X, Y, A) {
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
-	if ind.M == 0 || ind.N == 0 {
+	switch X.(type) {
+	case *matrix.FloatMatrix:
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
+		}
+		return GerFloat(X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix), A.(*matrix.FloatMatrix),
+			a, opts...)
+	case *matrix.ComplexMatrix:
+		a, e := complexValue("alpha", alpha)
+		if e != nil {
+			return e
+		}
+		return GeruComplex(X.(*matrix.ComplexMatrix), Y.(*matrix.ComplexMatrix), A.(*matrix.ComplexMatrix),
+			a, opts...)
+	default:
+		return onError(linalg.ErrType, "Unknown type, not implemented")
+	}
+}
+
+/*
+ General conjugated rank-1 update. (L2)
+
+ Gerc(X, Y, A, alpha=1.0, m=A.Rows, n=A.Cols, incx=1,
+ incy=1, ldA=max(1,A.Rows), offsetx=0, offsety=0, offsetA=0)
+
+ COMPUTES
+  A := A + alpha*X*Y^H with A m*n, real or complex.
+
+ Same as Ger. For float matrices Y^H = Y^T and Gerc, Geru and Ger
+ compute the same update.
+
+ ARGUMENTS
+  X         float or complex matrix.
+  Y         float or complex matrix. Must have the same type as X.
+  A         float or complex matrix. Must have the same type as X.
+  alpha     number (float or complex singleton matrix).
+
+ OPTIONS
+  m         integer.  If negative, the default value is used.
+  n         integer.  If negative, the default value is used.
+  incx      nonzero integer
+  incy      nonzero integer
+  ldA       nonnegative integer.  ldA >= max(1,m).
+            If zero, the default value is used.
+  offsetx   nonnegative integer
+  offsety   nonnegative integer
+  offsetA   nonnegative integer;
+
+*/
+func Gerc(X, Y, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
+	defer guard("Gerc", &err, opts...)()
+	if err = writable("Gerc", A); err != nil {
 		return
 	}
-	if !matrix.EqualTypes(A, X, Y) {
-		return onError("Parameters not of same type")
+	X, Y = matops.Readable(X), matops.Readable(Y)
+	if !matrix.EqualTypes(X, Y, A) {
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch X.(type) {
 	case *matrix.FloatMatrix:
-		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		Ya := Y.(*matrix.FloatMatrix).FloatArray()
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		if math.IsNaN(aval) {
-			return onError("alpha not a number")
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		dger(ind.M, ind.N, aval, Xa[ind.OffsetX:], ind.IncX,
-			Ya[ind.OffsetY:], ind.IncY, Aa[ind.OffsetA:], ind.LDa)
-
+		return GerFloat(X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix), A.(*matrix.FloatMatrix),
+			a, opts...)
 	case *matrix.ComplexMatrix:
-		Xa := X.(*matrix.ComplexMatrix).ComplexArray()
-		Ya := Y.(*matrix.ComplexMatrix).ComplexArray()
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		if cmplx.IsNaN(aval) {
-			return onError("alpha not a number")
+		a, e := complexValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		zgeru(ind.M, ind.N, aval, Xa[ind.OffsetX:], ind.IncX,
-			Ya[ind.OffsetY:], ind.IncY, Aa[ind.OffsetA:], ind.LDa)
-
+		return GercComplex(X.(*matrix.ComplexMatrix), Y.(*matrix.ComplexMatrix), A.(*matrix.ComplexMatrix),
+			a, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -926,40 +861,26 @@ func Geru(X, Y, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (er
   offsetA   nonnegative integer;
 */
 func Syr(X, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	var params *linalg.Parameters
-	params, err = linalg.GetParameters(opts...)
-	if err != nil {
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level2_func(ind, fsyr, X, nil, A, params)
-	if err != nil {
-		return
-	}
-	if ind.N == 0 {
+	defer guard("Syr", &err, opts...)()
+	if err = writable("Syr", A); err != nil {
 		return
 	}
-	if !matrix.EqualTypes(A, X) {
-		return onError("Parameters not of same type")
+	X = matops.Readable(X)
+	if !matrix.EqualTypes(X, A) {
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch X.(type) {
 	case *matrix.FloatMatrix:
-		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		uplo := linalg.ParamString(params.Uplo)
-		aval := alpha.Float()
-		if math.IsNaN(aval) {
-			return onError("alpha not a number")
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		dsyr(uplo, ind.N, aval, Xa[ind.OffsetX:], ind.IncX,
-			Aa[ind.OffsetA:], ind.LDa)
+		return SyrFloat(X.(*matrix.FloatMatrix), A.(*matrix.FloatMatrix), a, opts...)
 	case *matrix.ComplexMatrix:
-		return onError("Syr not possible for complx.Matrix")
+		return onError(linalg.ErrType, "Syr not possible for complx.Matrix")
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -989,48 +910,30 @@ func Syr(X, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err er
 
 */
 func Her(X, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	var params *linalg.Parameters
-	params, err = linalg.GetParameters(opts...)
-	if err != nil {
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level2_func(ind, fsyr, X, nil, A, params)
-	if err != nil {
-		return
-	}
-	if ind.N == 0 {
+	defer guard("Her", &err, opts...)()
+	if err = writable("Her", A); err != nil {
 		return
 	}
-	if !matrix.EqualTypes(A, X) {
-		return onError("Parameters not of same type")
+	X = matops.Readable(X)
+	if !matrix.EqualTypes(X, A) {
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch X.(type) {
 	case *matrix.FloatMatrix:
-		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		uplo := linalg.ParamString(params.Uplo)
-		aval := alpha.Float()
-		if math.IsNaN(aval) {
-			return onError("alpha not a number")
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		dsyr(uplo, ind.N, aval, Xa[ind.OffsetX:], ind.IncX,
-			Aa[ind.OffsetA:], ind.LDa)
+		return SyrFloat(X.(*matrix.FloatMatrix), A.(*matrix.FloatMatrix), a, opts...)
 	case *matrix.ComplexMatrix:
-		Xa := X.(*matrix.ComplexMatrix).ComplexArray()
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		uplo := linalg.ParamString(params.Uplo)
-		aval := alpha.Float()
-		if math.IsNaN(aval) {
-			return onError("alpha not a number")
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		zher(uplo, ind.N, aval, Xa[ind.OffsetX:], ind.IncX,
-			Aa[ind.OffsetA:], ind.LDa)
+		return HerComplex(X.(*matrix.ComplexMatrix), A.(*matrix.ComplexMatrix), a, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -1057,39 +960,27 @@ func Her(X, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err er
  offsetA   nonnegative integer;
 */
 func Syr2(X, Y, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	var params *linalg.Parameters
-	params, err = linalg.GetParameters(opts...)
-	if err != nil {
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level2_func(ind, fsyr2, X, Y, A, params)
-	if err != nil {
+	defer guard("Syr2", &err, opts...)()
+	if err = writable("Syr2", A); err != nil {
 		return
 	}
-	if !matrix.EqualTypes(A, X, Y) {
-		return onError("Parameters not of same type")
+	X, Y = matops.Readable(X), matops.Readable(Y)
+	if !matrix.EqualTypes(X, Y, A) {
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch X.(type) {
 	case *matrix.FloatMatrix:
-		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		Ya := X.(*matrix.FloatMatrix).FloatArray()
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		if math.IsNaN(aval) {
-			return onError("alpha not a number")
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		dsyr2(uplo, ind.N, aval, Xa[ind.OffsetX:], ind.IncX,
-			Ya[ind.OffsetY:], ind.IncY,
-			Aa[ind.OffsetA:], ind.LDa)
+		return Syr2Float(X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix), A.(*matrix.FloatMatrix),
+			a, opts...)
 	case *matrix.ComplexMatrix:
-		return onError("Not implemented yet for complx.Matrix")
+		return onError(linalg.ErrType, "Syr2 not possible for complx.Matrix")
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -1118,53 +1009,534 @@ func Syr2(X, Y, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (er
  offsetA   nonnegative integer;
 */
 func Her2(X, Y, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	var params *linalg.Parameters
-	params, err = linalg.GetParameters(opts...)
-	if err != nil {
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level2_func(ind, fsyr2, X, Y, A, params)
-	if err != nil {
+	defer guard("Her2", &err, opts...)()
+	if err = writable("Her2", A); err != nil {
 		return
 	}
-	if !matrix.EqualTypes(A, X, Y) {
-		return onError("Parameters not of same type")
+	X, Y = matops.Readable(X), matops.Readable(Y)
+	if !matrix.EqualTypes(X, Y, A) {
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch X.(type) {
 	case *matrix.FloatMatrix:
-		Xa := X.(*matrix.FloatMatrix).FloatArray()
-		Ya := X.(*matrix.FloatMatrix).FloatArray()
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		if math.IsNaN(aval) {
-			return onError("alpha not a number")
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		dsyr2(uplo, ind.N, aval, Xa[ind.OffsetX:], ind.IncX,
-			Ya[ind.OffsetY:], ind.IncY,
-			Aa[ind.OffsetA:], ind.LDa)
+		return Syr2Float(X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix), A.(*matrix.FloatMatrix),
+			a, opts...)
 	case *matrix.ComplexMatrix:
-		Xa := X.(*matrix.ComplexMatrix).ComplexArray()
-		Ya := X.(*matrix.ComplexMatrix).ComplexArray()
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		if cmplx.IsNaN(aval) {
-			return onError("alpha not a number")
+		a, e := complexValue("alpha", alpha)
+		if e != nil {
+			return e
+		}
+		return Her2Complex(X.(*matrix.ComplexMatrix), Y.(*matrix.ComplexMatrix), A.(*matrix.ComplexMatrix),
+			a, opts...)
+	default:
+		return onError(linalg.ErrType, "Unknown type, not implemented")
+	}
+}
+
+/*
+ Matrix-vector product with a real symmetric packed matrix. (L2)
+
+ Spmv(A, X, Y, alpha=1.0, beta=0.0, uplo=PLower, n=-1,
+ incx=1, incy=1, offsetA=0, offsetx=0, offsety=0)
+
+ COMPUTES
+  Y := alpha*A*X + beta*Y
+
+ A real symmetric of order n, with the lower or upper triangle stored
+ columnwise in n*(n+1)/2 elements.
+
+ ARGUMENTS
+  A         float matrix
+  X         float n*1 matrix
+  Y         float n*1 matrix
+  alpha     number (float or complex singleton matrix)
+  beta      number (float or complex singleton matrix)
+
+ OPTIONS
+  uplo      PLower or PUpper
+  n         integer.  If negative, the default value is used. The default
+            value is n with n*(n+1)/2 = A.NumElements()-offsetA.
+  incx      nonzero integer
+  incy      nonzero integer
+  offsetA   nonnegative integer
+  offsetx   nonnegative integer
+  offsety   nonnegative integer
+
+*/
+func Spmv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
+	defer guard("Spmv", &err, opts...)()
+	if err = writable("Spmv", Y); err != nil {
+		return
+	}
+	A, X = matops.Readable(A), matops.Readable(X)
+	if !matrix.EqualTypes(A, X, Y) {
+		return onError(linalg.ErrType, "Parameters not of same type")
+	}
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
+		}
+		return SpmvFloat(A.(*matrix.FloatMatrix), X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix),
+			a, b, opts...)
+	case *matrix.ComplexMatrix:
+		return onError(linalg.ErrType, "Spmv not possible for complx.Matrix")
+	default:
+		return onError(linalg.ErrType, "Unknown type, not implemented")
+	}
+}
+
+/*
+ Matrix-vector product with a real symmetric or complex hermitian packed
+ matrix. (L2)
+
+ Hpmv(A, X, Y, alpha=1.0, beta=0.0, uplo=PLower, n=-1,
+ incx=1, incy=1, offsetA=0, offsetx=0, offsety=0)
+
+ COMPUTES
+  Y := alpha*A*X + beta*Y
+
+ A real symmetric or complex hermitian of order n, with the lower or upper
+ triangle stored columnwise in n*(n+1)/2 elements.
+
+ ARGUMENTS
+  A         float or complex matrix
+  X         float or complex n*1 matrix
+  Y         float or complex n*1 matrix
+  alpha     number (float or complex singleton matrix)
+  beta      number (float or complex singleton matrix)
+
+ OPTIONS
+  uplo      PLower or PUpper
+  n         integer.  If negative, the default value is used. The default
+            value is n with n*(n+1)/2 = A.NumElements()-offsetA.
+  incx      nonzero integer
+  incy      nonzero integer
+  offsetA   nonnegative integer
+  offsetx   nonnegative integer
+  offsety   nonnegative integer
+
+*/
+func Hpmv(A, X, Y matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
+	defer guard("Hpmv", &err, opts...)()
+	if err = writable("Hpmv", Y); err != nil {
+		return
+	}
+	A, X = matops.Readable(A), matops.Readable(X)
+	if !matrix.EqualTypes(A, X, Y) {
+		return onError(linalg.ErrType, "Parameters not of same type")
+	}
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
+		}
+		return SpmvFloat(A.(*matrix.FloatMatrix), X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix),
+			a, b, opts...)
+	case *matrix.ComplexMatrix:
+		a, b, e := complexScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
+		return HpmvComplex(A.(*matrix.ComplexMatrix), X.(*matrix.ComplexMatrix), Y.(*matrix.ComplexMatrix),
+			a, b, opts...)
+	default:
+		return onError(linalg.ErrType, "Unknown type, not implemented")
+	}
+}
+
+/*
+ Matrix-vector product with a triangular packed matrix. (L2)
+
+ Tpmv(A, x, uplo=PLower, trans=PNoTrans, diag=PNonUnit, n=-1,
+ incx=1, offsetA=0, offsetx=0)
+
+ COMPUTES
+  X := A*X,   if trans is PNoTrans
+  X := A^T*X, if trans is PTrans
+  X := A^H*X, if trans is PConjTrans
+
+ A is triangular of order n, stored columnwise in n*(n+1)/2 elements.
+
+ ARGUMENTS
+  A         float or complex matrix
+  X         float or complex matrix.  Must have the same type as A.
+
+ OPTIONS
+  uplo      PLower or PUpper
+  trans     PNoTrans, PTrans or PConjTrans
+  diag      PNonUnit or PUnit
+  n         integer.  If negative, the default value is used. The default
+            value is n with n*(n+1)/2 = A.NumElements()-offsetA.
+  incx      nonzero integer
+  offsetA   nonnegative integer
+  offsetx   nonnegative integer
+
+*/
+func Tpmv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Tpmv", &err, opts...)()
+	if err = writable("Tpmv", X); err != nil {
+		return
+	}
+	A = matops.Readable(A)
+
+	var params *linalg.Parameters
+	if !matrix.EqualTypes(A, X) {
+		err = onError(linalg.ErrType, "Parameters not of same type")
+		return
+	}
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, ftpmv, X, nil, A, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	switch X.(type) {
+	case *matrix.FloatMatrix:
+		Xa := X.(*matrix.FloatMatrix).FloatArray()
+		Aa := A.(*matrix.FloatMatrix).FloatArray()
 		uplo := linalg.ParamString(params.Uplo)
-		zher2(uplo, ind.N, aval, Xa[ind.OffsetX:], ind.IncX,
-			Ya[ind.OffsetY:], ind.IncY,
-			Aa[ind.OffsetA:], ind.LDa)
-		//zher(uplo, ind.N, aval, Xa[ind.OffsetX:], ind.IncX,
-		//	Aa[ind.OffsetA:], ind.LDa)
+		trans := linalg.ParamString(params.Trans)
+		diag := linalg.ParamString(params.Diag)
+		dtpmv(uplo, trans, diag, ind.N, Aa[ind.OffsetA:],
+			Xa[ind.OffsetX:], ind.IncX)
+	case *matrix.ComplexMatrix:
+		Xa := X.(*matrix.ComplexMatrix).ComplexArray()
+		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
+		uplo := linalg.ParamString(params.Uplo)
+		trans := linalg.ParamString(params.Trans)
+		diag := linalg.ParamString(params.Diag)
+		ztpmv(uplo, trans, diag, ind.N, Aa[ind.OffsetA:],
+			Xa[ind.OffsetX:], ind.IncX)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
 	return
 }
 
+/*
+ Solution of a triangular and packed set of equations with one righthand
+ side. (L2)
+
+ Tpsv(A, x, uplo=PLower, trans=PNoTrans, diag=PNonUnit, n=-1,
+ incx=1, offsetA=0, offsetx=0)
+
+ COMPUTES
+  X := A^{-1}*X, if trans is PNoTrans
+  X := A^{-T}*X, if trans is PTrans
+  X := A^{-H}*X, if trans is PConjTrans
+
+ A is triangular of order n, stored columnwise in n*(n+1)/2 elements.
+ The code does not verify whether A is nonsingular.
+
+ ARGUMENTS
+  A         float or complex matrix
+  X         float or complex matrix.  Must have the same type as A.
+
+ OPTIONS
+  uplo      PLower or PUpper
+  trans     PNoTrans, PTrans or PConjTrans
+  diag      PNonUnit or PUnit
+  n         integer.  If negative, the default value is used. The default
+            value is n with n*(n+1)/2 = A.NumElements()-offsetA.
+  incx      nonzero integer
+  offsetA   nonnegative integer
+  offsetx   nonnegative integer
+
+*/
+func Tpsv(A, X matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Tpsv", &err, opts...)()
+	if err = writable("Tpsv", X); err != nil {
+		return
+	}
+	A = matops.Readable(A)
+
+	var params *linalg.Parameters
+	if !matrix.EqualTypes(A, X) {
+		err = onError(linalg.ErrType, "Parameters not of same type")
+		return
+	}
+	params, err = linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = check_level2_func(ind, ftpsv, X, nil, A, params)
+	if err != nil {
+		return
+	}
+	if ind.N == 0 {
+		return
+	}
+	switch X.(type) {
+	case *matrix.FloatMatrix:
+		Xa := X.(*matrix.FloatMatrix).FloatArray()
+		Aa := A.(*matrix.FloatMatrix).FloatArray()
+		uplo := linalg.ParamString(params.Uplo)
+		trans := linalg.ParamString(params.Trans)
+		diag := linalg.ParamString(params.Diag)
+		dtpsv(uplo, trans, diag, ind.N, Aa[ind.OffsetA:],
+			Xa[ind.OffsetX:], ind.IncX)
+	case *matrix.ComplexMatrix:
+		Xa := X.(*matrix.ComplexMatrix).ComplexArray()
+		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
+		uplo := linalg.ParamString(params.Uplo)
+		trans := linalg.ParamString(params.Trans)
+		diag := linalg.ParamString(params.Diag)
+		ztpsv(uplo, trans, diag, ind.N, Aa[ind.OffsetA:],
+			Xa[ind.OffsetX:], ind.IncX)
+	default:
+		return onError(linalg.ErrType, "Unknown type, not implemented")
+	}
+	return
+}
+
+/*
+ Symmetric packed rank-1 update. (L2)
+
+ Spr(X, A, alpha=1.0, uplo=PLower, n=-1, incx=1, offsetx=0, offsetA=0)
+
+ COMPUTES
+  A := A + alpha*X*X^T
+
+ A real symmetric matrix of order n, with the lower or upper triangle
+ stored columnwise in n*(n+1)/2 elements.
+
+ ARGUMENTS
+  X         float matrix.
+  A         float matrix.
+  alpha     real number
+
+ OPTIONS:
+  uplo      PLower or PUpper
+  n         integer.  If negative, the default value is used. The default
+            value is n with n*(n+1)/2 = A.NumElements()-offsetA.
+  incx      nonzero integer
+  offsetx   nonnegative integer
+  offsetA   nonnegative integer;
+*/
+func Spr(X, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
+	defer guard("Spr", &err, opts...)()
+	if err = writable("Spr", A); err != nil {
+		return
+	}
+	X = matops.Readable(X)
+	if !matrix.EqualTypes(X, A) {
+		return onError(linalg.ErrType, "Parameters not of same type")
+	}
+	switch X.(type) {
+	case *matrix.FloatMatrix:
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
+		}
+		return SprFloat(X.(*matrix.FloatMatrix), A.(*matrix.FloatMatrix), a, opts...)
+	case *matrix.ComplexMatrix:
+		return onError(linalg.ErrType, "Spr not possible for complx.Matrix")
+	default:
+		return onError(linalg.ErrType, "Unknown type, not implemented")
+	}
+}
+
+/*
+ Hermitian packed rank-1 update. (L2)
+
+ Hpr(X, A, alpha=1.0, uplo=PLower, n=-1, incx=1, offsetx=0, offsetA=0)
+
+ COMPUTES
+  A := A + alpha*X*X^H
+
+ A real symmetric or complex hermitian matrix of order n, with the lower
+ or upper triangle stored columnwise in n*(n+1)/2 elements.
+
+ ARGUMENTS
+  X         float or complex matrix.
+  A         float or complex matrix.
+  alpha     real number
+
+ OPTIONS:
+  uplo      PLower or PUpper
+  n         integer.  If negative, the default value is used. The default
+            value is n with n*(n+1)/2 = A.NumElements()-offsetA.
+  incx      nonzero integer
+  offsetx   nonnegative integer
+  offsetA   nonnegative integer;
+*/
+func Hpr(X, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
+	defer guard("Hpr", &err, opts...)()
+	if err = writable("Hpr", A); err != nil {
+		return
+	}
+	X = matops.Readable(X)
+	if !matrix.EqualTypes(X, A) {
+		return onError(linalg.ErrType, "Parameters not of same type")
+	}
+	switch X.(type) {
+	case *matrix.FloatMatrix:
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
+		}
+		return SprFloat(X.(*matrix.FloatMatrix), A.(*matrix.FloatMatrix), a, opts...)
+	case *matrix.ComplexMatrix:
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
+		}
+		return HprComplex(X.(*matrix.ComplexMatrix), A.(*matrix.ComplexMatrix), a, opts...)
+	default:
+		return onError(linalg.ErrType, "Unknown type, not implemented")
+	}
+}
+
+/*
+ Symmetric packed rank-2 update. (L2)
+
+ Spr2(X, Y, A, alpha=1.0, uplo=PLower, n=-1, incx=1, incy=1,
+ offsetx=0, offsety=0, offsetA=0)
+
+ COMPUTES
+  A := A + alpha*(X*Y^T + Y*X^T)
+
+ A real symmetric matrix of order n, with the lower or upper triangle
+ stored columnwise in n*(n+1)/2 elements.
+
+ ARGUMENTS
+  X         float matrix
+  Y         float matrix
+  A         float matrix
+  alpha     real number
+
+ OPTIONS
+  uplo      PLower or PUpper
+  n         integer.  If negative, the default value is used. The default
+            value is n with n*(n+1)/2 = A.NumElements()-offsetA.
+  incx      nonzero integer
+  incy      nonzero integer
+  offsetx   nonnegative integer
+  offsety   nonnegative integer
+  offsetA   nonnegative integer;
+*/
+func Spr2(X, Y, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
+	defer guard("Spr2", &err, opts...)()
+	if err = writable("Spr2", A); err != nil {
+		return
+	}
+	X, Y = matops.Readable(X), matops.Readable(Y)
+	if !matrix.EqualTypes(X, Y, A) {
+		return onError(linalg.ErrType, "Parameters not of same type")
+	}
+	switch X.(type) {
+	case *matrix.FloatMatrix:
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
+		}
+		return Spr2Float(X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix), A.(*matrix.FloatMatrix),
+			a, opts...)
+	case *matrix.ComplexMatrix:
+		return onError(linalg.ErrType, "Spr2 not possible for complx.Matrix")
+	default:
+		return onError(linalg.ErrType, "Unknown type, not implemented")
+	}
+}
+
+/*
+ Hermitian packed rank-2 update. (L2)
+
+ Hpr2(X, Y, A, alpha=1.0, uplo=PLower, n=-1, incx=1, incy=1,
+ offsetx=0, offsety=0, offsetA=0)
+
+ COMPUTES
+  A := A + alpha*X*Y^H + conj(alpha)*Y*X^H
+
+ A real symmetric or complex hermitian matrix of order n, with the lower
+ or upper triangle stored columnwise in n*(n+1)/2 elements.
+
+ ARGUMENTS
+  X         float or complex matrix
+  Y         float or complex matrix
+  A         float or complex matrix
+  alpha     float or complex singleton value
+
+ OPTIONS
+  uplo      PLower or PUpper
+  n         integer.  If negative, the default value is used. The default
+            value is n with n*(n+1)/2 = A.NumElements()-offsetA.
+  incx      nonzero integer
+  incy      nonzero integer
+  offsetx   nonnegative integer
+  offsety   nonnegative integer
+  offsetA   nonnegative integer;
+*/
+func Hpr2(X, Y, A matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
+	defer guard("Hpr2", &err, opts...)()
+	if err = writable("Hpr2", A); err != nil {
+		return
+	}
+	X, Y = matops.Readable(X), matops.Readable(Y)
+	if !matrix.EqualTypes(X, Y, A) {
+		return onError(linalg.ErrType, "Parameters not of same type")
+	}
+	switch X.(type) {
+	case *matrix.FloatMatrix:
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
+		}
+		return Spr2Float(X.(*matrix.FloatMatrix), Y.(*matrix.FloatMatrix), A.(*matrix.FloatMatrix),
+			a, opts...)
+	case *matrix.ComplexMatrix:
+		a, e := complexValue("alpha", alpha)
+		if e != nil {
+			return e
+		}
+		return Hpr2Complex(X.(*matrix.ComplexMatrix), Y.(*matrix.ComplexMatrix), A.(*matrix.ComplexMatrix),
+			a, opts...)
+	default:
+		return onError(linalg.ErrType, "Unknown type, not implemented")
+	}
+}
+
+// Replace band matrix A by its storage and append the options it implies,
+// m, n, kl and ku for general and n, k and uplo for symmetric or
+// triangular (tri true) routines. Other matrices are returned as is.
+func bandArgs(name string, A matrix.Matrix, opts []linalg.Option, tri bool) (matrix.Matrix, []linalg.Option, error) {
+	B, ok := A.(*matops.BandMatrix)
+	if !ok {
+		return A, opts, nil
+	}
+	// index options and parameters take the last value given
+	bopts := append([]linalg.Option{}, opts...)
+	if !tri {
+		bopts = append(bopts, linalg.IntOpt("m", B.M), linalg.IntOpt("n", B.N),
+			linalg.IntOpt("kl", B.Kl), linalg.IntOpt("ku", B.Ku))
+		return B.Matrix, bopts, nil
+	}
+	if B.M != B.N || (B.Kl > 0 && B.Ku > 0) {
+		return nil, nil, onError(linalg.ErrShape, name+": band matrix not square and triangular")
+	}
+	bopts = append(bopts, linalg.IntOpt("n", B.N), linalg.IntOpt("k", B.Kl+B.Ku))
+	if B.Kl > 0 {
+		bopts = append(bopts, linalg.OptLower)
+	} else if B.Ku > 0 {
+		bopts = append(bopts, linalg.OptUpper)
+	}
+	return B.Matrix, bopts, nil
+}
+
 // Local Variables:
 // tab-width: 4
 // End:
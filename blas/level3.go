@@ -9,9 +9,8 @@ package blas
 import (
 	//"errors"
 	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
 	"github.com/nvcook42/matrix"
-	"math"
-	"math/cmplx"
 )
 
 /*
@@ -59,62 +58,43 @@ import (
   offsetA   nonnegative integer
   offsetB   nonnegative integer
   offsetC   nonnegative integer;
+  kernel    "auto" (default), "go", "native" or "gpu".  Float products only;
+            see Profile for the automatic choice.  The other level 3
+            routines have no kernel option and always use the native BLAS.
+  gemm3m    bool.  Complex products only; compute with the 3M scheme of
+            zgemm3m if true, with zgemm if false.  By default zgemm3m is
+            used when the backend provides it (linalg.ExtGemm3m) and m, n
+            and k are all at least 128.  The 3M scheme takes about 25% less
+            time but its error bound is normwise rather than per element,
+            so small parts of results may lose relative accuracy.
 */
 func Gemm(A, B, C matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	params, e := linalg.GetParameters(opts...)
-	if e != nil {
-		err = e
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level3_func(ind, fgemm, A, B, C, params)
-	if err != nil {
-		return
-	}
-	if ind.M == 0 || ind.N == 0 {
+	defer guard("Gemm", &err, opts...)()
+	if err = writable("Gemm", C); err != nil {
 		return
 	}
+	A, B = matops.Readable(A), matops.Readable(B)
 	if !matrix.EqualTypes(A, B, C) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		Ba := B.(*matrix.FloatMatrix).FloatArray()
-		Ca := C.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		bval := beta.Float()
-		if math.IsNaN(aval) || math.IsNaN(bval) {
-			return onError("alpha or beta not a number")
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
-		transB := linalg.ParamString(params.TransB)
-		transA := linalg.ParamString(params.TransA)
-		dgemm(transA, transB, ind.M, ind.N, ind.K, aval,
-			Aa[ind.OffsetA:], ind.LDa, Ba[ind.OffsetB:], ind.LDb, bval,
-			Ca[ind.OffsetC:], ind.LDc)
-
+		return GemmFloat(A.(*matrix.FloatMatrix), B.(*matrix.FloatMatrix), C.(*matrix.FloatMatrix),
+			a, b, opts...)
 	case *matrix.ComplexMatrix:
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		Ba := B.(*matrix.ComplexMatrix).ComplexArray()
-		Ca := C.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		if cmplx.IsNaN(aval) {
-			return onError("alpha not a number")
-		}
-		bval := beta.Complex()
-		if cmplx.IsNaN(bval) {
-			return onError("beta not a number")
+		a, b, e := complexScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
-		transB := linalg.ParamString(params.TransB)
-		transA := linalg.ParamString(params.TransA)
-		zgemm(transA, transB, ind.M, ind.N, ind.K, aval,
-			Aa[ind.OffsetA:], ind.LDa, Ba[ind.OffsetB:], ind.LDb, bval,
-			Ca[ind.OffsetC:], ind.LDc)
+		return GemmComplex(A.(*matrix.ComplexMatrix), B.(*matrix.ComplexMatrix), C.(*matrix.ComplexMatrix),
+			a, b, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -155,63 +135,61 @@ func Gemm(A, B, C matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Optio
 
 */
 func Symm(A, B, C matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	params, e := linalg.GetParameters(opts...)
-	if e != nil {
-		err = e
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level3_func(ind, fsymm, A, B, C, params)
-	if err != nil {
-		return
-	}
-	if ind.M == 0 || ind.N == 0 {
+	defer guard("Symm", &err, opts...)()
+	if err = writable("Symm", C); err != nil {
 		return
 	}
+	A, B = matops.Readable(A), matops.Readable(B)
 	if !matrix.EqualTypes(A, B, C) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		Ba := B.(*matrix.FloatMatrix).FloatArray()
-		Ca := C.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		bval := beta.Float()
-		if math.IsNaN(aval) || math.IsNaN(bval) {
-			return onError("alpha or beta not a number")
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		side := linalg.ParamString(params.Side)
-		dsymm(side, uplo, ind.M, ind.N, aval, Aa[ind.OffsetA:], ind.LDa,
-			Ba[ind.OffsetB:], ind.LDb, bval, Ca[ind.OffsetC:], ind.LDc)
+		return SymmFloat(A.(*matrix.FloatMatrix), B.(*matrix.FloatMatrix), C.(*matrix.FloatMatrix),
+			a, b, opts...)
 	case *matrix.ComplexMatrix:
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		Ba := B.(*matrix.ComplexMatrix).ComplexArray()
-		Ca := C.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		if cmplx.IsNaN(aval) {
-			return onError("alpha not a number")
-		}
-		bval := beta.Complex()
-		if cmplx.IsNaN(bval) {
-			return onError("beta not a number")
+		a, b, e := complexScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		side := linalg.ParamString(params.Side)
-		zhemm(side, uplo, ind.M, ind.N, aval, Aa[ind.OffsetA:], ind.LDa,
-			Ba[ind.OffsetB:], ind.LDb, bval, Ca[ind.OffsetC:], ind.LDc)
+		return SymmComplex(A.(*matrix.ComplexMatrix), B.(*matrix.ComplexMatrix), C.(*matrix.ComplexMatrix),
+			a, b, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-
-	return
 }
 
 func Hemm(A, B, C matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
-	err = Symm(A, B, C, alpha, beta, opts...)
-	return
+	defer guard("Hemm", &err, opts...)()
+	if err = writable("Hemm", C); err != nil {
+		return
+	}
+	A, B = matops.Readable(A), matops.Readable(B)
+	if !matrix.EqualTypes(A, B, C) {
+		return onError(linalg.ErrType, "Parameters not of same type")
+	}
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
+		}
+		return SymmFloat(A.(*matrix.FloatMatrix), B.(*matrix.FloatMatrix), C.(*matrix.FloatMatrix),
+			a, b, opts...)
+	case *matrix.ComplexMatrix:
+		a, b, e := complexScalars(alpha, beta)
+		if e != nil {
+			return e
+		}
+		return HemmComplex(A.(*matrix.ComplexMatrix), B.(*matrix.ComplexMatrix), C.(*matrix.ComplexMatrix),
+			a, b, opts...)
+	default:
+		return onError(linalg.ErrType, "Unknown type, not implemented")
+	}
 }
 
 /*
@@ -251,53 +229,30 @@ func Hemm(A, B, C matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Optio
   offsetC   nonnegative integer;
 */
 func Syrk(A, C matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	params, e := linalg.GetParameters(opts...)
-	if e != nil {
-		err = e
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level3_func(ind, fsyrk, A, nil, C, params)
-	if e != nil || err != nil {
+	defer guard("Syrk", &err, opts...)()
+	if err = writable("Syrk", C); err != nil {
 		return
 	}
+	A = matops.Readable(A)
 	if !matrix.EqualTypes(A, C) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		Ca := C.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		bval := beta.Float()
-		if math.IsNaN(aval) || math.IsNaN(bval) {
-			return onError("alpha or beta not a number")
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		trans := linalg.ParamString(params.Trans)
-		dsyrk(uplo, trans, ind.N, ind.K, aval, Aa[ind.OffsetA:], ind.LDa, bval,
-			Ca[ind.OffsetC:], ind.LDc)
+		return SyrkFloat(A.(*matrix.FloatMatrix), C.(*matrix.FloatMatrix), a, b, opts...)
 	case *matrix.ComplexMatrix:
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		Ca := C.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		if cmplx.IsNaN(aval) {
-			return onError("alpha not a number")
+		a, b, e := complexScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
-		bval := beta.Complex()
-		if cmplx.IsNaN(bval) {
-			return onError("beta not a number")
-		}
-		uplo := linalg.ParamString(params.Uplo)
-		trans := linalg.ParamString(params.Trans)
-		zsyrk(uplo, trans, ind.N, ind.K, aval, Aa[ind.OffsetA:], ind.LDa, bval,
-			Ca[ind.OffsetC:], ind.LDc)
+		return SyrkComplex(A.(*matrix.ComplexMatrix), C.(*matrix.ComplexMatrix), a, b, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-
-	return
 }
 
 /*
@@ -337,53 +292,34 @@ func Syrk(A, C matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option)
   offsetC   nonnegative integer;
 */
 func Herk(A, C matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	params, e := linalg.GetParameters(opts...)
-	if e != nil {
-		err = e
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level3_func(ind, fsyrk, A, nil, C, params)
-	if e != nil || err != nil {
+	defer guard("Herk", &err, opts...)()
+	if err = writable("Herk", C); err != nil {
 		return
 	}
+	A = matops.Readable(A)
 	if !matrix.EqualTypes(A, C) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		Ca := C.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		bval := beta.Float()
-		if math.IsNaN(aval) || math.IsNaN(bval) {
-			return onError("alpha or beta not a number")
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		trans := linalg.ParamString(params.Trans)
-		dsyrk(uplo, trans, ind.N, ind.K, aval, Aa[ind.OffsetA:], ind.LDa, bval,
-			Ca[ind.OffsetC:], ind.LDc)
+		return SyrkFloat(A.(*matrix.FloatMatrix), C.(*matrix.FloatMatrix), a, b, opts...)
 	case *matrix.ComplexMatrix:
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		Ca := C.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		if cmplx.IsNaN(aval) {
-			return onError("alpha not a real or complex number")
+		a, e := complexValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		bval := beta.Float()
-		if math.IsNaN(bval) {
-			return onError("beta not a real number")
+		b, e := floatValue("beta", beta)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		trans := linalg.ParamString(params.Trans)
-		zherk(uplo, trans, ind.N, ind.K, aval, Aa[ind.OffsetA:], ind.LDa, bval,
-			Ca[ind.OffsetC:], ind.LDc)
+		return HerkComplex(A.(*matrix.ComplexMatrix), C.(*matrix.ComplexMatrix), a, b, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-
-	return
 }
 
 /*
@@ -435,55 +371,32 @@ func Herk(A, C matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option)
 
 */
 func Syr2k(A, B, C matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	params, e := linalg.GetParameters(opts...)
-	if e != nil {
-		err = e
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level3_func(ind, fsyr2k, A, B, C, params)
-	if err != nil {
+	defer guard("Syr2k", &err, opts...)()
+	if err = writable("Syr2k", C); err != nil {
 		return
 	}
+	A, B = matops.Readable(A), matops.Readable(B)
 	if !matrix.EqualTypes(A, B, C) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		Ba := B.(*matrix.FloatMatrix).FloatArray()
-		Ca := C.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		bval := beta.Float()
-		if math.IsNaN(aval) || math.IsNaN(bval) {
-			return onError("alpha or beta not a number")
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		trans := linalg.ParamString(params.Trans)
-		dsyr2k(uplo, trans, ind.N, ind.K, aval, Aa[ind.OffsetA:], ind.LDa,
-			Ba[ind.OffsetB:], ind.LDb, bval, Ca[ind.OffsetC:], ind.LDc)
-
+		return Syr2kFloat(A.(*matrix.FloatMatrix), B.(*matrix.FloatMatrix), C.(*matrix.FloatMatrix),
+			a, b, opts...)
 	case *matrix.ComplexMatrix:
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		Ba := B.(*matrix.ComplexMatrix).ComplexArray()
-		Ca := C.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		if cmplx.IsNaN(aval) {
-			return onError("alpha not a real or complex number")
-		}
-		bval := beta.Complex()
-		if cmplx.IsNaN(bval) {
-			return onError("beta not a real or complex number")
+		a, b, e := complexScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		trans := linalg.ParamString(params.Trans)
-		zsyr2k(uplo, trans, ind.N, ind.K, aval, Aa[ind.OffsetA:], ind.LDa,
-			Ba[ind.OffsetB:], ind.LDb, bval, Ca[ind.OffsetC:], ind.LDc)
+		return Syr2kComplex(A.(*matrix.ComplexMatrix), B.(*matrix.ComplexMatrix), C.(*matrix.ComplexMatrix),
+			a, b, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -533,55 +446,36 @@ func Syr2k(A, B, C matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Opti
   offsetC   nonnegative integer
 */
 func Her2k(A, B, C matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	params, e := linalg.GetParameters(opts...)
-	if e != nil {
-		err = e
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level3_func(ind, fsyr2k, A, B, C, params)
-	if err != nil {
+	defer guard("Her2k", &err, opts...)()
+	if err = writable("Her2k", C); err != nil {
 		return
 	}
+	A, B = matops.Readable(A), matops.Readable(B)
 	if !matrix.EqualTypes(A, B, C) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		Ba := B.(*matrix.FloatMatrix).FloatArray()
-		Ca := C.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		bval := beta.Float()
-		if math.IsNaN(aval) || math.IsNaN(bval) {
-			return onError("alpha or beta not a number")
+		a, b, e := floatScalars(alpha, beta)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		trans := linalg.ParamString(params.Trans)
-		dsyr2k(uplo, trans, ind.N, ind.K, aval, Aa[ind.OffsetA:], ind.LDa,
-			Ba[ind.OffsetB:], ind.LDb, bval, Ca[ind.OffsetC:], ind.LDc)
-
+		return Syr2kFloat(A.(*matrix.FloatMatrix), B.(*matrix.FloatMatrix), C.(*matrix.FloatMatrix),
+			a, b, opts...)
 	case *matrix.ComplexMatrix:
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		Ba := B.(*matrix.ComplexMatrix).ComplexArray()
-		Ca := C.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		if cmplx.IsNaN(aval) {
-			return onError("alpha not a number")
+		a, e := complexValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		bval := beta.Float()
-		if math.IsNaN(bval) {
-			return onError("beta not a real number")
+		b, e := floatValue("beta", beta)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		trans := linalg.ParamString(params.Trans)
-		zher2k(uplo, trans, ind.N, ind.K, aval, Aa[ind.OffsetA:], ind.LDa,
-			Ba[ind.OffsetB:], ind.LDb, bval, Ca[ind.OffsetC:], ind.LDc)
+		return Her2kComplex(A.(*matrix.ComplexMatrix), B.(*matrix.ComplexMatrix), C.(*matrix.ComplexMatrix),
+			a, b, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -626,51 +520,30 @@ func Her2k(A, B, C matrix.Matrix, alpha, beta matrix.Scalar, opts ...linalg.Opti
   offsetB   nonnegative integer
 */
 func Trmm(A, B matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	params, e := linalg.GetParameters(opts...)
-	if e != nil {
-		err = e
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level3_func(ind, ftrmm, A, B, nil, params)
-	if err != nil {
+	defer guard("Trmm", &err, opts...)()
+	if err = writable("Trmm", B); err != nil {
 		return
 	}
+	A = matops.Readable(A)
 	if !matrix.EqualTypes(A, B) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		Ba := B.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		if math.IsNaN(aval) {
-			return onError("alpha  not a number")
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		transA := linalg.ParamString(params.TransA)
-		side := linalg.ParamString(params.Side)
-		diag := linalg.ParamString(params.Diag)
-		dtrmm(side, uplo, transA, diag, ind.M, ind.N, aval,
-			Aa[ind.OffsetA:], ind.LDa, Ba[ind.OffsetB:], ind.LDb)
+		return TrmmFloat(A.(*matrix.FloatMatrix), B.(*matrix.FloatMatrix), a, opts...)
 	case *matrix.ComplexMatrix:
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		Ba := B.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		if cmplx.IsNaN(aval) {
-			return onError("alpha  not a number")
+		a, e := complexValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		transA := linalg.ParamString(params.TransA)
-		side := linalg.ParamString(params.Side)
-		diag := linalg.ParamString(params.Diag)
-		ztrmm(side, uplo, transA, diag, ind.M, ind.N, aval,
-			Aa[ind.OffsetA:], ind.LDa, Ba[ind.OffsetB:], ind.LDb)
+		return TrmmComplex(A.(*matrix.ComplexMatrix), B.(*matrix.ComplexMatrix), a, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 /*
@@ -715,51 +588,30 @@ func Trmm(A, B matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err e
   offsetB   nonnegative integer
 */
 func Trsm(A, B matrix.Matrix, alpha matrix.Scalar, opts ...linalg.Option) (err error) {
-
-	params, e := linalg.GetParameters(opts...)
-	if e != nil {
-		err = e
-		return
-	}
-	ind := linalg.GetIndexOpts(opts...)
-	err = check_level3_func(ind, ftrsm, A, B, nil, params)
-	if err != nil {
+	defer guard("Trsm", &err, opts...)()
+	if err = writable("Trsm", B); err != nil {
 		return
 	}
+	A = matops.Readable(A)
 	if !matrix.EqualTypes(A, B) {
-		return onError("Parameters not of same type")
+		return onError(linalg.ErrType, "Parameters not of same type")
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Aa := A.(*matrix.FloatMatrix).FloatArray()
-		Ba := B.(*matrix.FloatMatrix).FloatArray()
-		aval := alpha.Float()
-		if math.IsNaN(aval) {
-			return onError("alpha or beta not a number")
+		a, e := floatValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		transA := linalg.ParamString(params.TransA)
-		side := linalg.ParamString(params.Side)
-		diag := linalg.ParamString(params.Diag)
-		dtrsm(side, uplo, transA, diag, ind.M, ind.N, aval,
-			Aa[ind.OffsetA:], ind.LDa, Ba[ind.OffsetB:], ind.LDb)
+		return TrsmFloat(A.(*matrix.FloatMatrix), B.(*matrix.FloatMatrix), a, opts...)
 	case *matrix.ComplexMatrix:
-		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
-		Ba := B.(*matrix.ComplexMatrix).ComplexArray()
-		aval := alpha.Complex()
-		if cmplx.IsNaN(aval) {
-			return onError("alpha  not a number")
+		a, e := complexValue("alpha", alpha)
+		if e != nil {
+			return e
 		}
-		uplo := linalg.ParamString(params.Uplo)
-		transA := linalg.ParamString(params.TransA)
-		side := linalg.ParamString(params.Side)
-		diag := linalg.ParamString(params.Diag)
-		ztrsm(side, uplo, transA, diag, ind.M, ind.N, aval,
-			Aa[ind.OffsetA:], ind.LDa, Ba[ind.OffsetB:], ind.LDb)
+		return TrsmComplex(A.(*matrix.ComplexMatrix), B.(*matrix.ComplexMatrix), a, opts...)
 	default:
-		return onError("Unknown type, not implemented")
+		return onError(linalg.ErrType, "Unknown type, not implemented")
 	}
-	return
 }
 
 // Local Variables:
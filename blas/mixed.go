@@ -0,0 +1,119 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/blas package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package blas
+
+import (
+	"github.com/nvcook42/linalg"
+)
+
+/*
+ General matrix-matrix product of single precision matrices with double
+ precision accumulation. (L3)
+
+ PURPOSE
+ Computes
+  C := alpha*op(A)*op(B) + beta*C
+ as Gemm does for matrices stored in column-major order in float32
+ slices. Every product and inner sum is formed in float64 and the result
+ is rounded to float32 once, so the error of an element is one rounding
+ of the float32 result plus the float64 error of the inner product,
+ independent of k for practical sizes. Storage is half that of float64
+ matrices; arithmetic is done in pure Go.
+
+ The slices carry no shape, so m, n and k must be given as options.
+
+ ARGUMENTS
+  A         float32 slice, storage of m*k matrix, k*m if transposed
+  B         float32 slice, storage of k*n matrix, n*k if transposed
+  C         float32 slice, storage of m*n matrix
+  alpha     float64
+  beta      float64
+
+ OPTIONS
+  transA    PNoTrans or PTrans
+  transB    PNoTrans or PTrans
+  m         nonnegative integer
+  n         nonnegative integer
+  k         nonnegative integer
+  ldA       positive integer.  Default rows of stored A, m or k if transposed.
+  ldB       positive integer.  Default rows of stored B, k or n if transposed.
+  ldC       positive integer.  Default m.
+  offsetA   nonnegative integer
+  offsetB   nonnegative integer
+  offsetC   nonnegative integer;
+*/
+func GemmFloat32(A, B, C []float32, alpha, beta float64, opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("blas.GemmFloat32")
+	defer op.Finish(&err)
+	defer guard("GemmFloat32", &err, opts...)()
+
+	params, e := linalg.GetParameters(opts...)
+	if e != nil {
+		err = e
+		return
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	_, _, _, err = checkStridedBatch(ind, params, len(A), len(B), len(C), 1)
+	if err != nil {
+		return
+	}
+	op.SetDims(ind.M, ind.N, ind.K)
+	if ind.M == 0 || ind.N == 0 {
+		return
+	}
+	if _, _, err = floatScalars(linalg.FloatScalar(alpha), linalg.FloatScalar(beta)); err != nil {
+		return
+	}
+	op.SetBackend(KernelGo)
+	gemmFloat32(params.TransA != linalg.PNoTrans, params.TransB != linalg.PNoTrans,
+		ind.M, ind.N, ind.K, alpha, A[ind.OffsetA:], ind.LDa, B[ind.OffsetB:], ind.LDb,
+		beta, C[ind.OffsetC:], ind.LDc)
+	return
+}
+
+// Pure Go sgemm accumulating in float64, with the loop order of goDgemm.
+func gemmFloat32(transA, transB bool, M, N, K int, alpha float64, A []float32, lda int,
+	B []float32, ldb int, beta float64, C []float32, ldc int) {
+	acc := make([]float64, M)
+	for j := 0; j < N; j++ {
+		for i := range acc {
+			acc[i] = 0.0
+		}
+		for l := 0; l < K; l++ {
+			// b = op(B)[l, j]
+			b := float64(B[j*ldb+l])
+			if transB {
+				b = float64(B[l*ldb+j])
+			}
+			if b == 0.0 {
+				continue
+			}
+			if !transA {
+				a := A[l*lda : l*lda+M]
+				for i := range acc {
+					acc[i] += float64(a[i]) * b
+				}
+			} else {
+				for i := range acc {
+					acc[i] += float64(A[i*lda+l]) * b
+				}
+			}
+		}
+		c := C[j*ldc : j*ldc+M]
+		for i := range c {
+			v := alpha * acc[i]
+			if beta != 0.0 {
+				v += beta * float64(c[i])
+			}
+			c[i] = float32(v)
+		}
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,96 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/blas package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package blas
+
+import (
+	"math"
+)
+
+// Euclidean norm without intermediate overflow or underflow, with the
+// algorithm of Blue (ACM TOMS 4, 1978) as in the reference dnrm2 of LAPACK
+// 3.10. Not every optimized BLAS scales its sum of squares, so Nrm2,
+// Nrm2Float and Nrm2Complex use this instead of the linked dnrm2.
+//
+// Elements are summed in three accumulators. Squares of elements above
+// nrm2Tbig are accumulated scaled by nrm2Sbig and squares of elements
+// below nrm2Tsml scaled by nrm2Ssml, so that none of the sums can overflow
+// or lose the small elements to underflow; the rest are summed unscaled.
+// The result is accurate to a few ulps for all finite inputs whose norm is
+// representable. As with the reference dnrm2 it is NaN if any element is
+// NaN and otherwise +Inf if any element is infinite.
+const (
+	nrm2Tsml = 0x1p-511 // square is normal
+	nrm2Tbig = 0x1p486  // square can be summed 2^52 times
+	nrm2Ssml = 0x1p537  // scale of small elements
+	nrm2Sbig = 0x1p-538 // scale of big elements
+)
+
+// Accumulators of nrm2.
+type nrm2Sum struct {
+	asml, amed, abig float64
+}
+
+// Add absolute value ax to the sum.
+func (s *nrm2Sum) add(ax float64) {
+	switch {
+	case ax > nrm2Tbig:
+		s.abig += (ax * nrm2Sbig) * (ax * nrm2Sbig)
+	case ax < nrm2Tsml:
+		if s.abig == 0.0 {
+			s.asml += (ax * nrm2Ssml) * (ax * nrm2Ssml)
+		}
+	default:
+		// NaN lands here and propagates through amed
+		s.amed += ax * ax
+	}
+}
+
+// Return the norm from the accumulated sums.
+func (s *nrm2Sum) norm() float64 {
+	switch {
+	case s.abig > 0.0:
+		if s.amed > 0.0 || math.IsNaN(s.amed) {
+			s.abig += (s.amed * nrm2Sbig) * nrm2Sbig
+		}
+		return math.Sqrt(s.abig) / nrm2Sbig
+	case s.asml > 0.0:
+		if s.amed > 0.0 || math.IsNaN(s.amed) {
+			amed := math.Sqrt(s.amed)
+			asml := math.Sqrt(s.asml) / nrm2Ssml
+			ymin, ymax := math.Min(amed, asml), math.Max(amed, asml)
+			if math.IsNaN(amed) {
+				return amed
+			}
+			return ymax * math.Sqrt(1.0+(ymin/ymax)*(ymin/ymax))
+		}
+		return math.Sqrt(s.asml) / nrm2Ssml
+	}
+	return math.Sqrt(s.amed)
+}
+
+// Return Euclidean norm of n elements of X with stride inc.
+func nrm2(n int, X []float64, inc int) float64 {
+	var s nrm2Sum
+	for i := 0; i < n; i++ {
+		s.add(math.Abs(X[i*inc]))
+	}
+	return s.norm()
+}
+
+// Return Euclidean norm of n elements of complex X with stride inc.
+func znrm2(n int, X []complex128, inc int) float64 {
+	var s nrm2Sum
+	for i := 0; i < n; i++ {
+		s.add(math.Abs(real(X[i*inc])))
+		s.add(math.Abs(imag(X[i*inc])))
+	}
+	return s.norm()
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
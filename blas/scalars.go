@@ -0,0 +1,61 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/blas package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package blas
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+)
+
+// The functions taking alpha and beta as matrix.Scalar are shims that
+// convert the scalars with the functions below and call the typed
+// function, XxxFloat with float64 or XxxComplex with complex128 scalars.
+// The checks of the values are those of linalg.Scalar.
+
+// Value of scalar argument name of a real routine. Complex scalars are
+// not accepted.
+func floatValue(name string, s matrix.Scalar) (float64, error) {
+	v, err := linalg.ScalarOf(s).Real(name)
+	return v, scalarError(err)
+}
+
+// Value of scalar argument name of a complex routine. Real scalars are
+// converted to complex.
+func complexValue(name string, s matrix.Scalar) (complex128, error) {
+	v, err := linalg.ScalarOf(s).Cmplx(name)
+	return v, scalarError(err)
+}
+
+// Return err of a linalg.Scalar check as onError does.
+func scalarError(err error) error {
+	if err != nil && panicOnError {
+		panic(err)
+	}
+	return err
+}
+
+// Values of alpha and beta of a real routine.
+func floatScalars(alpha, beta matrix.Scalar) (a, b float64, err error) {
+	if a, err = floatValue("alpha", alpha); err != nil {
+		return
+	}
+	b, err = floatValue("beta", beta)
+	return
+}
+
+// Values of alpha and beta of a complex routine.
+func complexScalars(alpha, beta matrix.Scalar) (a, b complex128, err error) {
+	if a, err = complexValue("alpha", alpha); err != nil {
+		return
+	}
+	b, err = complexValue("beta", beta)
+	return
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,183 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/eval"
+	"github.com/nvcook42/linalg/lapack"
+	"github.com/nvcook42/matrix"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// Evaluate statements given with -e, or read from standard input one per
+// line, with matrices bound from NAME=FILE arguments. Results of
+// expression statements are printed; the value of the last statement is
+// written to -o if given.
+func runEval(fs *flag.FlagSet, args []string) error {
+	var exprs []string
+	fs.Func("e", "evaluate statement (may be repeated)", func(s string) error {
+		exprs = append(exprs, s)
+		return nil
+	})
+	fs.Parse(args)
+	env := eval.Env{
+		"svd":  eval.Func(evalSvd),
+		"eig":  eval.Func(evalEig),
+		"cond": eval.Func(evalCond),
+		"det":  eval.Func(evalDet),
+	}
+	for _, arg := range fs.Args() {
+		k := strings.IndexByte(arg, '=')
+		if k <= 0 {
+			return fmt.Errorf("argument %q not of form NAME=FILE", arg)
+		}
+		A, err := readFile(arg[k+1:], inFormat)
+		if err != nil {
+			return err
+		}
+		env[arg[:k]] = A
+	}
+	var last eval.Value
+	run := func(src string) error {
+		e, err := eval.Parse(src)
+		if err != nil {
+			return err
+		}
+		v, err := e.Eval(env)
+		if err != nil {
+			return err
+		}
+		last = v
+		if e.Name() == "" && outFile == "-" {
+			return printValue(os.Stdout, v)
+		}
+		return nil
+	}
+	if len(exprs) == 0 {
+		s := bufio.NewScanner(os.Stdin)
+		for s.Scan() {
+			line := strings.TrimSpace(s.Text())
+			if line == "" || line[0] == '#' {
+				continue
+			}
+			if err := run(line); err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+			}
+		}
+		return s.Err()
+	}
+	for _, src := range exprs {
+		if err := run(src); err != nil {
+			return err
+		}
+	}
+	if outFile != "-" && last != nil {
+		A, _ := eval.AsMatrix(last)
+		return writeFile(outFile, outFormat, A)
+	}
+	return nil
+}
+
+func printValue(w io.Writer, v eval.Value) error {
+	if s, ok := v.(float64); ok {
+		_, err := fmt.Fprintln(w, formatFloat(s))
+		return err
+	}
+	A, err := eval.AsMatrix(v)
+	if err != nil {
+		return err
+	}
+	return writeMatrix(w, outFormat, A)
+}
+
+func evalMatrix(name string, args []eval.Value) (*matrix.FloatMatrix, error) {
+	if len(args) != 1 {
+		return nil, linalg.NewError(linalg.ErrParameter, fmt.Sprintf("%s: wrong number of arguments", name))
+	}
+	return eval.AsMatrix(args[0])
+}
+
+// svd(A) returns singular values of A.
+func evalSvd(args ...eval.Value) (eval.Value, error) {
+	A, err := evalMatrix("svd", args)
+	if err != nil {
+		return nil, err
+	}
+	S, _, _, err := svd(A, false)
+	return S, err
+}
+
+// eig(A) returns eigenvalues of symmetric A in ascending order.
+func evalEig(args ...eval.Value) (eval.Value, error) {
+	A, err := evalMatrix("eig", args)
+	if err != nil {
+		return nil, err
+	}
+	if A.Rows() != A.Cols() {
+		return nil, linalg.NewError(linalg.ErrShape, "eig: matrix not square")
+	}
+	W := matrix.FloatZeros(A.Rows(), 1)
+	err = lapack.Syevd(A.Copy(), W, linalg.OptJobZNo, linalg.OptLower)
+	return W, err
+}
+
+// cond(A) returns 2-norm condition number of A.
+func evalCond(args ...eval.Value) (eval.Value, error) {
+	A, err := evalMatrix("cond", args)
+	if err != nil {
+		return nil, err
+	}
+	S, _, _, err := svd(A, false)
+	if err != nil {
+		return nil, err
+	}
+	k := S.NumElements()
+	if k == 0 || S.GetIndex(k-1) == 0 {
+		return math.Inf(1), nil
+	}
+	return S.GetIndex(0) / S.GetIndex(k-1), nil
+}
+
+// det(A) returns determinant of square A computed from LU factorization.
+func evalDet(args ...eval.Value) (eval.Value, error) {
+	A, err := evalMatrix("det", args)
+	if err != nil {
+		return nil, err
+	}
+	n := A.Rows()
+	if A.Cols() != n {
+		return nil, linalg.NewError(linalg.ErrShape, "det: matrix not square")
+	}
+	LU := A.Copy()
+	ipiv := make([]int32, n)
+	if err = lapack.Getrf(LU, ipiv); err != nil {
+		if errors.Is(err, linalg.ErrSingular) {
+			return 0.0, nil
+		}
+		return nil, err
+	}
+	d := 1.0
+	for k := 0; k < n; k++ {
+		d *= LU.GetAt(k, k)
+		if int(ipiv[k]) != k+1 {
+			d = -d
+		}
+	}
+	return d, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,256 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/nvcook42/linalg/matio"
+	"github.com/nvcook42/matrix"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Matrix file formats.
+const (
+	formatMM  = "mtx"
+	formatCSV = "csv"
+	formatNpy = "npy"
+)
+
+// Return file format implied by file name extension; MatrixMarket by default.
+func formatOf(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv", ".txt":
+		return formatCSV
+	case ".npy":
+		return formatNpy
+	}
+	return formatMM
+}
+
+// Read matrix from named file, "-" for standard input.
+func readFile(path, format string) (*matrix.FloatMatrix, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+		if format == "" {
+			format = formatOf(path)
+		}
+	}
+	A, err := readMatrix(r, format)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return A, nil
+}
+
+// Write matrix to named file, "-" for standard output.
+func writeFile(path, format string, A *matrix.FloatMatrix) error {
+	if path == "-" || path == "" {
+		return writeMatrix(os.Stdout, format, A)
+	}
+	if format == "" {
+		format = formatOf(path)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = writeMatrix(f, format, A); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readMatrix(r io.Reader, format string) (*matrix.FloatMatrix, error) {
+	switch format {
+	case formatCSV:
+		return readCSV(r)
+	case formatNpy:
+		return readNpy(r)
+	case formatMM, "":
+		return readMM(r)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+func writeMatrix(w io.Writer, format string, A *matrix.FloatMatrix) error {
+	switch format {
+	case formatCSV:
+		return writeCSV(w, A)
+	case formatNpy:
+		return writeNpy(w, A)
+	case formatMM, "":
+		return writeMM(w, A)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// Read real MatrixMarket file; see matio.ReadMM.
+func readMM(r io.Reader) (*matrix.FloatMatrix, error) {
+	A, err := matio.ReadMM(r)
+	if err != nil {
+		return nil, err
+	}
+	F, ok := A.(*matrix.FloatMatrix)
+	if !ok {
+		return nil, errors.New("complex MatrixMarket files not supported")
+	}
+	return F, nil
+}
+
+// Write matrix as a general real MatrixMarket array.
+func writeMM(w io.Writer, A *matrix.FloatMatrix) error {
+	return matio.WriteMM(w, A)
+}
+
+// Read comma separated rows of numbers.
+func readCSV(r io.Reader) (*matrix.FloatMatrix, error) {
+	A, _, err := matio.ReadCSV(r)
+	return A, err
+}
+
+func writeCSV(w io.Writer, A *matrix.FloatMatrix) error {
+	return matio.WriteCSV(w, A, nil)
+}
+
+// Read NumPy .npy file holding a one or two dimensional float64 array.
+func readNpy(r io.Reader) (*matrix.FloatMatrix, error) {
+	var magic [8]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, err
+	}
+	if string(magic[:6]) != "\x93NUMPY" {
+		return nil, errors.New("not a npy file")
+	}
+	var hlen int
+	switch magic[6] {
+	case 1:
+		var n uint16
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return nil, err
+		}
+		hlen = int(n)
+	case 2, 3:
+		var n uint32
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return nil, err
+		}
+		hlen = int(n)
+	default:
+		return nil, fmt.Errorf("unsupported npy version %d", magic[6])
+	}
+	hdr := make([]byte, hlen)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, err
+	}
+	descr, fortran, shape, err := parseNpyHeader(string(hdr))
+	if err != nil {
+		return nil, err
+	}
+	var order binary.ByteOrder
+	switch descr {
+	case "<f8", "=f8":
+		order = binary.LittleEndian
+	case ">f8":
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("unsupported npy dtype %q", descr)
+	}
+	rows, cols := 1, 1
+	switch len(shape) {
+	case 0:
+	case 1:
+		rows = shape[0]
+	case 2:
+		rows, cols = shape[0], shape[1]
+	default:
+		return nil, fmt.Errorf("npy array of %d dimensions", len(shape))
+	}
+	data := make([]float64, rows*cols)
+	if err := binary.Read(r, order, data); err != nil {
+		return nil, err
+	}
+	if fortran || cols == 1 {
+		return matrix.FloatNew(rows, cols, data), nil
+	}
+	return matrix.FloatNew(rows, cols, data, matrix.RowOrder), nil
+}
+
+// Parse descr, fortran_order and shape from npy header dictionary.
+func parseNpyHeader(h string) (descr string, fortran bool, shape []int, err error) {
+	field := func(key string) string {
+		k := strings.Index(h, "'"+key+"'")
+		if k < 0 {
+			return ""
+		}
+		v := strings.TrimSpace(h[k+len(key)+2:])
+		return strings.TrimSpace(strings.TrimPrefix(v, ":"))
+	}
+	d := field("descr")
+	if len(d) < 2 || d[0] != '\'' {
+		return "", false, nil, errors.New("npy header: missing descr")
+	}
+	descr = d[1 : 1+strings.IndexByte(d[1:], '\'')]
+	fortran = strings.HasPrefix(field("fortran_order"), "True")
+	s := field("shape")
+	if len(s) == 0 || s[0] != '(' || strings.IndexByte(s, ')') < 0 {
+		return "", false, nil, errors.New("npy header: missing shape")
+	}
+	for _, f := range strings.Split(s[1:strings.IndexByte(s, ')')], ",") {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return "", false, nil, fmt.Errorf("npy header: %v", err)
+		}
+		shape = append(shape, n)
+	}
+	return descr, fortran, shape, nil
+}
+
+// Write matrix as Fortran ordered float64 npy version 1.0 file.
+func writeNpy(w io.Writer, A *matrix.FloatMatrix) error {
+	hdr := fmt.Sprintf("{'descr': '<f8', 'fortran_order': True, 'shape': (%d, %d), }",
+		A.Rows(), A.Cols())
+	// pad header with spaces so that data starts at 64 byte boundary
+	pad := 64 - (10+len(hdr)+1)%64
+	if pad == 64 {
+		pad = 0
+	}
+	hdr += strings.Repeat(" ", pad) + "\n"
+	var buf bytes.Buffer
+	buf.WriteString("\x93NUMPY\x01\x00")
+	binary.Write(&buf, binary.LittleEndian, uint16(len(hdr)))
+	buf.WriteString(hdr)
+	data := make([]float64, 0, A.NumElements())
+	for j := 0; j < A.Cols(); j++ {
+		for i := 0; i < A.Rows(); i++ {
+			data = append(data, A.GetAt(i, j))
+		}
+	}
+	binary.Write(&buf, binary.LittleEndian, data)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"github.com/nvcook42/matrix"
+	"testing"
+)
+
+func TestFormats(t *testing.T) {
+	A := matrix.FloatNew(2, 3, []float64{1, 2, 3, 4, 5, 0.125})
+	for _, f := range []string{formatMM, formatCSV, formatNpy} {
+		var buf bytes.Buffer
+		if err := writeMatrix(&buf, f, A); err != nil {
+			t.Logf("%s write: %v\n", f, err)
+			t.Fail()
+			continue
+		}
+		B, err := readMatrix(&buf, f)
+		if err != nil || !A.Equal(B) {
+			t.Logf("%s round trip: %v\n%v\n", f, err, B)
+			t.Fail()
+		}
+	}
+}
+
+func TestNpyHeader(t *testing.T) {
+	// C ordered npy header as written by numpy.save
+	hdr := "{'descr': '<f8', 'fortran_order': False, 'shape': (2, 3), }"
+	_, fortran, shape, err := parseNpyHeader(hdr)
+	if err != nil || fortran || len(shape) != 2 || shape[0] != 2 || shape[1] != 3 {
+		t.Logf("npy header: %v %v %v\n", fortran, shape, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,322 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+// Command linalg performs dense matrix operations on matrix files.
+//
+// Usage:
+//
+//	linalg solve [-o X] A B       solve A*X = B (least squares if A not square)
+//	linalg svd [-o S] [-u U] [-vt Vt] A
+//	                              singular values, optionally singular vectors
+//	linalg eig [-o W] [-v V] A    eigenvalues and vectors of symmetric A
+//	linalg norm [-p 1|2|inf|fro] A
+//	linalg cond A                 2-norm condition number
+//	linalg convert IN OUT         convert between file formats
+//	linalg eval [-e stmt]... [NAME=FILE]...
+//	                              evaluate expressions, see package eval
+//
+// Matrix files are MatrixMarket (.mtx, the default), comma separated values
+// (.csv, .txt) or NumPy arrays (.npy); the format is selected by file name
+// extension or with flag -f. File name "-" reads standard input or writes
+// standard output. Matrix results are written to standard output unless
+// flag -o is given; scalar results are always printed.
+//
+// Without -e flags, eval reads statements from standard input one per line
+// and prints the value of each expression, making it usable as an
+// interactive calculator:
+//
+//	linalg eval A=a.mtx b=b.csv
+//	x = A\b
+//	norm(A*x - b)
+//	cond(A)
+//
+// In addition to the builtins of package eval, functions svd, eig
+// (symmetric), cond and det are available.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/lapack"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+	"math"
+	"os"
+	"strconv"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(fs *flag.FlagSet, args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"solve", "[-o X] A B", runSolve},
+		{"svd", "[-o S] [-u U] [-vt Vt] A", runSvd},
+		{"eig", "[-o W] [-v V] A", runEig},
+		{"norm", "[-p 1|2|inf|fro] A", runNorm},
+		{"cond", "A", runCond},
+		{"convert", "IN OUT", runConvert},
+		{"eval", "[-e stmt]... [NAME=FILE]...", runEval},
+	}
+}
+
+// Common flags.
+var (
+	inFormat  string
+	outFormat string
+	outFile   string
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: linalg command [flags] files...\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %s %s\n", c.name, c.usage)
+	}
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	for _, c := range commands {
+		if c.name != os.Args[1] {
+			continue
+		}
+		fs := flag.NewFlagSet(c.name, flag.ExitOnError)
+		fs.Usage = func() {
+			fmt.Fprintf(os.Stderr, "usage: linalg %s %s\n", c.name, c.usage)
+			fs.PrintDefaults()
+		}
+		fs.StringVar(&inFormat, "f", "", "input format: mtx, csv or npy")
+		fs.StringVar(&outFormat, "F", "", "output format: mtx, csv or npy")
+		fs.StringVar(&outFile, "o", "-", "output file")
+		if err := c.run(fs, os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "linalg %s: %v\n", c.name, err)
+			os.Exit(1)
+		}
+		return
+	}
+	usage()
+}
+
+// Parse flags and read exactly n matrix arguments.
+func parse(fs *flag.FlagSet, args []string, n int) ([]*matrix.FloatMatrix, error) {
+	fs.Parse(args)
+	if fs.NArg() != n {
+		fs.Usage()
+		os.Exit(2)
+	}
+	ms := make([]*matrix.FloatMatrix, n)
+	for k := range ms {
+		A, err := readFile(fs.Arg(k), inFormat)
+		if err != nil {
+			return nil, err
+		}
+		ms[k] = A
+	}
+	return ms, nil
+}
+
+func runSolve(fs *flag.FlagSet, args []string) error {
+	ms, err := parse(fs, args, 2)
+	if err != nil {
+		return err
+	}
+	A, B := ms[0], ms[1]
+	m, n := A.Size()
+	if B.Rows() != m {
+		return linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("A is %dx%d but B has %d rows", m, n, B.Rows()))
+	}
+	if m == n {
+		X := B.Copy()
+		ipiv := make([]int32, n)
+		if err = lapack.Gesv(A.Copy(), X, ipiv); err != nil {
+			return err
+		}
+		return writeFile(outFile, outFormat, X)
+	}
+	// least squares or minimum norm solution; Gels needs max(m,n) rows in B
+	X := matrix.FloatZeros(imax(m, n), B.Cols())
+	X.SetSubMatrix(0, 0, B)
+	if err = lapack.Gels(A.Copy(), X, linalg.IntOpt("m", m), linalg.IntOpt("n", n)); err != nil {
+		return err
+	}
+	return writeFile(outFile, outFormat, X.GetSubMatrix(0, 0, n, B.Cols()))
+}
+
+// Compute singular values of A and optionally the min(m,n) left and right
+// singular vectors.
+func svd(A *matrix.FloatMatrix, vectors bool) (S, U, Vt *matrix.FloatMatrix, err error) {
+	m, n := A.Size()
+	k := imin(m, n)
+	S = matrix.FloatZeros(k, 1)
+	opts := []linalg.Option{linalg.OptJobuNo, linalg.OptJobvtNo}
+	U = matrix.FloatZeros(1, 1)
+	Vt = matrix.FloatZeros(1, 1)
+	if vectors {
+		U = matrix.FloatZeros(m, k)
+		Vt = matrix.FloatZeros(k, n)
+		opts = []linalg.Option{linalg.OptJobuS, linalg.OptJobvtS}
+	}
+	err = lapack.Gesvd(A.Copy(), S, U, Vt, opts...)
+	return
+}
+
+func runSvd(fs *flag.FlagSet, args []string) error {
+	ufile := fs.String("u", "", "write left singular vectors to file")
+	vtfile := fs.String("vt", "", "write right singular vectors (transposed) to file")
+	ms, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	S, U, Vt, err := svd(ms[0], *ufile != "" || *vtfile != "")
+	if err != nil {
+		return err
+	}
+	if *ufile != "" {
+		if err = writeFile(*ufile, outFormat, U); err != nil {
+			return err
+		}
+	}
+	if *vtfile != "" {
+		if err = writeFile(*vtfile, outFormat, Vt); err != nil {
+			return err
+		}
+	}
+	return writeFile(outFile, outFormat, S)
+}
+
+func runEig(fs *flag.FlagSet, args []string) error {
+	vfile := fs.String("v", "", "write eigenvectors to file")
+	ms, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	A := ms[0].Copy()
+	if A.Rows() != A.Cols() {
+		return linalg.NewError(linalg.ErrShape, "matrix not square")
+	}
+	W := matrix.FloatZeros(A.Rows(), 1)
+	jobz := linalg.OptJobZNo
+	if *vfile != "" {
+		jobz = linalg.OptJobZValue
+	}
+	if err = lapack.Syevd(A, W, jobz, linalg.OptLower); err != nil {
+		return err
+	}
+	if *vfile != "" {
+		if err = writeFile(*vfile, outFormat, A); err != nil {
+			return err
+		}
+	}
+	return writeFile(outFile, outFormat, W)
+}
+
+func runNorm(fs *flag.FlagSet, args []string) error {
+	p := fs.String("p", "fro", "norm: 1, 2, inf or fro")
+	ms, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	A := ms[0]
+	var nrm float64
+	switch *p {
+	case "1":
+		nrm = normOne(A)
+	case "inf":
+		nrm = normOne(A.Transpose())
+	case "fro":
+		for _, v := range matops.Ravel(A) {
+			nrm = math.Hypot(nrm, v)
+		}
+	case "2":
+		S, _, _, err := svd(A, false)
+		if err != nil {
+			return err
+		}
+		if S.NumElements() > 0 {
+			nrm = S.GetIndex(0)
+		}
+	default:
+		return linalg.NewError(linalg.ErrParameter, fmt.Sprintf("unknown norm %q", *p))
+	}
+	fmt.Println(formatFloat(nrm))
+	return nil
+}
+
+func runCond(fs *flag.FlagSet, args []string) error {
+	ms, err := parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	S, _, _, err := svd(ms[0], false)
+	if err != nil {
+		return err
+	}
+	k := S.NumElements()
+	cond := math.Inf(1)
+	if k > 0 && S.GetIndex(k-1) != 0 {
+		cond = S.GetIndex(0) / S.GetIndex(k-1)
+	}
+	fmt.Println(formatFloat(cond))
+	return nil
+}
+
+func runConvert(fs *flag.FlagSet, args []string) error {
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	A, err := readFile(fs.Arg(0), inFormat)
+	if err != nil {
+		return err
+	}
+	return writeFile(fs.Arg(1), outFormat, A)
+}
+
+// Maximum absolute column sum.
+func normOne(A *matrix.FloatMatrix) float64 {
+	nrm := 0.0
+	for j := 0; j < A.Cols(); j++ {
+		s := 0.0
+		for i := 0; i < A.Rows(); i++ {
+			s += math.Abs(A.GetAt(i, j))
+		}
+		nrm = math.Max(nrm, s)
+	}
+	return nrm
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func imin(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func imax(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
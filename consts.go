@@ -7,7 +7,6 @@
 package linalg
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 )
@@ -91,42 +90,42 @@ Loop:
 				p.TransA = p.Trans
 				p.TransB = p.Trans
 			} else {
-				err = errors.New("Illegal value for Transpose parameter")
+				err = NewError(ErrParameter, "Illegal value for Transpose parameter")
 				break Loop
 			}
 		case strings.EqualFold(o.Name(), "transa"):
 			if pval == PNoTrans || pval == PTrans || pval == PConjTrans {
 				p.TransA = pval
 			} else {
-				err = errors.New("Illegal value for Transpose parameter")
+				err = NewError(ErrParameter, "Illegal value for Transpose parameter")
 				break Loop
 			}
 		case strings.EqualFold(o.Name(), "transb"):
 			if pval == PNoTrans || pval == PTrans || pval == PConjTrans {
 				p.TransB = pval
 			} else {
-				err = errors.New("Illegal value for Transpose parameter")
+				err = NewError(ErrParameter, "Illegal value for Transpose parameter")
 				break Loop
 			}
 		case strings.EqualFold(o.Name(), "uplo"):
 			if pval == PUpper || pval == PLower {
 				p.Uplo = pval
 			} else {
-				err = errors.New("Illegal value for UpLo parameter")
+				err = NewError(ErrParameter, "Illegal value for UpLo parameter")
 				break Loop
 			}
 		case strings.EqualFold(o.Name(), "diag"):
 			if pval == PNonUnit || pval == PUnit || pval == PDiag {
 				p.Diag = pval
 			} else {
-				err = errors.New("Illegal value for Diag parameter")
+				err = NewError(ErrParameter, "Illegal value for Diag parameter")
 				break Loop
 			}
 		case strings.EqualFold(o.Name(), "side"):
 			if pval == PLeft || pval == PRight {
 				p.Side = pval
 			} else {
-				err = errors.New("Illegal value for Side parameter")
+				err = NewError(ErrParameter, "Illegal value for Side parameter")
 				break Loop
 			}
 		// Lapack parameters
@@ -134,28 +133,28 @@ Loop:
 			if pval == PJobNo || pval == PJobValue {
 				p.Jobz = pval
 			} else {
-				err = errors.New("Illegal value for Jobz parameter")
+				err = NewError(ErrParameter, "Illegal value for Jobz parameter")
 				break Loop
 			}
 		case strings.EqualFold(o.Name(), "jobu"):
 			if pval == PJobNo || pval == PJobAll || pval == PJobS || pval == PJobO {
 				p.Jobu = pval
 			} else {
-				err = errors.New("Illegal value for Jobu parameter")
+				err = NewError(ErrParameter, "Illegal value for Jobu parameter")
 				break Loop
 			}
 		case strings.EqualFold(o.Name(), "jobvt"):
 			if pval == PJobNo || pval == PJobAll || pval == PJobS || pval == PJobO {
 				p.Jobvt = pval
 			} else {
-				err = errors.New("Illegal value for Jobu parameter")
+				err = NewError(ErrParameter, "Illegal value for Jobu parameter")
 				break Loop
 			}
 		case strings.EqualFold(o.Name(), "range"):
 			if pval == PRangeAll || pval == PRangeValue || pval == PRangeInt {
 				p.Range = pval
 			} else {
-				err = errors.New("Illegal value for Range parameter")
+				err = NewError(ErrParameter, "Illegal value for Range parameter")
 				break Loop
 			}
 		}
@@ -0,0 +1,190 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+// Command cshared builds a C shared library exporting core solvers with a
+// plain C ABI. Build with
+//
+//	go build -buildmode=c-shared -o liblinalg.so github.com/nvcook42/linalg/cshared
+//
+// which also writes the C header liblinalg.h. All matrices are column-major
+// double arrays with explicit leading dimensions, as in Fortran LAPACK.
+// Functions return 0 on success, a positive LAPACK info value if the
+// computation failed (eg. singular matrix) and a negative LINALG_E* code
+// for argument errors. See python/linalg.py for a ctypes wrapper.
+package main
+
+/*
+// Error codes returned by the exported functions.
+#define LINALG_ESHAPE     -1
+#define LINALG_ETYPE      -2
+#define LINALG_EPARAMETER -3
+#define LINALG_EOTHER     -4
+*/
+import "C"
+import (
+	"errors"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/blas"
+	"github.com/nvcook42/linalg/lapack"
+	"github.com/nvcook42/matrix"
+	"unsafe"
+)
+
+func main() {}
+
+// Return Go slice over n doubles at p; nil if p is nil.
+func doubles(p *C.double, n int) []float64 {
+	if p == nil || n <= 0 {
+		return nil
+	}
+	return (*[1 << 40]float64)(unsafe.Pointer(p))[:n:n]
+}
+
+// Wrap column-major array of cols columns with leading dimension ld into a
+// matrix. FloatNew uses the array as the matrix storage, so results are
+// written to the caller's array; store copies them back only if a routine
+// replaced the storage.
+func wrap(p *C.double, ld, cols int) (*matrix.FloatMatrix, []float64) {
+	s := doubles(p, ld*cols)
+	return matrix.FloatNew(ld, cols, s), s
+}
+
+func store(A *matrix.FloatMatrix, s []float64) {
+	if a := A.FloatArray(); len(s) > 0 && &a[0] != &s[0] {
+		copy(s, a)
+	}
+}
+
+func errorCode(err error) C.int {
+	var lerr *linalg.Error
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &lerr) && lerr.Info > 0:
+		return C.int(lerr.Info)
+	case errors.Is(err, linalg.ErrShape):
+		return C.LINALG_ESHAPE
+	case errors.Is(err, linalg.ErrType):
+		return C.LINALG_ETYPE
+	case errors.Is(err, linalg.ErrParameter):
+		return C.LINALG_EPARAMETER
+	}
+	return C.LINALG_EOTHER
+}
+
+func opt(name string, val C.int) linalg.Option {
+	return linalg.IntOpt(name, int(val))
+}
+
+func transOpt(name string, t C.char) linalg.Option {
+	switch t {
+	case 'T', 't':
+		return linalg.IntOpt(name, linalg.PTrans)
+	case 'C', 'c':
+		return linalg.IntOpt(name, linalg.PConjTrans)
+	}
+	return linalg.IntOpt(name, linalg.PNoTrans)
+}
+
+// Solve A*X = B for n by n A. On exit A holds LU factors, B the solution.
+//export linalg_gesv
+func linalg_gesv(n, nrhs C.int, A *C.double, lda C.int, B *C.double, ldb C.int) C.int {
+	if n < 0 || nrhs < 0 || lda < 1 || ldb < 1 {
+		return C.LINALG_EPARAMETER
+	}
+	Am, as := wrap(A, int(lda), int(n))
+	Bm, bs := wrap(B, int(ldb), int(nrhs))
+	ipiv := make([]int32, n)
+	err := lapack.Gesv(Am, Bm, ipiv, opt("n", n), opt("nrhs", nrhs),
+		opt("lda", lda), opt("ldb", ldb))
+	store(Am, as)
+	store(Bm, bs)
+	return errorCode(err)
+}
+
+// Least squares or minimum norm solution of m by n system A*X = B. B has
+// max(m,n) rows; on exit the first n rows hold the solution.
+//export linalg_gels
+func linalg_gels(m, n, nrhs C.int, A *C.double, lda C.int, B *C.double, ldb C.int) C.int {
+	if m < 0 || n < 0 || nrhs < 0 || lda < 1 || ldb < 1 {
+		return C.LINALG_EPARAMETER
+	}
+	Am, as := wrap(A, int(lda), int(n))
+	Bm, bs := wrap(B, int(ldb), int(nrhs))
+	err := lapack.Gels(Am, Bm, opt("m", m), opt("n", n), opt("nrhs", nrhs),
+		opt("lda", lda), opt("ldb", ldb))
+	store(Am, as)
+	store(Bm, bs)
+	return errorCode(err)
+}
+
+// Singular value decomposition of m by n A. S receives min(m,n) singular
+// values. If U and VT are not NULL the first min(m,n) left and right
+// singular vectors are stored in them. A is destroyed.
+//export linalg_gesvd
+func linalg_gesvd(m, n C.int, A *C.double, lda C.int, S *C.double,
+	U *C.double, ldu C.int, VT *C.double, ldvt C.int) C.int {
+	if m < 0 || n < 0 || lda < 1 {
+		return C.LINALG_EPARAMETER
+	}
+	k := int(m)
+	if n < m {
+		k = int(n)
+	}
+	Am, as := wrap(A, int(lda), int(n))
+	Sm, ss := wrap(S, k, 1)
+	opts := []linalg.Option{opt("m", m), opt("n", n), opt("lda", lda)}
+	var Um, Vm *matrix.FloatMatrix
+	var us, vs []float64
+	if U != nil && VT != nil {
+		Um, us = wrap(U, int(ldu), k)
+		Vm, vs = wrap(VT, int(ldvt), int(n))
+		opts = append(opts, linalg.OptJobuS, linalg.OptJobvtS, opt("ldu", ldu), opt("ldvt", ldvt))
+	} else {
+		Um = matrix.FloatZeros(1, 1)
+		Vm = matrix.FloatZeros(1, 1)
+		opts = append(opts, linalg.OptJobuNo, linalg.OptJobvtNo)
+	}
+	err := lapack.Gesvd(Am, Sm, Um, Vm, opts...)
+	store(Am, as)
+	store(Sm, ss)
+	if us != nil {
+		store(Um, us)
+		store(Vm, vs)
+	}
+	return errorCode(err)
+}
+
+// Compute C := alpha*op(A)*op(B) + beta*C with op(A) m by k and op(B) k by n.
+// transa and transb are 'N', 'T' or 'C'.
+//export linalg_gemm
+func linalg_gemm(transa, transb C.char, m, n, k C.int, alpha C.double,
+	A *C.double, lda C.int, B *C.double, ldb C.int, beta C.double,
+	Cp *C.double, ldc C.int) C.int {
+	if m < 0 || n < 0 || k < 0 || lda < 1 || ldb < 1 || ldc < 1 {
+		return C.LINALG_EPARAMETER
+	}
+	acols, bcols := int(k), int(n)
+	if transa != 'N' && transa != 'n' {
+		acols = int(m)
+	}
+	if transb != 'N' && transb != 'n' {
+		bcols = int(k)
+	}
+	Am, _ := wrap(A, int(lda), acols)
+	Bm, _ := wrap(B, int(ldb), bcols)
+	Cm, cs := wrap(Cp, int(ldc), int(n))
+	err := blas.Gemm(Am, Bm, Cm, matrix.FScalar(alpha), matrix.FScalar(beta),
+		transOpt("transA", transa), transOpt("transB", transb),
+		opt("m", m), opt("n", n), opt("k", k),
+		opt("lda", lda), opt("ldb", ldb), opt("ldc", ldc))
+	store(Cm, cs)
+	return errorCode(err)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,22 @@
+# Compare results of liblinalg.so against NumPy. Run from the directory
+# containing liblinalg.so:  python3 check_numpy.py
+
+import numpy as np
+
+import linalg
+
+rng = np.random.default_rng(1)
+A = rng.standard_normal((6, 6))
+B = rng.standard_normal((6, 2))
+M = rng.standard_normal((8, 5))
+y = rng.standard_normal(8)
+
+checks = {
+    "gesv": np.allclose(linalg.gesv(A, B), np.linalg.solve(A, B)),
+    "gels": np.allclose(linalg.gels(M, y), np.linalg.lstsq(M, y, rcond=None)[0]),
+    "gesvd": np.allclose(linalg.gesvd(M, compute_uv=False), np.linalg.svd(M, compute_uv=False)),
+    "gemm": np.allclose(linalg.gemm(M, A[:5, :5], transb="T"), M @ A[:5, :5].T),
+}
+for name, ok in checks.items():
+    print("%-6s %s" % (name, "ok" if ok else "MISMATCH"))
+raise SystemExit(0 if all(checks.values()) else 1)
@@ -0,0 +1,131 @@
+# Copyright (c) Harri Rautila, 2013
+#
+# This file is part of github.com/nvcook42/linalg package.
+# It is free software, distributed under the terms of GNU Lesser General Public
+# License Version 3, or any later version. See the COPYING tile included in this archive.
+
+"""Thin ctypes wrapper around liblinalg.so built from the cshared package.
+
+Functions take and return NumPy arrays. Inputs are copied to Fortran
+(column-major) order before the call, so arguments are never modified.
+
+    >>> import numpy as np, linalg
+    >>> A = np.random.rand(4, 4); b = np.random.rand(4)
+    >>> np.allclose(linalg.gesv(A, b), np.linalg.solve(A, b))
+    True
+
+Set LINALG_LIB to the path of the shared library if it is not found in
+the current directory or on the system library path.
+"""
+
+import ctypes
+import os
+
+import numpy as np
+
+__all__ = ["LinalgError", "gesv", "gels", "gesvd", "gemm"]
+
+_ERRORS = {-1: "dimension mismatch", -2: "unsupported matrix type",
+           -3: "illegal parameter value", -4: "internal error"}
+
+
+def _load():
+    path = os.environ.get("LINALG_LIB")
+    if path is None:
+        local = os.path.join(os.getcwd(), "liblinalg.so")
+        path = local if os.path.exists(local) else "liblinalg.so"
+    return ctypes.CDLL(path)
+
+
+_lib = _load()
+_int = ctypes.c_int
+_dbl = ctypes.c_double
+_ptr = ctypes.POINTER(ctypes.c_double)
+
+_lib.linalg_gesv.argtypes = [_int, _int, _ptr, _int, _ptr, _int]
+_lib.linalg_gels.argtypes = [_int, _int, _int, _ptr, _int, _ptr, _int]
+_lib.linalg_gesvd.argtypes = [_int, _int, _ptr, _int, _ptr, _ptr, _int, _ptr, _int]
+_lib.linalg_gemm.argtypes = [ctypes.c_char, ctypes.c_char, _int, _int, _int, _dbl,
+                             _ptr, _int, _ptr, _int, _dbl, _ptr, _int]
+for _f in (_lib.linalg_gesv, _lib.linalg_gels, _lib.linalg_gesvd, _lib.linalg_gemm):
+    _f.restype = _int
+
+
+class LinalgError(Exception):
+    """Raised on a non-zero return code. Attribute code holds the value:
+    positive for LAPACK info (eg. singular matrix), negative for argument
+    errors."""
+
+    def __init__(self, func, code):
+        msg = _ERRORS.get(code, "lapack error %d" % code)
+        super().__init__("%s: %s" % (func, msg))
+        self.code = code
+
+
+def _fortran(a, ndmin=2):
+    a = np.array(a, dtype=np.float64, order="F", copy=True, ndmin=ndmin)
+    if a.ndim == 1:
+        a = a.reshape(-1, 1, order="F")
+    return a
+
+
+def _p(a):
+    return a.ctypes.data_as(_ptr)
+
+
+def _check(func, code):
+    if code != 0:
+        raise LinalgError(func, code)
+
+
+def gesv(A, B):
+    """Solve A*X = B for square A. B may be a vector."""
+    vec = np.ndim(B) == 1
+    A, B = _fortran(A), _fortran(B, 1)
+    n, nrhs = A.shape[0], B.shape[1]
+    _check("gesv", _lib.linalg_gesv(n, nrhs, _p(A), max(1, n), _p(B), max(1, n)))
+    return B[:, 0] if vec else B
+
+
+def gels(A, B):
+    """Least squares solution of overdetermined or minimum norm solution of
+    underdetermined system A*X = B."""
+    vec = np.ndim(B) == 1
+    A, B = _fortran(A), _fortran(B, 1)
+    m, n = A.shape
+    nrhs = B.shape[1]
+    ldb = max(1, m, n)
+    Bw = np.zeros((ldb, nrhs), order="F")
+    Bw[:m, :] = B
+    _check("gels", _lib.linalg_gels(m, n, nrhs, _p(A), max(1, m), _p(Bw), ldb))
+    X = Bw[:n, :]
+    return X[:, 0] if vec else X
+
+
+def gesvd(A, compute_uv=True):
+    """Thin SVD. Returns (U, s, Vt) or s alone if compute_uv is false."""
+    A = _fortran(A)
+    m, n = A.shape
+    k = min(m, n)
+    s = np.zeros(k)
+    if not compute_uv:
+        _check("gesvd", _lib.linalg_gesvd(m, n, _p(A), max(1, m), _p(s), None, 1, None, 1))
+        return s
+    U = np.zeros((m, k), order="F")
+    Vt = np.zeros((k, n), order="F")
+    _check("gesvd", _lib.linalg_gesvd(m, n, _p(A), max(1, m), _p(s),
+                                      _p(U), max(1, m), _p(Vt), max(1, k)))
+    return U, s, Vt
+
+
+def gemm(A, B, C=None, alpha=1.0, beta=0.0, transa="N", transb="N"):
+    """Return alpha*op(A)*op(B) + beta*C."""
+    A, B = _fortran(A), _fortran(B)
+    m = A.shape[0] if transa in "Nn" else A.shape[1]
+    k = A.shape[1] if transa in "Nn" else A.shape[0]
+    n = B.shape[1] if transb in "Nn" else B.shape[0]
+    C = np.zeros((m, n), order="F") if C is None else _fortran(C)
+    _check("gemm", _lib.linalg_gemm(transa.encode(), transb.encode(), m, n, k, alpha,
+                                    _p(A), max(1, A.shape[0]), _p(B), max(1, B.shape[0]),
+                                    beta, _p(C), max(1, m)))
+    return C
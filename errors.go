@@ -0,0 +1,97 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package linalg
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error classes. Every error returned by this package and by blas and lapack
+// packages wraps exactly one of these; test for them with errors.Is.
+var (
+	// Matrix dimensions do not agree or a matrix is too small for given options.
+	ErrShape = errors.New("linalg: dimension mismatch")
+	// Argument matrices are of unsupported or mixed types.
+	ErrType = errors.New("linalg: unsupported matrix type")
+	// Option or scalar argument has an illegal value.
+	ErrParameter = errors.New("linalg: illegal parameter value")
+	// Matrix is exactly singular; factorization completed but solve is not possible.
+	ErrSingular = errors.New("linalg: matrix is singular")
+	// Matrix is not positive definite; Cholesky factorization failed.
+	ErrNotPositiveDefinite = errors.New("linalg: matrix is not positive definite")
+	// Iterative algorithm failed to converge.
+	ErrNoConvergence = errors.New("linalg: no convergence")
+	// Operation is not implemented for given argument types.
+	ErrNotImplemented = errors.New("linalg: not implemented")
+	// Output argument is a read-only matrix.
+	ErrReadOnly = errors.New("linalg: matrix is read-only")
+	// Operation would exceed the memory budget set with SetMemoryBudget.
+	ErrMemoryBudget = errors.New("linalg: memory budget exceeded")
+	// Output argument shares elements with an input argument.
+	ErrAlias = errors.New("linalg: output overlaps input")
+)
+
+// Error is the concrete error type returned by the linalg packages. Kind is one
+// of the error class variables above and is what errors.Is matches against.
+// Info holds the LAPACK info value of a failed call or zero if error was
+// detected before calling LAPACK.
+//
+// Unconverged is set for ErrNoConvergence from drivers that return partial
+// results; it lists the indices of eigenvalues or eigenvectors that did not
+// converge. Output elements at the other indices are valid.
+type Error struct {
+	Kind        error
+	Msg         string
+	Info        int
+	Unconverged []int
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// Create new error of class kind with message msg.
+func NewError(kind error, msg string) error {
+	return &Error{Kind: kind, Msg: msg}
+}
+
+// Create error for LAPACK function name returning non-zero info. Negative info
+// reports an illegal argument and is classified as ErrParameter, positive info
+// is classified as kind.
+func LapackError(name string, info int, kind error) error {
+	if info < 0 {
+		kind = ErrParameter
+	}
+	msg := fmt.Sprintf("%s: lapack error %d", name, info)
+	return &Error{Kind: kind, Msg: msg, Info: info}
+}
+
+// Create ErrNoConvergence error for LAPACK function name returning positive
+// info, with indices of unconverged values.
+func ConvergenceError(name string, info int, unconverged []int) error {
+	msg := fmt.Sprintf("%s: did not converge, %d values unconverged", name, len(unconverged))
+	return &Error{Kind: ErrNoConvergence, Msg: msg, Info: info, Unconverged: unconverged}
+}
+
+// Return indices of unconverged values reported by err, or nil if err
+// carries no partial result.
+func Unconverged(err error) []int {
+	var e *Error
+	if errors.As(err, &e) && e.Kind == ErrNoConvergence {
+		return e.Unconverged
+	}
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
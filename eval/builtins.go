@@ -0,0 +1,337 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/eval package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package eval
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+var constants = map[string]float64{
+	"pi":  math.Pi,
+	"e":   math.E,
+	"inf": math.Inf(1),
+	"nan": math.NaN(),
+}
+
+var builtins map[string]Func
+
+func init() {
+	builtins = map[string]Func{
+		"inv":   inv,
+		"solve": solve,
+		"t":     trans,
+		"eye":   eye,
+		"zeros": zeros,
+		"ones":  ones,
+		"diag":  diag,
+		"trace": trace,
+		"norm":  norm,
+		"rows":  rows,
+		"cols":  cols,
+		"sum":   sum,
+		"kron":  kron,
+		"abs":   elementwise("abs", math.Abs),
+		"sqrt":  elementwise("sqrt", math.Sqrt),
+		"exp":   elementwise("exp", math.Exp),
+		"log":   elementwise("log", math.Log),
+	}
+}
+
+// Return names of builtin functions.
+func Builtins() []string {
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	return names
+}
+
+func nargs(name string, args []Value, n ...int) error {
+	for _, k := range n {
+		if len(args) == k {
+			return nil
+		}
+	}
+	return linalg.NewError(linalg.ErrParameter,
+		fmt.Sprintf("eval: %s: wrong number of arguments %d", name, len(args)))
+}
+
+func matrixArg(name string, v Value) (*matrix.FloatMatrix, error) {
+	A, err := AsMatrix(v)
+	if err != nil {
+		return nil, linalg.NewError(linalg.ErrType, fmt.Sprintf("eval: %s: argument not a matrix", name))
+	}
+	return A, nil
+}
+
+// Largest number of elements of a matrix made from size arguments.
+const maxElements = math.MaxInt32
+
+func intArg(name string, v Value) (int, error) {
+	s, err := AsScalar(v)
+	if err != nil || math.IsInf(s, 0) || s != math.Trunc(s) || s < 0 {
+		return 0, linalg.NewError(linalg.ErrParameter,
+			fmt.Sprintf("eval: %s: argument not a non-negative integer", name))
+	}
+	if s > maxElements {
+		return 0, linalg.NewError(linalg.ErrParameter,
+			fmt.Sprintf("eval: %s: argument %g too large", name, s))
+	}
+	return int(s), nil
+}
+
+func inv(args ...Value) (Value, error) {
+	if err := nargs("inv", args, 1); err != nil {
+		return nil, err
+	}
+	A, err := matrixArg("inv", args[0])
+	if err != nil {
+		return nil, err
+	}
+	return matops.Solve(A, matrix.FloatIdentity(A.Rows()))
+}
+
+func solve(args ...Value) (Value, error) {
+	if err := nargs("solve", args, 2); err != nil {
+		return nil, err
+	}
+	A, err := matrixArg("solve", args[0])
+	if err != nil {
+		return nil, err
+	}
+	B, err := matrixArg("solve", args[1])
+	if err != nil {
+		return nil, err
+	}
+	return matops.Solve(A, B)
+}
+
+func trans(args ...Value) (Value, error) {
+	if err := nargs("t", args, 1); err != nil {
+		return nil, err
+	}
+	A, err := matrixArg("t", args[0])
+	if err != nil {
+		return nil, err
+	}
+	return A.Transpose(), nil
+}
+
+// Size arguments (n) or (m, n).
+func sizeArgs(name string, args []Value) (int, int, error) {
+	if err := nargs(name, args, 1, 2); err != nil {
+		return 0, 0, err
+	}
+	m, err := intArg(name, args[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	n := m
+	if len(args) == 2 {
+		if n, err = intArg(name, args[1]); err != nil {
+			return 0, 0, err
+		}
+	}
+	if n > 0 && m > maxElements/n {
+		return 0, 0, linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("eval: %s: %d by %d matrix too large", name, m, n))
+	}
+	need := int64(m) * int64(n) * 8
+	if budget := linalg.MemoryBudget(); budget > 0 && need > budget {
+		return 0, 0, linalg.NewError(linalg.ErrMemoryBudget,
+			fmt.Sprintf("eval: %s: %d by %d matrix needs %d bytes, budget %d", name, m, n, need, budget))
+	}
+	return m, n, nil
+}
+
+func eye(args ...Value) (Value, error) {
+	m, n, err := sizeArgs("eye", args)
+	if err != nil {
+		return nil, err
+	}
+	A := matrix.FloatZeros(m, n)
+	for k := 0; k < m && k < n; k++ {
+		A.SetAt(k, k, 1.0)
+	}
+	return A, nil
+}
+
+func zeros(args ...Value) (Value, error) {
+	m, n, err := sizeArgs("zeros", args)
+	if err != nil {
+		return nil, err
+	}
+	return matrix.FloatZeros(m, n), nil
+}
+
+func ones(args ...Value) (Value, error) {
+	m, n, err := sizeArgs("ones", args)
+	if err != nil {
+		return nil, err
+	}
+	return matrix.FloatWithValue(m, n, 1.0), nil
+}
+
+// diag(v) returns diagonal matrix, diag(A) the diagonal of A as column vector.
+func diag(args ...Value) (Value, error) {
+	if err := nargs("diag", args, 1); err != nil {
+		return nil, err
+	}
+	A, err := matrixArg("diag", args[0])
+	if err != nil {
+		return nil, err
+	}
+	if A.Rows() == 1 || A.Cols() == 1 {
+		v := matops.Ravel(A)
+		D := matrix.FloatZeros(len(v), len(v))
+		for k, x := range v {
+			D.SetAt(k, k, x)
+		}
+		return D, nil
+	}
+	d, err := matops.GetDiag(A, 0)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func trace(args ...Value) (Value, error) {
+	if err := nargs("trace", args, 1); err != nil {
+		return nil, err
+	}
+	A, err := matrixArg("trace", args[0])
+	if err != nil {
+		return nil, err
+	}
+	s := 0.0
+	for k := 0; k < A.Rows() && k < A.Cols(); k++ {
+		s += A.GetAt(k, k)
+	}
+	return s, nil
+}
+
+// norm(A) is the Frobenius norm, norm(A, 1) and norm(A, inf) the 1- and
+// infinity norms.
+func norm(args ...Value) (Value, error) {
+	if err := nargs("norm", args, 1, 2); err != nil {
+		return nil, err
+	}
+	A, err := matrixArg("norm", args[0])
+	if err != nil {
+		return nil, err
+	}
+	p := 2.0
+	if len(args) == 2 {
+		if p, err = AsScalar(args[1]); err != nil {
+			return nil, err
+		}
+	}
+	nrm := 0.0
+	switch {
+	case p == 1 || math.IsInf(p, 1):
+		if math.IsInf(p, 1) {
+			A = A.Transpose()
+		}
+		for j := 0; j < A.Cols(); j++ {
+			s := 0.0
+			for i := 0; i < A.Rows(); i++ {
+				s += math.Abs(A.GetAt(i, j))
+			}
+			nrm = math.Max(nrm, s)
+		}
+	case p == 2 && (A.Rows() == 1 || A.Cols() == 1), len(args) == 1:
+		for _, v := range matops.Ravel(A) {
+			nrm = math.Hypot(nrm, v)
+		}
+	default:
+		return nil, linalg.NewError(linalg.ErrParameter, fmt.Sprintf("eval: norm: unsupported norm %v", p))
+	}
+	return nrm, nil
+}
+
+func rows(args ...Value) (Value, error) {
+	if err := nargs("rows", args, 1); err != nil {
+		return nil, err
+	}
+	A, err := matrixArg("rows", args[0])
+	if err != nil {
+		return nil, err
+	}
+	return float64(A.Rows()), nil
+}
+
+func cols(args ...Value) (Value, error) {
+	if err := nargs("cols", args, 1); err != nil {
+		return nil, err
+	}
+	A, err := matrixArg("cols", args[0])
+	if err != nil {
+		return nil, err
+	}
+	return float64(A.Cols()), nil
+}
+
+func sum(args ...Value) (Value, error) {
+	if err := nargs("sum", args, 1); err != nil {
+		return nil, err
+	}
+	A, err := matrixArg("sum", args[0])
+	if err != nil {
+		return nil, err
+	}
+	s := 0.0
+	for _, v := range matops.Ravel(A) {
+		s += v
+	}
+	return s, nil
+}
+
+func kron(args ...Value) (Value, error) {
+	if err := nargs("kron", args, 2); err != nil {
+		return nil, err
+	}
+	A, err := matrixArg("kron", args[0])
+	if err != nil {
+		return nil, err
+	}
+	B, err := matrixArg("kron", args[1])
+	if err != nil {
+		return nil, err
+	}
+	return matops.Kron(A, B)
+}
+
+// Return function applying f to scalar or to each element of matrix.
+func elementwise(name string, f func(float64) float64) Func {
+	return func(args ...Value) (Value, error) {
+		if err := nargs(name, args, 1); err != nil {
+			return nil, err
+		}
+		if s, ok := args[0].(float64); ok {
+			return f(s), nil
+		}
+		A, err := matrixArg(name, args[0])
+		if err != nil {
+			return nil, err
+		}
+		B := matrix.FloatZeros(A.Rows(), A.Cols())
+		Br := B.FloatArray()
+		for k, v := range matops.Ravel(A) {
+			Br[k] = f(v)
+		}
+		return B, nil
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
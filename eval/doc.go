@@ -0,0 +1,46 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/eval package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+/*
+Package eval evaluates matrix expressions such as
+
+	x = inv(A)*b
+	r = norm(A*x - b)/norm(b)
+
+over float matrices and scalars bound in an environment.
+
+Syntax, from lowest to highest precedence:
+
+	name = expr              assignment, stores result in environment
+	x + y,  x - y            addition, subtraction; scalars broadcast
+	x * y                    matrix product or scaling
+	x .* y, x ./ y           elementwise product and division
+	x / y, x \ y             right and left division; A\b solves A*x = b
+	-x                       negation
+	x ^ p                    power; integer matrix power for square x
+	x'                       transpose
+	f(a, b, ...)             function call
+	[1, 2; 3, 4]             matrix literal, rows separated with ';'
+
+Elements of matrix literals may be separated with commas or spaces and may
+themselves be matrices of matching size; elements that are not numbers,
+names or calls should be parenthesized.
+
+Builtin functions are inv, solve, t, eye, zeros, ones, diag, trace, norm,
+rows, cols, sum, kron, abs, sqrt, exp and log; builtin constants are pi, e,
+inf and nan. Environment entries of type Func override builtins, so
+applications can register functions such as LAPACK based decompositions.
+Builtins are pure Go (see matops.Solve) and the package does not need BLAS
+or LAPACK libraries.
+
+Errors wrap the linalg error classes; syntax errors and undefined names are
+reported as linalg.ErrParameter with the position in the source string.
+*/
+package eval
+
+// Local Variables:
+// tab-width: 4
+// End:
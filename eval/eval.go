@@ -0,0 +1,437 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/eval package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package eval
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Value of an expression: float64 or *matrix.FloatMatrix.
+type Value interface{}
+
+// Function callable from expressions.
+type Func func(args ...Value) (Value, error)
+
+// Evaluation environment. Values are float64, *matrix.FloatMatrix or Func.
+// Names not found in environment are looked up from builtin functions.
+type Env map[string]interface{}
+
+// Parsed statement.
+type Expr struct {
+	src  string
+	name string
+	root node
+}
+
+// Parse statement "expr" or "name = expr".
+func Parse(src string) (*Expr, error) {
+	toks, err := scan(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	name, root, err := p.statement()
+	if err != nil {
+		return nil, err
+	}
+	return &Expr{src, name, root}, nil
+}
+
+// Evaluate expression in env. If expression is an assignment the result is
+// also stored in env under the assigned name.
+func (e *Expr) Eval(env Env) (Value, error) {
+	v, err := e.root.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if e.name != "" {
+		if env == nil {
+			return nil, linalg.NewError(linalg.ErrParameter, "eval: assignment to nil environment")
+		}
+		env[e.name] = v
+	}
+	return v, nil
+}
+
+// Name assigned to by the statement or empty string.
+func (e *Expr) Name() string {
+	return e.name
+}
+
+func (e *Expr) String() string {
+	return e.src
+}
+
+// Parse and evaluate statement src in env. See package documentation for
+// the expression syntax.
+func Eval(src string, env Env) (Value, error) {
+	e, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return e.Eval(env)
+}
+
+// Return value as matrix; scalars are returned as 1x1 matrices.
+func AsMatrix(v Value) (*matrix.FloatMatrix, error) {
+	switch x := v.(type) {
+	case float64:
+		return matrix.FloatWithValue(1, 1, x), nil
+	case *matrix.FloatMatrix:
+		return x, nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, fmt.Sprintf("eval: %T is not a matrix", v))
+}
+
+// Return value as scalar; 1x1 matrices are accepted.
+func AsScalar(v Value) (float64, error) {
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case *matrix.FloatMatrix:
+		if x.Rows() == 1 && x.Cols() == 1 {
+			return x.GetAt(0, 0), nil
+		}
+	}
+	return 0, linalg.NewError(linalg.ErrType, fmt.Sprintf("eval: %v is not a scalar", v))
+}
+
+type node interface {
+	eval(env Env) (Value, error)
+}
+
+type number float64
+
+func (n number) eval(env Env) (Value, error) {
+	return float64(n), nil
+}
+
+type ident struct {
+	name string
+	pos  int
+}
+
+func (n *ident) eval(env Env) (Value, error) {
+	v, ok := env[n.name]
+	if !ok {
+		if c, ok := constants[n.name]; ok {
+			return c, nil
+		}
+		return nil, linalg.NewError(linalg.ErrParameter,
+			fmt.Sprintf("eval: undefined name %q at %d", n.name, n.pos))
+	}
+	switch v.(type) {
+	case float64, *matrix.FloatMatrix:
+		return v, nil
+	}
+	return nil, linalg.NewError(linalg.ErrType,
+		fmt.Sprintf("eval: %q at %d is not a value", n.name, n.pos))
+}
+
+type call struct {
+	name string
+	pos  int
+	args []node
+}
+
+func (n *call) eval(env Env) (Value, error) {
+	var f Func
+	if v, ok := env[n.name]; ok {
+		f, _ = v.(Func)
+		if g, ok := v.(func(...Value) (Value, error)); ok {
+			f = g
+		}
+	} else {
+		f = builtins[n.name]
+	}
+	if f == nil {
+		return nil, linalg.NewError(linalg.ErrParameter,
+			fmt.Sprintf("eval: undefined function %q at %d", n.name, n.pos))
+	}
+	args := make([]Value, len(n.args))
+	for k, a := range n.args {
+		v, err := a.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[k] = v
+	}
+	return f(args...)
+}
+
+type negate struct {
+	x node
+}
+
+func (n *negate) eval(env Env) (Value, error) {
+	v, err := n.x.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch x := v.(type) {
+	case float64:
+		return -x, nil
+	case *matrix.FloatMatrix:
+		return matrix.Scale(x, -1.0), nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "eval: invalid operand")
+}
+
+type transpose struct {
+	x node
+}
+
+func (n *transpose) eval(env Env) (Value, error) {
+	v, err := n.x.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if A, ok := v.(*matrix.FloatMatrix); ok {
+		return A.Transpose(), nil
+	}
+	return v, nil
+}
+
+type binary struct {
+	op   string
+	pos  int
+	x, y node
+}
+
+func (n *binary) eval(env Env) (Value, error) {
+	x, err := n.x.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	y, err := n.y.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	v, err := apply(n.op, x, y)
+	if err != nil {
+		if e, ok := err.(*linalg.Error); ok {
+			return nil, &linalg.Error{Kind: e.Kind, Info: e.Info,
+				Msg: fmt.Sprintf("%s (operator %s at %d)", e.Msg, n.op, n.pos)}
+		}
+	}
+	return v, err
+}
+
+// Apply binary operator op.
+func apply(op string, x, y Value) (Value, error) {
+	a, xs := x.(float64)
+	b, ys := y.(float64)
+	if xs && ys {
+		switch op {
+		case "+":
+			return a + b, nil
+		case "-":
+			return a - b, nil
+		case "*", ".*":
+			return a * b, nil
+		case "/", "./":
+			return a / b, nil
+		case "\\":
+			return b / a, nil
+		case "^":
+			return math.Pow(a, b), nil
+		}
+	}
+	switch op {
+	case "+", "-":
+		if xs || ys {
+			return scalarOp(op, x, y)
+		}
+		A, B := x.(*matrix.FloatMatrix), y.(*matrix.FloatMatrix)
+		if A.Rows() != B.Rows() || A.Cols() != B.Cols() {
+			return nil, shapeError(op, A, B)
+		}
+		if op == "+" {
+			return A.Copy().Plus(B), nil
+		}
+		return matrix.Minus(A, B), nil
+	case "*":
+		if xs || ys {
+			return scalarOp(op, x, y)
+		}
+		A, B := x.(*matrix.FloatMatrix), y.(*matrix.FloatMatrix)
+		if A.Cols() != B.Rows() {
+			return nil, shapeError(op, A, B)
+		}
+		return matrix.Times(A, B), nil
+	case ".*", "./":
+		if xs || ys {
+			return scalarOp(op, x, y)
+		}
+		var C matrix.Matrix
+		var err error
+		if op == ".*" {
+			C, err = matops.MulElem(x.(*matrix.FloatMatrix), y.(*matrix.FloatMatrix))
+		} else {
+			C, err = matops.DivElem(x.(*matrix.FloatMatrix), y.(*matrix.FloatMatrix))
+		}
+		if err != nil {
+			return nil, err
+		}
+		return C, nil
+	case "/":
+		if ys {
+			return scalarOp(op, x, y)
+		}
+		// X/B = (B'\X')'
+		B, _ := AsMatrix(y)
+		A, _ := AsMatrix(x)
+		X, err := matops.Solve(B.Transpose(), A.Transpose())
+		if err != nil {
+			return nil, err
+		}
+		return X.Transpose(), nil
+	case "\\":
+		if xs {
+			return scalarOp("/", y, x)
+		}
+		B, _ := AsMatrix(y)
+		return matops.Solve(x.(*matrix.FloatMatrix), B)
+	case "^":
+		if !ys {
+			return nil, linalg.NewError(linalg.ErrType, "eval: exponent not a scalar")
+		}
+		if xs {
+			return math.Pow(a, b), nil
+		}
+		return mpower(x.(*matrix.FloatMatrix), b)
+	}
+	return nil, linalg.NewError(linalg.ErrParameter, fmt.Sprintf("eval: unknown operator %s", op))
+}
+
+// Elementwise operation between scalar and matrix.
+func scalarOp(op string, x, y Value) (Value, error) {
+	var A *matrix.FloatMatrix
+	var s float64
+	left := false
+	if v, ok := x.(float64); ok {
+		A, s, left = y.(*matrix.FloatMatrix).Copy(), v, true
+	} else {
+		A, s = x.(*matrix.FloatMatrix).Copy(), y.(float64)
+	}
+	Ar := matops.Ravel(A)
+	for k, a := range Ar {
+		switch {
+		case op == "+":
+			Ar[k] = a + s
+		case op == "-" && left:
+			Ar[k] = s - a
+		case op == "-":
+			Ar[k] = a - s
+		case op == "*" || op == ".*":
+			Ar[k] = a * s
+		case (op == "/" || op == "./") && left:
+			Ar[k] = s / a
+		case op == "/" || op == "./":
+			Ar[k] = a / s
+		}
+	}
+	return matrix.FloatNew(A.Rows(), A.Cols(), Ar), nil
+}
+
+// Integer power of square matrix by repeated squaring; negative powers
+// invert first.
+func mpower(A *matrix.FloatMatrix, p float64) (Value, error) {
+	n := A.Rows()
+	if A.Cols() != n {
+		return nil, linalg.NewError(linalg.ErrShape, "eval: matrix power of non-square matrix")
+	}
+	if math.IsInf(p, 0) || p != math.Trunc(p) {
+		return nil, linalg.NewError(linalg.ErrParameter, "eval: matrix power not an integer")
+	}
+	// float64(math.MaxInt64) is 2^63, which does not fit int64
+	if math.Abs(p) >= math.MaxInt64 {
+		return nil, linalg.NewError(linalg.ErrParameter,
+			fmt.Sprintf("eval: matrix power %g too large", p))
+	}
+	if p < 0 {
+		Ai, err := matops.Solve(A, matrix.FloatIdentity(n))
+		if err != nil {
+			return nil, err
+		}
+		A, p = Ai, -p
+	}
+	R := matrix.FloatIdentity(n)
+	for k := int64(p); k > 0; k >>= 1 {
+		if k&1 == 1 {
+			R = matrix.Times(R, A)
+		}
+		if k > 1 {
+			A = matrix.Times(A, A)
+		}
+	}
+	return R, nil
+}
+
+func shapeError(op string, A, B *matrix.FloatMatrix) error {
+	return linalg.NewError(linalg.ErrShape,
+		fmt.Sprintf("eval: %dx%d %s %dx%d", A.Rows(), A.Cols(), op, B.Rows(), B.Cols()))
+}
+
+type literal struct {
+	pos  int
+	rows [][]node
+}
+
+func (n *literal) eval(env Env) (Value, error) {
+	if len(n.rows) == 1 && len(n.rows[0]) == 0 {
+		return matrix.FloatZeros(0, 0), nil
+	}
+	var R *matrix.FloatMatrix
+	for _, row := range n.rows {
+		var Row *matrix.FloatMatrix
+		for _, e := range row {
+			v, err := e.eval(env)
+			if err != nil {
+				return nil, err
+			}
+			B, _ := AsMatrix(v)
+			if Row == nil {
+				Row = B
+				continue
+			}
+			if B.Rows() != Row.Rows() {
+				return nil, linalg.NewError(linalg.ErrShape,
+					fmt.Sprintf("eval: row lengths differ in matrix at %d", n.pos))
+			}
+			C := matrix.FloatZeros(Row.Rows(), Row.Cols()+B.Cols())
+			C.SetSubMatrix(0, 0, Row)
+			C.SetSubMatrix(0, Row.Cols(), B)
+			Row = C
+		}
+		if Row == nil {
+			return nil, linalg.NewError(linalg.ErrShape,
+				fmt.Sprintf("eval: empty row in matrix at %d", n.pos))
+		}
+		if R == nil {
+			R = Row
+			continue
+		}
+		if Row.Cols() != R.Cols() {
+			return nil, linalg.NewError(linalg.ErrShape,
+				fmt.Sprintf("eval: row lengths differ in matrix at %d", n.pos))
+		}
+		C := matrix.FloatZeros(R.Rows()+Row.Rows(), R.Cols())
+		C.SetSubMatrix(0, 0, R)
+		C.SetSubMatrix(R.Rows(), 0, Row)
+		R = C
+	}
+	return R, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,89 @@
+package eval
+
+import (
+	"errors"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	A := matrix.FloatMatrixFromTable([][]float64{
+		[]float64{4.0, 1.0},
+		[]float64{2.0, 3.0}}, matrix.RowOrder)
+	env := Env{"A": A, "b": matrix.FloatVector([]float64{1.0, 2.0})}
+	_, err := Eval("x = inv(A)*b", env)
+	if err != nil {
+		t.Logf("inv(A)*b: %v\n", err)
+		t.FailNow()
+	}
+	_, err = Eval("y = A\\b", env)
+	if err != nil {
+		t.FailNow()
+	}
+	r, err := Eval("norm(A*x - b) + norm(x - y)", env)
+	if s, _ := AsScalar(r); err != nil || s > 1e-14 {
+		t.Logf("residual %v, %v\n", r, err)
+		t.Fail()
+	}
+	if _, ok := env["x"]; !ok {
+		t.Logf("assignment not stored\n")
+		t.Fail()
+	}
+	tests := map[string]float64{
+		"1 + 2*3^2":                 19,
+		"-2^2":                      -4,
+		"trace(A') + 1":             8,
+		"sum([1 2; 3 4] .* eye(2))": 5,
+		"norm([3, 4])":              5,
+		"sum(A^2 - A*A)":            0,
+		"rows(kron(A, ones(3, 1)))": 6,
+		"sum(A^-1 * A)":             2,
+		"2 \\ 8":                    4,
+	}
+	for src, want := range tests {
+		v, err := Eval(src, env)
+		s, _ := AsScalar(v)
+		if err != nil || math.Abs(s-want) > 1e-12 {
+			t.Logf("%s = %v, %v; want %v\n", src, v, err, want)
+			t.Fail()
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	env := Env{
+		"A": matrix.FloatZeros(2, 3),
+		"double": Func(func(args ...Value) (Value, error) {
+			s, err := AsScalar(args[0])
+			return 2 * s, err
+		}),
+	}
+	if v, err := Eval("double(21)", env); err != nil || v.(float64) != 42 {
+		t.Logf("user function: %v %v\n", v, err)
+		t.Fail()
+	}
+	errs := map[string]error{
+		"A*A":                  linalg.ErrShape,
+		"B + 1":                linalg.ErrParameter,
+		"1 +":                  linalg.ErrParameter,
+		"[1 2; 3]":             linalg.ErrShape,
+		"foo(1)":               linalg.ErrParameter,
+		"zeros(2)\\ones(2, 1)": linalg.ErrSingular,
+		"1 # 2":                linalg.ErrParameter,
+		"eye(1e10)":            linalg.ErrParameter,
+		"zeros(1/0)":           linalg.ErrParameter,
+		"ones(1e5, 1e5)":       linalg.ErrShape,
+		"[2 0; 0 2]^1e30":      linalg.ErrParameter,
+		"[2 0; 0 2]^(1/0)":     linalg.ErrParameter,
+		"[2 0; 0 2]^(0/0)":     linalg.ErrParameter,
+	}
+	for src, kind := range errs {
+		_, err := Eval(src, env)
+		if !errors.Is(err, kind) {
+			t.Logf("%s: %v, want %v\n", src, err, kind)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,288 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/eval package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package eval
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"strconv"
+	"unicode"
+)
+
+const (
+	tEOF = iota
+	tNum
+	tIdent
+	tOp
+)
+
+type token struct {
+	kind int
+	text string
+	pos  int
+}
+
+// Split expression into tokens.
+func scan(src string) ([]token, error) {
+	var toks []token
+	rs := []rune(src)
+	for k := 0; k < len(rs); {
+		r := rs[k]
+		switch {
+		case unicode.IsSpace(r):
+			k++
+		case unicode.IsDigit(r) || (r == '.' && k+1 < len(rs) && unicode.IsDigit(rs[k+1])):
+			s := k
+			for k < len(rs) && (unicode.IsDigit(rs[k]) || rs[k] == '.') {
+				k++
+			}
+			if k < len(rs) && (rs[k] == 'e' || rs[k] == 'E') {
+				k++
+				if k < len(rs) && (rs[k] == '+' || rs[k] == '-') {
+					k++
+				}
+				for k < len(rs) && unicode.IsDigit(rs[k]) {
+					k++
+				}
+			}
+			toks = append(toks, token{tNum, string(rs[s:k]), s})
+		case unicode.IsLetter(r) || r == '_':
+			s := k
+			for k < len(rs) && (unicode.IsLetter(rs[k]) || unicode.IsDigit(rs[k]) || rs[k] == '_') {
+				k++
+			}
+			toks = append(toks, token{tIdent, string(rs[s:k]), s})
+		case r == '.' && k+1 < len(rs) && (rs[k+1] == '*' || rs[k+1] == '/'):
+			toks = append(toks, token{tOp, string(rs[k : k+2]), k})
+			k += 2
+		case r == '\\' || r == '\'' || r == '+' || r == '-' || r == '*' || r == '/' ||
+			r == '^' || r == '(' || r == ')' || r == '[' || r == ']' ||
+			r == ',' || r == ';' || r == '=':
+			toks = append(toks, token{tOp, string(r), k})
+			k++
+		default:
+			return nil, syntaxError(k, fmt.Sprintf("unexpected character %q", r))
+		}
+	}
+	return append(toks, token{tEOF, "", len(rs)}), nil
+}
+
+func syntaxError(pos int, msg string) error {
+	return linalg.NewError(linalg.ErrParameter, fmt.Sprintf("eval: syntax error at %d: %s", pos, msg))
+}
+
+type parser struct {
+	toks []token
+	k    int
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.k]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.k]
+	if t.kind != tEOF {
+		p.k++
+	}
+	return t
+}
+
+func (p *parser) isOp(ops ...string) bool {
+	t := p.peek()
+	if t.kind != tOp {
+		return false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.isOp(op) {
+		t := p.peek()
+		return syntaxError(t.pos, fmt.Sprintf("expected %q", op))
+	}
+	p.next()
+	return nil
+}
+
+// stmt := [ident '='] expr
+func (p *parser) statement() (string, node, error) {
+	name := ""
+	if t := p.peek(); t.kind == tIdent && p.toks[p.k+1].kind == tOp && p.toks[p.k+1].text == "=" {
+		name = t.text
+		p.k += 2
+	}
+	e, err := p.expr()
+	if err != nil {
+		return "", nil, err
+	}
+	if t := p.peek(); t.kind != tEOF {
+		return "", nil, syntaxError(t.pos, fmt.Sprintf("unexpected %q", t.text))
+	}
+	return name, e, nil
+}
+
+// expr := term (('+'|'-') term)*
+func (p *parser) expr() (node, error) {
+	x, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+", "-") {
+		op := p.next()
+		y, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{op.text, op.pos, x, y}
+	}
+	return x, nil
+}
+
+// term := unary (('*'|'/'|'\'|'.*'|'./') unary)*
+func (p *parser) term() (node, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*", "/", "\\", ".*", "./") {
+		op := p.next()
+		y, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{op.text, op.pos, x, y}
+	}
+	return x, nil
+}
+
+// unary := ('-'|'+') unary | power
+func (p *parser) unary() (node, error) {
+	if p.isOp("-", "+") {
+		op := p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if op.text == "+" {
+			return x, nil
+		}
+		return &negate{x}, nil
+	}
+	return p.power()
+}
+
+// power := postfix ['^' unary]
+func (p *parser) power() (node, error) {
+	x, err := p.postfix()
+	if err != nil {
+		return nil, err
+	}
+	if p.isOp("^") {
+		op := p.next()
+		y, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		x = &binary{op.text, op.pos, x, y}
+	}
+	return x, nil
+}
+
+// postfix := primary { "'" }
+func (p *parser) postfix() (node, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("'") {
+		p.next()
+		x = &transpose{x}
+	}
+	return x, nil
+}
+
+// primary := number | ident | ident '(' args ')' | '(' expr ')' | '[' rows ']'
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch {
+	case t.kind == tNum:
+		v, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, syntaxError(t.pos, fmt.Sprintf("invalid number %q", t.text))
+		}
+		return number(v), nil
+	case t.kind == tIdent:
+		if !p.isOp("(") {
+			return &ident{t.text, t.pos}, nil
+		}
+		p.next()
+		c := &call{name: t.text, pos: t.pos}
+		for !p.isOp(")") {
+			a, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			c.args = append(c.args, a)
+			if !p.isOp(",") {
+				break
+			}
+			p.next()
+		}
+		return c, p.expect(")")
+	case t.kind == tOp && t.text == "(":
+		x, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		return x, p.expect(")")
+	case t.kind == tOp && t.text == "[":
+		return p.literal(t.pos)
+	case t.kind == tEOF:
+		return nil, syntaxError(t.pos, "unexpected end of expression")
+	}
+	return nil, syntaxError(t.pos, fmt.Sprintf("unexpected %q", t.text))
+}
+
+// Matrix literal after '['; rows separated with ';', elements with ',' or space.
+func (p *parser) literal(pos int) (node, error) {
+	m := &literal{pos: pos}
+	row := []node{}
+	for {
+		switch {
+		case p.isOp("]"):
+			p.next()
+			if len(row) > 0 || len(m.rows) == 0 {
+				m.rows = append(m.rows, row)
+			}
+			return m, nil
+		case p.isOp(";"):
+			p.next()
+			m.rows = append(m.rows, row)
+			row = []node{}
+		case p.isOp(","):
+			p.next()
+		case p.peek().kind == tEOF:
+			return nil, syntaxError(p.peek().pos, "unterminated matrix literal")
+		default:
+			x, err := p.unary()
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, x)
+		}
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
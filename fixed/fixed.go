@@ -0,0 +1,327 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/fixed package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+// Deterministic fixed-point matrix arithmetic.
+//
+// Matrix elements are 32 bit signed integers interpreted as numbers
+// in Q format with a configurable number of fractional bits, ie. integer
+// value v represents real value v/2^frac. All arithmetic is done with integer
+// operations and saturates to the 32 bit range instead of wrapping, which
+// mimics typical DSP and FPGA datapaths. Results are bit-exact on every
+// platform.
+//
+// Products are accumulated at full 64 bit precision and rounded back to
+// frac fractional bits (round half up). Quotients are truncated toward zero.
+package fixed
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"strings"
+)
+
+// Largest supported number of fractional bits.
+const MaxFrac = 30
+
+// Column-major fixed-point matrix.
+type Matrix struct {
+	rows, cols int
+	frac       uint
+	elements   []int32
+}
+
+// Create a new rows*cols zero matrix with frac fractional bits.
+func Zeros(rows, cols int, frac uint) *Matrix {
+	if frac > MaxFrac {
+		frac = MaxFrac
+	}
+	return &Matrix{rows, cols, frac, make([]int32, rows*cols)}
+}
+
+// Convert float matrix to fixed-point matrix with frac fractional bits.
+// Values are rounded to nearest representable value and saturated.
+func FromFloat(A *matrix.FloatMatrix, frac uint) *Matrix {
+	rows, cols := A.Size()
+	F := Zeros(rows, cols, frac)
+	Ar := A.FloatArray()
+	lda := A.LeadingIndex()
+	scale := math.Ldexp(1.0, int(F.frac))
+	for j := 0; j < cols; j++ {
+		for i := 0; i < rows; i++ {
+			F.elements[j*rows+i] = saturateFloat(Ar[j*lda+i] * scale)
+		}
+	}
+	return F
+}
+
+// Convert fixed-point matrix to a new float matrix.
+func (A *Matrix) Float() *matrix.FloatMatrix {
+	B := matrix.FloatZeros(A.rows, A.cols)
+	Br := B.FloatArray()
+	scale := math.Ldexp(1.0, -int(A.frac))
+	for k, v := range A.elements {
+		Br[k] = float64(v) * scale
+	}
+	return B
+}
+
+// Return number of rows.
+func (A *Matrix) Rows() int {
+	return A.rows
+}
+
+// Return number of columns.
+func (A *Matrix) Cols() int {
+	return A.cols
+}
+
+// Return size of matrix as (rows, cols) tuple.
+func (A *Matrix) Size() (int, int) {
+	return A.rows, A.cols
+}
+
+// Return number of fractional bits.
+func (A *Matrix) Frac() uint {
+	return A.frac
+}
+
+// Return raw integer element array in column-major order.
+func (A *Matrix) IntArray() []int32 {
+	return A.elements
+}
+
+// Return raw integer value of element at (i, j).
+func (A *Matrix) GetAt(i, j int) int32 {
+	return A.elements[j*A.rows+i]
+}
+
+// Set raw integer value of element at (i, j).
+func (A *Matrix) SetAt(i, j int, val int32) {
+	A.elements[j*A.rows+i] = val
+}
+
+// Return a copy of A.
+func (A *Matrix) Copy() *Matrix {
+	B := Zeros(A.rows, A.cols, A.frac)
+	copy(B.elements, A.elements)
+	return B
+}
+
+func (A *Matrix) String() string {
+	s := make([]string, 0, A.rows)
+	scale := math.Ldexp(1.0, -int(A.frac))
+	for i := 0; i < A.rows; i++ {
+		row := make([]string, A.cols)
+		for j := 0; j < A.cols; j++ {
+			row[j] = fmt.Sprintf("%9.4f", float64(A.GetAt(i, j))*scale)
+		}
+		s = append(s, "["+strings.Join(row, " ")+"]")
+	}
+	return strings.Join(s, "\n")
+}
+
+// Return saturated sum A + B.
+func Plus(A, B *Matrix) (*Matrix, error) {
+	if err := checkSame(A, B); err != nil {
+		return nil, err
+	}
+	C := Zeros(A.rows, A.cols, A.frac)
+	for k := range C.elements {
+		C.elements[k] = saturate(int64(A.elements[k]) + int64(B.elements[k]))
+	}
+	return C, nil
+}
+
+// Return saturated difference A - B.
+func Minus(A, B *Matrix) (*Matrix, error) {
+	if err := checkSame(A, B); err != nil {
+		return nil, err
+	}
+	C := Zeros(A.rows, A.cols, A.frac)
+	for k := range C.elements {
+		C.elements[k] = saturate(int64(A.elements[k]) - int64(B.elements[k]))
+	}
+	return C, nil
+}
+
+// Return element-wise product of A and B.
+func Mul(A, B *Matrix) (*Matrix, error) {
+	if err := checkSame(A, B); err != nil {
+		return nil, err
+	}
+	C := Zeros(A.rows, A.cols, A.frac)
+	for k := range C.elements {
+		C.elements[k] = mul(A.elements[k], B.elements[k], A.frac)
+	}
+	return C, nil
+}
+
+// Return matrix product A*B. Inner products are accumulated at full
+// precision with saturation and rounded once at the end.
+func Times(A, B *Matrix) (*Matrix, error) {
+	if A.frac != B.frac {
+		return nil, linalg.NewError(linalg.ErrParameter, "Times: fractional bits do not match")
+	}
+	if A.cols != B.rows {
+		return nil, linalg.NewError(linalg.ErrShape, "Times: dimensions do not match")
+	}
+	C := Zeros(A.rows, B.cols, A.frac)
+	for j := 0; j < B.cols; j++ {
+		for i := 0; i < A.rows; i++ {
+			var acc int64
+			for k := 0; k < A.cols; k++ {
+				acc = saturatingAdd(acc, int64(A.GetAt(i, k))*int64(B.GetAt(k, j)))
+			}
+			C.SetAt(i, j, saturate(roundShift(acc, A.frac)))
+		}
+	}
+	return C, nil
+}
+
+// Solve A*X = B with Gaussian elimination and partial pivoting using fixed-point
+// operations only. A is n*n and B is n*nrhs with equal number of fractional
+// bits. Arguments are not modified; returns the solution X.
+func Solve(A, B *Matrix) (*Matrix, error) {
+	n := A.rows
+	if A.cols != n {
+		return nil, linalg.NewError(linalg.ErrShape, "Solve: A not square")
+	}
+	if B.rows != n {
+		return nil, linalg.NewError(linalg.ErrShape, "Solve: dimensions of A and B do not match")
+	}
+	if A.frac != B.frac {
+		return nil, linalg.NewError(linalg.ErrParameter, "Solve: fractional bits do not match")
+	}
+	frac := A.frac
+	LU := A.Copy()
+	X := B.Copy()
+	for k := 0; k < n; k++ {
+		// pivot is element with largest magnitude in column k
+		p := k
+		for i := k + 1; i < n; i++ {
+			if abs32(LU.GetAt(i, k)) > abs32(LU.GetAt(p, k)) {
+				p = i
+			}
+		}
+		if LU.GetAt(p, k) == 0 {
+			return nil, linalg.NewError(linalg.ErrSingular, fmt.Sprintf("Solve: zero pivot at column %d", k))
+		}
+		if p != k {
+			swapRows(LU, p, k)
+			swapRows(X, p, k)
+		}
+		pivot := LU.GetAt(k, k)
+		for i := k + 1; i < n; i++ {
+			f := div(LU.GetAt(i, k), pivot, frac)
+			LU.SetAt(i, k, 0)
+			for j := k + 1; j < n; j++ {
+				LU.SetAt(i, j, saturate(int64(LU.GetAt(i, j))-int64(mul(f, LU.GetAt(k, j), frac))))
+			}
+			for j := 0; j < X.cols; j++ {
+				X.SetAt(i, j, saturate(int64(X.GetAt(i, j))-int64(mul(f, X.GetAt(k, j), frac))))
+			}
+		}
+	}
+	// back substitution
+	for j := 0; j < X.cols; j++ {
+		for i := n - 1; i >= 0; i-- {
+			s := int64(X.GetAt(i, j))
+			for k := i + 1; k < n; k++ {
+				s -= int64(mul(LU.GetAt(i, k), X.GetAt(k, j), frac))
+			}
+			X.SetAt(i, j, div(saturate(s), LU.GetAt(i, i), frac))
+		}
+	}
+	return X, nil
+}
+
+func checkSame(A, B *Matrix) error {
+	if A.frac != B.frac {
+		return linalg.NewError(linalg.ErrParameter, "fractional bits do not match")
+	}
+	if A.rows != B.rows || A.cols != B.cols {
+		return linalg.NewError(linalg.ErrShape, "dimensions do not match")
+	}
+	return nil
+}
+
+func swapRows(A *Matrix, i, k int) {
+	for j := 0; j < A.cols; j++ {
+		t := A.GetAt(i, j)
+		A.SetAt(i, j, A.GetAt(k, j))
+		A.SetAt(k, j, t)
+	}
+}
+
+func abs32(v int32) int64 {
+	if v < 0 {
+		return -int64(v)
+	}
+	return int64(v)
+}
+
+// Saturate 64 bit value to 32 bit range.
+func saturate(v int64) int32 {
+	if v > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	if v < math.MinInt32 {
+		return math.MinInt32
+	}
+	return int32(v)
+}
+
+func saturateFloat(v float64) int32 {
+	v = math.Floor(v + 0.5)
+	if math.IsNaN(v) {
+		return 0
+	}
+	if v > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	if v < math.MinInt32 {
+		return math.MinInt32
+	}
+	return int32(v)
+}
+
+func saturatingAdd(a, b int64) int64 {
+	s := a + b
+	if a > 0 && b > 0 && s < 0 {
+		return math.MaxInt64
+	}
+	if a < 0 && b < 0 && s >= 0 {
+		return math.MinInt64
+	}
+	return s
+}
+
+// Shift right by frac bits rounding half up.
+func roundShift(v int64, frac uint) int64 {
+	if frac == 0 {
+		return v
+	}
+	half := int64(1) << (frac - 1)
+	if v > math.MaxInt64-half {
+		return v >> frac
+	}
+	return (v + half) >> frac
+}
+
+func mul(a, b int32, frac uint) int32 {
+	return saturate(roundShift(int64(a)*int64(b), frac))
+}
+
+// Fixed-point quotient a/b truncated toward zero; b must be nonzero.
+func div(a, b int32, frac uint) int32 {
+	return saturate((int64(a) << frac) / int64(b))
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
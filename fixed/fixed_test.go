@@ -0,0 +1,52 @@
+package fixed
+
+import (
+	"errors"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"testing"
+)
+
+func TestSaturate(t *testing.T) {
+	A := FromFloat(matrix.FloatVector([]float64{1000.0, -1000.0}), 24)
+	B, _ := Plus(A, A)
+	t.Logf("A+A:\n%v\n", B)
+	if B.GetAt(0, 0) != math.MaxInt32 || B.GetAt(1, 0) != math.MinInt32 {
+		t.Log("sum did not saturate\n")
+		t.Fail()
+	}
+}
+
+func TestSolve(t *testing.T) {
+	Af := matrix.FloatNew(3, 3, []float64{4, 1, 0, 1, 3, 1, 0, 1, 2})
+	bf := matrix.FloatVector([]float64{1, 2, 3})
+	X, err := Solve(FromFloat(Af, 16), FromFloat(bf, 16))
+	if err != nil {
+		t.Logf("Solve error: %s\n", err)
+		t.FailNow()
+	}
+	t.Logf("X:\n%v\n", X)
+	// exact solution of the system
+	ref := []float64{2.0 / 9.0, 1.0 / 9.0, 13.0 / 9.0}
+	for i, v := range X.Float().FloatArray() {
+		if math.Abs(v-ref[i]) > 1e-3 {
+			t.Logf("X[%d] = %f, expected %f\n", i, v, ref[i])
+			t.Fail()
+		}
+	}
+}
+
+func TestSolveSingular(t *testing.T) {
+	Af := matrix.FloatNew(2, 2, []float64{1, 2, 2, 4})
+	bf := matrix.FloatVector([]float64{1, 1})
+	_, err := Solve(FromFloat(Af, 16), FromFloat(bf, 16))
+	t.Logf("Solve error: %v\n", err)
+	if !errors.Is(err, linalg.ErrSingular) {
+		t.Fail()
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
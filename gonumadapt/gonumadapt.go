@@ -0,0 +1,130 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/gonumadapt package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+// Storage level adapters between column-major matrices of this project and
+// the row-major blas64 types of gonum.org/v1/gonum.
+//
+// A column-major m by n matrix and a row-major n by m matrix with the same
+// leading dimension are the same array, so a general matrix A is presented
+// to gonum as its transpose without copying: GeneralT(A) is A^T. Code that
+// calls gonum routines on the view must swap transposes accordingly,
+// eg. computing C = A*B with gonum as C^T = B^T*A^T. Symmetric and
+// triangular matrices need no transpose; only the triangle flag changes
+// meaning, which the adapters handle.
+//
+// All views share storage with the original matrix.
+//
+// Only storage is adapted. The package does not implement gonum's
+// blas.Float64 or lapack.Float64 interfaces on the BLAS and LAPACK
+// backends of this project; gonum routines called on the views run on
+// gonum's own implementation.
+package gonumadapt
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"gonum.org/v1/gonum/blas"
+	"gonum.org/v1/gonum/blas/blas64"
+)
+
+// Return row-major view of A^T.
+func GeneralT(A *matrix.FloatMatrix) blas64.General {
+	return blas64.General{
+		Rows:   A.Cols(),
+		Cols:   A.Rows(),
+		Data:   A.FloatArray(),
+		Stride: A.LeadingIndex(),
+	}
+}
+
+// Return column-major matrix G^T. The matrix is created with matrix.FloatNew
+// on the elements of G; G must be contiguous, ie. G.Stride == G.Cols.
+func FromGeneralT(G blas64.General) (*matrix.FloatMatrix, error) {
+	if G.Stride != G.Cols {
+		return nil, linalg.NewError(linalg.ErrShape, "FromGeneralT: matrix not contiguous")
+	}
+	return matrix.FloatNew(G.Cols, G.Rows, G.Data[:G.Rows*G.Cols]), nil
+}
+
+// Column-major lower triangle is the row-major upper triangle of the same array.
+func flipUplo(uplo int) blas.Uplo {
+	if uplo == linalg.PLower {
+		return blas.Upper
+	}
+	return blas.Lower
+}
+
+func checkSquare(name string, A *matrix.FloatMatrix, uplo int) error {
+	if A.Rows() != A.Cols() {
+		return linalg.NewError(linalg.ErrShape, name+": A not square")
+	}
+	if uplo != linalg.PLower && uplo != linalg.PUpper {
+		return linalg.NewError(linalg.ErrParameter, name+": illegal uplo")
+	}
+	return nil
+}
+
+// Return view of symmetric matrix A whose triangle uplo (linalg.PLower or
+// linalg.PUpper) is referenced.
+func Symmetric(A *matrix.FloatMatrix, uplo int) (blas64.Symmetric, error) {
+	if err := checkSquare("Symmetric", A, uplo); err != nil {
+		return blas64.Symmetric{}, err
+	}
+	return blas64.Symmetric{
+		Uplo:   flipUplo(uplo),
+		N:      A.Rows(),
+		Data:   A.FloatArray(),
+		Stride: A.LeadingIndex(),
+	}, nil
+}
+
+// Return view of triangular matrix A^T. Triangle uplo of A becomes the
+// opposite triangle of the view; diag is linalg.PUnit or linalg.PNonUnit.
+func TriangularT(A *matrix.FloatMatrix, uplo, diag int) (blas64.Triangular, error) {
+	if err := checkSquare("TriangularT", A, uplo); err != nil {
+		return blas64.Triangular{}, err
+	}
+	d := blas.NonUnit
+	if diag == linalg.PUnit {
+		d = blas.Unit
+	}
+	return blas64.Triangular{
+		Uplo:   flipUplo(uplo),
+		Diag:   d,
+		N:      A.Rows(),
+		Data:   A.FloatArray(),
+		Stride: A.LeadingIndex(),
+	}, nil
+}
+
+// Return view of column vector X or row vector X. Vector layout is the same
+// in both libraries.
+func Vector(X *matrix.FloatMatrix) (blas64.Vector, error) {
+	switch {
+	case X.Cols() == 1:
+		return blas64.Vector{N: X.Rows(), Data: X.FloatArray(), Inc: 1}, nil
+	case X.Rows() == 1:
+		return blas64.Vector{N: X.Cols(), Data: X.FloatArray(), Inc: X.LeadingIndex()}, nil
+	}
+	return blas64.Vector{}, linalg.NewError(linalg.ErrShape, "Vector: argument not a vector")
+}
+
+// Return column vector with elements of v. Strided vectors are copied,
+// contiguous vectors are passed to matrix.FloatVector as is.
+func FromVector(v blas64.Vector) *matrix.FloatMatrix {
+	if v.Inc == 1 {
+		return matrix.FloatVector(v.Data[:v.N])
+	}
+	x := make([]float64, v.N)
+	for i := range x {
+		x[i] = v.Data[i*v.Inc]
+	}
+	return matrix.FloatVector(x)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
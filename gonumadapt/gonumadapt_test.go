@@ -0,0 +1,31 @@
+package gonumadapt
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"gonum.org/v1/gonum/blas"
+	"testing"
+)
+
+func TestGeneralT(t *testing.T) {
+	A := matrix.FloatNew(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	G := GeneralT(A)
+	// row-major element (j, i) of the view is A[i, j]
+	if G.Rows != 3 || G.Cols != 2 || G.Data[2*G.Stride+1] != A.GetAt(1, 2) {
+		t.Logf("view: %+v\n", G)
+		t.Fail()
+	}
+	B, err := FromGeneralT(G)
+	if err != nil || !B.Equal(A) {
+		t.Logf("round trip: %v, %v\n", B, err)
+		t.Fail()
+	}
+	S, _ := Symmetric(matrix.FloatZeros(2, 2), linalg.PLower)
+	if S.Uplo != blas.Upper {
+		t.Fail()
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
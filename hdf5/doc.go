@@ -0,0 +1,38 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/hdf5 package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+/*
+Package hdf5 reads and writes two-dimensional float and complex datasets of
+HDF5 files as matrices.
+
+The package uses cgo and the HDF5 C library (version 1.10 or later) and is
+built only with the build tag hdf5:
+
+	go build -tags hdf5
+
+Datasets are stored in HDF5's row-major order and converted to and from the
+column-major matrix layout. Any integer or floating point dataset is read as
+a float matrix; complex matrices use the compound type with double fields
+"r" and "i" written by h5py and most other tools. One-dimensional datasets
+are read as column vectors.
+
+ReadRegion reads a rectangular block of a dataset without loading the rest,
+which together with chunked storage (option chunk of Write) allows
+processing of arrays larger than memory:
+
+	f, err := hdf5.Open("data.h5")
+	defer f.Close()
+	rows, cols, err := f.Dims("/results/X")
+	for r := 0; r < rows; r += 1000 {
+		B, err := f.ReadRegion("/results/X", r, 0, min(1000, rows-r), cols)
+		...
+	}
+*/
+package hdf5
+
+// Local Variables:
+// tab-width: 4
+// End:
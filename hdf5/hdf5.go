@@ -0,0 +1,297 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/hdf5 package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+//go:build hdf5
+// +build hdf5
+
+package hdf5
+
+// #cgo LDFLAGS: -lhdf5
+// #include <stdlib.h>
+// #include <hdf5.h>
+//
+// // Predefined HDF5 identifiers are macros over library globals.
+// static hid_t native_double(void) { return H5T_NATIVE_DOUBLE; }
+// static hid_t dataset_create(void) { return H5P_DATASET_CREATE; }
+// static hid_t link_create(void) { return H5P_LINK_CREATE; }
+// static void silence(void) { H5Eset_auto2(H5E_DEFAULT, NULL, NULL); }
+//
+// // Complex compound type compatible with h5py.
+// static hid_t complex_type(void) {
+//     hid_t t = H5Tcreate(H5T_COMPOUND, 2*sizeof(double));
+//     H5Tinsert(t, "r", 0, H5T_NATIVE_DOUBLE);
+//     H5Tinsert(t, "i", sizeof(double), H5T_NATIVE_DOUBLE);
+//     return t;
+// }
+import "C"
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"runtime"
+	"unsafe"
+)
+
+func init() {
+	// errors are reported through return values
+	C.silence()
+}
+
+// Open HDF5 file.
+type File struct {
+	id C.hid_t
+}
+
+func h5error(op, name string) error {
+	return linalg.NewError(linalg.ErrParameter, fmt.Sprintf("hdf5: %s %s failed", op, name))
+}
+
+// Open existing file for reading.
+func Open(name string) (*File, error) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	id := C.H5Fopen(cname, C.H5F_ACC_RDONLY, C.H5P_DEFAULT)
+	if id < 0 {
+		return nil, h5error("open", name)
+	}
+	return newFile(id), nil
+}
+
+// Create new file, truncating existing one, for reading and writing.
+func Create(name string) (*File, error) {
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	id := C.H5Fcreate(cname, C.H5F_ACC_TRUNC, C.H5P_DEFAULT, C.H5P_DEFAULT)
+	if id < 0 {
+		return nil, h5error("create", name)
+	}
+	return newFile(id), nil
+}
+
+func newFile(id C.hid_t) *File {
+	f := &File{id}
+	runtime.SetFinalizer(f, (*File).Close)
+	return f
+}
+
+// Close file. Further use of f is an error.
+func (f *File) Close() error {
+	if f.id < 0 {
+		return nil
+	}
+	status := C.H5Fclose(f.id)
+	f.id = -1
+	runtime.SetFinalizer(f, nil)
+	if status < 0 {
+		return h5error("close", "file")
+	}
+	return nil
+}
+
+// Open dataset and return its id and dataspace with dimensions.
+func (f *File) open(name string) (ds, space C.hid_t, rows, cols int, err error) {
+	if f.id < 0 {
+		err = linalg.NewError(linalg.ErrParameter, "hdf5: file closed")
+		return
+	}
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	ds = C.H5Dopen2(f.id, cname, C.H5P_DEFAULT)
+	if ds < 0 {
+		err = h5error("open dataset", name)
+		return
+	}
+	space = C.H5Dget_space(ds)
+	var dims [2]C.hsize_t
+	ndims := C.H5Sget_simple_extent_ndims(space)
+	if ndims < 1 || ndims > 2 {
+		C.H5Sclose(space)
+		C.H5Dclose(ds)
+		err = linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("hdf5: dataset %s has %d dimensions", name, int(ndims)))
+		return
+	}
+	C.H5Sget_simple_extent_dims(space, &dims[0], nil)
+	rows, cols = int(dims[0]), 1
+	if ndims == 2 {
+		cols = int(dims[1])
+	}
+	return
+}
+
+// Return dimensions of dataset. One-dimensional datasets have one column.
+func (f *File) Dims(name string) (rows, cols int, err error) {
+	ds, space, rows, cols, err := f.open(name)
+	if err != nil {
+		return 0, 0, err
+	}
+	C.H5Sclose(space)
+	C.H5Dclose(ds)
+	return rows, cols, nil
+}
+
+// Read dataset as float or complex matrix.
+func (f *File) Read(name string) (matrix.Matrix, error) {
+	return f.ReadRegion(name, 0, 0, -1, -1)
+}
+
+// Read block of rows by cols elements starting at (row, col) of dataset.
+// Negative rows or cols extend the block to the end of the dataset.
+func (f *File) ReadRegion(name string, row, col, rows, cols int) (matrix.Matrix, error) {
+	ds, space, m, n, err := f.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer C.H5Dclose(ds)
+	defer C.H5Sclose(space)
+	if rows < 0 {
+		rows = m - row
+	}
+	if cols < 0 {
+		cols = n - col
+	}
+	if row < 0 || col < 0 || rows < 0 || cols < 0 || row+rows > m || col+cols > n {
+		return nil, linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("hdf5: region (%d,%d)+(%d,%d) outside %d by %d dataset", row, col, rows, cols, m, n))
+	}
+	ndims := C.H5Sget_simple_extent_ndims(space)
+	start := [2]C.hsize_t{C.hsize_t(row), C.hsize_t(col)}
+	count := [2]C.hsize_t{C.hsize_t(rows), C.hsize_t(cols)}
+	if ndims == 1 {
+		count[0] = C.hsize_t(rows)
+	}
+	if rows*cols > 0 {
+		if C.H5Sselect_hyperslab(space, C.H5S_SELECT_SET, &start[0], nil, &count[0], nil) < 0 {
+			return nil, h5error("select region of", name)
+		}
+	}
+	mem := C.H5Screate_simple(ndims, &count[0], nil)
+	defer C.H5Sclose(mem)
+
+	dtype := C.H5Dget_type(ds)
+	cplx := C.H5Tget_class(dtype) == C.H5T_COMPOUND
+	C.H5Tclose(dtype)
+	// buf holds the block in row-major order
+	if cplx {
+		ctype := C.complex_type()
+		defer C.H5Tclose(ctype)
+		buf := make([]complex128, rows*cols)
+		if len(buf) > 0 && C.H5Dread(ds, ctype, mem, space, C.H5P_DEFAULT, unsafe.Pointer(&buf[0])) < 0 {
+			return nil, h5error("read", name)
+		}
+		A := matrix.ComplexZeros(rows, cols)
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				A.SetAt(i, j, buf[i*cols+j])
+			}
+		}
+		return A, nil
+	}
+	buf := make([]float64, rows*cols)
+	if len(buf) > 0 && C.H5Dread(ds, C.native_double(), mem, space, C.H5P_DEFAULT, unsafe.Pointer(&buf[0])) < 0 {
+		return nil, h5error("read", name)
+	}
+	A := matrix.FloatZeros(rows, cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			A.SetAt(i, j, buf[i*cols+j])
+		}
+	}
+	return A, nil
+}
+
+/*
+ Write matrix to new two-dimensional dataset. Intermediate groups in the
+ dataset name are created as needed.
+
+ OPTIONS
+  chunk     int; store the dataset in chunks of chunk rows, which makes
+            ReadRegion of row blocks efficient. Default 0, contiguous.
+  deflate   int; gzip compression level 1-9 of chunked datasets.
+            Default 0, no compression.
+*/
+func (f *File) Write(name string, A matrix.Matrix, opts ...linalg.Option) error {
+	if f.id < 0 {
+		return linalg.NewError(linalg.ErrParameter, "hdf5: file closed")
+	}
+	rows, cols := A.Rows(), A.Cols()
+	chunk := linalg.GetIntOpt("chunk", 0, opts...)
+	deflate := linalg.GetIntOpt("deflate", 0, opts...)
+	if chunk < 0 || deflate < 0 || deflate > 9 || (deflate > 0 && chunk == 0) {
+		return linalg.NewError(linalg.ErrParameter, "hdf5: invalid chunk or deflate option")
+	}
+	var data unsafe.Pointer
+	var dtype C.hid_t
+	switch A := A.(type) {
+	case *matrix.FloatMatrix:
+		buf := make([]float64, rows*cols)
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				buf[i*cols+j] = A.GetAt(i, j)
+			}
+		}
+		if len(buf) > 0 {
+			data = unsafe.Pointer(&buf[0])
+		}
+		dtype = C.native_double()
+		defer runtime.KeepAlive(buf)
+	case *matrix.ComplexMatrix:
+		buf := make([]complex128, rows*cols)
+		for i := 0; i < rows; i++ {
+			for j := 0; j < cols; j++ {
+				buf[i*cols+j] = A.GetAt(i, j)
+			}
+		}
+		if len(buf) > 0 {
+			data = unsafe.Pointer(&buf[0])
+		}
+		dtype = C.complex_type()
+		defer C.H5Tclose(dtype)
+		defer runtime.KeepAlive(buf)
+	default:
+		return linalg.NewError(linalg.ErrType, "hdf5: unknown matrix type")
+	}
+
+	dims := [2]C.hsize_t{C.hsize_t(rows), C.hsize_t(cols)}
+	space := C.H5Screate_simple(2, &dims[0], nil)
+	defer C.H5Sclose(space)
+	dcpl := C.H5Pcreate(C.dataset_create())
+	defer C.H5Pclose(dcpl)
+	if chunk > 0 && rows > 0 && cols > 0 {
+		cdims := [2]C.hsize_t{C.hsize_t(min(chunk, rows)), C.hsize_t(cols)}
+		C.H5Pset_chunk(dcpl, 2, &cdims[0])
+		if deflate > 0 {
+			C.H5Pset_deflate(dcpl, C.uint(deflate))
+		}
+	}
+	lcpl := C.H5Pcreate(C.link_create())
+	defer C.H5Pclose(lcpl)
+	C.H5Pset_create_intermediate_group(lcpl, 1)
+
+	cname := C.CString(name)
+	defer C.free(unsafe.Pointer(cname))
+	ds := C.H5Dcreate2(f.id, cname, dtype, space, lcpl, dcpl, C.H5P_DEFAULT)
+	if ds < 0 {
+		return h5error("create dataset", name)
+	}
+	defer C.H5Dclose(ds)
+	if data != nil && C.H5Dwrite(ds, dtype, C.H5S_ALL, C.H5S_ALL, C.H5P_DEFAULT, data) < 0 {
+		return h5error("write", name)
+	}
+	return nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
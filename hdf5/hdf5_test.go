@@ -0,0 +1,56 @@
+//go:build hdf5
+// +build hdf5
+
+package hdf5
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadWrite(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "test.h5")
+	f, err := Create(name)
+	if err != nil {
+		t.Fatalf("Create: %v\n", err)
+	}
+	A := matrix.FloatNew(3, 2, []float64{1, 2, 3, 4, 5, 6})
+	Z := matrix.ComplexNew(1, 2, []complex128{1 + 2i, -1i})
+	if err = f.Write("/g/A", A, linalg.IntOpt("chunk", 2), linalg.IntOpt("deflate", 4)); err != nil {
+		t.Fatalf("Write A: %v\n", err)
+	}
+	if err = f.Write("Z", Z); err != nil {
+		t.Fatalf("Write Z: %v\n", err)
+	}
+	f.Close()
+
+	if f, err = Open(name); err != nil {
+		t.Fatalf("Open: %v\n", err)
+	}
+	defer f.Close()
+	if m, n, err := f.Dims("/g/A"); err != nil || m != 3 || n != 2 {
+		t.Logf("Dims: %d %d %v\n", m, n, err)
+		t.Fail()
+	}
+	B, err := f.Read("/g/A")
+	if err != nil || !A.Equal(B.(*matrix.FloatMatrix)) {
+		t.Logf("Read A: %v\n%v\n", err, B)
+		t.Fail()
+	}
+	R, err := f.ReadRegion("/g/A", 1, 1, 2, -1)
+	if err != nil || R.Rows() != 2 || R.Cols() != 1 || R.(*matrix.FloatMatrix).GetAt(1, 0) != 6 {
+		t.Logf("ReadRegion: %v\n%v\n", err, R)
+		t.Fail()
+	}
+	W, err := f.Read("Z")
+	if err != nil || W.(*matrix.ComplexMatrix).GetAt(0, 0) != 1+2i {
+		t.Logf("Read Z: %v\n%v\n", err, W)
+		t.Fail()
+	}
+	if _, err = f.ReadRegion("/g/A", 2, 0, 2, 2); err == nil {
+		t.Logf("region outside dataset accepted\n")
+		t.Fail()
+	}
+}
@@ -8,6 +8,7 @@ package linalg
 
 import (
 	"fmt"
+	"math"
 	"strings"
 )
 
@@ -57,8 +58,41 @@ type IndexOpts struct {
 	OffsetVt int // default: 0
 }
 
+// Largest magnitude of an index option. The native libraries take 32 bit
+// integers. GetIndexOpts clamps larger values to MaxIndex+1 or -MaxIndex-1
+// so that index arithmetic on them cannot overflow, and CheckIndexOpts
+// rejects them.
+const MaxIndex = math.MaxInt32
+
+// Clamp index option value to [-MaxIndex-1, MaxIndex+1].
+func clampIndex(v int) int {
+	if v > MaxIndex {
+		return MaxIndex + 1
+	}
+	if v < -MaxIndex {
+		return -MaxIndex - 1
+	}
+	return v
+}
+
+// Return error wrapping ErrParameter if an index in ind is out of the range
+// of the native libraries, see MaxIndex.
+func CheckIndexOpts(ind *IndexOpts) error {
+	vals := []int{ind.N, ind.Nx, ind.Ny, ind.M, ind.Ma, ind.Mb, ind.LDa, ind.LDb, ind.LDc,
+		ind.IncX, ind.IncY, ind.OffsetX, ind.OffsetY, ind.OffsetA, ind.OffsetB, ind.OffsetC,
+		ind.K, ind.Ku, ind.Kl, ind.Nrhs, ind.OffsetD, ind.OffsetDL, ind.OffsetDU, ind.LDw,
+		ind.LDz, ind.OffsetW, ind.OffsetZ, ind.LDu, ind.LDvt, ind.LDt, ind.OffsetS,
+		ind.OffsetU, ind.OffsetVt}
+	for _, v := range vals {
+		if v > MaxIndex || v < -MaxIndex {
+			return NewError(ErrParameter, fmt.Sprintf("index option %d out of range", v))
+		}
+	}
+	return nil
+}
+
 // Parse option list and return index structure with relevant fields set and
-// other fields with default values.
+// other fields with default values. Values are clamped, see MaxIndex.
 func GetIndexOpts(opts ...Option) *IndexOpts {
 	is := &IndexOpts{
 		-1, -1, -1, // n, nX, nY
@@ -80,88 +114,89 @@ loop:
 		if _, ok := o.(*IOpt); !ok {
 			continue loop
 		}
+		v := clampIndex(o.Int())
 		switch {
 		case strings.EqualFold(o.Name(), "inc"):
-			is.IncX = o.Int()
-			is.IncY = o.Int()
+			is.IncX = v
+			is.IncY = v
 		case strings.EqualFold(o.Name(), "incx"):
-			is.IncX = o.Int()
+			is.IncX = v
 		case strings.EqualFold(o.Name(), "incy"):
-			is.IncY = o.Int()
+			is.IncY = v
 		case strings.EqualFold(o.Name(), "lda"):
-			is.LDa = o.Int()
+			is.LDa = v
 		case strings.EqualFold(o.Name(), "ldb"):
-			is.LDb = o.Int()
+			is.LDb = v
 		case strings.EqualFold(o.Name(), "ldc"):
-			is.LDc = o.Int()
+			is.LDc = v
 		case strings.EqualFold(o.Name(), "ldw"):
-			is.LDw = o.Int()
+			is.LDw = v
 		case strings.EqualFold(o.Name(), "ldz"):
-			is.LDz = o.Int()
+			is.LDz = v
 		case strings.EqualFold(o.Name(), "ldu"):
-			is.LDu = o.Int()
+			is.LDu = v
 		case strings.EqualFold(o.Name(), "ldvt"):
-			is.LDvt = o.Int()
+			is.LDvt = v
 		case strings.EqualFold(o.Name(), "ldt"):
-			is.LDt = o.Int()
+			is.LDt = v
 		case strings.EqualFold(o.Name(), "offset"):
-			is.OffsetX = o.Int()
-			is.OffsetY = o.Int()
-			is.OffsetA = o.Int()
-			is.OffsetB = o.Int()
-			is.OffsetC = o.Int()
+			is.OffsetX = v
+			is.OffsetY = v
+			is.OffsetA = v
+			is.OffsetB = v
+			is.OffsetC = v
 		case strings.EqualFold(o.Name(), "offsetx"):
-			is.OffsetX = o.Int()
+			is.OffsetX = v
 		case strings.EqualFold(o.Name(), "offsety"):
-			is.OffsetY = o.Int()
+			is.OffsetY = v
 		case strings.EqualFold(o.Name(), "offseta"):
-			is.OffsetA = o.Int()
+			is.OffsetA = v
 		case strings.EqualFold(o.Name(), "offsetb"):
-			is.OffsetB = o.Int()
+			is.OffsetB = v
 		case strings.EqualFold(o.Name(), "offsetc"):
-			is.OffsetC = o.Int()
+			is.OffsetC = v
 		case strings.EqualFold(o.Name(), "offsetw"):
-			is.OffsetW = o.Int()
+			is.OffsetW = v
 		case strings.EqualFold(o.Name(), "offsetd"):
-			is.OffsetD = o.Int()
+			is.OffsetD = v
 		case strings.EqualFold(o.Name(), "offsetdl"):
-			is.OffsetDL = o.Int()
+			is.OffsetDL = v
 		case strings.EqualFold(o.Name(), "offsetdu"):
-			is.OffsetDU = o.Int()
+			is.OffsetDU = v
 		case strings.EqualFold(o.Name(), "offsetdw"):
-			is.OffsetW = o.Int()
+			is.OffsetW = v
 		case strings.EqualFold(o.Name(), "offsetdz"):
-			is.OffsetZ = o.Int()
+			is.OffsetZ = v
 		case strings.EqualFold(o.Name(), "offsetu"):
-			is.OffsetU = o.Int()
+			is.OffsetU = v
 		case strings.EqualFold(o.Name(), "offsets"):
-			is.OffsetS = o.Int()
+			is.OffsetS = v
 		case strings.EqualFold(o.Name(), "offsetvt"):
-			is.OffsetVt = o.Int()
+			is.OffsetVt = v
 		case strings.EqualFold(o.Name(), "n"):
-			is.N = o.Int()
-			is.Nx = o.Int()
-			is.Ny = o.Int()
+			is.N = v
+			is.Nx = v
+			is.Ny = v
 		case strings.EqualFold(o.Name(), "nx"):
-			is.Nx = o.Int()
+			is.Nx = v
 		case strings.EqualFold(o.Name(), "ny"):
-			is.Ny = o.Int()
+			is.Ny = v
 		case strings.EqualFold(o.Name(), "m"):
-			is.M = o.Int()
-			is.Ma = o.Int()
-			is.Mb = o.Int()
+			is.M = v
+			is.Ma = v
+			is.Mb = v
 		case strings.EqualFold(o.Name(), "ma"):
-			is.Ma = o.Int()
+			is.Ma = v
 		case strings.EqualFold(o.Name(), "mb"):
-			is.Mb = o.Int()
+			is.Mb = v
 		case strings.EqualFold(o.Name(), "k"):
-			is.K = o.Int()
+			is.K = v
 		case strings.EqualFold(o.Name(), "kl"):
-			is.Kl = o.Int()
+			is.Kl = v
 		case strings.EqualFold(o.Name(), "ku"):
-			is.Ku = o.Int()
+			is.Ku = v
 		case strings.EqualFold(o.Name(), "nrhs"):
-			is.Nrhs = o.Int()
+			is.Nrhs = v
 		}
 	}
 	return is
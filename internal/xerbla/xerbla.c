@@ -0,0 +1,36 @@
+/*
+ * Replacement for the BLAS/LAPACK error handler XERBLA.
+ *
+ * The reference implementation prints a message and stops the program when
+ * a routine is called with an illegal argument. This version records the
+ * routine name and argument number in thread local storage and returns, so
+ * that the caller can report the failure as an error.
+ */
+
+#include <stddef.h>
+#include <string.h>
+#include "xerbla.h"
+
+static __thread int xerbla_info = 0;
+static __thread char xerbla_name[XERBLA_NAMELEN+1];
+
+void xerbla_(char *srname, int *info, size_t len)
+{
+    if (len > XERBLA_NAMELEN)
+        len = XERBLA_NAMELEN;
+    memcpy(xerbla_name, srname, len);
+    xerbla_name[len] = '\0';
+    xerbla_info = *info;
+}
+
+void xerbla_clear(void)
+{
+    xerbla_info = 0;
+    xerbla_name[0] = '\0';
+}
+
+int xerbla_last(char *name)
+{
+    strcpy(name, xerbla_name);
+    return xerbla_info;
+}
@@ -0,0 +1,39 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+// Package xerbla overrides the BLAS/LAPACK error handler XERBLA so that
+// illegal argument errors in the native library do not terminate the
+// program. It is shared by blas and lapack packages; the symbol may be
+// defined only once in a binary.
+//
+// State is kept per OS thread. Callers must lock the goroutine to its thread
+// with runtime.LockOSThread between Clear and Last.
+package xerbla
+
+// #include "xerbla.h"
+import "C"
+import "strings"
+
+// Forget any error recorded on current thread.
+func Clear() {
+	C.xerbla_clear()
+}
+
+// Return name of the routine and number of the illegal argument of the last
+// XERBLA call on current thread since Clear. Info is zero if no error was
+// reported.
+func Last() (name string, info int) {
+	var buf [C.XERBLA_NAMELEN + 1]C.char
+	info = int(C.xerbla_last(&buf[0]))
+	if info != 0 {
+		name = strings.TrimSpace(C.GoString(&buf[0]))
+	}
+	return
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,12 @@
+#ifndef XERBLA_H
+#define XERBLA_H
+
+#include <stddef.h>
+
+#define XERBLA_NAMELEN 32
+
+extern void xerbla_(char *srname, int *info, size_t len);
+extern void xerbla_clear(void);
+extern int xerbla_last(char *name);
+
+#endif
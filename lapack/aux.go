@@ -10,7 +10,6 @@ import (
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
 	//"errors"
-	"fmt"
 )
 
 /*
@@ -121,7 +120,8 @@ func LarfgFloat(alpha, X, tau *matrix.FloatMatrix, opts ...linalg.Option) {
 
 /*
  */
-func OrgqrFloat(A, tau *matrix.FloatMatrix, opts ...linalg.Option) error {
+func OrgqrFloat(A, tau *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	defer guard("OrgqrFloat", &err)()
 	ind := linalg.GetIndexOpts(opts...)
 	if ind.M < 0 {
 		ind.M = A.Rows()
@@ -139,7 +139,7 @@ func OrgqrFloat(A, tau *matrix.FloatMatrix, opts ...linalg.Option) error {
 	tr := tau.FloatArray()
 	info := dorgqr(ind.M, ind.N, ind.K, Ar, ind.LDa, tr)
 	if info != 0 {
-		return onError(fmt.Sprintf("Orgqr lapack error: %d", info))
+		return onLapackError("Orgqr", info, linalg.ErrParameter)
 	}
 	return nil
 }
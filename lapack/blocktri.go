@@ -0,0 +1,163 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/blas"
+	"github.com/nvcook42/matrix"
+)
+
+// Block LU factorization of a block tridiagonal matrix by the block Thomas
+// algorithm, reusable for any number of solves; see NewBlockTridiag.
+type BlockTridiag struct {
+	// LU factors of the Schur complements S[k] = D[k] - L[k-1]*G[k-1].
+	S []*Factor
+	// G[k] = inv(S[k])*U[k].
+	G []*matrix.FloatMatrix
+	// Subdiagonal blocks.
+	L []*matrix.FloatMatrix
+}
+
+/*
+ Block LU factorization of a block tridiagonal matrix.
+
+ PURPOSE
+ Factors the matrix
+
+      [ D[0] U[0]                   ]
+      [ L[0] D[1] U[1]              ]
+  A = [      L[1] D[2] ...          ]
+      [           ...  ...   U[N-2] ]
+      [                L[N-2] D[N-1]]
+
+ with square diagonal blocks D[k] of order n[k], L[k] n[k+1] by n[k] and
+ U[k] n[k] by n[k+1], by the block Thomas algorithm
+
+  S[0] = D[0],  G[k] = inv(S[k])*U[k],  S[k+1] = D[k+1] - L[k]*G[k].
+
+ Each Schur complement S[k] is LU factored with partial pivoting and the
+ updates are done with Getrs and Gemm, so the cost is dominated by level 3
+ kernels on blocks. There is no pivoting between blocks; the factorization
+ is stable for block diagonally dominant or symmetric positive definite A
+ and may fail with ErrSingular otherwise even when A is nonsingular.
+ The blocks are not changed.
+
+ ARGUMENTS
+  L         N-1 subdiagonal blocks
+  D         N diagonal blocks
+  U         N-1 superdiagonal blocks
+*/
+func NewBlockTridiag(L, D, U []*matrix.FloatMatrix) (*BlockTridiag, error) {
+	N := len(D)
+	if len(L) != max(0, N-1) || len(U) != max(0, N-1) {
+		return nil, onError(linalg.ErrShape,
+			fmt.Sprintf("BlockTridiag: %d diagonal blocks but %d and %d off-diagonal", N, len(L), len(U)))
+	}
+	for k := 0; k < N; k++ {
+		n := D[k].Rows()
+		if D[k].Cols() != n {
+			return nil, onError(linalg.ErrShape, fmt.Sprintf("BlockTridiag: D[%d] not square", k))
+		}
+		if k < N-1 {
+			n1 := D[k+1].Rows()
+			if L[k].Rows() != n1 || L[k].Cols() != n {
+				return nil, onError(linalg.ErrShape, fmt.Sprintf("BlockTridiag: L[%d] not %d by %d", k, n1, n))
+			}
+			if U[k].Rows() != n || U[k].Cols() != n1 {
+				return nil, onError(linalg.ErrShape, fmt.Sprintf("BlockTridiag: U[%d] not %d by %d", k, n, n1))
+			}
+		}
+	}
+	F := &BlockTridiag{make([]*Factor, N), make([]*matrix.FloatMatrix, max(0, N-1)), L}
+	if N == 0 {
+		return F, nil
+	}
+	S := D[0]
+	for k := 0; k < N; k++ {
+		f, err := NewLU(S)
+		if err != nil {
+			if errors.Is(err, linalg.ErrSingular) {
+				return nil, onError(linalg.ErrSingular,
+					fmt.Sprintf("BlockTridiag: Schur complement %d singular", k))
+			}
+			return nil, err
+		}
+		F.S[k] = f
+		if k == N-1 {
+			break
+		}
+		F.G[k] = U[k].Copy()
+		if err = f.Solve(F.G[k]); err != nil {
+			return nil, err
+		}
+		S = D[k+1].Copy()
+		err = blas.Gemm(L[k], F.G[k], S, matrix.FScalar(-1.0), matrix.FScalar(1.0))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return F, nil
+}
+
+// Solve A*X = B for B with the rows of the blocks stacked, sum of n[k]
+// rows; on exit B is overwritten with X.
+func (F *BlockTridiag) Solve(B *matrix.FloatMatrix) error {
+	N, nrhs := len(F.S), B.Cols()
+	rows := make([]int, N+1)
+	for k, f := range F.S {
+		rows[k+1] = rows[k] + f.F.Rows()
+	}
+	if B.Rows() != rows[N] {
+		return onError(linalg.ErrShape,
+			fmt.Sprintf("BlockTridiag: B has %d rows, want %d", B.Rows(), rows[N]))
+	}
+	if N == 0 || nrhs == 0 {
+		return nil
+	}
+	Y := make([]*matrix.FloatMatrix, N)
+	// forward: Y[k] = inv(S[k])*(B[k] - L[k-1]*Y[k-1])
+	for k := 0; k < N; k++ {
+		Y[k] = B.GetSubMatrix(rows[k], 0, rows[k+1]-rows[k], nrhs)
+		if k > 0 {
+			err := blas.Gemm(F.L[k-1], Y[k-1], Y[k], matrix.FScalar(-1.0), matrix.FScalar(1.0))
+			if err != nil {
+				return err
+			}
+		}
+		if err := F.S[k].Solve(Y[k]); err != nil {
+			return err
+		}
+	}
+	// backward: X[k] = Y[k] - G[k]*X[k+1]
+	for k := N - 2; k >= 0; k-- {
+		err := blas.Gemm(F.G[k], Y[k+1], Y[k], matrix.FScalar(-1.0), matrix.FScalar(1.0))
+		if err != nil {
+			return err
+		}
+	}
+	for k := range Y {
+		B.SetSubMatrix(rows[k], 0, Y[k])
+	}
+	return nil
+}
+
+// Solve block tridiagonal system A*X = B, see NewBlockTridiag; on exit B
+// is overwritten with X.
+func BlockTridiagSolve(L, D, U []*matrix.FloatMatrix, B *matrix.FloatMatrix) error {
+	F, err := NewBlockTridiag(L, D, U)
+	if err != nil {
+		return err
+	}
+	return F.Solve(B)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -11,18 +11,23 @@ package lapack
 // #include <stdlib.h>
 // #include "lapackutil.h"
 import "C"
-import "unsafe"
+import (
+	"github.com/nvcook42/linalg"
+	"unsafe"
+)
 
 func dlarf(side string, M, N int, V []float64, incv int, tau, C []float64, ldc int) {
+	alloc := linalg.GetAllocator()
 	var work []float64
 
 	cside := C.CString(side)
 	defer C.free(unsafe.Pointer(cside))
 	if side[0] == 'L' {
-		work = make([]float64, N, N)
+		work = alloc.Float64s(N)
 	} else {
-		work = make([]float64, M, M)
+		work = alloc.Float64s(M)
 	}
+	defer alloc.Free(work)
 
 	C.dlarf_(cside,
 		(*C.int)(unsafe.Pointer(&M)),
@@ -37,6 +42,7 @@ func dlarf(side string, M, N int, V []float64, incv int, tau, C []float64, ldc i
 }
 
 func dlarfb(side, trans, direct, storev string, M, N, K int, V []float64, ldv int, T []float64, ldt int, C []float64, ldc int) {
+	alloc := linalg.GetAllocator()
 	var work []float64
 	var ldwork int
 
@@ -49,12 +55,17 @@ func dlarfb(side, trans, direct, storev string, M, N, K int, V []float64, ldv in
 	cstorev := C.CString(storev)
 	defer C.free(unsafe.Pointer(cstorev))
 
+	// work is ldwork by K
 	if side[0] == 'L' {
-		ldwork = N * K
+		ldwork = N
 	} else {
-		ldwork = M * K
+		ldwork = M
 	}
-	work = make([]float64, ldwork, ldwork)
+	if ldwork < 1 {
+		ldwork = 1
+	}
+	work = alloc.Float64s(ldwork * K)
+	defer alloc.Free(work)
 
 	C.dlarfb_(cside, ctrans, cdirect, cstorev,
 		(*C.int)(unsafe.Pointer(&M)),
@@ -72,15 +83,17 @@ func dlarfb(side, trans, direct, storev string, M, N, K int, V []float64, ldv in
 }
 
 func dlarfx(side string, M, N int, V []float64, tau, C []float64, ldc int) {
+	alloc := linalg.GetAllocator()
 	var work []float64
 
 	cside := C.CString(side)
 	defer C.free(unsafe.Pointer(cside))
 	if side[0] == 'L' {
-		work = make([]float64, N, N)
+		work = alloc.Float64s(N)
 	} else {
-		work = make([]float64, M, M)
+		work = alloc.Float64s(M)
 	}
+	defer alloc.Free(work)
 
 	C.dlarfx_(cside,
 		(*C.int)(unsafe.Pointer(&M)),
@@ -122,6 +135,7 @@ func dlarft(direct, storev string, N, K int, V []float64, ldv int, tau, T []floa
 }
 
 func dorgqr(M, N, K int, A []float64, lda int, tau []float64) int {
+	alloc := linalg.GetAllocator()
 	var info int = 0
 	var lwork int = -1
 	var work float64
@@ -140,7 +154,8 @@ func dorgqr(M, N, K int, A []float64, lda int, tau []float64) int {
 
 	// allocate work area
 	lwork = int(work)
-	wbuf := make([]float64, lwork)
+	wbuf := alloc.Float64s(lwork)
+	defer alloc.Free(wbuf)
 
 	C.dorgqr_(
 		(*C.int)(unsafe.Pointer(&M)),
@@ -11,7 +11,10 @@ package lapack
 // #include <stdlib.h>
 // #include "lapack.h"
 import "C"
-import "unsafe"
+import (
+	"github.com/nvcook42/linalg"
+	"unsafe"
+)
 
 // void zlacpy_(char *uplo, int *m, int *n, complex *A, int *lda, complex *B, int *ldb);
 
@@ -46,6 +49,7 @@ func zgetrs(trans string, N, Nrhs int, A []complex128, lda int, ipiv []int32, B
 
 // void zgetri_(int *n, complex *A, int *lda, int *ipiv, complex *work, int *lwork, int *info);
 func zgetri(N int, A []complex128, lda int, ipiv []int32) int {
+	alloc := linalg.GetAllocator()
 	var info int = 0
 	var lwork int = -1
 	var work complex128
@@ -56,7 +60,8 @@ func zgetri(N int, A []complex128, lda int, ipiv []int32) int {
 
 	// allocate work area
 	lwork = int(real(work))
-	wbuf := make([]complex128, lwork)
+	wbuf := alloc.Complex128s(lwork)
+	defer alloc.Free(wbuf)
 
 	C.zgetri_((*C.int)(unsafe.Pointer(&N)),
 		(unsafe.Pointer(&A[0])),
@@ -170,19 +175,203 @@ func zgttrs(trans string, N, Nrhs int, DL, D, DU, DU2 []complex128, ipiv []int32
 // void zpotrs_(char *uplo, int *n, int *nrhs, complex *A, int *lda, complex *B, int *ldb, int *info);
 // void zpotri_(char *uplo, int *n, complex *A, int *lda, int *info);
 // void zposv_(char *uplo, int *n, int *nrhs, complex *A, int *lda, complex *B, int *ldb, int *info);
+
 // void zpbtrf_(char *uplo, int *n, int *kd, complex *AB, int *ldab, int *info);
-// void zpbtrs_(char *uplo, int *n, int *kd, int *nrhs, complex *AB, int *ldab, complex *B, int *ldb, int *info);
-// void zpbsv_(char *uplo, int *n, int *kd, int *nrhs, complex *A, int *lda, complex *B, int *ldb, int *info);
+func zpbtrf(uplo string, N, kd int, AB []complex128, ldab int) int {
+	var info int = 0
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	C.zpbtrf_(cuplo,
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.int)(unsafe.Pointer(&kd)),
+		unsafe.Pointer(&AB[0]),
+		(*C.int)(unsafe.Pointer(&ldab)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
+// void zpbtrs_(char *uplo, int *n, int *kd, int *nrhs, complex *AB,
+//		int *ldab, complex *B, int *ldb, int *info);
+func zpbtrs(uplo string, N, kd, Nrhs int, AB []complex128, ldab int, B []complex128, ldb int) int {
+	var info int = 0
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	C.zpbtrs_(cuplo,
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.int)(unsafe.Pointer(&kd)),
+		(*C.int)(unsafe.Pointer(&Nrhs)),
+		unsafe.Pointer(&AB[0]),
+		(*C.int)(unsafe.Pointer(&ldab)),
+		unsafe.Pointer(&B[0]),
+		(*C.int)(unsafe.Pointer(&ldb)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
+// void zpbsv_(char *uplo, int *n, int *kd, int *nrhs, complex *A,
+//		int *lda, complex *B, int *ldb, int *info);
+func zpbsv(uplo string, N, kd, Nrhs int, AB []complex128, ldab int, B []complex128, ldb int) int {
+	var info int = 0
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	C.zpbsv_(cuplo,
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.int)(unsafe.Pointer(&kd)),
+		(*C.int)(unsafe.Pointer(&Nrhs)),
+		unsafe.Pointer(&AB[0]),
+		(*C.int)(unsafe.Pointer(&ldab)),
+		unsafe.Pointer(&B[0]),
+		(*C.int)(unsafe.Pointer(&ldb)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
+// void zpftrf_(char *transr, char *uplo, int *n, complex *A, int *info);
+func zpftrf(transr, uplo string, N int, A []complex128) int {
+	var info int = 0
+	ctransr := C.CString(transr)
+	defer C.free(unsafe.Pointer(ctransr))
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	C.zpftrf_(ctransr, cuplo,
+		(*C.int)(unsafe.Pointer(&N)),
+		unsafe.Pointer(&A[0]),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
+// void zpftrs_(char *transr, char *uplo, int *n, int *nrhs, complex *A,
+//		complex *B, int *ldb, int *info);
+func zpftrs(transr, uplo string, N, Nrhs int, A []complex128, B []complex128, ldb int) int {
+	var info int = 0
+	ctransr := C.CString(transr)
+	defer C.free(unsafe.Pointer(ctransr))
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	C.zpftrs_(ctransr, cuplo,
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.int)(unsafe.Pointer(&Nrhs)),
+		unsafe.Pointer(&A[0]),
+		unsafe.Pointer(&B[0]),
+		(*C.int)(unsafe.Pointer(&ldb)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
+// void ztrttf_(char *transr, char *uplo, int *n, complex *A, int *lda,
+//		complex *ARF, int *info);
+func ztrttf(transr, uplo string, N int, A []complex128, lda int, ARF []complex128) int {
+	var info int = 0
+	ctransr := C.CString(transr)
+	defer C.free(unsafe.Pointer(ctransr))
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	C.ztrttf_(ctransr, cuplo,
+		(*C.int)(unsafe.Pointer(&N)),
+		unsafe.Pointer(&A[0]),
+		(*C.int)(unsafe.Pointer(&lda)),
+		unsafe.Pointer(&ARF[0]),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
+// void ztfttr_(char *transr, char *uplo, int *n, complex *ARF, complex *A,
+//		int *lda, int *info);
+func ztfttr(transr, uplo string, N int, ARF []complex128, A []complex128, lda int) int {
+	var info int = 0
+	ctransr := C.CString(transr)
+	defer C.free(unsafe.Pointer(ctransr))
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	C.ztfttr_(ctransr, cuplo,
+		(*C.int)(unsafe.Pointer(&N)),
+		unsafe.Pointer(&ARF[0]),
+		unsafe.Pointer(&A[0]),
+		(*C.int)(unsafe.Pointer(&lda)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
 // void zpttrf_(int *n, double *d, complex *e, int *info);
 // void zpttrs_(char *uplo, int *n, int *nrhs, double *d, complex *e, complex *B, int *ldB, int *info);
 // void zptsv_(int *n, int *nrhs, double *d, complex *e, complex *B, int *ldB, int *info);
 // void zsytrf_(char *uplo, int *n, complex *A, int *lda, int *ipiv, complex *work, int *lwork, int *info);
+func zsytrf(uplo string, N int, A []complex128, lda int, ipiv []int32) int {
+	alloc := linalg.GetAllocator()
+	var info int = 0
+	var lwork int = -1
+	var work complex128
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+
+	// pre-calculate work buffer size
+	C.zsytrf_(cuplo, (*C.int)(unsafe.Pointer(&N)),
+		nil, (*C.int)(unsafe.Pointer(&lda)), nil,
+		unsafe.Pointer(&work), (*C.int)(unsafe.Pointer(&lwork)),
+		(*C.int)(unsafe.Pointer(&info)))
+
+	// allocate work area
+	lwork = int(real(work))
+	wbuf := alloc.Complex128s(lwork)
+	defer alloc.Free(wbuf)
+
+	C.zsytrf_(cuplo, (*C.int)(unsafe.Pointer(&N)),
+		unsafe.Pointer(&A[0]), (*C.int)(unsafe.Pointer(&lda)),
+		(*C.int)(unsafe.Pointer(&ipiv[0])),
+		unsafe.Pointer(&wbuf[0]), (*C.int)(unsafe.Pointer(&lwork)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
 // void zhetrf_(char *uplo, int *n, complex *A, int *lda, int *ipiv, complex *work, int *lwork, int *info);
 // void zsytrs_(char *uplo, int *n, int *nrhs, complex *A, int *lda, int *ipiv, complex *B, int *ldb, int *info);
+func zsytrs(uplo string, N, Nrhs int, A []complex128, lda int, ipiv []int32, B []complex128, ldb int) int {
+	var info int = 0
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+
+	C.zsytrs_(cuplo, (*C.int)(unsafe.Pointer(&N)),
+		(*C.int)(unsafe.Pointer(&Nrhs)),
+		unsafe.Pointer(&A[0]), (*C.int)(unsafe.Pointer(&lda)),
+		(*C.int)(unsafe.Pointer(&ipiv[0])),
+		unsafe.Pointer(&B[0]), (*C.int)(unsafe.Pointer(&ldb)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
 // void zhetrs_(char *uplo, int *n, int *nrhs, complex *A, int *lda, int *ipiv, complex *B, int *ldb, int *info);
 // void zsytri_(char *uplo, int *n, complex *A, int *lda, int *ipiv, complex *work, int *info);
 // void zhetri_(char *uplo, int *n, complex *A, int *lda, int *ipiv, complex *work, int *info);
 // void zsysv_(char *uplo, int *n, int *nrhs, complex *A, int *lda, int *ipiv, complex *B, int *ldb, complex *work, int *lwork, int *info);
+func zsysv(uplo string, N, Nrhs int, A []complex128, lda int, ipiv []int32, B []complex128, ldb int) int {
+	alloc := linalg.GetAllocator()
+	var info int = 0
+	var lwork int = -1
+	var work complex128
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+
+	// pre-calculate work buffer size
+	C.zsysv_(cuplo, (*C.int)(unsafe.Pointer(&N)), (*C.int)(unsafe.Pointer(&Nrhs)),
+		nil, (*C.int)(unsafe.Pointer(&lda)), nil,
+		nil, (*C.int)(unsafe.Pointer(&ldb)),
+		unsafe.Pointer(&work), (*C.int)(unsafe.Pointer(&lwork)),
+		(*C.int)(unsafe.Pointer(&info)))
+
+	// allocate work area
+	lwork = int(real(work))
+	wbuf := alloc.Complex128s(lwork)
+	defer alloc.Free(wbuf)
+
+	C.zsysv_(cuplo, (*C.int)(unsafe.Pointer(&N)), (*C.int)(unsafe.Pointer(&Nrhs)),
+		unsafe.Pointer(&A[0]), (*C.int)(unsafe.Pointer(&lda)),
+		(*C.int)(unsafe.Pointer(&ipiv[0])),
+		unsafe.Pointer(&B[0]), (*C.int)(unsafe.Pointer(&ldb)),
+		unsafe.Pointer(&wbuf[0]), (*C.int)(unsafe.Pointer(&lwork)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
 // void zhesv_(char *uplo, int *n, int *nrhs, complex *A, int *lda, int *ipiv, complex *B, int *ldb, complex *work, int *lwork, int *info);
 // void ztrtrs_(char *uplo, char *trans, char *diag, int *n, int *nrhs, complex  *a, int *lda, complex *b, int *ldb, int *info);
 // void ztrtri_(char *uplo, char *diag, int *n, complex  *a, int *lda, int *info);
@@ -191,6 +380,7 @@ func zgttrs(trans string, N, Nrhs int, DL, D, DU, DU2 []complex128, ipiv []int32
 // void zgels_(char *trans, int *m, int *n, int *nrhs, complex *a, int *lda,
 //		complex *b, int *ldb, complex *work, int *lwork, int *info);
 func zgels(trans string, M, N, NRHS int, A []complex128, lda int, B []complex128, ldb int) int {
+	alloc := linalg.GetAllocator()
 	var info int = 0
 	var lwork int = -1
 	var work complex128
@@ -213,7 +403,8 @@ func zgels(trans string, M, N, NRHS int, A []complex128, lda int, B []complex128
 		(*C.int)(unsafe.Pointer(&info)))
 
 	lwork = int(real(work))
-	wbuf := make([]complex128, lwork)
+	wbuf := alloc.Complex128s(lwork)
+	defer alloc.Free(wbuf)
 
 	C.zgels_(
 		ctrans,
@@ -11,7 +11,10 @@ package lapack
 // #include <stdlib.h>
 // #include "lapack.h"
 import "C"
-import "unsafe"
+import (
+	"github.com/nvcook42/linalg"
+	"unsafe"
+)
 
 //import "fmt"
 
@@ -86,6 +89,7 @@ func dgetrs(trans string, N, Nrhs int, A []float64, lda int, ipiv []int32,
 
 // dgetri_(int *n, double *A, int *lda, int *ipiv, double *work, int *lwork, int *info);
 func dgetri(N int, A []float64, lda int, ipiv []int32) int {
+	alloc := linalg.GetAllocator()
 	var info int = 0
 	var lwork int = -1
 	var work float64
@@ -96,7 +100,8 @@ func dgetri(N int, A []float64, lda int, ipiv []int32) int {
 
 	// allocate work area
 	lwork = int(work)
-	wbuf := make([]float64, lwork)
+	wbuf := alloc.Float64s(lwork)
+	defer alloc.Free(wbuf)
 
 	C.dgetri_((*C.int)(unsafe.Pointer(&N)), (*C.double)(unsafe.Pointer(&A[0])),
 		(*C.int)(unsafe.Pointer(&lda)), (*C.int)(unsafe.Pointer(&ipiv[0])),
@@ -208,12 +213,120 @@ func dposv(uplo string, N, Nrhs int, A []float64, lda int, B []float64, ldb int)
 }
 
 // void dpbtrf_(char *uplo, int *n, int *kd, double *AB, int *ldab, int *info);
+func dpbtrf(uplo string, N, kd int, AB []float64, ldab int) int {
+	var info int = 0
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	C.dpbtrf_(cuplo,
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.int)(unsafe.Pointer(&kd)),
+		(*C.double)(unsafe.Pointer(&AB[0])),
+		(*C.int)(unsafe.Pointer(&ldab)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
 
 // void dpbtrs_(char *uplo, int *n, int *kd, int *nrhs, double *AB,
 //		int *ldab, double *B, int *ldb, int *info);
+func dpbtrs(uplo string, N, kd, Nrhs int, AB []float64, ldab int, B []float64, ldb int) int {
+	var info int = 0
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	C.dpbtrs_(cuplo,
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.int)(unsafe.Pointer(&kd)),
+		(*C.int)(unsafe.Pointer(&Nrhs)),
+		(*C.double)(unsafe.Pointer(&AB[0])),
+		(*C.int)(unsafe.Pointer(&ldab)),
+		(*C.double)(unsafe.Pointer(&B[0])),
+		(*C.int)(unsafe.Pointer(&ldb)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
 
 // void dpbsv_(char *uplo, int *n, int *kd, int *nrhs, double *A,
 //		int *lda, double *B, int *ldb, int *info);
+func dpbsv(uplo string, N, kd, Nrhs int, AB []float64, ldab int, B []float64, ldb int) int {
+	var info int = 0
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	C.dpbsv_(cuplo,
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.int)(unsafe.Pointer(&kd)),
+		(*C.int)(unsafe.Pointer(&Nrhs)),
+		(*C.double)(unsafe.Pointer(&AB[0])),
+		(*C.int)(unsafe.Pointer(&ldab)),
+		(*C.double)(unsafe.Pointer(&B[0])),
+		(*C.int)(unsafe.Pointer(&ldb)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
+// void dpftrf_(char *transr, char *uplo, int *n, double *A, int *info);
+func dpftrf(transr, uplo string, N int, A []float64) int {
+	var info int = 0
+	ctransr := C.CString(transr)
+	defer C.free(unsafe.Pointer(ctransr))
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	C.dpftrf_(ctransr, cuplo,
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.double)(unsafe.Pointer(&A[0])),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
+// void dpftrs_(char *transr, char *uplo, int *n, int *nrhs, double *A,
+//		double *B, int *ldb, int *info);
+func dpftrs(transr, uplo string, N, Nrhs int, A []float64, B []float64, ldb int) int {
+	var info int = 0
+	ctransr := C.CString(transr)
+	defer C.free(unsafe.Pointer(ctransr))
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	C.dpftrs_(ctransr, cuplo,
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.int)(unsafe.Pointer(&Nrhs)),
+		(*C.double)(unsafe.Pointer(&A[0])),
+		(*C.double)(unsafe.Pointer(&B[0])),
+		(*C.int)(unsafe.Pointer(&ldb)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
+// void dtrttf_(char *transr, char *uplo, int *n, double *A, int *lda,
+//		double *ARF, int *info);
+func dtrttf(transr, uplo string, N int, A []float64, lda int, ARF []float64) int {
+	var info int = 0
+	ctransr := C.CString(transr)
+	defer C.free(unsafe.Pointer(ctransr))
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	C.dtrttf_(ctransr, cuplo,
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.double)(unsafe.Pointer(&A[0])),
+		(*C.int)(unsafe.Pointer(&lda)),
+		(*C.double)(unsafe.Pointer(&ARF[0])),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
+// void dtfttr_(char *transr, char *uplo, int *n, double *ARF, double *A,
+//		int *lda, int *info);
+func dtfttr(transr, uplo string, N int, ARF []float64, A []float64, lda int) int {
+	var info int = 0
+	ctransr := C.CString(transr)
+	defer C.free(unsafe.Pointer(ctransr))
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	C.dtfttr_(ctransr, cuplo,
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.double)(unsafe.Pointer(&ARF[0])),
+		(*C.double)(unsafe.Pointer(&A[0])),
+		(*C.int)(unsafe.Pointer(&lda)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
 
 // void dpttrf_(int *n, double *d, double *e, int *info);
 
@@ -224,6 +337,7 @@ func dposv(uplo string, N, Nrhs int, A []float64, lda int, B []float64, ldb int)
 // void dsytrf_(char *uplo, int *n, double *A, int *lda, int *ipiv,
 //		double *work, int *lwork, int *info);
 func dsytrf(uplo string, N int, A []float64, lda int, ipiv []int32) int {
+	alloc := linalg.GetAllocator()
 	var info int = 0
 	var lwork int = -1
 	var work float64
@@ -238,7 +352,8 @@ func dsytrf(uplo string, N int, A []float64, lda int, ipiv []int32) int {
 
 	// allocate work area
 	lwork = int(work)
-	wbuf := make([]float64, lwork)
+	wbuf := alloc.Float64s(lwork)
+	defer alloc.Free(wbuf)
 
 	C.dsytrf_(cuplo, (*C.int)(unsafe.Pointer(&N)),
 		(*C.double)(unsafe.Pointer(&A[0])), (*C.int)(unsafe.Pointer(&lda)),
@@ -271,6 +386,34 @@ func dsytrs(uplo string, N, Nrhs int, A []float64, lda int, ipiv []int32, B []fl
 
 // void dsysv_(char *uplo, int *n, int *nrhs, double *A, int *lda,
 //		int *ipiv, double *B, int *ldb, double *work, int *lwork, int *info);
+func dsysv(uplo string, N, Nrhs int, A []float64, lda int, ipiv []int32, B []float64, ldb int) int {
+	alloc := linalg.GetAllocator()
+	var info int = 0
+	var lwork int = -1
+	var work float64
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+
+	// pre-calculate work buffer size
+	C.dsysv_(cuplo, (*C.int)(unsafe.Pointer(&N)), (*C.int)(unsafe.Pointer(&Nrhs)),
+		nil, (*C.int)(unsafe.Pointer(&lda)), nil,
+		nil, (*C.int)(unsafe.Pointer(&ldb)),
+		(*C.double)(unsafe.Pointer(&work)), (*C.int)(unsafe.Pointer(&lwork)),
+		(*C.int)(unsafe.Pointer(&info)))
+
+	// allocate work area
+	lwork = int(work)
+	wbuf := alloc.Float64s(lwork)
+	defer alloc.Free(wbuf)
+
+	C.dsysv_(cuplo, (*C.int)(unsafe.Pointer(&N)), (*C.int)(unsafe.Pointer(&Nrhs)),
+		(*C.double)(unsafe.Pointer(&A[0])), (*C.int)(unsafe.Pointer(&lda)),
+		(*C.int)(unsafe.Pointer(&ipiv[0])),
+		(*C.double)(unsafe.Pointer(&B[0])), (*C.int)(unsafe.Pointer(&ldb)),
+		(*C.double)(unsafe.Pointer(&wbuf[0])), (*C.int)(unsafe.Pointer(&lwork)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
 
 // void dtrtrs_(char *uplo, char *trans, char *diag, int *n, int *nrhs,
 //		double  *A, int *lda, double *B, int *ldb, int *info);
@@ -303,6 +446,7 @@ func dtrtrs(uplo, trans, diag string, N, Nrhs int, A []float64, lda int, B []flo
 // void dgels_(char *trans, int *m, int *n, int *nrhs, double *A, int *lda,
 //		double *B, int *ldb, double *work, int *lwork, int *info);
 func dgels(trans string, M, N, NRHS int, A []float64, lda int, B []float64, ldb int) int {
+	alloc := linalg.GetAllocator()
 	var info int = 0
 	var lwork int = -1
 	var work float64
@@ -325,7 +469,8 @@ func dgels(trans string, M, N, NRHS int, A []float64, lda int, B []float64, ldb
 		(*C.int)(unsafe.Pointer(&info)))
 
 	lwork = int(work)
-	wbuf := make([]float64, lwork)
+	wbuf := alloc.Float64s(lwork)
+	defer alloc.Free(wbuf)
 
 	C.dgels_(
 		ctrans,
@@ -345,6 +490,7 @@ func dgels(trans string, M, N, NRHS int, A []float64, lda int, B []float64, ldb
 // void dgeqrf_(int *m, int *n, double *a, int *lda, double *tau,
 //		double *work, int *lwork, int *info);
 func dgeqrf(M, N int, A []float64, lda int, tau []float64) int {
+	alloc := linalg.GetAllocator()
 	var info int = 0
 	var lwork int = -1
 	var work float64
@@ -360,7 +506,8 @@ func dgeqrf(M, N int, A []float64, lda int, tau []float64) int {
 		(*C.int)(unsafe.Pointer(&info)))
 
 	lwork = int(work)
-	wbuf := make([]float64, lwork)
+	wbuf := alloc.Float64s(lwork)
+	defer alloc.Free(wbuf)
 	C.dgeqrf_((*C.int)(unsafe.Pointer(&M)),
 		(*C.int)(unsafe.Pointer(&N)),
 		(*C.double)(unsafe.Pointer(&A[0])),
@@ -372,6 +519,40 @@ func dgeqrf(M, N int, A []float64, lda int, tau []float64) int {
 	return info
 }
 
+// void dgeqp3_(int *m, int *n, double *a, int *lda, int *jpvt,
+//		double *tau, double *work, int *lwork, int *info);
+func dgeqp3(M, N int, A []float64, lda int, jpvt []int32, tau []float64) int {
+	alloc := linalg.GetAllocator()
+	var info int = 0
+	var lwork int = -1
+	var work float64
+
+	// calculate work buffer size
+	C.dgeqp3_((*C.int)(unsafe.Pointer(&M)),
+		(*C.int)(unsafe.Pointer(&N)),
+		nil,
+		(*C.int)(unsafe.Pointer(&lda)),
+		nil,
+		nil,
+		(*C.double)(unsafe.Pointer(&work)),
+		(*C.int)(unsafe.Pointer(&lwork)),
+		(*C.int)(unsafe.Pointer(&info)))
+
+	lwork = int(work)
+	wbuf := alloc.Float64s(lwork)
+	defer alloc.Free(wbuf)
+	C.dgeqp3_((*C.int)(unsafe.Pointer(&M)),
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.double)(unsafe.Pointer(&A[0])),
+		(*C.int)(unsafe.Pointer(&lda)),
+		(*C.int)(unsafe.Pointer(&jpvt[0])),
+		(*C.double)(unsafe.Pointer(&tau[0])),
+		(*C.double)(unsafe.Pointer(&wbuf[0])),
+		(*C.int)(unsafe.Pointer(&lwork)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
 // void dgeqrt3_(int *m, int *n, double *a, int *lda, double *t,
 //		int *ldt, int *info);
 /*
@@ -393,6 +574,7 @@ func dgeqrt3(M, N int, A []float64, lda int, T []float64, ldt int) int {
 //		double *a, int *lda, double *tau, double *c, int *ldc,
 //		double *work, int *lwork, int *info);
 func dormqr(side, trans string, M, N, K int, A []float64, lda int, tau, C []float64, ldc int) int {
+	alloc := linalg.GetAllocator()
 	var info int = 0
 	var lwork int = -1
 	var work float64
@@ -417,7 +599,8 @@ func dormqr(side, trans string, M, N, K int, A []float64, lda int, tau, C []floa
 		(*C.int)(unsafe.Pointer(&info)))
 
 	lwork = int(work)
-	wbuf := make([]float64, lwork)
+	wbuf := alloc.Float64s(lwork)
+	defer alloc.Free(wbuf)
 	C.dormqr_(cside, ctrans,
 		(*C.int)(unsafe.Pointer(&M)),
 		(*C.int)(unsafe.Pointer(&N)),
@@ -454,6 +637,7 @@ func dormqr(side, trans string, M, N, K int, A []float64, lda int, tau, C []floa
 // void dsyevd_(char *jobz, char *uplo, int *n, double *A, int *ldA, double *W,
 //		double *work, int *lwork, int *iwork, int *liwork, int *info);
 func dsyevd(jobz, uplo string, N int, A []float64, lda int, W []float64) int {
+	alloc := linalg.GetAllocator()
 	var info int = 0
 	var lwork int = -1
 	var liwork int = -1
@@ -474,9 +658,11 @@ func dsyevd(jobz, uplo string, N int, A []float64, lda int, W []float64) int {
 
 	// allocate work area
 	lwork = int(work)
-	wbuf := make([]float64, lwork)
+	wbuf := alloc.Float64s(lwork)
+	defer alloc.Free(wbuf)
 	liwork = int(iwork)
-	wibuf := make([]int32, liwork)
+	wibuf := alloc.Int32s(liwork)
+	defer alloc.Free(wibuf)
 
 	C.dsyevd_(cjobz, cuplo, (*C.int)(unsafe.Pointer(&N)),
 		(*C.double)(unsafe.Pointer(&A[0])),
@@ -487,19 +673,184 @@ func dsyevd(jobz, uplo string, N int, A []float64, lda int, W []float64) int {
 	return info
 }
 
+// void dggev_(char *jobvl, char *jobvr, int *n, double *A, int *ldA,
+//		double *B, int *ldB, double *alphar, double *alphai, double *beta,
+//		double *vl, int *ldvl, double *vr, int *ldvr, double *work,
+//		int *lwork, int *info);
+func dggev(jobvl, jobvr string, N int, A []float64, lda int, B []float64, ldb int,
+	alphar, alphai, beta []float64, VL []float64, ldvl int, VR []float64, ldvr int) int {
+	alloc := linalg.GetAllocator()
+	var info int = 0
+	var lwork int = -1
+	var work float64
+	var vl, vr *C.double
+
+	cjobvl := C.CString(jobvl)
+	defer C.free(unsafe.Pointer(cjobvl))
+	cjobvr := C.CString(jobvr)
+	defer C.free(unsafe.Pointer(cjobvr))
+	if len(VL) > 0 {
+		vl = (*C.double)(unsafe.Pointer(&VL[0]))
+	}
+	if len(VR) > 0 {
+		vr = (*C.double)(unsafe.Pointer(&VR[0]))
+	}
+
+	// pre-calculate work buffer size
+	C.dggev_(cjobvl, cjobvr, (*C.int)(unsafe.Pointer(&N)),
+		nil, (*C.int)(unsafe.Pointer(&lda)), nil, (*C.int)(unsafe.Pointer(&ldb)),
+		nil, nil, nil, nil, (*C.int)(unsafe.Pointer(&ldvl)),
+		nil, (*C.int)(unsafe.Pointer(&ldvr)),
+		(*C.double)(unsafe.Pointer(&work)), (*C.int)(unsafe.Pointer(&lwork)),
+		(*C.int)(unsafe.Pointer(&info)))
+
+	// allocate work area
+	lwork = int(work)
+	wbuf := alloc.Float64s(lwork)
+	defer alloc.Free(wbuf)
+
+	C.dggev_(cjobvl, cjobvr, (*C.int)(unsafe.Pointer(&N)),
+		(*C.double)(unsafe.Pointer(&A[0])), (*C.int)(unsafe.Pointer(&lda)),
+		(*C.double)(unsafe.Pointer(&B[0])), (*C.int)(unsafe.Pointer(&ldb)),
+		(*C.double)(unsafe.Pointer(&alphar[0])),
+		(*C.double)(unsafe.Pointer(&alphai[0])),
+		(*C.double)(unsafe.Pointer(&beta[0])),
+		vl, (*C.int)(unsafe.Pointer(&ldvl)),
+		vr, (*C.int)(unsafe.Pointer(&ldvr)),
+		(*C.double)(unsafe.Pointer(&wbuf[0])), (*C.int)(unsafe.Pointer(&lwork)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
+// void dgeev_(char *jobvl, char *jobvr, int *n, double *A, int *ldA,
+//		double *wr, double *wi, double *vl, int *ldvl, double *vr, int *ldvr,
+//		double *work, int *lwork, int *info);
+func dgeev(jobvl, jobvr string, N int, A []float64, lda int, wr, wi []float64,
+	VL []float64, ldvl int, VR []float64, ldvr int) int {
+	alloc := linalg.GetAllocator()
+	var info int = 0
+	var lwork int = -1
+	var work float64
+	var vl, vr *C.double
+
+	cjobvl := C.CString(jobvl)
+	defer C.free(unsafe.Pointer(cjobvl))
+	cjobvr := C.CString(jobvr)
+	defer C.free(unsafe.Pointer(cjobvr))
+	if len(VL) > 0 {
+		vl = (*C.double)(unsafe.Pointer(&VL[0]))
+	}
+	if len(VR) > 0 {
+		vr = (*C.double)(unsafe.Pointer(&VR[0]))
+	}
+
+	// pre-calculate work buffer size
+	C.dgeev_(cjobvl, cjobvr, (*C.int)(unsafe.Pointer(&N)),
+		nil, (*C.int)(unsafe.Pointer(&lda)), nil, nil,
+		nil, (*C.int)(unsafe.Pointer(&ldvl)),
+		nil, (*C.int)(unsafe.Pointer(&ldvr)),
+		(*C.double)(unsafe.Pointer(&work)), (*C.int)(unsafe.Pointer(&lwork)),
+		(*C.int)(unsafe.Pointer(&info)))
+
+	// allocate work area
+	lwork = int(work)
+	wbuf := alloc.Float64s(lwork)
+	defer alloc.Free(wbuf)
+
+	C.dgeev_(cjobvl, cjobvr, (*C.int)(unsafe.Pointer(&N)),
+		(*C.double)(unsafe.Pointer(&A[0])), (*C.int)(unsafe.Pointer(&lda)),
+		(*C.double)(unsafe.Pointer(&wr[0])),
+		(*C.double)(unsafe.Pointer(&wi[0])),
+		vl, (*C.int)(unsafe.Pointer(&ldvl)),
+		vr, (*C.int)(unsafe.Pointer(&ldvr)),
+		(*C.double)(unsafe.Pointer(&wbuf[0])), (*C.int)(unsafe.Pointer(&lwork)),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
+// void dtrcon_(char *norm, char *uplo, char *diag, int *n, double *A,
+//		int *ldA, double *rcond, double *work, int *iwork, int *info);
+func dtrcon(norm, uplo, diag string, N int, A []float64, lda int) (float64, int) {
+	alloc := linalg.GetAllocator()
+	var info int = 0
+	var rcond float64
+
+	cnorm := C.CString(norm)
+	defer C.free(unsafe.Pointer(cnorm))
+	cuplo := C.CString(uplo)
+	defer C.free(unsafe.Pointer(cuplo))
+	cdiag := C.CString(diag)
+	defer C.free(unsafe.Pointer(cdiag))
+	wbuf := alloc.Float64s(3 * N)
+	defer alloc.Free(wbuf)
+	wibuf := alloc.Int32s(N)
+	defer alloc.Free(wibuf)
+
+	C.dtrcon_(cnorm, cuplo, cdiag,
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.double)(unsafe.Pointer(&A[0])),
+		(*C.int)(unsafe.Pointer(&lda)),
+		(*C.double)(unsafe.Pointer(&rcond)),
+		(*C.double)(unsafe.Pointer(&wbuf[0])),
+		(*C.int)(unsafe.Pointer(&wibuf[0])),
+		(*C.int)(unsafe.Pointer(&info)))
+	return rcond, info
+}
+
+// void dgecon_(char *norm, int *n, double *A, int *ldA, double *anorm,
+//		double *rcond, double *work, int *iwork, int *info);
+func dgecon(norm string, N int, A []float64, lda int, anorm float64) (float64, int) {
+	alloc := linalg.GetAllocator()
+	var info int = 0
+	var rcond float64
+
+	cnorm := C.CString(norm)
+	defer C.free(unsafe.Pointer(cnorm))
+	wbuf := alloc.Float64s(4 * N)
+	defer alloc.Free(wbuf)
+	wibuf := alloc.Int32s(N)
+	defer alloc.Free(wibuf)
+
+	C.dgecon_(cnorm,
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.double)(unsafe.Pointer(&A[0])),
+		(*C.int)(unsafe.Pointer(&lda)),
+		(*C.double)(unsafe.Pointer(&anorm)),
+		(*C.double)(unsafe.Pointer(&rcond)),
+		(*C.double)(unsafe.Pointer(&wbuf[0])),
+		(*C.int)(unsafe.Pointer(&wibuf[0])),
+		(*C.int)(unsafe.Pointer(&info)))
+	return rcond, info
+}
+
+// void ddisna_(char *job, int *m, int *n, double *d, double *sep, int *info);
+func ddisna(job string, M, N int, D, sep []float64) int {
+	var info int = 0
+	cjob := C.CString(job)
+	defer C.free(unsafe.Pointer(cjob))
+
+	C.ddisna_(cjob,
+		(*C.int)(unsafe.Pointer(&M)),
+		(*C.int)(unsafe.Pointer(&N)),
+		(*C.double)(unsafe.Pointer(&D[0])),
+		(*C.double)(unsafe.Pointer(&sep[0])),
+		(*C.int)(unsafe.Pointer(&info)))
+	return info
+}
+
 // void dsyevr_(char *jobz, char *range, char *uplo, int *n, double *A, int *ldA,
 //		double *vl, double *vu, int *il, int *iu, double *abstol, int *m, double *W,
 //		double *Z, int *ldZ, int *isuppz, double *work, int *lwork, int *iwork,
 //		int *liwork, int *info);
 func dsyevr(jobz, srange, uplo string, N int, A []float64, lda int, vl, vu float64,
-	il, iu int, M int, W, Z []float64, LDz int) int {
+	il, iu int, abstol float64, M int, W, Z []float64, LDz int) int {
+	alloc := linalg.GetAllocator()
 
 	var info int = 0
 	var lwork int = -1
 	var liwork int = -1
 	var iwork int32
 	var work float64
-	var abstol float64 = 0.0
 
 	cjobz := C.CString(jobz)
 	defer C.free(unsafe.Pointer(cjobz))
@@ -532,8 +883,10 @@ func dsyevr(jobz, srange, uplo string, N int, A []float64, lda int, vl, vu float
 	lwork = int(work)
 	liwork = int(iwork)
 	//fmt.Printf("dsyevr: lwork=%d, liwork=%d\n", lwork, liwork)
-	wbuf := make([]float64, lwork)
-	wibuf := make([]int32, liwork)
+	wbuf := alloc.Float64s(lwork)
+	defer alloc.Free(wbuf)
+	wibuf := alloc.Int32s(liwork)
+	defer alloc.Free(wibuf)
 
 	var Zbuf, Wbuf *C.double
 	if W != nil {
@@ -575,15 +928,17 @@ func dsyevr(jobz, srange, uplo string, N int, A []float64, lda int, vl, vu float
 //		double *W, double *Z, int *ldz, double *work, int *lwork, int *iwork,
 //		int *ifail, int *info);
 
+// On exit the first info elements of ifail, of length N when jobz is "V",
+// hold the 1-based indices of eigenvectors that failed to converge.
 func dsyevx(jobz, srange, uplo string, N int, A []float64, lda int, vl, vu float64,
-	il, iu int, M int, W, Z []float64, LDz int) int {
+	il, iu int, abstol float64, M int, W, Z []float64, LDz int, ifail []int32) int {
+	alloc := linalg.GetAllocator()
 
 	var info int = 0
 	var lwork int = -1
 	//var liwork int = -1
 	//var iwork int32
 	var work float64
-	var abstol float64 = 0.0
 
 	cjobz := C.CString(jobz)
 	defer C.free(unsafe.Pointer(cjobz))
@@ -615,15 +970,15 @@ func dsyevx(jobz, srange, uplo string, N int, A []float64, lda int, vl, vu float
 	// allocate work area
 	lwork = int(work)
 	//fmt.Printf("dsyevx: lwork=%d, liwork=%d\n", lwork, liwork)
-	wbuf := make([]float64, lwork)
-	wibuf := make([]int32, 5*N)
+	wbuf := alloc.Float64s(lwork)
+	defer alloc.Free(wbuf)
+	wibuf := alloc.Int32s(5*N)
+	defer alloc.Free(wibuf)
 
 	var ifailbuf *C.int
-	var ifail []int32
 	ifailbuf = (*C.int)(unsafe.Pointer(nil))
 
 	if jobz[0] == 'V' {
-		ifail = make([]int32, N)
 		ifailbuf = (*C.int)(unsafe.Pointer(&ifail[0]))
 	}
 	var Zbuf, Wbuf *C.double
@@ -667,6 +1022,7 @@ func dsyevx(jobz, srange, uplo string, N int, A []float64, lda int, vl, vu float
 //		int *lwork, int *info);
 func dgesvd(jobu, jobvt string, M, N int, A []float64, lda int, S []float64, U []float64,
 	ldu int, Vt []float64, ldvt int) int {
+	alloc := linalg.GetAllocator()
 
 	var info int = 0
 	var lwork int = -1
@@ -688,7 +1044,8 @@ func dgesvd(jobu, jobvt string, M, N int, A []float64, lda int, S []float64, U [
 
 	// allocate work area
 	lwork = int(work)
-	wbuf := make([]float64, lwork)
+	wbuf := alloc.Float64s(lwork)
+	defer alloc.Free(wbuf)
 
 	var Ubuf, Vtbuf *C.double
 	if U != nil {
@@ -0,0 +1,175 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+	"math"
+	"sync/atomic"
+)
+
+// Tolerance used when cross-checking is requested with WithCrossCheck
+// only.
+const defaultCrossCheckTolerance = 1e-8
+
+/*
+ Cross-check of Solve and Getrf against the pure Go implementations of
+ package matops.
+
+ When cross-checking is enabled, by SetCrossCheck, the crosscheck option
+ or WithCrossCheck, these routines compute their result a second time
+ without the native LAPACK and compare the two:
+
+  Solve, square system   X against matops.Solve of A and B
+  Getrf, real matrix     factors and pivots against matops.LU with
+                         partial pivoting of a copy of A
+
+ Diff is the largest elementwise difference relative to the largest
+ element of the native result. A Diff above Tolerance, different pivots,
+ or an error from only one of the two backends sets Passed false and adds
+ a warning to the logged record of the operation (see linalg.SetLogger).
+ Differences of a correct pair of results are of the order of machine
+ precision times the condition number of A.
+
+ Cross-checking costs a pure Go factorization of A and a copy of A and B.
+ It is meant for diagnosing backend specific errors, not for routine use.
+*/
+type CrossCheck struct {
+	Backend   string
+	Diff      float64
+	Tolerance float64
+	Passed    bool
+}
+
+var crossCheckTolerance uint64
+
+// Enable cross-checking of all calls with tolerance t and return the
+// previous tolerance. Zero or negative t disables cross-checking, which is
+// the default. The crosscheck option overrides the tolerance for one call.
+func SetCrossCheck(t float64) float64 {
+	return math.Float64frombits(atomic.SwapUint64(&crossCheckTolerance, math.Float64bits(math.Max(0.0, t))))
+}
+
+// Return the global cross-check tolerance; zero if disabled.
+func CrossCheckTolerance() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&crossCheckTolerance))
+}
+
+// Option that carries a pointer to cross-check to fill.
+type crossCheckOpt struct {
+	linalg.Option
+	c *CrossCheck
+}
+
+// Return option that makes Solve and Getrf fill in c. It enables
+// cross-checking for the call with the global tolerance, or 1e-8 if
+// cross-checking is not enabled globally.
+func WithCrossCheck(c *CrossCheck) linalg.Option {
+	return &crossCheckOpt{linalg.BoolOpt("crosschecking", true), c}
+}
+
+// Return new cross-check if cross-checking is enabled in options or
+// globally, otherwise nil.
+func startCrossCheck(opts ...linalg.Option) *CrossCheck {
+	var target *CrossCheck
+	for _, o := range opts {
+		if c, ok := o.(*crossCheckOpt); ok {
+			target = c.c
+		}
+	}
+	t := linalg.GetFloatOpt("crosscheck", CrossCheckTolerance(), opts...)
+	if t <= 0.0 && target == nil {
+		return nil
+	}
+	if t <= 0.0 {
+		t = defaultCrossCheckTolerance
+	}
+	if target == nil {
+		target = &CrossCheck{}
+	}
+	*target = CrossCheck{Backend: "matops", Tolerance: t, Passed: true}
+	return target
+}
+
+// Compare native result A with pure Go result G, both m by n arrays with
+// leading indexes lda and ldg.
+func (c *CrossCheck) compare(A []float64, lda int, G []float64, ldg, m, n int) {
+	amax, diff := 0.0, 0.0
+	for j := 0; j < n; j++ {
+		for i := 0; i < m; i++ {
+			a := A[j*lda+i]
+			amax = math.Max(amax, math.Abs(a))
+			if d := math.Abs(a - G[j*ldg+i]); d > diff || math.IsNaN(d) {
+				diff = d
+			}
+		}
+	}
+	c.Diff = relative(diff, amax)
+	if math.IsNaN(c.Diff) || c.Diff > c.Tolerance {
+		c.Passed = false
+	}
+}
+
+// Record a result of only one of the backends or different pivots.
+func (c *CrossCheck) mismatch() {
+	c.Diff = math.Inf(1)
+	c.Passed = false
+}
+
+// Warn on op if c did not pass.
+func (c *CrossCheck) report(op *linalg.Op, what string) {
+	if c.Passed {
+		return
+	}
+	if math.IsInf(c.Diff, 1) {
+		op.Warn(fmt.Sprintf("cross-check with %s failed: %s pivots or singularity differ", c.Backend, what))
+	} else {
+		op.Warn(fmt.Sprintf("cross-check with %s failed: %s difference %.3g > %.3g",
+			c.Backend, what, c.Diff, c.Tolerance))
+	}
+}
+
+// Cross-check solution X of square system A*X = B.
+func crossCheckSolve(c *CrossCheck, A, X, B *matrix.FloatMatrix) {
+	G, err := matops.Solve(A, B)
+	if err != nil {
+		c.mismatch()
+		return
+	}
+	c.compare(X.FloatArray(), X.LeadingIndex(), G.FloatArray(), G.LeadingIndex(), X.Rows(), X.Cols())
+}
+
+// Cross-check factors of m by n LU factorization in A with pivots ipiv
+// against pure Go factorization of copy G of the original matrix. Native
+// error err is nil or wraps ErrSingular.
+func crossCheckLU(c *CrossCheck, A []float64, lda, m, n int, ipiv []int32, G *matrix.FloatMatrix, err error) {
+	P, _, gerr := matops.LU(G)
+	if errors.Is(gerr, linalg.ErrSingular) != errors.Is(err, linalg.ErrSingular) {
+		c.mismatch()
+		return
+	}
+	perm := matops.IdentityPermutation(m)
+	for k := 0; k < min(m, n); k++ {
+		p := int(ipiv[k]) - 1
+		perm[k], perm[p] = perm[p], perm[k]
+	}
+	for k := range perm {
+		if perm[k] != P[k] {
+			c.mismatch()
+			return
+		}
+	}
+	c.compare(A, lda, G.FloatArray(), G.LeadingIndex(), m, n)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,216 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/blas"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+/*
+ Solves a real periodic tridiagonal set of linear equations.
+
+ PURPOSE
+ Solves A*X = B for the n by n matrix
+
+      [ d[0]    du[0]                       dl[n-1] ]
+      [ dl[0]   d[1]    du[1]                       ]
+  A = [         dl[1]   d[2]    ...                 ]
+      [                 ...     ...         du[n-2] ]
+      [ du[n-1]                 dl[n-2]     d[n-1]  ]
+
+ tridiagonal but for the corner elements A[0,n-1] = dl[n-1] and
+ A[n-1,0] = du[n-1], as from periodic boundary conditions. Writing
+ A = T + u*v^T with T tridiagonal, the Sherman-Morrison formula gives
+
+  X = Y - Z*(v^T*Y)/(1 + v^T*Z),  T*Y = B,  T*Z = u,
+
+ with one factorization of T by Gtrrf for the n by nrhs+1 right hand
+ sides. The corners are taken into u and v as in Numerical Recipes with
+ u[0] = -d[0], so that T is as well conditioned as A for diagonally
+ dominant A. On exit B is replaced by the solution X; DL, D and DU are
+ not changed.
+
+ ARGUMENTS
+  DL        float vector of length n; sub-diagonal and A[0,n-1]
+  D         float vector of length n; diagonal
+  DU        float vector of length n; super-diagonal and A[n-1,0]
+  B         float matrix, n by nrhs
+*/
+func PeriodicTridiagSolve(DL, D, DU, B *matrix.FloatMatrix) error {
+	n, nrhs := D.NumElements(), B.Cols()
+	if DL.NumElements() != n || DU.NumElements() != n || B.Rows() != n {
+		return onError(linalg.ErrShape,
+			fmt.Sprintf("PeriodicTridiagSolve: D has %d elements, DL %d, DU %d, B %d rows",
+				n, DL.NumElements(), DU.NumElements(), B.Rows()))
+	}
+	if n < 3 {
+		return onError(linalg.ErrShape, "PeriodicTridiagSolve: n < 3")
+	}
+	if nrhs == 0 {
+		return nil
+	}
+	dl, d, du := DL.FloatArray(), D.FloatArray(), DU.FloatArray()
+	top, bottom := dl[n-1], du[n-1]
+	gamma := -d[0]
+	if gamma == 0.0 {
+		gamma = -1.0
+	}
+	// T = A - u*v^T, u = [gamma, 0, ..., bottom], v = [1, 0, ..., top/gamma]
+	Tdl := matrix.FloatVector(append([]float64{}, dl[:n-1]...))
+	Td := matrix.FloatVector(append([]float64{}, d[:n]...))
+	Tdu := matrix.FloatVector(append([]float64{}, du[:n-1]...))
+	Td.SetIndex(0, d[0]-gamma)
+	Td.SetIndex(n-1, d[n-1]-bottom*top/gamma)
+	du2 := matrix.FloatZeros(n-2, 1)
+	alloc := linalg.GetAllocator()
+	ipiv := alloc.Int32s(n)
+	defer alloc.Free(ipiv)
+	if err := Gtrrf(Tdl, Td, Tdu, du2, ipiv); err != nil {
+		return err
+	}
+	R := matrix.FloatZeros(n, nrhs+1)
+	R.SetSubMatrix(0, 0, B)
+	R.SetAt(0, nrhs, gamma)
+	R.SetAt(n-1, nrhs, bottom)
+	if err := Gtrrs(Tdl, Td, Tdu, du2, R, ipiv); err != nil {
+		return err
+	}
+	vz := R.GetAt(0, nrhs) + top/gamma*R.GetAt(n-1, nrhs)
+	if 1.0+vz == 0.0 || math.IsNaN(vz) {
+		return onError(linalg.ErrSingular, "PeriodicTridiagSolve: singular correction")
+	}
+	for j := 0; j < nrhs; j++ {
+		f := (R.GetAt(0, j) + top/gamma*R.GetAt(n-1, j)) / (1.0 + vz)
+		for i := 0; i < n; i++ {
+			B.SetAt(i, j, R.GetAt(i, j)-f*R.GetAt(i, nrhs))
+		}
+	}
+	return nil
+}
+
+/*
+ Solves a real cyclic banded set of linear equations.
+
+ PURPOSE
+ Solves A*X = B for n by n A with kl subdiagonals and ku superdiagonals
+ that wrap around: A[i,j] is nonzero only if (i-j) mod n <= kl or
+ (j-i) mod n <= ku. S holds A in cyclic band storage, kl+ku+1 by n with
+ A[(j+k) mod n, j] in row ku+k of column j for -ku <= k <= kl; without
+ the wrapped elements this is the BLAS band storage of matops.BandMatrix.
+
+ The wrapped elements lie in rows 0 to kl-1 and n-ku to n-1 and make A a
+ rank kl+ku correction A = T + U*W of the band matrix T. By the Woodbury
+ formula
+
+  X = Y - Z*inv(I + W*Z)*W*Y,  T*Y = B,  T*Z = U,
+
+ with one band factorization of T by Gbsv and a small dense solve of
+ order kl+ku. T must be nonsingular, which holds for diagonally dominant
+ A. On exit B is replaced by the solution X; S is not changed.
+
+ ARGUMENTS
+  S         float matrix, kl+ku+1 by n; cyclic band storage of A
+  kl        nonnegative integer
+  ku        nonnegative integer, n > kl+ku
+  B         float matrix, n by nrhs
+*/
+func CyclicBandSolve(S *matrix.FloatMatrix, kl, ku int, B *matrix.FloatMatrix) error {
+	n, nrhs := S.Cols(), B.Cols()
+	if kl < 0 || ku < 0 {
+		return onError(linalg.ErrParameter, "CyclicBandSolve: negative bandwidth")
+	}
+	if S.Rows() != kl+ku+1 || B.Rows() != n {
+		return onError(linalg.ErrShape,
+			fmt.Sprintf("CyclicBandSolve: S is %d by %d, want %d rows, B has %d rows",
+				S.Rows(), n, kl+ku+1, B.Rows()))
+	}
+	if n <= kl+ku {
+		return onError(linalg.ErrShape, "CyclicBandSolve: n <= kl+ku")
+	}
+	if nrhs == 0 {
+		return nil
+	}
+	r := kl + ku
+	// rows of the wrapped elements and their index in W
+	rows := make([]int, 0, r)
+	for i := 0; i < kl; i++ {
+		rows = append(rows, i)
+	}
+	for i := n - ku; i < n; i++ {
+		rows = append(rows, i)
+	}
+	index := make(map[int]int, r)
+	for p, i := range rows {
+		index[i] = p
+	}
+	// T in Gbsv storage with kl extra rows for fill-in; W holds the rest
+	T := matrix.FloatZeros(2*kl+ku+1, n)
+	W := matrix.FloatZeros(r, n)
+	for j := 0; j < n; j++ {
+		for k := -ku; k <= kl; k++ {
+			v := S.GetAt(ku+k, j)
+			i := j + k
+			switch {
+			case i < 0:
+				W.SetAt(index[i+n], j, v)
+			case i >= n:
+				W.SetAt(index[i-n], j, v)
+			default:
+				T.SetAt(kl+ku+k, j, v)
+			}
+		}
+	}
+	R := matrix.FloatZeros(n, nrhs+r)
+	R.SetSubMatrix(0, 0, B)
+	for p, i := range rows {
+		R.SetAt(i, nrhs+p, 1.0)
+	}
+	alloc := linalg.GetAllocator()
+	ipiv := alloc.Int32s(n)
+	defer alloc.Free(ipiv)
+	err := Gbsv(T, R, ipiv, kl, linalg.IntOpt("ku", ku), linalg.IntOpt("n", n),
+		linalg.IntOpt("nrhs", nrhs+r))
+	if err != nil {
+		return err
+	}
+	if r == 0 {
+		B.SetSubMatrix(0, 0, R)
+		return nil
+	}
+	Y := R.GetSubMatrix(0, 0, n, nrhs)
+	Z := R.GetSubMatrix(0, nrhs, n, r)
+	// M = I + W*Z, C = W*Y; solve M*C := C
+	M := matrix.FloatIdentity(r)
+	C := matrix.FloatZeros(r, nrhs)
+	one := matrix.FScalar(1.0)
+	if err = blas.Gemm(W, Z, M, one, one); err != nil {
+		return err
+	}
+	if err = blas.Gemm(W, Y, C, one, matrix.FScalar(0.0)); err != nil {
+		return err
+	}
+	f, err := NewLU(M)
+	if err != nil {
+		return onError(linalg.ErrSingular, "CyclicBandSolve: singular correction")
+	}
+	if err = f.Solve(C); err != nil {
+		return err
+	}
+	if err = blas.Gemm(Z, C, Y, matrix.FScalar(-1.0), one); err != nil {
+		return err
+	}
+	B.SetSubMatrix(0, 0, Y)
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
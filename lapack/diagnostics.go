@@ -0,0 +1,133 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"math"
+)
+
+// Pivot ratio or reciprocal condition number below which logged operations
+// warn of a nearly singular matrix; see linalg.SetLogger.
+const nearSingular = 1e-12
+
+// Stability diagnostics of an LU or Cholesky factorization, filled in by
+// Getrf and Potrf for real matrices when given the option returned by
+// WithDiagnostics.
+//
+// For LU, Growth is the pivot growth factor max_j(max|U(:,j)| / max|A(:,j)|),
+// the reciprocal of what LAPACK's xLA_GERPVGRW returns. Values much larger
+// than one mean that partial pivoting was numerically marginal and the
+// factors may be inaccurate. For Cholesky, Growth is max|L|^2 / max|A|,
+// which is at most one in exact arithmetic.
+//
+// Pivots are the diagonal elements of U or L. MinPivot and MaxPivot are the
+// smallest and largest pivot in absolute value and PivotRatio is
+// MinPivot/MaxPivot, a cheap indicator of ill-conditioning; it is zero for a
+// singular LU factor.
+type PivotDiagnostics struct {
+	Growth     float64
+	MinPivot   float64
+	MaxPivot   float64
+	PivotRatio float64
+}
+
+// Option that carries a pointer to diagnostics to fill.
+type diagnosticsOpt struct {
+	linalg.Option
+	d *PivotDiagnostics
+}
+
+// Return option that makes Getrf and Potrf fill in d.
+func WithDiagnostics(d *PivotDiagnostics) linalg.Option {
+	return &diagnosticsOpt{linalg.BoolOpt("diagnostics", true), d}
+}
+
+// Return diagnostics requested in options or nil.
+func getDiagnostics(opts ...linalg.Option) *PivotDiagnostics {
+	for _, o := range opts {
+		if d, ok := o.(*diagnosticsOpt); ok {
+			return d.d
+		}
+	}
+	return nil
+}
+
+// Return maximum absolute value of each of n columns of m by n array.
+func columnMax(A []float64, m, n, lda int) []float64 {
+	cmax := make([]float64, n)
+	for j := range cmax {
+		for _, v := range A[j*lda : j*lda+m] {
+			cmax[j] = math.Max(cmax[j], math.Abs(v))
+		}
+	}
+	return cmax
+}
+
+// Fill d from m by n LU factors in A, given column maxima of the original.
+func luDiagnostics(d *PivotDiagnostics, A []float64, m, n, lda int, cmax []float64) {
+	*d = PivotDiagnostics{MinPivot: math.Inf(1)}
+	for j := 0; j < n; j++ {
+		umax := 0.0
+		for _, v := range A[j*lda : j*lda+min(j+1, m)] {
+			umax = math.Max(umax, math.Abs(v))
+		}
+		if cmax[j] > 0.0 {
+			d.Growth = math.Max(d.Growth, umax/cmax[j])
+		}
+		if j < m {
+			p := math.Abs(A[j*lda+j])
+			d.MinPivot = math.Min(d.MinPivot, p)
+			d.MaxPivot = math.Max(d.MaxPivot, p)
+		}
+	}
+	setPivotRatio(d)
+}
+
+// Fill d from n by n Cholesky factor in lower (uplo "L") or upper triangle
+// of A, given maximum absolute value of the original.
+func cholDiagnostics(d *PivotDiagnostics, A []float64, n, lda int, uplo string, amax float64) {
+	*d = PivotDiagnostics{MinPivot: math.Inf(1)}
+	lmax := 0.0
+	for j := 0; j < n; j++ {
+		i0, i1 := j, n
+		if uplo == "U" {
+			i0, i1 = 0, j+1
+		}
+		for _, v := range A[j*lda+i0 : j*lda+i1] {
+			lmax = math.Max(lmax, math.Abs(v))
+		}
+		p := math.Abs(A[j*lda+j])
+		d.MinPivot = math.Min(d.MinPivot, p)
+		d.MaxPivot = math.Max(d.MaxPivot, p)
+	}
+	if amax > 0.0 {
+		d.Growth = lmax * lmax / amax
+	}
+	setPivotRatio(d)
+}
+
+// Add warning to op if diagnostics in d indicate a nearly singular factor.
+func warnPivots(op *linalg.Op, d *PivotDiagnostics) {
+	if op.Enabled() && d.PivotRatio < nearSingular {
+		op.Warn(fmt.Sprintf("near-singular factor: pivot ratio %.3g", d.PivotRatio))
+	}
+}
+
+func setPivotRatio(d *PivotDiagnostics) {
+	if math.IsInf(d.MinPivot, 1) {
+		d.MinPivot = 0.0
+	}
+	if d.MaxPivot > 0.0 {
+		d.PivotRatio = d.MinPivot / d.MaxPivot
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
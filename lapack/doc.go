@@ -27,6 +27,10 @@
 //
 // If a routine from the LAPACK library returns with a non zero 'info'
 // value function returns with non-nil error with 'info' value included in
-// error string.
+// error string. Returned errors are of type *linalg.Error and wrap one of
+// the linalg error classes; for example a singular matrix in Gesv can be
+// detected with errors.Is(err, linalg.ErrSingular) and a failed Cholesky
+// factorization in Potrf with errors.Is(err, linalg.ErrNotPositiveDefinite).
+// The 'info' value is available in field Info of linalg.Error.
 
 package lapack
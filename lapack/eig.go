@@ -0,0 +1,184 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"math/cmplx"
+)
+
+// Eigenvalues, right eigenvectors and eigenvalue condition numbers of a
+// general real matrix.
+//
+// Vectors holds the right eigenvectors as columns, normalized to unit
+// 2-norm. Cond[k] is 1/|y^H*x| for the unit right and left eigenvectors x
+// and y of eigenvalue k, the factor by which a perturbation of A of norm
+// e may move Values[k] to first order. Cond[k] is one for eigenvalues of a
+// normal matrix and large for nearly defective ones.
+//
+// Verification holds the eigenpair residuals ||A*v - lambda*v|| relative to
+// ||A||*||v|| if verification is enabled, see Verification, and is nil
+// otherwise.
+type EigResult struct {
+	Values       *matrix.ComplexMatrix
+	Vectors      *matrix.ComplexMatrix
+	Cond         *matrix.FloatMatrix
+	Verification *Verification
+}
+
+// Eigenvalues, eigenvectors and eigenvector condition numbers of a real
+// symmetric matrix.
+//
+// Values are in increasing order and Vectors are orthonormal. Eigenvalues
+// of a symmetric matrix are perfectly conditioned, with absolute error of
+// about eps*norm(A). Sep[k] is the Disna reciprocal condition number of
+// eigenvector k, the gap to the nearest other eigenvalue; the computed
+// vector has an angular error of about eps*norm(A)/Sep[k]. Verification
+// is as in EigResult.
+type SymEigResult struct {
+	Values       *matrix.FloatMatrix
+	Vectors      *matrix.FloatMatrix
+	Sep          *matrix.FloatMatrix
+	Verification *Verification
+}
+
+// Compute eigenvalues, right eigenvectors and eigenvalue condition numbers
+// of n by n float matrix A with Geev. A is not changed.
+//
+// If the QR iteration fails the error is ErrNoConvergence and the partial
+// result is returned: linalg.Unconverged lists the eigenvalues that were
+// not computed, which are NaN in Values, and Vectors and Cond are nil.
+func Eig(A *matrix.FloatMatrix, opts ...linalg.Option) (res *EigResult, err error) {
+	n := A.Rows()
+	op := linalg.StartOp("lapack.Eig", n)
+	defer op.Finish(&err)
+	defer guard("Eig", &err)()
+	if A.Cols() != n {
+		return nil, onError(linalg.ErrShape, "Eig: A not square")
+	}
+	if err = checkMemory("eig", opts, n); err != nil {
+		return nil, err
+	}
+	res = &EigResult{
+		Values:  matrix.ComplexZeros(n, 1),
+		Vectors: matrix.ComplexZeros(n, n),
+		Cond:    matrix.FloatZeros(n, 1),
+	}
+	if n == 0 {
+		return res, nil
+	}
+	Ac := A.Copy()
+	alloc := linalg.GetAllocator()
+	wr := alloc.Float64s(n)
+	defer alloc.Free(wr)
+	wi := alloc.Float64s(n)
+	defer alloc.Free(wi)
+	VL := alloc.Float64s(n * n)
+	defer alloc.Free(VL)
+	VR := alloc.Float64s(n * n)
+	defer alloc.Free(VR)
+	info := dgeev("V", "V", n, Ac.FloatArray(), max(1, Ac.LeadingIndex()), wr, wi, VL, n, VR, n)
+	if info > 0 && info <= n {
+		// QR iteration failed; eigenvalues info:n have converged
+		unconverged := make([]int, info)
+		for k := 0; k < n; k++ {
+			if k < info {
+				unconverged[k] = k
+				res.Values.SetAt(k, 0, cmplx.NaN())
+			} else {
+				res.Values.SetAt(k, 0, complex(wr[k], wi[k]))
+			}
+		}
+		res.Vectors, res.Cond = nil, nil
+		return res, onConvergenceError("Eig", info, unconverged)
+	}
+	if info != 0 {
+		return nil, onLapackError("Eig", info, linalg.ErrNoConvergence)
+	}
+	// complex pair (k, k+1) is stored as real and imaginary parts in
+	// columns k and k+1 of VL and VR
+	for k := 0; k < n; k++ {
+		res.Values.SetAt(k, 0, complex(wr[k], wi[k]))
+		if wi[k] == 0.0 {
+			s := 0.0
+			for i := 0; i < n; i++ {
+				res.Vectors.SetAt(i, k, complex(VR[k*n+i], 0))
+				s += VL[k*n+i] * VR[k*n+i]
+			}
+			res.Cond.SetAt(k, 0, 1.0/math.Abs(s))
+			continue
+		}
+		res.Values.SetAt(k+1, 0, complex(wr[k+1], wi[k+1]))
+		// y^H*x = (a - ib)^T*(c + id)
+		var sre, sim float64
+		for i := 0; i < n; i++ {
+			a, b := VL[k*n+i], VL[(k+1)*n+i]
+			c, d := VR[k*n+i], VR[(k+1)*n+i]
+			res.Vectors.SetAt(i, k, complex(c, d))
+			res.Vectors.SetAt(i, k+1, complex(c, -d))
+			sre += a*c + b*d
+			sim += a*d - b*c
+		}
+		cond := 1.0 / math.Hypot(sre, sim)
+		res.Cond.SetAt(k, 0, cond)
+		res.Cond.SetAt(k+1, 0, cond)
+		k++
+	}
+	if v := startVerify(opts...); v != nil {
+		verifyEig(v, A, res.Values, res.Vectors)
+		v.report(op, "eigenpair")
+		res.Verification = v
+	}
+	return res, nil
+}
+
+// Compute eigenvalues, eigenvectors and eigenvector condition numbers of
+// symmetric float matrix A with Syevd and Disna. A is not changed.
+//
+// Options:
+//
+//	uplo    PLower or PUpper, the triangle of A referenced
+//	verify  float, residual threshold of verification
+func EigSym(A *matrix.FloatMatrix, opts ...linalg.Option) (res *SymEigResult, err error) {
+	n := A.Rows()
+	op := linalg.StartOp("lapack.EigSym", n)
+	defer op.Finish(&err)
+	if A.Cols() != n {
+		return nil, onError(linalg.ErrShape, "EigSym: A not square")
+	}
+	res = &SymEigResult{
+		Values:  matrix.FloatZeros(n, 1),
+		Vectors: A.Copy(),
+		Sep:     matrix.FloatZeros(n, 1),
+	}
+	if n == 0 {
+		return res, nil
+	}
+	uplo := linalg.GetParam("uplo", opts...)
+	if uplo < 0 {
+		uplo = linalg.PLower
+	}
+	err = SyevdFloat(res.Vectors, res.Values, linalg.IntOpt("uplo", uplo), linalg.OptJobZValue)
+	if err != nil {
+		return nil, err
+	}
+	if err = Disna(res.Values, res.Sep); err != nil {
+		return nil, err
+	}
+	if v := startVerify(opts...); v != nil {
+		verifyEigSym(v, symmetrize(A, uplo), res.Values, res.Vectors)
+		v.report(op, "eigenpair")
+		res.Verification = v
+	}
+	return res, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
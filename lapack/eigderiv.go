@@ -0,0 +1,128 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// First order perturbation theory of the symmetric eigenproblem. Given the
+// decomposition A = V*diag(w)*V^T computed by EigSym and a symmetric
+// direction dA, the derivatives of w and V along A + t*dA at t = 0 follow
+// from M = V^T*dA*V: dw[k] = M[k,k] and column k of dV is
+// sum_{j != k} M[j,k]/(w[k]-w[j]) * V[:,j]. Only the symmetric part
+// (dA+dA^T)/2 of dA is used.
+//
+// Eigenvalues closer than tol are treated as one cluster. Within a cluster
+// the eigenvalues split along the eigenvalues of the block of M for the
+// cluster, which are returned as one-sided derivatives (t -> 0+) in
+// increasing order. Eigenvectors of a cluster are not differentiable.
+//
+// Options:
+//
+//	tol     float; cluster tolerance.  Default n*eps*max|w|.
+
+// Return derivatives of eigenvalues of symmetric A in direction dA, see
+// above. Cost is O(n^3).
+func (res *SymEigResult) ValueDerivatives(dA *matrix.FloatMatrix, opts ...linalg.Option) (*matrix.FloatMatrix, error) {
+	M, err := res.projectPerturbation("ValueDerivatives", dA)
+	if err != nil {
+		return nil, err
+	}
+	n := M.Rows()
+	dW := matrix.FloatZeros(n, 1)
+	for _, c := range res.clusters(opts...) {
+		k, size := c[0], c[1]
+		if size == 1 {
+			dW.SetAt(k, 0, M.GetAt(k, k))
+			continue
+		}
+		B := M.GetSubMatrix(k, k, size, size)
+		mu := matrix.FloatZeros(size, 1)
+		if err = SyevdFloat(B, mu); err != nil {
+			return nil, err
+		}
+		for i := 0; i < size; i++ {
+			dW.SetAt(k+i, 0, mu.GetAt(i, 0))
+		}
+	}
+	return dW, nil
+}
+
+// Return derivatives of eigenvectors of symmetric A in direction dA as
+// columns of an n by n matrix, see above. The derivatives keep the
+// vectors at unit length and are orthogonal to them. Returns an error
+// wrapping linalg.ErrSingular if A has a cluster of eigenvalues.
+func (res *SymEigResult) VectorDerivatives(dA *matrix.FloatMatrix, opts ...linalg.Option) (*matrix.FloatMatrix, error) {
+	M, err := res.projectPerturbation("VectorDerivatives", dA)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range res.clusters(opts...) {
+		if c[1] > 1 {
+			return nil, onError(linalg.ErrSingular,
+				fmt.Sprintf("VectorDerivatives: eigenvalues %d to %d coincide", c[0], c[0]+c[1]-1))
+		}
+	}
+	n := M.Rows()
+	// F[j,k] = M[j,k]/(w[k]-w[j]), coefficients of dV in the basis V
+	F := matrix.FloatZeros(n, n)
+	for k := 0; k < n; k++ {
+		wk := res.Values.GetAt(k, 0)
+		for j := 0; j < n; j++ {
+			if j != k {
+				F.SetAt(j, k, M.GetAt(j, k)/(wk-res.Values.GetAt(j, 0)))
+			}
+		}
+	}
+	return matrix.Times(res.Vectors, F), nil
+}
+
+// Return V^T*S*V for the symmetric part S of dA.
+func (res *SymEigResult) projectPerturbation(name string, dA *matrix.FloatMatrix) (*matrix.FloatMatrix, error) {
+	n := res.Values.NumElements()
+	if dA.Rows() != n || dA.Cols() != n {
+		return nil, onError(linalg.ErrShape, name+": dA not n by n")
+	}
+	V := res.Vectors
+	M := matrix.Times(V.Transpose(), matrix.Times(dA, V))
+	for j := 0; j < n; j++ {
+		for i := 0; i < j; i++ {
+			s := 0.5 * (M.GetAt(i, j) + M.GetAt(j, i))
+			M.SetAt(i, j, s)
+			M.SetAt(j, i, s)
+		}
+	}
+	return M, nil
+}
+
+// Return clusters of eigenvalues as pairs of first index and size.
+func (res *SymEigResult) clusters(opts ...linalg.Option) [][2]int {
+	n := res.Values.NumElements()
+	if n == 0 {
+		return nil
+	}
+	w := res.Values.FloatArray()
+	wmax := math.Max(math.Abs(w[0]), math.Abs(w[n-1]))
+	tol := linalg.GetFloatOpt("tol", float64(n)*0x1p-52*wmax, opts...)
+	var cl [][2]int
+	start := 0
+	for k := 1; k <= n; k++ {
+		if k == n || w[k]-w[k-1] > tol {
+			cl = append(cl, [2]int{start, k - start})
+			start = k
+		}
+	}
+	return cl
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
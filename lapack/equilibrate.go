@@ -0,0 +1,203 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Ratio of smallest to largest scale factor below which rows or columns
+// are equilibrated, as in LAPACK's dlaqge.
+const equilibrateThreshold = 0.1
+
+// Report of the equilibration done by Solve and Lstsq.
+//
+// If Applied is true, the problem was solved for diag(R)*A*diag(C), with
+// R and C the row and column scale factors; R is nil if only columns were
+// scaled and C is nil if only rows were. The factors are powers of two,
+// so scaling itself introduces no rounding error.
+//
+// RCondBefore and RCondAfter are estimates of the reciprocal 1-norm
+// condition number of A and of the scaled matrix, equal if no scaling was
+// applied. Trusted is false if RCondAfter is below 1e-12 or the solution
+// is not finite; the result should then not be relied on.
+type Equilibration struct {
+	Applied     bool
+	R, C        []float64
+	RCondBefore float64
+	RCondAfter  float64
+	Trusted     bool
+}
+
+// Solve square system A*X = B for Solve with equilibration, see Solve.
+func solveSquare(op *linalg.Op, A, B *matrix.FloatMatrix, opts ...linalg.Option) (X *matrix.FloatMatrix, eq *Equilibration, err error) {
+	n, nrhs := A.Rows(), B.Cols()
+	eq = &Equilibration{RCondBefore: 1.0, RCondAfter: 1.0, Trusted: true}
+	if n == 0 || nrhs == 0 {
+		return B.Copy(), eq, nil
+	}
+	r, c, rowcnd, colcnd, amax := equilibrationFactors(A, true)
+	if linalg.GetBoolOpt("equilibrate", true, opts...) {
+		if rowcnd < equilibrateThreshold || amax < safeMin || amax > 1.0/safeMin {
+			eq.R = r
+		} else {
+			// column factors of the matrix with unscaled rows
+			_, c, _, colcnd, _ = equilibrationFactors(A, false)
+		}
+		if colcnd < equilibrateThreshold {
+			eq.C = c
+		}
+	}
+	eq.Applied = eq.R != nil || eq.C != nil
+	LU := A.Copy()
+	X = B.Copy()
+	scaleRowsCols(LU, eq.R, eq.C)
+	scaleRowsCols(X, eq.R, nil)
+	alloc := linalg.GetAllocator()
+	ipiv := alloc.Int32s(n)
+	defer alloc.Free(ipiv)
+	eq.RCondAfter, err = luRCond("Solve", LU, ipiv)
+	if err != nil {
+		eq.RCondBefore, eq.RCondAfter, eq.Trusted = 0.0, 0.0, false
+		return nil, eq, err
+	}
+	eq.RCondBefore = eq.RCondAfter
+	if eq.Applied {
+		// singular A is reported only through RCondBefore
+		ipiv0 := alloc.Int32s(n)
+		defer alloc.Free(ipiv0)
+		eq.RCondBefore, _ = luRCond("Solve", A.Copy(), ipiv0)
+	}
+	if err = Getrs(LU, X, ipiv); err != nil {
+		return nil, eq, err
+	}
+	scaleRowsCols(X, eq.C, nil)
+	eq.Trusted = eq.RCondAfter >= nearSingular && allFinite(X)
+	if !eq.Trusted {
+		op.Warn(fmt.Sprintf("result not trusted: rcond %.3g", eq.RCondAfter))
+	}
+	return X, eq, nil
+}
+
+// Smallest number with a finite reciprocal, divided by machine epsilon as
+// in dlaqge.
+var safeMin = 0x1p-1022 / 0x1p-53
+
+// Return row and (if rows is false, unscaled) column scale factors making
+// the largest absolute value in each row and column of A between 1 and 2,
+// with ratios of smallest to largest factor and largest absolute value of
+// A. The factors of zero rows or columns are one.
+func equilibrationFactors(A *matrix.FloatMatrix, rows bool) (r, c []float64, rowcnd, colcnd, amax float64) {
+	m, n := A.Rows(), A.Cols()
+	r = make([]float64, m)
+	c = make([]float64, n)
+	for i := range r {
+		r[i] = 1.0
+	}
+	rowcnd, colcnd = 1.0, 1.0
+	if rows {
+		rmax := make([]float64, m)
+		for j := 0; j < n; j++ {
+			for i := 0; i < m; i++ {
+				rmax[i] = math.Max(rmax[i], math.Abs(A.GetAt(i, j)))
+			}
+		}
+		for i, v := range rmax {
+			amax = math.Max(amax, v)
+			r[i] = powerOfTwoInverse(v)
+		}
+		rowcnd = factorRatio(r)
+	}
+	for j := 0; j < n; j++ {
+		cmax := 0.0
+		for i := 0; i < m; i++ {
+			cmax = math.Max(cmax, r[i]*math.Abs(A.GetAt(i, j)))
+			if !rows {
+				amax = math.Max(amax, math.Abs(A.GetAt(i, j)))
+			}
+		}
+		c[j] = powerOfTwoInverse(cmax)
+	}
+	colcnd = factorRatio(c)
+	return
+}
+
+// Return power of two p with p*v in [1, 2), or 1 if v is zero or not finite.
+func powerOfTwoInverse(v float64) float64 {
+	if v == 0.0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		return 1.0
+	}
+	return math.Ldexp(1.0, -math.Ilogb(v))
+}
+
+// Return ratio of smallest to largest element of s.
+func factorRatio(s []float64) float64 {
+	smin, smax := math.Inf(1), 0.0
+	for _, v := range s {
+		smin = math.Min(smin, v)
+		smax = math.Max(smax, v)
+	}
+	if smax == 0.0 {
+		return 1.0
+	}
+	return smin / smax
+}
+
+// Scale rows of A with r and columns with c; nil means no scaling.
+func scaleRowsCols(A *matrix.FloatMatrix, r, c []float64) {
+	for j := 0; j < A.Cols(); j++ {
+		for i := 0; i < A.Rows(); i++ {
+			v := A.GetAt(i, j)
+			if r != nil {
+				v *= r[i]
+			}
+			if c != nil {
+				v *= c[j]
+			}
+			A.SetAt(i, j, v)
+		}
+	}
+}
+
+// Factor square A in place with Getrf and return reciprocal 1-norm
+// condition number estimate of A.
+func luRCond(name string, A *matrix.FloatMatrix, ipiv []int32) (float64, error) {
+	n := A.Rows()
+	anorm := 0.0
+	for j := 0; j < n; j++ {
+		s := 0.0
+		for i := 0; i < n; i++ {
+			s += math.Abs(A.GetAt(i, j))
+		}
+		anorm = math.Max(anorm, s)
+	}
+	if err := Getrf(A, ipiv); err != nil {
+		return 0.0, err
+	}
+	rcond, info := dgecon("1", n, A.FloatArray(), max(1, A.LeadingIndex()), anorm)
+	if info != 0 {
+		return 0.0, onLapackError(name, info, linalg.ErrParameter)
+	}
+	return rcond, nil
+}
+
+// Return true if all elements of A are finite.
+func allFinite(A *matrix.FloatMatrix) bool {
+	for _, v := range A.FloatArray() {
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,229 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"sync"
+)
+
+// Factorization kinds of Factor.
+const (
+	FactorLU       = iota // P*A = L*U by Getrf
+	FactorCholesky        // A = L*L^T or U^T*U by Potrf
+)
+
+// LU or Cholesky factorization of a square float matrix, reusable for any
+// number of solves. A Factor is not modified by Solve and may be shared
+// between goroutines.
+type Factor struct {
+	Kind int
+	// Factors as computed by Getrf or Potrf.
+	F    *matrix.FloatMatrix
+	ipiv []int32
+	uplo int
+}
+
+// Compute LU factorization of A. A is not changed.
+func NewLU(A *matrix.FloatMatrix) (*Factor, error) {
+	if A.Rows() != A.Cols() {
+		return nil, onError(linalg.ErrShape, "NewLU: A not square")
+	}
+	f := &Factor{Kind: FactorLU, F: A.Copy(), ipiv: make([]int32, A.Rows())}
+	if err := Getrf(f.F, f.ipiv); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Compute Cholesky factorization of symmetric positive definite A using
+// triangle uplo (option, PLower by default). A is not changed.
+func NewCholesky(A *matrix.FloatMatrix, opts ...linalg.Option) (*Factor, error) {
+	if A.Rows() != A.Cols() {
+		return nil, onError(linalg.ErrShape, "NewCholesky: A not square")
+	}
+	pars, err := linalg.GetParameters(opts...)
+	if err != nil {
+		return nil, err
+	}
+	f := &Factor{Kind: FactorCholesky, F: A.Copy(), uplo: pars.Uplo}
+	if err := PotrfFloat(f.F, linalg.IntOpt("uplo", f.uplo)); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Solve A*X = B; on exit B is overwritten with X.
+func (f *Factor) Solve(B *matrix.FloatMatrix) error {
+	if f.Kind == FactorCholesky {
+		return Potrs(f.F, B, linalg.IntOpt("uplo", f.uplo))
+	}
+	return Getrs(f.F, B, f.ipiv)
+}
+
+/*
+ Cache of factorizations keyed by matrix content.
+
+ PURPOSE
+ Programs that solve with the same matrix in unrelated places, eg. the
+ stages of a simulation where the system matrix changes rarely, can
+ share one factorization through a cache instead of threading factor
+ objects through their code.  The key of a matrix is a SHA-256 hash of its
+ size and elements, so an unchanged matrix is found even if it is a
+ different copy.  Hashing reads every element once, which is cheap
+ compared with a factorization but not free; with the 'key' option the
+ caller names the matrix and hashing is skipped, in which case the caller
+ must invalidate the key when the matrix changes.
+
+ The cache holds at most capacity factorizations and evicts the least
+ recently used.  All methods are safe for concurrent use.
+
+*/
+type FactorCache struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	lru      *list.List
+	hits     int
+	misses   int
+}
+
+type cacheEntry struct {
+	key    string
+	factor *Factor
+}
+
+// Create cache for at most capacity factorizations.
+func NewFactorCache(capacity int) *FactorCache {
+	return &FactorCache{
+		capacity: max(1, capacity),
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// Return content key of A, as used by the cache without the 'key' option.
+func MatrixKey(A *matrix.FloatMatrix) string {
+	h := sha256.New()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(A.Rows()))
+	h.Write(buf[:])
+	binary.LittleEndian.PutUint64(buf[:], uint64(A.Cols()))
+	h.Write(buf[:])
+	for j := 0; j < A.Cols(); j++ {
+		for i := 0; i < A.Rows(); i++ {
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(A.GetAt(i, j)))
+			h.Write(buf[:])
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Return cached LU factorization of A, computing it on a miss.
+//
+// Options:
+//
+//	key     string naming A instead of its content hash
+func (c *FactorCache) LU(A *matrix.FloatMatrix, opts ...linalg.Option) (*Factor, error) {
+	return c.get("lu:"+c.key(A, opts...), func() (*Factor, error) {
+		return NewLU(A)
+	})
+}
+
+// Return cached Cholesky factorization of A, computing it on a miss.
+//
+// Options:
+//
+//	key     string naming A instead of its content hash
+//	uplo    PLower (default) or PUpper
+func (c *FactorCache) Cholesky(A *matrix.FloatMatrix, opts ...linalg.Option) (*Factor, error) {
+	uplo := "L:"
+	if linalg.GetParam("uplo", opts...) == linalg.PUpper {
+		uplo = "U:"
+	}
+	return c.get("chol"+uplo+c.key(A, opts...), func() (*Factor, error) {
+		return NewCholesky(A, opts...)
+	})
+}
+
+// Remove all factorizations of the matrix with key, a 'key' option value
+// or a MatrixKey result.
+func (c *FactorCache) Invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, prefix := range []string{"lu:", "cholL:", "cholU:"} {
+		if e, ok := c.entries[prefix+key]; ok {
+			c.lru.Remove(e)
+			delete(c.entries, prefix+key)
+		}
+	}
+}
+
+// Remove all factorizations.
+func (c *FactorCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+}
+
+// Return number of cached factorizations and hit and miss counts.
+func (c *FactorCache) Stats() (size, hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len(), c.hits, c.misses
+}
+
+func (c *FactorCache) key(A *matrix.FloatMatrix, opts ...linalg.Option) string {
+	if k := linalg.GetStringOpt("key", "", opts...); k != "" {
+		return k
+	}
+	return MatrixKey(A)
+}
+
+// Return factorization for key, computing it with factor on a miss. The
+// factorization runs without the lock held so that other keys are not
+// blocked; concurrent misses on one key may factor twice.
+func (c *FactorCache) get(key string, factor func() (*Factor, error)) (*Factor, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		c.hits++
+		c.mu.Unlock()
+		return e.Value.(*cacheEntry).factor, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	f, err := factor()
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*cacheEntry).factor, nil
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key, f})
+	for c.lru.Len() > c.capacity {
+		e := c.lru.Back()
+		c.lru.Remove(e)
+		delete(c.entries, e.Value.(*cacheEntry).key)
+	}
+	return f, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -8,7 +8,6 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
 )
@@ -53,9 +52,13 @@ import (
   offsetB   nonnegative integer;
 
 */
-func Gbsv(A, B matrix.Matrix, ipiv []int32, kl int, opts ...linalg.Option) error {
+func Gbsv(A, B matrix.Matrix, ipiv []int32, kl int, opts ...linalg.Option) (err error) {
+	defer guard("Gbsv", &err)()
+	if err = writable("Gbsv", A, B); err != nil {
+		return
+	}
 	if !matrix.EqualTypes(A, B) {
-		return onError("Gbsv: not same type")
+		return onError(linalg.ErrType, "Gbsv: not same type")
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
@@ -67,14 +70,15 @@ func Gbsv(A, B matrix.Matrix, ipiv []int32, kl int, opts ...linalg.Option) error
 		Bm := B.(*matrix.ComplexMatrix)
 		return GbsvComplex(Am, Bm, ipiv, kl, opts...)
 	}
-	return onError("Gbsv: unknown types types!")
+	return onError(linalg.ErrType, "Gbsv: unknown types types!")
 }
 
-func GbsvFloat(A, B *matrix.FloatMatrix, ipiv []int32, kl int, opts ...linalg.Option) error {
+func GbsvFloat(A, B *matrix.FloatMatrix, ipiv []int32, kl int, opts ...linalg.Option) (err error) {
+	defer guard("GbsvFloat", &err)()
 
 	ind := linalg.GetIndexOpts(opts...)
 	ind.Kl = kl
-	err := checkGbsv(ind, A, B, ipiv)
+	err = checkGbsv(ind, A, B, ipiv)
 	if err != nil {
 		return err
 	}
@@ -87,29 +91,30 @@ func GbsvFloat(A, B *matrix.FloatMatrix, ipiv []int32, kl int, opts ...linalg.Op
 	info := dgbsv(ind.N, ind.Kl, ind.Ku, ind.Nrhs, Aa[ind.OffsetA:], ind.LDa,
 		ipiv, Ba[ind.OffsetB:], ind.LDb)
 	if info != 0 {
-		return onError(fmt.Sprintf("Gbsv lapack error: %d", info))
+		return onLapackError("Gbsv", info, linalg.ErrSingular)
 	}
 	return nil
 }
 
-func GbsvComplex(A, B *matrix.ComplexMatrix, ipiv []int32, kl int, opts ...linalg.Option) error {
+func GbsvComplex(A, B *matrix.ComplexMatrix, ipiv []int32, kl int, opts ...linalg.Option) (err error) {
+	defer guard("GbsvComplex", &err)()
 	ind := linalg.GetIndexOpts(opts...)
 	ind.Kl = kl
-	err := checkGbsv(ind, A, B, ipiv)
+	err = checkGbsv(ind, A, B, ipiv)
 	if err != nil {
 		return err
 	}
 	if ind.N == 0 || ind.Nrhs == 0 {
 		return nil
 	}
-	return onError("Gbsv: complex not implemented yet")
+	return onError(linalg.ErrNotImplemented, "Gbsv: complex not implemented yet")
 }
 
 func checkGbsv(ind *linalg.IndexOpts, A, B matrix.Matrix, ipiv []int32) error {
 	arows := ind.LDa
 	brows := ind.LDb
 	if ind.Kl < 0 {
-		return onError("Gbsv: invalid kl")
+		return onError(linalg.ErrParameter, "Gbsv: invalid kl")
 	}
 	if ind.N < 0 {
 		ind.N = A.Rows()
@@ -124,35 +129,35 @@ func checkGbsv(ind *linalg.IndexOpts, A, B matrix.Matrix, ipiv []int32) error {
 		ind.Ku = A.Rows() - 2*ind.Kl - 1
 	}
 	if ind.Ku < 0 {
-		return onError("Gbsv: invalid ku")
+		return onError(linalg.ErrParameter, "Gbsv: invalid ku")
 	}
 	if ind.LDa == 0 {
 		ind.LDa = max(1, A.LeadingIndex())
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < 2*ind.Kl+ind.Ku+1 {
-		return onError("Gbsv: lda")
+		return onError(linalg.ErrParameter, "Gbsv: lda")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Gbsv: offsetA")
+		return onError(linalg.ErrParameter, "Gbsv: offsetA")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+2*ind.Kl+ind.Ku+1 {
-		return onError("Gbsv: sizeA")
+		return onError(linalg.ErrShape, "Gbsv: sizeA")
 	}
 	if ind.LDb == 0 {
 		ind.LDb = max(1, B.LeadingIndex())
 		brows = max(1, B.Rows())
 	}
 	if ind.OffsetB < 0 {
-		return onError("Gbsv: offsetB")
+		return onError(linalg.ErrParameter, "Gbsv: offsetB")
 	}
 	sizeB := B.NumElements()
 	if sizeB < ind.OffsetB+(ind.Nrhs-1)*brows+ind.N {
-		return onError("Gbsv: sizeB")
+		return onError(linalg.ErrShape, "Gbsv: sizeB")
 	}
 	if ipiv != nil && len(ipiv) < ind.N {
-		return onError("Gbsv: size ipiv")
+		return onError(linalg.ErrShape, "Gbsv: size ipiv")
 	}
 	return nil
 }
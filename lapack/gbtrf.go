@@ -8,7 +8,6 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
 )
@@ -36,22 +35,27 @@ import (
   ldA       positive integer, ldA >= 2*kl+ku+1. default = min(1, A.Rows())
   offsetA   nonnegative integer
 */
-func Gbtrf(A matrix.Matrix, ipiv []int32, M, KL int, opts ...linalg.Option) error {
+func Gbtrf(A matrix.Matrix, ipiv []int32, M, KL int, opts ...linalg.Option) (err error) {
+	defer guard("Gbtrf", &err)()
+	if err = writable("Gbtrf", A); err != nil {
+		return
+	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
 		Am := A.(*matrix.FloatMatrix)
 		return Gbtrf(Am, ipiv, M, KL, opts...)
 	case *matrix.ComplexMatrix:
-		return onError("Gbtrf: complex not yet implemented.")
+		return onError(linalg.ErrNotImplemented, "Gbtrf: complex not yet implemented.")
 	}
-	return onError("Gbtrf: unknown types")
+	return onError(linalg.ErrType, "Gbtrf: unknown types")
 }
 
-func GbtrfFloat(A *matrix.FloatMatrix, ipiv []int32, M, KL int, opts ...linalg.Option) error {
+func GbtrfFloat(A *matrix.FloatMatrix, ipiv []int32, M, KL int, opts ...linalg.Option) (err error) {
+	defer guard("GbtrfFloat", &err)()
 	ind := linalg.GetIndexOpts(opts...)
 	ind.M = M
 	ind.Kl = KL
-	err := checkGbtrf(ind, A, ipiv)
+	err = checkGbtrf(ind, A, ipiv)
 	if err != nil {
 		return err
 	}
@@ -61,7 +65,7 @@ func GbtrfFloat(A *matrix.FloatMatrix, ipiv []int32, M, KL int, opts ...linalg.O
 	Aa := A.FloatArray()
 	info := dgbtrf(ind.M, ind.N, ind.Kl, ind.Ku, Aa[ind.OffsetA:], ind.LDa, ipiv)
 	if info != 0 {
-		return onError(fmt.Sprintf("Gbtrf lapack error: %d", info))
+		return onLapackError("Gbtrf", info, linalg.ErrSingular)
 	}
 	return nil
 }
@@ -69,10 +73,10 @@ func GbtrfFloat(A *matrix.FloatMatrix, ipiv []int32, M, KL int, opts ...linalg.O
 func checkGbtrf(ind *linalg.IndexOpts, A matrix.Matrix, ipiv []int32) error {
 	arows := ind.LDa
 	if ind.M < 0 {
-		return onError("Gbtrf: illegal m")
+		return onError(linalg.ErrParameter, "Gbtrf: illegal m")
 	}
 	if ind.Kl < 0 {
-		return onError("GBtrf: illegal kl")
+		return onError(linalg.ErrParameter, "GBtrf: illegal kl")
 	}
 	if ind.N < 0 {
 		ind.N = A.Rows()
@@ -84,24 +88,24 @@ func checkGbtrf(ind *linalg.IndexOpts, A matrix.Matrix, ipiv []int32) error {
 		ind.Ku = A.Rows() - 2*ind.Kl - 1
 	}
 	if ind.Ku < 0 {
-		return onError("Gbtrf: invalid ku")
+		return onError(linalg.ErrParameter, "Gbtrf: invalid ku")
 	}
 	if ind.LDa == 0 {
 		ind.LDa = max(1, A.LeadingIndex())
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < 2*ind.Kl+ind.Ku+1 {
-		return onError("Gbtrf: lda")
+		return onError(linalg.ErrParameter, "Gbtrf: lda")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Gbtrf: offsetA")
+		return onError(linalg.ErrParameter, "Gbtrf: offsetA")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+2*ind.Kl+ind.Ku+1 {
-		return onError("Gbtrf: sizeA")
+		return onError(linalg.ErrShape, "Gbtrf: sizeA")
 	}
 	if ipiv != nil && len(ipiv) < min(ind.N, ind.M) {
-		return onError("Gbtrf: size ipiv")
+		return onError(linalg.ErrShape, "Gbtrf: size ipiv")
 	}
 	return nil
 }
@@ -8,8 +8,8 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
 	"github.com/nvcook42/matrix"
 )
 
@@ -45,7 +45,12 @@ import (
   offsetA   nonnegative integer
   offsetB   nonnegative integer;
 */
-func Gbtrs(A, B matrix.Matrix, ipiv []int32, KL int, opts ...linalg.Option) error {
+func Gbtrs(A, B matrix.Matrix, ipiv []int32, KL int, opts ...linalg.Option) (err error) {
+	defer guard("Gbtrs", &err)()
+	if err = writable("Gbtrs", B); err != nil {
+		return
+	}
+	A = matops.Readable(A)
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
 		return err
@@ -55,7 +60,7 @@ func Gbtrs(A, B matrix.Matrix, ipiv []int32, KL int, opts ...linalg.Option) erro
 	arows := ind.LDa
 	brows := ind.LDb
 	if ind.Kl < 0 {
-		return onError("Gbtrs: invalid kl")
+		return onError(linalg.ErrParameter, "Gbtrs: invalid kl")
 	}
 	if ind.N < 0 {
 		ind.N = A.Rows()
@@ -70,39 +75,39 @@ func Gbtrs(A, B matrix.Matrix, ipiv []int32, KL int, opts ...linalg.Option) erro
 		ind.Ku = A.Rows() - 2*ind.Kl - 1
 	}
 	if ind.Ku < 0 {
-		return onError("Gbtrs: invalid ku")
+		return onError(linalg.ErrParameter, "Gbtrs: invalid ku")
 	}
 	if ind.LDa == 0 {
 		ind.LDa = max(1, A.LeadingIndex())
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < 2*ind.Kl+ind.Ku+1 {
-		return onError("Gbtrs: ldA")
+		return onError(linalg.ErrParameter, "Gbtrs: ldA")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Gbtrs: offsetA")
+		return onError(linalg.ErrParameter, "Gbtrs: offsetA")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+2*ind.Kl+ind.Ku+1 {
-		return onError("Gbtrs: sizeA")
+		return onError(linalg.ErrShape, "Gbtrs: sizeA")
 	}
 	if ind.LDb == 0 {
 		ind.LDb = max(1, B.LeadingIndex())
 		brows = max(1, B.Rows())
 	}
 	if ind.OffsetB < 0 {
-		return onError("Gbtrs: offsetB")
+		return onError(linalg.ErrParameter, "Gbtrs: offsetB")
 	}
 	sizeB := B.NumElements()
 	if sizeB < ind.OffsetB+(ind.Nrhs-1)*brows+ind.N {
-		return onError("Gbtrs: sizeB")
+		return onError(linalg.ErrShape, "Gbtrs: sizeB")
 	}
 	if ipiv != nil && len(ipiv) < ind.N {
-		return onError("Gbtrs: size ipiv")
+		return onError(linalg.ErrShape, "Gbtrs: size ipiv")
 	}
 
 	if !matrix.EqualTypes(A, B) {
-		return onError("Gbtrs: arguments not of same type")
+		return onError(linalg.ErrType, "Gbtrs: arguments not of same type")
 	}
 	info := -1
 	switch A.(type) {
@@ -113,15 +118,16 @@ func Gbtrs(A, B matrix.Matrix, ipiv []int32, KL int, opts ...linalg.Option) erro
 		info = dgbtrs(trans, ind.N, ind.Kl, ind.Ku, ind.Nrhs,
 			Aa[ind.OffsetA:], ind.LDa, ipiv, Ba[ind.OffsetB:], ind.LDb)
 	case *matrix.ComplexMatrix:
-		return onError("Gbtrs: complex not yet implemented")
+		return onError(linalg.ErrNotImplemented, "Gbtrs: complex not yet implemented")
 	}
 	if info != 0 {
-		return onError(fmt.Sprintf("Gbtrs lapack error: %d", info))
+		return onLapackError("Gbtrs", info, linalg.ErrParameter)
 	}
 	return nil
 }
 
-func GbtrsFloat(A, B *matrix.FloatMatrix, ipiv []int32, KL int, opts ...linalg.Option) error {
+func GbtrsFloat(A, B *matrix.FloatMatrix, ipiv []int32, KL int, opts ...linalg.Option) (err error) {
+	defer guard("GbtrsFloat", &err)()
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
 		return err
@@ -142,7 +148,7 @@ func GbtrsFloat(A, B *matrix.FloatMatrix, ipiv []int32, KL int, opts ...linalg.O
 	info := dgbtrs(trans, ind.N, ind.Kl, ind.Ku, ind.Nrhs,
 		Aa[ind.OffsetA:], ind.LDa, ipiv, Ba[ind.OffsetB:], ind.LDb)
 	if info != 0 {
-		return onError(fmt.Sprintf("Gbtrs: lapack error: %d", info))
+		return onLapackError("Gbtrs", info, linalg.ErrParameter)
 	}
 	return nil
 }
@@ -151,7 +157,7 @@ func checkGbtrs(ind *linalg.IndexOpts, A, B matrix.Matrix, ipiv []int32) error {
 	arows := ind.LDa
 	brows := ind.LDb
 	if ind.Kl < 0 {
-		return onError("Gbtrs: invalid kl")
+		return onError(linalg.ErrParameter, "Gbtrs: invalid kl")
 	}
 	if ind.N < 0 {
 		ind.N = A.Rows()
@@ -166,35 +172,35 @@ func checkGbtrs(ind *linalg.IndexOpts, A, B matrix.Matrix, ipiv []int32) error {
 		ind.Ku = A.Rows() - 2*ind.Kl - 1
 	}
 	if ind.Ku < 0 {
-		return onError("Gbtrs: invalid ku")
+		return onError(linalg.ErrParameter, "Gbtrs: invalid ku")
 	}
 	if ind.LDa == 0 {
 		ind.LDa = max(1, A.LeadingIndex())
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < 2*ind.Kl+ind.Ku+1 {
-		return onError("Gbtrs: lda")
+		return onError(linalg.ErrParameter, "Gbtrs: lda")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Gbtrs: offsetA")
+		return onError(linalg.ErrParameter, "Gbtrs: offsetA")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+2*ind.Kl+ind.Ku+1 {
-		return onError("Gbtrs: sizeA")
+		return onError(linalg.ErrShape, "Gbtrs: sizeA")
 	}
 	if ind.LDb == 0 {
 		ind.LDb = max(1, B.LeadingIndex())
 		brows = max(1, B.Rows())
 	}
 	if ind.OffsetB < 0 {
-		return onError("Gbtrs: offsetB")
+		return onError(linalg.ErrParameter, "Gbtrs: offsetB")
 	}
 	sizeB := B.NumElements()
 	if sizeB < ind.OffsetB+(ind.Nrhs-1)*brows+ind.N {
-		return onError("Gbtrs: sizeB")
+		return onError(linalg.ErrShape, "Gbtrs: sizeB")
 	}
 	if ipiv != nil && len(ipiv) < ind.N {
-		return onError("Gbtrs: size ipiv")
+		return onError(linalg.ErrShape, "Gbtrs: size ipiv")
 	}
 	return nil
 }
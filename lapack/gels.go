@@ -8,7 +8,6 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
 )
@@ -32,7 +31,13 @@ import (
   ldA       positive integer.  ldA >= max(1,n).  If zero, the default value is used.
   ldB       positive integer.  ldB >= max(1,n).  If zero, the default value is used.
 */
-func Gels(A, B matrix.Matrix, opts ...linalg.Option) error {
+func Gels(A, B matrix.Matrix, opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("lapack.Gels")
+	defer op.Finish(&err)
+	defer guard("Gels", &err)()
+	if err = writable("Gels", A, B); err != nil {
+		return
+	}
 	pars, _ := linalg.GetParameters(opts...)
 	ind := linalg.GetIndexOpts(opts...)
 	arows := ind.LDa
@@ -46,6 +51,7 @@ func Gels(A, B matrix.Matrix, opts ...linalg.Option) error {
 	if ind.Nrhs < 0 {
 		ind.Nrhs = B.Cols()
 	}
+	op.SetDims(ind.M, ind.N, ind.Nrhs)
 	if ind.M == 0 || ind.N == 0 || ind.Nrhs == 0 {
 		return nil
 	}
@@ -54,17 +60,17 @@ func Gels(A, B matrix.Matrix, opts ...linalg.Option) error {
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, ind.M) {
-		return onError("Gesv: ldA")
+		return onError(linalg.ErrParameter, "Gesv: ldA")
 	}
 	if ind.LDb == 0 {
 		ind.LDb = max(1, B.LeadingIndex())
 		brows = max(1, B.Rows())
 	}
 	if ind.LDb < max(ind.M, ind.N) {
-		return onError("Gesv: ldB")
+		return onError(linalg.ErrParameter, "Gesv: ldB")
 	}
 	if !matrix.EqualTypes(A, B) {
-		return onError("Gesv: arguments not of same type")
+		return onError(linalg.ErrType, "Gesv: arguments not of same type")
 	}
 	_, _ = arows, brows // todo!! something
 	info := -1
@@ -82,7 +88,7 @@ func Gels(A, B matrix.Matrix, opts ...linalg.Option) error {
 			Ba[ind.OffsetB:], ind.LDb)
 	}
 	if info != 0 {
-		return onError(fmt.Sprintf("Gels: lapack error: %d", info))
+		return onLapackError("Gels", info, linalg.ErrSingular)
 	}
 	return nil
 }
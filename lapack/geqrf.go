@@ -8,7 +8,6 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
 )
@@ -41,7 +40,11 @@ import (
   offsetA   nonnegative integer
 
 */
-func Geqrf(A, tau matrix.Matrix, opts ...linalg.Option) error {
+func Geqrf(A, tau matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Geqrf", &err)()
+	if err = writable("Geqrf", A, tau); err != nil {
+		return
+	}
 	ind := linalg.GetIndexOpts(opts...)
 	arows := ind.LDa
 	if ind.N < 0 {
@@ -58,19 +61,19 @@ func Geqrf(A, tau matrix.Matrix, opts ...linalg.Option) error {
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, ind.M) {
-		return onError("Geqrf: ldA")
+		return onError(linalg.ErrParameter, "Geqrf: ldA")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Geqrf: offsetA")
+		return onError(linalg.ErrParameter, "Geqrf: offsetA")
 	}
 	if A.NumElements() < ind.OffsetA+ind.K*arows {
-		return onError("Geqrf: sizeA")
+		return onError(linalg.ErrShape, "Geqrf: sizeA")
 	}
 	if tau.NumElements() < min(ind.M, ind.N) {
-		return onError("Geqrf: sizeTau")
+		return onError(linalg.ErrShape, "Geqrf: sizeTau")
 	}
 	if !matrix.EqualTypes(A, tau) {
-		return onError("Geqrf: arguments not of same type")
+		return onError(linalg.ErrType, "Geqrf: arguments not of same type")
 	}
 	info := -1
 	switch A.(type) {
@@ -79,10 +82,10 @@ func Geqrf(A, tau matrix.Matrix, opts ...linalg.Option) error {
 		taua := tau.(*matrix.FloatMatrix).FloatArray()
 		info = dgeqrf(ind.M, ind.N, Aa[ind.OffsetA:], ind.LDa, taua)
 	case *matrix.ComplexMatrix:
-		return onError("Geqrf: complex not yet implemented")
+		return onError(linalg.ErrNotImplemented, "Geqrf: complex not yet implemented")
 	}
 	if info != 0 {
-		return onError(fmt.Sprintf("Geqrf lapack error: %d", info))
+		return onLapackError("Geqrf", info, linalg.ErrParameter)
 	}
 	return nil
 }
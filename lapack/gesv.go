@@ -8,8 +8,8 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
 	"github.com/nvcook42/matrix"
 )
 
@@ -39,7 +39,20 @@ import (
   offsetA   nonnegative integer
   offsetA   nonnegative integer;
 */
-func Gesv(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
+func Gesv(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("lapack.Gesv")
+	defer op.Finish(&err)
+	defer guard("Gesv", &err)()
+	if err = writable("Gesv", B); err != nil {
+		return
+	}
+	if ipiv != nil {
+		// A is overwritten with the factorization
+		if err = writable("Gesv", A); err != nil {
+			return
+		}
+	}
+	A = matops.Readable(A)
 	//pars, err := linalg.GetParameters(opts...)
 	ind := linalg.GetIndexOpts(opts...)
 	arows := ind.LDa
@@ -47,12 +60,13 @@ func Gesv(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
 	if ind.N < 0 {
 		ind.N = A.Rows()
 		if ind.N != A.Cols() {
-			return onError("Gesv: A not square")
+			return onError(linalg.ErrShape, "Gesv: A not square")
 		}
 	}
 	if ind.Nrhs < 0 {
 		ind.Nrhs = B.Cols()
 	}
+	op.SetDims(ind.N, ind.Nrhs)
 	if ind.N == 0 || ind.Nrhs == 0 {
 		return nil
 	}
@@ -61,61 +75,82 @@ func Gesv(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, ind.N) {
-		return onError("Gesv: ldA")
+		return onError(linalg.ErrParameter, "Gesv: ldA")
 	}
 	if ind.LDb == 0 {
 		ind.LDb = max(1, B.LeadingIndex())
 		brows = max(1, B.Rows())
 	}
 	if ind.LDb < max(1, ind.N) {
-		return onError("Gesv: ldB")
+		return onError(linalg.ErrParameter, "Gesv: ldB")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Gesv: offsetA")
+		return onError(linalg.ErrParameter, "Gesv: offsetA")
 	}
 	if ind.OffsetB < 0 {
-		return onError("Gesv: offsetB")
+		return onError(linalg.ErrParameter, "Gesv: offsetB")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.N {
-		return onError("Gesv: sizeA")
+		return onError(linalg.ErrShape, "Gesv: sizeA")
 	}
 	sizeB := B.NumElements()
 	if sizeB < ind.OffsetB+(ind.Nrhs-1)*brows+ind.N {
-		return onError("Gesv: sizeB")
+		return onError(linalg.ErrShape, "Gesv: sizeB")
 	}
 	if ipiv != nil && len(ipiv) < ind.N {
-		return onError("Gesv: size ipiv")
+		return onError(linalg.ErrShape, "Gesv: size ipiv")
 	}
 	if !matrix.EqualTypes(A, B) {
-		return onError("Gesv: arguments not of same type")
+		return onError(linalg.ErrType, "Gesv: arguments not of same type")
 	}
 	info := -1
-	if ipiv == nil {
-		ipiv = make([]int32, ind.N)
-		// Do not overwrite A.
-		A = A.MakeCopy()
+	// With ipiv nil the factorization is not returned and A must not be
+	// overwritten; work on a scratch copy from the allocator.
+	scratch := ipiv == nil
+	alloc := linalg.GetAllocator()
+	if scratch {
+		ipiv = alloc.Int32s(ind.N)
+		defer alloc.Free(ipiv)
+	}
+	nA := 0
+	if ind.N > 0 {
+		nA = (ind.N-1)*ind.LDa + ind.N
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
 		Aa := A.(*matrix.FloatMatrix).FloatArray()
 		Aa = Aa[ind.OffsetA:]
-		// Ensure there are sufficient elements in A.
-		Aa = Aa[:ind.LDa*ind.LDb]
+		if scratch {
+			Ac := alloc.Float64s(nA)
+			defer alloc.Free(Ac)
+			copy(Ac, Aa[:nA])
+			Aa = Ac
+		} else {
+			// Ensure there are sufficient elements in A.
+			Aa = Aa[:ind.LDa*ind.LDb]
+		}
 		Ba := B.(*matrix.FloatMatrix).FloatArray()
 		Ba = Ba[ind.OffsetB:]
 		info = dgesv(ind.N, ind.Nrhs, Aa, ind.LDa, ipiv, Ba, ind.LDb)
 	case *matrix.ComplexMatrix:
 		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
 		Aa = Aa[ind.OffsetA:]
-		// Ensure there are sufficient elements in A.
-		Aa = Aa[:ind.LDa*ind.LDb]
+		if scratch {
+			Ac := alloc.Complex128s(nA)
+			defer alloc.Free(Ac)
+			copy(Ac, Aa[:nA])
+			Aa = Ac
+		} else {
+			// Ensure there are sufficient elements in A.
+			Aa = Aa[:ind.LDa*ind.LDb]
+		}
 		Ba := B.(*matrix.ComplexMatrix).ComplexArray()
 		Ba = Ba[ind.OffsetB:]
 		info = zgesv(ind.N, ind.Nrhs, Aa, ind.LDa, ipiv, Ba, ind.LDb)
 	}
 	if info != 0 {
-		return onError(fmt.Sprintf("Gesv: lapack error: %d", info))
+		return onLapackError("Gesv", info, linalg.ErrSingular)
 	}
 	return nil
 }
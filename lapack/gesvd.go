@@ -8,7 +8,6 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
 )
@@ -88,9 +87,13 @@ import (
   offsetVt  nonnegative integer
 
 */
-func Gesvd(A, S, U, Vt matrix.Matrix, opts ...linalg.Option) error {
+func Gesvd(A, S, U, Vt matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Gesvd", &err)()
+	if err = writable("Gesvd", A, S, U, Vt); err != nil {
+		return
+	}
 	if !matrix.EqualTypes(A, S, U, Vt) {
-		return onError("Gesvd: arguments not of same type")
+		return onError(linalg.ErrType, "Gesvd: arguments not of same type")
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
@@ -106,10 +109,13 @@ func Gesvd(A, S, U, Vt matrix.Matrix, opts ...linalg.Option) error {
 		Vm := Vt.(*matrix.ComplexMatrix)
 		return GesvdComplex(Am, Sm, Um, Vm, opts...)
 	}
-	return onError("Gesvd: unknown parameter types")
+	return onError(linalg.ErrType, "Gesvd: unknown parameter types")
 }
 
-func GesvdFloat(A, S, U, Vt *matrix.FloatMatrix, opts ...linalg.Option) error {
+func GesvdFloat(A, S, U, Vt *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("lapack.Gesvd")
+	defer op.Finish(&err)
+	defer guard("GesvdFloat", &err)()
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
 		return err
@@ -122,6 +128,10 @@ func GesvdFloat(A, S, U, Vt *matrix.FloatMatrix, opts ...linalg.Option) error {
 	if ind.M == 0 || ind.N == 0 {
 		return nil
 	}
+	op.SetDims(ind.M, ind.N)
+	if err = checkMemory("gesvd", opts, ind.M, ind.N); err != nil {
+		return err
+	}
 	Aa := A.FloatArray()
 	Sa := S.FloatArray()
 	var Ua, Va []float64
@@ -133,15 +143,33 @@ func GesvdFloat(A, S, U, Vt *matrix.FloatMatrix, opts ...linalg.Option) error {
 	if Vt != nil {
 		Va = Vt.FloatArray()[ind.OffsetVt:]
 	}
+	// dgesvd overwrites A; keep the original for verification, which
+	// needs both singular vector sets
+	var Ac *matrix.FloatMatrix
+	v := startVerify(opts...)
+	if v != nil && (pars.Jobu == linalg.PJobAll || pars.Jobu == linalg.PJobS) &&
+		(pars.Jobvt == linalg.PJobAll || pars.Jobvt == linalg.PJobS) {
+		Ac = matrix.FloatZeros(ind.M, ind.N)
+		for j := 0; j < ind.N; j++ {
+			for i := 0; i < ind.M; i++ {
+				Ac.SetAt(i, j, Aa[ind.OffsetA+j*ind.LDa+i])
+			}
+		}
+	}
 	info := dgesvd(linalg.ParamString(pars.Jobu), linalg.ParamString(pars.Jobvt),
 		ind.M, ind.N, Aa[ind.OffsetA:], ind.LDa, Sa[ind.OffsetS:], Ua, ind.LDu, Va, ind.LDvt)
 	if info != 0 {
-		return onError(fmt.Sprintf("GesvdFloat lapack error: %d", info))
+		return onLapackError("Gesvd", info, linalg.ErrNoConvergence)
+	}
+	if Ac != nil {
+		verifySVD(v, Ac, Sa[ind.OffsetS:], Ua, Va, ind.LDu, ind.LDvt)
+		v.report(op, "SVD")
 	}
 	return nil
 }
 
-func GesvdComplex(A, S, U, Vt *matrix.ComplexMatrix, opts ...linalg.Option) error {
+func GesvdComplex(A, S, U, Vt *matrix.ComplexMatrix, opts ...linalg.Option) (err error) {
+	defer guard("GesvdComplex", &err)()
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
 		return err
@@ -154,7 +182,7 @@ func GesvdComplex(A, S, U, Vt *matrix.ComplexMatrix, opts ...linalg.Option) erro
 	if ind.M == 0 || ind.N == 0 {
 		return nil
 	}
-	return onError("GesvdComplex not implemented yet")
+	return onError(linalg.ErrNotImplemented, "GesvdComplex not implemented yet")
 }
 
 func checkGesvd(ind *linalg.IndexOpts, pars *linalg.Parameters, A, S, U, Vt matrix.Matrix) error {
@@ -169,48 +197,48 @@ func checkGesvd(ind *linalg.IndexOpts, pars *linalg.Parameters, A, S, U, Vt matr
 		return nil
 	}
 	if pars.Jobu == linalg.PJobO && pars.Jobvt == linalg.PJobO {
-		return onError("Gesvd: jobu and jobvt cannot both have value PJobO")
+		return onError(linalg.ErrParameter, "Gesvd: jobu and jobvt cannot both have value PJobO")
 	}
 	if pars.Jobu == linalg.PJobAll || pars.Jobu == linalg.PJobS {
 		if U == nil {
-			return onError("Gesvd: missing matrix U")
+			return onError(linalg.ErrParameter, "Gesvd: missing matrix U")
 		}
 		if ind.LDu == 0 {
 			ind.LDu = max(1, U.LeadingIndex())
 		}
 		if ind.LDu < max(1, ind.M) {
-			return onError("Gesvd: ldU")
+			return onError(linalg.ErrParameter, "Gesvd: ldU")
 		}
 	} else {
 		if ind.LDu == 0 {
 			ind.LDu = 1
 		}
 		if ind.LDu < 1 {
-			return onError("Gesvd: ldU")
+			return onError(linalg.ErrParameter, "Gesvd: ldU")
 		}
 	}
 	if pars.Jobvt == linalg.PJobAll || pars.Jobvt == linalg.PJobS {
 		if Vt == nil {
-			return onError("Gesvd: missing matrix Vt")
+			return onError(linalg.ErrParameter, "Gesvd: missing matrix Vt")
 		}
 		if ind.LDvt == 0 {
 			ind.LDvt = max(1, Vt.LeadingIndex())
 		}
 		if pars.Jobvt == linalg.PJobAll && ind.LDvt < max(1, ind.N) {
-			return onError("Gesvd: ldVt")
+			return onError(linalg.ErrParameter, "Gesvd: ldVt")
 		} else if pars.Jobvt != linalg.PJobAll && ind.LDvt < max(1, min(ind.M, ind.N)) {
-			return onError("Gesvd: ldVt")
+			return onError(linalg.ErrParameter, "Gesvd: ldVt")
 		}
 	} else {
 		if ind.LDvt == 0 {
 			ind.LDvt = 1
 		}
 		if ind.LDvt < 1 {
-			return onError("Gesvd: ldVt")
+			return onError(linalg.ErrParameter, "Gesvd: ldVt")
 		}
 	}
 	if ind.OffsetA < 0 {
-		return onError("Gesvd: offsetA")
+		return onError(linalg.ErrParameter, "Gesvd: offsetA")
 	}
 	sizeA := A.NumElements()
 	if ind.LDa == 0 {
@@ -218,39 +246,39 @@ func checkGesvd(ind *linalg.IndexOpts, pars *linalg.Parameters, A, S, U, Vt matr
 		arows = max(1, A.Rows())
 	}
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.M {
-		return onError("Gesvd: sizeA")
+		return onError(linalg.ErrShape, "Gesvd: sizeA")
 	}
 
 	if ind.OffsetS < 0 {
-		return onError("Gesvd: offsetS")
+		return onError(linalg.ErrParameter, "Gesvd: offsetS")
 	}
 	sizeS := S.NumElements()
 	if sizeS < ind.OffsetS+min(ind.M, ind.N) {
-		return onError("Gesvd: sizeA")
+		return onError(linalg.ErrShape, "Gesvd: sizeA")
 	}
 
 	/*
 		if U != nil {
 			if ind.OffsetU < 0 {
-				return onError("Gesvd: OffsetU")
+				return onError(linalg.ErrParameter, "Gesvd: OffsetU")
 			}
 			sizeU := U.NumElements()
 			if pars.Jobu == linalg.PJobAll && sizeU < ind.LDu*(ind.M-1) {
-				return onError("Gesvd: sizeU")
+				return onError(linalg.ErrShape, "Gesvd: sizeU")
 			} else if pars.Jobu == linalg.PJobS && sizeU < ind.LDu*(min(ind.M,ind.N)-1) {
-				return onError("Gesvd: sizeU")
+				return onError(linalg.ErrShape, "Gesvd: sizeU")
 			}
 		}
 
 		if Vt != nil {
 			if ind.OffsetVt < 0 {
-				return onError("Gesvd: OffsetVt")
+				return onError(linalg.ErrParameter, "Gesvd: OffsetVt")
 			}
 			sizeVt := Vt.NumElements()
 			if pars.Jobvt == linalg.PJobAll && sizeVt <  ind.N {
-				return onError("Gesvd: sizeVt")
+				return onError(linalg.ErrShape, "Gesvd: sizeVt")
 			} else if pars.Jobvt == linalg.PJobS && sizeVt < min(ind.M, ind.N) {
-				return onError("Gesvd: sizeVt")
+				return onError(linalg.ErrShape, "Gesvd: sizeVt")
 			}
 		}
 	*/
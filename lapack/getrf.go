@@ -34,8 +34,19 @@ import (
             value is used.
   offsetA   nonnegative integer
 
+ Option WithDiagnostics(d) fills d with the pivot growth factor and pivot
+ sizes of a real factorization, also if A is found singular. Option
+ WithCrossCheck(c), or the crosscheck option, compares a real
+ factorization with matops.LU, see CrossCheck.
+
 */
-func Getrf(A matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
+func Getrf(A matrix.Matrix, ipiv []int32, opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("lapack.Getrf")
+	defer op.Finish(&err)
+	defer guard("Getrf", &err)()
+	if err = writable("Getrf", A); err != nil {
+		return
+	}
 	ind := linalg.GetIndexOpts(opts...)
 	arows := ind.LDa
 	if ind.M < 0 {
@@ -44,6 +55,7 @@ func Getrf(A matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
 	if ind.N < 0 {
 		ind.N = A.Cols()
 	}
+	op.SetDims(ind.M, ind.N)
 	if ind.N == 0 || ind.M == 0 {
 		return nil
 	}
@@ -52,27 +64,56 @@ func Getrf(A matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, ind.M) {
-		return onError("lda")
+		return onError(linalg.ErrParameter, "lda")
 	}
 	if ind.OffsetA < 0 {
-		return onError("offsetA")
+		return onError(linalg.ErrParameter, "offsetA")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.M {
-		return onError("sizeA")
+		return onError(linalg.ErrShape, "sizeA")
 	}
 	if ipiv != nil && len(ipiv) < min(ind.N, ind.M) {
-		return onError("size ipiv")
+		return onError(linalg.ErrShape, "size ipiv")
 	}
 	info := -1
 	switch A.(type) {
 	case *matrix.FloatMatrix:
 		Aa := A.(*matrix.FloatMatrix).FloatArray()
+		diag := getDiagnostics(opts...)
+		if diag == nil && op.Enabled() {
+			diag = &PivotDiagnostics{}
+		}
+		var cmax []float64
+		if diag != nil {
+			cmax = columnMax(Aa[ind.OffsetA:], ind.M, ind.N, ind.LDa)
+		}
+		cc := startCrossCheck(opts...)
+		var G *matrix.FloatMatrix
+		if cc != nil {
+			G = matrix.FloatZeros(ind.M, ind.N)
+			for j := 0; j < ind.N; j++ {
+				k := ind.OffsetA + j*ind.LDa
+				copy(G.FloatArray()[j*ind.M:(j+1)*ind.M], Aa[k:k+ind.M])
+			}
+		}
 		info = dgetrf(ind.M, ind.N, Aa[ind.OffsetA:], ind.LDa, ipiv)
+		if diag != nil {
+			luDiagnostics(diag, Aa[ind.OffsetA:], ind.M, ind.N, ind.LDa, cmax)
+			warnPivots(op, diag)
+		}
+		if cc != nil && info >= 0 {
+			var singular error
+			if info > 0 {
+				singular = linalg.ErrSingular
+			}
+			crossCheckLU(cc, Aa[ind.OffsetA:], ind.LDa, ind.M, ind.N, ipiv, G, singular)
+			cc.report(op, "LU")
+		}
 	case *matrix.ComplexMatrix:
 	}
 	if info != 0 {
-		return onError("Getrf call error")
+		return onLapackError("Getrf", info, linalg.ErrSingular)
 	}
 	return nil
 }
@@ -8,7 +8,6 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
 )
@@ -32,7 +31,11 @@ import (
             value is used.
   offsetA   nonnegative integer;
 */
-func Getri(A matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
+func Getri(A matrix.Matrix, ipiv []int32, opts ...linalg.Option) (err error) {
+	defer guard("Getri", &err)()
+	if err = writable("Getri", A); err != nil {
+		return
+	}
 	ind := linalg.GetIndexOpts(opts...)
 	arows := ind.LDa
 	if ind.N < 0 {
@@ -46,14 +49,14 @@ func Getri(A matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
 		arows = max(1, A.Rows())
 	}
 	if ind.OffsetA < 0 {
-		return onError("Getri: offset")
+		return onError(linalg.ErrParameter, "Getri: offset")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.N {
-		return onError("Getri: sizeA")
+		return onError(linalg.ErrShape, "Getri: sizeA")
 	}
 	if ipiv != nil && len(ipiv) < ind.N {
-		return onError("Getri: size ipiv")
+		return onError(linalg.ErrShape, "Getri: size ipiv")
 	}
 	info := -1
 	switch A.(type) {
@@ -61,10 +64,10 @@ func Getri(A matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
 		Aa := A.(*matrix.FloatMatrix).FloatArray()
 		info = dgetri(ind.N, Aa[ind.OffsetA:], ind.LDa, ipiv)
 	case *matrix.ComplexMatrix:
-		return onError("Getri: complex not yet implemented")
+		return onError(linalg.ErrNotImplemented, "Getri: complex not yet implemented")
 	}
 	if info != 0 {
-		return onError(fmt.Sprintf("Getri lapack error: %d", info))
+		return onLapackError("Getri", info, linalg.ErrSingular)
 	}
 	return nil
 }
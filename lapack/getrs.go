@@ -8,8 +8,8 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
 	"github.com/nvcook42/matrix"
 )
 
@@ -42,7 +42,12 @@ import (
   offsetA   nonnegative integer
   offsetB   nonnegative integer;
 */
-func Getrs(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
+func Getrs(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) (err error) {
+	defer guard("Getrs", &err)()
+	if err = writable("Getrs", B); err != nil {
+		return
+	}
+	A = matops.Readable(A)
 
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
@@ -54,7 +59,7 @@ func Getrs(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
 	if ind.N < 0 {
 		ind.N = A.Rows()
 		if ind.N != A.Cols() {
-			return onError("Getrs: A not square")
+			return onError(linalg.ErrShape, "Getrs: A not square")
 		}
 	}
 	if ind.Nrhs < 0 {
@@ -68,34 +73,34 @@ func Getrs(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, ind.N) {
-		return onError("Getrs: ldA")
+		return onError(linalg.ErrParameter, "Getrs: ldA")
 	}
 	if ind.LDb == 0 {
 		ind.LDb = max(1, B.LeadingIndex())
 		brows = max(1, B.Rows())
 	}
 	if ind.LDb < max(1, ind.N) {
-		return onError("Getrs: ldB")
+		return onError(linalg.ErrParameter, "Getrs: ldB")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Getrs: offsetA")
+		return onError(linalg.ErrParameter, "Getrs: offsetA")
 	}
 	if ind.OffsetB < 0 {
-		return onError("Getrs: offsetB")
+		return onError(linalg.ErrParameter, "Getrs: offsetB")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.N {
-		return onError("Getrs: sizeA")
+		return onError(linalg.ErrShape, "Getrs: sizeA")
 	}
 	sizeB := B.NumElements()
 	if sizeB < ind.OffsetB+(ind.Nrhs-1)*brows+ind.N {
-		return onError("Getrs: sizeB")
+		return onError(linalg.ErrShape, "Getrs: sizeB")
 	}
 	if ipiv != nil && len(ipiv) < ind.N {
-		return onError("Getrs: size ipiv")
+		return onError(linalg.ErrShape, "Getrs: size ipiv")
 	}
 	if !matrix.EqualTypes(A, B) {
-		return onError("Getrs: arguments not of same type")
+		return onError(linalg.ErrType, "Getrs: arguments not of same type")
 	}
 	info := -1
 	trans := linalg.ParamString(pars.Trans)
@@ -106,10 +111,10 @@ func Getrs(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
 		info = dgetrs(trans, ind.N, ind.Nrhs,
 			Aa[ind.OffsetA:], ind.LDa, ipiv, Ba[ind.OffsetB:], ind.LDb)
 	case *matrix.ComplexMatrix:
-		return onError("Getrs: complex not yet implemented")
+		return onError(linalg.ErrNotImplemented, "Getrs: complex not yet implemented")
 	}
 	if info != 0 {
-		return onError(fmt.Sprintf("Getrs lapack error: %d", info))
+		return onLapackError("Getrs", info, linalg.ErrParameter)
 	}
 	return nil
 }
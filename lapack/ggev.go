@@ -0,0 +1,118 @@
+// Copyright (c) Harri Rautila, 2012,2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math/cmplx"
+)
+
+/*
+ Generalized eigenvalues and eigenvectors of a real matrix pair.
+
+ PURPOSE
+ Computes the generalized eigenvalues lambda and, optionally, the right
+ eigenvectors v of the n by n pair (A, B),
+
+  A*v = lambda*B*v.
+
+ Eigenvalues are returned as ratios lambda[k] = alpha[k]/beta[k]; beta[k]
+ is zero for infinite eigenvalues, which occur when B is singular.
+ Complex eigenvalues come in conjugate pairs.  On exit A and B are
+ overwritten.
+
+ ARGUMENTS
+  A         float matrix
+  B         float matrix
+  alpha     complex matrix of length at least n
+  beta      float matrix of length at least n
+  V         complex matrix with at least n rows and n columns, or nil.
+            On exit, column k holds the right eigenvector of eigenvalue k,
+            scaled so that its largest component has |re|+|im| = 1.
+
+ If the QZ iteration fails the error is ErrNoConvergence and
+ linalg.Unconverged lists the eigenvalues not computed, which are NaN in
+ alpha; the others are valid but V is not set.
+
+*/
+func Ggev(A, B *matrix.FloatMatrix, alpha *matrix.ComplexMatrix, beta *matrix.FloatMatrix,
+	V *matrix.ComplexMatrix, opts ...linalg.Option) (err error) {
+	defer guard("Ggev", &err)()
+	n := A.Rows()
+	if A.Cols() != n || B.Rows() != n || B.Cols() != n {
+		return onError(linalg.ErrShape, "Ggev: A and B not square of same size")
+	}
+	if alpha.NumElements() < n || beta.NumElements() < n {
+		return onError(linalg.ErrShape, "Ggev: size alpha or beta")
+	}
+	if V != nil && (V.Rows() < n || V.Cols() < n) {
+		return onError(linalg.ErrShape, "Ggev: size V")
+	}
+	if n == 0 {
+		return nil
+	}
+	alloc := linalg.GetAllocator()
+	ar := alloc.Float64s(n)
+	defer alloc.Free(ar)
+	ai := alloc.Float64s(n)
+	defer alloc.Free(ai)
+	jobvr := "N"
+	var VR []float64
+	if V != nil {
+		jobvr = "V"
+		VR = alloc.Float64s(n * n)
+		defer alloc.Free(VR)
+	}
+	info := dggev("N", jobvr, n, A.FloatArray(), max(1, A.LeadingIndex()),
+		B.FloatArray(), max(1, B.LeadingIndex()), ar, ai, beta.FloatArray(),
+		nil, 1, VR, n)
+	alphaa := alpha.ComplexArray()
+	if info > 0 && info <= n {
+		// QZ iteration failed; eigenvalues info:n have converged
+		unconverged := make([]int, info)
+		for k := 0; k < n; k++ {
+			if k < info {
+				unconverged[k] = k
+				alphaa[k] = cmplx.NaN()
+			} else {
+				alphaa[k] = complex(ar[k], ai[k])
+			}
+		}
+		return onConvergenceError("Ggev", info, unconverged)
+	}
+	if info != 0 {
+		return onLapackError("Ggev", info, linalg.ErrNoConvergence)
+	}
+	for k := 0; k < n; k++ {
+		alphaa[k] = complex(ar[k], ai[k])
+	}
+	if V == nil {
+		return nil
+	}
+	// complex pair (k, k+1) is stored as real and imaginary parts in
+	// columns k and k+1 of VR
+	for k := 0; k < n; k++ {
+		if ai[k] == 0.0 {
+			for i := 0; i < n; i++ {
+				V.SetAt(i, k, complex(VR[k*n+i], 0))
+			}
+			continue
+		}
+		for i := 0; i < n; i++ {
+			re, im := VR[k*n+i], VR[(k+1)*n+i]
+			V.SetAt(i, k, complex(re, im))
+			V.SetAt(i, k+1, complex(re, -im))
+		}
+		k++
+	}
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
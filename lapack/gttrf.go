@@ -8,7 +8,6 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
 )
@@ -38,44 +37,48 @@ import (
   offsetd   nonnegative integer
   offsetdu  nonnegative integer
 */
-func Gtrrf(DL, D, DU, DU2 matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
+func Gtrrf(DL, D, DU, DU2 matrix.Matrix, ipiv []int32, opts ...linalg.Option) (err error) {
+	defer guard("Gtrrf", &err)()
+	if err = writable("Gtrrf", DL, D, DU, DU2); err != nil {
+		return
+	}
 	ind := linalg.GetIndexOpts(opts...)
 	if ind.OffsetD < 0 {
-		return onError("Gttrf: offset D")
+		return onError(linalg.ErrParameter, "Gttrf: offset D")
 	}
 	if ind.N < 0 {
 		ind.N = D.NumElements() - ind.OffsetD
 	}
 	if ind.N < 0 {
-		return onError("Gttrf: size D")
+		return onError(linalg.ErrShape, "Gttrf: size D")
 	}
 	if ind.N == 0 {
 		return nil
 	}
 	if ind.OffsetDL < 0 {
-		return onError("Gttrf: offset DL")
+		return onError(linalg.ErrParameter, "Gttrf: offset DL")
 	}
 	sizeDL := DL.NumElements()
 	if sizeDL < ind.OffsetDL+ind.N-1 {
-		return onError("Gttrf: sizeDL")
+		return onError(linalg.ErrShape, "Gttrf: sizeDL")
 	}
 	if ind.OffsetDU < 0 {
-		return onError("Gttrf: offset DU")
+		return onError(linalg.ErrParameter, "Gttrf: offset DU")
 	}
 	sizeDU := DU.NumElements()
 	if sizeDU < ind.OffsetDU+ind.N-1 {
-		return onError("Gttrf: sizeDU")
+		return onError(linalg.ErrShape, "Gttrf: sizeDU")
 	}
 	sizeDU2 := DU2.NumElements()
 	if sizeDU2 < ind.N-2 {
-		return onError("Gttrf: sizeDU2")
+		return onError(linalg.ErrShape, "Gttrf: sizeDU2")
 	}
 	if len(ipiv) < ind.N {
-		return onError("Gttrf: size ipiv")
+		return onError(linalg.ErrShape, "Gttrf: size ipiv")
 	}
 	info := -1
 	if !matrix.EqualTypes(DL, D, DU, DU2) {
-		return onError("Gttrf: arguments not same type")
+		return onError(linalg.ErrType, "Gttrf: arguments not same type")
 	}
 	switch DL.(type) {
 	case *matrix.FloatMatrix:
@@ -86,10 +89,10 @@ func Gtrrf(DL, D, DU, DU2 matrix.Matrix, ipiv []int32, opts ...linalg.Option) er
 		info = dgttrf(ind.N, DLa[ind.OffsetDL:], Da[ind.OffsetD:], DUa[ind.OffsetDU:],
 			DU2a, ipiv)
 	case *matrix.ComplexMatrix:
-		return onError("Gttrf: complex not yet implemented")
+		return onError(linalg.ErrNotImplemented, "Gttrf: complex not yet implemented")
 	}
 	if info != 0 {
-		return onError(fmt.Sprintf("Gttrf lapack error: %d", info))
+		return onLapackError("Gttrf", info, linalg.ErrSingular)
 	}
 	return nil
 }
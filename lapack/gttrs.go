@@ -8,8 +8,8 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
 	"github.com/nvcook42/matrix"
 )
 
@@ -45,7 +45,12 @@ import (
   offsetB   nonnegative integer
 
 */
-func Gtrrs(DL, D, DU, DU2, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
+func Gtrrs(DL, D, DU, DU2, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) (err error) {
+	defer guard("Gtrrs", &err)()
+	if err = writable("Gtrrs", B); err != nil {
+		return
+	}
+	DL, D, DU, DU2 = matops.Readable(DL), matops.Readable(D), matops.Readable(DU), matops.Readable(DU2)
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
 		return err
@@ -53,34 +58,34 @@ func Gtrrs(DL, D, DU, DU2, B matrix.Matrix, ipiv []int32, opts ...linalg.Option)
 	ind := linalg.GetIndexOpts(opts...)
 	brows := ind.LDb
 	if ind.OffsetD < 0 {
-		return onError("Gttrs: offset D")
+		return onError(linalg.ErrParameter, "Gttrs: offset D")
 	}
 	if ind.N < 0 {
 		ind.N = D.NumElements() - ind.OffsetD
 	}
 	if ind.N < 0 {
-		return onError("Gttrs: size D")
+		return onError(linalg.ErrShape, "Gttrs: size D")
 	}
 	if ind.N == 0 {
 		return nil
 	}
 	if ind.OffsetDL < 0 {
-		return onError("Gttrs: offset DL")
+		return onError(linalg.ErrParameter, "Gttrs: offset DL")
 	}
 	sizeDL := DL.NumElements()
 	if sizeDL < ind.OffsetDL+ind.N-1 {
-		return onError("Gttrs: sizeDL")
+		return onError(linalg.ErrShape, "Gttrs: sizeDL")
 	}
 	if ind.OffsetDU < 0 {
-		return onError("Gttrs: offset DU")
+		return onError(linalg.ErrParameter, "Gttrs: offset DU")
 	}
 	sizeDU := DU.NumElements()
 	if sizeDU < ind.OffsetDU+ind.N-1 {
-		return onError("Gttrs: sizeDU")
+		return onError(linalg.ErrShape, "Gttrs: sizeDU")
 	}
 	sizeDU2 := DU2.NumElements()
 	if sizeDU2 < ind.N-2 {
-		return onError("Gttrs: sizeDU2")
+		return onError(linalg.ErrShape, "Gttrs: sizeDU2")
 	}
 	if ind.Nrhs < 0 {
 		ind.Nrhs = B.Cols()
@@ -93,20 +98,20 @@ func Gtrrs(DL, D, DU, DU2, B matrix.Matrix, ipiv []int32, opts ...linalg.Option)
 		brows = max(1, B.Rows())
 	}
 	if ind.LDb < max(1, ind.N) {
-		return onError("Gttrs: ldB")
+		return onError(linalg.ErrParameter, "Gttrs: ldB")
 	}
 	if ind.OffsetB < 0 {
-		return onError("Gttrs: offset B")
+		return onError(linalg.ErrParameter, "Gttrs: offset B")
 	}
 	sizeB := B.NumElements()
 	if sizeB < ind.OffsetB+(ind.Nrhs-1)*brows+ind.N {
-		return onError("Gttrs: sizeB")
+		return onError(linalg.ErrShape, "Gttrs: sizeB")
 	}
 	if len(ipiv) < ind.N {
-		return onError("Gttrs: size ipiv")
+		return onError(linalg.ErrShape, "Gttrs: size ipiv")
 	}
 	if !matrix.EqualTypes(DL, D, DU, DU2, B) {
-		return onError("Gttrs: matrix types")
+		return onError(linalg.ErrType, "Gttrs: matrix types")
 	}
 	var info int = -1
 	switch DL.(type) {
@@ -121,10 +126,10 @@ func Gtrrs(DL, D, DU, DU2, B matrix.Matrix, ipiv []int32, opts ...linalg.Option)
 			DLa[ind.OffsetDL:], Da[ind.OffsetD:], DUa[ind.OffsetDU:], DU2a,
 			ipiv, Ba[ind.OffsetB:], ind.LDb)
 	case *matrix.ComplexMatrix:
-		return onError("Gttrs: complex valued not yet implemented")
+		return onError(linalg.ErrNotImplemented, "Gttrs: complex valued not yet implemented")
 	}
 	if info != 0 {
-		return onError(fmt.Sprintf("Gttrs lapack error: %d", info))
+		return onLapackError("Gttrs", info, linalg.ErrParameter)
 	}
 	return nil
 }
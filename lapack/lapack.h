@@ -84,6 +84,21 @@ extern void dpbsv_(char *uplo, int *n, int *kd, int *nrhs, double *A,
 extern void zpbsv_(char *uplo, int *n, int *kd, int *nrhs, void *A,
     int *lda, void *B, int *ldb, int *info);
 
+extern void dpftrf_(char *transr, char *uplo, int *n, double *A, int *info);
+extern void zpftrf_(char *transr, char *uplo, int *n, void *A, int *info);
+extern void dpftrs_(char *transr, char *uplo, int *n, int *nrhs, double *A,
+    double *B, int *ldb, int *info);
+extern void zpftrs_(char *transr, char *uplo, int *n, int *nrhs, void *A,
+    void *B, int *ldb, int *info);
+extern void dtrttf_(char *transr, char *uplo, int *n, double *A, int *lda,
+    double *ARF, int *info);
+extern void ztrttf_(char *transr, char *uplo, int *n, void *A, int *lda,
+    void *ARF, int *info);
+extern void dtfttr_(char *transr, char *uplo, int *n, double *ARF, double *A,
+    int *lda, int *info);
+extern void ztfttr_(char *transr, char *uplo, int *n, void *ARF, void *A,
+    int *lda, int *info);
+
 extern void dpttrf_(int *n, double *d, double *e, int *info);
 extern void zpttrf_(int *n, double *d, void *e, int *info);
 extern void dpttrs_(int *n, int *nrhs, double *d, double *e, double *B,
@@ -208,6 +223,21 @@ extern void zhegv_(int *itype, char *jobz, char *uplo, int *n, void *A,
     int *lda, void *B, int *ldb, double *W, void *work, int *lwork,
     double *rwork, int *info);
 
+extern void dggev_(char *jobvl, char *jobvr, int *n, double *A, int *ldA,
+    double *B, int *ldB, double *alphar, double *alphai, double *beta,
+    double *vl, int *ldvl, double *vr, int *ldvr, double *work,
+    int *lwork, int *info);
+extern void dgeev_(char *jobvl, char *jobvr, int *n, double *A, int *ldA,
+    double *wr, double *wi, double *vl, int *ldvl, double *vr, int *ldvr,
+    double *work, int *lwork, int *info);
+
+extern void dtrcon_(char *norm, char *uplo, char *diag, int *n, double *A,
+    int *ldA, double *rcond, double *work, int *iwork, int *info);
+extern void dgecon_(char *norm, int *n, double *A, int *ldA, double *anorm,
+    double *rcond, double *work, int *iwork, int *info);
+extern void ddisna_(char *job, int *m, int *n, double *d, double *sep,
+    int *info);
+
 extern void dgesvd_(char *jobu, char *jobvt, int *m, int *n, double *A,
     int *ldA, double *S, double *U, int *ldU, double *Vt, int *ldVt,
     double *work, int *lwork, int *info);
@@ -1,7 +1,13 @@
 package lapack
 
 import (
+	"errors"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/bigfloat"
 	"github.com/nvcook42/matrix"
+	"math"
+	"math/big"
+	"math/cmplx"
 	"testing"
 )
 
@@ -16,6 +22,683 @@ func TestDGetrf(t *testing.T) {
 	t.Logf("post A:\n%s\n", A)
 }
 
+func TestZSysv(t *testing.T) {
+	// complex symmetric, not Hermitian
+	A := matrix.ComplexNew(3, 3, []complex128{
+		4 + 1i, 1 - 2i, 2i,
+		1 - 2i, 3, 1 + 1i,
+		2i, 1 + 1i, 5 - 1i})
+	X0 := matrix.ComplexNew(3, 1, []complex128{1, 1i, 2 - 1i})
+	B := matrix.ComplexZeros(3, 1)
+	for i := 0; i < 3; i++ {
+		var s complex128
+		for k := 0; k < 3; k++ {
+			s += A.GetAt(i, k) * X0.GetAt(k, 0)
+		}
+		B.SetAt(i, 0, s)
+	}
+	X := B.Copy()
+	if err := Sysv(A, X, nil, linalg.OptLower); err != nil {
+		t.Logf("Sysv: %v\n", err)
+		t.FailNow()
+	}
+	ipiv := make([]int32, 3)
+	LD := A.Copy()
+	Y := B.Copy()
+	if err := Sytrf(LD, ipiv, linalg.OptLower); err != nil {
+		t.Logf("Sytrf: %v\n", err)
+		t.FailNow()
+	}
+	if err := Sytrs(LD, Y, ipiv, linalg.OptLower); err != nil {
+		t.Logf("Sytrs: %v\n", err)
+		t.FailNow()
+	}
+	for i := 0; i < 3; i++ {
+		if cmplx.Abs(X.GetAt(i, 0)-X0.GetAt(i, 0)) > 1e-12 || cmplx.Abs(Y.GetAt(i, 0)-X0.GetAt(i, 0)) > 1e-12 {
+			t.Logf("X=\n%v\nY=\n%v\nwant\n%v\n", X, Y, X0)
+			t.Fail()
+			break
+		}
+	}
+}
+
+func TestQep(t *testing.T) {
+	// damped two mass system
+	M := matrix.FloatNew(2, 2, []float64{2, 0, 0, 1})
+	C := matrix.FloatNew(2, 2, []float64{0.3, -0.1, -0.1, 0.1})
+	K := matrix.FloatNew(2, 2, []float64{3, -1, -1, 1})
+	lambda := matrix.ComplexZeros(4, 1)
+	X := matrix.ComplexZeros(2, 4)
+	if err := Qep(M, C, K, lambda, X); err != nil {
+		t.Logf("Qep: %v\n", err)
+		t.FailNow()
+	}
+	for k := 0; k < 4; k++ {
+		l := lambda.GetAt(k, 0)
+		for i := 0; i < 2; i++ {
+			var r complex128
+			for j := 0; j < 2; j++ {
+				q := l*l*complex(M.GetAt(i, j), 0) + l*complex(C.GetAt(i, j), 0) + complex(K.GetAt(i, j), 0)
+				r += q * X.GetAt(j, k)
+			}
+			if cmplx.Abs(r) > 1e-12 {
+				t.Logf("residual %v for lambda %v\n", r, l)
+				t.Fail()
+			}
+		}
+	}
+}
+
+
+func TestSafeCholesky(t *testing.T) {
+	// rank one, positive semidefinite
+	A := matrix.FloatNew(3, 3, []float64{1, 1, 1, 1, 1, 1, 1, 1, 1})
+	jitter, err := SafeCholesky(A, linalg.OptLower)
+	t.Logf("jitter=%g, err=%v\nL:\n%v\n", jitter, err, A)
+	if err != nil || jitter <= 0.0 {
+		t.Fail()
+	}
+	B := matrix.FloatNew(2, 2, []float64{4, 2, 2, 3})
+	jitter, err = SafeCholesky(B)
+	if err != nil || jitter != 0.0 {
+		t.Logf("jitter=%g, err=%v\n", jitter, err)
+		t.Fail()
+	}
+}
+
+func TestLarfb(t *testing.T) {
+	// Q from Geqrf applied as one block reflector must be orthogonal
+	A := matrix.FloatNew(4, 2, []float64{1, 2, 3, 4, 2, 0, 1, 5})
+	tau := matrix.FloatZeros(2, 1)
+	if err := Geqrf(A, tau); err != nil {
+		t.Fatalf("Geqrf: %v\n", err)
+	}
+	V := matrix.FloatZeros(4, 2)
+	for j := 0; j < 2; j++ {
+		V.SetAt(j, j, 1.0)
+		for i := j + 1; i < 4; i++ {
+			V.SetAt(i, j, A.GetAt(i, j))
+		}
+	}
+	T := matrix.FloatZeros(2, 2)
+	if err := Larft(V, tau, T); err != nil {
+		t.Fatalf("Larft: %v\n", err)
+	}
+	Q := matrix.FloatIdentity(4)
+	if err := Larfb(V, T, Q, linalg.OptLeft, linalg.OptNoTrans); err != nil {
+		t.Fatalf("Larfb: %v\n", err)
+	}
+	if err := Larfb(V, T, Q, linalg.OptLeft, linalg.OptTrans); err != nil {
+		t.Fatalf("Larfb: %v\n", err)
+	}
+	// H^T*H = I
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			d := Q.GetAt(i, j)
+			if i == j {
+				d -= 1.0
+			}
+			if d > 1e-12 || d < -1e-12 {
+				t.Logf("H^T*H:\n%v\n", Q)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestPivotDiagnostics(t *testing.T) {
+	// Wilkinson's example: growth 2^(n-1) with partial pivoting
+	n := 6
+	A := matrix.FloatZeros(n, n)
+	for i := 0; i < n; i++ {
+		A.SetAt(i, i, 1.0)
+		A.SetAt(i, n-1, 1.0)
+		for j := 0; j < i; j++ {
+			A.SetAt(i, j, -1.0)
+		}
+	}
+	var d PivotDiagnostics
+	if err := Getrf(A, make([]int32, n), WithDiagnostics(&d)); err != nil {
+		t.Fatalf("Getrf: %v\n", err)
+	}
+	t.Logf("LU diagnostics: %+v\n", d)
+	if d.Growth != 32.0 || d.MinPivot != 1.0 {
+		t.Fail()
+	}
+	S := matrix.FloatNew(2, 2, []float64{4, 2, 2, 3})
+	if err := Potrf(S, WithDiagnostics(&d)); err != nil {
+		t.Fatalf("Potrf: %v\n", err)
+	}
+	t.Logf("Cholesky diagnostics: %+v\n", d)
+	if d.MaxPivot != 2.0 || d.Growth > 1.0 {
+		t.Fail()
+	}
+}
+
+func TestLstsq(t *testing.T) {
+	// fit a line through four points
+	A := matrix.FloatNew(4, 2, []float64{1, 1, 1, 1, 0, 1, 2, 3})
+	B := matrix.FloatNew(4, 1, []float64{1, 3, 5, 7.5})
+	res, err := Lstsq(A, B)
+	if err != nil {
+		t.Fatalf("Lstsq: %v\n", err)
+	}
+	t.Logf("X:\n%v\nrcond=%g, residual=%v, sensitivity=%v\n", res.X, res.RCond, res.Residual, res.Sensitivity)
+	// normal equations A^T*(B - A*X) = 0
+	R := matrix.Minus(B, matrix.Times(A, res.X))
+	if math.Abs(matrix.Times(A.Transpose(), R).Max()) > 1e-12 {
+		t.Fail()
+	}
+	if res.RCond <= 0.0 || res.RCond > 1.0 || res.Sensitivity[0] < 1.0/res.RCond {
+		t.Fail()
+	}
+}
+
+func TestEig(t *testing.T) {
+	// rotation block with eigenvalues 1+-2i and a nearly defective pair
+	A := matrix.FloatNew(4, 4, []float64{
+		1, -2, 0, 0,
+		2, 1, 0, 0,
+		0, 0, 3, 0,
+		0, 0, 1e4, 3.001})
+	res, err := Eig(A)
+	if err != nil {
+		t.Fatalf("Eig: %v\n", err)
+	}
+	t.Logf("values:\n%v\ncond:\n%v\n", res.Values, res.Cond)
+	for k := 0; k < 4; k++ {
+		lambda := res.Values.GetAt(k, 0)
+		c := res.Cond.GetAt(k, 0)
+		if imag(lambda) != 0.0 && math.Abs(c-1.0) > 1e-10 {
+			t.Logf("normal block cond %d: %g\n", k, c)
+			t.Fail()
+		}
+		if imag(lambda) == 0.0 && c < 1e3 {
+			t.Logf("nearly defective cond %d: %g\n", k, c)
+			t.Fail()
+		}
+	}
+}
+
+func TestEigSym(t *testing.T) {
+	A := matrix.FloatNew(3, 3, []float64{2, 0, 0, 0, 1, 0, 0, 0, 1.001})
+	res, err := EigSym(A)
+	if err != nil {
+		t.Fatalf("EigSym: %v\n", err)
+	}
+	t.Logf("values:\n%v\nsep:\n%v\n", res.Values, res.Sep)
+	if math.Abs(res.Sep.GetAt(0, 0)-0.001) > 1e-12 || math.Abs(res.Sep.GetAt(2, 0)-0.999) > 1e-12 {
+		t.Fail()
+	}
+}
+
+func TestRefineBig(t *testing.T) {
+	// Hilbert matrix of order 6, cond about 1.5e7, and exact right hand
+	// side for solution of all ones
+	n := 6
+	A := bigfloat.Zeros(n, n, 256)
+	B := bigfloat.Zeros(n, 1, 256)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			h := A.GetAt(i, j)
+			h.Quo(big.NewFloat(1), big.NewFloat(float64(i+j+1)))
+			B.GetAt(i, 0).Add(B.GetAt(i, 0), h)
+		}
+	}
+	X, bound, err := RefineBig(A, B, 40)
+	t.Logf("X:\n%v\nbound=%g, err=%v\n", X, bound, err)
+	if err != nil || bound > 1e-40 {
+		t.FailNow()
+	}
+	for i := 0; i < n; i++ {
+		e := new(big.Float).Sub(X.GetAt(i, 0), big.NewFloat(1))
+		if f, _ := e.Float64(); math.Abs(f) > 1e-38 {
+			t.Logf("error %d: %g\n", i, f)
+			t.Fail()
+		}
+	}
+}
+
+func TestFactorCache(t *testing.T) {
+	cache := NewFactorCache(2)
+	A := matrix.FloatNew(2, 2, []float64{4, 1, 1, 3})
+	f1, err := cache.LU(A)
+	if err != nil {
+		t.Fatalf("LU: %v\n", err)
+	}
+	// same content in another copy hits the cache
+	f2, _ := cache.LU(A.Copy())
+	if f1 != f2 {
+		t.Fail()
+	}
+	B := matrix.FloatNew(2, 1, []float64{1, 2})
+	if err = f1.Solve(B); err != nil || math.Abs(4*B.GetAt(0, 0)+B.GetAt(1, 0)-1) > 1e-14 {
+		t.Logf("X: %v, %v\n", B, err)
+		t.Fail()
+	}
+	if _, err = cache.Cholesky(A, linalg.StringOpt("key", "A")); err != nil {
+		t.Fatalf("Cholesky: %v\n", err)
+	}
+	cache.Invalidate("A")
+	size, hits, misses := cache.Stats()
+	t.Logf("size=%d hits=%d misses=%d\n", size, hits, misses)
+	if size != 1 || hits != 1 || misses != 2 {
+		t.Fail()
+	}
+}
+
+func TestSolveVector(t *testing.T) {
+	A := matrix.FloatNew(3, 3, []float64{4, 1, 0, 1, 4, 1, 0, 1, 4})
+	b := []float64{5, 6, 5}
+	x, err := GesvVector(A, b, nil)
+	if err != nil {
+		t.Fatalf("GesvVector: %v\n", err)
+	}
+	t.Logf("x: %v\n", x)
+	for i := range x {
+		if math.Abs(x[i]-1.0) > 1e-14 {
+			t.Fail()
+		}
+	}
+	if b[0] != 5 {
+		t.Logf("b changed\n")
+		t.Fail()
+	}
+	x, err = PosvVector(A.Copy(), b)
+	if err != nil || math.Abs(x[1]-1.0) > 1e-14 {
+		t.Logf("PosvVector: %v %v\n", x, err)
+		t.Fail()
+	}
+	// line through three points, the third off by 0.5
+	L := matrix.FloatNew(3, 2, []float64{1, 1, 1, 0, 1, 2})
+	x, err = GelsVector(L, []float64{1, 2, 3.5})
+	if err != nil || len(x) != 2 {
+		t.Fatalf("GelsVector: %v %v\n", x, err)
+	}
+	if math.Abs(x[1]-1.25) > 1e-14 {
+		t.Logf("GelsVector: %v\n", x)
+		t.Fail()
+	}
+}
+
+func TestPbsv(t *testing.T) {
+	// tridiagonal Hermitian, lower band storage; solution is all ones
+	AB := matrix.ComplexNew(2, 3, []complex128{4, 1 + 1i, 4, 1 + 1i, 4, 0})
+	B := matrix.ComplexNew(3, 1, []complex128{5 - 1i, 6, 5 + 1i})
+	if err := Pbsv(AB, B, linalg.OptLower); err != nil {
+		t.Fatalf("Pbsv complex: %v\n", err)
+	}
+	for _, v := range B.ComplexArray() {
+		if cmplx.Abs(v-1) > 1e-14 {
+			t.Logf("complex X: %v\n", B)
+			t.Fail()
+		}
+	}
+	F := matrix.FloatNew(2, 3, []float64{4, 1, 4, 1, 4, 0})
+	if err := Pbtrf(F, linalg.OptLower); err != nil {
+		t.Fatalf("Pbtrf: %v\n", err)
+	}
+	X := matrix.FloatNew(3, 1, []float64{5, 6, 5})
+	if err := Pbtrs(F, X, linalg.OptLower); err != nil {
+		t.Fatalf("Pbtrs: %v\n", err)
+	}
+	for _, v := range X.FloatArray() {
+		if math.Abs(v-1) > 1e-14 {
+			t.Logf("float X: %v\n", X)
+			t.Fail()
+		}
+	}
+}
+
+func TestRFP(t *testing.T) {
+	A := matrix.FloatNew(3, 3, []float64{4, 1, 0, 1, 4, 1, 0, 1, 4})
+	for _, transr := range []string{"N", "T"} {
+		ARF := matrix.FloatZeros(6, 1)
+		opts := []linalg.Option{linalg.OptLower, linalg.StringOpt("transr", transr)}
+		if err := Trttf(A, ARF, opts...); err != nil {
+			t.Fatalf("Trttf: %v\n", err)
+		}
+		if err := Pftrf(ARF, opts...); err != nil {
+			t.Fatalf("Pftrf: %v\n", err)
+		}
+		B := matrix.FloatNew(3, 1, []float64{5, 6, 5})
+		if err := Pftrs(ARF, B, opts...); err != nil {
+			t.Fatalf("Pftrs: %v\n", err)
+		}
+		for _, v := range B.FloatArray() {
+			if math.Abs(v-1) > 1e-14 {
+				t.Logf("transr %s: X: %v\n", transr, B)
+				t.Fail()
+			}
+		}
+		// the factor in full format is lower triangular with L*L^T = A
+		L := matrix.FloatZeros(3, 3)
+		if err := Tfttr(ARF, L, opts...); err != nil {
+			t.Fatalf("Tfttr: %v\n", err)
+		}
+		if math.Abs(L.GetAt(0, 0)-2) > 1e-15 || math.Abs(L.GetAt(1, 0)-0.5) > 1e-15 {
+			t.Logf("transr %s: L: %v\n", transr, L)
+			t.Fail()
+		}
+	}
+}
+
+func TestSolveEquilibrate(t *testing.T) {
+	// rows scaled by 1e-10 and 1e10
+	A := matrix.FloatNew(2, 2, []float64{2e-10, 1e10, 1e-10, 3e10})
+	B := matrix.FloatNew(2, 1, []float64{3e-10, 4e10})
+	res, err := Solve(A, B)
+	if err != nil {
+		t.Fatalf("Solve: %v\n", err)
+	}
+	X, eq := res.X, res.Equilibration
+	t.Logf("X: %v\nequilibration: %+v\n", X, eq)
+	if !eq.Applied || eq.R == nil || !eq.Trusted || eq.RCondAfter <= eq.RCondBefore {
+		t.Fail()
+	}
+	if math.Abs(X.GetAt(0, 0)-1) > 1e-14 || math.Abs(X.GetAt(1, 0)-1) > 1e-14 {
+		t.Fail()
+	}
+	res, _ = Solve(A, B, linalg.BoolOpt("equilibrate", false))
+	eq = res.Equilibration
+	if eq.Applied || eq.RCondAfter != eq.RCondBefore {
+		t.Logf("equilibrate=false: %+v\n", eq)
+		t.Fail()
+	}
+	// column scaled least squares problem
+	A = matrix.FloatNew(3, 2, []float64{1, 1, 1, 0, 1e8, 2e8})
+	B = matrix.FloatNew(3, 1, []float64{1, 2, 3})
+	ls, err := Lstsq(A, B)
+	if err != nil {
+		t.Fatalf("Lstsq: %v\n", err)
+	}
+	t.Logf("Lstsq equilibration: %+v\n", ls.Equilibration)
+	R := matrix.Minus(B, matrix.Times(A, ls.X))
+	if !ls.Equilibration.Applied || ls.Equilibration.R != nil ||
+		math.Abs(matrix.Times(A.Transpose(), R).Max()) > 1e-6 {
+		t.Fail()
+	}
+}
+
+func TestEigSymDerivatives(t *testing.T) {
+	A := matrix.FloatNew(3, 3, []float64{4, 1, 0, 1, 3, 1, 0, 1, 1})
+	dA := matrix.FloatNew(3, 3, []float64{1, 2, 0, 2, -1, 1, 0, 1, 0.5})
+	res, err := EigSym(A)
+	if err != nil {
+		t.Fatalf("EigSym: %v\n", err)
+	}
+	dW, err := res.ValueDerivatives(dA)
+	if err != nil {
+		t.Fatalf("ValueDerivatives: %v\n", err)
+	}
+	dV, err := res.VectorDerivatives(dA)
+	if err != nil {
+		t.Fatalf("VectorDerivatives: %v\n", err)
+	}
+	// central differences
+	h := 1e-6
+	rp, _ := EigSym(A.Plus(matrix.Scale(dA, h)))
+	rm, _ := EigSym(matrix.Minus(A, matrix.Scale(dA, h)))
+	for k := 0; k < 3; k++ {
+		d := (rp.Values.GetAt(k, 0) - rm.Values.GetAt(k, 0)) / (2 * h)
+		if math.Abs(d-dW.GetAt(k, 0)) > 1e-6 {
+			t.Logf("dw[%d]: %g, difference %g\n", k, dW.GetAt(k, 0), d)
+			t.Fail()
+		}
+		// align signs of the vectors
+		sp, sm := 0.0, 0.0
+		for i := 0; i < 3; i++ {
+			sp += rp.Vectors.GetAt(i, k) * res.Vectors.GetAt(i, k)
+			sm += rm.Vectors.GetAt(i, k) * res.Vectors.GetAt(i, k)
+		}
+		sp, sm = math.Copysign(1, sp), math.Copysign(1, sm)
+		for i := 0; i < 3; i++ {
+			d = (sp*rp.Vectors.GetAt(i, k) - sm*rm.Vectors.GetAt(i, k)) / (2 * h)
+			if math.Abs(d-dV.GetAt(i, k)) > 1e-6 {
+				t.Logf("dV[%d,%d]: %g, difference %g\n", i, k, dV.GetAt(i, k), d)
+				t.Fail()
+			}
+		}
+	}
+	// double eigenvalue: dw are the eigenvalues of the projected block
+	res, _ = EigSym(matrix.FloatIdentity(2))
+	dW, _ = res.ValueDerivatives(matrix.FloatNew(2, 2, []float64{0, 1, 1, 0}))
+	if math.Abs(dW.GetAt(0, 0)+1) > 1e-14 || math.Abs(dW.GetAt(1, 0)-1) > 1e-14 {
+		t.Logf("cluster dw: %v\n", dW)
+		t.Fail()
+	}
+	if _, err = res.VectorDerivatives(matrix.FloatIdentity(2)); err == nil {
+		t.Fail()
+	}
+}
+
+func TestPinvSolve(t *testing.T) {
+	A := matrix.FloatNew(1, 2, []float64{1, 1})
+	B := matrix.FloatNew(1, 1, []float64{2})
+	X, rank, err := PinvSolve(A, B)
+	if err != nil || rank != 1 || math.Abs(X.GetAt(0, 0)-1) > 1e-14 || math.Abs(X.GetAt(1, 0)-1) > 1e-14 {
+		t.Logf("minnorm: X=%v rank=%d err=%v\n", X, rank, err)
+		t.Fail()
+	}
+	X, _, _ = PinvSolve(A, B, linalg.StringOpt("solution", "basic"))
+	if X.GetAt(0, 0)*X.GetAt(1, 0) != 0 || math.Abs(X.GetAt(0, 0)+X.GetAt(1, 0)-2) > 1e-14 {
+		t.Logf("basic: X=%v\n", X)
+		t.Fail()
+	}
+	// min x1^2 + 4*x2^2 subject to x1 + x2 = 2
+	X, _, err = PinvSolve(A, B, linalg.StringOpt("solution", "weighted"), WithWeights([]float64{1, 4}))
+	if err != nil || math.Abs(X.GetAt(0, 0)-1.6) > 1e-14 || math.Abs(X.GetAt(1, 0)-0.4) > 1e-14 {
+		t.Logf("weighted: X=%v err=%v\n", X, err)
+		t.Fail()
+	}
+	// rank deficient
+	A = matrix.FloatNew(2, 2, []float64{1, 2, 1, 2})
+	B = matrix.FloatNew(2, 1, []float64{2, 4})
+	X, rank, _ = PinvSolve(A, B)
+	if rank != 1 || math.Abs(X.GetAt(0, 0)-1) > 1e-14 || math.Abs(X.GetAt(1, 0)-1) > 1e-14 {
+		t.Logf("rank deficient: X=%v rank=%d\n", X, rank)
+		t.Fail()
+	}
+	if _, _, err = PinvSolve(A, B, linalg.StringOpt("solution", "weighted")); err == nil {
+		t.Fail()
+	}
+}
+
+func TestSolveNonSquare(t *testing.T) {
+	A := matrix.FloatNew(4, 2, []float64{1, 1, 1, 1, 0, 1, 2, 3})
+	B := matrix.FloatNew(4, 1, []float64{1, 3, 5, 7.5})
+	res, err := Solve(A, B)
+	if err != nil || res.Formulation != LeastSquares || res.Rank != 2 || len(res.Residual) != 1 {
+		t.Logf("overdetermined: %+v, err=%v\n", res, err)
+		t.Fail()
+	}
+	res, err = Solve(A.Transpose(), matrix.FloatNew(2, 1, []float64{4, 6}))
+	if err != nil || res.Formulation != MinimumNorm || res.X.Rows() != 4 || res.Residual[0] > 1e-12 {
+		t.Logf("underdetermined: %+v, err=%v\n", res, err)
+		t.Fail()
+	}
+	if _, err = Solve(A, B, linalg.BoolOpt("nonsquare", false)); !errors.Is(err, linalg.ErrShape) {
+		t.Logf("nonsquare=false: %v\n", err)
+		t.Fail()
+	}
+	res, _ = Solve(matrix.FloatIdentity(2), matrix.FloatOnes(2, 1))
+	if res.Formulation != SquareSystem || res.Formulation.String() != "square" {
+		t.Fail()
+	}
+}
+
+func TestBlockTridiag(t *testing.T) {
+	D := []*matrix.FloatMatrix{
+		matrix.FloatNew(2, 2, []float64{4, 1, 1, 5}),
+		matrix.FloatNew(3, 3, []float64{6, 1, 0, 1, 6, 1, 0, 1, 6}),
+		matrix.FloatNew(2, 2, []float64{5, -1, 1, 4})}
+	L := []*matrix.FloatMatrix{
+		matrix.FloatNew(3, 2, []float64{1, 0, -1, 0.5, 1, 0}),
+		matrix.FloatNew(2, 3, []float64{1, 0, 0, 1, -1, 0.5})}
+	U := []*matrix.FloatMatrix{
+		matrix.FloatNew(2, 3, []float64{0.5, 1, 0, -1, 1, 0}),
+		matrix.FloatNew(3, 2, []float64{1, 0, 0, 1, 0.5, 0.5})}
+	// dense A with the blocks at row and column offsets 0, 2, 5
+	A := matrix.FloatZeros(7, 7)
+	off := []int{0, 2, 5}
+	for k := range D {
+		A.SetSubMatrix(off[k], off[k], D[k])
+		if k < 2 {
+			A.SetSubMatrix(off[k+1], off[k], L[k])
+			A.SetSubMatrix(off[k], off[k+1], U[k])
+		}
+	}
+	B := matrix.FloatNew(7, 2, []float64{1, 2, 3, 4, 5, 6, 7, -1, 0, 1, 0, -1, 2, 3})
+	X := B.Copy()
+	if err := BlockTridiagSolve(L, D, U, X); err != nil {
+		t.Fatalf("BlockTridiagSolve: %v\n", err)
+	}
+	for _, v := range matrix.Minus(matrix.Times(A, X), B).FloatArray() {
+		if math.Abs(v) > 1e-12 {
+			t.Logf("residual %v\n", matrix.Minus(matrix.Times(A, X), B))
+			t.Fail()
+			break
+		}
+	}
+	if err := BlockTridiagSolve(L[:1], D, U, X); !errors.Is(err, linalg.ErrShape) {
+		t.Logf("mismatched blocks: %v\n", err)
+		t.Fail()
+	}
+	// no blocks
+	F, err := NewBlockTridiag(nil, nil, nil)
+	if err != nil || F.Solve(matrix.FloatZeros(0, 1)) != nil {
+		t.Logf("empty block tridiagonal: %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestCyclicSolve(t *testing.T) {
+	n := 6
+	dl := []float64{1, -1, 0.5, 1, 2, -1.5}
+	d := []float64{5, 6, 4, 7, 5, 6}
+	du := []float64{-2, 1, 1, 0.5, -1, 1.25}
+	A := matrix.FloatZeros(n, n)
+	for i := 0; i < n; i++ {
+		A.SetAt(i, i, d[i])
+		A.SetAt((i+1)%n, i, dl[i])
+		A.SetAt(i, (i+1)%n, du[i])
+	}
+	B := matrix.FloatNew(n, 2, []float64{1, 2, 3, 4, 5, 6, -1, 0, 1, 0, -1, 2})
+	X := B.Copy()
+	err := PeriodicTridiagSolve(matrix.FloatVector(dl), matrix.FloatVector(d), matrix.FloatVector(du), X)
+	if err != nil {
+		t.Fatalf("PeriodicTridiagSolve: %v\n", err)
+	}
+	if r := matrix.Minus(matrix.Times(A, X), B); maxAbs(r) > 1e-12 {
+		t.Logf("PeriodicTridiagSolve residual %v\n", r)
+		t.Fail()
+	}
+	// same matrix in cyclic band storage, kl = ku = 1
+	S := matrix.FloatZeros(3, n)
+	for j := 0; j < n; j++ {
+		S.SetAt(0, j, A.GetAt((j+n-1)%n, j))
+		S.SetAt(1, j, A.GetAt(j, j))
+		S.SetAt(2, j, A.GetAt((j+1)%n, j))
+	}
+	X = B.Copy()
+	if err = CyclicBandSolve(S, 1, 1, X); err != nil {
+		t.Fatalf("CyclicBandSolve: %v\n", err)
+	}
+	if r := matrix.Minus(matrix.Times(A, X), B); maxAbs(r) > 1e-12 {
+		t.Logf("CyclicBandSolve residual %v\n", r)
+		t.Fail()
+	}
+}
+
+func TestVerify(t *testing.T) {
+	A := matrix.FloatNew(3, 3, []float64{4, 1, 0, 1, 4, 1, 0, 1, 4})
+	B := matrix.FloatNew(3, 1, []float64{5, 6, 5})
+	res, err := Solve(A, B)
+	if err != nil || res.Verification != nil {
+		t.Fatalf("Solve: %v, verification %v\n", err, res.Verification)
+	}
+	res, err = Solve(A, B, linalg.FloatOpt("verify", 1e-12))
+	if err != nil || res.Verification == nil || !res.Verification.Passed {
+		t.Fatalf("Solve: %v, verification %+v\n", err, res.Verification)
+	}
+	// least squares through the overdetermined path
+	L := matrix.FloatNew(3, 2, []float64{1, 1, 1, 0, 1, 2})
+	old := SetVerify(1e-12)
+	defer SetVerify(old)
+	res, err = Solve(L, matrix.FloatNew(3, 1, []float64{1, 2, 3.5}))
+	if err != nil || res.Verification == nil || !res.Verification.Passed {
+		t.Fatalf("Solve: %v, verification %+v\n", err, res.Verification)
+	}
+	eig, err := Eig(matrix.FloatNew(2, 2, []float64{0, -1, 1, 0}))
+	if err != nil || !eig.Verification.Passed || len(eig.Verification.Residual) != 2 {
+		t.Fatalf("Eig: %v, verification %+v\n", err, eig.Verification)
+	}
+	sym, err := EigSym(A)
+	if err != nil || !sym.Verification.Passed {
+		t.Fatalf("EigSym: %v, verification %+v\n", err, sym.Verification)
+	}
+	SetVerify(0)
+	var v Verification
+	S := matrix.FloatZeros(2, 1)
+	U := matrix.FloatZeros(3, 3)
+	Vt := matrix.FloatZeros(2, 2)
+	err = Gesvd(L.Copy(), S, U, Vt, linalg.OptJobuAll, linalg.OptJobvtAll, WithVerification(&v))
+	t.Logf("SVD verification: %+v\n", v)
+	if err != nil || !v.Passed || v.Max > 1e-14 {
+		t.Fail()
+	}
+	// a wrong solution fails verification
+	bad := startVerify(linalg.FloatOpt("verify", 1e-12))
+	verifySolve(bad, A, matrix.FloatWithValue(3, 1, 1.1), B)
+	if bad.Passed {
+		t.Logf("wrong solution passed: %+v\n", bad)
+		t.Fail()
+	}
+}
+
+func TestCrossCheck(t *testing.T) {
+	A := matrix.FloatNew(3, 3, []float64{4, 1, 0, 1, 4, 1, 0, 1, 4})
+	B := matrix.FloatNew(3, 1, []float64{5, 6, 5})
+	res, err := Solve(A, B)
+	if err != nil || res.CrossCheck != nil {
+		t.Fatalf("Solve: %v, cross-check %v\n", err, res.CrossCheck)
+	}
+	res, err = Solve(A, B, linalg.FloatOpt("crosscheck", 1e-12))
+	if err != nil || res.CrossCheck == nil || !res.CrossCheck.Passed {
+		t.Fatalf("Solve: %v, cross-check %+v\n", err, res.CrossCheck)
+	}
+	old := SetCrossCheck(1e-12)
+	defer SetCrossCheck(old)
+	var c CrossCheck
+	LU := matrix.FloatNew(3, 3, []float64{1, 3, 2, 4, 0, 1, 2, 5, 3})
+	ipiv := make([]int32, 3)
+	if err = Getrf(LU, ipiv, WithCrossCheck(&c)); err != nil || !c.Passed || c.Tolerance != 1e-12 {
+		t.Fatalf("Getrf: %v, cross-check %+v\n", err, c)
+	}
+	// singular matrix is singular for both backends
+	S := matrix.FloatNew(2, 2, []float64{1, 2, 2, 4})
+	err = Getrf(S, ipiv, WithCrossCheck(&c))
+	if !errors.Is(err, linalg.ErrSingular) || !c.Passed {
+		t.Fatalf("Getrf singular: %v, cross-check %+v\n", err, c)
+	}
+	SetCrossCheck(0)
+	// wrong factors and wrong pivots fail the cross-check
+	bad := startCrossCheck(linalg.FloatOpt("crosscheck", 1e-12))
+	G := matrix.FloatNew(2, 2, []float64{2, 1, 1, 3})
+	crossCheckLU(bad, []float64{2, 0.5, 1, 2.6}, 2, 2, 2, []int32{1, 2}, G, nil)
+	if bad.Passed {
+		t.Logf("wrong factors passed: %+v\n", bad)
+		t.Fail()
+	}
+	bad = startCrossCheck(linalg.FloatOpt("crosscheck", 1e-12))
+	G = matrix.FloatNew(2, 2, []float64{2, 1, 1, 3})
+	crossCheckLU(bad, []float64{1, 2, 3, -5}, 2, 2, 2, []int32{2, 2}, G, nil)
+	if bad.Passed || !math.IsInf(bad.Diff, 1) {
+		t.Logf("wrong pivots passed: %+v\n", bad)
+		t.Fail()
+	}
+}
+
 // Local Variables:
 // tab-width: 4
 // End:
@@ -0,0 +1,143 @@
+// Copyright (c) Harri Rautila, 2012,2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"strings"
+)
+
+// Return LAPACK direct and storev characters from options.
+func blockReflectorOpts(name string, opts ...linalg.Option) (direct, storev string, err error) {
+	direct = strings.ToUpper(linalg.GetStringOpt("direct", "F", opts...))
+	storev = strings.ToUpper(linalg.GetStringOpt("storev", "C", opts...))
+	if direct != "F" && direct != "B" {
+		err = onError(linalg.ErrParameter, name+": direct must be F or B")
+	} else if storev != "C" && storev != "R" {
+		err = onError(linalg.ErrParameter, name+": storev must be C or R")
+	}
+	return
+}
+
+/*
+ Triangular factor of a block reflector (compact WY representation).
+
+ PURPOSE
+ Forms the k by k triangular factor T of the block reflector
+
+  H = I - V*T*V^T
+
+ of order n, where H = H(1)*H(2)*...*H(k) if direct is "F" and
+ H = H(k)*...*H(2)*H(1) if direct is "B", and H(i) = I - tau[i]*v*v^T
+ is the elementary reflector with vector v stored in column (storev "C")
+ or row (storev "R") i of V, as computed by Geqrf.  T is upper triangular
+ if direct is "F" and lower triangular if direct is "B".
+
+ ARGUMENTS
+  V         float matrix, n by k if storev is "C" and k by n if "R"
+  tau       float matrix of length k
+  T         float matrix with at least k rows and k columns
+
+ OPTIONS
+  direct    string "F" (forward, default) or "B" (backward)
+  storev    string "C" (columnwise, default) or "R" (rowwise)
+
+*/
+func Larft(V, tau, T *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	defer guard("Larft", &err)()
+	direct, storev, err := blockReflectorOpts("Larft", opts...)
+	if err != nil {
+		return
+	}
+	k := tau.NumElements()
+	n, vk := V.Rows(), V.Cols()
+	if storev == "R" {
+		n, vk = vk, n
+	}
+	if vk < k {
+		return onError(linalg.ErrShape, "Larft: V has fewer than k reflectors")
+	}
+	if T.Rows() < k || T.Cols() < k {
+		return onError(linalg.ErrShape, "Larft: size T")
+	}
+	if k == 0 || n == 0 {
+		return nil
+	}
+	dlarft(direct, storev, n, k, V.FloatArray(), max(1, V.LeadingIndex()),
+		tau.FloatArray(), T.FloatArray(), max(1, T.LeadingIndex()))
+	return nil
+}
+
+/*
+ Applies a block reflector or its transpose to a general matrix.
+
+ PURPOSE
+ Computes
+  C := H*C   if side = PLeft  and trans = PNoTrans
+  C := H^T*C if side = PLeft  and trans = PTrans
+  C := C*H   if side = PRight and trans = PNoTrans
+  C := C*H^T if side = PRight and trans = PTrans
+
+ where H = I - V*T*V^T is the block reflector of k elementary reflectors
+ in V with triangular factor T computed by Larft.  C is m by n.  The
+ operation is done with matrix-matrix products.
+
+ ARGUMENTS
+  V         float matrix, storage as for Larft.  The order of H is m if
+            side is PLeft and n if side is PRight.
+  T         float matrix, k by k
+  C         float matrix
+
+ OPTIONS
+  side      PLeft or PRight
+  trans     PNoTrans or PTrans
+  direct    string "F" (forward, default) or "B" (backward)
+  storev    string "C" (columnwise, default) or "R" (rowwise)
+
+*/
+func Larfb(V, T, C *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	defer guard("Larfb", &err)()
+	pars, err := linalg.GetParameters(opts...)
+	if err != nil {
+		return
+	}
+	direct, storev, err := blockReflectorOpts("Larfb", opts...)
+	if err != nil {
+		return
+	}
+	if pars.Trans == linalg.PConjTrans {
+		pars.Trans = linalg.PTrans
+	}
+	k := T.Rows()
+	if T.Cols() != k {
+		return onError(linalg.ErrShape, "Larfb: T not square")
+	}
+	m, n := C.Rows(), C.Cols()
+	order := m
+	if pars.Side == linalg.PRight {
+		order = n
+	}
+	vn, vk := V.Rows(), V.Cols()
+	if storev == "R" {
+		vn, vk = vk, vn
+	}
+	if vn < order || vk < k {
+		return onError(linalg.ErrShape, "Larfb: size V")
+	}
+	if m == 0 || n == 0 || k == 0 {
+		return nil
+	}
+	dlarfb(linalg.ParamString(pars.Side), linalg.ParamString(pars.Trans), direct, storev,
+		m, n, k, V.FloatArray(), max(1, V.LeadingIndex()),
+		T.FloatArray(), max(1, T.LeadingIndex()), C.FloatArray(), max(1, C.LeadingIndex()))
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
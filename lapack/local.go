@@ -6,7 +6,14 @@
 
 package lapack
 
-import "errors"
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/internal/xerbla"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+	"runtime"
+)
 
 func min(a, b int) int {
 	if a < b {
@@ -28,11 +35,80 @@ func PanicOnError(flag bool) {
 	panicOnError = flag
 }
 
-func onError(msg string) error {
+func onError(kind error, msg string) error {
+	err := linalg.NewError(kind, msg)
 	if panicOnError {
-		panic(msg)
+		panic(err)
+	}
+	return err
+}
+
+func onLapackError(name string, info int, kind error) error {
+	err := linalg.LapackError(name, info, kind)
+	if panicOnError {
+		panic(err)
+	}
+	return err
+}
+
+// Return ErrNoConvergence error with indices of unconverged values, see
+// linalg.ConvergenceError.
+func onConvergenceError(name string, info int, unconverged []int) error {
+	err := linalg.ConvergenceError(name, info, unconverged)
+	if panicOnError {
+		panic(err)
+	}
+	return err
+}
+
+// Return error if op on arguments of size dims would exceed the memory
+// budget, see linalg.CheckMemory.
+func checkMemory(op string, opts []linalg.Option, dims ...int) error {
+	err := linalg.CheckMemory(op, opts, dims...)
+	if err != nil && panicOnError {
+		panic(err)
+	}
+	return err
+}
+
+// Return error if real scalar argument name of routine op is missing or
+// not a number, see linalg.Scalar.
+func checkScalar(op, name string, v float64) error {
+	_, err := linalg.FloatScalar(v).Real(op + ": " + name)
+	if err != nil && panicOnError {
+		panic(err)
+	}
+	return err
+}
+
+// Return error if any output argument is a read-only view.
+func writable(name string, mats ...matrix.Matrix) error {
+	if matops.IsReadOnly(mats...) {
+		return onError(linalg.ErrReadOnly, name+": output argument is read-only")
+	}
+	return nil
+}
+
+// Recover from native library failures for the duration of an exported
+// call and report illegal arguments noticed by XERBLA. Works as guard in
+// the blas package.
+func guard(name string, err *error) func() {
+	runtime.LockOSThread()
+	xerbla.Clear()
+	return func() {
+		defer runtime.UnlockOSThread()
+		if r := recover(); r != nil {
+			if panicOnError {
+				panic(r)
+			}
+			*err = linalg.NewError(linalg.ErrParameter, fmt.Sprintf("%s: %v", name, r))
+			return
+		}
+		if rname, info := xerbla.Last(); info != 0 && *err == nil {
+			*err = onError(linalg.ErrParameter,
+				fmt.Sprintf("%s: parameter %d to %s had an illegal value", name, info, rname))
+		}
 	}
-	return errors.New(msg)
 }
 
 // Local Variables:
@@ -0,0 +1,169 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Solution of a full rank least squares problem with sensitivity estimates.
+//
+// RCond is the Trcon estimate of the reciprocal 1-norm condition number
+// of the triangular factor R of A = Q*R; it is within a factor sqrt(n) of
+// the 2-norm condition number of A. Residual[j] is the 2-norm of
+// B(:,j) - A*X(:,j).
+//
+// Sensitivity[j] is kappa + kappa^2*tan(theta[j]), with kappa = 1/RCond
+// and theta[j] the angle between B(:,j) and the range of A. Up to a small
+// constant it bounds the relative change of X(:,j) per unit relative
+// perturbation of A and B (Golub and Van Loan, section 5.3). For
+// problems with large residuals it grows with the square of kappa.
+//
+// Equilibration reports the column scaling applied to A; rows are never
+// scaled as that would change the problem. RCond is its RCondBefore.
+//
+// Verification holds the optimality residuals ||A^T*(B - A*X)|| relative
+// to ||A||*(||A||*||X|| + ||B||) if verification is enabled, see
+// Verification, and is nil otherwise.
+type LstsqResult struct {
+	X             *matrix.FloatMatrix
+	Residual      []float64
+	RCond         float64
+	Sensitivity   []float64
+	Equilibration Equilibration
+	Verification  *Verification
+}
+
+// Solve min ||A*X - B|| for m by n float matrix A of full column rank,
+// m >= n, with QR factorization. A and B are not changed. Badly scaled
+// columns of A are equilibrated unless option equilibrate is false; see
+// Solve.
+func Lstsq(A, B *matrix.FloatMatrix, opts ...linalg.Option) (res *LstsqResult, err error) {
+	m, n, nrhs := A.Rows(), A.Cols(), B.Cols()
+	op := linalg.StartOp("lapack.Lstsq", m, n, nrhs)
+	defer op.Finish(&err)
+	if B.Rows() != m {
+		return nil, onError(linalg.ErrShape, "Lstsq: rows of A and B differ")
+	}
+	if m < n {
+		return nil, onError(linalg.ErrShape, "Lstsq: A has more columns than rows")
+	}
+	if err := checkMemory("lstsq", opts, m, n, nrhs); err != nil {
+		return nil, err
+	}
+	res = &LstsqResult{
+		X:           matrix.FloatZeros(n, nrhs),
+		Residual:    make([]float64, nrhs),
+		RCond:       1.0,
+		Sensitivity: make([]float64, nrhs),
+		Equilibration: Equilibration{
+			RCondBefore: 1.0, RCondAfter: 1.0, Trusted: true},
+	}
+	if n == 0 || nrhs == 0 {
+		for j := range res.Residual {
+			res.Residual[j] = columnNorm(B, j, 0, m)
+		}
+		return res, nil
+	}
+	eq := &res.Equilibration
+	if linalg.GetBoolOpt("equilibrate", true, opts...) {
+		_, c, _, colcnd, _ := equilibrationFactors(A, false)
+		if colcnd < equilibrateThreshold {
+			eq.C, eq.Applied = c, true
+		}
+	}
+	QR := A.Copy()
+	scaleRowsCols(QR, nil, eq.C)
+	tau := matrix.FloatZeros(n, 1)
+	if err := Geqrf(QR, tau); err != nil {
+		return nil, err
+	}
+	// C = Q^T*B; the last m-n rows are the residual in the rotated basis
+	C := B.Copy()
+	if err := Ormqr(QR, tau, C, linalg.OptLeft, linalg.OptTrans); err != nil {
+		return nil, err
+	}
+	rcond, err := Trcon(QR, linalg.OptUpper, linalg.IntOpt("n", n))
+	if err != nil {
+		return nil, err
+	}
+	if err := Trtrs(QR, C, linalg.OptUpper, linalg.IntOpt("n", n)); err != nil {
+		return nil, err
+	}
+	eq.RCondBefore, eq.RCondAfter = rcond, rcond
+	if eq.Applied {
+		// R*diag(1/c) is the triangular factor of A itself
+		R := QR.GetSubMatrix(0, 0, n, n)
+		scaleRowsCols(R, nil, reciprocals(eq.C))
+		if rcond, err = Trcon(R, linalg.OptUpper); err != nil {
+			return nil, err
+		}
+		eq.RCondBefore = rcond
+	}
+	res.RCond = rcond
+	if eq.RCondAfter < nearSingular {
+		op.Warn(fmt.Sprintf("near rank deficient: rcond %.3g", eq.RCondAfter))
+	}
+	kappa := 1.0 / rcond
+	for j := 0; j < nrhs; j++ {
+		r := columnNorm(C, j, n, m)
+		// norm of the projection of B(:,j) on the range of A
+		p := math.Sqrt(math.Max(0.0, math.Pow(columnNorm(B, j, 0, m), 2)-r*r))
+		res.Residual[j] = r
+		switch {
+		case r == 0.0:
+			res.Sensitivity[j] = kappa
+		case p == 0.0:
+			res.Sensitivity[j] = math.Inf(1)
+		default:
+			res.Sensitivity[j] = kappa + kappa*kappa*r/p
+		}
+	}
+	res.X = C.GetSubMatrix(0, 0, n, nrhs)
+	scaleRowsCols(res.X, eq.C, nil)
+	eq.Trusted = eq.RCondAfter >= nearSingular && allFinite(res.X)
+	if v := startVerify(opts...); v != nil {
+		verifyLstsq(v, A, res.X, B)
+		v.report(op, "least squares")
+		res.Verification = v
+	}
+	return res, nil
+}
+
+// Return elementwise reciprocals of s.
+func reciprocals(s []float64) []float64 {
+	r := make([]float64, len(s))
+	for i, v := range s {
+		r[i] = 1.0 / v
+	}
+	return r
+}
+
+// Return 2-norm of rows start to end-1 of column j of A.
+func columnNorm(A *matrix.FloatMatrix, j, start, end int) float64 {
+	scale, ssq := 0.0, 1.0
+	for i := start; i < end; i++ {
+		v := math.Abs(A.GetAt(i, j))
+		if v == 0.0 {
+			continue
+		}
+		if scale < v {
+			ssq = 1.0 + ssq*(scale/v)*(scale/v)
+			scale = v
+		} else {
+			ssq += (v / scale) * (v / scale)
+		}
+	}
+	return scale * math.Sqrt(ssq)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
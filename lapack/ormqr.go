@@ -8,8 +8,8 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
 	"github.com/nvcook42/matrix"
 )
 
@@ -50,7 +50,12 @@ import (
   offsetB   nonnegative integer
 
 */
-func Ormqr(A, tau, C matrix.Matrix, opts ...linalg.Option) error {
+func Ormqr(A, tau, C matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Ormqr", &err)()
+	if err = writable("Ormqr", C); err != nil {
+		return
+	}
+	A, tau = matops.Readable(A), matops.Readable(tau)
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
 		return err
@@ -81,36 +86,36 @@ func Ormqr(A, tau, C matrix.Matrix, opts ...linalg.Option) error {
 	switch pars.Side {
 	case linalg.PLeft:
 		if ind.K > ind.M {
-			onError("Ormqf: K")
+			onError(linalg.ErrParameter, "Ormqf: K")
 		}
 		if ind.LDa < max(1, ind.M) {
-			return onError("Ormqf: ldA")
+			return onError(linalg.ErrParameter, "Ormqf: ldA")
 		}
 	case linalg.PRight:
 		if ind.K > ind.N {
-			onError("Ormqf: K")
+			onError(linalg.ErrParameter, "Ormqf: K")
 		}
 		if ind.LDa < max(1, ind.N) {
-			return onError("Ormqf: ldA")
+			return onError(linalg.ErrParameter, "Ormqf: ldA")
 		}
 	}
 	if ind.OffsetA < 0 {
-		return onError("Ormqf: offsetA")
+		return onError(linalg.ErrParameter, "Ormqf: offsetA")
 	}
 	if A.NumElements() < ind.OffsetA+ind.K*arows {
-		return onError("Ormqf: sizeA")
+		return onError(linalg.ErrShape, "Ormqf: sizeA")
 	}
 	if ind.OffsetC < 0 {
-		return onError("Ormqf: offsetC")
+		return onError(linalg.ErrParameter, "Ormqf: offsetC")
 	}
 	if C.NumElements() < ind.OffsetC+(ind.N-1)*crows+ind.M {
-		return onError("Ormqf: sizeC")
+		return onError(linalg.ErrShape, "Ormqf: sizeC")
 	}
 	if tau.NumElements() < ind.K {
-		return onError("Ormqf: sizeTau")
+		return onError(linalg.ErrShape, "Ormqf: sizeTau")
 	}
 	if !matrix.EqualTypes(A, C, tau) {
-		return onError("Ormqf: arguments not of same type")
+		return onError(linalg.ErrType, "Ormqf: arguments not of same type")
 	}
 	info := -1
 	side := linalg.ParamString(pars.Side)
@@ -123,10 +128,10 @@ func Ormqr(A, tau, C matrix.Matrix, opts ...linalg.Option) error {
 		info = dormqr(side, trans, ind.M, ind.N, ind.K, Aa[ind.OffsetA:], ind.LDa,
 			taua, Ca[ind.OffsetC:], ind.LDc)
 	case *matrix.ComplexMatrix:
-		return onError("Ormqf: complex not implemented yet")
+		return onError(linalg.ErrNotImplemented, "Ormqf: complex not implemented yet")
 	}
 	if info != 0 {
-		return onError(fmt.Sprintf("Ormqr: lapack error %d", info))
+		return onLapackError("Ormqr", info, linalg.ErrParameter)
 	}
 	return nil
 }
@@ -0,0 +1,240 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+)
+
+/*
+ Cholesky factorization of a real symmetric or complex Hermitian positive
+ definite band matrix.
+
+ PURPOSE
+
+ Computes A = L*L^H or A = U^H*U with A an n by n positive definite band
+ matrix with kd subdiagonals (and superdiagonals).
+
+ On entry, A contains the kd+1 diagonals of the upper or lower triangle
+ in the BLAS format for band matrices: A[kd+i-j, j] = a_ij for upper and
+ A[i-j, j] = a_ij for lower storage. On exit A holds the factor in the
+ same format.
+
+ ARGUMENTS
+  A         float or complex matrix
+
+ OPTIONS
+  uplo      PLower or PUpper
+  n         nonnegative integer.  If negative, the default value is used.
+  kd        nonnegative integer.  If negative, the default value is used.
+            The default value is A.Rows()-1.
+  ldA       positive integer.  ldA >= kd+1.  If zero, the default value is used.
+  offsetA   nonnegative integer;
+
+*/
+func Pbtrf(A matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Pbtrf", &err)()
+	if err = writable("Pbtrf", A); err != nil {
+		return
+	}
+	pars, err := linalg.GetParameters(opts...)
+	if err != nil {
+		return err
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	kd := linalg.GetIntOpt("kd", -1, opts...)
+	if kd, err = checkPb("Pbtrf", ind, kd, A, nil); err != nil {
+		return err
+	}
+	if ind.N == 0 {
+		return nil
+	}
+	uplo := linalg.ParamString(pars.Uplo)
+	info := -1
+	switch A := A.(type) {
+	case *matrix.FloatMatrix:
+		info = dpbtrf(uplo, ind.N, kd, A.FloatArray()[ind.OffsetA:], ind.LDa)
+	case *matrix.ComplexMatrix:
+		info = zpbtrf(uplo, ind.N, kd, A.ComplexArray()[ind.OffsetA:], ind.LDa)
+	default:
+		return onError(linalg.ErrType, "Pbtrf: unknown types")
+	}
+	if info != 0 {
+		return onLapackError("Pbtrf", info, linalg.ErrNotPositiveDefinite)
+	}
+	return nil
+}
+
+/*
+ Solves a real symmetric or complex Hermitian positive definite set of
+ linear equations with a banded coefficient matrix, given the Cholesky
+ factorization computed by Pbtrf.
+
+ PURPOSE
+
+ Solves A*X = B with A n by n positive definite band matrix with kd
+ subdiagonals. On entry, A contains the factor computed by Pbtrf. On
+ exit B is replaced with the solution X.
+
+ ARGUMENTS
+  A         float or complex matrix
+  B         float or complex matrix.  Must have the same type as A.
+
+ OPTIONS
+  uplo      PLower or PUpper
+  n         nonnegative integer.  If negative, the default value is used.
+  kd        nonnegative integer.  If negative, the default value is used.
+            The default value is A.Rows()-1.
+  nrhs      nonnegative integer.  If negative, the default value is used.
+  ldA       positive integer.  ldA >= kd+1.  If zero, the default value is used.
+  ldB       positive integer.  ldB >= max(1,n).  If zero, the default value is used.
+  offsetA   nonnegative integer;
+  offsetB   nonnegative integer;
+
+*/
+func Pbtrs(A, B matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Pbtrs", &err)()
+	return solvePb("Pbtrs", A, B, opts...)
+}
+
+/*
+ Solves a real symmetric or complex Hermitian positive definite set of
+ linear equations with a banded coefficient matrix.
+
+ PURPOSE
+
+ Solves A*X = B with A n by n positive definite band matrix with kd
+ subdiagonals, stored as for Pbtrf. On exit A is replaced with its
+ Cholesky factor and B with the solution X.
+
+ ARGUMENTS
+  A         float or complex matrix
+  B         float or complex matrix.  Must have the same type as A.
+
+ OPTIONS
+  uplo      PLower or PUpper
+  n         nonnegative integer.  If negative, the default value is used.
+  kd        nonnegative integer.  If negative, the default value is used.
+            The default value is A.Rows()-1.
+  nrhs      nonnegative integer.  If negative, the default value is used.
+  ldA       positive integer.  ldA >= kd+1.  If zero, the default value is used.
+  ldB       positive integer.  ldB >= max(1,n).  If zero, the default value is used.
+  offsetA   nonnegative integer;
+  offsetB   nonnegative integer;
+
+*/
+func Pbsv(A, B matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Pbsv", &err)()
+	if err = writable("Pbsv", A); err != nil {
+		return
+	}
+	return solvePb("Pbsv", A, B, opts...)
+}
+
+// Solve with Pbtrs or Pbsv by name.
+func solvePb(name string, A, B matrix.Matrix, opts ...linalg.Option) error {
+	if err := writable(name, B); err != nil {
+		return err
+	}
+	if !matrix.EqualTypes(A, B) {
+		return onError(linalg.ErrType, name+": not same type")
+	}
+	pars, err := linalg.GetParameters(opts...)
+	if err != nil {
+		return err
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	kd := linalg.GetIntOpt("kd", -1, opts...)
+	if kd, err = checkPb(name, ind, kd, A, B); err != nil {
+		return err
+	}
+	if ind.N == 0 || ind.Nrhs == 0 {
+		return nil
+	}
+	uplo := linalg.ParamString(pars.Uplo)
+	info := -1
+	switch A := A.(type) {
+	case *matrix.FloatMatrix:
+		Aa := A.FloatArray()[ind.OffsetA:]
+		Ba := B.(*matrix.FloatMatrix).FloatArray()[ind.OffsetB:]
+		if name == "Pbsv" {
+			info = dpbsv(uplo, ind.N, kd, ind.Nrhs, Aa, ind.LDa, Ba, ind.LDb)
+		} else {
+			info = dpbtrs(uplo, ind.N, kd, ind.Nrhs, Aa, ind.LDa, Ba, ind.LDb)
+		}
+	case *matrix.ComplexMatrix:
+		Aa := A.ComplexArray()[ind.OffsetA:]
+		Ba := B.(*matrix.ComplexMatrix).ComplexArray()[ind.OffsetB:]
+		if name == "Pbsv" {
+			info = zpbsv(uplo, ind.N, kd, ind.Nrhs, Aa, ind.LDa, Ba, ind.LDb)
+		} else {
+			info = zpbtrs(uplo, ind.N, kd, ind.Nrhs, Aa, ind.LDa, Ba, ind.LDb)
+		}
+	default:
+		return onError(linalg.ErrType, name+": unknown types")
+	}
+	if info != 0 {
+		return onLapackError(name, info, linalg.ErrNotPositiveDefinite)
+	}
+	return nil
+}
+
+// Check band matrix A and optional right hand side B and return kd.
+func checkPb(name string, ind *linalg.IndexOpts, kd int, A, B matrix.Matrix) (int, error) {
+	if ind.N < 0 {
+		ind.N = A.Cols()
+	}
+	if kd < 0 {
+		kd = A.Rows() - 1
+	}
+	if kd < 0 {
+		return 0, onError(linalg.ErrParameter, name+": kd")
+	}
+	if B != nil && ind.Nrhs < 0 {
+		ind.Nrhs = B.Cols()
+	}
+	if ind.N == 0 || (B != nil && ind.Nrhs == 0) {
+		return kd, nil
+	}
+	arows := ind.LDa
+	if ind.LDa == 0 {
+		ind.LDa = max(1, A.LeadingIndex())
+		arows = max(1, A.Rows())
+	}
+	if ind.LDa < kd+1 {
+		return 0, onError(linalg.ErrParameter, name+": ldA")
+	}
+	if ind.OffsetA < 0 {
+		return 0, onError(linalg.ErrParameter, name+": offsetA")
+	}
+	if A.NumElements() < ind.OffsetA+(ind.N-1)*arows+kd+1 {
+		return 0, onError(linalg.ErrShape, name+": sizeA")
+	}
+	if B == nil {
+		return kd, nil
+	}
+	brows := ind.LDb
+	if ind.LDb == 0 {
+		ind.LDb = max(1, B.LeadingIndex())
+		brows = max(1, B.Rows())
+	}
+	if ind.LDb < max(1, ind.N) {
+		return 0, onError(linalg.ErrParameter, name+": ldB")
+	}
+	if ind.OffsetB < 0 {
+		return 0, onError(linalg.ErrParameter, name+": offsetB")
+	}
+	if B.NumElements() < ind.OffsetB+(ind.Nrhs-1)*brows+ind.N {
+		return 0, onError(linalg.ErrShape, name+": sizeB")
+	}
+	return kd, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
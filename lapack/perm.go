@@ -0,0 +1,50 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+)
+
+/*
+ LU factorization returning the row permutation as a Permutation.
+
+ On exit A is replaced with L, U in the factorization P*A = L*U and P is
+ returned. Arguments and options are as for Getrf.
+*/
+func GetrfPerm(A matrix.Matrix, opts ...linalg.Option) (P matops.Permutation, err error) {
+	m := linalg.GetIntOpt("m", -1, opts...)
+	n := linalg.GetIntOpt("n", -1, opts...)
+	if m < 0 {
+		m = A.Rows()
+	}
+	if n < 0 {
+		n = A.Cols()
+	}
+	ipiv := make([]int32, min(m, n))
+	if err = Getrf(A, ipiv, opts...); err != nil {
+		return nil, err
+	}
+	return matops.PivotPermutation(ipiv, m)
+}
+
+/*
+ Solves A*X = B (or transposed system) with LU factorization P*A = L*U
+ computed by GetrfPerm. Arguments and options are as for Getrs.
+*/
+func GetrsPerm(A, B matrix.Matrix, P matops.Permutation, opts ...linalg.Option) (err error) {
+	if err = P.Check(); err != nil {
+		return err
+	}
+	return Getrs(A, B, P.Pivots(), opts...)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,171 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"strings"
+)
+
+// Option that carries solution weights.
+type weightsOpt struct {
+	linalg.Option
+	w []float64
+}
+
+// Return option that gives the weights of PinvSolve solution "weighted".
+func WithWeights(w []float64) linalg.Option {
+	return &weightsOpt{linalg.StringOpt("weights", "set"), w}
+}
+
+// Return weights given in options or nil.
+func getWeights(opts ...linalg.Option) []float64 {
+	for _, o := range opts {
+		if w, ok := o.(*weightsOpt); ok {
+			return w.w
+		}
+	}
+	return nil
+}
+
+/*
+ Generalized inverse solution of a linear system.
+
+ PURPOSE
+
+ Solves min ||A*X - B|| for m by n matrix A of any shape and rank r, and
+ selects among the solutions of an underdetermined or rank deficient
+ problem. Uses QR factorization with column pivoting, A*P = Q*R, with
+ the rank decided by |R[k,k]| > rcond*|R[0,0]|. A and B are not changed.
+ Returns X and the rank.
+
+ The solution is selected with option solution:
+
+  "minnorm"   X = pinv(A)*B, the solution of minimum 2-norm.
+  "basic"     the basic solution with at most r nonzero rows, for the r
+              columns of A chosen by the pivoting; a sparse solution that
+              is not unique.
+  "weighted"  the solution minimizing sum_i w[i]*X[i,j]^2 for positive
+              weights w given with WithWeights.
+
+ ARGUMENTS
+  A         float matrix, m by n
+  B         float matrix, m by nrhs
+
+ OPTIONS
+  solution  string; "minnorm" (default), "basic" or "weighted"
+  rcond     float; relative rank tolerance.  Default eps*max(m,n).
+
+*/
+func PinvSolve(A, B *matrix.FloatMatrix, opts ...linalg.Option) (X *matrix.FloatMatrix, rank int, err error) {
+	defer guard("PinvSolve", &err)()
+	alloc := linalg.GetAllocator()
+	m, n, nrhs := A.Rows(), A.Cols(), B.Cols()
+	if B.Rows() != m {
+		return nil, 0, onError(linalg.ErrShape, "PinvSolve: rows of A and B differ")
+	}
+	var sw []float64
+	sel := strings.ToLower(linalg.GetStringOpt("solution", "minnorm", opts...))
+	switch sel {
+	case "minnorm", "basic":
+	case "weighted":
+		w := getWeights(opts...)
+		if len(w) != n {
+			return nil, 0, onError(linalg.ErrShape, "PinvSolve: number of weights not n")
+		}
+		sw = alloc.Float64s(n)
+		defer alloc.Free(sw)
+		for i, v := range w {
+			if !(v > 0.0) || math.IsInf(v, 1) {
+				return nil, 0, onError(linalg.ErrParameter, "PinvSolve: weights not positive")
+			}
+			sw[i] = math.Sqrt(v)
+		}
+	default:
+		return nil, 0, onError(linalg.ErrParameter, "PinvSolve: illegal solution")
+	}
+	X = matrix.FloatZeros(n, nrhs)
+	if m == 0 || n == 0 || nrhs == 0 {
+		return X, 0, nil
+	}
+	// weighted problem is min ||Y|| subject to A*diag(1/sqrt(w))*Y = B
+	QR := A.Copy()
+	if sw != nil {
+		scaleRowsCols(QR, nil, reciprocals(sw))
+	}
+	// allocator contents are undefined; zero jpvt to leave all columns free
+	jpvt := alloc.Int32s(n)
+	defer alloc.Free(jpvt)
+	for k := range jpvt {
+		jpvt[k] = 0
+	}
+	taubuf := alloc.Float64s(min(m, n))
+	defer alloc.Free(taubuf)
+	tau := matrix.FloatNew(min(m, n), 1, taubuf)
+	info := dgeqp3(m, n, QR.FloatArray(), max(1, QR.LeadingIndex()), jpvt, tau.FloatArray())
+	if info != 0 {
+		return nil, 0, onLapackError("PinvSolve", info, linalg.ErrParameter)
+	}
+	rcond := linalg.GetFloatOpt("rcond", 0x1p-52*float64(max(m, n)), opts...)
+	rmax := math.Abs(QR.GetAt(0, 0))
+	for rank < min(m, n) && math.Abs(QR.GetAt(rank, rank)) > rcond*rmax {
+		rank++
+	}
+	if rank == 0 {
+		return X, 0, nil
+	}
+	// basic solution of the permuted problem, R11*Y1 = (Q^T*B)[:rank]
+	C := B.Copy()
+	if err = Ormqr(QR, tau, C, linalg.OptLeft, linalg.OptTrans); err != nil {
+		return nil, 0, err
+	}
+	if err = Trtrs(QR, C, linalg.OptUpper, linalg.IntOpt("n", rank)); err != nil {
+		return nil, 0, err
+	}
+	Y := matrix.FloatZeros(n, nrhs)
+	Y.SetSubMatrix(0, 0, C.GetSubMatrix(0, 0, rank, nrhs))
+	if sel != "basic" && rank < n {
+		// project Y on the row space of A*P, the range of [R11 R12]^T
+		T := matrix.FloatZeros(n, rank)
+		for i := 0; i < rank; i++ {
+			for j := i; j < n; j++ {
+				T.SetAt(j, i, QR.GetAt(i, j))
+			}
+		}
+		tauT := matrix.FloatZeros(rank, 1)
+		if err = Geqrf(T, tauT); err != nil {
+			return nil, 0, err
+		}
+		if err = Ormqr(T, tauT, Y, linalg.OptLeft, linalg.OptTrans); err != nil {
+			return nil, 0, err
+		}
+		for j := 0; j < nrhs; j++ {
+			for i := rank; i < n; i++ {
+				Y.SetAt(i, j, 0.0)
+			}
+		}
+		if err = Ormqr(T, tauT, Y, linalg.OptLeft, linalg.OptNoTrans); err != nil {
+			return nil, 0, err
+		}
+	}
+	for i := 0; i < n; i++ {
+		p := int(jpvt[i]) - 1
+		for j := 0; j < nrhs; j++ {
+			X.SetAt(p, j, Y.GetAt(i, j))
+		}
+	}
+	if sw != nil {
+		scaleRowsCols(X, reciprocals(sw), nil)
+	}
+	return X, rank, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
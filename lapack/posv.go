@@ -8,7 +8,6 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
 )
@@ -38,9 +37,13 @@ import (
   offsetA   nonnegative integer
   offsetB   nonnegative integer
 */
-func Posv(A, B matrix.Matrix, opts ...linalg.Option) error {
+func Posv(A, B matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Posv", &err)()
+	if err = writable("Posv", A, B); err != nil {
+		return
+	}
 	if !matrix.EqualTypes(A, B) {
-		return onError("Posv: arguments not same type")
+		return onError(linalg.ErrType, "Posv: arguments not same type")
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
@@ -52,10 +55,11 @@ func Posv(A, B matrix.Matrix, opts ...linalg.Option) error {
 		Bm := B.(*matrix.ComplexMatrix)
 		return PosvComplex(Am, Bm, opts...)
 	}
-	return onError("Posv: unknown types")
+	return onError(linalg.ErrType, "Posv: unknown types")
 }
 
-func PosvFloat(A, B *matrix.FloatMatrix, opts ...linalg.Option) error {
+func PosvFloat(A, B *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	defer guard("PosvFloat", &err)()
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
 		return err
@@ -73,13 +77,14 @@ func PosvFloat(A, B *matrix.FloatMatrix, opts ...linalg.Option) error {
 	uplo := linalg.ParamString(pars.Uplo)
 	info := dposv(uplo, ind.N, ind.Nrhs, Aa[ind.OffsetA:], ind.LDa, Ba[ind.OffsetB:], ind.LDb)
 	if info != 0 {
-		return onError(fmt.Sprintf("Posv: lapack error %d", info))
+		return onLapackError("Posv", info, linalg.ErrNotPositiveDefinite)
 	}
 	return nil
 }
 
-func PosvComplex(A, B *matrix.ComplexMatrix, opts ...linalg.Option) error {
-	return onError(fmt.Sprintf("Posv: complex not yet implemented"))
+func PosvComplex(A, B *matrix.ComplexMatrix, opts ...linalg.Option) (err error) {
+	defer guard("PosvComplex", &err)()
+	return onError(linalg.ErrNotImplemented, "Posv: complex not yet implemented")
 }
 
 func checkPosv(ind *linalg.IndexOpts, A, B matrix.Matrix) error {
@@ -99,28 +104,28 @@ func checkPosv(ind *linalg.IndexOpts, A, B matrix.Matrix) error {
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, ind.N) {
-		return onError("Posv: lda")
+		return onError(linalg.ErrParameter, "Posv: lda")
 	}
 	if ind.LDb == 0 {
 		ind.LDb = max(1, B.LeadingIndex())
 		brows = max(1, B.Rows())
 	}
 	if ind.LDb < max(1, ind.N) {
-		return onError("Posv: ldb")
+		return onError(linalg.ErrParameter, "Posv: ldb")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Posv: offsetA")
+		return onError(linalg.ErrParameter, "Posv: offsetA")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.N {
-		return onError("Posv: sizeA")
+		return onError(linalg.ErrShape, "Posv: sizeA")
 	}
 	if ind.OffsetB < 0 {
-		return onError("Posv: offsetB")
+		return onError(linalg.ErrParameter, "Posv: offsetB")
 	}
 	sizeB := B.NumElements()
 	if sizeB < ind.OffsetB+(ind.Nrhs-1)*brows+ind.N {
-		return onError("Posv: sizeB")
+		return onError(linalg.ErrShape, "Posv: sizeB")
 	}
 	return nil
 }
@@ -8,9 +8,9 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
+	"math"
 )
 
 /*
@@ -36,32 +36,59 @@ import (
             value is used.
   offsetA   nonnegative integer
 
+ Option WithDiagnostics(d) fills d with the growth and pivot sizes of a
+ successful real factorization.
+
 */
-func Potrf(A matrix.Matrix, opts ...linalg.Option) error {
+func Potrf(A matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Potrf", &err)()
+	if err = writable("Potrf", A); err != nil {
+		return
+	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
 		return PotrfFloat(A.(*matrix.FloatMatrix), opts...)
 	case *matrix.ComplexMatrix:
-		return onError("Potrf: complex not implemented yet")
+		return onError(linalg.ErrNotImplemented, "Potrf: complex not implemented yet")
 	}
-	return onError("Potrf unknown types")
+	return onError(linalg.ErrType, "Potrf unknown types")
 }
 
-func PotrfFloat(A *matrix.FloatMatrix, opts ...linalg.Option) error {
+func PotrfFloat(A *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("lapack.Potrf")
+	defer op.Finish(&err)
+	defer guard("PotrfFloat", &err)()
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
 		return err
 	}
 	ind := linalg.GetIndexOpts(opts...)
-	err = checkPotrf(ind, A)
+	if err = checkPotrf(ind, A); err != nil {
+		return err
+	}
+	op.SetDims(ind.N)
 	if ind.N == 0 {
 		return nil
 	}
 	Aa := A.FloatArray()
 	uplo := linalg.ParamString(pars.Uplo)
+	diag := getDiagnostics(opts...)
+	if diag == nil && op.Enabled() {
+		diag = &PivotDiagnostics{}
+	}
+	amax := 0.0
+	if diag != nil {
+		for _, v := range columnMax(Aa[ind.OffsetA:], ind.N, ind.N, ind.LDa) {
+			amax = math.Max(amax, v)
+		}
+	}
 	info := dpotrf(uplo, ind.N, Aa[ind.OffsetA:], ind.LDa)
 	if info != 0 {
-		return onError(fmt.Sprintf("Potrf: lapack error %d", info))
+		return onLapackError("Potrf", info, linalg.ErrNotPositiveDefinite)
+	}
+	if diag != nil {
+		cholDiagnostics(diag, Aa[ind.OffsetA:], ind.N, ind.LDa, uplo, amax)
+		warnPivots(op, diag)
 	}
 	return nil
 }
@@ -71,7 +98,7 @@ func checkPotrf(ind *linalg.IndexOpts, A matrix.Matrix) error {
 	if ind.N < 0 {
 		ind.N = A.Rows()
 		if ind.N != A.Cols() {
-			return onError("Potrf: not square")
+			return onError(linalg.ErrShape, "Potrf: not square")
 		}
 	}
 	if ind.N == 0 {
@@ -82,13 +109,13 @@ func checkPotrf(ind *linalg.IndexOpts, A matrix.Matrix) error {
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, ind.N) {
-		return onError("Potrf: lda")
+		return onError(linalg.ErrParameter, "Potrf: lda")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Potrf: offsetA")
+		return onError(linalg.ErrParameter, "Potrf: offsetA")
 	}
 	if A.NumElements() < ind.OffsetA+(ind.N-1)*arows+ind.N {
-		return onError("Potrf: sizeA")
+		return onError(linalg.ErrShape, "Potrf: sizeA")
 	}
 	return nil
 }
@@ -8,7 +8,6 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
 )
@@ -34,17 +33,22 @@ import (
             value is used.
   offsetA   nonnegative integer;
 */
-func Potri(A matrix.Matrix, opts ...linalg.Option) error {
+func Potri(A matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Potri", &err)()
+	if err = writable("Potri", A); err != nil {
+		return
+	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
 		return PotriFloat(A.(*matrix.FloatMatrix), opts...)
 	case *matrix.ComplexMatrix:
-		return onError("Potri: complex not implemented yet")
+		return onError(linalg.ErrNotImplemented, "Potri: complex not implemented yet")
 	}
-	return onError("Potri: unknown types")
+	return onError(linalg.ErrType, "Potri: unknown types")
 }
 
-func PotriFloat(A *matrix.FloatMatrix, opts ...linalg.Option) error {
+func PotriFloat(A *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	defer guard("PotriFloat", &err)()
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
 		return err
@@ -61,7 +65,7 @@ func PotriFloat(A *matrix.FloatMatrix, opts ...linalg.Option) error {
 	uplo := linalg.ParamString(pars.Uplo)
 	info := dpotri(uplo, ind.N, Aa[ind.OffsetA:], ind.LDa)
 	if info != 0 {
-		return onError(fmt.Sprintf("Potri lapack error %d", info))
+		return onLapackError("Potri", info, linalg.ErrSingular)
 	}
 	return nil
 }
@@ -79,13 +83,13 @@ func checkPotri(ind *linalg.IndexOpts, A matrix.Matrix) error {
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, ind.N) {
-		return onError("Potri: lda")
+		return onError(linalg.ErrParameter, "Potri: lda")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Potri: offsetA")
+		return onError(linalg.ErrParameter, "Potri: offsetA")
 	}
 	if A.NumElements() < ind.OffsetA+(ind.N-1)*arows+ind.N {
-		return onError("Potri: sizeA")
+		return onError(linalg.ErrShape, "Potri: sizeA")
 	}
 	return nil
 }
@@ -8,8 +8,8 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
 	"github.com/nvcook42/matrix"
 )
 
@@ -43,7 +43,12 @@ import (
   offsetB   nonnegative integer;
 
 */
-func Potrs(A, B matrix.Matrix, opts ...linalg.Option) error {
+func Potrs(A, B matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Potrs", &err)()
+	if err = writable("Potrs", B); err != nil {
+		return
+	}
+	A = matops.Readable(A)
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
 		return err
@@ -65,29 +70,29 @@ func Potrs(A, B matrix.Matrix, opts ...linalg.Option) error {
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, ind.N) {
-		return onError("Potrs: ldA")
+		return onError(linalg.ErrParameter, "Potrs: ldA")
 	}
 	if ind.LDb == 0 {
 		ind.LDb = max(1, B.LeadingIndex())
 		brows = max(1, B.Rows())
 	}
 	if ind.LDb < max(1, ind.N) {
-		return onError("Potrs: ldB")
+		return onError(linalg.ErrParameter, "Potrs: ldB")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Potrs: offsetA")
+		return onError(linalg.ErrParameter, "Potrs: offsetA")
 	}
 	if A.NumElements() < ind.OffsetA+(ind.N-1)*arows+ind.N {
-		return onError("Potrs: sizeA")
+		return onError(linalg.ErrShape, "Potrs: sizeA")
 	}
 	if ind.OffsetB < 0 {
-		return onError("Potrs: offsetB")
+		return onError(linalg.ErrParameter, "Potrs: offsetB")
 	}
 	if B.NumElements() < ind.OffsetB+(ind.Nrhs-1)*brows+ind.N {
-		return onError("Potrs: sizeB")
+		return onError(linalg.ErrShape, "Potrs: sizeB")
 	}
 	if !matrix.EqualTypes(A, B) {
-		return onError("Potrs: arguments not of same types")
+		return onError(linalg.ErrType, "Potrs: arguments not of same types")
 	}
 	info := -1
 	switch A.(type) {
@@ -98,10 +103,10 @@ func Potrs(A, B matrix.Matrix, opts ...linalg.Option) error {
 		info = dpotrs(uplo, ind.N, ind.Nrhs, Aa[ind.OffsetA:], ind.LDa,
 			Ba[ind.OffsetB:], ind.LDb)
 	case *matrix.ComplexMatrix:
-		return onError("Potrs: complex not implemented yet")
+		return onError(linalg.ErrNotImplemented, "Potrs: complex not implemented yet")
 	}
 	if info != 0 {
-		return onError(fmt.Sprintf("Potrs: lapack error %d", info))
+		return onLapackError("Potrs", info, linalg.ErrParameter)
 	}
 	return nil
 }
@@ -0,0 +1,113 @@
+// Copyright (c) Harri Rautila, 2012,2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"math/cmplx"
+)
+
+/*
+ Solves the quadratic eigenvalue problem.
+
+ PURPOSE
+ Computes all 2n eigenvalues lambda and, optionally, eigenvectors x of
+
+  (lambda^2*M + lambda*C + K)*x = 0
+
+ for n by n real M, C and K, eg. mass, damping and stiffness matrices.
+ The problem is solved as the generalized eigenvalue problem of the
+ first companion linearization
+
+  [  0  I ] [   x      ]          [ I  0 ] [   x      ]
+  [ -K -C ] [ lambda*x ] = lambda [ 0  M ] [ lambda*x ]
+
+ with Ggev.  Singular M gives infinite eigenvalues, returned as
+ cmplx.Inf().  Arguments M, C and K are not modified.
+
+ ARGUMENTS
+  M, C, K   float matrices, n by n
+  lambda    complex matrix of length at least 2n.  On exit, the eigenvalues.
+  X         complex matrix with at least n rows and 2n columns, or nil.
+            On exit, column k holds the eigenvector of lambda[k]
+            normalized to unit 2-norm.
+
+*/
+func Qep(M, C, K *matrix.FloatMatrix, lambda, X *matrix.ComplexMatrix) (err error) {
+	defer guard("Qep", &err)()
+	n := M.Rows()
+	for _, Z := range []*matrix.FloatMatrix{M, C, K} {
+		if Z.Rows() != n || Z.Cols() != n {
+			return onError(linalg.ErrShape, "Qep: M, C and K not square of same size")
+		}
+	}
+	if lambda.NumElements() < 2*n {
+		return onError(linalg.ErrShape, "Qep: size lambda")
+	}
+	if X != nil && (X.Rows() < n || X.Cols() < 2*n) {
+		return onError(linalg.ErrShape, "Qep: size X")
+	}
+	if n == 0 {
+		return nil
+	}
+	A := matrix.FloatZeros(2*n, 2*n)
+	B := matrix.FloatZeros(2*n, 2*n)
+	for i := 0; i < n; i++ {
+		A.SetAt(i, n+i, 1.0)
+		B.SetAt(i, i, 1.0)
+		for j := 0; j < n; j++ {
+			A.SetAt(n+i, j, -K.GetAt(i, j))
+			A.SetAt(n+i, n+j, -C.GetAt(i, j))
+			B.SetAt(n+i, n+j, M.GetAt(i, j))
+		}
+	}
+	alpha := matrix.ComplexZeros(2*n, 1)
+	beta := matrix.FloatZeros(2*n, 1)
+	var V *matrix.ComplexMatrix
+	if X != nil {
+		V = matrix.ComplexZeros(2*n, 2*n)
+	}
+	if err = Ggev(A, B, alpha, beta, V); err != nil {
+		return err
+	}
+	la := lambda.ComplexArray()
+	for k := 0; k < 2*n; k++ {
+		if b := beta.GetIndex(k); b != 0.0 {
+			la[k] = alpha.GetAt(k, 0) / complex(b, 0)
+		} else {
+			la[k] = cmplx.Inf()
+		}
+	}
+	if X == nil {
+		return nil
+	}
+	for k := 0; k < 2*n; k++ {
+		// x is the leading block of z = [x; lambda*x], or the trailing
+		// block for infinite eigenvalues where the leading block vanishes
+		off := 0
+		if beta.GetIndex(k) == 0.0 {
+			off = n
+		}
+		nrm := 0.0
+		for i := 0; i < n; i++ {
+			nrm = math.Hypot(nrm, cmplx.Abs(V.GetAt(off+i, k)))
+		}
+		if nrm == 0.0 {
+			nrm = 1.0
+		}
+		for i := 0; i < n; i++ {
+			X.SetAt(i, k, V.GetAt(off+i, k)/complex(nrm, 0))
+		}
+	}
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
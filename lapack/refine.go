@@ -0,0 +1,127 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/bigfloat"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+/*
+ Solution of a set of linear equations to a requested accuracy by
+ multiple-precision iterative refinement.
+
+ PURPOSE
+ Solves A*X = B where A is n by n and B is n by nrhs, given as
+ multiple-precision matrices, until the solution has the requested number
+ of correct significant decimal digits.  A is rounded to double precision
+ and factored once with Getrf.  Each step computes the residual
+ R = B - A*X in multiple precision, solves A*D = R with the double
+ precision factors and updates X := X + D in multiple precision.
+
+ The iteration converges linearly at a rate of about eps*cond(A), so A
+ must be well enough conditioned for double precision LU to give at
+ least one correct digit.  Returns the solution and the estimated
+ normwise relative error bound max|X-X*|/max|X|, taken as
+ ||D||*rho/(1-rho)/||X|| where rho is the observed ratio of successive
+ corrections.  If the bound does not reach 10^-digits within maxiter
+ steps, or the corrections stop decreasing, the current solution and bound
+ are returned with an error wrapping linalg.ErrNoConvergence.
+
+ ARGUMENTS
+  A         multiple-precision matrix, n by n
+  B         multiple-precision matrix, n by nrhs
+  digits    number of correct decimal digits requested
+
+ OPTIONS
+  prec      int; precision of X and the residual in bits.  Default
+            digits*log2(10) plus 64 bits, at least the precision of A and B.
+  maxiter   int; maximum number of refinement steps.  Default 50.
+
+*/
+func RefineBig(A, B *bigfloat.Matrix, digits int, opts ...linalg.Option) (X *bigfloat.Matrix, bound float64, err error) {
+	n, nrhs := A.Rows(), B.Cols()
+	if A.Cols() != n || B.Rows() != n {
+		return nil, 0.0, onError(linalg.ErrShape, "RefineBig: A not square or rows of B differ")
+	}
+	if digits < 1 {
+		return nil, 0.0, onError(linalg.ErrParameter, "RefineBig: digits must be positive")
+	}
+	prec := uint(math.Ceil(float64(digits)*math.Log2(10))) + 64
+	if A.Prec() > prec {
+		prec = A.Prec()
+	}
+	if B.Prec() > prec {
+		prec = B.Prec()
+	}
+	prec = uint(linalg.GetIntOpt("prec", int(prec), opts...))
+	maxiter := linalg.GetIntOpt("maxiter", 50, opts...)
+	X = bigfloat.Zeros(n, nrhs, prec)
+	if n == 0 || nrhs == 0 {
+		return X, 0.0, nil
+	}
+	LU := A.Float()
+	alloc := linalg.GetAllocator()
+	ipiv := alloc.Int32s(n)
+	defer alloc.Free(ipiv)
+	if err = Getrf(LU, ipiv); err != nil {
+		return nil, 0.0, err
+	}
+	tol := math.Pow(10.0, -float64(digits))
+	bound = math.Inf(1)
+	dprev := math.Inf(1)
+	for iter := 0; iter < maxiter; iter++ {
+		R, _ := bigfloat.Residual(A, X, B, prec)
+		D := R.Float()
+		if err = Getrs(LU, D, ipiv); err != nil {
+			return X, bound, err
+		}
+		if X, err = bigfloat.Plus(X, bigfloat.FromFloat(D, prec)); err != nil {
+			return nil, bound, err
+		}
+		dnorm := maxAbs(D)
+		xnorm := maxAbs(X.Float())
+		if dnorm == 0.0 {
+			return X, 0.0, nil
+		}
+		if xnorm == 0.0 || math.IsInf(dprev, 1) {
+			// the first correction is the whole solution; no rate yet
+			dprev = dnorm
+			continue
+		}
+		rho := dnorm / dprev
+		if rho < 1.0 {
+			bound = dnorm / xnorm * rho / (1.0 - rho)
+		}
+		if bound <= tol {
+			return X, bound, nil
+		}
+		if rho > 0.5 && iter > 2 {
+			return X, bound, onError(linalg.ErrNoConvergence,
+				fmt.Sprintf("RefineBig: stagnated at bound %.3g, A too ill-conditioned", bound))
+		}
+		dprev = dnorm
+	}
+	return X, bound, onError(linalg.ErrNoConvergence,
+		fmt.Sprintf("RefineBig: bound %.3g after %d steps", bound, maxiter))
+}
+
+// Return largest absolute value of elements of A.
+func maxAbs(A *matrix.FloatMatrix) float64 {
+	m := 0.0
+	for _, v := range A.FloatArray() {
+		m = math.Max(m, math.Abs(v))
+	}
+	return m
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,304 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"strings"
+)
+
+// Routines for the rectangular full packed (RFP) format. A triangle of an
+// n by n matrix is stored in n*(n+1)/2 elements, as in packed storage, but
+// arranged as a full rectangular matrix so that factorizations run with
+// level 3 BLAS at the speed of full storage. The layout is determined by
+// uplo, the stored triangle, and transr, "N" for normal or "T" (or "C" for
+// complex) for the transposed RFP format; see the LAPACK documentation of
+// dtrttf for details. Only the routines here need to know the layout.
+
+/*
+ Copy triangular matrix from full format to RFP format.
+
+ PURPOSE
+
+ Copies the upper or lower triangle of n by n matrix A to ARF in RFP
+ format.
+
+ ARGUMENTS
+  A         float or complex matrix
+  ARF       float or complex matrix of at least n*(n+1)/2 elements.
+            Must have the same type as A.
+
+ OPTIONS
+  uplo      PLower or PUpper
+  transr    string; "N", or "T" ("C" for complex). Default "N".
+  n         nonnegative integer.  If negative, the default value is used.
+  ldA       positive integer.  ldA >= max(1,n).  If zero, the default value is used.
+
+*/
+func Trttf(A, ARF matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Trttf", &err)()
+	if err = writable("Trttf", ARF); err != nil {
+		return
+	}
+	uplo, transr, ind, err := rfpOpts("Trttf", A, ARF, opts...)
+	if err != nil {
+		return err
+	}
+	if ind.N < 0 {
+		ind.N = A.Rows()
+	}
+	if err = checkRfpFull("Trttf", ind, A, ARF); err != nil || ind.N == 0 {
+		return err
+	}
+	info := -1
+	switch A := A.(type) {
+	case *matrix.FloatMatrix:
+		info = dtrttf(transr, uplo, ind.N, A.FloatArray(), ind.LDa,
+			ARF.(*matrix.FloatMatrix).FloatArray())
+	case *matrix.ComplexMatrix:
+		info = ztrttf(transr, uplo, ind.N, A.ComplexArray(), ind.LDa,
+			ARF.(*matrix.ComplexMatrix).ComplexArray())
+	}
+	if info != 0 {
+		return onLapackError("Trttf", info, linalg.ErrParameter)
+	}
+	return nil
+}
+
+/*
+ Copy triangular matrix from RFP format to full format.
+
+ PURPOSE
+
+ Copies n by n triangular matrix in RFP format in ARF to the upper or
+ lower triangle of A. The other triangle of A is not referenced.
+
+ ARGUMENTS
+  ARF       float or complex matrix of at least n*(n+1)/2 elements.
+  A         float or complex matrix.  Must have the same type as ARF.
+
+ OPTIONS
+  uplo      PLower or PUpper
+  transr    string; "N", or "T" ("C" for complex). Default "N".
+  n         nonnegative integer.  If negative, the default value is used.
+  ldA       positive integer.  ldA >= max(1,n).  If zero, the default value is used.
+
+*/
+func Tfttr(ARF, A matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Tfttr", &err)()
+	if err = writable("Tfttr", A); err != nil {
+		return
+	}
+	uplo, transr, ind, err := rfpOpts("Tfttr", A, ARF, opts...)
+	if err != nil {
+		return err
+	}
+	if ind.N < 0 {
+		ind.N = A.Rows()
+	}
+	if err = checkRfpFull("Tfttr", ind, A, ARF); err != nil || ind.N == 0 {
+		return err
+	}
+	info := -1
+	switch A := A.(type) {
+	case *matrix.FloatMatrix:
+		info = dtfttr(transr, uplo, ind.N, ARF.(*matrix.FloatMatrix).FloatArray(),
+			A.FloatArray(), ind.LDa)
+	case *matrix.ComplexMatrix:
+		info = ztfttr(transr, uplo, ind.N, ARF.(*matrix.ComplexMatrix).ComplexArray(),
+			A.ComplexArray(), ind.LDa)
+	}
+	if info != 0 {
+		return onLapackError("Tfttr", info, linalg.ErrParameter)
+	}
+	return nil
+}
+
+/*
+ Cholesky factorization of a real symmetric or complex Hermitian positive
+ definite matrix in RFP format.
+
+ PURPOSE
+
+ Computes A = L*L^H or A = U^H*U with A n by n positive definite matrix
+ stored in RFP format in ARF. On exit ARF holds the factor in the same
+ format.
+
+ ARGUMENTS
+  ARF       float or complex matrix of n*(n+1)/2 elements
+
+ OPTIONS
+  uplo      PLower or PUpper
+  transr    string; "N", or "T" ("C" for complex). Default "N".
+  n         nonnegative integer.  If negative, the default value is used.
+            The default value is computed from the size of ARF.
+
+*/
+func Pftrf(ARF matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Pftrf", &err)()
+	if err = writable("Pftrf", ARF); err != nil {
+		return
+	}
+	uplo, transr, ind, err := rfpOpts("Pftrf", ARF, ARF, opts...)
+	if err != nil {
+		return err
+	}
+	if ind.N, err = rfpOrder("Pftrf", ind.N, ARF); err != nil || ind.N == 0 {
+		return err
+	}
+	info := -1
+	switch ARF := ARF.(type) {
+	case *matrix.FloatMatrix:
+		info = dpftrf(transr, uplo, ind.N, ARF.FloatArray())
+	case *matrix.ComplexMatrix:
+		info = zpftrf(transr, uplo, ind.N, ARF.ComplexArray())
+	}
+	if info != 0 {
+		return onLapackError("Pftrf", info, linalg.ErrNotPositiveDefinite)
+	}
+	return nil
+}
+
+/*
+ Solves a real symmetric or complex Hermitian positive definite set of
+ linear equations, given the Cholesky factorization in RFP format
+ computed by Pftrf.
+
+ PURPOSE
+
+ Solves A*X = B with A n by n positive definite. On exit B is replaced
+ with the solution X.
+
+ ARGUMENTS
+  ARF       float or complex matrix of n*(n+1)/2 elements
+  B         float or complex matrix.  Must have the same type as ARF.
+
+ OPTIONS
+  uplo      PLower or PUpper
+  transr    string; "N", or "T" ("C" for complex). Default "N".
+  n         nonnegative integer.  If negative, the default value is used.
+            The default value is computed from the size of ARF.
+  nrhs      nonnegative integer.  If negative, the default value is used.
+  ldB       positive integer.  ldB >= max(1,n).  If zero, the default value is used.
+
+*/
+func Pftrs(ARF, B matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Pftrs", &err)()
+	if err = writable("Pftrs", B); err != nil {
+		return
+	}
+	uplo, transr, ind, err := rfpOpts("Pftrs", B, ARF, opts...)
+	if err != nil {
+		return err
+	}
+	if ind.N, err = rfpOrder("Pftrs", ind.N, ARF); err != nil {
+		return err
+	}
+	if ind.Nrhs < 0 {
+		ind.Nrhs = B.Cols()
+	}
+	if ind.N == 0 || ind.Nrhs == 0 {
+		return nil
+	}
+	brows := ind.LDb
+	if ind.LDb == 0 {
+		ind.LDb = max(1, B.LeadingIndex())
+		brows = max(1, B.Rows())
+	}
+	if ind.LDb < max(1, ind.N) {
+		return onError(linalg.ErrParameter, "Pftrs: ldB")
+	}
+	if B.NumElements() < (ind.Nrhs-1)*brows+ind.N {
+		return onError(linalg.ErrShape, "Pftrs: sizeB")
+	}
+	info := -1
+	switch ARF := ARF.(type) {
+	case *matrix.FloatMatrix:
+		info = dpftrs(transr, uplo, ind.N, ind.Nrhs, ARF.FloatArray(),
+			B.(*matrix.FloatMatrix).FloatArray(), ind.LDb)
+	case *matrix.ComplexMatrix:
+		info = zpftrs(transr, uplo, ind.N, ind.Nrhs, ARF.ComplexArray(),
+			B.(*matrix.ComplexMatrix).ComplexArray(), ind.LDb)
+	}
+	if info != 0 {
+		return onLapackError("Pftrs", info, linalg.ErrNotPositiveDefinite)
+	}
+	return nil
+}
+
+// Return uplo and transr parameters and indexes for RFP routine name with
+// full or right hand side matrix A and RFP matrix ARF.
+func rfpOpts(name string, A, ARF matrix.Matrix, opts ...linalg.Option) (string, string, *linalg.IndexOpts, error) {
+	if !matrix.EqualTypes(A, ARF) {
+		return "", "", nil, onError(linalg.ErrType, name+": not same type")
+	}
+	if _, ok := ARF.(*matrix.FloatMatrix); !ok {
+		if _, ok = ARF.(*matrix.ComplexMatrix); !ok {
+			return "", "", nil, onError(linalg.ErrType, name+": unknown types")
+		}
+	}
+	pars, err := linalg.GetParameters(opts...)
+	if err != nil {
+		return "", "", nil, err
+	}
+	transr := strings.ToUpper(linalg.GetStringOpt("transr", "N", opts...))
+	switch transr {
+	case "N":
+	case "T", "C":
+		// LAPACK wants T for real and C for complex matrices
+		transr = "T"
+		if _, ok := ARF.(*matrix.ComplexMatrix); ok {
+			transr = "C"
+		}
+	default:
+		return "", "", nil, onError(linalg.ErrParameter, name+": transr")
+	}
+	return linalg.ParamString(pars.Uplo), transr, linalg.GetIndexOpts(opts...), nil
+}
+
+// Check full matrix A and RFP matrix ARF of order ind.N.
+func checkRfpFull(name string, ind *linalg.IndexOpts, A, ARF matrix.Matrix) error {
+	if ind.N == 0 {
+		return nil
+	}
+	arows := ind.LDa
+	if ind.LDa == 0 {
+		ind.LDa = max(1, A.LeadingIndex())
+		arows = max(1, A.Rows())
+	}
+	if ind.LDa < max(1, ind.N) {
+		return onError(linalg.ErrParameter, name+": ldA")
+	}
+	if A.NumElements() < (ind.N-1)*arows+ind.N {
+		return onError(linalg.ErrShape, name+": sizeA")
+	}
+	if ARF.NumElements() < ind.N*(ind.N+1)/2 {
+		return onError(linalg.ErrShape, name+": sizeARF")
+	}
+	return nil
+}
+
+// Return order n of RFP matrix ARF, given or computed from its size.
+func rfpOrder(name string, n int, ARF matrix.Matrix) (int, error) {
+	size := ARF.NumElements()
+	if n < 0 {
+		n = int((math.Sqrt(8*float64(size)+1) - 1) / 2)
+		if n*(n+1)/2 != size {
+			return 0, onError(linalg.ErrShape, name+": size of ARF not n*(n+1)/2")
+		}
+	}
+	if size < n*(n+1)/2 {
+		return 0, onError(linalg.ErrShape, name+": sizeARF")
+	}
+	return n, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,96 @@
+// Copyright (c) Harri Rautila, 2012,2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"errors"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+/*
+ Cholesky factorization with automatic diagonal regularization.
+
+ PURPOSE
+ Factors A + jitter*I = L*L^T where A is n by n real symmetric and
+ positive semidefinite or nearly so, eg. a kernel or covariance matrix
+ that is indefinite in floating point.  The factorization is first tried
+ with jitter zero; if Potrf reports that the matrix is not positive
+ definite, it is retried with jitter starting from the 'jitter' option and
+ growing ten-fold per try.  Returns the jitter used.
+
+ On exit A holds the factor as with Potrf.  If no try succeeds A is not
+ modified and ErrNotPositiveDefinite is returned along with the largest
+ jitter tried.
+
+ ARGUMENTS
+  A         float matrix
+
+ OPTIONS
+  uplo      PLower or PUpper
+  jitter    float; first non-zero jitter.  Default 1e-10 times the mean
+            of the diagonal of A.
+  maxtries  int; maximum number of non-zero jitters tried.  Default 10.
+
+*/
+func SafeCholesky(A *matrix.FloatMatrix, opts ...linalg.Option) (jitter float64, err error) {
+	defer guard("SafeCholesky", &err)()
+	n := A.Rows()
+	if A.Cols() != n {
+		return 0.0, onError(linalg.ErrShape, "SafeCholesky: A not square")
+	}
+	pars, err := linalg.GetParameters(opts...)
+	if err != nil {
+		return 0.0, err
+	}
+	uplo := linalg.IntOpt("uplo", pars.Uplo)
+	mean := 0.0
+	for k := 0; k < n; k++ {
+		mean += math.Abs(A.GetAt(k, k))
+	}
+	if n > 0 {
+		mean /= float64(n)
+	}
+	if mean == 0.0 {
+		mean = 1.0
+	}
+	first := linalg.GetFloatOpt("jitter", 1e-10*mean, opts...)
+	maxtries := linalg.GetIntOpt("maxtries", 10, opts...)
+	if !(first > 0.0) || maxtries < 0 {
+		return 0.0, onError(linalg.ErrParameter, "SafeCholesky: jitter or maxtries")
+	}
+	L := A.Copy()
+	for try := 0; try <= maxtries; try++ {
+		if try > 0 {
+			jitter = first * math.Pow(10.0, float64(try-1))
+			L = A.Copy()
+			for k := 0; k < n; k++ {
+				L.SetAt(k, k, L.GetAt(k, k)+jitter)
+			}
+		}
+		err = PotrfFloat(L, uplo)
+		if err == nil {
+			for j := 0; j < n; j++ {
+				for i := 0; i < n; i++ {
+					A.SetAt(i, j, L.GetAt(i, j))
+				}
+			}
+			return jitter, nil
+		}
+		if !errors.Is(err, linalg.ErrNotPositiveDefinite) {
+			return jitter, err
+		}
+	}
+	return jitter, onError(linalg.ErrNotPositiveDefinite,
+		fmt.Sprintf("SafeCholesky: not positive definite with jitter %g", jitter))
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
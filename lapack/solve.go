@@ -0,0 +1,145 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+)
+
+// Problem solved by Solve, decided by the shape of A.
+type Formulation int
+
+const (
+	// Square system A*X = B.
+	SquareSystem Formulation = iota
+	// Overdetermined system, min ||A*X - B||.
+	LeastSquares
+	// Underdetermined system, min ||X|| subject to A*X = B.
+	MinimumNorm
+)
+
+func (f Formulation) String() string {
+	switch f {
+	case SquareSystem:
+		return "square"
+	case LeastSquares:
+		return "least squares"
+	case MinimumNorm:
+		return "minimum norm"
+	}
+	return fmt.Sprintf("Formulation(%d)", int(f))
+}
+
+// Result of Solve.
+//
+// Formulation tells which problem was solved. Rank is the rank of A, n for
+// a nonsingular square or full rank overdetermined A. Residual[j] is the
+// 2-norm of B(:,j) - A*X(:,j), nil for square systems. Equilibration
+// reports the scaling of A, nil for underdetermined systems.
+// Verification holds the relative residuals of X if verification is
+// enabled, see Verification, and is nil otherwise. CrossCheck holds the
+// comparison of a square system solution with matops.Solve if
+// cross-checking is enabled, see CrossCheck, and is nil otherwise.
+type SolveResult struct {
+	X             *matrix.FloatMatrix
+	Formulation   Formulation
+	Rank          int
+	Residual      []float64
+	Equilibration *Equilibration
+	Verification  *Verification
+	CrossCheck    *CrossCheck
+}
+
+/*
+ Solves a general real set of linear equations, in the least squares or
+ minimum norm sense if A is not square.
+
+ PURPOSE
+
+ For m by n matrix A and m by nrhs matrix B computes
+
+  X = A^{-1}*B                        if m = n, with Getrf and Getrs
+  X minimizing ||A*X - B||            if m > n, with Lstsq
+  X minimizing ||X|| with A*X = B     if m < n, with PinvSolve
+
+ A and B are not changed. Square systems with badly scaled rows or
+ columns are first equilibrated to diag(R)*A*diag(C)*Y = diag(R)*B and
+ X = diag(C)*Y; estimating Equilibration.RCondBefore then costs an
+ additional factorization of A. Lstsq requires A of full column rank;
+ the minimum norm solution allows rank deficient A.
+
+ ARGUMENTS
+  A         float matrix, m by n
+  B         float matrix, m by nrhs
+
+ OPTIONS
+  equilibrate  bool; scale badly scaled systems.  Default true.
+  nonsquare    bool; solve non-square systems.  If false, a non-square A
+               is an error wrapping linalg.ErrShape.  Default true.
+  verify       float; verify X with this residual threshold, see
+               Verification.  Default VerifyThreshold().
+  crosscheck   float; compare X of a square system with matops.Solve
+               with this tolerance, see CrossCheck.  Default
+               CrossCheckTolerance().
+
+*/
+func Solve(A, B *matrix.FloatMatrix, opts ...linalg.Option) (res *SolveResult, err error) {
+	m, n, nrhs := A.Rows(), A.Cols(), B.Cols()
+	op := linalg.StartOp("lapack.Solve", m, n, nrhs)
+	defer op.Finish(&err)
+	if B.Rows() != m {
+		return nil, onError(linalg.ErrShape,
+			fmt.Sprintf("Solve: A is %d by %d but B has %d rows", m, n, B.Rows()))
+	}
+	if m != n && !linalg.GetBoolOpt("nonsquare", true, opts...) {
+		return nil, onError(linalg.ErrShape,
+			fmt.Sprintf("Solve: A is %d by %d, not square, and option nonsquare is false", m, n))
+	}
+	switch {
+	case m > n:
+		ls, err := Lstsq(A, B, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &SolveResult{ls.X, LeastSquares, n, ls.Residual, &ls.Equilibration, ls.Verification, nil}, nil
+	case m < n:
+		X, rank, err := PinvSolve(A, B)
+		if err != nil {
+			return nil, err
+		}
+		res = &SolveResult{X, MinimumNorm, rank, make([]float64, nrhs), nil, nil, nil}
+		if nrhs > 0 {
+			R := matrix.Minus(B, matrix.Times(A, X))
+			for j := range res.Residual {
+				res.Residual[j] = columnNorm(R, j, 0, m)
+			}
+		}
+	default:
+		X, eq, err := solveSquare(op, A, B, opts...)
+		if err != nil {
+			return nil, err
+		}
+		res = &SolveResult{X, SquareSystem, n, nil, eq, nil, nil}
+		if c := startCrossCheck(opts...); c != nil {
+			crossCheckSolve(c, A, X, B)
+			c.report(op, res.Formulation.String())
+			res.CrossCheck = c
+		}
+	}
+	if v := startVerify(opts...); v != nil {
+		verifySolve(v, A, res.X, B)
+		v.report(op, res.Formulation.String())
+		res.Verification = v
+	}
+	return res, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
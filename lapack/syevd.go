@@ -8,7 +8,6 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
 )
@@ -39,9 +38,13 @@ import (
   offsetA   nonnegative integer
   offsetB   nonnegative integer;
 */
-func Syevd(A, W matrix.Matrix, opts ...linalg.Option) error {
+func Syevd(A, W matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Syevd", &err)()
+	if err = writable("Syevd", A, W); err != nil {
+		return
+	}
 	if !matrix.EqualTypes(A, W) {
-		return onError("Syevd: arguments not of same type")
+		return onError(linalg.ErrType, "Syevd: arguments not of same type")
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
@@ -49,12 +52,15 @@ func Syevd(A, W matrix.Matrix, opts ...linalg.Option) error {
 		Wm := W.(*matrix.FloatMatrix)
 		return SyevdFloat(Am, Wm, opts...)
 	case *matrix.ComplexMatrix:
-		return onError("Syevd: not a complex function")
+		return onError(linalg.ErrType, "Syevd: not a complex function")
 	}
-	return onError("Syevd: unknown types")
+	return onError(linalg.ErrType, "Syevd: unknown types")
 }
 
-func SyevdFloat(A, W *matrix.FloatMatrix, opts ...linalg.Option) error {
+func SyevdFloat(A, W *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("lapack.Syevd")
+	defer op.Finish(&err)
+	defer guard("SyevdFloat", &err)()
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
 		return err
@@ -67,13 +73,17 @@ func SyevdFloat(A, W *matrix.FloatMatrix, opts ...linalg.Option) error {
 	if ind.N == 0 {
 		return nil
 	}
+	op.SetDims(ind.N)
+	if err = checkMemory("syevd", opts, ind.N); err != nil {
+		return err
+	}
 	jobz := linalg.ParamString(pars.Jobz)
 	uplo := linalg.ParamString(pars.Uplo)
 	Aa := A.FloatArray()
 	Wa := W.FloatArray()
 	info := dsyevd(jobz, uplo, ind.N, Aa[ind.OffsetA:], ind.LDa, Wa[ind.OffsetW:])
 	if info != 0 {
-		return onError(fmt.Sprintf("Syevd: lapack error %d", info))
+		return onLapackError("Syevd", info, linalg.ErrNoConvergence)
 	}
 	return nil
 }
@@ -83,7 +93,7 @@ func checkSyevd(ind *linalg.IndexOpts, A, W matrix.Matrix) error {
 	if ind.N < 0 {
 		ind.N = A.Rows()
 		if ind.N != A.Cols() {
-			return onError("Syevd: A not square")
+			return onError(linalg.ErrShape, "Syevd: A not square")
 		}
 	}
 	if ind.N == 0 {
@@ -94,21 +104,21 @@ func checkSyevd(ind *linalg.IndexOpts, A, W matrix.Matrix) error {
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, ind.N) {
-		return onError("Syevd: lda")
+		return onError(linalg.ErrParameter, "Syevd: lda")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Syevd: offsetA")
+		return onError(linalg.ErrParameter, "Syevd: offsetA")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.N {
-		return onError("Syevd: sizeA")
+		return onError(linalg.ErrShape, "Syevd: sizeA")
 	}
 	if ind.OffsetW < 0 {
-		return onError("Syevd: offsetW")
+		return onError(linalg.ErrParameter, "Syevd: offsetW")
 	}
 	sizeW := W.NumElements()
 	if sizeW < ind.OffsetW+ind.N {
-		return onError("Syevd: sizeW")
+		return onError(linalg.ErrShape, "Syevd: sizeW")
 	}
 	return nil
 }
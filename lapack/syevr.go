@@ -8,7 +8,6 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
 )
@@ -65,9 +64,13 @@ import (
   m         the number of eigenvalues computed
 
 */
-func Syevr(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit []int, opts ...linalg.Option) error {
+func Syevr(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit []int, opts ...linalg.Option) (err error) {
+	defer guard("Syevr", &err)()
+	if err = writable("Syevr", A, W, Z); err != nil {
+		return
+	}
 	if !matrix.EqualTypes(A, W, Z) {
-		return onError("Syevr: arguments not of same type")
+		return onError(linalg.ErrType, "Syevr: arguments not of same type")
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
@@ -76,10 +79,19 @@ func Syevr(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit []int
 		Zm := Z.(*matrix.FloatMatrix)
 		return SyevrFloat(Am, Wm, Zm, abstol, vlimit, ilimit, opts...)
 	}
-	return onError("Syevr: unknown types")
+	return onError(linalg.ErrType, "Syevr: unknown types")
 }
 
-func SyevrFloat(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit []int, opts ...linalg.Option) error {
+func SyevrFloat(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit []int, opts ...linalg.Option) (err error) {
+	op := linalg.StartOp("lapack.Syevr")
+	defer op.Finish(&err)
+	defer guard("SyevrFloat", &err)()
+	if err = writable("SyevrFloat", A, W, Z); err != nil {
+		return
+	}
+	if err = checkScalar("Syevr", "abstol", abstol); err != nil {
+		return
+	}
 	var vl, vu float64
 	var il, iu int
 
@@ -92,74 +104,84 @@ func SyevrFloat(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit
 	if ind.N < 0 {
 		ind.N = A.Rows()
 		if ind.N != A.Cols() {
-			return onError("Syevr: A not square")
+			return onError(linalg.ErrShape, "Syevr: A not square")
 		}
 	}
 	// Check indexes
 	if ind.N == 0 {
 		return nil
 	}
+	op.SetDims(ind.N)
+	if err = checkMemory("syevr", opts, ind.N); err != nil {
+		return err
+	}
 	if ind.LDa == 0 {
 		ind.LDa = max(1, A.LeadingIndex())
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, A.Rows()) {
-		return onError("Syevr: lda")
+		return onError(linalg.ErrParameter, "Syevr: lda")
 	}
 	if pars.Range == linalg.PRangeValue {
 		if vlimit == nil {
-			return onError("Syevr: vlimit is nil")
+			return onError(linalg.ErrParameter, "Syevr: vlimit is nil")
 		}
 		vl = vlimit[0]
 		vu = vlimit[1]
+		if err = checkScalar("Syevr", "vl", vl); err != nil {
+			return
+		}
+		if err = checkScalar("Syevr", "vu", vu); err != nil {
+			return
+		}
 		if vl >= vu {
-			return onError("Syevr: must be: vl < vu")
+			return onError(linalg.ErrParameter, "Syevr: must be: vl < vu")
 		}
 	} else if pars.Range == linalg.PRangeInt {
 		if ilimit == nil {
-			return onError("Syevr: ilimit is nil")
+			return onError(linalg.ErrParameter, "Syevr: ilimit is nil")
 		}
 		il = ilimit[0]
 		iu = ilimit[1]
 		if il < 1 || il > iu || iu > ind.N {
-			return onError("Syevr: must be:1 <= il <= iu <= N")
+			return onError(linalg.ErrParameter, "Syevr: must be:1 <= il <= iu <= N")
 		}
 	}
 	if pars.Jobz == linalg.PJobValue {
 		if Z == nil {
-			return onError("Syevr: Z is nil")
+			return onError(linalg.ErrParameter, "Syevr: Z is nil")
 		}
 		if ind.LDz == 0 {
 			ind.LDz = max(1, Z.LeadingIndex())
 		}
 		if ind.LDz < max(1, ind.N) {
-			return onError("Syevr: ldz")
+			return onError(linalg.ErrParameter, "Syevr: ldz")
 		}
 	} else {
 		if ind.LDz == 0 {
 			ind.LDz = 1
 		}
 		if ind.LDz < 1 {
-			return onError("Syevr: ldz")
+			return onError(linalg.ErrParameter, "Syevr: ldz")
 		}
 	}
 	if ind.OffsetA < 0 {
-		return onError("Syevr: OffsetA")
+		return onError(linalg.ErrParameter, "Syevr: OffsetA")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.N {
-		return onError("Syevr: sizeA")
+		return onError(linalg.ErrShape, "Syevr: sizeA")
 	}
 	if ind.OffsetW < 0 {
-		return onError("Syevr: OffsetW")
+		return onError(linalg.ErrParameter, "Syevr: OffsetW")
 	}
 	sizeW := W.NumElements()
 	if sizeW < ind.OffsetW+ind.N {
-		return onError("Syevr: sizeW")
+		return onError(linalg.ErrShape, "Syevr: sizeW")
 	}
 	if pars.Jobz == linalg.PJobValue {
 		if ind.OffsetZ < 0 {
-			return onError("Syevr: OffsetW")
+			return onError(linalg.ErrParameter, "Syevr: OffsetW")
 		}
 		zrows := max(1, Z.Rows())
 		minZ := ind.OffsetZ + (ind.N-1)*zrows + ind.N
@@ -167,7 +189,7 @@ func SyevrFloat(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit
 			minZ = ind.OffsetZ + (iu-il)*zrows + ind.N
 		}
 		if Z.NumElements() < minZ {
-			return onError("Syevr: sizeZ")
+			return onError(linalg.ErrShape, "Syevr: sizeZ")
 		}
 	}
 
@@ -184,9 +206,9 @@ func SyevrFloat(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit
 	uplo := linalg.ParamString(pars.Uplo)
 
 	info := dsyevr(jobz, rnge, uplo, ind.N, Aa[ind.OffsetA:], ind.LDa,
-		vl, vu, il, iu, ind.M, Wa[ind.OffsetW:], Za, ind.LDz)
+		vl, vu, il, iu, abstol, ind.M, Wa[ind.OffsetW:], Za, ind.LDz)
 	if info != 0 {
-		return onError(fmt.Sprintf("Syevr: lapack error %d", info))
+		return onLapackError("Syevr", info, linalg.ErrNoConvergence)
 	}
 	return nil
 }
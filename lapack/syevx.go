@@ -8,7 +8,6 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
 )
@@ -46,6 +45,10 @@ import (
   abstol    double.  Absolute error tolerance for eigenvalues.
             If nonpositive, the LAPACK default value is used.
 
+ If some eigenvectors fail to converge the error is ErrNoConvergence and
+ linalg.Unconverged returns their indices; W and the other columns of Z
+ are valid.
+
  OPTIONS
   jobz      linalg.OptJobNo or linalg.OptJobValue
   range     linalg.OptRangeAll, linalg.OptRangeValue or linalg.OptRangeInt
@@ -62,24 +65,29 @@ import (
   offsetW   nonnegative integer
   offsetZ   nonnegative integer
 */
-func Syevx(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit []int, opts ...linalg.Option) error {
+func Syevx(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit []int, opts ...linalg.Option) (err error) {
+	defer guard("Syevx", &err)()
+	if err = writable("Syevx", A, W, Z); err != nil {
+		return
+	}
 	if !matrix.EqualTypes(A, W, Z) {
-		return onError("Syevx: not same type")
+		return onError(linalg.ErrType, "Syevx: not same type")
 	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
-		Am := A.(*matrix.FloatMatrix)
-		Wm := W.(*matrix.FloatMatrix)
-		var Zm *matrix.FloatMatrix = nil
-		if Z != nil {
-			Zm = Z.(*matrix.FloatMatrix)
-		}
-		return SyevrFloat(Am, Wm, Zm, abstol, vlimit, ilimit, opts...)
+		return SyevxFloat(A, W, Z, abstol, vlimit, ilimit, opts...)
 	}
-	return onError("Syevr: unknown types")
+	return onError(linalg.ErrType, "Syevx: unknown types")
 }
 
-func SyevxFloat(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit []int, opts ...linalg.Option) error {
+func SyevxFloat(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit []int, opts ...linalg.Option) (err error) {
+	defer guard("SyevxFloat", &err)()
+	if err = writable("SyevxFloat", A, W, Z); err != nil {
+		return
+	}
+	if err = checkScalar("Syevx", "abstol", abstol); err != nil {
+		return
+	}
 	var vl, vu float64
 	var il, iu int
 
@@ -92,7 +100,7 @@ func SyevxFloat(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit
 	if ind.N < 0 {
 		ind.N = A.Rows()
 		if ind.N != A.Cols() {
-			return onError("Syevr: A not square")
+			return onError(linalg.ErrShape, "Syevr: A not square")
 		}
 	}
 	// Check indexes
@@ -104,62 +112,68 @@ func SyevxFloat(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, A.Rows()) {
-		return onError("Syevr: lda")
+		return onError(linalg.ErrParameter, "Syevr: lda")
 	}
 	if pars.Range == linalg.PRangeValue {
 		if vlimit == nil {
-			return onError("Syevx: vlimit is nil")
+			return onError(linalg.ErrParameter, "Syevx: vlimit is nil")
 		}
 		vl = vlimit[0]
 		vu = vlimit[1]
+		if err = checkScalar("Syevx", "vl", vl); err != nil {
+			return
+		}
+		if err = checkScalar("Syevx", "vu", vu); err != nil {
+			return
+		}
 		if vl >= vu {
-			return onError("Syevx: must be: vl < vu")
+			return onError(linalg.ErrParameter, "Syevx: must be: vl < vu")
 		}
 	} else if pars.Range == linalg.PRangeInt {
 		if ilimit == nil {
-			return onError("Syevx: ilimit is nil")
+			return onError(linalg.ErrParameter, "Syevx: ilimit is nil")
 		}
 		il = ilimit[0]
 		iu = ilimit[1]
 		if il < 1 || il > iu || iu > ind.N {
-			return onError("Syevx: must be:1 <= il <= iu <= N")
+			return onError(linalg.ErrParameter, "Syevx: must be:1 <= il <= iu <= N")
 		}
 	}
 	if pars.Jobz == linalg.PJobValue {
 		if Z == nil {
-			return onError("Syevx: Z is nil")
+			return onError(linalg.ErrParameter, "Syevx: Z is nil")
 		}
 		if ind.LDz == 0 {
 			ind.LDz = max(1, Z.LeadingIndex())
 		}
 		if ind.LDz < max(1, ind.N) {
-			return onError("Syevx: ldz")
+			return onError(linalg.ErrParameter, "Syevx: ldz")
 		}
 	} else {
 		if ind.LDz == 0 {
 			ind.LDz = 1
 		}
 		if ind.LDz < 1 {
-			return onError("Syevx: ldz")
+			return onError(linalg.ErrParameter, "Syevx: ldz")
 		}
 	}
 	if ind.OffsetA < 0 {
-		return onError("Syevx: OffsetA")
+		return onError(linalg.ErrParameter, "Syevx: OffsetA")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.N {
-		return onError("Syevx: sizeA")
+		return onError(linalg.ErrShape, "Syevx: sizeA")
 	}
 	if ind.OffsetW < 0 {
-		return onError("Syevx: OffsetW")
+		return onError(linalg.ErrParameter, "Syevx: OffsetW")
 	}
 	sizeW := W.NumElements()
 	if sizeW < ind.OffsetW+ind.N {
-		return onError("Syevx: sizeW")
+		return onError(linalg.ErrShape, "Syevx: sizeW")
 	}
 	if pars.Jobz == linalg.PJobValue {
 		if ind.OffsetZ < 0 {
-			return onError("Syevx: OffsetW")
+			return onError(linalg.ErrParameter, "Syevx: OffsetW")
 		}
 		zrows := max(1, Z.Rows())
 		minZ := ind.OffsetZ + (ind.N-1)*zrows + ind.N
@@ -167,7 +181,7 @@ func SyevxFloat(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit
 			minZ = ind.OffsetZ + (iu-il)*zrows + ind.N
 		}
 		if Z.NumElements() < minZ {
-			return onError("Syevx: sizeZ")
+			return onError(linalg.ErrShape, "Syevx: sizeZ")
 		}
 	}
 
@@ -183,10 +197,24 @@ func SyevxFloat(A, W, Z matrix.Matrix, abstol float64, vlimit []float64, ilimit
 	rnge := linalg.ParamString(pars.Range)
 	uplo := linalg.ParamString(pars.Uplo)
 
+	var ifail []int32
+	if pars.Jobz == linalg.PJobValue {
+		alloc := linalg.GetAllocator()
+		ifail = alloc.Int32s(ind.N)
+		defer alloc.Free(ifail)
+	}
 	info := dsyevx(jobz, rnge, uplo, ind.N, Aa[ind.OffsetA:], ind.LDa,
-		vl, vu, il, iu, ind.M, Wa[ind.OffsetW:], Za, ind.LDz)
+		vl, vu, il, iu, abstol, ind.M, Wa[ind.OffsetW:], Za, ind.LDz, ifail)
+	if info > 0 && ifail != nil && info <= ind.N {
+		// eigenvalues and the other eigenvectors are valid
+		unconverged := make([]int, info)
+		for k := range unconverged {
+			unconverged[k] = int(ifail[k]) - 1
+		}
+		return onConvergenceError("Syevx", info, unconverged)
+	}
 	if info != 0 {
-		return onError(fmt.Sprintf("Syevx: call failed %d", info))
+		return onLapackError("Syevx", info, linalg.ErrNoConvergence)
 	}
 	return nil
 }
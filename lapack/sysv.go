@@ -0,0 +1,133 @@
+// Copyright (c) Harri Rautila, 2012,2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+)
+
+/*
+ Solves a real or complex symmetric set of linear equations.
+
+ PURPOSE
+ Solves
+  A*X = B
+
+ with A real or complex symmetric of order n and B n by nrhs, using
+ the LDL^T factorization computed by Sytrf.  Complex A is symmetric,
+ not Hermitian (A = A^T), as arises eg. in electromagnetic finite
+ element models.  On exit, B is replaced by the solution.  If ipiv is
+ provided, A and ipiv contain the factorization as returned by Sytrf
+ and may be passed to Sytrs; if ipiv is nil, A is not modified.
+
+ ARGUMENTS
+  A         float or complex matrix
+  B         float or complex matrix.  Must have the same type as A.
+  ipiv      int vector of length at least n, or nil
+
+ OPTIONS
+  uplo      PLower or PUpper
+  n         nonnegative integer.  If negative, the default value is used.
+  nrhs      nonnegative integer.  If negative, the default value is used.
+  ldA       positive integer.  ldA >= max(1,n).  If zero, the default
+            value is used.
+  ldB       nonnegative integer.  ldB >= max(1,n).  If zero, the
+            default value is used.
+  offsetA   nonnegative integer
+  offsetB   nonnegative integer;
+
+*/
+func Sysv(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) (err error) {
+	defer guard("Sysv", &err)()
+	if err = writable("Sysv", B); err != nil {
+		return
+	}
+	if ipiv != nil {
+		// A is overwritten with the factorization
+		if err = writable("Sysv", A); err != nil {
+			return
+		}
+	}
+	A = matops.Readable(A)
+	pars, err := linalg.GetParameters(opts...)
+	if err != nil {
+		return err
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	if err = checkSytrf(ind, A, ipiv); err != nil {
+		return err
+	}
+	brows := ind.LDb
+	if ind.Nrhs < 0 {
+		ind.Nrhs = B.Cols()
+	}
+	if ind.N == 0 || ind.Nrhs == 0 {
+		return nil
+	}
+	if ind.LDb == 0 {
+		ind.LDb = max(1, B.LeadingIndex())
+		brows = max(1, B.Rows())
+	}
+	if ind.LDb < max(1, ind.N) {
+		return onError(linalg.ErrParameter, "Sysv: ldB")
+	}
+	if ind.OffsetB < 0 {
+		return onError(linalg.ErrParameter, "Sysv: offsetB")
+	}
+	sizeB := B.NumElements()
+	if sizeB < ind.OffsetB+(ind.Nrhs-1)*brows+ind.N {
+		return onError(linalg.ErrShape, "Sysv: sizeB")
+	}
+	if !matrix.EqualTypes(A, B) {
+		return onError(linalg.ErrType, "Sysv: arguments not of same type")
+	}
+	// With ipiv nil the factorization is not returned and A must not be
+	// overwritten; work on a scratch copy from the allocator.
+	scratch := ipiv == nil
+	alloc := linalg.GetAllocator()
+	if scratch {
+		ipiv = alloc.Int32s(ind.N)
+		defer alloc.Free(ipiv)
+	}
+	nA := (ind.N-1)*ind.LDa + ind.N
+	uplo := linalg.ParamString(pars.Uplo)
+	info := -1
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Aa := A.(*matrix.FloatMatrix).FloatArray()[ind.OffsetA:]
+		if scratch {
+			Ac := alloc.Float64s(nA)
+			defer alloc.Free(Ac)
+			copy(Ac, Aa[:nA])
+			Aa = Ac
+		}
+		Ba := B.(*matrix.FloatMatrix).FloatArray()
+		info = dsysv(uplo, ind.N, ind.Nrhs, Aa, ind.LDa, ipiv, Ba[ind.OffsetB:], ind.LDb)
+	case *matrix.ComplexMatrix:
+		Aa := A.(*matrix.ComplexMatrix).ComplexArray()[ind.OffsetA:]
+		if scratch {
+			Ac := alloc.Complex128s(nA)
+			defer alloc.Free(Ac)
+			copy(Ac, Aa[:nA])
+			Aa = Ac
+		}
+		Ba := B.(*matrix.ComplexMatrix).ComplexArray()
+		info = zsysv(uplo, ind.N, ind.Nrhs, Aa, ind.LDa, ipiv, Ba[ind.OffsetB:], ind.LDb)
+	default:
+		return onError(linalg.ErrType, "Sysv: unknown types")
+	}
+	if info != 0 {
+		return onLapackError("Sysv", info, linalg.ErrSingular)
+	}
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -8,7 +8,6 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
 	"github.com/nvcook42/matrix"
 )
@@ -19,7 +18,7 @@ import (
  PURPOSE
  Computes the LDL^T factorization of a real or complex symmetric
  n by n matrix  A.  On exit, A and ipiv contain the details of the
- factorization.
+ factorization.  Complex A is symmetric, not Hermitian: A = A^T.
 
  ARGUMENTS
   A         float or complex matrix
@@ -33,17 +32,22 @@ import (
   offsetA   nonnegative integer;
 
 */
-func Sytrf(A matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
+func Sytrf(A matrix.Matrix, ipiv []int32, opts ...linalg.Option) (err error) {
+	defer guard("Sytrf", &err)()
+	if err = writable("Sytrf", A); err != nil {
+		return
+	}
 	switch A.(type) {
 	case *matrix.FloatMatrix:
 		return SytrfFloat(A.(*matrix.FloatMatrix), ipiv, opts...)
 	case *matrix.ComplexMatrix:
 		return SytrfComplex(A.(*matrix.ComplexMatrix), ipiv, opts...)
 	}
-	return onError("Sytrf: unknown types")
+	return onError(linalg.ErrType, "Sytrf: unknown types")
 }
 
-func SytrfFloat(A *matrix.FloatMatrix, ipiv []int32, opts ...linalg.Option) error {
+func SytrfFloat(A *matrix.FloatMatrix, ipiv []int32, opts ...linalg.Option) (err error) {
+	defer guard("SytrfFloat", &err)()
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
 		return err
@@ -60,13 +64,32 @@ func SytrfFloat(A *matrix.FloatMatrix, ipiv []int32, opts ...linalg.Option) erro
 	uplo := linalg.ParamString(pars.Uplo)
 	info := dsytrf(uplo, ind.N, Aa[ind.OffsetA:], ind.LDa, ipiv)
 	if info != 0 {
-		return onError(fmt.Sprintf("Sytrf: lapack error %d", info))
+		return onLapackError("Sytrf", info, linalg.ErrSingular)
 	}
 	return nil
 }
 
-func SytrfComplex(A *matrix.ComplexMatrix, ipiv []int32, opts ...linalg.Option) error {
-	return onError(fmt.Sprintf("Sytrf: complex not yet implemented"))
+func SytrfComplex(A *matrix.ComplexMatrix, ipiv []int32, opts ...linalg.Option) (err error) {
+	defer guard("SytrfComplex", &err)()
+	pars, err := linalg.GetParameters(opts...)
+	if err != nil {
+		return err
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	err = checkSytrf(ind, A, ipiv)
+	if err != nil {
+		return err
+	}
+	if ind.N == 0 {
+		return nil
+	}
+	Aa := A.ComplexArray()
+	uplo := linalg.ParamString(pars.Uplo)
+	info := zsytrf(uplo, ind.N, Aa[ind.OffsetA:], ind.LDa, ipiv)
+	if info != 0 {
+		return onLapackError("Sytrf", info, linalg.ErrSingular)
+	}
+	return nil
 }
 
 func checkSytrf(ind *linalg.IndexOpts, A matrix.Matrix, ipiv []int32) error {
@@ -74,7 +97,7 @@ func checkSytrf(ind *linalg.IndexOpts, A matrix.Matrix, ipiv []int32) error {
 	if ind.N < 0 {
 		ind.N = A.Rows()
 		if ind.N != A.Cols() {
-			return onError("A not square")
+			return onError(linalg.ErrShape, "A not square")
 		}
 	}
 	if ind.N == 0 {
@@ -85,17 +108,17 @@ func checkSytrf(ind *linalg.IndexOpts, A matrix.Matrix, ipiv []int32) error {
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, ind.N) {
-		return onError("Sytrf: lda")
+		return onError(linalg.ErrParameter, "Sytrf: lda")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Sytrf: offsetA")
+		return onError(linalg.ErrParameter, "Sytrf: offsetA")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.N {
-		return onError("Sytrf: sizeA")
+		return onError(linalg.ErrShape, "Sytrf: sizeA")
 	}
 	if ipiv != nil && len(ipiv) < ind.N {
-		return onError("Sytrf: size ipiv")
+		return onError(linalg.ErrShape, "Sytrf: size ipiv")
 	}
 	return nil
 }
@@ -8,8 +8,8 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
 	"github.com/nvcook42/matrix"
 )
 
@@ -43,7 +43,12 @@ import (
   offsetB   nonnegative integer;
 
 */
-func Sytrs(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
+func Sytrs(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) (err error) {
+	defer guard("Sytrs", &err)()
+	if err = writable("Sytrs", B); err != nil {
+		return
+	}
+	A = matops.Readable(A)
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
 		return err
@@ -54,7 +59,7 @@ func Sytrs(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
 	if ind.N < 0 {
 		ind.N = A.Rows()
 		if ind.N != A.Cols() {
-			return onError("Sytrs: A not square")
+			return onError(linalg.ErrShape, "Sytrs: A not square")
 		}
 	}
 	if ind.Nrhs < 0 {
@@ -68,34 +73,34 @@ func Sytrs(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, ind.N) {
-		return onError("Sytrs: ldA")
+		return onError(linalg.ErrParameter, "Sytrs: ldA")
 	}
 	if ind.LDb == 0 {
 		ind.LDb = max(1, B.LeadingIndex())
 		brows = max(1, B.Rows())
 	}
 	if ind.LDb < max(1, ind.N) {
-		return onError("Sytrs: ldB")
+		return onError(linalg.ErrParameter, "Sytrs: ldB")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Sytrs: offsetA")
+		return onError(linalg.ErrParameter, "Sytrs: offsetA")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.N {
-		return onError("Sytrs: sizeA")
+		return onError(linalg.ErrShape, "Sytrs: sizeA")
 	}
 	if ind.OffsetB < 0 {
-		return onError("Sytrs: offsetB")
+		return onError(linalg.ErrParameter, "Sytrs: offsetB")
 	}
 	sizeB := B.NumElements()
 	if sizeB < ind.OffsetB+(ind.Nrhs-1)*brows+ind.N {
-		return onError("Sytrs: sizeB")
+		return onError(linalg.ErrShape, "Sytrs: sizeB")
 	}
 	if ipiv != nil && len(ipiv) < ind.N {
-		return onError("Sytrs: size ipiv")
+		return onError(linalg.ErrShape, "Sytrs: size ipiv")
 	}
 	if !matrix.EqualTypes(A, B) {
-		return onError("Sytrs: arguments not of same type")
+		return onError(linalg.ErrType, "Sytrs: arguments not of same type")
 	}
 	info := -1
 	switch A.(type) {
@@ -106,10 +111,14 @@ func Sytrs(A, B matrix.Matrix, ipiv []int32, opts ...linalg.Option) error {
 		info = dsytrs(uplo, ind.N, ind.Nrhs, Aa[ind.OffsetA:], ind.LDa, ipiv,
 			Ba[ind.OffsetB:], ind.LDb)
 	case *matrix.ComplexMatrix:
-		return onError("Sytrs: complex not yet implemented")
+		Aa := A.(*matrix.ComplexMatrix).ComplexArray()
+		Ba := B.(*matrix.ComplexMatrix).ComplexArray()
+		uplo := linalg.ParamString(pars.Uplo)
+		info = zsytrs(uplo, ind.N, ind.Nrhs, Aa[ind.OffsetA:], ind.LDa, ipiv,
+			Ba[ind.OffsetB:], ind.LDb)
 	}
 	if info != 0 {
-		return onError(fmt.Sprintf("Sytrs lapack error: %d", info))
+		return onLapackError("Sytrs", info, linalg.ErrParameter)
 	}
 	return nil
 }
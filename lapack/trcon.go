@@ -0,0 +1,129 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"strings"
+)
+
+/*
+ Reciprocal condition number of a triangular matrix.
+
+ PURPOSE
+ Estimates the reciprocal of the condition number of triangular matrix A
+ in the 1-norm or the infinity-norm,
+
+  rcond = 1/(norm(A)*norm(inv(A))).
+
+ ARGUMENTS
+  A         float matrix, n by n triangular
+
+ OPTIONS
+  uplo      PLower or PUpper
+  diag      PNonUnit or PUnit
+  norm      "1" or "O" for the 1-norm (default), "I" for the infinity-norm
+  n         integer.  If negative, the default value is used.  Only the
+            leading n by n submatrix of A is referenced.
+
+*/
+func Trcon(A *matrix.FloatMatrix, opts ...linalg.Option) (rcond float64, err error) {
+	defer guard("Trcon", &err)()
+	pars, err := linalg.GetParameters(opts...)
+	if err != nil {
+		return 0.0, err
+	}
+	norm := strings.ToUpper(linalg.GetStringOpt("norm", "1", opts...))
+	if norm != "1" && norm != "O" && norm != "I" {
+		return 0.0, onError(linalg.ErrParameter, "Trcon: illegal norm")
+	}
+	n := linalg.GetIntOpt("n", -1, opts...)
+	if n < 0 {
+		n = A.Rows()
+		if n != A.Cols() {
+			return 0.0, onError(linalg.ErrShape, "Trcon: A not square")
+		}
+	}
+	if n > A.Rows() || n > A.Cols() {
+		return 0.0, onError(linalg.ErrShape, "Trcon: n larger than A")
+	}
+	if n == 0 {
+		return 1.0, nil
+	}
+	uplo := linalg.ParamString(pars.Uplo)
+	diag := linalg.ParamString(pars.Diag)
+	rcond, info := dtrcon(norm, uplo, diag, n, A.FloatArray(), max(1, A.LeadingIndex()))
+	if info != 0 {
+		return 0.0, onLapackError("Trcon", info, linalg.ErrParameter)
+	}
+	return rcond, nil
+}
+
+/*
+ Reciprocal condition numbers of eigenvectors or singular vectors.
+
+ PURPOSE
+ Computes the reciprocal condition numbers sep[i] of the eigenvectors of a
+ symmetric matrix with eigenvalues W, or of the left or right singular
+ vectors of a general m by n matrix with singular values W. sep[i] is the
+ gap between W[i] and the nearest other value, bounded below by
+ eps*max|W|. The computed vector i has an angular error of about
+ eps*norm(A)/sep[i].
+
+ ARGUMENTS
+  W         float matrix of eigenvalues or singular values, in increasing
+            or decreasing order
+  sep       float matrix of length at least len(W)
+
+ OPTIONS
+  job       "E" for eigenvectors (default), "L" for left or "R" for right
+            singular vectors
+  m, n      integers, size of the matrix for singular vectors.  If
+            negative, len(W) is used.
+
+*/
+func Disna(W, sep *matrix.FloatMatrix, opts ...linalg.Option) (err error) {
+	defer guard("Disna", &err)()
+	if err = writable("Disna", sep); err != nil {
+		return
+	}
+	job := strings.ToUpper(linalg.GetStringOpt("job", "E", opts...))
+	if job != "E" && job != "L" && job != "R" {
+		return onError(linalg.ErrParameter, "Disna: illegal job")
+	}
+	k := W.NumElements()
+	m := linalg.GetIntOpt("m", -1, opts...)
+	n := linalg.GetIntOpt("n", -1, opts...)
+	if m < 0 {
+		m = k
+	}
+	if n < 0 {
+		n = k
+	}
+	if job == "E" && m != n {
+		return onError(linalg.ErrParameter, "Disna: m != n for eigenvectors")
+	}
+	if job != "E" && k != min(m, n) {
+		return onError(linalg.ErrShape, "Disna: len(W) != min(m, n)")
+	}
+	if sep.NumElements() < k {
+		return onError(linalg.ErrShape, "Disna: size sep")
+	}
+	if k == 0 {
+		return nil
+	}
+	info := ddisna(job, m, n, W.FloatArray(), sep.FloatArray())
+	if info != 0 {
+		return onLapackError("Disna", info, linalg.ErrParameter)
+	}
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
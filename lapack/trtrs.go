@@ -8,8 +8,8 @@ package lapack
 
 import (
 	//"errors"
-	"fmt"
 	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
 	"github.com/nvcook42/matrix"
 )
 
@@ -43,7 +43,12 @@ import (
   offsetB   nonnegative integer;
 
 */
-func Trtrs(A, B matrix.Matrix, opts ...linalg.Option) error {
+func Trtrs(A, B matrix.Matrix, opts ...linalg.Option) (err error) {
+	defer guard("Trtrs", &err)()
+	if err = writable("Trtrs", B); err != nil {
+		return
+	}
+	A = matops.Readable(A)
 	pars, err := linalg.GetParameters(opts...)
 	if err != nil {
 		return err
@@ -54,7 +59,7 @@ func Trtrs(A, B matrix.Matrix, opts ...linalg.Option) error {
 	if ind.N < 0 {
 		ind.N = A.Rows()
 		if ind.N != A.Cols() {
-			return onError("Trtrs: A not square")
+			return onError(linalg.ErrShape, "Trtrs: A not square")
 		}
 	}
 	if ind.Nrhs < 0 {
@@ -68,31 +73,31 @@ func Trtrs(A, B matrix.Matrix, opts ...linalg.Option) error {
 		arows = max(1, A.Rows())
 	}
 	if ind.LDa < max(1, ind.N) {
-		return onError("Trtrs: ldA")
+		return onError(linalg.ErrParameter, "Trtrs: ldA")
 	}
 	if ind.LDb == 0 {
 		ind.LDb = max(1, B.LeadingIndex())
 		brows = max(1, B.Rows())
 	}
 	if ind.LDb < max(1, ind.N) {
-		return onError("Trtrs: ldB")
+		return onError(linalg.ErrParameter, "Trtrs: ldB")
 	}
 	if ind.OffsetA < 0 {
-		return onError("Trtrs: offsetA")
+		return onError(linalg.ErrParameter, "Trtrs: offsetA")
 	}
 	sizeA := A.NumElements()
 	if sizeA < ind.OffsetA+(ind.N-1)*arows+ind.N {
-		return onError("Trtrs: sizeA")
+		return onError(linalg.ErrShape, "Trtrs: sizeA")
 	}
 	if ind.OffsetB < 0 {
-		return onError("Trtrs: offsetB")
+		return onError(linalg.ErrParameter, "Trtrs: offsetB")
 	}
 	sizeB := B.NumElements()
 	if sizeB < ind.OffsetB+(ind.Nrhs-1)*brows+ind.N {
-		return onError("Trtrs: sizeB")
+		return onError(linalg.ErrShape, "Trtrs: sizeB")
 	}
 	if !matrix.EqualTypes(A, B) {
-		return onError("Trtrs: arguments not of same type")
+		return onError(linalg.ErrType, "Trtrs: arguments not of same type")
 	}
 	info := -1
 	uplo := linalg.ParamString(pars.Uplo)
@@ -105,10 +110,10 @@ func Trtrs(A, B matrix.Matrix, opts ...linalg.Option) error {
 		info = dtrtrs(uplo, trans, diag, ind.N, ind.Nrhs, Aa[ind.OffsetA:], ind.LDa,
 			Ba[ind.OffsetB:], ind.LDb)
 	case *matrix.ComplexMatrix:
-		return onError("Trtrs: complex not yet implmented")
+		return onError(linalg.ErrNotImplemented, "Trtrs: complex not yet implmented")
 	}
 	if info != 0 {
-		return onError(fmt.Sprintf("Trtrs lapack error: %d", info))
+		return onLapackError("Trtrs", info, linalg.ErrSingular)
 	}
 	return nil
 }
@@ -0,0 +1,120 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+)
+
+// Solvers for the common case of a single right hand side given as a
+// slice. Each copies b into an n by 1 matrix, calls the matrix version with
+// the same arguments and options and returns the solution as a new slice;
+// b is not changed. A is used and overwritten as by the matrix version.
+
+// Solve A*x = b with Gesv. See Gesv.
+func GesvVector(A matrix.Matrix, b []float64, ipiv []int32, opts ...linalg.Option) ([]float64, error) {
+	X := vectorRHS(b, len(b))
+	if err := Gesv(A, X, ipiv, opts...); err != nil {
+		return nil, err
+	}
+	return X.FloatArray(), nil
+}
+
+// Solve A*x = b with the LU factorization computed by Getrf. See Getrs.
+func GetrsVector(A matrix.Matrix, b []float64, ipiv []int32, opts ...linalg.Option) ([]float64, error) {
+	X := vectorRHS(b, len(b))
+	if err := Getrs(A, X, ipiv, opts...); err != nil {
+		return nil, err
+	}
+	return X.FloatArray(), nil
+}
+
+// Solve A*x = b for positive definite A with Posv. See Posv.
+func PosvVector(A matrix.Matrix, b []float64, opts ...linalg.Option) ([]float64, error) {
+	X := vectorRHS(b, len(b))
+	if err := Posv(A, X, opts...); err != nil {
+		return nil, err
+	}
+	return X.FloatArray(), nil
+}
+
+// Solve A*x = b with the Cholesky factorization computed by Potrf. See Potrs.
+func PotrsVector(A matrix.Matrix, b []float64, opts ...linalg.Option) ([]float64, error) {
+	X := vectorRHS(b, len(b))
+	if err := Potrs(A, X, opts...); err != nil {
+		return nil, err
+	}
+	return X.FloatArray(), nil
+}
+
+// Solve A*x = b for symmetric A with Sysv. See Sysv.
+func SysvVector(A matrix.Matrix, b []float64, ipiv []int32, opts ...linalg.Option) ([]float64, error) {
+	X := vectorRHS(b, len(b))
+	if err := Sysv(A, X, ipiv, opts...); err != nil {
+		return nil, err
+	}
+	return X.FloatArray(), nil
+}
+
+// Solve A*x = b with the factorization computed by Sytrf. See Sytrs.
+func SytrsVector(A matrix.Matrix, b []float64, ipiv []int32, opts ...linalg.Option) ([]float64, error) {
+	X := vectorRHS(b, len(b))
+	if err := Sytrs(A, X, ipiv, opts...); err != nil {
+		return nil, err
+	}
+	return X.FloatArray(), nil
+}
+
+// Solve A*x = b for triangular A with Trtrs. See Trtrs.
+func TrtrsVector(A matrix.Matrix, b []float64, opts ...linalg.Option) ([]float64, error) {
+	X := vectorRHS(b, len(b))
+	if err := Trtrs(A, X, opts...); err != nil {
+		return nil, err
+	}
+	return X.FloatArray(), nil
+}
+
+// Solve least squares or minimum norm problem op(A)*x = b with Gels for m
+// by n matrix A. The length of b is m and of the result n for trans "N",
+// and the other way round for trans "T". See Gels.
+func GelsVector(A matrix.Matrix, b []float64, opts ...linalg.Option) ([]float64, error) {
+	pars, err := linalg.GetParameters(opts...)
+	if err != nil {
+		return nil, err
+	}
+	ind := linalg.GetIndexOpts(opts...)
+	m, n := ind.M, ind.N
+	if m < 0 {
+		m = A.Rows()
+	}
+	if n < 0 {
+		n = A.Cols()
+	}
+	if pars.Trans != linalg.PNoTrans {
+		m, n = n, m
+	}
+	if len(b) != m {
+		return nil, onError(linalg.ErrShape, "GelsVector: length of b")
+	}
+	X := vectorRHS(b, max(m, n))
+	if err = Gels(A, X, opts...); err != nil {
+		return nil, err
+	}
+	return X.FloatArray()[:n], nil
+}
+
+// Return rows by 1 matrix with b copied to its first elements.
+func vectorRHS(b []float64, rows int) *matrix.FloatMatrix {
+	X := matrix.FloatZeros(rows, 1)
+	copy(X.FloatArray(), b)
+	return X
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
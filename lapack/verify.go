@@ -0,0 +1,227 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/lapack package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package lapack
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"math/cmplx"
+	"sync/atomic"
+)
+
+// Threshold used when verification is requested with WithVerification
+// only. Backward stable results have relative residuals of a small
+// multiple of n*eps.
+const defaultVerifyThreshold = 1e-10
+
+/*
+ Result verification of Solve, Lstsq, Eig, EigSym and Gesvd.
+
+ When verification is enabled, by SetVerify, the verify option or
+ WithVerification, these routines compute relative residuals of their
+ result after it has been found:
+
+  Solve, square or minimum norm  ||b - A*x|| / (||A||*||x|| + ||b||)
+  Lstsq, least squares           ||A^T*r|| / (||A||*(||A||*||x|| + ||b||))
+  Eig, EigSym                    ||A*v - lambda*v|| / (||A||*||v||)
+  Gesvd                          ||A - U*S*V^T|| / ||A||
+
+ with one residual for each right hand side b or eigenpair and one for
+ the singular value decomposition, r = b - A*x, Frobenius norms for
+ matrices and 2-norms for vectors. Each is a normwise backward error and
+ is a small multiple of machine precision for a correct result whatever
+ the conditioning of A. A residual above Threshold, or one that is not a
+ number, sets Passed false and adds a warning to the logged record of
+ the operation (see linalg.SetLogger).
+
+ Verification costs about one more matrix product with A, and a copy of
+ A for Gesvd which overwrites it.
+*/
+type Verification struct {
+	Residual  []float64
+	Max       float64
+	Threshold float64
+	Passed    bool
+}
+
+var verifyThreshold uint64
+
+// Enable verification of all calls with residual threshold t and return
+// the previous threshold. Zero or negative t disables verification, which
+// is the default. The verify option overrides the threshold for one call.
+func SetVerify(t float64) float64 {
+	return math.Float64frombits(atomic.SwapUint64(&verifyThreshold, math.Float64bits(math.Max(0.0, t))))
+}
+
+// Return the global verification threshold; zero if disabled.
+func VerifyThreshold() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&verifyThreshold))
+}
+
+// Option that carries a pointer to verification to fill.
+type verificationOpt struct {
+	linalg.Option
+	v *Verification
+}
+
+// Return option that makes the verifying routines fill in v. It enables
+// verification for the call with the global threshold, or 1e-10 if
+// verification is not enabled globally.
+func WithVerification(v *Verification) linalg.Option {
+	return &verificationOpt{linalg.BoolOpt("verification", true), v}
+}
+
+// Return new verification if verification is enabled in options or
+// globally, otherwise nil.
+func startVerify(opts ...linalg.Option) *Verification {
+	var target *Verification
+	for _, o := range opts {
+		if v, ok := o.(*verificationOpt); ok {
+			target = v.v
+		}
+	}
+	t := linalg.GetFloatOpt("verify", VerifyThreshold(), opts...)
+	if t <= 0.0 && target == nil {
+		return nil
+	}
+	if t <= 0.0 {
+		t = defaultVerifyThreshold
+	}
+	if target == nil {
+		target = &Verification{}
+	}
+	*target = Verification{Threshold: t, Passed: true}
+	return target
+}
+
+// Add relative residual r to v.
+func (v *Verification) add(r float64) {
+	v.Residual = append(v.Residual, r)
+	if math.IsNaN(r) || r > v.Threshold {
+		v.Passed = false
+	}
+	if r > v.Max || math.IsNaN(r) {
+		v.Max = r
+	}
+}
+
+// Warn on op if v did not pass.
+func (v *Verification) report(op *linalg.Op, what string) {
+	if !v.Passed {
+		op.Warn(fmt.Sprintf("verification failed: %s residual %.3g > %.3g", what, v.Max, v.Threshold))
+	}
+}
+
+// Return ratio of r to d, r if d is zero.
+func relative(r, d float64) float64 {
+	if d == 0.0 {
+		return r
+	}
+	return r / d
+}
+
+// Return Frobenius norm of A.
+func frobenius(A *matrix.FloatMatrix) float64 {
+	s := 0.0
+	for j := 0; j < A.Cols(); j++ {
+		s = math.Hypot(s, columnNorm(A, j, 0, A.Rows()))
+	}
+	return s
+}
+
+// Add backward errors of solutions X of A*X = B to v.
+func verifySolve(v *Verification, A, X, B *matrix.FloatMatrix) {
+	anorm := frobenius(A)
+	R := matrix.Minus(B, matrix.Times(A, X))
+	for j := 0; j < B.Cols(); j++ {
+		r := columnNorm(R, j, 0, R.Rows())
+		x, b := columnNorm(X, j, 0, X.Rows()), columnNorm(B, j, 0, B.Rows())
+		v.add(relative(r, anorm*x+b))
+	}
+}
+
+// Add optimality residuals of least squares solutions X of A*X = B to v.
+func verifyLstsq(v *Verification, A, X, B *matrix.FloatMatrix) {
+	anorm := frobenius(A)
+	G := matrix.Times(A.Transpose(), matrix.Minus(B, matrix.Times(A, X)))
+	for j := 0; j < B.Cols(); j++ {
+		g := columnNorm(G, j, 0, G.Rows())
+		x, b := columnNorm(X, j, 0, X.Rows()), columnNorm(B, j, 0, B.Rows())
+		v.add(relative(g, anorm*(anorm*x+b)))
+	}
+}
+
+// Add eigenpair residuals of real A and complex eigenpairs to v.
+func verifyEig(v *Verification, A *matrix.FloatMatrix, values, vectors *matrix.ComplexMatrix) {
+	n := A.Rows()
+	anorm := frobenius(A)
+	for k := 0; k < n; k++ {
+		lambda := values.GetAt(k, 0)
+		r, x := 0.0, 0.0
+		for i := 0; i < n; i++ {
+			s := -lambda * vectors.GetAt(i, k)
+			for j := 0; j < n; j++ {
+				s += complex(A.GetAt(i, j), 0) * vectors.GetAt(j, k)
+			}
+			r = math.Hypot(r, cmplx.Abs(s))
+			x = math.Hypot(x, cmplx.Abs(vectors.GetAt(i, k)))
+		}
+		v.add(relative(r, anorm*x))
+	}
+}
+
+// Add eigenpair residuals of symmetric A to v.
+func verifyEigSym(v *Verification, A, values, vectors *matrix.FloatMatrix) {
+	anorm := frobenius(A)
+	R := matrix.Times(A, vectors)
+	for k := 0; k < A.Rows(); k++ {
+		for i := 0; i < A.Rows(); i++ {
+			R.SetAt(i, k, R.GetAt(i, k)-values.GetAt(k, 0)*vectors.GetAt(i, k))
+		}
+		r := columnNorm(R, k, 0, R.Rows())
+		v.add(relative(r, anorm*columnNorm(vectors, k, 0, vectors.Rows())))
+	}
+}
+
+// Return full symmetric matrix of the uplo triangle of A.
+func symmetrize(A *matrix.FloatMatrix, uplo int) *matrix.FloatMatrix {
+	S := A.Copy()
+	for j := 0; j < A.Cols(); j++ {
+		for i := j + 1; i < A.Rows(); i++ {
+			if uplo == linalg.PUpper {
+				S.SetAt(i, j, A.GetAt(j, i))
+			} else {
+				S.SetAt(j, i, A.GetAt(i, j))
+			}
+		}
+	}
+	return S
+}
+
+// Add residual of the m by n singular value decomposition U*diag(S)*Vt of
+// A with k = min(m,n) singular triplets to v.
+func verifySVD(v *Verification, A *matrix.FloatMatrix, S, U, Vt []float64, ldu, ldvt int) {
+	m, n := A.Rows(), A.Cols()
+	k := min(m, n)
+	E := A.Copy()
+	for j := 0; j < n; j++ {
+		for i := 0; i < m; i++ {
+			s := E.GetAt(i, j)
+			for l := 0; l < k; l++ {
+				s -= U[l*ldu+i] * S[l] * Vt[j*ldvt+l]
+			}
+			E.SetAt(i, j, s)
+		}
+	}
+	v.add(relative(frobenius(E), frobenius(A)))
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
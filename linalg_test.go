@@ -1,7 +1,14 @@
 package linalg
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"github.com/nvcook42/matrix"
+	"math"
+	"math/cmplx"
 	"testing"
 )
 
@@ -28,6 +35,196 @@ func TestOpt(t *testing.T) {
 	PrintOpts(&iopt, &fopt, &sopt, &BOpt{"bopt", true})
 }
 
+func TestBackendInfo(t *testing.T) {
+	// no cgo package linked into this test binary
+	b := BackendInfo()
+	t.Logf("backend: %v\n", b)
+	if b.Name != "none" || b.Has(ExtGemm3m) {
+		t.Fail()
+	}
+}
+
+type countingAllocator struct {
+	heapAllocator
+	n int
+}
+
+func (a *countingAllocator) Float64s(n int) []float64 {
+	a.n += n
+	return make([]float64, n)
+}
+
+func TestAllocator(t *testing.T) {
+	ca := &countingAllocator{}
+	prev := SetAllocator(ca)
+	GetAllocator().Float64s(10)
+	if SetAllocator(prev) != ca || ca.n != 10 {
+		t.Fail()
+	}
+	if _, ok := GetAllocator().(heapAllocator); !ok {
+		t.Logf("allocator not restored: %T\n", GetAllocator())
+		t.Fail()
+	}
+}
+
+func TestEstimateMemory(t *testing.T) {
+	// syevd of order 1000 needs about 2n^2 doubles of workspace
+	b, err := EstimateMemory("syevd", 1000)
+	if err != nil || b < 16e6 || b > 17e6 {
+		t.Logf("syevd: %d, %v\n", b, err)
+		t.Fail()
+	}
+	if _, err = EstimateMemory("gesvd", 10); !errors.Is(err, ErrParameter) {
+		t.Fail()
+	}
+	old := SetMemoryBudget(1 << 20)
+	defer SetMemoryBudget(old)
+	if err = CheckMemory("syevd", nil, 1000); !errors.Is(err, ErrMemoryBudget) {
+		t.Logf("over budget: %v\n", err)
+		t.Fail()
+	}
+	if err = CheckMemory("syevd", nil, 100); err != nil {
+		t.Fail()
+	}
+	if err = CheckMemory("syevd", []Option{IntOpt("membudget", 0)}, 1000); err != nil {
+		t.Logf("option did not disable check: %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestLogger(t *testing.T) {
+	if op := StartOp("test.Nop", 1); op.Enabled() {
+		t.Fatalf("operation recorded without logger")
+	}
+	var buf bytes.Buffer
+	SetLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer SetLogger(nil)
+
+	op := StartOp("test.Solve", 3, 2)
+	op.Warn("near-singular factor")
+	op.Finish(new(error))
+	var err error = NewError(ErrSingular, "singular")
+	op = StartOp("test.Factor")
+	op.SetDims(4, 4)
+	op.SetBackend("go")
+	op.Finish(&err)
+
+	var recs []map[string]interface{}
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r map[string]interface{}
+		if e := dec.Decode(&r); e != nil {
+			t.Fatalf("decode: %v", e)
+		}
+		recs = append(recs, r)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("want 2 records, got %d", len(recs))
+	}
+	t.Logf("%v\n", recs)
+	if recs[0]["op"] != "test.Solve" || recs[0]["level"] != "WARN" || recs[0]["warnings"] == nil {
+		t.Logf("record 0: %v\n", recs[0])
+		t.Fail()
+	}
+	if recs[1]["level"] != "ERROR" || recs[1]["backend"] != "go" || recs[1]["error"] == nil {
+		t.Logf("record 1: %v\n", recs[1])
+		t.Fail()
+	}
+	if recs[0]["id"] == recs[1]["id"] {
+		t.Logf("operation ids not unique\n")
+		t.Fail()
+	}
+}
+
+func TestOptionCodec(t *testing.T) {
+	opts := []Option{IntOpt("lda", -7), FloatOpt("tol", 1e-12), StringOpt("kernel", "go"),
+		BoolOpt("gemm3m", true), ComplexOpt("shift", 1-2i), IntOpt("n", MaxIndex+5)}
+	back, err := DecodeOptions(EncodeOptions(opts...))
+	if err != nil || len(back) != len(opts) {
+		t.Fatalf("decode: %v, %v\n", back, err)
+	}
+	for i := range opts {
+		if !opts[i].Equal(back[i]) {
+			t.Logf("option %d: %v != %v\n", i, opts[i], back[i])
+			t.Fail()
+		}
+	}
+	if _, err := DecodeOptions([]byte{'i', 3, 'l', 'd'}); !errors.Is(err, ErrParameter) {
+		t.Fail()
+	}
+	// out of range index options are clamped and rejected
+	ind := GetIndexOpts(back...)
+	if ind.N != MaxIndex+1 || ind.LDa != -7 || !errors.Is(CheckIndexOpts(ind), ErrParameter) {
+		t.Logf("n=%d, lda=%d\n", ind.N, ind.LDa)
+		t.Fail()
+	}
+	if GetBoolOpt("flag", true, StringOpt("flag", "")) {
+		t.Fail()
+	}
+}
+
+func FuzzDecodeOptions(f *testing.F) {
+	f.Add(EncodeOptions(IntOpt("m", 3), StringOpt("uplo", "L"), FloatOpt("x", math.NaN())))
+	f.Add([]byte{'c', 1, 'z', 0, 0, 0, 0, 0, 0, 0, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		opts, err := DecodeOptions(data)
+		if err != nil {
+			return
+		}
+		enc := EncodeOptions(opts...)
+		again, err := DecodeOptions(enc)
+		if err != nil || !bytes.Equal(EncodeOptions(again...), enc) {
+			t.Fatalf("round trip of %v: %v, %v\n", opts, again, err)
+		}
+		GetIndexOpts(opts...)
+		GetParameters(opts...)
+	})
+}
+
+func TestScalar(t *testing.T) {
+	type scalar struct{ f float64 }
+	cases := []struct {
+		v       interface{}
+		complex bool
+		real    error
+		cmplx   error
+	}{
+		{2.0, false, nil, nil},
+		{3, false, nil, nil},
+		{1 - 1i, true, ErrParameter, nil},
+		{math.NaN(), false, ErrParameter, ErrParameter},
+		{nil, false, ErrParameter, ErrParameter},
+		{scalar{1.0}, false, ErrParameter, ErrParameter},
+		{ComplexScalar(2i), true, ErrParameter, nil},
+		{matrix.FScalar(1.5), false, nil, nil},
+		{matrix.FScalar(math.NaN()), false, ErrParameter, ErrParameter},
+		{matrix.CScalar(1 + 2i), true, ErrParameter, nil},
+		{matrix.CScalar(2), true, ErrParameter, nil},
+		{matrix.CScalar(cmplx.NaN()), true, ErrParameter, ErrParameter},
+	}
+	for k, c := range cases {
+		s := ScalarOf(c.v)
+		if s.IsComplex() != c.complex {
+			t.Errorf("%d: %v complex %v\n", k, s, s.IsComplex())
+		}
+		if _, err := s.Real("alpha"); !errors.Is(err, c.real) {
+			t.Errorf("%d: Real(%v): %v\n", k, s, err)
+		}
+		if _, err := s.Cmplx("alpha"); !errors.Is(err, c.cmplx) {
+			t.Errorf("%d: Cmplx(%v): %v\n", k, s, err)
+		}
+	}
+	if v, _ := FloatScalar(2.5).Cmplx("beta"); v != 2.5 {
+		t.Errorf("FloatScalar(2.5) as complex: %v\n", v)
+	}
+	if v, _ := ScalarOf(matrix.CScalar(2)).Cmplx("alpha"); v != 2 {
+		t.Errorf("CScalar(2) as complex: %v\n", v)
+	}
+	if s := ScalarOf(FloatScalar(1.5)); s.Float() != 1.5 || s.String() != "1.5" {
+		t.Errorf("ScalarOf(FloatScalar(1.5)): %v\n", s)
+	}
+}
+
 // Local Variables:
 // tab-width: 4
 // End:
@@ -0,0 +1,109 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/linalgtest package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package linalgtest
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// If set, Golden rewrites golden files with computed results instead of
+// comparing against them. Tests usually set this from a command line flag.
+var UpdateGolden bool = false
+
+// Compare A against reference matrix stored in golden file path. Reports
+// test error if sizes differ or if any element differs more than
+// tol*max(1, |ref|). Missing golden file is created from A when UpdateGolden
+// is set, otherwise it is reported as error.
+func Golden(t testing.TB, path string, A *matrix.FloatMatrix, tol float64) {
+	t.Helper()
+	if UpdateGolden {
+		if err := WriteGolden(path, A); err != nil {
+			t.Errorf("update golden file: %s", err)
+		}
+		return
+	}
+	ref, err := ReadGolden(path)
+	if err != nil {
+		t.Errorf("read golden file: %s", err)
+		return
+	}
+	if ref.Rows() != A.Rows() || ref.Cols() != A.Cols() {
+		t.Errorf("%s: size %dx%d, golden size %dx%d", path,
+			A.Rows(), A.Cols(), ref.Rows(), ref.Cols())
+		return
+	}
+	for j := 0; j < A.Cols(); j++ {
+		for i := 0; i < A.Rows(); i++ {
+			r := ref.GetAt(i, j)
+			if d := math.Abs(A.GetAt(i, j) - r); !(d <= tol*math.Max(1.0, math.Abs(r))) {
+				t.Errorf("%s: element (%d,%d) = %.17g, golden %.17g", path, i, j, A.GetAt(i, j), r)
+			}
+		}
+	}
+}
+
+// Write A to golden file. First line holds number of rows and columns,
+// followed by elements in column-major order, one per line, with full
+// precision.
+func WriteGolden(path string, A *matrix.FloatMatrix) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "%d %d\n", A.Rows(), A.Cols())
+	for j := 0; j < A.Cols(); j++ {
+		for i := 0; i < A.Rows(); i++ {
+			fmt.Fprintf(w, "%.17g\n", A.GetAt(i, j))
+		}
+	}
+	if err = w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read matrix from golden file written with WriteGolden.
+func ReadGolden(path string) (*matrix.FloatMatrix, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r := bufio.NewReader(f)
+	var rows, cols int
+	if _, err = fmt.Fscan(r, &rows, &cols); err != nil {
+		return nil, err
+	}
+	if rows < 0 || cols < 0 {
+		return nil, linalg.NewError(linalg.ErrParameter,
+			fmt.Sprintf("%s: invalid golden file header %d %d", path, rows, cols))
+	}
+	A := matrix.FloatZeros(rows, cols)
+	Ar := A.FloatArray()
+	for k := range Ar {
+		if _, err = fmt.Fscan(r, &Ar[k]); err != nil {
+			return nil, err
+		}
+	}
+	return A, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,225 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/linalgtest package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+// Helpers for testing code built on linalg packages.
+//
+// Package provides reproducible generators for random matrices of given
+// structure and condition number and checks for relative residuals and
+// orthogonality. Generators and checks are implemented in pure Go and
+// do not depend on BLAS or LAPACK libraries.
+package linalgtest
+
+import (
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// Structure of generated matrix.
+type Structure int
+
+const (
+	General Structure = iota
+	Symmetric
+	PosDef // symmetric positive definite
+	Lower  // lower triangular
+	Upper  // upper triangular
+	Orthogonal
+)
+
+// Generator produces random test matrices from a seeded source.
+type Generator struct {
+	rnd *rand.Rand
+}
+
+// Return new generator seeded with seed.
+func NewGenerator(seed int64) *Generator {
+	return &Generator{rand.New(rand.NewSource(seed))}
+}
+
+// Return rows*cols matrix with standard normal elements and given structure.
+// Structures other than General require rows == cols.
+func (g *Generator) Float(rows, cols int, s Structure) *matrix.FloatMatrix {
+	A := matrix.FloatZeros(rows, cols)
+	Ar := A.FloatArray()
+	for k := range Ar {
+		Ar[k] = g.rnd.NormFloat64()
+	}
+	switch s {
+	case Symmetric:
+		symmetrize(A)
+	case PosDef:
+		// A^T*A + n*I is well conditioned positive definite
+		B := matrix.FloatZeros(cols, cols)
+		gemmTN(A, A, B)
+		for i := 0; i < cols; i++ {
+			B.SetAt(i, i, B.GetAt(i, i)+float64(cols))
+		}
+		return B
+	case Lower, Upper:
+		for j := 0; j < cols; j++ {
+			for i := 0; i < rows; i++ {
+				if (s == Lower && i < j) || (s == Upper && i > j) {
+					Ar[j*rows+i] = 0.0
+				}
+			}
+			if j < rows {
+				// keep diagonal away from zero
+				Ar[j*rows+j] = math.Copysign(1.0+math.Abs(Ar[j*rows+j]), Ar[j*rows+j])
+			}
+		}
+	case Orthogonal:
+		return g.orthogonal(rows)
+	}
+	return A
+}
+
+// Return n*n matrix with 2-norm condition number cond. Singular values are
+// geometrically spaced between 1 and 1/cond. Supported structures are
+// General (U*S*V^T), Symmetric (Q*D*Q^T with random signs) and
+// PosDef (Q*D*Q^T, see matops.RandWithSpectrum).
+func (g *Generator) WithCond(n int, cond float64, s Structure) *matrix.FloatMatrix {
+	d := make([]float64, n)
+	for i := range d {
+		if n > 1 {
+			d[i] = math.Pow(cond, -float64(i)/float64(n-1))
+		} else {
+			d[i] = 1.0
+		}
+		if s == Symmetric && g.rnd.Intn(2) == 1 {
+			d[i] = -d[i]
+		}
+	}
+	if s != General {
+		return matops.RandWithSpectrum(d, g.rnd)
+	}
+	// U*diag(d)*V^T
+	US := g.orthogonal(n)
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			US.SetAt(i, j, US.GetAt(i, j)*d[j])
+		}
+	}
+	A := matrix.FloatZeros(n, n)
+	gemmNT(US, g.orthogonal(n), A)
+	return A
+}
+
+// Return Haar distributed random n*n orthogonal matrix, see
+// matops.RandOrthogonal.
+func (g *Generator) orthogonal(n int) *matrix.FloatMatrix {
+	return matops.RandOrthogonal(n, g.rnd)
+}
+
+// Return relative residual ||A*X - B||_F / (||A||_F*||X||_F).
+func Residual(A, X, B *matrix.FloatMatrix) float64 {
+	R := B.Copy()
+	m, n := R.Size()
+	for j := 0; j < n; j++ {
+		for i := 0; i < m; i++ {
+			s := 0.0
+			for k := 0; k < A.Cols(); k++ {
+				s += A.GetAt(i, k) * X.GetAt(k, j)
+			}
+			R.SetAt(i, j, s-R.GetAt(i, j))
+		}
+	}
+	den := normF(A) * normF(X)
+	if den == 0.0 {
+		return normF(R)
+	}
+	return normF(R) / den
+}
+
+// Return ||Q^T*Q - I||_F, a measure of orthogonality of columns of Q.
+func Orthogonality(Q *matrix.FloatMatrix) float64 {
+	n := Q.Cols()
+	E := matrix.FloatZeros(n, n)
+	gemmTN(Q, Q, E)
+	for i := 0; i < n; i++ {
+		E.SetAt(i, i, E.GetAt(i, i)-1.0)
+	}
+	return normF(E)
+}
+
+// Report error if relative residual of A*X = B exceeds tol.
+func CheckResidual(t testing.TB, A, X, B *matrix.FloatMatrix, tol float64) {
+	t.Helper()
+	if r := Residual(A, X, B); !(r <= tol) {
+		t.Errorf("relative residual %.3e exceeds tolerance %.3e", r, tol)
+	}
+}
+
+// Report error if columns of Q are not orthonormal within tol.
+func CheckOrthogonal(t testing.TB, Q *matrix.FloatMatrix, tol float64) {
+	t.Helper()
+	if e := Orthogonality(Q); !(e <= tol) {
+		t.Errorf("orthogonality error %.3e exceeds tolerance %.3e", e, tol)
+	}
+}
+
+// Return Frobenius norm of A.
+func normF(A *matrix.FloatMatrix) float64 {
+	scale, ssq := 0.0, 1.0
+	m, n := A.Size()
+	for j := 0; j < n; j++ {
+		for i := 0; i < m; i++ {
+			v := math.Abs(A.GetAt(i, j))
+			if v == 0.0 {
+				continue
+			}
+			if scale < v {
+				ssq = 1.0 + ssq*(scale/v)*(scale/v)
+				scale = v
+			} else {
+				ssq += (v / scale) * (v / scale)
+			}
+		}
+	}
+	return scale * math.Sqrt(ssq)
+}
+
+// Copy lower triangle to upper triangle.
+func symmetrize(A *matrix.FloatMatrix) {
+	n := A.Rows()
+	for j := 0; j < n; j++ {
+		for i := j + 1; i < n; i++ {
+			A.SetAt(j, i, A.GetAt(i, j))
+		}
+	}
+}
+
+// C = A^T*B
+func gemmTN(A, B, C *matrix.FloatMatrix) {
+	for j := 0; j < C.Cols(); j++ {
+		for i := 0; i < C.Rows(); i++ {
+			s := 0.0
+			for k := 0; k < A.Rows(); k++ {
+				s += A.GetAt(k, i) * B.GetAt(k, j)
+			}
+			C.SetAt(i, j, s)
+		}
+	}
+}
+
+// C = A*B^T
+func gemmNT(A, B, C *matrix.FloatMatrix) {
+	for j := 0; j < C.Cols(); j++ {
+		for i := 0; i < C.Rows(); i++ {
+			s := 0.0
+			for k := 0; k < A.Cols(); k++ {
+				s += A.GetAt(i, k) * B.GetAt(j, k)
+			}
+			C.SetAt(i, j, s)
+		}
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,61 @@
+package linalgtest
+
+import (
+	"errors"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOrthogonal(t *testing.T) {
+	g := NewGenerator(1)
+	Q := g.Float(6, 6, Orthogonal)
+	t.Logf("Q:\n%v\n", Q)
+	CheckOrthogonal(t, Q, 1e-12)
+}
+
+func TestWithCond(t *testing.T) {
+	g := NewGenerator(2)
+	A := g.WithCond(5, 1e4, PosDef)
+	for i := 0; i < 5; i++ {
+		for j := 0; j < i; j++ {
+			if A.GetAt(i, j) != A.GetAt(j, i) {
+				t.Log("PosDef matrix not symmetric\n")
+				t.Fail()
+			}
+		}
+	}
+	// trace equals sum of eigenvalues 1 ... 1e-4
+	tr := 0.0
+	for i := 0; i < 5; i++ {
+		tr += A.GetAt(i, i)
+	}
+	if tr < 1.0 || tr > 2.0 {
+		t.Logf("unexpected trace %f\n", tr)
+		t.Fail()
+	}
+	X := matrix.FloatVector([]float64{1, 2, 3, 4, 5})
+	B := matrix.Times(A, X)
+	CheckResidual(t, A, X, B, 1e-14)
+}
+
+func TestGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "A.golden")
+	A := NewGenerator(3).Float(3, 2, General)
+	if err := WriteGolden(path, A); err != nil {
+		t.Fatal(err)
+	}
+	Golden(t, path, A, 0.0)
+	if err := os.WriteFile(path, []byte("-1 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadGolden(path); !errors.Is(err, linalg.ErrParameter) {
+		t.Errorf("negative size in header: %v", err)
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,139 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package linalg
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	opLogger atomic.Pointer[slog.Logger]
+	opSerial atomic.Uint64
+)
+
+/*
+ Set logger receiving a record of each high-level operation.
+
+ Drivers such as lapack.Getrf, lapack.Gesv, lapack.Lstsq, lapack.Eig and
+ blas.Gemm log one record when they return. Each record has message
+ "linalg" and attributes
+
+    op        operation name, e.g. "lapack.Getrf"
+    id        operation identifier unique within the process
+    dims      operand dimensions, when known
+    backend   name of the BLAS/LAPACK backend (BackendInfo)
+    duration  wall clock time of the call
+    warnings  numerical warnings, e.g. near-singular factor, if any
+    error     returned error, if any
+
+ Records are logged at level Debug, at Warn if the operation reported
+ warnings and at Error if it failed. Setting nil, the default, disables
+ logging; operations then only pay for one atomic load.
+*/
+func SetLogger(l *slog.Logger) {
+	opLogger.Store(l)
+}
+
+// Return logger set with SetLogger or nil.
+func Logger() *slog.Logger {
+	return opLogger.Load()
+}
+
+// Record of one operation in progress. A nil *Op is valid and ignores all
+// calls, so callers need not check whether logging is enabled.
+type Op struct {
+	logger   *slog.Logger
+	name     string
+	id       uint64
+	dims     []int
+	backend  string
+	warnings []string
+	start    time.Time
+}
+
+// Start record of operation name. Returns nil if no logger is set.
+func StartOp(name string, dims ...int) *Op {
+	l := opLogger.Load()
+	if l == nil {
+		return nil
+	}
+	return &Op{logger: l, name: name, id: opSerial.Add(1), dims: dims, start: time.Now()}
+}
+
+// Report whether op is being recorded. Use to skip computing diagnostics
+// that are only needed for logging.
+func (op *Op) Enabled() bool {
+	return op != nil
+}
+
+// Set operand dimensions of op.
+func (op *Op) SetDims(dims ...int) {
+	if op != nil {
+		op.dims = dims
+	}
+}
+
+// Set backend that executed op when it is not the BLAS/LAPACK library,
+// e.g. a pure Go or GPU kernel.
+func (op *Op) SetBackend(name string) {
+	if op != nil {
+		op.backend = name
+	}
+}
+
+// Add numerical warning to op.
+func (op *Op) Warn(msg string) {
+	if op != nil {
+		op.warnings = append(op.warnings, msg)
+	}
+}
+
+// Log the record of op with error *err. Intended to be deferred with
+// pointer to the named error result of the operation.
+func (op *Op) Finish(err *error) {
+	if op == nil {
+		return
+	}
+	level := slog.LevelDebug
+	if len(op.warnings) > 0 {
+		level = slog.LevelWarn
+	}
+	if err != nil && *err != nil {
+		level = slog.LevelError
+	}
+	ctx := context.Background()
+	if !op.logger.Enabled(ctx, level) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("op", op.name),
+		slog.Uint64("id", op.id),
+	}
+	if op.dims != nil {
+		attrs = append(attrs, slog.Any("dims", op.dims))
+	}
+	if op.backend == "" {
+		op.backend = BackendInfo().Name
+	}
+	attrs = append(attrs,
+		slog.String("backend", op.backend),
+		slog.Duration("duration", time.Since(op.start)))
+	if len(op.warnings) > 0 {
+		attrs = append(attrs, slog.Any("warnings", op.warnings))
+	}
+	if level == slog.LevelError {
+		attrs = append(attrs, slog.String("error", (*err).Error()))
+	}
+	op.logger.LogAttrs(ctx, level, "linalg", attrs...)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
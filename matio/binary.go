@@ -0,0 +1,169 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matio package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"io"
+	"math"
+	"unsafe"
+)
+
+// Binary matrix file layout. The file starts with a header of
+// binaryHeaderSize bytes, all integers little-endian:
+//
+//	offset  size  field
+//	0       8     magic "LINALGMX"
+//	8       4     format version, 1
+//	12      4     element type, 0 for float64
+//	16      8     rows
+//	24      8     columns
+//	32      8     offset of data from start of file
+//	40      24    reserved, zero
+//
+// followed by rows*cols little-endian IEEE 754 doubles in column-major
+// order. The data offset is a multiple of 8 so that a page aligned
+// memory mapping of the file can be used as a []float64 directly.
+const (
+	binaryMagic      = "LINALGMX"
+	binaryVersion    = 1
+	binaryFloat64    = 0
+	binaryHeaderSize = 64
+	// data is read in chunks of at most this many bytes
+	binaryChunk = 1 << 20
+)
+
+type binaryHeader struct {
+	rows, cols, offset uint64
+}
+
+// Write float matrix A to w in the binary matrix format.
+func WriteBinary(w io.Writer, A *matrix.FloatMatrix) error {
+	hdr := make([]byte, binaryHeaderSize)
+	le := binary.LittleEndian
+	copy(hdr, binaryMagic)
+	le.PutUint32(hdr[8:], binaryVersion)
+	le.PutUint32(hdr[12:], binaryFloat64)
+	le.PutUint64(hdr[16:], uint64(A.Rows()))
+	le.PutUint64(hdr[24:], uint64(A.Cols()))
+	le.PutUint64(hdr[32:], binaryHeaderSize)
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(hdr); err != nil {
+		return err
+	}
+	var buf [8]byte
+	for j := 0; j < A.Cols(); j++ {
+		for i := 0; i < A.Rows(); i++ {
+			le.PutUint64(buf[:], math.Float64bits(A.GetAt(i, j)))
+			if _, err := bw.Write(buf[:]); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// Read float matrix in the binary matrix format from r.
+func ReadBinary(r io.Reader) (*matrix.FloatMatrix, error) {
+	hdr := make([]byte, binaryHeaderSize)
+	if _, err := io.ReadFull(r, hdr); err != nil {
+		return nil, binaryError("short header")
+	}
+	h, err := parseBinaryHeader(hdr)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.CopyN(io.Discard, r, int64(h.offset-binaryHeaderSize)); err != nil {
+		return nil, binaryError("short file")
+	}
+	data, err := readChunked(r, 8*h.rows*h.cols)
+	if err != nil {
+		return nil, err
+	}
+	return matrix.FloatNew(int(h.rows), int(h.cols), decodeFloats(data)), nil
+}
+
+// Parse and validate header.
+func parseBinaryHeader(hdr []byte) (*binaryHeader, error) {
+	le := binary.LittleEndian
+	if len(hdr) < binaryHeaderSize || !bytes.Equal(hdr[:8], []byte(binaryMagic)) {
+		return nil, binaryError("not a binary matrix file")
+	}
+	if v := le.Uint32(hdr[8:]); v != binaryVersion {
+		return nil, binaryError(fmt.Sprintf("unsupported version %d", v))
+	}
+	if le.Uint32(hdr[12:]) != binaryFloat64 {
+		return nil, linalg.NewError(linalg.ErrType, "matio: binary: unsupported element type")
+	}
+	h := &binaryHeader{le.Uint64(hdr[16:]), le.Uint64(hdr[24:]), le.Uint64(hdr[32:])}
+	if h.offset < binaryHeaderSize || h.offset%8 != 0 {
+		return nil, binaryError("invalid data offset")
+	}
+	// guard against overflow from corrupt input
+	if h.rows > math.MaxInt32 || h.cols > math.MaxInt32 ||
+		(h.rows != 0 && h.cols > (math.MaxInt64/8)/h.rows) {
+		return nil, linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("matio: binary: invalid size %d by %d", h.rows, h.cols))
+	}
+	if h.offset > math.MaxInt64-8*h.rows*h.cols {
+		return nil, binaryError("invalid data offset")
+	}
+	return h, nil
+}
+
+// Read n bytes from r. The buffer grows with the data read, so that a
+// corrupt size in a header fails at the end of input instead of
+// allocating the size up front.
+func readChunked(r io.Reader, n uint64) ([]byte, error) {
+	var data []byte
+	for uint64(len(data)) < n {
+		k := binaryChunk
+		if rest := n - uint64(len(data)); rest < binaryChunk {
+			k = int(rest)
+		}
+		data = append(data, make([]byte, k)...)
+		if _, err := io.ReadFull(r, data[len(data)-k:]); err != nil {
+			return nil, binaryError("short data")
+		}
+	}
+	return data, nil
+}
+
+// Return little-endian doubles in data as slice; data is used in place on
+// little-endian hosts when suitably aligned.
+func decodeFloats(data []byte) []float64 {
+	n := len(data) / 8
+	if n == 0 {
+		return []float64{}
+	}
+	if littleEndian && uintptr(unsafe.Pointer(&data[0]))%8 == 0 {
+		return unsafe.Slice((*float64)(unsafe.Pointer(&data[0])), n)
+	}
+	v := make([]float64, n)
+	for k := range v {
+		v[k] = math.Float64frombits(binary.LittleEndian.Uint64(data[8*k:]))
+	}
+	return v
+}
+
+var littleEndian = func() bool {
+	probe := uint16(1)
+	return *(*byte)(unsafe.Pointer(&probe)) == 1
+}()
+
+func binaryError(msg string) error {
+	return linalg.NewError(linalg.ErrParameter, "matio: binary: "+msg)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
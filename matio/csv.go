@@ -0,0 +1,241 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matio package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Return field delimiter from option delimiter.
+func delimiter(name string, opts ...linalg.Option) (rune, error) {
+	d := linalg.GetStringOpt("delimiter", ",", opts...)
+	if d == `\t` {
+		d = "\t"
+	}
+	c, n := utf8.DecodeRuneInString(d)
+	if n == 0 || n != len(d) || c == '"' || c == '\n' || c == '\r' {
+		return 0, linalg.NewError(linalg.ErrParameter, name+": invalid delimiter")
+	}
+	return c, nil
+}
+
+/*
+ Read delimited rows of numbers into a float matrix.
+
+ Rows are read one at a time so only the selected columns are kept in
+ memory. Lines starting with '#' are comments and are not counted by
+ option skip. Empty fields are read as
+ NaN. All rows must have the same number of fields. If option header is
+ set, the first row after skipped lines holds column names, which are
+ returned for the selected columns; otherwise the returned names are nil.
+
+ OPTIONS
+  delimiter string; field separator, eg. "\t" for TSV. Default ",".
+  skip      int; number of leading lines to ignore. Default 0.
+  header    bool; first row holds column names. Default false.
+  columns   string; comma separated zero-based column indexes or, with
+            header, column names to read in the given order. Default is
+            all columns.
+*/
+func ReadCSV(r io.Reader, opts ...linalg.Option) (*matrix.FloatMatrix, []string, error) {
+	rr, err := NewCSVRowReader(r, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	A, err := ReadAllRows(rr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return A, rr.Names(), nil
+}
+
+// Rows of delimited numbers read one at a time, see ReadCSV. Implements
+// RowReader.
+type CSVRowReader struct {
+	cr      *csv.Reader
+	sel     []int
+	names   []string
+	nfields int
+	pending []string
+}
+
+// Return reader of delimited rows from r. Leading lines and the header row
+// are read immediately so that Cols and Names are known. Options are as for
+// ReadCSV.
+func NewCSVRowReader(r io.Reader, opts ...linalg.Option) (*CSVRowReader, error) {
+	delim, err := delimiter("ReadCSV", opts...)
+	if err != nil {
+		return nil, err
+	}
+	skip := linalg.GetIntOpt("skip", 0, opts...)
+	header := linalg.GetBoolOpt("header", false, opts...)
+	columns := linalg.GetStringOpt("columns", "", opts...)
+
+	R := &CSVRowReader{cr: csv.NewReader(r)}
+	R.cr.Comma = delim
+	R.cr.Comment = '#'
+	R.cr.TrimLeadingSpace = !unicode.IsSpace(delim)
+	R.cr.ReuseRecord = true
+	R.cr.FieldsPerRecord = -1
+	for line := 1; ; line++ {
+		rec, err := R.cr.Read()
+		if err == io.EOF {
+			R.sel = []int{}
+			return R, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line <= skip {
+			continue
+		}
+		R.nfields = len(rec)
+		if header {
+			R.names = append([]string(nil), rec...)
+		}
+		if R.sel, err = selectColumns(columns, len(rec), R.names); err != nil {
+			return nil, err
+		}
+		if header {
+			R.names = pick(R.names, R.sel)
+		} else {
+			R.pending = append([]string(nil), rec...)
+		}
+		return R, nil
+	}
+}
+
+// Return names of selected columns from the header row or nil.
+func (R *CSVRowReader) Names() []string {
+	return R.names
+}
+
+// Return number of selected columns.
+func (R *CSVRowReader) Cols() int {
+	return len(R.sel)
+}
+
+// Read next row into row[:Cols()]. Empty fields are read as NaN. Returns
+// io.EOF after the last row.
+func (R *CSVRowReader) ReadRow(row []float64) error {
+	rec := R.pending
+	R.pending = nil
+	if rec == nil {
+		var err error
+		if rec, err = R.cr.Read(); err != nil {
+			return err
+		}
+	}
+	if len(rec) != R.nfields {
+		pos, _ := R.cr.FieldPos(0)
+		return linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("ReadCSV: line %d: %d fields, expected %d", pos, len(rec), R.nfields))
+	}
+	for k, j := range R.sel {
+		s := strings.TrimSpace(rec[j])
+		v := math.NaN()
+		if s != "" {
+			var err error
+			if v, err = strconv.ParseFloat(s, 64); err != nil {
+				pos, _ := R.cr.FieldPos(j)
+				return linalg.NewError(linalg.ErrParameter, fmt.Sprintf("ReadCSV: line %d: %v", pos, err))
+			}
+		}
+		row[k] = v
+	}
+	return nil
+}
+
+// Return indexes of selected columns out of n.
+func selectColumns(columns string, n int, names []string) ([]int, error) {
+	sel := make([]int, 0, n)
+	if columns == "" {
+		for j := 0; j < n; j++ {
+			sel = append(sel, j)
+		}
+		return sel, nil
+	}
+	for _, c := range strings.Split(columns, ",") {
+		c = strings.TrimSpace(c)
+		j, err := strconv.Atoi(c)
+		if err != nil {
+			j = -1
+			for k, name := range names {
+				if strings.TrimSpace(name) == c {
+					j = k
+					break
+				}
+			}
+		}
+		if j < 0 || j >= n {
+			return nil, linalg.NewError(linalg.ErrParameter, fmt.Sprintf("ReadCSV: no column %q", c))
+		}
+		sel = append(sel, j)
+	}
+	return sel, nil
+}
+
+func pick(s []string, idx []int) []string {
+	r := make([]string, len(idx))
+	for k, j := range idx {
+		r[k] = s[j]
+	}
+	return r
+}
+
+/*
+ Write rows of A as delimited numbers, preceded by a row of column names
+ if names is not nil. Numbers are written with the shortest
+ representation that reads back exactly.
+
+ OPTIONS
+  delimiter string; field separator, eg. "\t" for TSV. Default ",".
+  columns   string; comma separated zero-based indexes of columns to write
+            in the given order. Default is all columns.
+*/
+func WriteCSV(w io.Writer, A *matrix.FloatMatrix, names []string, opts ...linalg.Option) error {
+	delim, err := delimiter("WriteCSV", opts...)
+	if err != nil {
+		return err
+	}
+	sel, err := selectColumns(linalg.GetStringOpt("columns", "", opts...), A.Cols(), nil)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = delim
+	if names != nil {
+		if len(names) != A.Cols() {
+			return linalg.NewError(linalg.ErrShape, "WriteCSV: number of names and columns differ")
+		}
+		cw.Write(pick(names, sel))
+	}
+	rec := make([]string, len(sel))
+	for i := 0; i < A.Rows(); i++ {
+		for k, j := range sel {
+			rec[k] = strconv.FormatFloat(A.GetAt(i, j), 'g', -1, 64)
+		}
+		if err = cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,49 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matio package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+/*
+Package matio reads and writes matrices in common interchange formats.
+
+ReadMM and WriteMM handle MatrixMarket (.mtx) files in both array and
+coordinate layout, so that test matrices from standard collections such
+as SuiteSparse and the NIST Matrix Market can be loaded directly. Matrices
+are returned dense; symmetric, skew-symmetric and Hermitian storage is
+expanded to the full matrix.
+
+ReadMAT and WriteMAT handle MATLAB level 5 MAT-files, the default format
+of MATLAB versions 5 to 7.2, with variables keyed by name.
+
+ReadCSV and WriteCSV stream delimited text such as CSV and TSV files, with
+options to skip leading lines, read a header row and select columns.
+
+JSONMatrix wraps a float or complex matrix to give it a JSON encoding with
+dimensions and column-major data, for web APIs and configuration files.
+GobMatrix does the same for encoding/gob and net/rpc with an exact binary
+encoding.
+MarshalProto and UnmarshalProto encode message Matrix of matrix.proto for
+gRPC services, without depending on the protobuf runtime.
+
+WriteBinary and ReadBinary use a simple binary format of a fixed header
+followed by the raw column-major data. OpenMapped maps such a file to
+memory as a read-only matrix for out-of-core work on matrices larger than
+the available RAM.
+
+A RowReader streams rows of a data set one at a time; CSVRowReader,
+BinaryRowReader and MatrixRowReader read delimited text, binary matrix
+files and in-memory matrices. ReadRows reads the next chunk of rows into
+a matrix and Builder collects rows into a matrix incrementally. Streamed
+rows can be fed to single-pass algorithms such as matops.Covariance.
+
+	A, err := matio.ReadMM(f)
+	err = matio.WriteMM(os.Stdout, A, linalg.BoolOpt("coordinate", true))
+	vars, err := matio.ReadMAT(f)
+	M, err := matio.OpenMapped("big.bin")
+*/
+package matio
+
+// Local Variables:
+// tab-width: 4
+// End:
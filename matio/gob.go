@@ -0,0 +1,124 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matio package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matio
+
+import (
+	"encoding/binary"
+	"encoding/gob"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+func init() {
+	// allow GobMatrix values in interface typed fields
+	gob.Register(GobMatrix{})
+}
+
+// Float or complex matrix implementing gob.GobEncoder and gob.GobDecoder,
+// for sending matrices over net/rpc or caching them with encoding/gob.
+// Elements are stored in column-major order as little-endian IEEE 754
+// values, so encoding is exact and fast.
+//
+//	err := gob.NewEncoder(w).Encode(matio.GobMatrix{A})
+type GobMatrix struct {
+	matrix.Matrix
+}
+
+// Encoding version and element types.
+const (
+	gobVersion = 1
+	gobFloat   = 0
+	gobComplex = 1
+)
+
+func (M GobMatrix) GobEncode() ([]byte, error) {
+	if M.Matrix == nil {
+		return nil, linalg.NewError(linalg.ErrParameter, "GobMatrix: nil matrix")
+	}
+	rows, cols := M.Matrix.Rows(), M.Matrix.Cols()
+	buf := make([]byte, 2, 2+2*binary.MaxVarintLen64+16*rows*cols)
+	buf[0] = gobVersion
+	buf = binary.AppendUvarint(buf, uint64(rows))
+	buf = binary.AppendUvarint(buf, uint64(cols))
+	le := binary.LittleEndian
+	switch A := M.Matrix.(type) {
+	case *matrix.FloatMatrix:
+		buf[1] = gobFloat
+		for j := 0; j < cols; j++ {
+			for i := 0; i < rows; i++ {
+				buf = le.AppendUint64(buf, math.Float64bits(A.GetAt(i, j)))
+			}
+		}
+	case *matrix.ComplexMatrix:
+		buf[1] = gobComplex
+		for j := 0; j < cols; j++ {
+			for i := 0; i < rows; i++ {
+				z := A.GetAt(i, j)
+				buf = le.AppendUint64(buf, math.Float64bits(real(z)))
+				buf = le.AppendUint64(buf, math.Float64bits(imag(z)))
+			}
+		}
+	default:
+		return nil, linalg.NewError(linalg.ErrType, "GobMatrix: unknown matrix type")
+	}
+	return buf, nil
+}
+
+func (M *GobMatrix) GobDecode(b []byte) error {
+	if len(b) < 2 || b[0] != gobVersion {
+		return linalg.NewError(linalg.ErrParameter, "GobMatrix: unknown encoding")
+	}
+	typ := b[1]
+	b = b[2:]
+	rows, n := binary.Uvarint(b)
+	if n <= 0 {
+		return linalg.NewError(linalg.ErrParameter, "GobMatrix: invalid rows")
+	}
+	b = b[n:]
+	cols, n := binary.Uvarint(b)
+	if n <= 0 {
+		return linalg.NewError(linalg.ErrParameter, "GobMatrix: invalid cols")
+	}
+	b = b[n:]
+	size := 8
+	if typ == gobComplex {
+		size = 16
+	}
+	// compare in uint64 to guard against overflow from corrupt input
+	if rows != 0 && (cols > uint64(len(b))/rows || rows*cols*uint64(size) != uint64(len(b))) {
+		return linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("GobMatrix: %d bytes for %d by %d matrix", len(b), rows, cols))
+	}
+	if rows == 0 && len(b) != 0 {
+		return linalg.NewError(linalg.ErrShape, "GobMatrix: data for empty matrix")
+	}
+	le := binary.LittleEndian
+	switch typ {
+	case gobFloat:
+		v := make([]float64, rows*cols)
+		for k := range v {
+			v[k] = math.Float64frombits(le.Uint64(b[8*k:]))
+		}
+		M.Matrix = matrix.FloatNew(int(rows), int(cols), v)
+	case gobComplex:
+		v := make([]complex128, rows*cols)
+		for k := range v {
+			v[k] = complex(math.Float64frombits(le.Uint64(b[16*k:])),
+				math.Float64frombits(le.Uint64(b[16*k+8:])))
+		}
+		M.Matrix = matrix.ComplexNew(int(rows), int(cols), v)
+	default:
+		return linalg.NewError(linalg.ErrType, "GobMatrix: unknown element type")
+	}
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
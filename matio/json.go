@@ -0,0 +1,152 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matio package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matio
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"strconv"
+)
+
+// Float or complex matrix with JSON encoding
+//
+//	{"type": "float", "rows": 2, "cols": 2, "data": [1, 2, 3, 4]}
+//	{"type": "complex", "rows": 1, "cols": 1, "data": [[1, -2]]}
+//
+// Data is in column-major order; complex elements are [real, imag] pairs.
+// NaN and infinities, which JSON numbers cannot represent, are written as
+// the strings "NaN", "+Inf" and "-Inf". When decoding, type defaults to
+// "float". Use as a field in a struct or wrap a matrix to marshal it:
+//
+//	b, err := json.Marshal(matio.JSONMatrix{A})
+type JSONMatrix struct {
+	matrix.Matrix
+}
+
+type jsonMatrix struct {
+	Type string            `json:"type"`
+	Rows int               `json:"rows"`
+	Cols int               `json:"cols"`
+	Data []json.RawMessage `json:"data"`
+}
+
+// Float that encodes NaN and infinities as strings.
+type jsonFloat float64
+
+func (f jsonFloat) MarshalJSON() ([]byte, error) {
+	v := float64(f)
+	switch {
+	case math.IsNaN(v):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(v, 1):
+		return []byte(`"+Inf"`), nil
+	case math.IsInf(v, -1):
+		return []byte(`"-Inf"`), nil
+	}
+	return strconv.AppendFloat(nil, v, 'g', -1, 64), nil
+}
+
+func (f *jsonFloat) UnmarshalJSON(b []byte) error {
+	var s string
+	if len(b) > 0 && b[0] == '"' {
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		switch s {
+		case "NaN", "+Inf", "-Inf", "Inf":
+			v, _ := strconv.ParseFloat(s, 64)
+			*f = jsonFloat(v)
+			return nil
+		}
+		return linalg.NewError(linalg.ErrParameter, fmt.Sprintf("invalid number %q", s))
+	}
+	var v float64
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	*f = jsonFloat(v)
+	return nil
+}
+
+func (M JSONMatrix) MarshalJSON() ([]byte, error) {
+	var jm jsonMatrix
+	switch A := M.Matrix.(type) {
+	case *matrix.FloatMatrix:
+		jm.Type = "float"
+		for j := 0; j < A.Cols(); j++ {
+			for i := 0; i < A.Rows(); i++ {
+				b, _ := jsonFloat(A.GetAt(i, j)).MarshalJSON()
+				jm.Data = append(jm.Data, b)
+			}
+		}
+	case *matrix.ComplexMatrix:
+		jm.Type = "complex"
+		for j := 0; j < A.Cols(); j++ {
+			for i := 0; i < A.Rows(); i++ {
+				z := A.GetAt(i, j)
+				b, _ := json.Marshal([2]jsonFloat{jsonFloat(real(z)), jsonFloat(imag(z))})
+				jm.Data = append(jm.Data, b)
+			}
+		}
+	case nil:
+		return []byte("null"), nil
+	default:
+		return nil, linalg.NewError(linalg.ErrType, "JSONMatrix: unknown matrix type")
+	}
+	jm.Rows, jm.Cols = M.Matrix.Rows(), M.Matrix.Cols()
+	if jm.Data == nil {
+		jm.Data = []json.RawMessage{}
+	}
+	return json.Marshal(jm)
+}
+
+func (M *JSONMatrix) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		M.Matrix = nil
+		return nil
+	}
+	var jm jsonMatrix
+	if err := json.Unmarshal(b, &jm); err != nil {
+		return err
+	}
+	if jm.Rows < 0 || jm.Cols < 0 || len(jm.Data) != jm.Rows*jm.Cols {
+		return linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("JSONMatrix: %d elements for %d by %d matrix", len(jm.Data), jm.Rows, jm.Cols))
+	}
+	switch jm.Type {
+	case "float", "":
+		v := make([]float64, len(jm.Data))
+		for k, d := range jm.Data {
+			var f jsonFloat
+			if err := f.UnmarshalJSON(d); err != nil {
+				return linalg.NewError(linalg.ErrParameter, fmt.Sprintf("JSONMatrix: element %d: %v", k, err))
+			}
+			v[k] = float64(f)
+		}
+		M.Matrix = matrix.FloatNew(jm.Rows, jm.Cols, v)
+	case "complex":
+		v := make([]complex128, len(jm.Data))
+		for k, d := range jm.Data {
+			var z [2]jsonFloat
+			if err := json.Unmarshal(d, &z); err != nil {
+				return linalg.NewError(linalg.ErrParameter, fmt.Sprintf("JSONMatrix: element %d: %v", k, err))
+			}
+			v[k] = complex(float64(z[0]), float64(z[1]))
+		}
+		M.Matrix = matrix.ComplexNew(jm.Rows, jm.Cols, v)
+	default:
+		return linalg.NewError(linalg.ErrType, fmt.Sprintf("JSONMatrix: unknown type %q", jm.Type))
+	}
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
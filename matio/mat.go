@@ -0,0 +1,356 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matio package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matio
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"io"
+	"io/ioutil"
+	"math"
+	"sort"
+)
+
+// MAT-file level 5 data types.
+const (
+	miINT8       = 1
+	miUINT8      = 2
+	miINT16      = 3
+	miUINT16     = 4
+	miINT32      = 5
+	miUINT32     = 6
+	miSINGLE     = 7
+	miDOUBLE     = 9
+	miINT64      = 12
+	miUINT64     = 13
+	miMATRIX     = 14
+	miCOMPRESSED = 15
+)
+
+// MAT-file array classes holding numeric data.
+const (
+	mxDOUBLE_CLASS = 6
+	mxUINT64_CLASS = 15
+	mxCOMPLEX_FLAG = 0x0800
+)
+
+func matError(format string, args ...interface{}) error {
+	return linalg.NewError(linalg.ErrParameter, "MAT-file: "+fmt.Sprintf(format, args...))
+}
+
+/*
+ Read all numeric two-dimensional variables from a MATLAB level 5 MAT-file.
+
+ Variables of class double, single and the integer classes are returned as
+ *matrix.FloatMatrix, or *matrix.ComplexMatrix if complex, keyed by
+ variable name. Compressed variables (MATLAB 7 default) and both byte
+ orders are supported. Other classes, such as cell arrays, structures,
+ character arrays and sparse matrices, and arrays with more than two
+ dimensions are skipped.
+*/
+func ReadMAT(r io.Reader) (map[string]matrix.Matrix, error) {
+	var hdr [128]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, matError("reading header: %v", err)
+	}
+	var order binary.ByteOrder
+	switch string(hdr[126:128]) {
+	case "IM":
+		order = binary.LittleEndian
+	case "MI":
+		order = binary.BigEndian
+	default:
+		return nil, matError("not a level 5 MAT-file")
+	}
+	vars := make(map[string]matrix.Matrix)
+	for {
+		typ, data, err := readElement(r, order)
+		if err == io.EOF {
+			return vars, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if typ == miCOMPRESSED {
+			zr, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, matError("compressed variable: %v", err)
+			}
+			typ, data, err = readElement(zr, order)
+			zr.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if typ != miMATRIX {
+			continue
+		}
+		name, A, err := readArray(data, order)
+		if err != nil {
+			return nil, err
+		}
+		if A != nil {
+			vars[name] = A
+		}
+	}
+}
+
+// Read one data element, returning its type and data without padding.
+// Returns io.EOF only if there are no more elements.
+func readElement(r io.Reader, order binary.ByteOrder) (typ uint32, data []byte, err error) {
+	var tag [8]byte
+	if _, err = io.ReadFull(r, tag[:]); err != nil {
+		if err != io.EOF {
+			err = matError("reading tag: %v", err)
+		}
+		return
+	}
+	typ = order.Uint32(tag[0:4])
+	if typ>>16 != 0 {
+		// small data element: size in upper half, data in the tag
+		n := typ >> 16
+		if n > 4 {
+			return 0, nil, matError("invalid small data element")
+		}
+		return typ & 0xffff, tag[4 : 4+n], nil
+	}
+	n := order.Uint32(tag[4:8])
+	data, err = ioutil.ReadAll(io.LimitReader(r, int64(n)))
+	if err == nil && uint32(len(data)) != n {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return 0, nil, matError("reading data: %v", err)
+	}
+	if typ != miCOMPRESSED && n%8 != 0 {
+		// skip padding to 8 byte boundary; may be missing at end of file
+		io.ReadFull(r, make([]byte, 8-n%8))
+	}
+	return
+}
+
+// Parse body of miMATRIX element. Returns nil matrix for unsupported arrays.
+func readArray(data []byte, order binary.ByteOrder) (string, matrix.Matrix, error) {
+	r := bytes.NewReader(data)
+	typ, flags, err := readElement(r, order)
+	if err != nil || typ != miUINT32 || len(flags) < 8 {
+		return "", nil, matError("invalid array flags")
+	}
+	class := order.Uint32(flags[0:4]) & 0xff
+	cplx := order.Uint32(flags[0:4])&mxCOMPLEX_FLAG != 0
+	if class < mxDOUBLE_CLASS || class > mxUINT64_CLASS {
+		return "", nil, nil
+	}
+	typ, dims, err := readElement(r, order)
+	if err != nil || typ != miINT32 {
+		return "", nil, matError("invalid array dimensions")
+	}
+	if len(dims) != 8 {
+		// not two-dimensional
+		return "", nil, nil
+	}
+	rows := int(int32(order.Uint32(dims[0:4])))
+	cols := int(int32(order.Uint32(dims[4:8])))
+	typ, bname, err := readElement(r, order)
+	if err != nil || typ != miINT8 {
+		return "", nil, matError("invalid array name")
+	}
+	name := string(bname)
+	parts := 1
+	if cplx {
+		parts = 2
+	}
+	var values [2][]float64
+	for k := 0; k < parts; k++ {
+		typ, d, err := readElement(r, order)
+		if err != nil {
+			return "", nil, matError("variable %s: missing data", name)
+		}
+		if values[k], err = numbers(typ, d, order); err != nil {
+			return "", nil, err
+		}
+		if len(values[k]) != rows*cols {
+			return "", nil, matError("variable %s: %d elements for %d by %d array",
+				name, len(values[k]), rows, cols)
+		}
+	}
+	if !cplx {
+		return name, matrix.FloatNew(rows, cols, values[0]), nil
+	}
+	z := make([]complex128, rows*cols)
+	for k := range z {
+		z[k] = complex(values[0][k], values[1][k])
+	}
+	return name, matrix.ComplexNew(rows, cols, z), nil
+}
+
+// Decode numeric data element as float64 values.
+func numbers(typ uint32, d []byte, order binary.ByteOrder) ([]float64, error) {
+	var size int
+	switch typ {
+	case miINT8, miUINT8:
+		size = 1
+	case miINT16, miUINT16:
+		size = 2
+	case miINT32, miUINT32, miSINGLE:
+		size = 4
+	case miDOUBLE, miINT64, miUINT64:
+		size = 8
+	default:
+		return nil, matError("unsupported data type %d", typ)
+	}
+	v := make([]float64, len(d)/size)
+	for k := range v {
+		b := d[k*size : (k+1)*size]
+		switch typ {
+		case miINT8:
+			v[k] = float64(int8(b[0]))
+		case miUINT8:
+			v[k] = float64(b[0])
+		case miINT16:
+			v[k] = float64(int16(order.Uint16(b)))
+		case miUINT16:
+			v[k] = float64(order.Uint16(b))
+		case miINT32:
+			v[k] = float64(int32(order.Uint32(b)))
+		case miUINT32:
+			v[k] = float64(order.Uint32(b))
+		case miSINGLE:
+			v[k] = float64(math.Float32frombits(order.Uint32(b)))
+		case miDOUBLE:
+			v[k] = math.Float64frombits(order.Uint64(b))
+		case miINT64:
+			v[k] = float64(int64(order.Uint64(b)))
+		case miUINT64:
+			v[k] = float64(order.Uint64(b))
+		}
+	}
+	return v, nil
+}
+
+// Test if name is a valid MATLAB variable name.
+func validName(name string) bool {
+	if len(name) == 0 || len(name) > 63 {
+		return false
+	}
+	for k, c := range name {
+		letter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		if !letter && (k == 0 || !(c == '_' || (c >= '0' && c <= '9'))) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+ Write float and complex matrices as double arrays to a MATLAB level 5
+ MAT-file in little-endian byte order.
+
+ Variables are written in name order. Names must be valid MATLAB
+ identifiers.
+
+ OPTIONS
+  compress  bool; compress variables with zlib as MATLAB 7 does.
+            Default false.
+*/
+func WriteMAT(w io.Writer, vars map[string]matrix.Matrix, opts ...linalg.Option) error {
+	compress := linalg.GetBoolOpt("compress", false, opts...)
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		if !validName(name) {
+			return matError("invalid variable name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var hdr [128]byte
+	for k := range hdr[:116] {
+		hdr[k] = ' '
+	}
+	copy(hdr[:], "MATLAB 5.0 MAT-file, written by github.com/nvcook42/linalg/matio")
+	binary.LittleEndian.PutUint16(hdr[124:], 0x0100)
+	copy(hdr[126:], "IM")
+	if _, err := w.Write(hdr[:]); err != nil {
+		return err
+	}
+	for _, name := range names {
+		var buf bytes.Buffer
+		if err := writeArray(&buf, name, vars[name]); err != nil {
+			return err
+		}
+		data := buf.Bytes()
+		if compress {
+			var zbuf bytes.Buffer
+			zw := zlib.NewWriter(&zbuf)
+			zw.Write(data)
+			zw.Close()
+			var tag [8]byte
+			binary.LittleEndian.PutUint32(tag[0:], miCOMPRESSED)
+			binary.LittleEndian.PutUint32(tag[4:], uint32(zbuf.Len()))
+			data = append(tag[:], zbuf.Bytes()...)
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write miMATRIX element with tag.
+func writeArray(buf *bytes.Buffer, name string, A matrix.Matrix) error {
+	var re, im []float64
+	flags := uint32(mxDOUBLE_CLASS)
+	switch A := A.(type) {
+	case *matrix.FloatMatrix:
+		re = make([]float64, 0, A.NumElements())
+		for j := 0; j < A.Cols(); j++ {
+			for i := 0; i < A.Rows(); i++ {
+				re = append(re, A.GetAt(i, j))
+			}
+		}
+	case *matrix.ComplexMatrix:
+		flags |= mxCOMPLEX_FLAG
+		re = make([]float64, 0, A.NumElements())
+		im = make([]float64, 0, A.NumElements())
+		for j := 0; j < A.Cols(); j++ {
+			for i := 0; i < A.Rows(); i++ {
+				re = append(re, real(A.GetAt(i, j)))
+				im = append(im, imag(A.GetAt(i, j)))
+			}
+		}
+	default:
+		return linalg.NewError(linalg.ErrType, "WriteMAT: unknown matrix type")
+	}
+	var body bytes.Buffer
+	le := binary.LittleEndian
+	element := func(typ uint32, data interface{}) {
+		var d bytes.Buffer
+		binary.Write(&d, le, data)
+		binary.Write(&body, le, [2]uint32{typ, uint32(d.Len())})
+		body.Write(d.Bytes())
+		body.Write(make([]byte, (8-d.Len()%8)%8))
+	}
+	element(miUINT32, [2]uint32{flags, 0})
+	element(miINT32, [2]int32{int32(A.Rows()), int32(A.Cols())})
+	element(miINT8, []byte(name))
+	element(miDOUBLE, re)
+	if im != nil {
+		element(miDOUBLE, im)
+	}
+	binary.Write(buf, le, [2]uint32{miMATRIX, uint32(body.Len())})
+	buf.Write(body.Bytes())
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,399 @@
+package matio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"io"
+	"math"
+	"math/cmplx"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadMM(t *testing.T) {
+	src := `%%MatrixMarket matrix coordinate real symmetric
+% comment
+3 3 3
+1 1 2.0
+3 1 -1.0
+2 2 4
+`
+	A, err := ReadMM(strings.NewReader(src))
+	F, ok := A.(*matrix.FloatMatrix)
+	if err != nil || !ok {
+		t.Logf("ReadMM: %v\n", err)
+		t.FailNow()
+	}
+	if F.GetAt(0, 2) != -1.0 || F.GetAt(2, 0) != -1.0 || F.GetAt(1, 1) != 4.0 {
+		t.Logf("symmetric coordinate:\n%v\n", F)
+		t.Fail()
+	}
+	src = `%%MatrixMarket matrix array complex hermitian
+2 2
+1 0
+2 3
+5 0
+`
+	A, err = ReadMM(strings.NewReader(src))
+	C, ok := A.(*matrix.ComplexMatrix)
+	if err != nil || !ok || C.GetAt(1, 0) != complex(2, 3) || C.GetAt(0, 1) != complex(2, -3) {
+		t.Logf("hermitian array: %v\n%v\n", err, A)
+		t.Fail()
+	}
+	src = `%%MatrixMarket matrix coordinate pattern general
+2 3 2
+1 3
+2 1
+`
+	A, err = ReadMM(strings.NewReader(src))
+	if err != nil || A.(*matrix.FloatMatrix).GetAt(0, 2) != 1.0 || A.(*matrix.FloatMatrix).GetAt(1, 1) != 0.0 {
+		t.Logf("pattern: %v\n%v\n", err, A)
+		t.Fail()
+	}
+	if _, err = ReadMM(strings.NewReader("%%MatrixMarket matrix array real general\n2 2\n1 2 3\n")); err == nil {
+		t.Logf("short array accepted\n")
+		t.Fail()
+	}
+	// sizes of large sparse files must not be allocated blindly
+	huge := "%%MatrixMarket matrix coordinate real general\n4000000000 4000000000 1\n1 1 1\n"
+	if _, err = ReadMM(strings.NewReader(huge)); !errors.Is(err, linalg.ErrShape) {
+		t.Logf("huge coordinate matrix: %v\n", err)
+		t.Fail()
+	}
+	old := linalg.SetMemoryBudget(1 << 20)
+	defer linalg.SetMemoryBudget(old)
+	big := "%%MatrixMarket matrix coordinate real general\n1000 1000 1\n1 1 1\n"
+	if _, err = ReadMM(strings.NewReader(big)); !errors.Is(err, linalg.ErrMemoryBudget) {
+		t.Logf("matrix over budget: %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestWriteMM(t *testing.T) {
+	A := matrix.FloatNew(2, 3, []float64{1, 0, 0, 4, 5, 0.125})
+	for _, coord := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := WriteMM(&buf, A, linalg.BoolOpt("coordinate", coord)); err != nil {
+			t.Logf("WriteMM: %v\n", err)
+			t.FailNow()
+		}
+		B, err := ReadMM(&buf)
+		if err != nil || !A.Equal(B.(*matrix.FloatMatrix)) {
+			t.Logf("coordinate=%v round trip: %v\n%v\n", coord, err, B)
+			t.Fail()
+		}
+	}
+	C := matrix.ComplexNew(2, 1, []complex128{1 + 2i, 0})
+	var buf bytes.Buffer
+	WriteMM(&buf, C, linalg.BoolOpt("coordinate", true))
+	if !strings.Contains(buf.String(), "coordinate complex general\n2 1 1\n1 1 1 2\n") {
+		t.Logf("complex coordinate:\n%s\n", buf.String())
+		t.Fail()
+	}
+}
+
+func TestMAT(t *testing.T) {
+	vars := map[string]matrix.Matrix{
+		"A":   matrix.FloatNew(2, 3, []float64{1, 2, 3, 4, 5, 0.125}),
+		"z_1": matrix.ComplexNew(2, 1, []complex128{1 + 2i, -3i}),
+		"e":   matrix.FloatZeros(0, 0),
+	}
+	for _, compress := range []bool{false, true} {
+		var buf bytes.Buffer
+		if err := WriteMAT(&buf, vars, linalg.BoolOpt("compress", compress)); err != nil {
+			t.Fatalf("WriteMAT: %v\n", err)
+		}
+		if !compress && buf.Len()%8 != 0 {
+			t.Logf("file length %d not aligned\n", buf.Len())
+			t.Fail()
+		}
+		got, err := ReadMAT(&buf)
+		if err != nil || len(got) != 3 {
+			t.Fatalf("ReadMAT: %v %v\n", err, got)
+		}
+		if A, ok := got["A"].(*matrix.FloatMatrix); !ok || !A.Equal(vars["A"].(*matrix.FloatMatrix)) {
+			t.Logf("compress=%v A: %v\n", compress, got["A"])
+			t.Fail()
+		}
+		z, ok := got["z_1"].(*matrix.ComplexMatrix)
+		if !ok || z.GetAt(0, 0) != 1+2i || z.GetAt(1, 0) != -3i {
+			t.Logf("compress=%v z_1: %v\n", compress, got["z_1"])
+			t.Fail()
+		}
+	}
+	if err := WriteMAT(&bytes.Buffer{}, map[string]matrix.Matrix{"1x": vars["A"]}); err == nil {
+		t.Logf("invalid name accepted\n")
+		t.Fail()
+	}
+}
+
+func TestCSV(t *testing.T) {
+	src := `generated by test
+# comment
+a	b	c
+1	2	3
+4		6
+`
+	A, names, err := ReadCSV(strings.NewReader(src), linalg.StringOpt("delimiter", "\t"),
+		linalg.IntOpt("skip", 1), linalg.BoolOpt("header", true), linalg.StringOpt("columns", "c,0"))
+	if err != nil || A.Rows() != 2 || A.Cols() != 2 || A.GetAt(1, 0) != 6 || A.GetAt(0, 1) != 1 {
+		t.Fatalf("ReadCSV: %v\n%v\n", err, A)
+	}
+	if len(names) != 2 || names[0] != "c" || names[1] != "a" {
+		t.Logf("names: %v\n", names)
+		t.Fail()
+	}
+	B, _, err := ReadCSV(strings.NewReader(src), linalg.StringOpt("delimiter", `\t`),
+		linalg.IntOpt("skip", 2))
+	if err != nil || B.Rows() != 2 || B.GetAt(1, 1) == B.GetAt(1, 1) {
+		t.Logf("empty field not NaN: %v\n%v\n", err, B)
+		t.Fail()
+	}
+	var buf bytes.Buffer
+	if err = WriteCSV(&buf, A, names, linalg.StringOpt("columns", "1")); err != nil {
+		t.Fatalf("WriteCSV: %v\n", err)
+	}
+	if buf.String() != "a\n1\n4\n" {
+		t.Logf("WriteCSV: %q\n", buf.String())
+		t.Fail()
+	}
+	if _, _, err = ReadCSV(strings.NewReader("1,2\n3\n")); err == nil {
+		t.Logf("ragged rows accepted\n")
+		t.Fail()
+	}
+}
+
+func TestJSON(t *testing.T) {
+	A := matrix.FloatNew(2, 2, []float64{1, 2.5, math.Inf(-1), 4})
+	b, err := json.Marshal(JSONMatrix{A})
+	if err != nil || string(b) != `{"type":"float","rows":2,"cols":2,"data":[1,2.5,"-Inf",4]}` {
+		t.Fatalf("Marshal: %v %s\n", err, b)
+	}
+	var M JSONMatrix
+	if err = json.Unmarshal(b, &M); err != nil || !A.Equal(M.Matrix.(*matrix.FloatMatrix)) {
+		t.Logf("Unmarshal: %v %v\n", err, M.Matrix)
+		t.Fail()
+	}
+	var cfg struct {
+		Z JSONMatrix `json:"z"`
+	}
+	err = json.Unmarshal([]byte(`{"z": {"type": "complex", "rows": 1, "cols": 2, "data": [[1, -2], [0, "NaN"]]}}`), &cfg)
+	z, ok := cfg.Z.Matrix.(*matrix.ComplexMatrix)
+	if err != nil || !ok || z.GetAt(0, 0) != 1-2i || !cmplx.IsNaN(z.GetAt(0, 1)) {
+		t.Logf("complex: %v %v\n", err, cfg.Z.Matrix)
+		t.Fail()
+	}
+	if err = json.Unmarshal([]byte(`{"rows": 2, "cols": 2, "data": [1]}`), &M); err == nil {
+		t.Logf("short data accepted\n")
+		t.Fail()
+	}
+	for _, src := range []string{
+		`{"rows": 1, "cols": 2, "data": [1, "one"]}`,
+		`{"type": "complex", "rows": 1, "cols": 1, "data": [[1, "i"]]}`,
+	} {
+		if err = json.Unmarshal([]byte(src), &M); !errors.Is(err, linalg.ErrParameter) {
+			t.Logf("%s: %v\n", src, err)
+			t.Fail()
+		}
+	}
+}
+
+func TestGob(t *testing.T) {
+	type cache struct {
+		Name string
+		A    GobMatrix
+		Any  interface{}
+	}
+	in := cache{
+		Name: "x",
+		A:    GobMatrix{matrix.FloatNew(2, 3, []float64{1, 2, 3, 4, math.NaN(), 6})},
+		Any:  GobMatrix{matrix.ComplexNew(1, 2, []complex128{1 + 2i, -1i})},
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(in); err != nil {
+		t.Fatalf("Encode: %v\n", err)
+	}
+	var out cache
+	if err := gob.NewDecoder(&buf).Decode(&out); err != nil {
+		t.Fatalf("Decode: %v\n", err)
+	}
+	A := out.A.Matrix.(*matrix.FloatMatrix)
+	if A.Rows() != 2 || A.Cols() != 3 || A.GetAt(1, 2) != 6 || !math.IsNaN(A.GetAt(0, 2)) {
+		t.Logf("float: %v\n", A)
+		t.Fail()
+	}
+	Z, ok := out.Any.(GobMatrix).Matrix.(*matrix.ComplexMatrix)
+	if !ok || Z.GetAt(0, 0) != 1+2i || Z.GetAt(0, 1) != -1i {
+		t.Logf("complex: %v\n", out.Any)
+		t.Fail()
+	}
+	var M GobMatrix
+	if err := M.GobDecode([]byte{gobVersion, gobFloat, 2, 2, 0}); err == nil {
+		t.Logf("short data accepted\n")
+		t.Fail()
+	}
+}
+
+func TestBinary(t *testing.T) {
+	A := matrix.FloatNew(3, 2, []float64{1, 2, 3, 4, 5, math.Inf(-1)})
+	path := filepath.Join(t.TempDir(), "a.bin")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create: %v\n", err)
+	}
+	if err = WriteBinary(f, A); err != nil {
+		t.Fatalf("WriteBinary: %v\n", err)
+	}
+	f.Close()
+	M, err := OpenMapped(path)
+	if err != nil {
+		t.Fatalf("OpenMapped: %v\n", err)
+	}
+	defer M.Close()
+	if M.Matrix.Rows() != 3 || M.Matrix.Cols() != 2 || M.Matrix.FloatAt(2, 1) != math.Inf(-1) || M.Matrix.FloatAt(1, 0) != 2 {
+		t.Logf("mapped:\n%v\n", M.Matrix)
+		t.Fail()
+	}
+	b, _ := os.ReadFile(path)
+	B, err := ReadBinary(bytes.NewReader(b))
+	if err != nil || !B.Equal(A) {
+		t.Logf("ReadBinary: %v, %v\n", B, err)
+		t.Fail()
+	}
+	if _, err = ReadBinary(bytes.NewReader(b[:70])); err == nil {
+		t.Logf("truncated file accepted\n")
+		t.Fail()
+	}
+}
+
+func TestProto(t *testing.T) {
+	A := matrix.FloatNew(2, 3, []float64{1, 2, 3, 4, math.NaN(), 6})
+	b, err := MarshalProto(A)
+	if err != nil {
+		t.Fatalf("MarshalProto: %v\n", err)
+	}
+	M, err := UnmarshalProto(b)
+	if err != nil {
+		t.Fatalf("UnmarshalProto: %v\n", err)
+	}
+	F := M.(*matrix.FloatMatrix)
+	if F.Rows() != 2 || F.Cols() != 3 || F.GetAt(1, 2) != 6 || !math.IsNaN(F.GetAt(0, 2)) {
+		t.Logf("float: %v\n", F)
+		t.Fail()
+	}
+	Z := matrix.ComplexNew(1, 2, []complex128{1 + 2i, -1i})
+	b, _ = MarshalProto(Z)
+	M, err = UnmarshalProto(b)
+	if C, ok := M.(*matrix.ComplexMatrix); err != nil || !ok || C.GetAt(0, 1) != -1i {
+		t.Logf("complex: %v %v\n", M, err)
+		t.Fail()
+	}
+	// unpacked data after an unknown string field 5, as older encoders write
+	b = []byte{0x08, 1, 0x10, 1, 0x2a, 2, 'h', 'i', 0x19, 0, 0, 0, 0, 0, 0, 0xf8, 0x3f}
+	M, err = UnmarshalProto(b)
+	if err != nil || M.(*matrix.FloatMatrix).GetAt(0, 0) != 1.5 {
+		t.Logf("unpacked: %v %v\n", M, err)
+		t.Fail()
+	}
+	if _, err = UnmarshalProto(b[:len(b)-1]); err == nil {
+		t.Logf("truncated message accepted\n")
+		t.Fail()
+	}
+}
+
+func TestRowReader(t *testing.T) {
+	src := "# data\nx,y,z\n1,2,3\n4,,6\n7,8,9\n"
+	rr, err := NewCSVRowReader(strings.NewReader(src), linalg.BoolOpt("header", true),
+		linalg.StringOpt("columns", "z,x"))
+	if err != nil {
+		t.Fatalf("NewCSVRowReader: %v\n", err)
+	}
+	if rr.Cols() != 2 || rr.Names()[0] != "z" {
+		t.Fatalf("cols %d, names %v\n", rr.Cols(), rr.Names())
+	}
+	A, err := ReadRows(rr, 2)
+	if err != nil || A.Rows() != 2 || A.GetAt(1, 0) != 6 {
+		t.Fatalf("first chunk: %v %v\n", A, err)
+	}
+	A, err = ReadRows(rr, 2)
+	if err != nil || A.Rows() != 1 || A.GetAt(0, 1) != 7 {
+		t.Fatalf("second chunk: %v %v\n", A, err)
+	}
+	if _, err = ReadRows(rr, 2); err != io.EOF {
+		t.Fatalf("end: %v\n", err)
+	}
+	for src, kind := range map[string]error{"1,2\n3\n": linalg.ErrShape, "1,2\n3,x\n": linalg.ErrParameter} {
+		rr, err = NewCSVRowReader(strings.NewReader(src))
+		if err != nil {
+			t.Fatalf("NewCSVRowReader: %v\n", err)
+		}
+		if _, err = ReadRows(rr, 2); !errors.Is(err, kind) {
+			t.Errorf("%q: %v, want %v\n", src, err, kind)
+		}
+	}
+
+	M := matrix.FloatNew(5, 3, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15})
+	var buf bytes.Buffer
+	if err = WriteBinary(&buf, M); err != nil {
+		t.Fatalf("WriteBinary: %v\n", err)
+	}
+	br, err := NewBinaryRowReader(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("NewBinaryRowReader: %v\n", err)
+	}
+	B, err := ReadAllRows(br)
+	if err != nil || !B.Equal(M) {
+		t.Logf("binary rows: %v %v\n", B, err)
+		t.Fail()
+	}
+	bld := NewBuilder(3)
+	ForEachRow(NewMatrixRowReader(M), func(row []float64) error {
+		row[0] = -row[0]
+		return bld.AppendRow(row)
+	})
+	if bld.Rows() != 5 || bld.Matrix().GetAt(4, 0) != -5 {
+		t.Logf("builder: %v\n", bld.Matrix())
+		t.Fail()
+	}
+}
+
+func TestBinaryCorrupt(t *testing.T) {
+	var b bytes.Buffer
+	WriteBinary(&b, matrix.FloatNew(1, 1, []float64{1}))
+	hdr := b.Bytes()
+	le := binary.LittleEndian
+	// huge size with no data must fail without allocating it
+	big := append([]byte{}, hdr...)
+	le.PutUint64(big[16:], 1<<28)
+	le.PutUint64(big[24:], 1<<28)
+	if _, err := ReadBinary(bytes.NewReader(big)); err == nil {
+		t.Logf("huge size accepted\n")
+		t.Fail()
+	}
+	// offset wrapping the end of data around
+	wrap := append([]byte{}, hdr...)
+	le.PutUint64(wrap[32:], math.MaxUint64-7)
+	path := filepath.Join(t.TempDir(), "wrap.bin")
+	os.WriteFile(path, wrap, 0644)
+	if _, err := OpenMapped(path); err == nil {
+		t.Logf("wrapping offset accepted\n")
+		t.Fail()
+	}
+	if _, err := ReadBinary(bytes.NewReader(wrap)); err == nil {
+		t.Logf("wrapping offset accepted by ReadBinary\n")
+		t.Fail()
+	}
+	le.PutUint64(wrap[32:], 1<<20)
+	os.WriteFile(path, wrap, 0644)
+	if _, err := OpenMapped(path); err == nil {
+		t.Logf("offset past end of file accepted\n")
+		t.Fail()
+	}
+}
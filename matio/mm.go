@@ -0,0 +1,301 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matio package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matio
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Return format error with MatrixMarket prefix.
+func mmError(format string, args ...interface{}) error {
+	return linalg.NewError(linalg.ErrParameter, "MatrixMarket: "+fmt.Sprintf(format, args...))
+}
+
+// Largest number of elements of a matrix read by ReadMM.
+const maxDenseElements = math.MaxInt32
+
+// Return error if a dense rows by cols matrix of elements of size bytes is
+// too large to allocate or exceeds the memory budget, see
+// linalg.SetMemoryBudget. Sizes come from file headers and are not trusted.
+func checkDenseSize(rows, cols, size int) error {
+	if cols > 0 && rows > maxDenseElements/cols {
+		return linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("MatrixMarket: %d by %d matrix too large", rows, cols))
+	}
+	need := int64(rows) * int64(cols) * int64(size)
+	if budget := linalg.MemoryBudget(); budget > 0 && need > budget {
+		return linalg.NewError(linalg.ErrMemoryBudget,
+			fmt.Sprintf("MatrixMarket: %d by %d matrix needs %d bytes, budget %d", rows, cols, need, budget))
+	}
+	return nil
+}
+
+/*
+ Read MatrixMarket matrix.
+
+ Accepts array and coordinate layout with field real, double, integer,
+ complex or pattern (coordinate only) and symmetry general, symmetric,
+ skew-symmetric or hermitian. Returns *matrix.FloatMatrix for real fields
+ and *matrix.ComplexMatrix for complex field. Pattern entries are read as
+ ones and duplicate coordinate entries are summed.
+*/
+func ReadMM(r io.Reader) (matrix.Matrix, error) {
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, 1<<20)
+	if !s.Scan() {
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+		return nil, mmError("empty file")
+	}
+	hdr := strings.Fields(strings.ToLower(s.Text()))
+	if len(hdr) != 5 || hdr[0] != "%%matrixmarket" || hdr[1] != "matrix" {
+		return nil, mmError("not a matrix file")
+	}
+	layout, field, symm := hdr[2], hdr[3], hdr[4]
+	if layout != "array" && layout != "coordinate" {
+		return nil, mmError("unsupported layout %q", layout)
+	}
+	nval := 1
+	switch field {
+	case "real", "double", "integer":
+	case "complex":
+		nval = 2
+	case "pattern":
+		nval = 0
+		if layout != "coordinate" {
+			return nil, mmError("pattern field requires coordinate layout")
+		}
+	default:
+		return nil, mmError("unsupported field %q", field)
+	}
+	switch symm {
+	case "general", "symmetric", "skew-symmetric":
+	case "hermitian":
+		if field != "complex" {
+			symm = "symmetric"
+		}
+	default:
+		return nil, mmError("unsupported symmetry %q", symm)
+	}
+
+	var fields [][]string
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || line[0] == '%' {
+			continue
+		}
+		fields = append(fields, strings.Fields(line))
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, mmError("missing size line")
+	}
+	size, err := atois(fields[0])
+	fields = fields[1:]
+	if err != nil || len(size) < 2 || size[0] < 0 || size[1] < 0 {
+		return nil, mmError("invalid size line")
+	}
+	rows, cols := size[0], size[1]
+	if symm != "general" && rows != cols {
+		return nil, mmError("%s matrix not square", symm)
+	}
+	elemSize := 8
+	if nval == 2 {
+		elemSize = 16
+	}
+	if err := checkDenseSize(rows, cols, elemSize); err != nil {
+		return nil, err
+	}
+
+	var set func(i, j int, v []string) error
+	var A matrix.Matrix
+	if nval == 2 {
+		C := matrix.ComplexZeros(rows, cols)
+		set = func(i, j int, v []string) error {
+			re, err := strconv.ParseFloat(v[0], 64)
+			if err != nil {
+				return err
+			}
+			im, err := strconv.ParseFloat(v[1], 64)
+			if err != nil {
+				return err
+			}
+			z := complex(re, im)
+			C.SetAt(i, j, C.GetAt(i, j)+z)
+			if i != j {
+				switch symm {
+				case "symmetric":
+					C.SetAt(j, i, C.GetAt(j, i)+z)
+				case "skew-symmetric":
+					C.SetAt(j, i, C.GetAt(j, i)-z)
+				case "hermitian":
+					C.SetAt(j, i, C.GetAt(j, i)+complex(re, -im))
+				}
+			}
+			return nil
+		}
+		A = C
+	} else {
+		F := matrix.FloatZeros(rows, cols)
+		set = func(i, j int, v []string) error {
+			x := 1.0
+			if nval == 1 {
+				var err error
+				if x, err = strconv.ParseFloat(v[0], 64); err != nil {
+					return err
+				}
+			}
+			F.SetAt(i, j, F.GetAt(i, j)+x)
+			if i != j {
+				switch symm {
+				case "symmetric":
+					F.SetAt(j, i, F.GetAt(j, i)+x)
+				case "skew-symmetric":
+					F.SetAt(j, i, F.GetAt(j, i)-x)
+				}
+			}
+			return nil
+		}
+		A = F
+	}
+
+	if layout == "array" {
+		// entries may be split over lines arbitrarily
+		var tokens []string
+		for _, f := range fields {
+			tokens = append(tokens, f...)
+		}
+		k := 0
+		for j := 0; j < cols; j++ {
+			i0 := 0
+			if symm == "skew-symmetric" {
+				i0 = j + 1
+			} else if symm != "general" {
+				i0 = j
+			}
+			for i := i0; i < rows; i++ {
+				if k+nval > len(tokens) {
+					return nil, mmError("too few entries")
+				}
+				if err := set(i, j, tokens[k:k+nval]); err != nil {
+					return nil, mmError("%v", err)
+				}
+				k += nval
+			}
+		}
+		return A, nil
+	}
+
+	if len(size) != 3 || size[2] < 0 {
+		return nil, mmError("invalid size line")
+	}
+	if size[2] > len(fields) {
+		return nil, mmError("too few entries")
+	}
+	for _, f := range fields[:size[2]] {
+		if len(f) < 2+nval {
+			return nil, mmError("invalid entry %q", strings.Join(f, " "))
+		}
+		ij, err := atois(f[:2])
+		if err != nil {
+			return nil, mmError("%v", err)
+		}
+		if ij[0] < 1 || ij[0] > rows || ij[1] < 1 || ij[1] > cols {
+			return nil, mmError("entry (%d,%d) out of range", ij[0], ij[1])
+		}
+		if err = set(ij[0]-1, ij[1]-1, f[2:2+nval]); err != nil {
+			return nil, mmError("%v", err)
+		}
+	}
+	return A, nil
+}
+
+/*
+ Write matrix in MatrixMarket format with general symmetry.
+
+ OPTIONS
+  coordinate  bool; write nonzero entries in coordinate layout instead of
+              all entries in array layout. Default false.
+*/
+func WriteMM(w io.Writer, A matrix.Matrix, opts ...linalg.Option) error {
+	coord := linalg.GetBoolOpt("coordinate", false, opts...)
+	var field string
+	var at func(i, j int) (string, bool)
+	switch A := A.(type) {
+	case *matrix.FloatMatrix:
+		field = "real"
+		at = func(i, j int) (string, bool) {
+			v := A.GetAt(i, j)
+			return strconv.FormatFloat(v, 'g', -1, 64), v != 0.0
+		}
+	case *matrix.ComplexMatrix:
+		field = "complex"
+		at = func(i, j int) (string, bool) {
+			v := A.GetAt(i, j)
+			return strconv.FormatFloat(real(v), 'g', -1, 64) + " " +
+				strconv.FormatFloat(imag(v), 'g', -1, 64), v != 0
+		}
+	default:
+		return linalg.NewError(linalg.ErrType, "WriteMM: unknown matrix type")
+	}
+	rows, cols := A.Rows(), A.Cols()
+	bw := bufio.NewWriter(w)
+	if !coord {
+		fmt.Fprintf(bw, "%%%%MatrixMarket matrix array %s general\n%d %d\n", field, rows, cols)
+		for j := 0; j < cols; j++ {
+			for i := 0; i < rows; i++ {
+				v, _ := at(i, j)
+				bw.WriteString(v)
+				bw.WriteByte('\n')
+			}
+		}
+		return bw.Flush()
+	}
+	nnz := 0
+	for j := 0; j < cols; j++ {
+		for i := 0; i < rows; i++ {
+			if _, nz := at(i, j); nz {
+				nnz++
+			}
+		}
+	}
+	fmt.Fprintf(bw, "%%%%MatrixMarket matrix coordinate %s general\n%d %d %d\n", field, rows, cols, nnz)
+	for j := 0; j < cols; j++ {
+		for i := 0; i < rows; i++ {
+			if v, nz := at(i, j); nz {
+				fmt.Fprintf(bw, "%d %d %s\n", i+1, j+1, v)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// Convert strings to ints.
+func atois(s []string) ([]int, error) {
+	n := make([]int, len(s))
+	for k, v := range s {
+		var err error
+		if n[k], err = strconv.Atoi(v); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
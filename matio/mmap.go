@@ -0,0 +1,84 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matio package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matio
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+	"os"
+)
+
+// Matrix in a binary matrix file mapped to memory. The operating system
+// pages the data in on demand, so matrices larger than the available RAM
+// can be read. Matrix is a read-only view, accepted by blas and lapack as
+// input only; writing through it would fault as the mapping is read-only.
+type Mapped struct {
+	Matrix *matops.ReadOnly
+	data   []byte
+}
+
+// Open binary matrix file written by WriteBinary and map it to memory. On
+// systems without mmap support and on big-endian hosts the file is read
+// into memory instead. The mapping is released by Close, after which the
+// matrix must not be used.
+func OpenMapped(path string) (*Mapped, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	hdr := make([]byte, binaryHeaderSize)
+	if _, err := f.ReadAt(hdr, 0); err != nil {
+		return nil, binaryError("short header")
+	}
+	h, err := parseBinaryHeader(hdr)
+	if err != nil {
+		return nil, err
+	}
+	if h.offset > uint64(st.Size()) {
+		return nil, binaryError("data offset past end of file")
+	}
+	end := h.offset + 8*h.rows*h.cols
+	if end < h.offset || uint64(st.Size()) < end {
+		return nil, linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("matio: binary: %d bytes for %d by %d matrix", st.Size(), h.rows, h.cols))
+	}
+	if !littleEndian || end == h.offset {
+		A, err := ReadBinary(f)
+		if err != nil {
+			return nil, err
+		}
+		return &Mapped{Matrix: matops.NewReadOnly(A)}, nil
+	}
+	data, err := mmapFile(f, int(end))
+	if err != nil {
+		return nil, err
+	}
+	elems := decodeFloats(data[h.offset:end])
+	A := matrix.FloatNew(int(h.rows), int(h.cols), elems)
+	return &Mapped{Matrix: matops.NewReadOnly(A), data: data}, nil
+}
+
+// Release the memory mapping.
+func (M *Mapped) Close() error {
+	if M.data == nil {
+		return nil
+	}
+	data := M.data
+	M.data = nil
+	return munmap(data)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
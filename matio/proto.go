@@ -0,0 +1,199 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matio package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Field numbers of message Matrix in matrix.proto.
+const (
+	protoRows        = 1
+	protoCols        = 2
+	protoData        = 3
+	protoComplexData = 4
+)
+
+// Protocol buffer wire types.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// Encode float or complex matrix A as protocol buffer message Matrix
+// defined in matrix.proto. The encoding is done directly, without the
+// protobuf runtime, and elements are written as packed doubles.
+func MarshalProto(A matrix.Matrix) ([]byte, error) {
+	rows, cols := A.Rows(), A.Cols()
+	var data []float64
+	field := protoData
+	switch A := A.(type) {
+	case *matrix.FloatMatrix:
+		data = make([]float64, 0, rows*cols)
+		for j := 0; j < cols; j++ {
+			for i := 0; i < rows; i++ {
+				data = append(data, A.GetAt(i, j))
+			}
+		}
+	case *matrix.ComplexMatrix:
+		field = protoComplexData
+		data = make([]float64, 0, 2*rows*cols)
+		for j := 0; j < cols; j++ {
+			for i := 0; i < rows; i++ {
+				v := A.GetAt(i, j)
+				data = append(data, real(v), imag(v))
+			}
+		}
+	default:
+		return nil, linalg.NewError(linalg.ErrType, "matio: proto: unsupported matrix type")
+	}
+	b := make([]byte, 0, 24+8*len(data))
+	if rows != 0 {
+		b = binary.AppendUvarint(b, protoRows<<3|wireVarint)
+		b = binary.AppendUvarint(b, uint64(rows))
+	}
+	if cols != 0 {
+		b = binary.AppendUvarint(b, protoCols<<3|wireVarint)
+		b = binary.AppendUvarint(b, uint64(cols))
+	}
+	if len(data) > 0 {
+		b = binary.AppendUvarint(b, uint64(field)<<3|wireBytes)
+		b = binary.AppendUvarint(b, uint64(8*len(data)))
+		for _, v := range data {
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(v))
+		}
+	}
+	return b, nil
+}
+
+// Decode protocol buffer message Matrix defined in matrix.proto. Returns
+// a *matrix.ComplexMatrix if complex_data is set and a *matrix.FloatMatrix
+// otherwise. Unknown fields are skipped and unpacked repeated doubles are
+// accepted, as required of protobuf parsers.
+func UnmarshalProto(b []byte) (matrix.Matrix, error) {
+	var rows, cols uint64
+	var data, cdata []float64
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, protoError("invalid tag")
+		}
+		b = b[n:]
+		field, wire := tag>>3, tag&7
+		var err error
+		switch {
+		case field == protoRows && wire == wireVarint:
+			rows, b, err = protoVarint(b)
+		case field == protoCols && wire == wireVarint:
+			cols, b, err = protoVarint(b)
+		case field == protoData && (wire == wireBytes || wire == wireFixed64):
+			data, b, err = protoDoubles(data, b, wire)
+		case field == protoComplexData && (wire == wireBytes || wire == wireFixed64):
+			cdata, b, err = protoDoubles(cdata, b, wire)
+		default:
+			b, err = protoSkip(b, wire)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if rows > math.MaxInt32 || cols > math.MaxInt32 {
+		return nil, linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("matio: proto: invalid size %d by %d", rows, cols))
+	}
+	size := int(rows * cols)
+	if cdata != nil {
+		if data != nil || len(cdata) != 2*size {
+			return nil, protoError("complex_data length does not match size")
+		}
+		A := matrix.ComplexZeros(int(rows), int(cols))
+		for k := 0; k < size; k++ {
+			A.SetAt(k%int(rows), k/int(rows), complex(cdata[2*k], cdata[2*k+1]))
+		}
+		return A, nil
+	}
+	if len(data) != size {
+		return nil, protoError("data length does not match size")
+	}
+	if size == 0 {
+		return matrix.FloatZeros(int(rows), int(cols)), nil
+	}
+	return matrix.FloatNew(int(rows), int(cols), data), nil
+}
+
+// Read varint from b and return it with the rest of b.
+func protoVarint(b []byte) (uint64, []byte, error) {
+	v, n := binary.Uvarint(b)
+	if n <= 0 {
+		return 0, nil, protoError("invalid varint")
+	}
+	return v, b[n:], nil
+}
+
+// Append packed (wireBytes) or single (wireFixed64) doubles from b to
+// data and return data with the rest of b.
+func protoDoubles(data []float64, b []byte, wire uint64) ([]float64, []byte, error) {
+	m := uint64(8)
+	if wire == wireBytes {
+		var err error
+		if m, b, err = protoVarint(b); err != nil {
+			return nil, nil, err
+		}
+		if m%8 != 0 {
+			return nil, nil, protoError("packed doubles length not a multiple of 8")
+		}
+	}
+	if m > uint64(len(b)) {
+		return nil, nil, protoError("truncated message")
+	}
+	if data == nil {
+		data = make([]float64, 0, m/8)
+	}
+	for k := uint64(0); k < m; k += 8 {
+		data = append(data, math.Float64frombits(binary.LittleEndian.Uint64(b[k:])))
+	}
+	return data, b[m:], nil
+}
+
+// Skip value of unknown field of the given wire type.
+func protoSkip(b []byte, wire uint64) ([]byte, error) {
+	var m uint64
+	switch wire {
+	case wireVarint:
+		_, b, err := protoVarint(b)
+		return b, err
+	case wireFixed64:
+		m = 8
+	case wireFixed32:
+		m = 4
+	case wireBytes:
+		var err error
+		if m, b, err = protoVarint(b); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, protoError(fmt.Sprintf("unsupported wire type %d", wire))
+	}
+	if m > uint64(len(b)) {
+		return nil, protoError("truncated message")
+	}
+	return b[m:], nil
+}
+
+func protoError(msg string) error {
+	return linalg.NewError(linalg.ErrParameter, "matio: proto: "+msg)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
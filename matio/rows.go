@@ -0,0 +1,228 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matio package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matio
+
+import (
+	"encoding/binary"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"io"
+	"math"
+)
+
+// Source of matrix rows read one at a time, for data sets that do not fit
+// in memory. ReadRow fills row[:Cols()] with the next row and returns
+// io.EOF after the last one.
+type RowReader interface {
+	Cols() int
+	ReadRow(row []float64) error
+}
+
+// Call f with each remaining row of rr. The slice passed to f is reused
+// between calls. Stops at the first error from rr or f; io.EOF at the
+// end of rows is not returned.
+func ForEachRow(rr RowReader, f func(row []float64) error) error {
+	row := make([]float64, rr.Cols())
+	for {
+		err := rr.ReadRow(row)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err = f(row); err != nil {
+			return err
+		}
+	}
+}
+
+// Read at most n rows from rr into a new matrix, for chunked processing.
+// Returns fewer rows when rr is exhausted and io.EOF if no rows remain.
+func ReadRows(rr RowReader, n int) (*matrix.FloatMatrix, error) {
+	B := NewBuilder(rr.Cols())
+	row := make([]float64, rr.Cols())
+	for B.Rows() < n {
+		err := rr.ReadRow(row)
+		if err == io.EOF {
+			if B.Rows() == 0 {
+				return nil, io.EOF
+			}
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		B.AppendRow(row)
+	}
+	return B.Matrix(), nil
+}
+
+// Read all remaining rows from rr into a new matrix.
+func ReadAllRows(rr RowReader) (*matrix.FloatMatrix, error) {
+	B := NewBuilder(rr.Cols())
+	if err := ForEachRow(rr, B.AppendRow); err != nil {
+		return nil, err
+	}
+	return B.Matrix(), nil
+}
+
+// Float matrix built incrementally one row at a time.
+type Builder struct {
+	cols int
+	data []float64 // row-major
+}
+
+// Return builder of matrices with cols columns.
+func NewBuilder(cols int) *Builder {
+	return &Builder{cols: cols}
+}
+
+// Append copy of row[:Cols()] as the last row.
+func (B *Builder) AppendRow(row []float64) error {
+	if len(row) < B.cols {
+		return linalg.NewError(linalg.ErrShape, "Builder: row too short")
+	}
+	B.data = append(B.data, row[:B.cols]...)
+	return nil
+}
+
+// Return number of rows appended.
+func (B *Builder) Rows() int {
+	if B.cols == 0 {
+		return 0
+	}
+	return len(B.data) / B.cols
+}
+
+// Return number of columns.
+func (B *Builder) Cols() int {
+	return B.cols
+}
+
+// Return new matrix of the rows appended so far.
+func (B *Builder) Matrix() *matrix.FloatMatrix {
+	rows := B.Rows()
+	A := matrix.FloatZeros(rows, B.cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < B.cols; j++ {
+			A.SetAt(i, j, B.data[i*B.cols+j])
+		}
+	}
+	return A
+}
+
+// Remove all rows, keeping allocated storage for reuse.
+func (B *Builder) Reset() {
+	B.data = B.data[:0]
+}
+
+// Rows of an in-memory matrix, implementing RowReader.
+type MatrixRowReader struct {
+	A *matrix.FloatMatrix
+	i int
+}
+
+// Return reader of the rows of A.
+func NewMatrixRowReader(A *matrix.FloatMatrix) *MatrixRowReader {
+	return &MatrixRowReader{A: A}
+}
+
+func (R *MatrixRowReader) Cols() int {
+	return R.A.Cols()
+}
+
+func (R *MatrixRowReader) ReadRow(row []float64) error {
+	if R.i >= R.A.Rows() {
+		return io.EOF
+	}
+	for j := 0; j < R.A.Cols(); j++ {
+		row[j] = R.A.GetAt(R.i, j)
+	}
+	R.i++
+	return nil
+}
+
+// Number of elements read per block by BinaryRowReader.
+const binaryRowBlock = 1 << 17
+
+// Rows of a matrix in the binary format of WriteBinary, implementing
+// RowReader. The data is column-major, so rows are read in blocks of
+// contiguous column segments; a block takes about 1MB of memory.
+type BinaryRowReader struct {
+	r          io.ReaderAt
+	h          *binaryHeader
+	next       uint64    // index of next row
+	start, end uint64    // rows held in buf
+	buf        []float64 // rows start:end in row-major order
+	seg        []byte
+}
+
+// Return reader of rows of the binary matrix file read through r.
+func NewBinaryRowReader(r io.ReaderAt) (*BinaryRowReader, error) {
+	hdr := make([]byte, binaryHeaderSize)
+	if n, _ := r.ReadAt(hdr, 0); n < len(hdr) {
+		return nil, binaryError("short header")
+	}
+	h, err := parseBinaryHeader(hdr)
+	if err != nil {
+		return nil, err
+	}
+	return &BinaryRowReader{r: r, h: h}, nil
+}
+
+// Return total number of rows.
+func (R *BinaryRowReader) Rows() int {
+	return int(R.h.rows)
+}
+
+func (R *BinaryRowReader) Cols() int {
+	return int(R.h.cols)
+}
+
+func (R *BinaryRowReader) ReadRow(row []float64) error {
+	if R.next >= R.h.rows {
+		return io.EOF
+	}
+	if R.next >= R.end {
+		if err := R.fill(); err != nil {
+			return err
+		}
+	}
+	cols := R.h.cols
+	copy(row[:cols], R.buf[(R.next-R.start)*cols:])
+	R.next++
+	return nil
+}
+
+// Read block of rows starting at R.next.
+func (R *BinaryRowReader) fill() error {
+	rows, cols := R.h.rows, R.h.cols
+	k := uint64(binaryRowBlock) / max(cols, 1)
+	k = min(max(k, 1), rows-R.next)
+	if uint64(cap(R.seg)) < 8*k {
+		R.buf = make([]float64, k*cols)
+		R.seg = make([]byte, 8*k)
+	}
+	R.buf = R.buf[:k*cols]
+	seg := R.seg[:8*k]
+	for j := uint64(0); j < cols; j++ {
+		off := R.h.offset + 8*(j*rows+R.next)
+		if n, _ := R.r.ReadAt(seg, int64(off)); n < len(seg) {
+			return binaryError("short data")
+		}
+		for i := uint64(0); i < k; i++ {
+			R.buf[i*cols+j] = math.Float64frombits(binary.LittleEndian.Uint64(seg[8*i:]))
+		}
+	}
+	R.start, R.end = R.next, R.next+k
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
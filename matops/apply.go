@@ -0,0 +1,100 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"runtime"
+	"sync"
+)
+
+// Matrices with fewer elements than this are always processed serially; the
+// cost of starting goroutines would dominate.
+const minParallel = 1 << 14
+
+/*
+ Apply function fn to every element of A and return the result as a new matrix.
+
+ OPTIONS
+  workers  number of goroutines; 1 (default) runs serially, 0 or negative
+           uses runtime.GOMAXPROCS(0). Small matrices are always processed
+           serially.
+*/
+func Apply(A *matrix.FloatMatrix, fn func(float64) float64, opts ...linalg.Option) *matrix.FloatMatrix {
+	return ApplyInPlace(A.Copy(), fn, opts...)
+}
+
+// Compute A[i,j] := fn(A[i,j]) for all elements of A. Returns A. Options as for Apply.
+func ApplyInPlace(A *matrix.FloatMatrix, fn func(float64) float64, opts ...linalg.Option) *matrix.FloatMatrix {
+	Ar := A.FloatArray()
+	lda := A.LeadingIndex()
+	m := A.Rows()
+	parallelCols(A, func(j0, j1 int) {
+		for j := j0; j < j1; j++ {
+			col := Ar[j*lda : j*lda+m]
+			for i, v := range col {
+				col[i] = fn(v)
+			}
+		}
+	}, opts...)
+	return A
+}
+
+// Apply function fn to every element of A and return the result as a new matrix.
+// Function is called with row and column index of the element. Options as for Apply.
+func ApplyIndexed(A *matrix.FloatMatrix, fn func(i, j int, v float64) float64, opts ...linalg.Option) *matrix.FloatMatrix {
+	return ApplyIndexedInPlace(A.Copy(), fn, opts...)
+}
+
+// Compute A[i,j] := fn(i, j, A[i,j]) for all elements of A. Returns A.
+// Options as for Apply.
+func ApplyIndexedInPlace(A *matrix.FloatMatrix, fn func(i, j int, v float64) float64, opts ...linalg.Option) *matrix.FloatMatrix {
+	Ar := A.FloatArray()
+	lda := A.LeadingIndex()
+	m := A.Rows()
+	parallelCols(A, func(j0, j1 int) {
+		for j := j0; j < j1; j++ {
+			col := Ar[j*lda : j*lda+m]
+			for i, v := range col {
+				col[i] = fn(i, j, v)
+			}
+		}
+	}, opts...)
+	return A
+}
+
+// Call work on disjoint column ranges [j0, j1) covering all columns of A,
+// concurrently if requested with 'workers' option and A is large enough.
+func parallelCols(A matrix.Matrix, work func(j0, j1 int), opts ...linalg.Option) {
+	n := A.Cols()
+	nw := linalg.GetIntOpt("workers", 1, opts...)
+	if nw <= 0 {
+		nw = runtime.GOMAXPROCS(0)
+	}
+	if nw > n {
+		nw = n
+	}
+	if nw <= 1 || A.NumElements() < minParallel {
+		work(0, n)
+		return
+	}
+	var wg sync.WaitGroup
+	for k := 0; k < nw; k++ {
+		j0, j1 := k*n/nw, (k+1)*n/nw
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			work(j0, j1)
+		}()
+	}
+	wg.Wait()
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,76 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+)
+
+// Band matrix of size M by N with Kl subdiagonals and Ku superdiagonals.
+// The embedded matrix holds the band in BLAS band storage, see
+// BandStorage; its Rows and Cols are those of the storage, Kl+Ku+1 by N.
+// A band matrix with Kl or Ku zero is also a triangular band matrix and,
+// with the other triangle implied, a symmetric or Hermitian one.
+//
+// The banded level 2 routines of package blas accept a *BandMatrix in
+// place of the storage and the m, n, kl, ku, k and uplo options.
+type BandMatrix struct {
+	matrix.Matrix
+	M, N, Kl, Ku int
+}
+
+// Return band matrix of the elements of A within kl subdiagonals and ku
+// superdiagonals.
+func NewBandMatrix(A matrix.Matrix, kl, ku int) (*BandMatrix, error) {
+	S, err := BandStorage(A, kl, ku)
+	if err != nil {
+		return nil, err
+	}
+	return &BandMatrix{S, A.Rows(), A.Cols(), kl, ku}, nil
+}
+
+// Return band matrix of size m by n with storage S in BLAS band storage.
+func BandMatrixFromStorage(S matrix.Matrix, m, n, kl, ku int) (*BandMatrix, error) {
+	if m < 0 || n < 0 || kl < 0 || ku < 0 {
+		return nil, linalg.NewError(linalg.ErrParameter, "BandMatrix: negative size or bandwidth")
+	}
+	if S.Rows() < kl+ku+1 || S.Cols() < n {
+		return nil, linalg.NewError(linalg.ErrShape, "BandMatrix: storage too small")
+	}
+	return &BandMatrix{S, m, n, kl, ku}, nil
+}
+
+// Return the band matrix as a new dense M by N matrix.
+func (B *BandMatrix) Dense() matrix.Matrix {
+	ld := B.LeadingIndex()
+	switch S := B.Matrix.(type) {
+	case *matrix.FloatMatrix:
+		Sr := S.FloatArray()
+		A := matrix.FloatZeros(B.M, B.N)
+		for j := 0; j < B.N; j++ {
+			for i := max(0, j-B.Ku); i < min(B.M, j+B.Kl+1); i++ {
+				A.SetAt(i, j, Sr[j*ld+B.Ku+i-j])
+			}
+		}
+		return A
+	case *matrix.ComplexMatrix:
+		Sr := S.ComplexArray()
+		A := matrix.ComplexZeros(B.M, B.N)
+		for j := 0; j < B.N; j++ {
+			for i := max(0, j-B.Ku); i < min(B.M, j+B.Kl+1); i++ {
+				A.SetAt(i, j, Sr[j*ld+B.Ku+i-j])
+			}
+		}
+		return A
+	}
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,245 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Reduce symmetric n*n A to tridiagonal form T = Q^T*A*Q with Householder
+// reflections and return the diagonal d and subdiagonal e of T. Only the
+// lower triangle of A is referenced. A is not modified.
+func SymTridiagonal(A *matrix.FloatMatrix) (d, e []float64, err error) {
+	n := A.Rows()
+	if A.Cols() != n {
+		return nil, nil, linalg.NewError(linalg.ErrShape, "SymTridiagonal: A not square")
+	}
+	// full symmetric working copy, column-major
+	a := make([]float64, n*n)
+	for j := 0; j < n; j++ {
+		for i := j; i < n; i++ {
+			a[j*n+i] = A.GetAt(i, j)
+			a[i*n+j] = a[j*n+i]
+		}
+	}
+	d = make([]float64, n)
+	e = make([]float64, max(0, n-1))
+	v := make([]float64, n)
+	w := make([]float64, n)
+	for k := 0; k < n-2; k++ {
+		// reflector annihilating a[k+2:n, k]
+		x := a[k*n+k+1 : k*n+n]
+		xnorm := 0.0
+		for _, xi := range x {
+			xnorm = math.Hypot(xnorm, xi)
+		}
+		if xnorm == 0.0 {
+			e[k] = 0.0
+			continue
+		}
+		alpha := -math.Copysign(xnorm, x[0])
+		vk := v[k+1 : n]
+		copy(vk, x)
+		vk[0] -= alpha
+		vtv := 0.0
+		for _, vi := range vk {
+			vtv += vi * vi
+		}
+		e[k] = alpha
+		if vtv == 0.0 {
+			continue
+		}
+		tau := 2.0 / vtv
+		// p = tau*A22*v, K = tau/2*v^T*p, w = p - K*v
+		wk := w[k+1 : n]
+		for i := range wk {
+			s := 0.0
+			for j, vj := range vk {
+				s += a[(k+1+j)*n+k+1+i] * vj
+			}
+			wk[i] = tau * s
+		}
+		K := 0.0
+		for i, vi := range vk {
+			K += vi * wk[i]
+		}
+		K *= tau / 2.0
+		for i := range wk {
+			wk[i] -= K * vk[i]
+		}
+		// A22 -= v*w^T + w*v^T
+		for j := range vk {
+			col := a[(k+1+j)*n+k+1 : (k+1+j)*n+n]
+			for i := range col {
+				col[i] -= vk[i]*wk[j] + wk[i]*vk[j]
+			}
+		}
+	}
+	for k := 0; k < n; k++ {
+		d[k] = a[k*n+k]
+	}
+	if n >= 2 {
+		e[n-2] = a[(n-2)*n+n-1]
+	}
+	return d, e, nil
+}
+
+// Return number of eigenvalues less than x of symmetric tridiagonal matrix
+// with diagonal d and squared subdiagonal e2 (Sturm sequence count).
+func sturmCount(d, e2 []float64, x, pivmin float64) int {
+	count := 0
+	q := 1.0
+	for i, di := range d {
+		if i == 0 {
+			q = di - x
+		} else {
+			q = di - x - e2[i-1]/q
+		}
+		if math.Abs(q) < pivmin {
+			q = -pivmin
+		}
+		if q < 0.0 {
+			count++
+		}
+	}
+	return count
+}
+
+// Smallest allowed Sturm sequence pivot given largest squared subdiagonal
+// element, as in LAPACK's dstebz.
+func pivotMin(maxe2 float64) float64 {
+	const safmin = 0x1p-1022
+	return safmin * math.Max(1.0, maxe2)
+}
+
+/*
+ Eigenvalues of a symmetric tridiagonal matrix by bisection.
+
+ Computes eigenvalues lo, ..., hi-1 in ascending order (zero-based) of the
+ n*n symmetric tridiagonal matrix with diagonal d and subdiagonal e,
+ 0 <= lo <= hi <= n. Each eigenvalue is independently bracketed with
+ Sturm sequence counts and bisected until its enclosing interval is no
+ wider than the absolute tolerance tol, or the floating point resolution
+ near it if that is larger. A nonpositive tol selects
+ 2*eps*max(|Gershgorin bounds|).
+
+ The cost is O(n) per bisection step, so a few eigenvalues of a large
+ matrix are cheap.
+*/
+func TridiagBisect(d, e []float64, lo, hi int, tol float64) ([]float64, error) {
+	n := len(d)
+	if len(e) < n-1 {
+		return nil, linalg.NewError(linalg.ErrShape, "TridiagBisect: len(e) < len(d)-1")
+	}
+	if lo < 0 || hi > n || lo > hi {
+		return nil, linalg.NewError(linalg.ErrParameter, "TridiagBisect: need 0 <= lo <= hi <= n")
+	}
+	e2 := make([]float64, max(0, n-1))
+	gl, gu := math.Inf(1), math.Inf(-1)
+	maxe2 := 0.0
+	for i := 0; i < n; i++ {
+		r := 0.0
+		if i > 0 {
+			r += math.Abs(e[i-1])
+		}
+		if i < n-1 {
+			r += math.Abs(e[i])
+			e2[i] = e[i] * e[i]
+			maxe2 = math.Max(maxe2, e2[i])
+		}
+		gl = math.Min(gl, d[i]-r)
+		gu = math.Max(gu, d[i]+r)
+	}
+	const eps = 0x1p-52
+	bnorm := math.Max(math.Abs(gl), math.Abs(gu))
+	pivmin := pivotMin(maxe2)
+	if tol <= 0.0 {
+		tol = 2.0 * eps * bnorm
+	}
+	// widen Gershgorin interval slightly to guard against rounding
+	gl -= 2.0*eps*bnorm*float64(n) + 2.0*pivmin
+	gu += 2.0*eps*bnorm*float64(n) + 2.0*pivmin
+	w := make([]float64, hi-lo)
+	for k := lo; k < hi; k++ {
+		// invariant: count(a) <= k < count(b)
+		a, b := gl, gu
+		if k > lo && w[k-lo-1] > a {
+			a = w[k-lo-1] - tol
+			if sturmCount(d, e2, a, pivmin) > k {
+				a = gl
+			}
+		}
+		for {
+			width := math.Max(tol, 2.0*eps*math.Max(math.Abs(a), math.Abs(b)))
+			if b-a <= width {
+				break
+			}
+			mid := a + (b-a)/2.0
+			if mid <= a || mid >= b {
+				break
+			}
+			if sturmCount(d, e2, mid, pivmin) <= k {
+				a = mid
+			} else {
+				b = mid
+			}
+		}
+		w[k-lo] = a + (b-a)/2.0
+	}
+	return w, nil
+}
+
+/*
+ Selected eigenvalues of a symmetric matrix by bisection.
+
+ Computes eigenvalues lo, ..., hi-1 in ascending order (zero-based) of
+ symmetric A to absolute accuracy tol, plus the rounding error of the
+ reduction to tridiagonal form, which is a modest multiple of
+ eps*||A||. Only the lower triangle of A is referenced. A is not modified.
+ See TridiagBisect.
+
+ OPTIONS
+  tol       float; absolute tolerance. Default as for TridiagBisect.
+*/
+func SymEigBisect(A *matrix.FloatMatrix, lo, hi int, opts ...linalg.Option) ([]float64, error) {
+	d, e, err := SymTridiagonal(A)
+	if err != nil {
+		return nil, err
+	}
+	return TridiagBisect(d, e, lo, hi, linalg.GetFloatOpt("tol", 0.0, opts...))
+}
+
+/*
+ Eigenvalues of symmetric A in half-open interval (vl, vu] by bisection,
+ in ascending order. Options as for SymEigBisect.
+*/
+func SymEigInterval(A *matrix.FloatMatrix, vl, vu float64, opts ...linalg.Option) ([]float64, error) {
+	if !(vl < vu) {
+		return nil, linalg.NewError(linalg.ErrParameter, "SymEigInterval: must be vl < vu")
+	}
+	d, e, err := SymTridiagonal(A)
+	if err != nil {
+		return nil, err
+	}
+	e2 := make([]float64, len(e))
+	maxe2 := 0.0
+	for i, ei := range e {
+		e2[i] = ei * ei
+		maxe2 = math.Max(maxe2, e2[i])
+	}
+	pivmin := pivotMin(maxe2)
+	// count(x) counts eigenvalues < x; nudge to count those <= vl and <= vu
+	lo := sturmCount(d, e2, math.Nextafter(vl, math.Inf(1)), pivmin)
+	hi := sturmCount(d, e2, math.Nextafter(vu, math.Inf(1)), pivmin)
+	return TridiagBisect(d, e, lo, hi, linalg.GetFloatOpt("tol", 0.0, opts...))
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
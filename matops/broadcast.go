@@ -0,0 +1,172 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+)
+
+const (
+	bcAddRow = iota
+	bcAddCol
+	bcScaleRows
+	bcScaleCols
+)
+
+// Return A + 1*x^T as a new matrix, ie. add vector x to every row of A.
+// Vector x may be row or column vector with A.Cols() elements.
+func AddRowVector(A, x matrix.Matrix) (matrix.Matrix, error) {
+	return broadcastCopy(bcAddRow, A, x)
+}
+
+// Compute A := A + 1*x^T.
+func AddRowVectorInPlace(A, x matrix.Matrix) error {
+	return broadcast(bcAddRow, A, x)
+}
+
+// Return A + x*1^T as a new matrix, ie. add vector x to every column of A.
+// Vector x may be row or column vector with A.Rows() elements.
+func AddColVector(A, x matrix.Matrix) (matrix.Matrix, error) {
+	return broadcastCopy(bcAddCol, A, x)
+}
+
+// Compute A := A + x*1^T.
+func AddColVectorInPlace(A, x matrix.Matrix) error {
+	return broadcast(bcAddCol, A, x)
+}
+
+// Return diag(d)*A as a new matrix, ie. scale row i of A with d[i].
+func ScaleRows(A, d matrix.Matrix) (matrix.Matrix, error) {
+	return broadcastCopy(bcScaleRows, A, d)
+}
+
+// Compute A := diag(d)*A.
+func ScaleRowsInPlace(A, d matrix.Matrix) error {
+	return broadcast(bcScaleRows, A, d)
+}
+
+// Return A*diag(d) as a new matrix, ie. scale column j of A with d[j].
+func ScaleCols(A, d matrix.Matrix) (matrix.Matrix, error) {
+	return broadcastCopy(bcScaleCols, A, d)
+}
+
+// Compute A := A*diag(d).
+func ScaleColsInPlace(A, d matrix.Matrix) error {
+	return broadcast(bcScaleCols, A, d)
+}
+
+func broadcastCopy(op int, A, x matrix.Matrix) (matrix.Matrix, error) {
+	C := A.MakeCopy()
+	if err := broadcast(op, C, x); err != nil {
+		return nil, err
+	}
+	return C, nil
+}
+
+func broadcast(op int, A, x matrix.Matrix) error {
+	m, n := A.Size()
+	nx := n
+	if op == bcAddCol || op == bcScaleRows {
+		nx = m
+	}
+	if x.Rows() != 1 && x.Cols() != 1 {
+		return linalg.NewError(linalg.ErrShape, "argument not a vector")
+	}
+	if x.NumElements() != nx {
+		return linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("vector length %d, expected %d", x.NumElements(), nx))
+	}
+	if !matrix.EqualTypes(A, x) {
+		return linalg.NewError(linalg.ErrType, "arguments not of same type")
+	}
+	lda := A.LeadingIndex()
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		xv := floatVector(x.(*matrix.FloatMatrix))
+		for j := 0; j < n; j++ {
+			col := Ar[j*lda : j*lda+m]
+			switch op {
+			case bcAddRow:
+				for i := range col {
+					col[i] += xv[j]
+				}
+			case bcAddCol:
+				for i := range col {
+					col[i] += xv[i]
+				}
+			case bcScaleRows:
+				for i := range col {
+					col[i] *= xv[i]
+				}
+			case bcScaleCols:
+				for i := range col {
+					col[i] *= xv[j]
+				}
+			}
+		}
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		xv := complexVector(x.(*matrix.ComplexMatrix))
+		for j := 0; j < n; j++ {
+			col := Ar[j*lda : j*lda+m]
+			switch op {
+			case bcAddRow:
+				for i := range col {
+					col[i] += xv[j]
+				}
+			case bcAddCol:
+				for i := range col {
+					col[i] += xv[i]
+				}
+			case bcScaleRows:
+				for i := range col {
+					col[i] *= xv[i]
+				}
+			case bcScaleCols:
+				for i := range col {
+					col[i] *= xv[j]
+				}
+			}
+		}
+	default:
+		return linalg.NewError(linalg.ErrType, "unknown matrix type")
+	}
+	return nil
+}
+
+// Elements of row or column vector x as a slice. Row vectors are strided by
+// the leading index and are copied.
+func floatVector(x *matrix.FloatMatrix) []float64 {
+	xr := x.FloatArray()
+	if x.Cols() == 1 || x.LeadingIndex() == 1 {
+		return xr[:x.NumElements()]
+	}
+	v := make([]float64, x.Cols())
+	for k := range v {
+		v[k] = xr[k*x.LeadingIndex()]
+	}
+	return v
+}
+
+func complexVector(x *matrix.ComplexMatrix) []complex128 {
+	xr := x.ComplexArray()
+	if x.Cols() == 1 || x.LeadingIndex() == 1 {
+		return xr[:x.NumElements()]
+	}
+	v := make([]complex128, x.Cols())
+	for k := range v {
+		v[k] = xr[k*x.LeadingIndex()]
+	}
+	return v
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
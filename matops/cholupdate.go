@@ -0,0 +1,242 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Compute lower triangular L1 with L1*L1^T = L*L^T + x*x^T in place of lower
+// triangular n*n L with positive diagonal. x is a vector of length n and is
+// not modified. Elements above the diagonal of L are not referenced. Cost is
+// O(n^2). Fails with ErrParameter and leaves L unchanged if a diagonal
+// element of L is not positive.
+func CholUpdate(L, x *matrix.FloatMatrix) error {
+	if err := checkCholUpdate("CholUpdate", L, x); err != nil {
+		return err
+	}
+	L0 := L.Copy()
+	if !cholRotate(L, x, 1.0) {
+		copyFloat(L, L0)
+		return linalg.NewError(linalg.ErrParameter, "CholUpdate: diagonal of L not positive")
+	}
+	return nil
+}
+
+// Compute lower triangular L1 with L1*L1^T = L*L^T - x*x^T in place of lower
+// triangular n*n L. Fails with ErrNotPositiveDefinite and leaves L unchanged
+// if the result is not positive definite.
+func CholDowndate(L, x *matrix.FloatMatrix) error {
+	if err := checkCholUpdate("CholDowndate", L, x); err != nil {
+		return err
+	}
+	L0 := L.Copy()
+	if !cholRotate(L, x, -1.0) {
+		copyFloat(L, L0)
+		return linalg.NewError(linalg.ErrNotPositiveDefinite, "CholDowndate: result not positive definite")
+	}
+	return nil
+}
+
+func checkCholUpdate(name string, L, x *matrix.FloatMatrix) error {
+	n := L.Rows()
+	if L.Cols() != n {
+		return linalg.NewError(linalg.ErrShape, name+": L not square")
+	}
+	if x.NumElements() != n || (x.Rows() != 1 && x.Cols() != 1) {
+		return linalg.NewError(linalg.ErrShape, name+": x not vector of length n")
+	}
+	for _, v := range floatVector(x) {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return linalg.NewError(linalg.ErrParameter, name+": x not finite")
+		}
+	}
+	return nil
+}
+
+// Apply rank one update (sign 1) or downdate (sign -1) to lower triangular
+// L with hyperbolic or plane rotations. Returns false if a diagonal element
+// of the result is not positive; L is then partially updated.
+func cholRotate(L, x *matrix.FloatMatrix, sign float64) bool {
+	n := L.Rows()
+	lda := L.LeadingIndex()
+	Lr := L.FloatArray()
+	w := append([]float64(nil), floatVector(x)...)
+	for k := 0; k < n; k++ {
+		lkk := Lr[k*lda+k]
+		r2 := lkk*lkk + sign*w[k]*w[k]
+		if !(r2 > 0.0) || !(lkk > 0.0) {
+			return false
+		}
+		r := math.Sqrt(r2)
+		c, s := r/lkk, w[k]/lkk
+		Lr[k*lda+k] = r
+		col := Lr[k*lda+k+1 : k*lda+n]
+		for i := range col {
+			col[i] = (col[i] + sign*s*w[k+1+i]) / c
+			w[k+1+i] = c*w[k+1+i] - s*col[i]
+		}
+	}
+	return true
+}
+
+// Copy elements of B to A of the same size.
+func copyFloat(A, B *matrix.FloatMatrix) {
+	for j := 0; j < A.Cols(); j++ {
+		for i := 0; i < A.Rows(); i++ {
+			A.SetAt(i, j, B.GetAt(i, j))
+		}
+	}
+}
+
+// Cholesky factor of an exponentially weighted second moment matrix
+//
+//	S(t) = lambda*S(t-1) + (1-lambda)*x(t)*x(t)^T,  S(0) = delta*I
+//
+// of a stream of samples x(t), maintained with rank one updates so that each
+// sample and each solve with S costs O(n^2). Samples are not centered;
+// subtract a running mean first for a covariance estimate.
+type EWCholesky struct {
+	lambda float64
+	L      *matrix.FloatMatrix
+	count  int
+}
+
+/*
+ Return new exponentially weighted Cholesky factor of size n with
+ forgetting factor lambda, 0 < lambda < 1.
+
+ OPTIONS
+  delta     float; initial S = delta*I, which regularizes the estimate
+            until enough samples have been seen. Default 1e-8.
+*/
+func NewEWCholesky(n int, lambda float64, opts ...linalg.Option) (*EWCholesky, error) {
+	if !(lambda > 0.0 && lambda < 1.0) {
+		return nil, linalg.NewError(linalg.ErrParameter, "NewEWCholesky: lambda not in (0, 1)")
+	}
+	delta := linalg.GetFloatOpt("delta", 1e-8, opts...)
+	if !(delta > 0.0) || n < 0 {
+		return nil, linalg.NewError(linalg.ErrParameter, "NewEWCholesky: delta or n")
+	}
+	L := matrix.FloatZeros(n, n)
+	for k := 0; k < n; k++ {
+		L.SetAt(k, k, math.Sqrt(delta))
+	}
+	return &EWCholesky{lambda: lambda, L: L}, nil
+}
+
+// Add sample x, a vector of length n. Fails with ErrParameter and leaves S
+// unchanged if x is not finite or the rotations break down.
+func (E *EWCholesky) Update(x *matrix.FloatMatrix) error {
+	if err := checkCholUpdate("Update", E.L, x); err != nil {
+		return err
+	}
+	// lambda*(L*L^T + y*y^T) with y = sqrt((1-lambda)/lambda)*x
+	L0 := E.L.Copy()
+	if !cholRotate(E.L, scaledVector(x, math.Sqrt((1.0-E.lambda)/E.lambda)), 1.0) {
+		copyFloat(E.L, L0)
+		return linalg.NewError(linalg.ErrParameter, "Update: rotation failed")
+	}
+	Lr := E.L.FloatArray()
+	sl := math.Sqrt(E.lambda)
+	for k := range Lr {
+		Lr[k] *= sl
+	}
+	E.count++
+	return nil
+}
+
+// Subtract w*x*x^T from S, eg. to remove a sample added k updates ago with
+// w = (1-lambda)*lambda^k. Fails with ErrNotPositiveDefinite and leaves S
+// unchanged if the result is not positive definite.
+func (E *EWCholesky) Downdate(x *matrix.FloatMatrix, w float64) error {
+	if err := checkCholUpdate("Downdate", E.L, x); err != nil {
+		return err
+	}
+	if w < 0.0 || math.IsNaN(w) {
+		return linalg.NewError(linalg.ErrParameter, "Downdate: negative weight")
+	}
+	return CholDowndate(E.L, scaledVector(x, math.Sqrt(w)))
+}
+
+// Return alpha*x as a new column vector.
+func scaledVector(x *matrix.FloatMatrix, alpha float64) *matrix.FloatMatrix {
+	y := append([]float64(nil), floatVector(x)...)
+	for k := range y {
+		y[k] *= alpha
+	}
+	return matrix.FloatVector(y)
+}
+
+// Return solution X of S*X = B as a new matrix. B has n rows.
+func (E *EWCholesky) Solve(B *matrix.FloatMatrix) (*matrix.FloatMatrix, error) {
+	n := E.L.Rows()
+	if B.Rows() != n {
+		return nil, linalg.NewError(linalg.ErrShape, "Solve: B.Rows() != n")
+	}
+	X := B.Copy()
+	for j := 0; j < X.Cols(); j++ {
+		// L*y = b, then L^T*x = y
+		for i := 0; i < n; i++ {
+			s := X.GetAt(i, j)
+			for k := 0; k < i; k++ {
+				s -= E.L.GetAt(i, k) * X.GetAt(k, j)
+			}
+			X.SetAt(i, j, s/E.L.GetAt(i, i))
+		}
+		for i := n - 1; i >= 0; i-- {
+			s := X.GetAt(i, j)
+			for k := i + 1; k < n; k++ {
+				s -= E.L.GetAt(k, i) * X.GetAt(k, j)
+			}
+			X.SetAt(i, j, s/E.L.GetAt(i, i))
+		}
+	}
+	return X, nil
+}
+
+// Return copy of lower triangular factor L of S = L*L^T.
+func (E *EWCholesky) Factor() *matrix.FloatMatrix {
+	return E.L.Copy()
+}
+
+// Return S = L*L^T as a new matrix.
+func (E *EWCholesky) Matrix() *matrix.FloatMatrix {
+	n := E.L.Rows()
+	S := matrix.FloatZeros(n, n)
+	for j := 0; j < n; j++ {
+		for i := j; i < n; i++ {
+			s := 0.0
+			for k := 0; k <= j; k++ {
+				s += E.L.GetAt(i, k) * E.L.GetAt(j, k)
+			}
+			S.SetAt(i, j, s)
+			S.SetAt(j, i, s)
+		}
+	}
+	return S
+}
+
+// Return log(det(S)).
+func (E *EWCholesky) LogDet() float64 {
+	s := 0.0
+	for k := 0; k < E.L.Rows(); k++ {
+		s += math.Log(E.L.GetAt(k, k))
+	}
+	return 2.0 * s
+}
+
+// Return number of samples added with Update.
+func (E *EWCholesky) Count() int {
+	return E.count
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
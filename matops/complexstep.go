@@ -0,0 +1,150 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Default step of complex-step differentiation. Truncation error is of
+// order h^2*f''' and there is no subtractive cancellation, so any h small
+// enough that h^2 is below machine precision gives full accuracy.
+const ComplexStepSize = 1e-20
+
+/*
+ Vector function of a column vector for complex-step differentiation.
+
+ F is evaluated at complex points x + i*h*e_j and must be the analytic
+ extension of a real function: written with +, -, *, / and analytic
+ functions such as cmplx.Exp, cmplx.Sin or cmplx.Sqrt. Functions that
+ are not analytic, such as cmplx.Abs, cmplx.Conj or comparisons of real
+ parts, give wrong derivatives. Abs(x) may be replaced by x or -x as the
+ sign of real(x) indicates.
+*/
+type ComplexFunc func(x *matrix.ComplexMatrix) (*matrix.ComplexMatrix, error)
+
+/*
+ Compute value and Jacobian of real function f at x by complex-step
+ differentiation.
+
+ For n by 1 x and f returning an m by 1 vector, returns fx = f(x) and the m
+ by n Jacobian J with
+
+  J[:,j] = Im(f(x + i*h*e_j)) / h
+
+ which is accurate to machine precision, unlike finite differences, since
+ no difference of nearby values is formed. Costs n+1 evaluations of f in
+ complex arithmetic.
+
+ OPTIONS
+  step     float; step h.  Default ComplexStepSize.
+*/
+func ComplexStepJacobian(f ComplexFunc, x *matrix.FloatMatrix, opts ...linalg.Option) (fx, J *matrix.FloatMatrix, err error) {
+	h := linalg.GetFloatOpt("step", ComplexStepSize, opts...)
+	if !(h > 0.0) || math.IsInf(h, 0) {
+		return nil, nil, linalg.NewError(linalg.ErrParameter,
+			fmt.Sprintf("ComplexStepJacobian: step %g not positive", h))
+	}
+	if x.Cols() != 1 {
+		return nil, nil, linalg.NewError(linalg.ErrShape, "ComplexStepJacobian: x not a column vector")
+	}
+	n := x.Rows()
+	xc := matrix.ComplexZeros(n, 1)
+	for i := 0; i < n; i++ {
+		xc.SetAt(i, 0, complex(x.GetAt(i, 0), 0))
+	}
+	y, err := evalComplexFunc(f, xc, -1)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := y.Rows()
+	fx = RealPart(y)
+	J = matrix.FloatZeros(m, n)
+	for j := 0; j < n; j++ {
+		xc.SetAt(j, 0, complex(x.GetAt(j, 0), h))
+		y, err = evalComplexFunc(f, xc, m)
+		xc.SetAt(j, 0, complex(x.GetAt(j, 0), 0))
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := 0; i < m; i++ {
+			J.SetAt(i, j, imag(y.GetAt(i, 0))/h)
+		}
+	}
+	return fx, J, nil
+}
+
+// Compute value and gradient of real scalar function f at n by 1 x by
+// complex-step differentiation. Returns f(x) and the n by 1 gradient.
+// Options as for ComplexStepJacobian.
+func ComplexStepGradient(f func(x *matrix.ComplexMatrix) (complex128, error), x *matrix.FloatMatrix,
+	opts ...linalg.Option) (float64, *matrix.FloatMatrix, error) {
+	F := func(x *matrix.ComplexMatrix) (*matrix.ComplexMatrix, error) {
+		v, err := f(x)
+		if err != nil {
+			return nil, err
+		}
+		return matrix.ComplexNew(1, 1, []complex128{v}), nil
+	}
+	fx, J, err := ComplexStepJacobian(F, x, opts...)
+	if err != nil {
+		return 0.0, nil, err
+	}
+	return fx.GetAt(0, 0), J.Transpose(), nil
+}
+
+// Return real function that computes value and Jacobian of f with
+// ComplexStepJacobian, for solvers that require derivatives of a function
+// they are given. Options are passed to ComplexStepJacobian.
+func ComplexStepFunc(f ComplexFunc, opts ...linalg.Option) func(x *matrix.FloatMatrix) (fx, J *matrix.FloatMatrix, err error) {
+	return func(x *matrix.FloatMatrix) (*matrix.FloatMatrix, *matrix.FloatMatrix, error) {
+		return ComplexStepJacobian(f, x, opts...)
+	}
+}
+
+// Return real parts of elements of A.
+func RealPart(A *matrix.ComplexMatrix) *matrix.FloatMatrix {
+	R := matrix.FloatZeros(A.Rows(), A.Cols())
+	for j := 0; j < A.Cols(); j++ {
+		for i := 0; i < A.Rows(); i++ {
+			R.SetAt(i, j, real(A.GetAt(i, j)))
+		}
+	}
+	return R
+}
+
+// Return imaginary parts of elements of A divided by h, the derivatives
+// of a complex-step evaluation with step h.
+func ImagScaled(A *matrix.ComplexMatrix, h float64) *matrix.FloatMatrix {
+	R := matrix.FloatZeros(A.Rows(), A.Cols())
+	for j := 0; j < A.Cols(); j++ {
+		for i := 0; i < A.Rows(); i++ {
+			R.SetAt(i, j, imag(A.GetAt(i, j))/h)
+		}
+	}
+	return R
+}
+
+// Evaluate f at x and check that it returns a column vector of m rows, or
+// of any length if m is negative.
+func evalComplexFunc(f ComplexFunc, x *matrix.ComplexMatrix, m int) (*matrix.ComplexMatrix, error) {
+	y, err := f(x)
+	if err != nil {
+		return nil, err
+	}
+	if y == nil || y.Cols() != 1 || (m >= 0 && y.Rows() != m) {
+		return nil, linalg.NewError(linalg.ErrShape, "ComplexStepJacobian: f did not return a column vector of fixed length")
+	}
+	return y, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,139 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Return n*n matrix with ones on k'th diagonal and zeros elsewhere. Diagonal
+// k > 0 is above and k < 0 below the main diagonal; Eye(n, 1) is the shift
+// matrix and Eye(n, 0) - Eye(n, 1) a forward difference operator. Result is
+// zero if |k| >= n.
+func Eye(n, k int) *matrix.FloatMatrix {
+	A := matrix.FloatZeros(n, n)
+	Ar := A.FloatArray()
+	for j := max(0, k); j < n && j-k < n; j++ {
+		Ar[j*n+j-k] = 1.0
+	}
+	return A
+}
+
+// Return column vector of num evenly spaced values from start to stop
+// inclusive. The last element is exactly stop.
+func Linspace(start, stop float64, num int) (*matrix.FloatMatrix, error) {
+	if num < 0 {
+		return nil, linalg.NewError(linalg.ErrParameter, "Linspace: negative number of points")
+	}
+	v := make([]float64, num)
+	step := 0.0
+	if num > 1 {
+		step = (stop - start) / float64(num-1)
+	}
+	for k := range v {
+		v[k] = start + float64(k)*step
+	}
+	if num > 1 {
+		v[num-1] = stop
+	}
+	return matrix.FloatVector(v), nil
+}
+
+// Return column vector of values start, start+step, ... up to but not
+// including stop.
+func Arange(start, stop, step float64) (*matrix.FloatMatrix, error) {
+	if step == 0.0 || math.IsNaN(step) {
+		return nil, linalg.NewError(linalg.ErrParameter, "Arange: zero step")
+	}
+	n := int(math.Ceil((stop - start) / step))
+	if n < 0 {
+		n = 0
+	}
+	v := make([]float64, n)
+	for k := range v {
+		v[k] = start + float64(k)*step
+	}
+	return matrix.FloatVector(v), nil
+}
+
+// Return new rows*cols matrix with element (i, j) equal to f(i, j). Columns
+// are computed concurrently as in Apply if option workers is given; f must
+// then be safe for concurrent use.
+//
+// OPTIONS
+//  workers  number of goroutines; see Apply.
+func FloatFromFunc(rows, cols int, f func(i, j int) float64, opts ...linalg.Option) *matrix.FloatMatrix {
+	A := matrix.FloatZeros(rows, cols)
+	Ar := A.FloatArray()
+	parallelCols(A, func(j0, j1 int) {
+		for j := j0; j < j1; j++ {
+			for i := 0; i < rows; i++ {
+				Ar[j*rows+i] = f(i, j)
+			}
+		}
+	}, opts...)
+	return A
+}
+
+// Return new rows*cols complex matrix with element (i, j) equal to f(i, j).
+// See FloatFromFunc.
+func ComplexFromFunc(rows, cols int, f func(i, j int) complex128, opts ...linalg.Option) *matrix.ComplexMatrix {
+	A := matrix.ComplexZeros(rows, cols)
+	Ar := A.ComplexArray()
+	parallelCols(A, func(j0, j1 int) {
+		for j := j0; j < j1; j++ {
+			for i := 0; i < rows; i++ {
+				Ar[j*rows+i] = f(i, j)
+			}
+		}
+	}, opts...)
+	return A
+}
+
+// Return new matrix with rows taken from a [][]float64 in row-major order.
+// Elements are copied; later changes to rows are not seen in the result.
+// All rows must have the same length.
+func FromSlices(rows [][]float64) (*matrix.FloatMatrix, error) {
+	m := len(rows)
+	n := 0
+	if m > 0 {
+		n = len(rows[0])
+	}
+	A := matrix.FloatZeros(m, n)
+	Ar := A.FloatArray()
+	for i, row := range rows {
+		if len(row) != n {
+			return nil, linalg.NewError(linalg.ErrShape, "FromSlices: rows of unequal length")
+		}
+		for j, v := range row {
+			Ar[j*m+i] = v
+		}
+	}
+	return A, nil
+}
+
+// Return elements of A as a row-major [][]float64. Elements are copied into
+// a single new backing array shared by the rows; writes to the result do
+// not change A.
+func ToSlices(A *matrix.FloatMatrix) [][]float64 {
+	m, n := A.Rows(), A.Cols()
+	data := make([]float64, m*n)
+	rows := make([][]float64, m)
+	for i := range rows {
+		rows[i] = data[i*n : (i+1)*n : (i+1)*n]
+		for j := range rows[i] {
+			rows[i][j] = A.GetAt(i, j)
+		}
+	}
+	return rows
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
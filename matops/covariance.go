@@ -0,0 +1,93 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+)
+
+// Column means and covariance of observations added one row at a time,
+// for data sets read in a single pass, e.g. with a matio.RowReader.
+// Updates use Welford's algorithm, which avoids the cancellation of the
+// textbook sum of squares formula. Memory use is O(p^2) for p variables
+// regardless of the number of observations.
+type Covariance struct {
+	n    int
+	mean []float64
+	m2   []float64 // upper triangle of sum of centered products, row-major
+	diff []float64
+}
+
+// Return accumulator for observations of p variables.
+func NewCovariance(p int) *Covariance {
+	return &Covariance{
+		mean: make([]float64, p),
+		m2:   make([]float64, p*p),
+		diff: make([]float64, p),
+	}
+}
+
+// Add observation x[:p]. The slice is not retained, so that it can be
+// reused by the caller.
+func (C *Covariance) Add(x []float64) error {
+	p := len(C.mean)
+	if len(x) < p {
+		return linalg.NewError(linalg.ErrShape, "Covariance: observation too short")
+	}
+	C.n++
+	w := 1.0 / float64(C.n)
+	for j := 0; j < p; j++ {
+		C.diff[j] = x[j] - C.mean[j]
+		C.mean[j] += C.diff[j] * w
+	}
+	// m2 += (x - mean_old)*(x - mean_new)^T
+	for i := 0; i < p; i++ {
+		di := C.diff[i]
+		for j := i; j < p; j++ {
+			C.m2[i*p+j] += di * (x[j] - C.mean[j])
+		}
+	}
+	return nil
+}
+
+// Return number of observations added.
+func (C *Covariance) Count() int {
+	return C.n
+}
+
+// Return column means as a p by 1 matrix.
+func (C *Covariance) Mean() *matrix.FloatMatrix {
+	return matrix.FloatVector(append([]float64(nil), C.mean...))
+}
+
+// Return p by p covariance matrix, normalized by n-1 (the unbiased sample
+// covariance) or, with population set, by n. Returns zeros if fewer than
+// two observations were added.
+func (C *Covariance) Matrix(population bool) *matrix.FloatMatrix {
+	p := len(C.mean)
+	S := matrix.FloatZeros(p, p)
+	d := float64(C.n - 1)
+	if population {
+		d = float64(C.n)
+	}
+	if C.n < 2 {
+		return S
+	}
+	for i := 0; i < p; i++ {
+		for j := i; j < p; j++ {
+			v := C.m2[i*p+j] / d
+			S.SetAt(i, j, v)
+			S.SetAt(j, i, v)
+		}
+	}
+	return S
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,164 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Start offset and length of k'th diagonal of m by n matrix with leading
+// index lda. Diagonal k > 0 is above and k < 0 below the main diagonal.
+func diagRange(m, n, lda, k int) (start, length int) {
+	if k >= 0 {
+		return k * lda, max(0, min(m, n-k))
+	}
+	return -k, max(0, min(m+k, n))
+}
+
+// Return k'th diagonal of A as a new column vector.
+func GetDiag(A matrix.Matrix, k int) (matrix.Matrix, error) {
+	m, n := A.Size()
+	lda := A.LeadingIndex()
+	start, nd := diagRange(m, n, lda, k)
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		d := make([]float64, nd)
+		for i := range d {
+			d[i] = Ar[start+i*(lda+1)]
+		}
+		return matrix.FloatVector(d), nil
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		d := make([]complex128, nd)
+		for i := range d {
+			d[i] = Ar[start+i*(lda+1)]
+		}
+		return matrix.ComplexVector(d), nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "GetDiag: unknown matrix type")
+}
+
+// Set k'th diagonal of A to elements of vector v. Length of v must equal
+// the length of the diagonal.
+func SetDiag(A matrix.Matrix, k int, v matrix.Matrix) error {
+	m, n := A.Size()
+	lda := A.LeadingIndex()
+	start, nd := diagRange(m, n, lda, k)
+	if v.NumElements() != nd {
+		return linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("SetDiag: vector length %d, diagonal %d has %d elements", v.NumElements(), k, nd))
+	}
+	if !matrix.EqualTypes(A, v) {
+		return linalg.NewError(linalg.ErrType, "SetDiag: arguments not of same type")
+	}
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		for i, x := range floatVector(v.(*matrix.FloatMatrix)) {
+			Ar[start+i*(lda+1)] = x
+		}
+		return nil
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		for i, x := range complexVector(v.(*matrix.ComplexMatrix)) {
+			Ar[start+i*(lda+1)] = x
+		}
+		return nil
+	}
+	return linalg.NewError(linalg.ErrType, "SetDiag: unknown matrix type")
+}
+
+// Compute A := A + alpha*I, ie. add alpha to main diagonal of A.
+func AddDiag(A matrix.Matrix, alpha matrix.Scalar) error {
+	m, n := A.Size()
+	lda := A.LeadingIndex()
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		aval := alpha.Float()
+		if math.IsNaN(aval) {
+			return linalg.NewError(linalg.ErrParameter, "AddDiag: alpha not a real number")
+		}
+		for i := 0; i < min(m, n); i++ {
+			Ar[i*(lda+1)] += aval
+		}
+		return nil
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		aval := alpha.Complex()
+		for i := 0; i < min(m, n); i++ {
+			Ar[i*(lda+1)] += aval
+		}
+		return nil
+	}
+	return linalg.NewError(linalg.ErrType, "AddDiag: unknown matrix type")
+}
+
+// Return new matrix with elements of A within kl subdiagonals and ku
+// superdiagonals; elements outside the band are zero.
+func ExtractBand(A matrix.Matrix, kl, ku int) (matrix.Matrix, error) {
+	if kl < 0 || ku < 0 {
+		return nil, linalg.NewError(linalg.ErrParameter, "ExtractBand: negative kl or ku")
+	}
+	L, err := Tril(A, ku)
+	if err != nil {
+		return nil, err
+	}
+	return Triu(L, -kl)
+}
+
+// Return band of A in BLAS band storage: a (kl+ku+1) by n matrix with
+// element A[i,j] stored at row ku+i-j of column j, as used by Gbmv.
+func BandStorage(A matrix.Matrix, kl, ku int) (matrix.Matrix, error) {
+	return bandStorage(A, kl, ku, 0)
+}
+
+// Return band of A in LAPACK factorization band storage: a (2*kl+ku+1) by n
+// matrix with kl extra rows on top for fill-in, as required by Gbtrf and Gbsv.
+func BandStorageLU(A matrix.Matrix, kl, ku int) (matrix.Matrix, error) {
+	return bandStorage(A, kl, ku, kl)
+}
+
+func bandStorage(A matrix.Matrix, kl, ku, extra int) (matrix.Matrix, error) {
+	if kl < 0 || ku < 0 {
+		return nil, linalg.NewError(linalg.ErrParameter, "BandStorage: negative kl or ku")
+	}
+	m, n := A.Size()
+	lda := A.LeadingIndex()
+	ldb := kl + ku + 1 + extra
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		B := matrix.FloatZeros(ldb, n)
+		Br := B.FloatArray()
+		for j := 0; j < n; j++ {
+			for i := max(0, j-ku); i < min(m, j+kl+1); i++ {
+				Br[j*ldb+extra+ku+i-j] = Ar[j*lda+i]
+			}
+		}
+		return B, nil
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		B := matrix.ComplexZeros(ldb, n)
+		Br := B.ComplexArray()
+		for j := 0; j < n; j++ {
+			for i := max(0, j-ku); i < min(m, j+kl+1); i++ {
+				Br[j*ldb+extra+ku+i-j] = Ar[j*lda+i]
+			}
+		}
+		return B, nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "BandStorage: unknown matrix type")
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
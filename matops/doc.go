@@ -0,0 +1,16 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+// Helper operations on column-major float and complex matrices.
+//
+// This package collects construction, reshaping and element-wise helpers
+// that operate on matrices from github.com/nvcook42/matrix without calling
+// the BLAS or LAPACK libraries.
+//
+// Unless noted otherwise functions return a new matrix and leave their
+// arguments unmodified. Functions that work in-place are named with
+// suffix InPlace or document the argument that is overwritten.
+package matops
@@ -0,0 +1,115 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+)
+
+const (
+	opMul = iota
+	opDiv
+)
+
+// Return element-wise (Hadamard) product A .* B as a new matrix.
+func MulElem(A, B matrix.Matrix) (matrix.Matrix, error) {
+	C := A.MakeCopy()
+	if err := elemOp(opMul, C, B); err != nil {
+		return nil, err
+	}
+	return C, nil
+}
+
+// Compute A := A .* B.
+func MulElemInPlace(A, B matrix.Matrix) error {
+	return elemOp(opMul, A, B)
+}
+
+// Return element-wise quotient A ./ B as a new matrix.
+func DivElem(A, B matrix.Matrix) (matrix.Matrix, error) {
+	C := A.MakeCopy()
+	if err := elemOp(opDiv, C, B); err != nil {
+		return nil, err
+	}
+	return C, nil
+}
+
+// Compute A := A ./ B.
+func DivElemInPlace(A, B matrix.Matrix) error {
+	return elemOp(opDiv, A, B)
+}
+
+func elemOp(op int, A, B matrix.Matrix) error {
+	if A.Rows() != B.Rows() || A.Cols() != B.Cols() {
+		return linalg.NewError(linalg.ErrShape, "dimensions do not match")
+	}
+	if !matrix.EqualTypes(A, B) {
+		return linalg.NewError(linalg.ErrType, "arguments not of same type")
+	}
+	m, n := A.Size()
+	lda := A.LeadingIndex()
+	ldb := B.LeadingIndex()
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		Br := B.(*matrix.FloatMatrix).FloatArray()
+		if lda == m && ldb == m {
+			// contiguous storage, single pass
+			floatKernel(op, Ar[:m*n], Br[:m*n])
+			return nil
+		}
+		for j := 0; j < n; j++ {
+			floatKernel(op, Ar[j*lda:j*lda+m], Br[j*ldb:j*ldb+m])
+		}
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		Br := B.(*matrix.ComplexMatrix).ComplexArray()
+		if lda == m && ldb == m {
+			complexKernel(op, Ar[:m*n], Br[:m*n])
+			return nil
+		}
+		for j := 0; j < n; j++ {
+			complexKernel(op, Ar[j*lda:j*lda+m], Br[j*ldb:j*ldb+m])
+		}
+	default:
+		return linalg.NewError(linalg.ErrType, "unknown matrix type")
+	}
+	return nil
+}
+
+func floatKernel(op int, x, y []float64) {
+	y = y[:len(x)]
+	switch op {
+	case opMul:
+		for i := range x {
+			x[i] *= y[i]
+		}
+	case opDiv:
+		for i := range x {
+			x[i] /= y[i]
+		}
+	}
+}
+
+func complexKernel(op int, x, y []complex128) {
+	y = y[:len(x)]
+	switch op {
+	case opMul:
+		for i := range x {
+			x[i] *= y[i]
+		}
+	case opDiv:
+		for i := range x {
+			x[i] /= y[i]
+		}
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
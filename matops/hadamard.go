@@ -0,0 +1,180 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Row orderings of the Walsh-Hadamard transform, values of option order.
+const (
+	// Natural (Hadamard) order of the Sylvester construction,
+	// H(2n) = [H(n) H(n); H(n) -H(n)].
+	OrderNatural = "natural"
+	// Sequency (Walsh) order, row k has k sign changes.
+	OrderSequency = "sequency"
+)
+
+/*
+ Fast Walsh-Hadamard transform of the columns of X in place.
+
+ PURPOSE
+ Computes X := H*X with H the n by n Hadamard matrix of elements +1 and
+ -1, n = X.Rows() a power of two, in n*log2(n) additions per column. H is
+ symmetric and H*H = n*I; with option normalize H is scaled by 1/sqrt(n)
+ and is orthogonal, so the transform is its own inverse.
+
+ OPTIONS
+  normalize  bool; scale by 1/sqrt(n).  Default false.
+  order      string; OrderNatural (default) or OrderSequency.
+*/
+func FWHT(X matrix.Matrix, opts ...linalg.Option) error {
+	n := X.Rows()
+	if n&(n-1) != 0 {
+		return linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("FWHT: %d rows, not a power of two", n))
+	}
+	order := linalg.GetStringOpt("order", OrderNatural, opts...)
+	if order != OrderNatural && order != OrderSequency {
+		return linalg.NewError(linalg.ErrParameter, "FWHT: unknown order "+order)
+	}
+	if n == 0 {
+		return nil
+	}
+	scale := 1.0
+	if linalg.GetBoolOpt("normalize", false, opts...) {
+		scale = 1.0 / math.Sqrt(float64(n))
+	}
+	var perm []int
+	if order == OrderSequency {
+		perm = sequencyPerm(n)
+	}
+	ld := X.LeadingIndex()
+	switch Xm := X.(type) {
+	case *matrix.FloatMatrix:
+		xr := Xm.FloatArray()
+		t := make([]float64, n)
+		for j := 0; j < X.Cols(); j++ {
+			x := xr[j*ld : j*ld+n]
+			for h := 1; h < n; h *= 2 {
+				for i := 0; i < n; i += 2 * h {
+					for k := i; k < i+h; k++ {
+						x[k], x[k+h] = x[k]+x[k+h], x[k]-x[k+h]
+					}
+				}
+			}
+			copy(t, x)
+			for k := range x {
+				if perm != nil {
+					x[k] = t[perm[k]] * scale
+				} else {
+					x[k] = t[k] * scale
+				}
+			}
+		}
+	case *matrix.ComplexMatrix:
+		xr := Xm.ComplexArray()
+		t := make([]complex128, n)
+		cs := complex(scale, 0)
+		for j := 0; j < X.Cols(); j++ {
+			x := xr[j*ld : j*ld+n]
+			for h := 1; h < n; h *= 2 {
+				for i := 0; i < n; i += 2 * h {
+					for k := i; k < i+h; k++ {
+						x[k], x[k+h] = x[k]+x[k+h], x[k]-x[k+h]
+					}
+				}
+			}
+			copy(t, x)
+			for k := range x {
+				if perm != nil {
+					x[k] = t[perm[k]] * cs
+				} else {
+					x[k] = t[k] * cs
+				}
+			}
+		}
+	default:
+		return linalg.NewError(linalg.ErrType, "FWHT: unknown type")
+	}
+	return nil
+}
+
+// Return natural order row of sequency order row k of the n by n Walsh
+// matrix, the bit reversal of the Gray code of k.
+func sequencyPerm(n int) []int {
+	bits := 0
+	for 1<<uint(bits) < n {
+		bits++
+	}
+	p := make([]int, n)
+	for k := range p {
+		g := k ^ (k >> 1)
+		r := 0
+		for b := 0; b < bits; b++ {
+			r = r<<1 | (g>>uint(b))&1
+		}
+		p[k] = r
+	}
+	return p
+}
+
+// Walsh-Hadamard transform of order N as a LinearOperator; see FWHT for
+// the meaning of Normalize and Order. The matrix is symmetric in either
+// order, so Apply ignores trans.
+type WalshHadamard struct {
+	N         int
+	Normalize bool
+	Order     string
+}
+
+// Return Walsh-Hadamard operator of order n, a power of two.
+func NewWalshHadamard(n int, normalize bool, order string) (*WalshHadamard, error) {
+	if n < 1 || n&(n-1) != 0 {
+		return nil, linalg.NewError(linalg.ErrParameter,
+			fmt.Sprintf("WalshHadamard: order %d not a power of two", n))
+	}
+	if order != OrderNatural && order != OrderSequency {
+		return nil, linalg.NewError(linalg.ErrParameter, "WalshHadamard: unknown order "+order)
+	}
+	return &WalshHadamard{n, normalize, order}, nil
+}
+
+func (W *WalshHadamard) Size() (int, int) {
+	return W.N, W.N
+}
+
+// Return new matrix W*X.
+func (W *WalshHadamard) Apply(X matrix.Matrix, trans bool) (matrix.Matrix, error) {
+	if X.Rows() != W.N {
+		return nil, linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("WalshHadamard: X has %d rows, want %d", X.Rows(), W.N))
+	}
+	Y := X.MakeCopy()
+	err := FWHT(Y, linalg.BoolOpt("normalize", W.Normalize),
+		linalg.StringOpt("order", W.Order))
+	if err != nil {
+		return nil, err
+	}
+	return Y, nil
+}
+
+// Return the n by n Hadamard matrix in natural order, n a power of two.
+func Hadamard(n int) (*matrix.FloatMatrix, error) {
+	W, err := NewWalshHadamard(n, false, OrderNatural)
+	if err != nil {
+		return nil, err
+	}
+	return OperatorMatrix(W)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
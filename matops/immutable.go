@@ -0,0 +1,132 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"sync/atomic"
+)
+
+// Alias so that the embedded field below is unexported.
+type wrappedMatrix = matrix.Matrix
+
+// ReadOnly is a view of a matrix that functions in blas and lapack accept
+// as input but refuse as output with an error wrapping linalg.ErrReadOnly.
+// The view itself gives no access to the underlying matrix except through
+// the matrix.Matrix interface, so it can be handed to other goroutines
+// while the owner keeps the only writable reference.
+type ReadOnly struct {
+	wrappedMatrix
+}
+
+// Return read-only view of A. A view of a view is the view itself.
+func NewReadOnly(A matrix.Matrix) *ReadOnly {
+	if r, ok := A.(*ReadOnly); ok {
+		return r
+	}
+	return &ReadOnly{A}
+}
+
+// Return element (i, j) of the view; real part for complex matrices.
+func (r *ReadOnly) FloatAt(i, j int) float64 {
+	if A, ok := r.wrappedMatrix.(*matrix.FloatMatrix); ok {
+		return A.GetAt(i, j)
+	}
+	return real(r.ComplexAt(i, j))
+}
+
+// Return element (i, j) of the view as complex number.
+func (r *ReadOnly) ComplexAt(i, j int) complex128 {
+	switch A := r.wrappedMatrix.(type) {
+	case *matrix.FloatMatrix:
+		return complex(A.GetAt(i, j), 0)
+	case *matrix.ComplexMatrix:
+		return A.GetAt(i, j)
+	}
+	return 0
+}
+
+// Return the matrix behind a read-only view for reading; other matrices are
+// returned as is. For use by routines that only read their argument.
+func Readable(A matrix.Matrix) matrix.Matrix {
+	if r, ok := A.(*ReadOnly); ok {
+		return r.wrappedMatrix
+	}
+	return A
+}
+
+// Test if any of the matrices is a read-only view. Nil arguments are ignored.
+func IsReadOnly(mats ...matrix.Matrix) bool {
+	for _, A := range mats {
+		if _, ok := A.(*ReadOnly); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Return error wrapping linalg.ErrReadOnly if any of the matrices is a
+// read-only view.
+func Writable(name string, mats ...matrix.Matrix) error {
+	if IsReadOnly(mats...) {
+		return linalg.NewError(linalg.ErrReadOnly, name+": output argument is read-only")
+	}
+	return nil
+}
+
+type cowData struct {
+	m    matrix.Matrix
+	refs int32
+}
+
+// COW is a copy-on-write handle to a matrix. Clones share storage until one
+// of them asks for a writable matrix, at which point that handle gets a
+// private copy. Each handle must be used by one goroutine at a time; give
+// other goroutines their own clones.
+type COW struct {
+	d *cowData
+}
+
+// Return copy-on-write handle owning A. A must not be used directly after
+// the call.
+func NewCOW(A matrix.Matrix) *COW {
+	return &COW{&cowData{m: A, refs: 1}}
+}
+
+// Return new handle sharing storage with c.
+func (c *COW) Clone() *COW {
+	atomic.AddInt32(&c.d.refs, 1)
+	return &COW{c.d}
+}
+
+// Return read-only view of current contents.
+func (c *COW) View() *ReadOnly {
+	return NewReadOnly(c.d.m)
+}
+
+// Return matrix that may be modified without affecting other handles,
+// copying it first if storage is shared.
+func (c *COW) Mutable() matrix.Matrix {
+	if atomic.LoadInt32(&c.d.refs) == 1 {
+		return c.d.m
+	}
+	d := &cowData{m: c.d.m.MakeCopy(), refs: 1}
+	atomic.AddInt32(&c.d.refs, -1)
+	c.d = d
+	return d.m
+}
+
+// Detach handle from shared storage. The handle must not be used afterwards.
+func (c *COW) Release() {
+	atomic.AddInt32(&c.d.refs, -1)
+	c.d = nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
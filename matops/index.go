@@ -0,0 +1,208 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"math/cmplx"
+)
+
+// Return new matrix with rows idx[0], idx[1], ... of A. Indexes may repeat
+// and appear in any order, so SelectRows(A, rand.Perm(A.Rows())) shuffles
+// the rows of A.
+func SelectRows(A matrix.Matrix, idx []int) (matrix.Matrix, error) {
+	if err := checkIndexes("SelectRows", idx, A.Rows()); err != nil {
+		return nil, err
+	}
+	n := A.Cols()
+	lda := A.LeadingIndex()
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		C := matrix.FloatZeros(len(idx), n)
+		Cr := C.FloatArray()
+		for j := 0; j < n; j++ {
+			for k, i := range idx {
+				Cr[j*len(idx)+k] = Ar[j*lda+i]
+			}
+		}
+		return C, nil
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		C := matrix.ComplexZeros(len(idx), n)
+		Cr := C.ComplexArray()
+		for j := 0; j < n; j++ {
+			for k, i := range idx {
+				Cr[j*len(idx)+k] = Ar[j*lda+i]
+			}
+		}
+		return C, nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "SelectRows: unknown matrix type")
+}
+
+// Return new matrix with columns idx[0], idx[1], ... of A.
+func SelectCols(A matrix.Matrix, idx []int) (matrix.Matrix, error) {
+	if err := checkIndexes("SelectCols", idx, A.Cols()); err != nil {
+		return nil, err
+	}
+	m := A.Rows()
+	lda := A.LeadingIndex()
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		C := matrix.FloatZeros(m, len(idx))
+		Cr := C.FloatArray()
+		for k, j := range idx {
+			copy(Cr[k*m:(k+1)*m], Ar[j*lda:j*lda+m])
+		}
+		return C, nil
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		C := matrix.ComplexZeros(m, len(idx))
+		Cr := C.ComplexArray()
+		for k, j := range idx {
+			copy(Cr[k*m:(k+1)*m], Ar[j*lda:j*lda+m])
+		}
+		return C, nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "SelectCols: unknown matrix type")
+}
+
+// Return mask with element k true if pred is true for element k of A.
+// Mask is in column-major order and has A.NumElements() elements.
+func Mask(A *matrix.FloatMatrix, pred func(float64) bool) []bool {
+	m, n := A.Size()
+	lda := A.LeadingIndex()
+	Ar := A.FloatArray()
+	mask := make([]bool, m*n)
+	for j := 0; j < n; j++ {
+		for i := 0; i < m; i++ {
+			mask[j*m+i] = pred(Ar[j*lda+i])
+		}
+	}
+	return mask
+}
+
+// Set elements of A where mask is true to value. Mask is in column-major
+// order and must have A.NumElements() elements. For float matrices value
+// must be real.
+func MaskedSet(A matrix.Matrix, mask []bool, value matrix.Scalar) error {
+	m, n := A.Size()
+	if len(mask) != m*n {
+		return linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("MaskedSet: mask length %d, expected %d", len(mask), m*n))
+	}
+	lda := A.LeadingIndex()
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		v := value.Float()
+		// NaN is a valid fill value; reject only genuinely complex values
+		if math.IsNaN(v) && !cmplx.IsNaN(value.Complex()) {
+			return linalg.NewError(linalg.ErrParameter, "MaskedSet: value not a real number")
+		}
+		for j := 0; j < n; j++ {
+			for i := 0; i < m; i++ {
+				if mask[j*m+i] {
+					Ar[j*lda+i] = v
+				}
+			}
+		}
+		return nil
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		v := value.Complex()
+		for j := 0; j < n; j++ {
+			for i := 0; i < m; i++ {
+				if mask[j*m+i] {
+					Ar[j*lda+i] = v
+				}
+			}
+		}
+		return nil
+	}
+	return linalg.NewError(linalg.ErrType, "MaskedSet: unknown matrix type")
+}
+
+// Return column vector of elements of A at column-major linear indexes
+// idx[0], idx[1], ...; element (i, j) has linear index j*A.Rows()+i.
+func Gather(A matrix.Matrix, idx []int) (matrix.Matrix, error) {
+	if err := checkIndexes("Gather", idx, A.NumElements()); err != nil {
+		return nil, err
+	}
+	m := A.Rows()
+	lda := A.LeadingIndex()
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		x := make([]float64, len(idx))
+		for k, l := range idx {
+			x[k] = Ar[(l/m)*lda+l%m]
+		}
+		return matrix.FloatVector(x), nil
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		x := make([]complex128, len(idx))
+		for k, l := range idx {
+			x[k] = Ar[(l/m)*lda+l%m]
+		}
+		return matrix.ComplexVector(x), nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "Gather: unknown matrix type")
+}
+
+// Store elements of vector x to A at column-major linear indexes idx; the
+// inverse of Gather. If an index repeats the last value is kept.
+func Scatter(A, x matrix.Matrix, idx []int) error {
+	if err := checkIndexes("Scatter", idx, A.NumElements()); err != nil {
+		return err
+	}
+	if x.NumElements() != len(idx) {
+		return linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("Scatter: %d values for %d indexes", x.NumElements(), len(idx)))
+	}
+	if !matrix.EqualTypes(A, x) {
+		return linalg.NewError(linalg.ErrType, "Scatter: arguments not of same type")
+	}
+	m := A.Rows()
+	lda := A.LeadingIndex()
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		xv := floatVector(x.(*matrix.FloatMatrix))
+		for k, l := range idx {
+			Ar[(l/m)*lda+l%m] = xv[k]
+		}
+		return nil
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		xv := complexVector(x.(*matrix.ComplexMatrix))
+		for k, l := range idx {
+			Ar[(l/m)*lda+l%m] = xv[k]
+		}
+		return nil
+	}
+	return linalg.NewError(linalg.ErrType, "Scatter: unknown matrix type")
+}
+
+func checkIndexes(name string, idx []int, n int) error {
+	for k, i := range idx {
+		if i < 0 || i >= n {
+			return linalg.NewError(linalg.ErrParameter,
+				fmt.Sprintf("%s: index %d at position %d out of range [0,%d)", name, i, k, n))
+		}
+	}
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,72 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+)
+
+// Return Kronecker product of A and B. If A is m*n and B is p*q result is
+// a mp*nq block matrix with block (i, j) equal to A(i,j)*B. A and B must be of
+// same type. See also blas.Kron for BLAS-backed version.
+func Kron(A, B matrix.Matrix) (matrix.Matrix, error) {
+	if !matrix.EqualTypes(A, B) {
+		return nil, linalg.NewError(linalg.ErrType, "Kron: arguments not of same type")
+	}
+	m, n := A.Size()
+	p, q := B.Size()
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		Br := B.(*matrix.FloatMatrix).FloatArray()
+		lda := A.LeadingIndex()
+		ldb := B.LeadingIndex()
+		C := matrix.FloatZeros(m*p, n*q)
+		Cr := C.FloatArray()
+		ldc := m * p
+		for j := 0; j < n; j++ {
+			for i := 0; i < m; i++ {
+				aij := Ar[j*lda+i]
+				for l := 0; l < q; l++ {
+					c := Cr[(j*q+l)*ldc+i*p:]
+					b := Br[l*ldb:]
+					for k := 0; k < p; k++ {
+						c[k] = aij * b[k]
+					}
+				}
+			}
+		}
+		return C, nil
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		Br := B.(*matrix.ComplexMatrix).ComplexArray()
+		lda := A.LeadingIndex()
+		ldb := B.LeadingIndex()
+		C := matrix.ComplexZeros(m*p, n*q)
+		Cr := C.ComplexArray()
+		ldc := m * p
+		for j := 0; j < n; j++ {
+			for i := 0; i < m; i++ {
+				aij := Ar[j*lda+i]
+				for l := 0; l < q; l++ {
+					c := Cr[(j*q+l)*ldc+i*p:]
+					b := Br[l*ldb:]
+					for k := 0; k < p; k++ {
+						c[k] = aij * b[k]
+					}
+				}
+			}
+		}
+		return C, nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "Kron: unknown matrix type")
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
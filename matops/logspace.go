@@ -0,0 +1,68 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+/*
+ Matrix product in the log domain.
+
+ Computes C[i,j] = log(sum_k exp(A[i,k] + B[k,j])), ie. log(exp(A)*exp(B)),
+ where A and B hold elementwise logarithms of nonnegative matrices such as
+ transition probabilities. Each sum is evaluated as a log-sum-exp shifted by
+ its largest term, so long chains of products neither underflow nor
+ overflow. Zero entries are represented by -Inf and propagate exactly.
+
+ OPTIONS
+  workers  number of goroutines; see Apply.
+*/
+func LogMatMul(A, B *matrix.FloatMatrix, opts ...linalg.Option) (*matrix.FloatMatrix, error) {
+	if A.Cols() != B.Rows() {
+		return nil, linalg.NewError(linalg.ErrShape, "LogMatMul: A.Cols() != B.Rows()")
+	}
+	m, n, p := A.Rows(), B.Cols(), A.Cols()
+	C := matrix.FloatZeros(m, n)
+	Cr := C.FloatArray()
+	parallelCols(C, func(j0, j1 int) {
+		terms := make([]float64, p)
+		for j := j0; j < j1; j++ {
+			for i := 0; i < m; i++ {
+				for k := 0; k < p; k++ {
+					terms[k] = A.GetAt(i, k) + B.GetAt(k, j)
+				}
+				Cr[j*m+i] = logSumExp(terms)
+			}
+		}
+	}, opts...)
+	return C, nil
+}
+
+// Return log(sum(exp(v))) without intermediate overflow or underflow.
+func logSumExp(v []float64) float64 {
+	vmax := math.Inf(-1)
+	for _, x := range v {
+		if x > vmax || math.IsNaN(x) {
+			vmax = x
+		}
+	}
+	if math.IsInf(vmax, 0) || math.IsNaN(vmax) {
+		return vmax
+	}
+	s := 0.0
+	for _, x := range v {
+		s += math.Exp(x - vmax)
+	}
+	return vmax + math.Log(s)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,148 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Pivoting strategies of LU, values of option pivoting.
+const (
+	PivotPartial  = "partial"
+	PivotRook     = "rook"
+	PivotComplete = "complete"
+)
+
+/*
+ LU factorization with partial, rook or complete pivoting in pure Go.
+
+ PURPOSE
+ Factors m by n A in place as
+
+  P*A*Q^T = L*U,  ie. A[P[i], Q[j]] = (L*U)[i, j],
+
+ where L is unit lower triangular (trapezoidal if m > n) and U upper
+ triangular (trapezoidal if m < n); on exit the strictly lower part of
+ A holds L and the upper part U. Returns the row permutation P and column
+ permutation Q, which apply to other matrices with P.ApplyRows and
+ Q.ApplyCols.
+
+ Partial pivoting chooses the largest element of the current column and
+ leaves Q the identity. Rook pivoting searches alternately along columns
+ and rows for an element that is largest in both its row and column; it
+ costs little more than partial pivoting in practice and bounds element
+ growth much better. Complete pivoting chooses the largest element of the
+ whole remaining submatrix at O(n^3) comparisons and reveals rank: the
+ trailing diagonal of U is zero for a rank deficient A.
+
+ If a zero pivot is met the factorization is completed and ErrSingular is
+ returned along with valid P, Q and factors, as with Getrf.
+
+ OPTIONS
+  pivoting  string; PivotPartial (default), PivotRook or PivotComplete.
+*/
+func LU(A *matrix.FloatMatrix, opts ...linalg.Option) (P, Q Permutation, err error) {
+	strategy := linalg.GetStringOpt("pivoting", PivotPartial, opts...)
+	if strategy != PivotPartial && strategy != PivotRook && strategy != PivotComplete {
+		return nil, nil, linalg.NewError(linalg.ErrParameter, "LU: unknown pivoting "+strategy)
+	}
+	m, n := A.Rows(), A.Cols()
+	lda := A.LeadingIndex()
+	a := A.FloatArray()
+	at := func(i, j int) float64 { return math.Abs(a[j*lda+i]) }
+	// row in [k, m) with largest element in column j
+	colMax := func(j, k int) int {
+		p := k
+		for i := k + 1; i < m; i++ {
+			if at(i, j) > at(p, j) {
+				p = i
+			}
+		}
+		return p
+	}
+	// column in [k, n) with largest element in row i
+	rowMax := func(i, k int) int {
+		q := k
+		for j := k + 1; j < n; j++ {
+			if at(i, j) > at(i, q) {
+				q = j
+			}
+		}
+		return q
+	}
+	P, Q = IdentityPermutation(m), IdentityPermutation(n)
+	zero := -1
+	for k := 0; k < min(m, n); k++ {
+		p, q := colMax(k, k), k
+		switch strategy {
+		case PivotRook:
+			for {
+				q1 := rowMax(p, k)
+				if at(p, q1) <= at(p, q) {
+					break
+				}
+				q = q1
+				p1 := colMax(q, k)
+				if at(p1, q) <= at(p, q) {
+					break
+				}
+				p = p1
+			}
+		case PivotComplete:
+			for j := k; j < n; j++ {
+				if i := colMax(j, k); at(i, j) > at(p, q) {
+					p, q = i, j
+				}
+			}
+		}
+		if p != k {
+			for j := 0; j < n; j++ {
+				a[j*lda+p], a[j*lda+k] = a[j*lda+k], a[j*lda+p]
+			}
+			P[p], P[k] = P[k], P[p]
+		}
+		if q != k {
+			cq, ck := a[q*lda:q*lda+m], a[k*lda:k*lda+m]
+			for i := range cq {
+				cq[i], ck[i] = ck[i], cq[i]
+			}
+			Q[q], Q[k] = Q[k], Q[q]
+		}
+		pivot := a[k*lda+k]
+		if pivot == 0.0 {
+			if zero < 0 {
+				zero = k
+			}
+			continue
+		}
+		col := a[k*lda+k+1 : k*lda+m]
+		for i := range col {
+			col[i] /= pivot
+		}
+		for j := k + 1; j < n; j++ {
+			ukj := a[j*lda+k]
+			if ukj == 0.0 {
+				continue
+			}
+			cj := a[j*lda+k+1 : j*lda+m]
+			for i, l := range col {
+				cj[i] -= l * ukj
+			}
+		}
+	}
+	if zero >= 0 {
+		err = linalg.NewError(linalg.ErrSingular, fmt.Sprintf("LU: zero pivot at column %d", zero+1))
+	}
+	return P, Q, err
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,1037 @@
+package matops
+
+import (
+	"errors"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+)
+
+func TestReshape(t *testing.T) {
+	A := matrix.FloatNew(2, 3, []float64{1, 2, 3, 4, 5, 6})
+	v := Ravel(A)
+	if &v[0] != &A.FloatArray()[0] {
+		t.Log("Ravel copied contiguous matrix\n")
+		t.Fail()
+	}
+	B, err := ReshapeCopy(A, 3, 2)
+	if err != nil {
+		t.Logf("ReshapeCopy error: %s\n", err)
+		t.FailNow()
+	}
+	t.Logf("B:\n%v\n", B)
+	if B.(*matrix.FloatMatrix).FloatArray()[4] != 5 {
+		t.Fail()
+	}
+	if _, err = ReshapeCopy(A, 4, 2); err == nil {
+		t.Log("expected error on size mismatch\n")
+		t.Fail()
+	}
+	R, err := Reshape(A, 3, 2)
+	if err != nil {
+		t.Fatalf("Reshape error: %s\n", err)
+	}
+	R.(*matrix.FloatMatrix).SetAt(1, 1, 10)
+	if R.Rows() != 3 || A.GetAt(0, 2) != 10 {
+		t.Logf("Reshape did not share storage:\n%v\n%v\n", R, A)
+		t.Fail()
+	}
+	Z := matrix.ComplexNew(1, 2, []complex128{1i, 2})
+	if Rz, err := Reshape(Z, 2, 1); err != nil || Rz.(*matrix.ComplexMatrix).GetAt(1, 0) != 2 {
+		t.Logf("complex Reshape: %v %v\n", Rz, err)
+		t.Fail()
+	}
+	if _, err = Reshape(A, 4, 2); !errors.Is(err, linalg.ErrShape) {
+		t.Log("expected error on size mismatch\n")
+		t.Fail()
+	}
+}
+
+
+func TestBlockFrom(t *testing.T) {
+	A := matrix.FloatNew(2, 2, []float64{1, 2, 3, 4})
+	b := matrix.FloatVector([]float64{5, 6})
+	Ab, err := HStack(A, b)
+	if err != nil {
+		t.Logf("HStack error: %s\n", err)
+		t.FailNow()
+	}
+	t.Logf("[A|b]:\n%v\n", Ab)
+	if Ab.Cols() != 3 || Ab.(*matrix.FloatMatrix).GetAt(1, 2) != 6 {
+		t.Fail()
+	}
+	K, err := BlockFrom([][]matrix.Matrix{
+		{A, nil},
+		{nil, A}})
+	if err != nil {
+		t.Logf("BlockFrom error: %s\n", err)
+		t.FailNow()
+	}
+	t.Logf("K:\n%v\n", K)
+	if K.(*matrix.FloatMatrix).GetAt(3, 3) != 4 || K.(*matrix.FloatMatrix).GetAt(0, 3) != 0 {
+		t.Fail()
+	}
+	if _, err = VStack(A, b); err == nil {
+		t.Log("expected error on column mismatch\n")
+		t.Fail()
+	}
+}
+
+func TestKron(t *testing.T) {
+	A := matrix.FloatNew(2, 2, []float64{1, 3, 2, 4})
+	I := matrix.FloatNew(2, 2, []float64{1, 0, 0, 1})
+	K, err := Kron(A, I)
+	if err != nil {
+		t.Logf("Kron error: %s\n", err)
+		t.FailNow()
+	}
+	t.Logf("kron(A, I):\n%v\n", K)
+	Kf := K.(*matrix.FloatMatrix)
+	if Kf.GetAt(0, 2) != 2 || Kf.GetAt(3, 1) != 3 || Kf.GetAt(1, 0) != 0 {
+		t.Fail()
+	}
+}
+
+func TestElem(t *testing.T) {
+	A := matrix.ComplexVector([]complex128{1 + 1i, 2, 3i})
+	B := matrix.ComplexVector([]complex128{1i, 2, 3})
+	C, err := MulElem(A, B)
+	if err != nil {
+		t.Logf("MulElem error: %s\n", err)
+		t.FailNow()
+	}
+	if C.(*matrix.ComplexMatrix).ComplexArray()[0] != -1+1i {
+		t.Fail()
+	}
+	DivElemInPlace(C, B)
+	for i, v := range C.(*matrix.ComplexMatrix).ComplexArray() {
+		if v != A.ComplexArray()[i] {
+			t.Logf("C[%d]=%v, expected %v\n", i, v, A.ComplexArray()[i])
+			t.Fail()
+		}
+	}
+}
+
+func TestErrors(t *testing.T) {
+	A := matrix.FloatZeros(2, 3)
+	_, err := MulElem(A, matrix.FloatZeros(3, 2))
+	if !errors.Is(err, linalg.ErrShape) {
+		t.Logf("MulElem: expected ErrShape, got %v\n", err)
+		t.Fail()
+	}
+	_, err = Kron(A, matrix.ComplexZeros(2, 2))
+	if !errors.Is(err, linalg.ErrType) {
+		t.Logf("Kron: expected ErrType, got %v\n", err)
+		t.Fail()
+	}
+	var lerr *linalg.Error
+	if !errors.As(err, &lerr) || lerr.Info != 0 {
+		t.Logf("Kron: expected *linalg.Error, got %T\n", err)
+		t.Fail()
+	}
+}
+
+func TestApply(t *testing.T) {
+	A := matrix.FloatZeros(200, 100)
+	ApplyIndexedInPlace(A, func(i, j int, v float64) float64 {
+		return float64(i + 1000*j)
+	})
+	B := Apply(A, func(v float64) float64 { return 2 * v }, linalg.IntOpt("workers", 0))
+	C := ApplyIndexed(A, func(i, j int, v float64) float64 {
+		return v + float64(i+1000*j)
+	}, linalg.IntOpt("workers", 7))
+	if !B.Equal(C) {
+		t.Logf("parallel Apply and ApplyIndexed results differ\n")
+		t.Fail()
+	}
+	if A.GetAt(3, 2) != 2003 || B.GetAt(199, 99) != 2*99199 {
+		t.Logf("A[3,2]=%v, B[199,99]=%v\n", A.GetAt(3, 2), B.GetAt(199, 99))
+		t.Fail()
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	A := matrix.FloatNew(2, 3, []float64{1, 4, 2, 5, 3, 6})
+	r := matrix.FloatNew(1, 3, []float64{10, 20, 30})
+	c := matrix.FloatVector([]float64{2, 3})
+	B, _ := AddRowVector(A, r)
+	C, _ := ScaleRows(A, c)
+	D, _ := ScaleCols(A, r)
+	E, err := AddColVector(A, c)
+	if err != nil {
+		t.Logf("AddColVector: %v\n", err)
+		t.FailNow()
+	}
+	t.Logf("A+1r:\n%v\ndiag(c)A:\n%v\n", B, C)
+	b := B.(*matrix.FloatMatrix)
+	cc := C.(*matrix.FloatMatrix)
+	d := D.(*matrix.FloatMatrix)
+	e := E.(*matrix.FloatMatrix)
+	if b.GetAt(1, 2) != 36 || cc.GetAt(1, 2) != 18 || d.GetAt(1, 2) != 180 || e.GetAt(1, 0) != 7 {
+		t.Fail()
+	}
+	if err := AddRowVectorInPlace(A, c); !errors.Is(err, linalg.ErrShape) {
+		t.Logf("expected ErrShape, got %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestIndexing(t *testing.T) {
+	A := matrix.FloatNew(3, 2, []float64{1, 2, 3, 4, 5, 6})
+	R, _ := SelectRows(A, []int{2, 0, 2})
+	C, _ := SelectCols(A, []int{1})
+	t.Logf("rows [2,0,2]:\n%v\ncols [1]:\n%v\n", R, C)
+	if R.(*matrix.FloatMatrix).GetAt(0, 1) != 6 || R.Rows() != 3 ||
+		C.(*matrix.FloatMatrix).GetAt(2, 0) != 6 {
+		t.Fail()
+	}
+	mask := Mask(A, func(v float64) bool { return v > 4 })
+	MaskedSet(A, mask, matrix.FScalar(0))
+	if A.GetAt(1, 1) != 0 || A.GetAt(2, 1) != 0 || A.GetAt(0, 1) != 4 {
+		t.Logf("masked A:\n%v\n", A)
+		t.Fail()
+	}
+	x, _ := Gather(A, []int{0, 3})
+	Scatter(A, x, []int{3, 0})
+	if A.GetAt(0, 0) != 4 || A.GetAt(0, 1) != 1 {
+		t.Logf("scattered A:\n%v\n", A)
+		t.Fail()
+	}
+	if _, err := SelectRows(A, []int{3}); !errors.Is(err, linalg.ErrParameter) {
+		t.Logf("expected ErrParameter, got %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestTriangular(t *testing.T) {
+	A := matrix.FloatNew(3, 3, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9})
+	L, _ := Tril(A, 0)
+	U, _ := Triu(A, 1)
+	t.Logf("tril(A):\n%v\ntriu(A,1):\n%v\n", L, U)
+	sum := L.(*matrix.FloatMatrix).Copy().Plus(U.(*matrix.FloatMatrix))
+	if !sum.Equal(A) {
+		t.Fail()
+	}
+	Ap, _ := Pack(A, linalg.PLower)
+	if Ap.NumElements() != 6 || Ap.(*matrix.FloatMatrix).GetAt(3, 0) != 5 {
+		t.Logf("packed: %v\n", Ap)
+		t.Fail()
+	}
+	L2, _ := Unpack(Ap, 3, linalg.PLower)
+	if !L2.(*matrix.FloatMatrix).Equal(L.(*matrix.FloatMatrix)) {
+		t.Fail()
+	}
+	S, _ := Symmetrize(A, linalg.PUpper)
+	s := S.(*matrix.FloatMatrix)
+	if s.GetAt(2, 0) != 7 || s.GetAt(0, 2) != 7 || s.GetAt(1, 2) != 8 {
+		t.Logf("symmetrized:\n%v\n", S)
+		t.Fail()
+	}
+	H := matrix.ComplexNew(2, 2, []complex128{1 + 1i, 2 + 1i, 0, 3})
+	HermitianInPlace(H, linalg.PLower)
+	if H.GetAt(0, 1) != 2-1i || H.GetAt(0, 0) != 1 {
+		t.Logf("hermitian:\n%v\n", H)
+		t.Fail()
+	}
+}
+
+func TestDiag(t *testing.T) {
+	A := matrix.FloatNew(3, 4, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	d1, _ := GetDiag(A, 1)
+	dm1, _ := GetDiag(A, -1)
+	t.Logf("diag(A,1): %v\ndiag(A,-1): %v\n", d1, dm1)
+	if d1.NumElements() != 3 || d1.(*matrix.FloatMatrix).GetAt(2, 0) != 12 ||
+		dm1.NumElements() != 2 || dm1.(*matrix.FloatMatrix).GetAt(1, 0) != 6 {
+		t.Fail()
+	}
+	SetDiag(A, -1, matrix.FloatVector([]float64{0, 0}))
+	AddDiag(A, matrix.FScalar(100))
+	if A.GetAt(1, 0) != 0 || A.GetAt(2, 2) != 109 {
+		t.Logf("A:\n%v\n", A)
+		t.Fail()
+	}
+	B, _ := BandStorage(A, 1, 1)
+	b := B.(*matrix.FloatMatrix)
+	// row ku holds main diagonal, row ku-1 first superdiagonal
+	if b.Rows() != 3 || b.GetAt(1, 2) != 109 || b.GetAt(0, 1) != 4 || b.GetAt(2, 1) != 0 {
+		t.Logf("band storage:\n%v\n", B)
+		t.Fail()
+	}
+	E, _ := ExtractBand(A, 0, 1)
+	if E.(*matrix.FloatMatrix).GetAt(0, 2) != 0 || E.(*matrix.FloatMatrix).GetAt(0, 1) != 4 {
+		t.Logf("band:\n%v\n", E)
+		t.Fail()
+	}
+}
+
+func TestPermutation(t *testing.T) {
+	P := Permutation{2, 0, 3, 1}
+	Q := Permutation{1, 0, 2, 3}
+	A := matrix.FloatNew(4, 1, []float64{10, 11, 12, 13})
+	QA, _ := Q.ApplyRows(A)
+	PQA, _ := P.ApplyRows(QA)
+	R, _ := P.Compose(Q).ApplyRows(A)
+	if !R.(*matrix.FloatMatrix).Equal(PQA.(*matrix.FloatMatrix)) {
+		t.Logf("compose: %v != %v\n", R, PQA)
+		t.Fail()
+	}
+	I := P.Compose(P.Inverse())
+	if I.Check() != nil || !matrix.Times(P.Matrix(), A).Equal(mustFloat(P.ApplyRows(A))) {
+		t.Fail()
+	}
+	for k, v := range I {
+		if k != v {
+			t.Logf("P*P^-1 = %v\n", I)
+			t.Fail()
+		}
+	}
+	P2, err := PivotPermutation(P.Pivots(), 4)
+	if err != nil {
+		t.Fail()
+	}
+	for k := range P {
+		if P[k] != P2[k] {
+			t.Logf("pivots round trip: %v -> %v -> %v\n", P, P.Pivots(), P2)
+			t.Fail()
+		}
+	}
+}
+
+func mustFloat(A matrix.Matrix, err error) *matrix.FloatMatrix {
+	if err != nil {
+		panic(err)
+	}
+	return A.(*matrix.FloatMatrix)
+}
+
+func TestReadOnly(t *testing.T) {
+	A := matrix.FloatNew(2, 2, []float64{1, 2, 3, 4})
+	R := NewReadOnly(A)
+	if R.FloatAt(1, 0) != 2 || Readable(R) != matrix.Matrix(A) || !IsReadOnly(nil, R) {
+		t.Fail()
+	}
+	if err := Writable("test", A, R); !errors.Is(err, linalg.ErrReadOnly) {
+		t.Logf("expected ErrReadOnly, got %v\n", err)
+		t.Fail()
+	}
+
+	c1 := NewCOW(A)
+	c2 := c1.Clone()
+	M := c2.Mutable().(*matrix.FloatMatrix)
+	M.SetAt(0, 0, 100)
+	if c1.View().FloatAt(0, 0) != 1 || c2.View().FloatAt(0, 0) != 100 {
+		t.Logf("clone modified shared storage\n")
+		t.Fail()
+	}
+	// c1 is now the only handle to A and writes in place
+	if c1.Mutable() != matrix.Matrix(A) {
+		t.Fail()
+	}
+}
+
+func TestSolve(t *testing.T) {
+	A := matrix.FloatMatrixFromTable([][]float64{
+		[]float64{0.0, 2.0, 1.0},
+		[]float64{1.0, 1.0, 0.0},
+		[]float64{4.0, 0.0, 3.0}}, matrix.RowOrder)
+	X0 := matrix.FloatNew(3, 2, []float64{1, 2, 3, -1, 0, 1})
+	B := matrix.Times(A, X0)
+	X, err := Solve(A, B)
+	if err != nil {
+		t.Logf("Solve: %v\n", err)
+		t.FailNow()
+	}
+	d := matrix.Minus(X, X0)
+	for _, v := range d.FloatArray() {
+		if v > 1e-12 || v < -1e-12 {
+			t.Logf("X=\n%v\nwant\n%v\n", X, X0)
+			t.Fail()
+			break
+		}
+	}
+	_, err = Solve(matrix.FloatZeros(2, 2), matrix.FloatOnes(2, 1))
+	if !errors.Is(err, linalg.ErrSingular) {
+		t.Logf("singular: %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestRandom(t *testing.T) {
+	A := RandNormal(50, 40, rand.NewSource(7))
+	B := RandNormal(50, 40, rand.NewSource(7))
+	if !A.Equal(B) {
+		t.Logf("RandNormal not reproducible\n")
+		t.Fail()
+	}
+	U := RandUniform(100, 100, rand.NewSource(1))
+	for _, v := range U.FloatArray() {
+		if v < 0.0 || v >= 1.0 {
+			t.Logf("RandUniform: %v out of range\n", v)
+			t.Fail()
+			break
+		}
+	}
+	if mean := U.Sum() / 1e4; math.Abs(mean-0.5) > 0.02 {
+		t.Logf("RandUniform: mean %v\n", mean)
+		t.Fail()
+	}
+	Z := RandNormalComplex(100, 100, rand.NewSource(3))
+	s := 0.0
+	for _, v := range Z.ComplexArray() {
+		s += real(v)*real(v) + imag(v)*imag(v)
+	}
+	if s /= 1e4; math.Abs(s-1.0) > 0.05 {
+		t.Logf("RandNormalComplex: E|z|^2 = %v\n", s)
+		t.Fail()
+	}
+	C1 := RandUniformComplex(3, 3, rand.NewSource(5))
+	C2 := RandUniformComplex(3, 3, rand.NewSource(5))
+	for k, v := range C1.ComplexArray() {
+		if C2.ComplexArray()[k] != v {
+			t.Fail()
+		}
+	}
+}
+
+func TestRandOrthogonal(t *testing.T) {
+	n := 20
+	Q := RandOrthogonal(n, rand.NewSource(11))
+	E := matrix.Minus(matrix.Times(Q.Transpose(), Q), matrix.FloatIdentity(n))
+	for _, v := range E.FloatArray() {
+		if math.Abs(v) > 1e-13 {
+			t.Logf("Q^T*Q - I: %v\n", v)
+			t.Fail()
+			break
+		}
+	}
+	U := RandUnitary(n, rand.NewSource(11))
+	for j := 0; j < n; j++ {
+		for k := 0; k < n; k++ {
+			var s complex128
+			for i := 0; i < n; i++ {
+				s += cmplx.Conj(U.GetAt(i, j)) * U.GetAt(i, k)
+			}
+			if j == k {
+				s -= 1
+			}
+			if cmplx.Abs(s) > 1e-13 {
+				t.Logf("U^H*U - I (%d,%d): %v\n", j, k, s)
+				t.FailNow()
+			}
+		}
+	}
+}
+
+func TestRandSPD(t *testing.T) {
+	A, err := RandSPD(8, 1e4, rand.NewSource(2))
+	if err != nil {
+		t.FailNow()
+	}
+	B, _ := RandSPD(8, 1e4, rand.NewSource(2))
+	if !A.Equal(B) || !A.Equal(A.Transpose()) {
+		t.Logf("not reproducible or not symmetric\n")
+		t.Fail()
+	}
+	// trace equals sum of eigenvalues
+	tr, sum := 0.0, 0.0
+	for k := 0; k < 8; k++ {
+		tr += A.GetAt(k, k)
+		sum += math.Pow(1e4, -float64(k)/7.0)
+	}
+	if math.Abs(tr-sum) > 1e-12 {
+		t.Logf("trace %v, sum of eigenvalues %v\n", tr, sum)
+		t.Fail()
+	}
+	// smallest eigenvalue 1e-4: solution of A*x = A*v is v to about cond*eps
+	v := RandNormal(8, 1, rand.NewSource(3))
+	x, err := Solve(A, matrix.Times(A, v))
+	if err != nil || normInf(matrix.Minus(x, v)) > 1e-9 {
+		t.Logf("solve: %v\n", err)
+		t.Fail()
+	}
+	if _, err = RandSPD(3, 0.5, rand.NewSource(1)); !errors.Is(err, linalg.ErrParameter) {
+		t.Fail()
+	}
+}
+
+func normInf(A *matrix.FloatMatrix) float64 {
+	m := 0.0
+	for _, v := range A.FloatArray() {
+		m = math.Max(m, math.Abs(v))
+	}
+	return m
+}
+
+func TestStructured(t *testing.T) {
+	c := matrix.FloatVector([]float64{1, 2, 3})
+	r := matrix.FloatNew(1, 4, []float64{9, 4, 5, 6})
+	T, _ := Toeplitz(c, r)
+	Tref := matrix.FloatMatrixFromTable([][]float64{
+		[]float64{1, 4, 5, 6},
+		[]float64{2, 1, 4, 5},
+		[]float64{3, 2, 1, 4}}, matrix.RowOrder)
+	if !T.(*matrix.FloatMatrix).Equal(Tref) {
+		t.Logf("Toeplitz:\n%v\n", T)
+		t.Fail()
+	}
+	H, _ := Hankel(c, r)
+	Href := matrix.FloatMatrixFromTable([][]float64{
+		[]float64{1, 2, 3, 4},
+		[]float64{2, 3, 4, 5},
+		[]float64{3, 4, 5, 6}}, matrix.RowOrder)
+	if !H.(*matrix.FloatMatrix).Equal(Href) {
+		t.Logf("Hankel:\n%v\n", H)
+		t.Fail()
+	}
+	C, _ := Circulant(c)
+	Cref := matrix.FloatMatrixFromTable([][]float64{
+		[]float64{1, 3, 2},
+		[]float64{2, 1, 3},
+		[]float64{3, 2, 1}}, matrix.RowOrder)
+	if !C.(*matrix.FloatMatrix).Equal(Cref) {
+		t.Logf("Circulant:\n%v\n", C)
+		t.Fail()
+	}
+	z := matrix.ComplexVector([]complex128{1, 2i})
+	Z, _ := Toeplitz(z, nil)
+	if Z.(*matrix.ComplexMatrix).GetAt(0, 1) != -2i || Z.(*matrix.ComplexMatrix).GetAt(1, 0) != 2i {
+		t.Logf("Hermitian Toeplitz:\n%v\n", Z)
+		t.Fail()
+	}
+	if _, err := Hankel(c, matrix.ComplexVector([]complex128{1})); !errors.Is(err, linalg.ErrType) {
+		t.Fail()
+	}
+	if _, err := Circulant(Tref); !errors.Is(err, linalg.ErrShape) {
+		t.Fail()
+	}
+}
+
+func TestVandermonde(t *testing.T) {
+	V, _ := Vandermonde([]float64{2, 3}, 2)
+	Vref := matrix.FloatNew(2, 3, []float64{4, 9, 2, 3, 1, 1})
+	if !V.Equal(Vref) {
+		t.Logf("Vandermonde:\n%v\n", V)
+		t.Fail()
+	}
+	V, _ = Vandermonde([]float64{2, 3}, 2, linalg.BoolOpt("increasing", true))
+	Vref = matrix.FloatNew(2, 3, []float64{1, 1, 2, 3, 4, 9})
+	if !V.Equal(Vref) {
+		t.Logf("Vandermonde increasing:\n%v\n", V)
+		t.Fail()
+	}
+	if _, err := Vandermonde(nil, -1); !errors.Is(err, linalg.ErrParameter) {
+		t.Fail()
+	}
+}
+
+func TestConstructors(t *testing.T) {
+	E := Eye(3, 1)
+	Eref := matrix.FloatNew(3, 3, []float64{0, 0, 0, 1, 0, 0, 0, 1, 0})
+	if !E.Equal(Eref) || !Eye(3, -1).Equal(Eref.Transpose()) || Eye(3, 3).Sum() != 0 {
+		t.Logf("Eye(3, 1):\n%v\n", E)
+		t.Fail()
+	}
+	x, _ := Linspace(0, 1, 5)
+	if !x.Equal(matrix.FloatVector([]float64{0, 0.25, 0.5, 0.75, 1})) {
+		t.Logf("Linspace: %v\n", x)
+		t.Fail()
+	}
+	y, _ := Arange(1, 0, -0.25)
+	if !y.Equal(matrix.FloatVector([]float64{1, 0.75, 0.5, 0.25})) {
+		t.Logf("Arange: %v\n", y)
+		t.Fail()
+	}
+	if _, err := Arange(0, 1, 0); !errors.Is(err, linalg.ErrParameter) {
+		t.Fail()
+	}
+}
+
+func TestFromFunc(t *testing.T) {
+	// Gram matrix of Gaussian kernel, large enough to run in parallel
+	x, _ := Linspace(0, 1, 200)
+	k := func(i, j int) float64 {
+		d := x.GetIndex(i) - x.GetIndex(j)
+		return math.Exp(-d * d)
+	}
+	K := FloatFromFunc(200, 200, k, linalg.IntOpt("workers", 4))
+	if !K.Equal(FloatFromFunc(200, 200, k)) || !K.Equal(K.Transpose()) || K.GetAt(3, 3) != 1.0 {
+		t.Logf("FloatFromFunc: parallel and serial results differ\n")
+		t.Fail()
+	}
+	Z := ComplexFromFunc(2, 3, func(i, j int) complex128 { return complex(float64(i), float64(j)) })
+	if Z.GetAt(1, 2) != 1+2i {
+		t.Logf("ComplexFromFunc:\n%v\n", Z)
+		t.Fail()
+	}
+}
+
+func TestBlockDiag(t *testing.T) {
+	A := matrix.FloatNew(2, 1, []float64{1, 2})
+	B := matrix.FloatNew(1, 2, []float64{3, 4})
+	D, err := BlockDiag(A, B)
+	Dref := matrix.FloatNew(3, 3, []float64{1, 2, 0, 0, 0, 3, 0, 0, 4})
+	if err != nil || !D.(*matrix.FloatMatrix).Equal(Dref) {
+		t.Logf("BlockDiag:\n%v\n", D)
+		t.Fail()
+	}
+	if _, err = BlockDiag(A, matrix.ComplexZeros(1, 1)); !errors.Is(err, linalg.ErrType) {
+		t.Fail()
+	}
+}
+
+
+func TestSlices(t *testing.T) {
+	s := [][]float64{{1, 2, 3}, {4, 5, 6}}
+	A, err := FromSlices(s)
+	if err != nil || A.Rows() != 2 || A.Cols() != 3 || A.GetAt(1, 0) != 4.0 {
+		t.Logf("A:\n%v\nerr=%v\n", A, err)
+		t.Fail()
+	}
+	s[0][0] = 10.0
+	if A.GetAt(0, 0) != 1.0 {
+		t.Logf("FromSlices did not copy\n")
+		t.Fail()
+	}
+	r := ToSlices(A)
+	if len(r) != 2 || len(r[1]) != 3 || r[1][2] != 6.0 || r[0][1] != 2.0 {
+		t.Logf("ToSlices: %v\n", r)
+		t.Fail()
+	}
+	r[0] = append(r[0], 7.0)
+	if r[1][0] != 4.0 {
+		t.Logf("rows alias: %v\n", r)
+		t.Fail()
+	}
+	if _, err = FromSlices([][]float64{{1, 2}, {3}}); err == nil {
+		t.Logf("ragged rows accepted\n")
+		t.Fail()
+	}
+}
+
+func TestLogMatMul(t *testing.T) {
+	P := matrix.FloatNew(2, 2, []float64{0.9, 0.2, 0.1, 0.8})
+	logP := Apply(P, math.Log)
+	L := logP.Copy()
+	for k := 0; k < 2000; k++ {
+		L, _ = LogMatMul(L, logP)
+	}
+	// rows of P^k sum to one, so log of row sums stays at zero
+	for i := 0; i < 2; i++ {
+		s := math.Exp(L.GetAt(i, 0)) + math.Exp(L.GetAt(i, 1))
+		if math.Abs(s-1.0) > 1e-10 {
+			t.Logf("row %d sum %g\n", i, s)
+			t.Fail()
+		}
+	}
+	// tiny entries that underflow in linear space
+	A := matrix.FloatNew(1, 2, []float64{-1000, -1000})
+	B := matrix.FloatNew(2, 1, []float64{-1000, math.Inf(-1)})
+	C, err := LogMatMul(A, B)
+	if err != nil || math.Abs(C.GetAt(0, 0)+2000) > 1e-12 {
+		t.Logf("C=%v err=%v\n", C, err)
+		t.Fail()
+	}
+	if _, err = LogMatMul(A, A); err == nil {
+		t.Fail()
+	}
+}
+
+func TestEWCholesky(t *testing.T) {
+	n, lambda := 4, 0.9
+	E, err := NewEWCholesky(n, lambda, linalg.FloatOpt("delta", 1.0))
+	if err != nil {
+		t.Fatalf("NewEWCholesky: %v\n", err)
+	}
+	src := rand.NewSource(5)
+	S := FloatFromFunc(n, n, func(i, j int) float64 {
+		if i == j {
+			return 1.0
+		}
+		return 0.0
+	})
+	var x *matrix.FloatMatrix
+	for k := 0; k < 50; k++ {
+		x = RandNormal(n, 1, src)
+		if err = E.Update(x); err != nil {
+			t.Fatalf("Update: %v\n", err)
+		}
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				S.SetAt(i, j, lambda*S.GetAt(i, j)+(1-lambda)*x.GetAt(i, 0)*x.GetAt(j, 0))
+			}
+		}
+	}
+	if d := normInf(matrix.Minus(E.Matrix(), S)); d > 1e-12 || E.Count() != 50 {
+		t.Logf("|LL^T - S| = %g, count %d\n", d, E.Count())
+		t.Fail()
+	}
+	b := RandNormal(n, 2, src)
+	X, err := E.Solve(b)
+	if err != nil || normInf(matrix.Minus(matrix.Times(S, X), b)) > 1e-10 {
+		t.Logf("Solve: %v\n", err)
+		t.Fail()
+	}
+	// remove the last sample again
+	if err = E.Downdate(x, 1-lambda); err != nil {
+		t.Logf("Downdate: %v\n", err)
+		t.Fail()
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			S.SetAt(i, j, S.GetAt(i, j)-(1-lambda)*x.GetAt(i, 0)*x.GetAt(j, 0))
+		}
+	}
+	if d := normInf(matrix.Minus(E.Matrix(), S)); d > 1e-12 {
+		t.Logf("downdate |LL^T - S| = %g\n", d)
+		t.Fail()
+	}
+	L0 := E.Factor()
+	if err = E.Downdate(x, 1e6); err == nil || !errors.Is(err, linalg.ErrNotPositiveDefinite) || !L0.Equal(E.Factor()) {
+		t.Logf("failed downdate: %v\n", err)
+		t.Fail()
+	}
+	// non-finite samples leave the factor unchanged
+	x.SetAt(n-1, 0, math.NaN())
+	if err = E.Update(x); !errors.Is(err, linalg.ErrParameter) || !L0.Equal(E.Factor()) || E.Count() != 50 {
+		t.Logf("update with NaN: %v, count %d\n", err, E.Count())
+		t.Fail()
+	}
+	L := E.Factor()
+	L.SetAt(n-1, n-1, 0.0)
+	L1 := L.Copy()
+	x.SetAt(n-1, 0, 1.0)
+	if err = CholUpdate(L, x); !errors.Is(err, linalg.ErrParameter) || !L.Equal(L1) {
+		t.Logf("CholUpdate with zero diagonal: %v\n", err)
+		t.Fail()
+	}
+}
+
+func TestLU(t *testing.T) {
+	src := rand.NewSource(7)
+	A := RandNormal(5, 4, src)
+	// rank 2 matrix for complete pivoting
+	R := matrix.Times(RandNormal(4, 2, src), RandNormal(2, 4, src))
+	for _, strategy := range []string{PivotPartial, PivotRook, PivotComplete} {
+		for _, M := range []*matrix.FloatMatrix{A, A.Transpose(), R} {
+			F := M.Copy()
+			P, Q, err := LU(F, linalg.StringOpt("pivoting", strategy))
+			if M == R && strategy == PivotComplete {
+				// rounding leaves tiny instead of exactly zero pivots
+				if (err != nil && !errors.Is(err, linalg.ErrSingular)) || math.Abs(F.GetAt(2, 2)) > 1e-12 {
+					t.Logf("%s: rank not revealed: %v\n%v\n", strategy, err, F)
+					t.Fail()
+				}
+			} else if err != nil && M != R {
+				t.Fatalf("%s: %v\n", strategy, err)
+			}
+			m, n := M.Rows(), M.Cols()
+			L := matrix.FloatZeros(m, min(m, n))
+			U := matrix.FloatZeros(min(m, n), n)
+			for i := 0; i < m; i++ {
+				for j := 0; j < n; j++ {
+					if i > j {
+						L.SetAt(i, j, F.GetAt(i, j))
+					} else if i < U.Rows() {
+						U.SetAt(i, j, F.GetAt(i, j))
+					}
+					if i == j {
+						L.SetAt(i, j, 1.0)
+					}
+				}
+			}
+			PA, _ := P.ApplyRows(M)
+			PAQ, _ := Q.ApplyCols(PA)
+			if d := normInf(matrix.Minus(matrix.Times(L, U), PAQ.(*matrix.FloatMatrix))); d > 1e-12 {
+				t.Logf("%s %dx%d: |PAQ^T - LU| = %g\n", strategy, m, n, d)
+				t.Fail()
+			}
+			for j := range Q {
+				if strategy == PivotPartial && Q[j] != j {
+					t.Logf("partial pivoting permuted columns: %v\n", Q)
+					t.Fail()
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestLUSingular(t *testing.T) {
+	A := matrix.FloatNew(3, 3, []float64{1, 2, 3, 2, 4, 6, 0, 0, 1})
+	_, _, err := LU(A, linalg.StringOpt("pivoting", PivotRook))
+	if !errors.Is(err, linalg.ErrSingular) {
+		t.Logf("err=%v\n%v\n", err, A)
+		t.Fail()
+	}
+	if _, _, err = LU(A, linalg.StringOpt("pivoting", "full")); err == nil {
+		t.Fail()
+	}
+}
+
+func TestSymEigBisect(t *testing.T) {
+	spectrum := []float64{-3, -1, 0.5, 0.5, 2, 10}
+	A := RandWithSpectrum(spectrum, rand.NewSource(11))
+	w, err := SymEigBisect(A, 1, 5, linalg.FloatOpt("tol", 1e-10))
+	if err != nil || len(w) != 4 {
+		t.Fatalf("SymEigBisect: %v %v\n", err, w)
+	}
+	for k, v := range w {
+		if math.Abs(v-spectrum[k+1]) > 1e-9 {
+			t.Logf("eigenvalue %d: %g, expected %g\n", k+1, v, spectrum[k+1])
+			t.Fail()
+		}
+	}
+	w, err = SymEigInterval(A, 0.0, 2.0)
+	if err != nil || len(w) != 3 || math.Abs(w[2]-2.0) > 1e-12 {
+		t.Logf("SymEigInterval: %v %v\n", err, w)
+		t.Fail()
+	}
+	// 1D Laplacian: eigenvalues 2 - 2*cos(k*pi/(n+1))
+	n := 50
+	d, e := make([]float64, n), make([]float64, n-1)
+	for i := range d {
+		d[i] = 2.0
+		if i < n-1 {
+			e[i] = -1.0
+		}
+	}
+	w, _ = TridiagBisect(d, e, 0, n, 0.0)
+	for k, v := range w {
+		if x := 2.0 - 2.0*math.Cos(float64(k+1)*math.Pi/float64(n+1)); math.Abs(v-x) > 1e-14 {
+			t.Logf("Laplacian eigenvalue %d: %g, expected %g\n", k, v, x)
+			t.Fail()
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	A := MustParseFloat("[1 2; 3, 4]")
+	if A.Rows() != 2 || A.Cols() != 2 || A.GetAt(0, 1) != 2 || A.GetAt(1, 0) != 3 {
+		t.Logf("A:\n%v\n", A)
+		t.Fail()
+	}
+	Z, err := Parse("1+2i 0\n-Inf 3j")
+	C, ok := Z.(*matrix.ComplexMatrix)
+	if err != nil || !ok || C.GetAt(0, 0) != complex(1, 2) || C.GetAt(1, 1) != complex(0, 3) ||
+		!math.IsInf(real(C.GetAt(1, 0)), -1) {
+		t.Logf("Z:\n%v\n%v\n", Z, err)
+		t.Fail()
+	}
+	if W := MustParseComplex("1 2"); W.GetAt(0, 1) != 2 {
+		t.Fail()
+	}
+	if _, err = Parse("1 2; 3"); !errors.Is(err, linalg.ErrShape) {
+		t.Fail()
+	}
+	if _, err = Parse("1 x"); !errors.Is(err, linalg.ErrParameter) {
+		t.Fail()
+	}
+	if E := MustParseFloat(""); E.Rows() != 0 || E.Cols() != 0 {
+		t.Fail()
+	}
+}
+
+func TestCovariance(t *testing.T) {
+	X := [][]float64{{1, 2}, {2, 4.5}, {3, 5}, {4, 9}}
+	C := NewCovariance(2)
+	for _, x := range X {
+		C.Add(x)
+	}
+	mean := []float64{2.5, 5.125}
+	S := C.Matrix(false)
+	t.Logf("mean=%v\nS=\n%v\n", C.Mean(), S)
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			s := 0.0
+			for _, x := range X {
+				s += (x[i] - mean[i]) * (x[j] - mean[j])
+			}
+			if math.Abs(S.GetAt(i, j)-s/3) > 1e-13 {
+				t.Logf("S[%d,%d] = %v, want %v\n", i, j, S.GetAt(i, j), s/3)
+				t.Fail()
+			}
+		}
+	}
+	if C.Count() != 4 || math.Abs(C.Mean().GetAt(1, 0)-mean[1]) > 1e-15 {
+		t.Fail()
+	}
+}
+
+func TestBandMatrix(t *testing.T) {
+	A := matrix.FloatNew(4, 3, []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	B, err := NewBandMatrix(A, 1, 0)
+	if err != nil {
+		t.Fatalf("NewBandMatrix: %v\n", err)
+	}
+	E, _ := ExtractBand(A, 1, 0)
+	D := B.Dense()
+	t.Logf("band storage:\n%v\ndense:\n%v\n", B.Matrix, D)
+	if B.Rows() != 2 || B.Cols() != 3 || !D.(*matrix.FloatMatrix).Equal(E.(*matrix.FloatMatrix)) {
+		t.Fail()
+	}
+	if _, err = BandMatrixFromStorage(B.Matrix, 4, 3, 2, 0); err == nil {
+		t.Fail()
+	}
+}
+
+func TestStandardize(t *testing.T) {
+	X := matrix.FloatNew(4, 3, []float64{1, 2, 3, 4, 10, 10, 10, 10, -2, 0, 2, 4})
+	Y, T, err := Standardize(X)
+	if err != nil {
+		t.Fatalf("Standardize: %v\n", err)
+	}
+	t.Logf("Y:\n%v\ntransform: %+v\n", Y, T)
+	for j := 0; j < 3; j++ {
+		mean, ssq := 0.0, 0.0
+		for i := 0; i < 4; i++ {
+			mean += Y.GetAt(i, j) / 4
+			ssq += Y.GetAt(i, j) * Y.GetAt(i, j)
+		}
+		want := 3.0
+		if j == 1 {
+			want = 0.0
+		}
+		if math.Abs(mean) > 1e-15 || math.Abs(ssq-want) > 1e-14 {
+			t.Logf("column %d: mean %g, sum of squares %g\n", j, mean, ssq)
+			t.Fail()
+		}
+	}
+	X2, _ := T.Inverse(Y)
+	if maxDiff(X2, X) > 1e-14 {
+		t.Logf("inverse:\n%v\n", X2)
+		t.Fail()
+	}
+	Y, T, _ = NormalizeCols(X, "1")
+	if Y.GetAt(3, 0) != 0.4 || T.Scale[2] != 8 || T.Offset[0] != 0 {
+		t.Logf("NormalizeCols 1:\n%v\n", Y)
+		t.Fail()
+	}
+	Y, _, _ = NormalizeCols(X, "I")
+	if Y.GetAt(3, 2) != 1.0 {
+		t.Fail()
+	}
+	if _, _, err = NormalizeCols(X, "F"); err == nil {
+		t.Fail()
+	}
+}
+
+// Return largest absolute difference of elements of A and B.
+func maxDiff(A, B *matrix.FloatMatrix) float64 {
+	d := 0.0
+	for i, v := range A.FloatArray() {
+		d = math.Max(d, math.Abs(v-B.FloatArray()[i]))
+	}
+	return d
+}
+
+func TestWalshHadamard(t *testing.T) {
+	H, err := Hadamard(4)
+	if err != nil {
+		t.Fatalf("Hadamard: %v\n", err)
+	}
+	H0 := matrix.FloatNew(4, 4, []float64{
+		1, 1, 1, 1,
+		1, -1, 1, -1,
+		1, 1, -1, -1,
+		1, -1, -1, 1})
+	if maxDiff(H, H0) != 0.0 {
+		t.Logf("Hadamard(4): %v\n", H)
+		t.Fail()
+	}
+	W, _ := NewWalshHadamard(8, false, OrderSequency)
+	S, _ := OperatorMatrix(W)
+	for k := 0; k < 8; k++ {
+		changes := 0
+		for j := 1; j < 8; j++ {
+			if S.GetAt(k, j) != S.GetAt(k, j-1) {
+				changes++
+			}
+		}
+		if changes != k {
+			t.Logf("sequency row %d has %d sign changes\n", k, changes)
+			t.Fail()
+		}
+	}
+	// normalized transform is its own inverse
+	X := matrix.FloatZeros(8, 3)
+	for k := range X.FloatArray() {
+		X.FloatArray()[k] = float64(k*k%7) - 3.0
+	}
+	Y := X.Copy()
+	FWHT(Y, linalg.BoolOpt("normalize", true))
+	FWHT(Y, linalg.BoolOpt("normalize", true))
+	if d := maxDiff(X, Y); d > 1e-14 {
+		t.Logf("FWHT twice differs by %v\n", d)
+		t.Fail()
+	}
+	if err := FWHT(matrix.FloatZeros(6, 1)); err == nil {
+		t.Logf("FWHT accepted 6 rows\n")
+		t.Fail()
+	}
+}
+
+func TestMatrixPowers(t *testing.T) {
+	A := matrix.FloatNew(3, 3, []float64{2, 1, 0, -1, 3, 1, 0.5, 0, 1})
+	V := matrix.FloatNew(3, 2, []float64{1, 0, 2, -1, 1, 1})
+	K, err := MatrixPowers(DenseOperator{A}, V, 3)
+	if err != nil {
+		t.Fatalf("MatrixPowers: %v\n", err)
+	}
+	X := V.Copy()
+	for k := 0; k <= 3; k++ {
+		if d := maxDiff(K.GetSubMatrix(0, 2*k, 3, 2), X); d > 1e-12 {
+			t.Logf("power %d differs by %v\n", k, d)
+			t.Fail()
+		}
+		X = matrix.Times(A, X)
+	}
+	// generic operator path and normalization
+	W, _ := NewWalshHadamard(4, false, OrderNatural)
+	K, err = MatrixPowers(W, matrix.FloatNew(4, 1, []float64{1, 0, 0, 0}), 2,
+		linalg.BoolOpt("normalize", true))
+	if err != nil {
+		t.Fatalf("MatrixPowers: %v\n", err)
+	}
+	// H*H = 4*I, so the normalized second power is e0 again
+	if K.GetAt(0, 2) != 1.0 || math.Abs(K.GetAt(1, 1)-0.5) > 1e-15 {
+		t.Logf("normalized powers: %v\n", K)
+		t.Fail()
+	}
+}
+
+func TestComplexStepJacobian(t *testing.T) {
+	// f(x) = (x0*x1, exp(x0)*sin(x1))
+	f := func(x *matrix.ComplexMatrix) (*matrix.ComplexMatrix, error) {
+		x0, x1 := x.GetAt(0, 0), x.GetAt(1, 0)
+		return matrix.ComplexNew(2, 1, []complex128{x0 * x1, cmplx.Exp(x0) * cmplx.Sin(x1)}), nil
+	}
+	x := matrix.FloatNew(2, 1, []float64{0.5, 2.0})
+	fx, J, err := ComplexStepJacobian(f, x)
+	if err != nil {
+		t.Fatalf("ComplexStepJacobian: %v\n", err)
+	}
+	e := math.Exp(0.5)
+	want := matrix.FloatNew(2, 2, []float64{2.0, e * math.Sin(2), 0.5, e * math.Cos(2)})
+	if d := maxDiff(J, want); d > 1e-15 || math.Abs(fx.GetAt(0, 0)-1.0) > 1e-15 {
+		t.Logf("f: %v\nJ: %v\nwant: %v\n", fx, J, want)
+		t.Fail()
+	}
+	g := func(x *matrix.ComplexMatrix) (complex128, error) {
+		return x.GetAt(0, 0) * x.GetAt(0, 0) * x.GetAt(1, 0), nil
+	}
+	v, grad, err := ComplexStepGradient(g, x)
+	if err != nil || v != 0.5 || grad.Rows() != 2 || grad.GetAt(0, 0) != 2.0 || grad.GetAt(1, 0) != 0.25 {
+		t.Logf("gradient: %v %v %v\n", v, grad, err)
+		t.Fail()
+	}
+	if _, _, err = ComplexStepJacobian(f, x, linalg.FloatOpt("step", 0)); err == nil {
+		t.Fail()
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
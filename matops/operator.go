@@ -0,0 +1,41 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+)
+
+// Linear map given by its action on vectors rather than by its elements,
+// for structured matrices with fast products.
+type LinearOperator interface {
+	// Size of the map as a matrix, m by n.
+	Size() (int, int)
+	// Return new matrix A*X for n by k X, or A^T*X for m by k X if trans
+	// is true.
+	Apply(X matrix.Matrix, trans bool) (matrix.Matrix, error)
+}
+
+// Return the operator A as a new dense float matrix, computed by applying
+// it to the columns of the identity.
+func OperatorMatrix(A LinearOperator) (*matrix.FloatMatrix, error) {
+	_, n := A.Size()
+	M, err := A.Apply(matrix.FloatIdentity(n), false)
+	if err != nil {
+		return nil, err
+	}
+	D, ok := M.(*matrix.FloatMatrix)
+	if !ok {
+		return nil, linalg.NewError(linalg.ErrType, "OperatorMatrix: not a float operator")
+	}
+	return D, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
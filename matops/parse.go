@@ -0,0 +1,133 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"strconv"
+	"strings"
+)
+
+/*
+ Parse matrix from a MATLAB-like literal.
+
+ Rows are separated by semicolons or newlines and elements by spaces or
+ commas; enclosing brackets are optional.  Elements are decimal numbers,
+ Inf and NaN, or complex numbers written without spaces such as 1+2i,
+ -0.5e-3-1i, 2i or 3j.  The result is a float matrix unless some element
+ has an imaginary part, in which case it is a complex matrix.  An empty
+ literal gives a 0 by 0 float matrix.
+
+	A, err := matops.Parse("[1 2; 3 4]")
+	z, err := matops.Parse("1+2i 0; 0 1-2i")
+
+*/
+func Parse(s string) (matrix.Matrix, error) {
+	return parseLiteral(s, false)
+}
+
+// Parse literal as complex matrix if wantComplex or some element is complex.
+func parseLiteral(s string, wantComplex bool) (matrix.Matrix, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		s = s[1 : len(s)-1]
+	}
+	var rows [][]complex128
+	isComplex := wantComplex
+	for _, line := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == '\n' }) {
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t' || r == '\r'
+		})
+		if len(fields) == 0 {
+			continue
+		}
+		row := make([]complex128, len(fields))
+		for k, f := range fields {
+			v, cplx, err := parseElement(f)
+			if err != nil {
+				return nil, err
+			}
+			row[k] = v
+			isComplex = isComplex || cplx
+		}
+		if len(rows) > 0 && len(row) != len(rows[0]) {
+			return nil, linalg.NewError(linalg.ErrShape,
+				fmt.Sprintf("Parse: row %d has %d elements, expected %d", len(rows)+1, len(row), len(rows[0])))
+		}
+		rows = append(rows, row)
+	}
+	m, n := len(rows), 0
+	if m > 0 {
+		n = len(rows[0])
+	}
+	if isComplex {
+		A := matrix.ComplexZeros(m, n)
+		for i, row := range rows {
+			for j, v := range row {
+				A.SetAt(i, j, v)
+			}
+		}
+		return A, nil
+	}
+	A := matrix.FloatZeros(m, n)
+	for i, row := range rows {
+		for j, v := range row {
+			A.SetAt(i, j, real(v))
+		}
+	}
+	return A, nil
+}
+
+// Parse element; report whether it was written as a complex number.
+func parseElement(s string) (complex128, bool, error) {
+	if v, err := strconv.ParseFloat(s, 64); err == nil {
+		return complex(v, 0), false, nil
+	}
+	if strings.HasSuffix(s, "j") {
+		s = s[:len(s)-1] + "i"
+	}
+	if strings.HasSuffix(s, "i") {
+		if z, err := strconv.ParseComplex(s, 128); err == nil {
+			return z, true, nil
+		}
+	}
+	return 0, false, linalg.NewError(linalg.ErrParameter, "Parse: invalid element "+strconv.Quote(s))
+}
+
+// Like Parse but panics on error. For tests and literals known to be valid.
+func MustParse(s string) matrix.Matrix {
+	A, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return A
+}
+
+// Parse float matrix; panics on error or if the literal has complex
+// elements.
+func MustParseFloat(s string) *matrix.FloatMatrix {
+	A, ok := MustParse(s).(*matrix.FloatMatrix)
+	if !ok {
+		panic(linalg.NewError(linalg.ErrType, "MustParseFloat: complex literal"))
+	}
+	return A
+}
+
+// Parse complex matrix; float literals are converted to complex.
+func MustParseComplex(s string) *matrix.ComplexMatrix {
+	A, err := parseLiteral(s, true)
+	if err != nil {
+		panic(err)
+	}
+	return A.(*matrix.ComplexMatrix)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
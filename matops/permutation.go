@@ -0,0 +1,129 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+)
+
+// Permutation of n items stored as an index vector: position i of the
+// permuted sequence holds item P[i] of the original one. Applied to the rows
+// of A, row i of P*A is row P[i] of A.
+type Permutation []int
+
+// Return identity permutation of n items.
+func IdentityPermutation(n int) Permutation {
+	p := make(Permutation, n)
+	for i := range p {
+		p[i] = i
+	}
+	return p
+}
+
+// Return permutation P with P*A = L*U from pivot vector ipiv of an m row
+// matrix as computed by Getrf, Gesv or Gbtrf. Pivot indexes are one-based
+// row interchanges applied in order (LAPACK convention).
+func PivotPermutation(ipiv []int32, m int) (Permutation, error) {
+	p := IdentityPermutation(m)
+	for i, r := range ipiv {
+		k := int(r) - 1
+		if i >= m || k < 0 || k >= m {
+			return nil, linalg.NewError(linalg.ErrParameter,
+				fmt.Sprintf("PivotPermutation: ipiv[%d] = %d out of range", i, r))
+		}
+		p[i], p[k] = p[k], p[i]
+	}
+	return p, nil
+}
+
+// Return LAPACK style one-based row interchange vector equivalent to P;
+// the inverse of PivotPermutation.
+func (P Permutation) Pivots() []int32 {
+	n := len(P)
+	cur := IdentityPermutation(n)
+	pos := IdentityPermutation(n)
+	ipiv := make([]int32, n)
+	for i := 0; i < n; i++ {
+		k := pos[P[i]]
+		ipiv[i] = int32(k + 1)
+		// swap items at positions i and k
+		cur[i], cur[k] = cur[k], cur[i]
+		pos[cur[i]], pos[cur[k]] = i, k
+	}
+	return ipiv
+}
+
+// Number of items.
+func (P Permutation) Len() int {
+	return len(P)
+}
+
+// Check that P is a permutation of 0, ..., len(P)-1.
+func (P Permutation) Check() error {
+	seen := make([]bool, len(P))
+	for i, k := range P {
+		if k < 0 || k >= len(P) || seen[k] {
+			return linalg.NewError(linalg.ErrParameter,
+				fmt.Sprintf("Permutation: invalid index %d at position %d", k, i))
+		}
+		seen[k] = true
+	}
+	return nil
+}
+
+// Return inverse permutation Q with Q*P = P*Q = I.
+func (P Permutation) Inverse() Permutation {
+	q := make(Permutation, len(P))
+	for i, k := range P {
+		q[k] = i
+	}
+	return q
+}
+
+// Return P*Q, the permutation that applies Q first and then P. Both must
+// have the same length.
+func (P Permutation) Compose(Q Permutation) Permutation {
+	r := make(Permutation, len(P))
+	for i, k := range P {
+		r[i] = Q[k]
+	}
+	return r
+}
+
+// Return P*A as a new matrix.
+func (P Permutation) ApplyRows(A matrix.Matrix) (matrix.Matrix, error) {
+	if len(P) != A.Rows() {
+		return nil, linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("ApplyRows: permutation of %d, matrix has %d rows", len(P), A.Rows()))
+	}
+	return SelectRows(A, P)
+}
+
+// Return A*P^T as a new matrix; column j of the result is column P[j] of A.
+func (P Permutation) ApplyCols(A matrix.Matrix) (matrix.Matrix, error) {
+	if len(P) != A.Cols() {
+		return nil, linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("ApplyCols: permutation of %d, matrix has %d columns", len(P), A.Cols()))
+	}
+	return SelectCols(A, P)
+}
+
+// Return P as a dense permutation matrix.
+func (P Permutation) Matrix() *matrix.FloatMatrix {
+	n := len(P)
+	M := matrix.FloatZeros(n, n)
+	for i, k := range P {
+		M.SetAt(i, k, 1.0)
+	}
+	return M
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,138 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Dense float matrix as a LinearOperator.
+type DenseOperator struct {
+	*matrix.FloatMatrix
+}
+
+// Return new matrix A*X or A^T*X.
+func (A DenseOperator) Apply(X matrix.Matrix, trans bool) (matrix.Matrix, error) {
+	Xf, ok := X.(*matrix.FloatMatrix)
+	if !ok {
+		return nil, linalg.NewError(linalg.ErrType, "DenseOperator: X not a float matrix")
+	}
+	m, n := A.Size()
+	if trans {
+		m, n = n, m
+	}
+	if Xf.Rows() != n {
+		return nil, linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("DenseOperator: X has %d rows, want %d", Xf.Rows(), n))
+	}
+	if trans {
+		return matrix.Times(A.Transpose(), Xf), nil
+	}
+	Y := matrix.FloatZeros(m, Xf.Cols())
+	denseProduct(A.FloatMatrix, Xf, Y)
+	return Y, nil
+}
+
+// Rows of A in a block of denseProduct, sized so that the block of Y and a
+// column segment of A stay in L1 cache.
+const powersRowBlock = 256
+
+// Compute Y := A*X, sweeping over A once per row block in storage order.
+func denseProduct(A, X, Y *matrix.FloatMatrix) {
+	m, n, k := A.Rows(), A.Cols(), X.Cols()
+	a, x, y := A.FloatArray(), X.FloatArray(), Y.FloatArray()
+	lda, ldx, ldy := A.LeadingIndex(), X.LeadingIndex(), Y.LeadingIndex()
+	for i0 := 0; i0 < m; i0 += powersRowBlock {
+		i1 := min(m, i0+powersRowBlock)
+		for j := 0; j < n; j++ {
+			aj := a[j*lda+i0 : j*lda+i1]
+			for c := 0; c < k; c++ {
+				xjc := x[c*ldx+j]
+				if xjc == 0.0 {
+					continue
+				}
+				yc := y[c*ldy+i0 : c*ldy+i1]
+				for i, v := range aj {
+					yc[i] += v * xjc
+				}
+			}
+		}
+	}
+}
+
+/*
+ Krylov sequence of A, the matrix powers kernel of s-step Krylov methods.
+
+ PURPOSE
+ Returns n by m*(s+1) matrix K = [V, A*V, A^2*V, ..., A^s*V] for the
+ n by m block V of starting vectors, with block k in columns k*m to
+ (k+1)*m-1. A is any square LinearOperator; use DenseOperator for a dense
+ matrix and an operator of its own for a sparse one. Each power is
+ computed from the previous block with a single application of A to all
+ m vectors, so a sparse or remote A is traversed s times in total rather
+ than s*m times, and a DenseOperator streams A through cache in row
+ blocks in storage order.
+
+ With option normalize each column of every block is scaled to unit
+ 2-norm before the next power is computed, which keeps high powers from
+ overflowing or underflowing; the columns then span the same Krylov
+ spaces but are no longer the plain powers. A zero column stays zero.
+
+ OPTIONS
+  normalize  bool; scale columns to unit norm.  Default false.
+*/
+func MatrixPowers(A LinearOperator, V *matrix.FloatMatrix, s int, opts ...linalg.Option) (*matrix.FloatMatrix, error) {
+	m, n := A.Size()
+	if m != n {
+		return nil, linalg.NewError(linalg.ErrShape, "MatrixPowers: A not square")
+	}
+	if V.Rows() != n {
+		return nil, linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("MatrixPowers: V has %d rows, want %d", V.Rows(), n))
+	}
+	if s < 0 {
+		return nil, linalg.NewError(linalg.ErrParameter, "MatrixPowers: negative s")
+	}
+	normalize := linalg.GetBoolOpt("normalize", false, opts...)
+	nv := V.Cols()
+	K := matrix.FloatZeros(n, nv*(s+1))
+	X := V.Copy()
+	for k := 0; k <= s; k++ {
+		if k > 0 {
+			Y, err := A.Apply(X, false)
+			if err != nil {
+				return nil, err
+			}
+			var ok bool
+			if X, ok = Y.(*matrix.FloatMatrix); !ok {
+				return nil, linalg.NewError(linalg.ErrType, "MatrixPowers: not a float operator")
+			}
+		}
+		if normalize {
+			for c := 0; c < nv; c++ {
+				nrm := 0.0
+				for i := 0; i < n; i++ {
+					nrm = math.Hypot(nrm, X.GetAt(i, c))
+				}
+				if nrm > 0.0 {
+					for i := 0; i < n; i++ {
+						X.SetAt(i, c, X.GetAt(i, c)/nrm)
+					}
+				}
+			}
+		}
+		K.SetSubMatrix(0, k*nv, X)
+	}
+	return K, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,168 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"math/cmplx"
+	"math/rand"
+)
+
+// Return new rows*cols matrix with elements uniformly distributed in [0, 1).
+// Elements are drawn from src in column-major order so equally seeded
+// sources give equal matrices.
+func RandUniform(rows, cols int, src rand.Source) *matrix.FloatMatrix {
+	rnd := rand.New(src)
+	A := matrix.FloatZeros(rows, cols)
+	Ar := A.FloatArray()
+	for k := range Ar {
+		Ar[k] = rnd.Float64()
+	}
+	return A
+}
+
+// Return new rows*cols matrix with standard normally distributed elements.
+// See RandUniform.
+func RandNormal(rows, cols int, src rand.Source) *matrix.FloatMatrix {
+	rnd := rand.New(src)
+	A := matrix.FloatZeros(rows, cols)
+	Ar := A.FloatArray()
+	for k := range Ar {
+		Ar[k] = rnd.NormFloat64()
+	}
+	return A
+}
+
+// Return new rows*cols complex matrix with real and imaginary parts
+// independently and uniformly distributed in [0, 1).
+func RandUniformComplex(rows, cols int, src rand.Source) *matrix.ComplexMatrix {
+	rnd := rand.New(src)
+	A := matrix.ComplexZeros(rows, cols)
+	Ar := A.ComplexArray()
+	for k := range Ar {
+		re := rnd.Float64()
+		Ar[k] = complex(re, rnd.Float64())
+	}
+	return A
+}
+
+// Return new rows*cols complex matrix with standard complex normal elements,
+// ie. real and imaginary parts independent normal with variance 1/2 so that
+// E|a_ij|^2 = 1.
+func RandNormalComplex(rows, cols int, src rand.Source) *matrix.ComplexMatrix {
+	rnd := rand.New(src)
+	A := matrix.ComplexZeros(rows, cols)
+	Ar := A.ComplexArray()
+	for k := range Ar {
+		re := rnd.NormFloat64() * math.Sqrt2 / 2
+		Ar[k] = complex(re, rnd.NormFloat64()*math.Sqrt2/2)
+	}
+	return A
+}
+
+// Return Haar distributed random n*n orthogonal matrix. The matrix is the Q
+// factor, normalized to positive diagonal R, of the QR factorization of an
+// n*n standard normal matrix drawn from src.
+func RandOrthogonal(n int, src rand.Source) *matrix.FloatMatrix {
+	Q := RandNormal(n, n, src)
+	q := Q.FloatArray()
+	for j := 0; j < n; j++ {
+		qj := q[j*n : (j+1)*n]
+		// Gram-Schmidt twice is enough for orthogonality to working precision
+		for pass := 0; pass < 2; pass++ {
+			for k := 0; k < j; k++ {
+				qk := q[k*n : (k+1)*n]
+				s := 0.0
+				for i := range qj {
+					s += qk[i] * qj[i]
+				}
+				for i := range qj {
+					qj[i] -= s * qk[i]
+				}
+			}
+		}
+		nrm := 0.0
+		for _, v := range qj {
+			nrm = math.Hypot(nrm, v)
+		}
+		for i := range qj {
+			qj[i] /= nrm
+		}
+	}
+	return Q
+}
+
+// Return Haar distributed random n*n unitary matrix. See RandOrthogonal.
+func RandUnitary(n int, src rand.Source) *matrix.ComplexMatrix {
+	Q := RandNormalComplex(n, n, src)
+	q := Q.ComplexArray()
+	for j := 0; j < n; j++ {
+		qj := q[j*n : (j+1)*n]
+		for pass := 0; pass < 2; pass++ {
+			for k := 0; k < j; k++ {
+				qk := q[k*n : (k+1)*n]
+				var s complex128
+				for i := range qj {
+					s += cmplx.Conj(qk[i]) * qj[i]
+				}
+				for i := range qj {
+					qj[i] -= s * qk[i]
+				}
+			}
+		}
+		nrm := 0.0
+		for _, v := range qj {
+			nrm = math.Hypot(nrm, cmplx.Abs(v))
+		}
+		for i := range qj {
+			qj[i] /= complex(nrm, 0)
+		}
+	}
+	return Q
+}
+
+// Return symmetric matrix Q*diag(d)*Q^T with eigenvalues d, where Q is a
+// Haar distributed random orthogonal matrix drawn from src.
+func RandWithSpectrum(d []float64, src rand.Source) *matrix.FloatMatrix {
+	n := len(d)
+	Q := RandOrthogonal(n, src)
+	A := matrix.FloatZeros(n, n)
+	for j := 0; j < n; j++ {
+		for i := j; i < n; i++ {
+			s := 0.0
+			for k := 0; k < n; k++ {
+				s += Q.GetAt(i, k) * d[k] * Q.GetAt(j, k)
+			}
+			A.SetAt(i, j, s)
+			A.SetAt(j, i, s)
+		}
+	}
+	return A
+}
+
+// Return random n*n symmetric positive definite matrix with 2-norm condition
+// number cond. Eigenvalues are geometrically spaced from 1 down to 1/cond.
+// See RandWithSpectrum.
+func RandSPD(n int, cond float64, src rand.Source) (*matrix.FloatMatrix, error) {
+	if !(cond >= 1.0) || math.IsInf(cond, 1) {
+		return nil, linalg.NewError(linalg.ErrParameter, "RandSPD: condition number not finite and >= 1")
+	}
+	d := make([]float64, n)
+	for k := range d {
+		d[k] = 1.0
+		if n > 1 {
+			d[k] = math.Pow(cond, -float64(k)/float64(n-1))
+		}
+	}
+	return RandWithSpectrum(d, src), nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,95 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+)
+
+// Return elements of A as a column-major vector. If A is stored contiguously,
+// ie. A.LeadingIndex() == A.Rows(), returned slice shares storage with A and
+// no elements are copied.
+func Ravel(A *matrix.FloatMatrix) []float64 {
+	rows, cols := A.Size()
+	lda := A.LeadingIndex()
+	Ar := A.FloatArray()
+	if lda == rows || cols <= 1 {
+		return Ar[:rows*cols]
+	}
+	v := make([]float64, rows*cols)
+	for j := 0; j < cols; j++ {
+		copy(v[j*rows:(j+1)*rows], Ar[j*lda:j*lda+rows])
+	}
+	return v
+}
+
+// Return elements of A as a column-major vector. See function Ravel.
+func RavelComplex(A *matrix.ComplexMatrix) []complex128 {
+	rows, cols := A.Size()
+	lda := A.LeadingIndex()
+	Ar := A.ComplexArray()
+	if lda == rows || cols <= 1 {
+		return Ar[:rows*cols]
+	}
+	v := make([]complex128, rows*cols)
+	for j := 0; j < cols; j++ {
+		copy(v[j*rows:(j+1)*rows], Ar[j*lda:j*lda+rows])
+	}
+	return v
+}
+
+// Return a rows*cols matrix sharing storage with A, with the elements of A
+// in column-major order. A may be float or complex matrix and must be
+// stored contiguously, ie. A.LeadingIndex() == A.Rows(); use ReshapeCopy
+// for other matrices. No elements are copied and changes to one matrix are
+// seen in the other.
+func Reshape(A matrix.Matrix, rows, cols int) (matrix.Matrix, error) {
+	if rows < 0 || cols < 0 {
+		return nil, linalg.NewError(linalg.ErrShape, "Reshape: negative dimension")
+	}
+	if rows*cols != A.Rows()*A.Cols() {
+		return nil, linalg.NewError(linalg.ErrShape, "Reshape: number of elements does not match")
+	}
+	if A.LeadingIndex() != A.Rows() && A.Cols() > 1 {
+		return nil, linalg.NewError(linalg.ErrShape, "Reshape: matrix not contiguous")
+	}
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		return matrix.FloatNew(rows, cols, Ravel(A.(*matrix.FloatMatrix))), nil
+	case *matrix.ComplexMatrix:
+		return matrix.ComplexNew(rows, cols, RavelComplex(A.(*matrix.ComplexMatrix))), nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "Reshape: unknown matrix type")
+}
+
+// Return a new rows*cols matrix with elements of A copied in column-major
+// order. Argument A may be float or complex matrix and rows*cols must
+// equal number of elements in A.
+func ReshapeCopy(A matrix.Matrix, rows, cols int) (matrix.Matrix, error) {
+	if rows < 0 || cols < 0 {
+		return nil, linalg.NewError(linalg.ErrShape, "ReshapeCopy: negative dimension")
+	}
+	if rows*cols != A.Rows()*A.Cols() {
+		return nil, linalg.NewError(linalg.ErrShape, "ReshapeCopy: number of elements does not match")
+	}
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		B := matrix.FloatZeros(rows, cols)
+		copy(B.FloatArray(), Ravel(A.(*matrix.FloatMatrix)))
+		return B, nil
+	case *matrix.ComplexMatrix:
+		B := matrix.ComplexZeros(rows, cols)
+		copy(B.ComplexArray(), RavelComplex(A.(*matrix.ComplexMatrix)))
+		return B, nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "ReshapeCopy: unknown matrix type")
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,126 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"strings"
+)
+
+// Affine transform of the columns of a matrix, Y[i,j] = (X[i,j] -
+// Offset[j]) / Scale[j], as returned by Standardize and NormalizeCols.
+// Keep it to transform new data the same way or to map results back to the
+// original units with Inverse.
+type ColumnTransform struct {
+	Offset []float64
+	Scale  []float64
+}
+
+// Return new matrix with the columns of X transformed.
+func (T *ColumnTransform) Apply(X *matrix.FloatMatrix) (*matrix.FloatMatrix, error) {
+	if X.Cols() != len(T.Scale) {
+		return nil, linalg.NewError(linalg.ErrShape, "ColumnTransform: number of columns")
+	}
+	Y := X.Copy()
+	for j := 0; j < Y.Cols(); j++ {
+		for i := 0; i < Y.Rows(); i++ {
+			Y.SetAt(i, j, (Y.GetAt(i, j)-T.Offset[j])/T.Scale[j])
+		}
+	}
+	return Y, nil
+}
+
+// Return new matrix with the transform of the columns of Y undone.
+func (T *ColumnTransform) Inverse(Y *matrix.FloatMatrix) (*matrix.FloatMatrix, error) {
+	if Y.Cols() != len(T.Scale) {
+		return nil, linalg.NewError(linalg.ErrShape, "ColumnTransform: number of columns")
+	}
+	X := Y.Copy()
+	for j := 0; j < X.Cols(); j++ {
+		for i := 0; i < X.Rows(); i++ {
+			X.SetAt(i, j, X.GetAt(i, j)*T.Scale[j]+T.Offset[j])
+		}
+	}
+	return X, nil
+}
+
+// Return X with each column centered to zero mean and scaled to unit
+// standard deviation, and the transform. The standard deviation is the
+// sample one, with divisor n-1, unless option population is true.
+// Constant columns are only centered.
+//
+// Options:
+//
+//	population  bool; divide by n instead of n-1.  Default false.
+func Standardize(X *matrix.FloatMatrix, opts ...linalg.Option) (*matrix.FloatMatrix, *ColumnTransform, error) {
+	m, n := X.Size()
+	T := &ColumnTransform{make([]float64, n), make([]float64, n)}
+	div := float64(m - 1)
+	if linalg.GetBoolOpt("population", false, opts...) {
+		div = float64(m)
+	}
+	for j := 0; j < n; j++ {
+		// two passes, the mean first, for accuracy
+		mean := 0.0
+		for i := 0; i < m; i++ {
+			mean += X.GetAt(i, j)
+		}
+		if m > 0 {
+			mean /= float64(m)
+		}
+		ssq := 0.0
+		for i := 0; i < m; i++ {
+			d := X.GetAt(i, j) - mean
+			ssq += d * d
+		}
+		T.Offset[j] = mean
+		T.Scale[j] = 1.0
+		if div > 0.0 && ssq > 0.0 {
+			T.Scale[j] = math.Sqrt(ssq / div)
+		}
+	}
+	Y, err := T.Apply(X)
+	return Y, T, err
+}
+
+// Return X with each column scaled to unit norm, and the transform.
+// Norm is "1", "2" or "I" for the maximum absolute value. Zero columns are
+// not scaled.
+func NormalizeCols(X *matrix.FloatMatrix, norm string) (*matrix.FloatMatrix, *ColumnTransform, error) {
+	m, n := X.Size()
+	norm = strings.ToUpper(norm)
+	if norm != "1" && norm != "2" && norm != "I" {
+		return nil, nil, linalg.NewError(linalg.ErrParameter, "NormalizeCols: illegal norm")
+	}
+	T := &ColumnTransform{make([]float64, n), make([]float64, n)}
+	for j := 0; j < n; j++ {
+		s := 0.0
+		for i := 0; i < m; i++ {
+			v := math.Abs(X.GetAt(i, j))
+			switch norm {
+			case "1":
+				s += v
+			case "2":
+				s = math.Hypot(s, v)
+			case "I":
+				s = math.Max(s, v)
+			}
+		}
+		T.Scale[j] = 1.0
+		if s > 0.0 {
+			T.Scale[j] = s
+		}
+	}
+	Y, err := T.Apply(X)
+	return Y, T, err
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
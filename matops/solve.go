@@ -0,0 +1,86 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Solve A*X = B for square A with LU factorization and partial pivoting in
+// pure Go. Arguments are not modified; returns X. Intended for small systems
+// and for platforms without BLAS/LAPACK (eg. js/wasm); use lapack.Gesv
+// otherwise.
+func Solve(A, B *matrix.FloatMatrix) (*matrix.FloatMatrix, error) {
+	n := A.Rows()
+	if A.Cols() != n {
+		return nil, linalg.NewError(linalg.ErrShape, "Solve: A not square")
+	}
+	if B.Rows() != n {
+		return nil, linalg.NewError(linalg.ErrShape, "Solve: dimensions of A and B do not match")
+	}
+	nrhs := B.Cols()
+	lu := Ravel(A.Copy())
+	X := B.Copy()
+	x := Ravel(X)
+	for k := 0; k < n; k++ {
+		p := k
+		for i := k + 1; i < n; i++ {
+			if math.Abs(lu[k*n+i]) > math.Abs(lu[k*n+p]) {
+				p = i
+			}
+		}
+		if lu[k*n+p] == 0 {
+			return nil, linalg.NewError(linalg.ErrSingular, fmt.Sprintf("Solve: zero pivot at column %d", k+1))
+		}
+		if p != k {
+			for j := 0; j < n; j++ {
+				lu[j*n+p], lu[j*n+k] = lu[j*n+k], lu[j*n+p]
+			}
+			for j := 0; j < nrhs; j++ {
+				x[j*n+p], x[j*n+k] = x[j*n+k], x[j*n+p]
+			}
+		}
+		// column k of L
+		for i := k + 1; i < n; i++ {
+			lu[k*n+i] /= lu[k*n+k]
+		}
+		// rank-1 update of trailing matrix, column by column
+		for j := k + 1; j < n; j++ {
+			ukj := lu[j*n+k]
+			if ukj == 0 {
+				continue
+			}
+			for i := k + 1; i < n; i++ {
+				lu[j*n+i] -= lu[k*n+i] * ukj
+			}
+		}
+	}
+	for j := 0; j < nrhs; j++ {
+		xj := x[j*n : (j+1)*n]
+		// forward substitution with unit lower L
+		for k := 0; k < n; k++ {
+			for i := k + 1; i < n; i++ {
+				xj[i] -= lu[k*n+i] * xj[k]
+			}
+		}
+		// back substitution with U
+		for k := n - 1; k >= 0; k-- {
+			xj[k] /= lu[k*n+k]
+			for i := 0; i < k; i++ {
+				xj[i] -= lu[k*n+i] * xj[k]
+			}
+		}
+	}
+	return X, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,193 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+)
+
+// Concatenate matrices horizontally, [A0 A1 ...]. All arguments must have the
+// same number of rows and be of the same type.
+func HStack(mats ...matrix.Matrix) (matrix.Matrix, error) {
+	return BlockFrom([][]matrix.Matrix{mats})
+}
+
+// Concatenate matrices vertically, [A0; A1; ...]. All arguments must have the
+// same number of columns and be of the same type.
+func VStack(mats ...matrix.Matrix) (matrix.Matrix, error) {
+	blocks := make([][]matrix.Matrix, len(mats))
+	for i, m := range mats {
+		blocks[i] = []matrix.Matrix{m}
+	}
+	return BlockFrom(blocks)
+}
+
+// Assemble a block matrix from a table of blocks given in row order.
+// All blocks in a block row must have equal number of rows and all
+// blocks in a block column equal number of columns. A nil entry is
+// a zero block; its size is taken from the other blocks in the same
+// block row and column. Result is allocated once.
+func BlockFrom(blocks [][]matrix.Matrix) (matrix.Matrix, error) {
+	if len(blocks) == 0 {
+		return nil, linalg.NewError(linalg.ErrParameter, "BlockFrom: no blocks")
+	}
+	ncols := len(blocks[0])
+	rowsz := make([]int, len(blocks))
+	colsz := make([]int, ncols)
+	for j := range colsz {
+		colsz[j] = -1
+	}
+	var first matrix.Matrix
+	for i, brow := range blocks {
+		if len(brow) != ncols {
+			return nil, linalg.NewError(linalg.ErrShape, fmt.Sprintf("BlockFrom: block row %d has %d blocks, expected %d",
+				i, len(brow), ncols))
+		}
+		rowsz[i] = -1
+		for j, B := range brow {
+			if B == nil {
+				continue
+			}
+			if first == nil {
+				first = B
+			} else if !matrix.EqualTypes(first, B) {
+				return nil, linalg.NewError(linalg.ErrType, "BlockFrom: blocks not of same type")
+			}
+			if rowsz[i] < 0 {
+				rowsz[i] = B.Rows()
+			} else if rowsz[i] != B.Rows() {
+				return nil, linalg.NewError(linalg.ErrShape, fmt.Sprintf("BlockFrom: block (%d,%d) has %d rows, expected %d",
+					i, j, B.Rows(), rowsz[i]))
+			}
+			if colsz[j] < 0 {
+				colsz[j] = B.Cols()
+			} else if colsz[j] != B.Cols() {
+				return nil, linalg.NewError(linalg.ErrShape, fmt.Sprintf("BlockFrom: block (%d,%d) has %d columns, expected %d",
+					i, j, B.Cols(), colsz[j]))
+			}
+		}
+	}
+	if first == nil {
+		return nil, linalg.NewError(linalg.ErrParameter, "BlockFrom: all blocks nil")
+	}
+	rows, cols := 0, 0
+	for i, n := range rowsz {
+		if n < 0 {
+			return nil, linalg.NewError(linalg.ErrShape, fmt.Sprintf("BlockFrom: size of block row %d unknown", i))
+		}
+		rows += n
+	}
+	for j, n := range colsz {
+		if n < 0 {
+			return nil, linalg.NewError(linalg.ErrShape, fmt.Sprintf("BlockFrom: size of block column %d unknown", j))
+		}
+		cols += n
+	}
+
+	switch first.(type) {
+	case *matrix.FloatMatrix:
+		C := matrix.FloatZeros(rows, cols)
+		r := 0
+		for i, brow := range blocks {
+			c := 0
+			for j, B := range brow {
+				if B != nil {
+					setBlockFloat(C, r, c, B.(*matrix.FloatMatrix))
+				}
+				c += colsz[j]
+			}
+			r += rowsz[i]
+		}
+		return C, nil
+	case *matrix.ComplexMatrix:
+		C := matrix.ComplexZeros(rows, cols)
+		r := 0
+		for i, brow := range blocks {
+			c := 0
+			for j, B := range brow {
+				if B != nil {
+					setBlockComplex(C, r, c, B.(*matrix.ComplexMatrix))
+				}
+				c += colsz[j]
+			}
+			r += rowsz[i]
+		}
+		return C, nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "BlockFrom: unknown matrix type")
+}
+
+// Return block diagonal matrix diag(A0, A1, ...). Blocks need not be square
+// but must be of the same type. Sparse output is not supported; the result
+// is dense.
+func BlockDiag(blocks ...matrix.Matrix) (matrix.Matrix, error) {
+	if len(blocks) == 0 {
+		return nil, linalg.NewError(linalg.ErrParameter, "BlockDiag: no blocks")
+	}
+	for k, B := range blocks {
+		if B == nil {
+			return nil, linalg.NewError(linalg.ErrParameter, fmt.Sprintf("BlockDiag: block %d is nil", k))
+		}
+	}
+	if !matrix.EqualTypes(blocks...) {
+		return nil, linalg.NewError(linalg.ErrType, "BlockDiag: blocks not of same type")
+	}
+	rows, cols := 0, 0
+	for _, B := range blocks {
+		rows += B.Rows()
+		cols += B.Cols()
+	}
+	switch blocks[0].(type) {
+	case *matrix.FloatMatrix:
+		C := matrix.FloatZeros(rows, cols)
+		r, c := 0, 0
+		for _, B := range blocks {
+			setBlockFloat(C, r, c, B.(*matrix.FloatMatrix))
+			r, c = r+B.Rows(), c+B.Cols()
+		}
+		return C, nil
+	case *matrix.ComplexMatrix:
+		C := matrix.ComplexZeros(rows, cols)
+		r, c := 0, 0
+		for _, B := range blocks {
+			setBlockComplex(C, r, c, B.(*matrix.ComplexMatrix))
+			r, c = r+B.Rows(), c+B.Cols()
+		}
+		return C, nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "BlockDiag: unknown matrix type")
+}
+
+// Copy B to C with upper left corner of B at C(row, col).
+func setBlockFloat(C *matrix.FloatMatrix, row, col int, B *matrix.FloatMatrix) {
+	Cr := C.FloatArray()
+	Br := B.FloatArray()
+	ldc := C.LeadingIndex()
+	ldb := B.LeadingIndex()
+	m, n := B.Size()
+	for j := 0; j < n; j++ {
+		copy(Cr[(col+j)*ldc+row:(col+j)*ldc+row+m], Br[j*ldb:j*ldb+m])
+	}
+}
+
+// Copy B to C with upper left corner of B at C(row, col).
+func setBlockComplex(C *matrix.ComplexMatrix, row, col int, B *matrix.ComplexMatrix) {
+	Cr := C.ComplexArray()
+	Br := B.ComplexArray()
+	ldc := C.LeadingIndex()
+	ldb := B.LeadingIndex()
+	m, n := B.Size()
+	for j := 0; j < n; j++ {
+		copy(Cr[(col+j)*ldc+row:(col+j)*ldc+row+m], Br[j*ldb:j*ldb+m])
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
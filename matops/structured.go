@@ -0,0 +1,178 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math/cmplx"
+)
+
+// Return Toeplitz matrix with first column c and first row r. Element r[0]
+// is ignored. If r is nil, the result is the Hermitian (for float c,
+// symmetric) Toeplitz matrix with first row conj(c). Arguments are row or
+// column vectors of same type; result is len(c)*len(r).
+func Toeplitz(c, r matrix.Matrix) (matrix.Matrix, error) {
+	if r == nil {
+		switch c.(type) {
+		case *matrix.ComplexMatrix:
+			cv := complexVector(c.(*matrix.ComplexMatrix))
+			rv := make([]complex128, len(cv))
+			for k := range cv {
+				rv[k] = cmplx.Conj(cv[k])
+			}
+			r = matrix.ComplexVector(rv)
+		default:
+			r = c
+		}
+	}
+	if err := checkVectors("Toeplitz", c, r); err != nil {
+		return nil, err
+	}
+	m, n := c.NumElements(), r.NumElements()
+	// T(i,j) = v[n-1+i-j] with v = [r[n-1], ..., r[1], c[0], ..., c[m-1]]
+	index := func(i, j int) int { return n - 1 + i - j }
+	switch c.(type) {
+	case *matrix.FloatMatrix:
+		cv := floatVector(c.(*matrix.FloatMatrix))
+		rv := floatVector(r.(*matrix.FloatMatrix))
+		v := make([]float64, 0, m+n)
+		for k := n - 1; k > 0; k-- {
+			v = append(v, rv[k])
+		}
+		return fillFloat(m, n, append(v, cv...), index), nil
+	case *matrix.ComplexMatrix:
+		cv := complexVector(c.(*matrix.ComplexMatrix))
+		rv := complexVector(r.(*matrix.ComplexMatrix))
+		v := make([]complex128, 0, m+n)
+		for k := n - 1; k > 0; k-- {
+			v = append(v, rv[k])
+		}
+		return fillComplex(m, n, append(v, cv...), index), nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "Toeplitz: unknown types")
+}
+
+// Return Hankel matrix with first column c and last row r. Element r[0] is
+// ignored. If r is nil, the result is square with zeros below the
+// anti-diagonal. Result is len(c)*len(r).
+func Hankel(c, r matrix.Matrix) (matrix.Matrix, error) {
+	if r == nil {
+		switch c.(type) {
+		case *matrix.ComplexMatrix:
+			r = matrix.ComplexZeros(c.NumElements(), 1)
+		default:
+			r = matrix.FloatZeros(c.NumElements(), 1)
+		}
+	}
+	if err := checkVectors("Hankel", c, r); err != nil {
+		return nil, err
+	}
+	m, n := c.NumElements(), r.NumElements()
+	// H(i,j) = v[i+j] with v = [c[0], ..., c[m-1], r[1], ..., r[n-1]]
+	index := func(i, j int) int { return i + j }
+	switch c.(type) {
+	case *matrix.FloatMatrix:
+		v := append([]float64{}, floatVector(c.(*matrix.FloatMatrix))...)
+		if rv := floatVector(r.(*matrix.FloatMatrix)); n > 1 {
+			v = append(v, rv[1:]...)
+		}
+		return fillFloat(m, n, v, index), nil
+	case *matrix.ComplexMatrix:
+		v := append([]complex128{}, complexVector(c.(*matrix.ComplexMatrix))...)
+		if rv := complexVector(r.(*matrix.ComplexMatrix)); n > 1 {
+			v = append(v, rv[1:]...)
+		}
+		return fillComplex(m, n, v, index), nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "Hankel: unknown types")
+}
+
+// Return n*n circulant matrix with first column c, ie. C(i,j) = c[(i-j) mod n].
+func Circulant(c matrix.Matrix) (matrix.Matrix, error) {
+	if err := checkVectors("Circulant", c); err != nil {
+		return nil, err
+	}
+	n := c.NumElements()
+	index := func(i, j int) int { return (i - j + n) % n }
+	switch c.(type) {
+	case *matrix.FloatMatrix:
+		return fillFloat(n, n, floatVector(c.(*matrix.FloatMatrix)), index), nil
+	case *matrix.ComplexMatrix:
+		return fillComplex(n, n, complexVector(c.(*matrix.ComplexMatrix)), index), nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "Circulant: unknown types")
+}
+
+// Check that arguments are row or column vectors of same type.
+func checkVectors(name string, vs ...matrix.Matrix) error {
+	if !matrix.EqualTypes(vs...) {
+		return linalg.NewError(linalg.ErrType, name+": arguments not of same type")
+	}
+	for _, v := range vs {
+		if v.Rows() != 1 && v.Cols() != 1 {
+			return linalg.NewError(linalg.ErrShape, name+": argument not a vector")
+		}
+	}
+	return nil
+}
+
+func fillFloat(m, n int, v []float64, index func(i, j int) int) *matrix.FloatMatrix {
+	A := matrix.FloatZeros(m, n)
+	Ar := A.FloatArray()
+	for j := 0; j < n; j++ {
+		for i := 0; i < m; i++ {
+			Ar[j*m+i] = v[index(i, j)]
+		}
+	}
+	return A
+}
+
+func fillComplex(m, n int, v []complex128, index func(i, j int) int) *matrix.ComplexMatrix {
+	A := matrix.ComplexZeros(m, n)
+	Ar := A.ComplexArray()
+	for j := 0; j < n; j++ {
+		for i := 0; i < m; i++ {
+			Ar[j*m+i] = v[index(i, j)]
+		}
+	}
+	return A
+}
+
+// Return len(x) by degree+1 Vandermonde matrix with rows of powers of x.
+// By default powers decrease from x^degree in the first column to 1 in the
+// last, the coefficient order of polynomial fitting; with option increasing
+// column j holds x^j. Solving V*c = y in least squares sense fits polynomial
+// coefficients c to data (x, y).
+//
+// OPTIONS
+//  increasing  bool; columns in increasing powers. Default false.
+func Vandermonde(x []float64, degree int, opts ...linalg.Option) (*matrix.FloatMatrix, error) {
+	if degree < 0 {
+		return nil, linalg.NewError(linalg.ErrParameter, "Vandermonde: negative degree")
+	}
+	increasing := linalg.GetBoolOpt("increasing", false, opts...)
+	m, n := len(x), degree+1
+	V := matrix.FloatZeros(m, n)
+	Vr := V.FloatArray()
+	for i, xi := range x {
+		p := 1.0
+		for k := 0; k < n; k++ {
+			j := k
+			if !increasing {
+				j = n - 1 - k
+			}
+			Vr[j*m+i] = p
+			p *= xi
+		}
+	}
+	return V, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
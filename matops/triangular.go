@@ -0,0 +1,231 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/matops package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package matops
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math/cmplx"
+)
+
+// Return lower triangle of A on and below the k'th diagonal as a new matrix;
+// other elements are zero. k = 0 is the main diagonal, k > 0 is above and
+// k < 0 below it. A need not be square.
+func Tril(A matrix.Matrix, k int) (matrix.Matrix, error) {
+	return triangle(A, k, true)
+}
+
+// Return upper triangle of A on and above the k'th diagonal as a new matrix;
+// other elements are zero.
+func Triu(A matrix.Matrix, k int) (matrix.Matrix, error) {
+	return triangle(A, k, false)
+}
+
+func triangle(A matrix.Matrix, k int, lower bool) (matrix.Matrix, error) {
+	m, n := A.Size()
+	lda := A.LeadingIndex()
+	// row range [i0, i1) of column j that is kept
+	rows := func(j int) (int, int) {
+		if lower {
+			return max(0, min(m, j-k)), m
+		}
+		return 0, max(0, min(m, j-k+1))
+	}
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		C := matrix.FloatZeros(m, n)
+		Cr := C.FloatArray()
+		for j := 0; j < n; j++ {
+			i0, i1 := rows(j)
+			copy(Cr[j*m+i0:j*m+i1], Ar[j*lda+i0:j*lda+i1])
+		}
+		return C, nil
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		C := matrix.ComplexZeros(m, n)
+		Cr := C.ComplexArray()
+		for j := 0; j < n; j++ {
+			i0, i1 := rows(j)
+			copy(Cr[j*m+i0:j*m+i1], Ar[j*lda+i0:j*lda+i1])
+		}
+		return C, nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "unknown matrix type")
+}
+
+// Return triangle uplo (linalg.PLower or linalg.PUpper) of square matrix A in
+// BLAS packed storage as a column vector with n*(n+1)/2 elements, ready for
+// Tpmv, Spmv and the other packed routines.
+func Pack(A matrix.Matrix, uplo int) (matrix.Matrix, error) {
+	n := A.Rows()
+	if err := checkUplo("Pack", A, uplo); err != nil {
+		return nil, err
+	}
+	lda := A.LeadingIndex()
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		ap := make([]float64, 0, n*(n+1)/2)
+		for j := 0; j < n; j++ {
+			if uplo == linalg.PLower {
+				ap = append(ap, Ar[j*lda+j:j*lda+n]...)
+			} else {
+				ap = append(ap, Ar[j*lda:j*lda+j+1]...)
+			}
+		}
+		return matrix.FloatVector(ap), nil
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		ap := make([]complex128, 0, n*(n+1)/2)
+		for j := 0; j < n; j++ {
+			if uplo == linalg.PLower {
+				ap = append(ap, Ar[j*lda+j:j*lda+n]...)
+			} else {
+				ap = append(ap, Ar[j*lda:j*lda+j+1]...)
+			}
+		}
+		return matrix.ComplexVector(ap), nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "Pack: unknown matrix type")
+}
+
+// Return n by n triangular matrix from packed vector Ap holding triangle uplo.
+// Elements outside the triangle are zero.
+func Unpack(Ap matrix.Matrix, n, uplo int) (matrix.Matrix, error) {
+	if Ap.NumElements() != n*(n+1)/2 {
+		return nil, linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("Unpack: %d elements, expected %d", Ap.NumElements(), n*(n+1)/2))
+	}
+	if uplo != linalg.PLower && uplo != linalg.PUpper {
+		return nil, linalg.NewError(linalg.ErrParameter, "Unpack: illegal uplo")
+	}
+	switch Ap.(type) {
+	case *matrix.FloatMatrix:
+		ap := floatVector(Ap.(*matrix.FloatMatrix))
+		C := matrix.FloatZeros(n, n)
+		Cr := C.FloatArray()
+		for j := 0; j < n; j++ {
+			if uplo == linalg.PLower {
+				ap = ap[copy(Cr[j*n+j:j*n+n], ap):]
+			} else {
+				ap = ap[copy(Cr[j*n:j*n+j+1], ap):]
+			}
+		}
+		return C, nil
+	case *matrix.ComplexMatrix:
+		ap := complexVector(Ap.(*matrix.ComplexMatrix))
+		C := matrix.ComplexZeros(n, n)
+		Cr := C.ComplexArray()
+		for j := 0; j < n; j++ {
+			if uplo == linalg.PLower {
+				ap = ap[copy(Cr[j*n+j:j*n+n], ap):]
+			} else {
+				ap = ap[copy(Cr[j*n:j*n+j+1], ap):]
+			}
+		}
+		return C, nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "Unpack: unknown matrix type")
+}
+
+// Return symmetric matrix built from triangle uplo of square matrix A, as
+// left by Syrk, Syr2k and Syr with the same uplo option.
+func Symmetrize(A matrix.Matrix, uplo int) (matrix.Matrix, error) {
+	C := A.MakeCopy()
+	if err := SymmetrizeInPlace(C, uplo); err != nil {
+		return nil, err
+	}
+	return C, nil
+}
+
+// Copy triangle uplo of square matrix A to the opposite triangle.
+func SymmetrizeInPlace(A matrix.Matrix, uplo int) error {
+	return symmetrize("Symmetrize", A, uplo, false)
+}
+
+// Make complex matrix A Hermitian by storing the conjugate transpose of
+// triangle uplo to the opposite triangle, as needed after Herk and Her2k.
+// Imaginary parts of the diagonal are set to zero.
+func HermitianInPlace(A *matrix.ComplexMatrix, uplo int) error {
+	return symmetrize("Hermitian", A, uplo, true)
+}
+
+func symmetrize(name string, A matrix.Matrix, uplo int, conj bool) error {
+	if err := checkUplo(name, A, uplo); err != nil {
+		return err
+	}
+	n := A.Rows()
+	lda := A.LeadingIndex()
+	switch A.(type) {
+	case *matrix.FloatMatrix:
+		Ar := A.(*matrix.FloatMatrix).FloatArray()
+		for j := 0; j < n; j++ {
+			for i := j + 1; i < n; i++ {
+				if uplo == linalg.PLower {
+					Ar[i*lda+j] = Ar[j*lda+i]
+				} else {
+					Ar[j*lda+i] = Ar[i*lda+j]
+				}
+			}
+		}
+	case *matrix.ComplexMatrix:
+		Ar := A.(*matrix.ComplexMatrix).ComplexArray()
+		for j := 0; j < n; j++ {
+			if conj {
+				Ar[j*lda+j] = complex(real(Ar[j*lda+j]), 0)
+			}
+			for i := j + 1; i < n; i++ {
+				// (i,j) is below and (j,i) above the diagonal
+				lo, up := j*lda+i, i*lda+j
+				if uplo == linalg.PLower {
+					Ar[up] = Ar[lo]
+					if conj {
+						Ar[up] = cmplx.Conj(Ar[up])
+					}
+				} else {
+					Ar[lo] = Ar[up]
+					if conj {
+						Ar[lo] = cmplx.Conj(Ar[lo])
+					}
+				}
+			}
+		}
+	default:
+		return linalg.NewError(linalg.ErrType, name+": unknown matrix type")
+	}
+	return nil
+}
+
+func checkUplo(name string, A matrix.Matrix, uplo int) error {
+	if A.Rows() != A.Cols() {
+		return linalg.NewError(linalg.ErrShape, name+": A not square")
+	}
+	if uplo != linalg.PLower && uplo != linalg.PUpper {
+		return linalg.NewError(linalg.ErrParameter, name+": illegal uplo")
+	}
+	return nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,142 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package linalg
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// Number of operands of each operation known to EstimateMemory.
+var memoryOps = map[string]int{
+	"gesvd": 2, // m, n
+	"gesdd": 2, // m, n
+	"syevd": 1, // n
+	"syevr": 1, // n
+	"geev":  1, // n
+	"getrf": 2, // m, n
+	"potrf": 1, // n
+	"gels":  3, // m, n, nrhs
+	"lstsq": 3, // m, n, nrhs
+	"eig":   1, // n
+}
+
+// Panel width assumed for blocked LAPACK workspaces.
+const memoryBlock = 64
+
+/*
+ Estimate memory an operation allocates in addition to its arguments.
+
+ Returns bytes of workspace, pivot arrays and internal copies for the
+ operation on arguments of the given dimensions, using the LAPACK
+ workspace formulas for blocked algorithms with panel width 64 and
+ eigenvectors or singular vectors requested. Output matrices supplied by
+ the caller are not included; for drivers that allocate their results,
+ such as "eig" (lapack.Eig) and "lstsq" (lapack.Lstsq), the results are.
+
+ Operations and their dimensions:
+  gesvd, gesdd     m, n
+  syevd, syevr     n
+  geev, eig        n
+  getrf            m, n
+  potrf            n
+  gels, lstsq      m, n, nrhs
+
+*/
+func EstimateMemory(op string, dims ...int) (int64, error) {
+	op = strings.ToLower(op)
+	nd, ok := memoryOps[op]
+	if !ok {
+		return 0, NewError(ErrParameter, "EstimateMemory: unknown operation "+op)
+	}
+	if len(dims) != nd {
+		return 0, NewError(ErrParameter,
+			fmt.Sprintf("EstimateMemory: %s needs %d dimensions", op, nd))
+	}
+	d := make([]int64, nd)
+	for k, v := range dims {
+		if v < 0 {
+			return 0, NewError(ErrParameter, "EstimateMemory: negative dimension")
+		}
+		d[k] = int64(v)
+	}
+	const f, i = 8, 4 // bytes of double and LAPACK integer
+	nb := int64(memoryBlock)
+	var bytes int64
+	switch op {
+	case "gesvd":
+		mn, mx := min(d[0], d[1]), max(d[0], d[1])
+		bytes = f*max(3*mn+mx, 5*mn) + f*nb*(d[0]+d[1])
+	case "gesdd":
+		mn, mx := min(d[0], d[1]), max(d[0], d[1])
+		bytes = f*(3*mn*mn+max(mx, 4*mn*mn+4*mn)) + i*8*mn
+	case "syevd":
+		n := d[0]
+		bytes = f*(1+6*n+2*n*n) + i*(3+5*n)
+	case "syevr":
+		n := d[0]
+		bytes = f*(26+nb)*n + i*12*n
+	case "geev":
+		n := d[0]
+		bytes = f * (4 + nb) * n
+	case "eig":
+		// copy of A, real VL and VR, complex values and vectors, condition
+		n := d[0]
+		bytes = f*(3*n*n+(4+nb)*n+2*n) + 16*(n*n+n) + f*n
+	case "getrf":
+		bytes = i * min(d[0], d[1])
+	case "potrf":
+		bytes = 0
+	case "gels":
+		mn := min(d[0], d[1])
+		bytes = f * (mn + max(mn, d[2])*nb)
+	case "lstsq":
+		// copies of A and B, tau and X
+		m, n, nrhs := d[0], d[1], d[2]
+		bytes = f * (m*n + m*nrhs + n + n*nrhs + n*nb)
+	}
+	return bytes, nil
+}
+
+var memoryBudget int64
+
+// Set memory budget in bytes for operations that check it with
+// CheckMemory, and return the previous budget. Zero or negative disables
+// the checks, which is the default.
+func SetMemoryBudget(bytes int64) int64 {
+	return atomic.SwapInt64(&memoryBudget, bytes)
+}
+
+// Return the memory budget; zero if checks are disabled.
+func MemoryBudget() int64 {
+	return max(0, atomic.LoadInt64(&memoryBudget))
+}
+
+// Return error wrapping ErrMemoryBudget if the estimated memory of op on
+// dims exceeds the budget. The 'membudget' option overrides the global
+// budget for one call. Drivers in package lapack call this before
+// allocating their workspace.
+func CheckMemory(op string, opts []Option, dims ...int) error {
+	budget := int64(GetIntOpt("membudget", int(MemoryBudget()), opts...))
+	if budget <= 0 {
+		return nil
+	}
+	need, err := EstimateMemory(op, dims...)
+	if err != nil {
+		return err
+	}
+	if need > budget {
+		return NewError(ErrMemoryBudget,
+			fmt.Sprintf("%s %v needs about %d bytes, budget %d", op, dims, need, budget))
+	}
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,150 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/nearest package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+// Package nearest solves matrix nearness problems in the Frobenius norm.
+//
+// PSD projects a symmetric matrix onto the cone of positive semidefinite
+// matrices by clipping negative eigenvalues. Correlation computes the nearest
+// correlation matrix (symmetric positive semidefinite with unit diagonal)
+// with the alternating projections method of Higham, N. J., Computing the
+// nearest correlation matrix - a problem from finance, IMA J. Numer. Anal.
+// 22 (2002). Both repair covariance and correlation estimates that have
+// lost definiteness to rounding, missing data or ad-hoc adjustments.
+package nearest
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/lapack"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+/*
+ Return nearest symmetric positive semidefinite matrix to symmetric A.
+
+ The result is Q*max(D, floor)*Q^T where A = Q*D*Q^T is the eigenvalue
+ decomposition of A. Only the lower triangle of A is referenced. A is not
+ modified.
+
+ OPTIONS
+  floor     float; lower bound for eigenvalues of result. Default 0.
+*/
+func PSD(A *matrix.FloatMatrix, opts ...linalg.Option) (*matrix.FloatMatrix, error) {
+	n := A.Rows()
+	if A.Cols() != n {
+		return nil, linalg.NewError(linalg.ErrShape, "PSD: matrix not square")
+	}
+	floor := linalg.GetFloatOpt("floor", 0.0, opts...)
+	if floor < 0.0 || math.IsNaN(floor) {
+		return nil, linalg.NewError(linalg.ErrParameter, "PSD: negative eigenvalue floor")
+	}
+	X := A.Copy()
+	if err := projectPSD(X, floor); err != nil {
+		return nil, err
+	}
+	return X, nil
+}
+
+/*
+ Return nearest correlation matrix to symmetric A.
+
+ Alternates projections onto the positive semidefinite matrices and onto
+ the matrices with unit diagonal, with Dykstra's correction, until the
+ relative change of the iterates is less than tol. The result has unit
+ diagonal and eigenvalues not less than -tol*||A||. Only the lower triangle
+ of A is referenced. A is not modified.
+
+ Returns ErrNoConvergence if tolerance is not reached in maxiter iterations;
+ the last iterate is returned with the error.
+
+ OPTIONS
+  tol       float; relative convergence tolerance. Default 1e-10.
+  maxiter   int; maximum number of iterations. Default 500.
+*/
+func Correlation(A *matrix.FloatMatrix, opts ...linalg.Option) (*matrix.FloatMatrix, error) {
+	n := A.Rows()
+	if A.Cols() != n {
+		return nil, linalg.NewError(linalg.ErrShape, "Correlation: matrix not square")
+	}
+	tol := linalg.GetFloatOpt("tol", 1e-10, opts...)
+	maxiter := linalg.GetIntOpt("maxiter", 500, opts...)
+	if tol <= 0.0 || maxiter < 1 {
+		return nil, linalg.NewError(linalg.ErrParameter, "Correlation: tol or maxiter not positive")
+	}
+	Y := A.Copy()
+	if err := matops.SymmetrizeInPlace(Y, linalg.PLower); err != nil {
+		return nil, err
+	}
+	dS := matrix.FloatZeros(n, n)
+	R := matrix.FloatZeros(n, n)
+	y, ds, r := Y.FloatArray(), dS.FloatArray(), R.FloatArray()
+	for iter := 0; iter < maxiter; iter++ {
+		// R = Y - dS; X = P_S(R); dS = X - R
+		for k := range r {
+			r[k] = y[k] - ds[k]
+		}
+		X := R.Copy()
+		if err := projectPSD(X, 0.0); err != nil {
+			return nil, err
+		}
+		x := X.FloatArray()
+		for k := range ds {
+			ds[k] = x[k] - r[k]
+		}
+		// Y = P_U(X)
+		diff, ynorm := 0.0, 0.0
+		for j := 0; j < n; j++ {
+			for i := 0; i < n; i++ {
+				v := x[j*n+i]
+				if i == j {
+					v = 1.0
+				}
+				diff = math.Hypot(diff, v-y[j*n+i])
+				ynorm = math.Hypot(ynorm, v)
+				y[j*n+i] = v
+			}
+		}
+		if diff <= tol*ynorm {
+			return Y, nil
+		}
+	}
+	return Y, linalg.NewError(linalg.ErrNoConvergence,
+		fmt.Sprintf("Correlation: no convergence in %d iterations", maxiter))
+}
+
+// Replace symmetric X with Q*max(D, floor)*Q^T.
+func projectPSD(X *matrix.FloatMatrix, floor float64) error {
+	n := X.Rows()
+	if n == 0 {
+		return nil
+	}
+	W := matrix.FloatZeros(n, 1)
+	if err := lapack.Syevd(X, W, linalg.OptJobZValue, linalg.OptLower); err != nil {
+		return err
+	}
+	// X holds eigenvectors Q; form lower triangle of Q*D*Q^T
+	Q := X.Copy()
+	d := make([]float64, n)
+	for k := range d {
+		d[k] = math.Max(W.GetIndex(k), floor)
+	}
+	for j := 0; j < n; j++ {
+		for i := j; i < n; i++ {
+			v := 0.0
+			for k := 0; k < n; k++ {
+				v += Q.GetAt(i, k) * d[k] * Q.GetAt(j, k)
+			}
+			X.SetAt(i, j, v)
+		}
+	}
+	return matops.SymmetrizeInPlace(X, linalg.PLower)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
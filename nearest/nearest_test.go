@@ -0,0 +1,65 @@
+package nearest
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/lapack"
+	"github.com/nvcook42/matrix"
+	"math"
+	"testing"
+)
+
+func minEig(t *testing.T, A *matrix.FloatMatrix) float64 {
+	W := matrix.FloatZeros(A.Rows(), 1)
+	if err := lapack.Syevd(A.Copy(), W, linalg.OptLower); err != nil {
+		t.Logf("Syevd: %v\n", err)
+		t.FailNow()
+	}
+	return W.GetIndex(0)
+}
+
+func TestCorrelation(t *testing.T) {
+	// example from Higham (2002), nearest correlation matrix is known
+	A := matrix.FloatMatrixFromTable([][]float64{
+		[]float64{2, -1, 0, 0},
+		[]float64{-1, 2, -1, 0},
+		[]float64{0, -1, 2, -1},
+		[]float64{0, 0, -1, 2}}, matrix.RowOrder)
+	X, err := Correlation(A)
+	if err != nil {
+		t.Logf("Correlation: %v\n", err)
+		t.FailNow()
+	}
+	ref := []float64{1.0, -0.8084, 0.1916, 0.1068, 1.0, -0.6562, 0.1916, 1.0, -0.8084, 1.0}
+	k := 0
+	for j := 0; j < 4; j++ {
+		for i := j; i < 4; i++ {
+			if math.Abs(X.GetAt(i, j)-ref[k]) > 1e-4 || X.GetAt(i, j) != X.GetAt(j, i) {
+				t.Logf("X=\n%v\n", X)
+				t.FailNow()
+			}
+			k++
+		}
+	}
+	if minEig(t, X) < -1e-8 {
+		t.Fail()
+	}
+}
+
+func TestPSD(t *testing.T) {
+	A := matrix.FloatNew(2, 2, []float64{1, 2, 2, 1})
+	X, err := PSD(A)
+	if err != nil {
+		t.FailNow()
+	}
+	// eigenvalues 3 and -1; projection keeps 3*v*v^T with v = (1,1)/sqrt(2)
+	for _, v := range X.FloatArray() {
+		if math.Abs(v-1.5) > 1e-14 {
+			t.Logf("PSD:\n%v\n", X)
+			t.Fail()
+		}
+	}
+	X, _ = PSD(A, linalg.FloatOpt("floor", 0.1))
+	if math.Abs(minEig(t, X)-0.1) > 1e-14 {
+		t.Fail()
+	}
+}
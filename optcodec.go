@@ -0,0 +1,171 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package linalg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Kinds of encoded options.
+const (
+	codecInt     = 'i'
+	codecFloat   = 'f'
+	codecString  = 's'
+	codecBool    = 'b'
+	codecComplex = 'c'
+)
+
+// Longest option name or string value accepted by DecodeOptions.
+const maxCodecString = 1 << 16
+
+/*
+ Encode option list to bytes that DecodeOptions reads back.
+
+ Each option is a kind byte ('i', 'f', 's', 'b' or 'c'), the name as an
+ uvarint length and bytes and the value: a varint for integers, 8 or 16
+ bytes of IEEE 754 bits for floats and complex numbers, an uvarint length
+ and bytes for strings and one byte for booleans. Options of other types
+ are skipped.
+
+ Options received from outside the program, eg. in a service, can be passed
+ around in this form and given to the wrappers after decoding; the wrappers
+ return errors for any values.
+*/
+func EncodeOptions(opts ...Option) []byte {
+	var buf []byte
+	for _, o := range opts {
+		var kind byte
+		switch o.(type) {
+		case *IOpt:
+			kind = codecInt
+		case *FOpt:
+			kind = codecFloat
+		case *SOpt:
+			kind = codecString
+		case *BOpt:
+			kind = codecBool
+		case *COpt:
+			kind = codecComplex
+		default:
+			continue
+		}
+		buf = append(buf, kind)
+		buf = binary.AppendUvarint(buf, uint64(len(o.Name())))
+		buf = append(buf, o.Name()...)
+		switch kind {
+		case codecInt:
+			buf = binary.AppendVarint(buf, int64(o.Int()))
+		case codecFloat:
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(o.Float()))
+		case codecString:
+			buf = binary.AppendUvarint(buf, uint64(len(o.String())))
+			buf = append(buf, o.String()...)
+		case codecBool:
+			if o.Bool() {
+				buf = append(buf, 1)
+			} else {
+				buf = append(buf, 0)
+			}
+		case codecComplex:
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(real(o.Complex())))
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(imag(o.Complex())))
+		}
+	}
+	return buf
+}
+
+// Decode option list encoded by EncodeOptions. Returns an error wrapping
+// ErrParameter for malformed input; any input is safe to decode, and
+// decoding the encoding of the result gives equal options.
+func DecodeOptions(data []byte) ([]Option, error) {
+	var opts []Option
+	d := optDecoder{data: data}
+	for d.pos < len(d.data) {
+		at := d.pos
+		kind := d.data[d.pos]
+		d.pos++
+		name, ok := d.str()
+		if !ok {
+			return nil, d.fail(at)
+		}
+		switch kind {
+		case codecInt:
+			v, n := binary.Varint(d.data[d.pos:])
+			if n <= 0 || v < math.MinInt || v > math.MaxInt {
+				return nil, d.fail(at)
+			}
+			d.pos += n
+			opts = append(opts, IntOpt(name, int(v)))
+		case codecFloat:
+			v, ok := d.float()
+			if !ok {
+				return nil, d.fail(at)
+			}
+			opts = append(opts, FloatOpt(name, v))
+		case codecString:
+			v, ok := d.str()
+			if !ok {
+				return nil, d.fail(at)
+			}
+			opts = append(opts, StringOpt(name, v))
+		case codecBool:
+			if d.pos >= len(d.data) || d.data[d.pos] > 1 {
+				return nil, d.fail(at)
+			}
+			opts = append(opts, BoolOpt(name, d.data[d.pos] == 1))
+			d.pos++
+		case codecComplex:
+			re, ok1 := d.float()
+			im, ok2 := d.float()
+			if !ok1 || !ok2 {
+				return nil, d.fail(at)
+			}
+			opts = append(opts, ComplexOpt(name, complex(re, im)))
+		default:
+			return nil, d.fail(at)
+		}
+	}
+	return opts, nil
+}
+
+// Reader of encoded options.
+type optDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *optDecoder) fail(at int) error {
+	return NewError(ErrParameter, fmt.Sprintf("DecodeOptions: malformed option at byte %d", at))
+}
+
+// Read length prefixed string.
+func (d *optDecoder) str() (string, bool) {
+	n, k := binary.Uvarint(d.data[d.pos:])
+	if k <= 0 || n > maxCodecString || n > uint64(len(d.data)-d.pos-k) {
+		return "", false
+	}
+	d.pos += k
+	s := string(d.data[d.pos : d.pos+int(n)])
+	d.pos += int(n)
+	return s, true
+}
+
+// Read float64 bits.
+func (d *optDecoder) float() (float64, bool) {
+	if len(d.data)-d.pos < 8 {
+		return 0.0, false
+	}
+	v := math.Float64frombits(binary.LittleEndian.Uint64(d.data[d.pos:]))
+	d.pos += 8
+	return v, true
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
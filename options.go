@@ -9,6 +9,7 @@ package linalg
 import (
 	"math"
 	"math/cmplx"
+	"strconv"
 	"strings"
 )
 
@@ -80,7 +81,7 @@ func GetBoolOpt(name string, defval bool, opts ...Option) (val bool) {
 				val = o.Bool()
 			case *SOpt:
 				v := o.String()
-				val = v[0] == 't' || v[0] == 'T' || v[0] == 'y' || v[0] == 'Y'
+				val = len(v) > 0 && (v[0] == 't' || v[0] == 'T' || v[0] == 'y' || v[0] == 'Y')
 			}
 			return
 		}
@@ -158,7 +159,7 @@ func (O *IOpt) Bool() bool {
 }
 
 func (O *IOpt) String() string {
-	return string(O.Val)
+	return strconv.Itoa(O.Val)
 }
 
 func (O *IOpt) Equal(other Option) bool {
@@ -0,0 +1,28 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/plot package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+/*
+Package plot writes matrices and convergence histories in plot-ready form.
+
+Heatmap draws a matrix as ASCII characters or ANSI colored cells on a
+terminal, which is often enough to see the structure of a factor or the
+location of large residuals. HeatmapPNG writes the same map as a PNG image
+with a colormap, for covariance and sparsity patterns too large for a
+terminal. Spy and SpyPNG draw only the nonzero pattern, of band matrices
+from their band storage, to inspect orderings and fill. GnuplotMatrix and
+GnuplotHistory write
+self-contained gnuplot scripts with inline data, and WriteMatrix and
+WriteHistory write the plain data for other tools.
+
+	plot.Heatmap(os.Stdout, A, linalg.BoolOpt("log", true))
+	plot.GnuplotHistory(f, []string{"residual"}, [][]float64{res},
+		linalg.StringOpt("output", "conv.png"))
+*/
+package plot
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,178 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/plot package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package plot
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"io"
+	"math/cmplx"
+	"strconv"
+	"strings"
+)
+
+// Return element accessor for float or complex (by magnitude) matrix.
+func elements(A matrix.Matrix) (func(i, j int) float64, error) {
+	switch X := A.(type) {
+	case *matrix.FloatMatrix:
+		return X.GetAt, nil
+	case *matrix.ComplexMatrix:
+		return func(i, j int) float64 { return cmplx.Abs(X.GetAt(i, j)) }, nil
+	}
+	return nil, linalg.NewError(linalg.ErrType, "unknown matrix type")
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// Write elements of A as whitespace separated rows, the gnuplot
+// "matrix" data format. Complex elements are written by magnitude.
+func WriteMatrix(w io.Writer, A matrix.Matrix) error {
+	at, err := elements(A)
+	if err != nil {
+		return linalg.NewError(linalg.ErrType, "WriteMatrix: unknown matrix type")
+	}
+	out := bufio.NewWriter(w)
+	rows, cols := A.Size()
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			if j > 0 {
+				out.WriteByte(' ')
+			}
+			out.WriteString(formatFloat(at(i, j)))
+		}
+		out.WriteByte('\n')
+	}
+	return out.Flush()
+}
+
+/*
+ Write self-contained gnuplot script plotting A as an image with row 0 at
+ the top. Run with 'gnuplot -p script'.
+
+ OPTIONS
+  title     string; plot title. Default none.
+  output    string; if set, script writes PNG image to named file.
+*/
+func GnuplotMatrix(w io.Writer, A matrix.Matrix, opts ...linalg.Option) error {
+	if _, err := elements(A); err != nil {
+		return linalg.NewError(linalg.ErrType, "GnuplotMatrix: unknown matrix type")
+	}
+	out := bufio.NewWriter(w)
+	header(out, opts...)
+	rows, cols := A.Size()
+	fmt.Fprintf(out, "set xrange [-0.5:%g]\nset yrange [%g:-0.5]\n", float64(cols)-0.5, float64(rows)-0.5)
+	out.WriteString("set palette grey\n$A << EOD\n")
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	if err := WriteMatrix(w, A); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "EOD\nplot $A matrix with image notitle\n")
+	return err
+}
+
+/*
+ Write convergence histories as CSV with a header line of names. Row k
+ holds iteration k followed by the value of each series at k; series
+ shorter than the longest are padded with empty fields.
+*/
+func WriteHistory(w io.Writer, names []string, series [][]float64) error {
+	if len(names) != len(series) {
+		return linalg.NewError(linalg.ErrShape, "WriteHistory: number of names and series differ")
+	}
+	out := bufio.NewWriter(w)
+	out.WriteString("iteration")
+	for _, name := range names {
+		out.WriteString("," + strings.Replace(name, ",", " ", -1))
+	}
+	out.WriteByte('\n')
+	for k := 0; k < longest(series); k++ {
+		out.WriteString(strconv.Itoa(k))
+		for _, s := range series {
+			out.WriteByte(',')
+			if k < len(s) {
+				out.WriteString(formatFloat(s[k]))
+			}
+		}
+		out.WriteByte('\n')
+	}
+	return out.Flush()
+}
+
+/*
+ Write self-contained gnuplot script plotting convergence histories, one
+ line per series, against iteration number.
+
+ OPTIONS
+  title     string; plot title. Default none.
+  output    string; if set, script writes PNG image to named file.
+  logscale  bool; logarithmic y axis. Default true.
+*/
+func GnuplotHistory(w io.Writer, names []string, series [][]float64, opts ...linalg.Option) error {
+	if len(names) != len(series) {
+		return linalg.NewError(linalg.ErrShape, "GnuplotHistory: number of names and series differ")
+	}
+	out := bufio.NewWriter(w)
+	header(out, opts...)
+	if linalg.GetBoolOpt("logscale", true, opts...) {
+		out.WriteString("set logscale y\n")
+	}
+	out.WriteString("set xlabel 'iteration'\nset key top right\n$H << EOD\n")
+	for k := 0; k < longest(series); k++ {
+		out.WriteString(strconv.Itoa(k))
+		for _, s := range series {
+			if k < len(s) {
+				out.WriteString(" " + formatFloat(s[k]))
+			} else {
+				out.WriteString(" NaN")
+			}
+		}
+		out.WriteByte('\n')
+	}
+	out.WriteString("EOD\nplot")
+	for k, name := range names {
+		if k > 0 {
+			out.WriteByte(',')
+		}
+		fmt.Fprintf(out, " $H using 1:%d with lines title %s", k+2, quote(name))
+	}
+	out.WriteByte('\n')
+	return out.Flush()
+}
+
+func header(out *bufio.Writer, opts ...linalg.Option) {
+	if file := linalg.GetStringOpt("output", "", opts...); file != "" {
+		fmt.Fprintf(out, "set terminal pngcairo\nset output %s\n", quote(file))
+	}
+	if title := linalg.GetStringOpt("title", "", opts...); title != "" {
+		fmt.Fprintf(out, "set title %s\n", quote(title))
+	}
+}
+
+// Quote string for gnuplot.
+func quote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+func longest(series [][]float64) int {
+	n := 0
+	for _, s := range series {
+		if len(s) > n {
+			n = len(s)
+		}
+	}
+	return n
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
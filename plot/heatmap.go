@@ -0,0 +1,171 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/plot package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package plot
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"io"
+	"math"
+)
+
+// Characters from lowest to highest value.
+const ramp = " .:-=+*#%@"
+
+/*
+ Write heatmap of A to w, one character per element.
+
+ Elements are mapped linearly from [min, max] to a character ramp or, with
+ option ansi, to 24 shades of the xterm 256 color palette. Complex elements
+ are plotted by magnitude. If the matrix is larger than width columns or
+ height rows, blocks of elements are combined and the element of largest
+ magnitude in each block is plotted. A legend with the value range is
+ written after the map.
+
+ OPTIONS
+  abs      bool; plot |a_ij|. Default false.
+  log      bool; plot log10(|a_ij|), zero elements at the bottom of scale.
+           Default false.
+  min      float; value mapped to bottom of scale. Default smallest element.
+  max      float; value mapped to top of scale. Default largest element.
+  width    int; maximum number of columns, 0 for no limit. Default 80.
+  height   int; maximum number of rows, 0 for no limit. Default 0.
+  ansi     bool; use ANSI background colors. Default false.
+*/
+func Heatmap(w io.Writer, A matrix.Matrix, opts ...linalg.Option) error {
+	width := linalg.GetIntOpt("width", 80, opts...)
+	height := linalg.GetIntOpt("height", 0, opts...)
+	ansi := linalg.GetBoolOpt("ansi", false, opts...)
+	h, err := blockHeat("Heatmap", A, width, height, opts...)
+	if err != nil {
+		return err
+	}
+	rows, cols := A.Size()
+	out := bufio.NewWriter(w)
+	for bi := 0; bi < h.nr; bi++ {
+		for bj := 0; bj < h.nc; bj++ {
+			v := h.cells[bi*h.nc+bj]
+			if ansi {
+				if k := h.level(v, 24); k >= 0 {
+					fmt.Fprintf(out, "\x1b[48;5;%dm ", 232+k)
+				} else {
+					out.WriteString("\x1b[0m?")
+				}
+				continue
+			}
+			if k := h.level(v, len(ramp)); k >= 0 {
+				out.WriteByte(ramp[k])
+			} else {
+				out.WriteByte('?')
+			}
+		}
+		if ansi {
+			out.WriteString("\x1b[0m")
+		}
+		out.WriteByte('\n')
+	}
+	fmt.Fprintf(out, "%dx%d", rows, cols)
+	if h.bw > 1 || h.bh > 1 {
+		fmt.Fprintf(out, " in %dx%d blocks", h.bh, h.bw)
+	}
+	if ansi {
+		fmt.Fprintf(out, ", %s %.3g (black) .. %.3g (white)\n", h.scale, h.lo, h.hi)
+	} else {
+		fmt.Fprintf(out, ", %s %.3g '%c' .. %.3g '%c'\n", h.scale, h.lo, ramp[0], h.hi, ramp[len(ramp)-1])
+	}
+	return out.Flush()
+}
+
+// Matrix reduced to blocks and mapped to a value scale for plotting.
+type heat struct {
+	cells          []float64 // nr by nc, row-major
+	nr, nc, bh, bw int
+	lo, hi         float64
+	scale          string
+}
+
+// Reduce A to blocks of at most width columns and height rows, zero for
+// no limit, plotting the element of largest magnitude or NaN in each block, and
+// find the value range. Handles options abs, log, min and max.
+func blockHeat(name string, A matrix.Matrix, width, height int, opts ...linalg.Option) (*heat, error) {
+	useAbs := linalg.GetBoolOpt("abs", false, opts...)
+	useLog := linalg.GetBoolOpt("log", false, opts...)
+	if width < 0 || height < 0 {
+		return nil, linalg.NewError(linalg.ErrParameter, name+": negative width or height")
+	}
+	at, err := elements(A)
+	if err != nil {
+		return nil, linalg.NewError(linalg.ErrType, name+": unknown matrix type")
+	}
+	value := func(v float64) float64 {
+		if useAbs || useLog {
+			v = math.Abs(v)
+		}
+		if useLog {
+			v = math.Log10(v)
+		}
+		return v
+	}
+	rows, cols := A.Size()
+	h := &heat{bh: 1, bw: 1, scale: "value"}
+	if useLog {
+		h.scale = "log10|value|"
+	} else if useAbs {
+		h.scale = "|value|"
+	}
+	if width > 0 && cols > width {
+		h.bw = (cols + width - 1) / width
+	}
+	if height > 0 && rows > height {
+		h.bh = (rows + height - 1) / height
+	}
+	h.nr, h.nc = (rows+h.bh-1)/h.bh, (cols+h.bw-1)/h.bw
+	h.cells = make([]float64, h.nr*h.nc)
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for bi := 0; bi < h.nr; bi++ {
+		for bj := 0; bj < h.nc; bj++ {
+			c := 0.0
+			for i := bi * h.bh; i < rows && i < (bi+1)*h.bh; i++ {
+				for j := bj * h.bw; j < cols && j < (bj+1)*h.bw; j++ {
+					if v := at(i, j); math.IsNaN(v) || math.Abs(v) >= math.Abs(c) {
+						c = v
+					}
+				}
+			}
+			c = value(c)
+			h.cells[bi*h.nc+bj] = c
+			if !math.IsInf(c, 0) && !math.IsNaN(c) {
+				lo, hi = math.Min(lo, c), math.Max(hi, c)
+			}
+		}
+	}
+	if math.IsInf(lo, 1) {
+		lo, hi = 0.0, 0.0
+	}
+	h.lo = linalg.GetFloatOpt("min", lo, opts...)
+	h.hi = linalg.GetFloatOpt("max", hi, opts...)
+	return h, nil
+}
+
+// Return level 0 to n-1 of v on the value scale, -1 for NaN.
+func (h *heat) level(v float64, n int) int {
+	switch {
+	case math.IsNaN(v):
+		return -1
+	case h.hi <= h.lo || v <= h.lo:
+		return 0
+	case v >= h.hi:
+		return n - 1
+	}
+	return int(float64(n) * (v - h.lo) / (h.hi - h.lo))
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,120 @@
+package plot
+
+import (
+	"bytes"
+	"errors"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestHeatmap(t *testing.T) {
+	A := matrix.FloatNew(2, 3, []float64{0, 9, 1, 2, 4.5, 3})
+	var buf bytes.Buffer
+	if err := Heatmap(&buf, A); err != nil {
+		t.Fail()
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if lines[0] != " .+" || lines[1] != "@:-" {
+		t.Logf("heatmap:\n%s", buf.String())
+		t.Fail()
+	}
+	buf.Reset()
+	B := matrix.FloatZeros(10, 100)
+	B.SetAt(9, 99, 1.0)
+	Heatmap(&buf, B, linalg.IntOpt("width", 10), linalg.IntOpt("height", 5))
+	lines = strings.Split(buf.String(), "\n")
+	if len(lines[0]) != 10 || lines[4] != "         @" || !strings.Contains(lines[5], "2x10 blocks") {
+		t.Logf("blocked heatmap:\n%s", buf.String())
+		t.Fail()
+	}
+	err := Heatmap(&buf, A, linalg.IntOpt("width", -1))
+	if !errors.Is(err, linalg.ErrParameter) {
+		t.Fail()
+	}
+}
+
+func TestGnuplot(t *testing.T) {
+	var buf bytes.Buffer
+	A := matrix.FloatNew(2, 2, []float64{1, 2, 3, 4})
+	GnuplotMatrix(&buf, A, linalg.StringOpt("title", "it's A"))
+	s := buf.String()
+	if !strings.Contains(s, "set title 'it''s A'") || !strings.Contains(s, "1 3\n2 4\nEOD\n") {
+		t.Logf("matrix script:\n%s", s)
+		t.Fail()
+	}
+	buf.Reset()
+	WriteHistory(&buf, []string{"r", "e"}, [][]float64{{1, 0.5, 0.25}, {2}})
+	if buf.String() != "iteration,r,e\n0,1,2\n1,0.5,\n2,0.25,\n" {
+		t.Logf("history:\n%s", buf.String())
+		t.Fail()
+	}
+	err := GnuplotHistory(&buf, []string{"r"}, nil)
+	if !errors.Is(err, linalg.ErrShape) {
+		t.Fail()
+	}
+}
+
+func TestHeatmapPNG(t *testing.T) {
+	A := matrix.FloatNew(2, 2, []float64{0, 1, math.NaN(), 3})
+	var buf bytes.Buffer
+	if err := HeatmapPNG(&buf, A, linalg.IntOpt("scale", 4), linalg.StringOpt("colormap", "gray")); err != nil {
+		t.Fatalf("HeatmapPNG: %v\n", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("decode: %v\n", err)
+	}
+	at := func(x, y int) color.RGBA {
+		return color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+	}
+	// element (0,0) black, (1,1) white, (0,1) NaN
+	if img.Bounds().Dx() != 8 || at(0, 0) != (color.RGBA{0, 0, 0, 255}) ||
+		at(7, 7) != (color.RGBA{255, 255, 255, 255}) || at(5, 1) != nanColor {
+		t.Logf("bounds %v, pixels %v %v %v\n", img.Bounds(), at(0, 0), at(7, 7), at(5, 1))
+		t.Fail()
+	}
+	if err = HeatmapPNG(&buf, A, linalg.StringOpt("colormap", "jet")); !errors.Is(err, linalg.ErrParameter) {
+		t.Fail()
+	}
+}
+
+func TestSpy(t *testing.T) {
+	A := matrix.FloatNew(3, 3, []float64{1, 0, 2, 0, 3, 0, 0, 1e-20, 4})
+	var buf bytes.Buffer
+	if err := Spy(&buf, A, linalg.FloatOpt("tol", 1e-15)); err != nil {
+		t.Fatalf("Spy: %v\n", err)
+	}
+	lines := strings.Split(buf.String(), "\n")
+	if lines[0] != "*  " || lines[1] != " * " || lines[2] != "* *" ||
+		!strings.Contains(lines[3], "nnz 4") || !strings.Contains(lines[3], "bandwidth 2/0") {
+		t.Logf("spy:\n%s", buf.String())
+		t.Fail()
+	}
+	// tridiagonal with a zero superdiagonal element, band storage
+	T := matrix.FloatNew(4, 4, []float64{2, 1, 0, 0, 1, 2, 1, 0, 0, 0, 2, 1, 0, 0, 1, 2})
+	B, err := matops.NewBandMatrix(T, 1, 1)
+	if err != nil {
+		t.Fatalf("NewBandMatrix: %v\n", err)
+	}
+	buf.Reset()
+	Spy(&buf, B)
+	lines = strings.Split(buf.String(), "\n")
+	if lines[0] != "**  " || lines[1] != "**. " || lines[2] != " ***" || lines[3] != "  **" {
+		t.Logf("band spy:\n%s", buf.String())
+		t.Fail()
+	}
+	buf.Reset()
+	if err = SpyPNG(&buf, B, linalg.IntOpt("scale", 2)); err != nil {
+		t.Fatalf("SpyPNG: %v\n", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil || img.Bounds().Dx() != 8 {
+		t.Fail()
+	}
+}
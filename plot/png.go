@@ -0,0 +1,107 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/plot package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package plot
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+)
+
+// Colormaps as evenly spaced anchor colors from lowest to highest value;
+// colors in between are interpolated linearly.
+var colormaps = map[string][]color.RGBA{
+	// matplotlib viridis, perceptually uniform
+	"viridis": {
+		{68, 1, 84, 255}, {72, 40, 120, 255}, {62, 74, 137, 255},
+		{49, 104, 142, 255}, {38, 130, 142, 255}, {31, 158, 137, 255},
+		{53, 183, 121, 255}, {109, 205, 89, 255}, {180, 222, 44, 255},
+		{253, 231, 37, 255}},
+	"gray": {{0, 0, 0, 255}, {255, 255, 255, 255}},
+	// blue-white-red for signed data with min = -max
+	"coolwarm": {{59, 76, 192, 255}, {221, 221, 221, 255}, {180, 4, 38, 255}},
+	// white to black, for sparsity patterns with option abs
+	"binary": {{255, 255, 255, 255}, {0, 0, 0, 255}},
+}
+
+// Color of NaN elements.
+var nanColor = color.RGBA{255, 0, 255, 255}
+
+/*
+ Write heatmap of A to w as a PNG image.
+
+ Each element, or block of elements for matrices larger than width by
+ height, becomes a square of scale by scale pixels colored by its value.
+ Values are mapped linearly from [min, max] to the colormap; NaN elements
+ are magenta. Complex elements are plotted by magnitude. Options abs, log,
+ min and max work as in Heatmap.
+
+ OPTIONS
+  colormap string; "viridis" (default), "gray", "coolwarm" or "binary".
+  width    int; maximum number of columns, 0 for no limit. Default 1024.
+  height   int; maximum number of rows, 0 for no limit. Default 1024.
+  scale    int; pixels per element or block. Default chosen so that the
+           longer side of the image is at least 256 pixels.
+*/
+func HeatmapPNG(w io.Writer, A matrix.Matrix, opts ...linalg.Option) error {
+	cmap, ok := colormaps[strings.ToLower(linalg.GetStringOpt("colormap", "viridis", opts...))]
+	if !ok {
+		return linalg.NewError(linalg.ErrParameter, "HeatmapPNG: unknown colormap")
+	}
+	width := linalg.GetIntOpt("width", 1024, opts...)
+	height := linalg.GetIntOpt("height", 1024, opts...)
+	h, err := blockHeat("HeatmapPNG", A, width, height, opts...)
+	if err != nil {
+		return err
+	}
+	if h.nr == 0 || h.nc == 0 {
+		return linalg.NewError(linalg.ErrShape, "HeatmapPNG: empty matrix")
+	}
+	scale := linalg.GetIntOpt("scale", max(1, 256/max(h.nr, h.nc)), opts...)
+	if scale < 1 {
+		return linalg.NewError(linalg.ErrParameter, "HeatmapPNG: scale must be positive")
+	}
+	// 256 levels per colormap segment is finer than 8 bit output
+	levels := 256 * (len(cmap) - 1)
+	img := image.NewRGBA(image.Rect(0, 0, h.nc*scale, h.nr*scale))
+	for bi := 0; bi < h.nr; bi++ {
+		for bj := 0; bj < h.nc; bj++ {
+			c := nanColor
+			if k := h.level(h.cells[bi*h.nc+bj], levels+1); k >= 0 {
+				c = interpolate(cmap, k, levels)
+			}
+			for y := bi * scale; y < (bi+1)*scale; y++ {
+				for x := bj * scale; x < (bj+1)*scale; x++ {
+					img.SetRGBA(x, y, c)
+				}
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// Return color of level k out of 0..levels on colormap.
+func interpolate(cmap []color.RGBA, k, levels int) color.RGBA {
+	seg := k / 256
+	if seg >= len(cmap)-1 {
+		return cmap[len(cmap)-1]
+	}
+	t := k % 256
+	a, b := cmap[seg], cmap[seg+1]
+	mix := func(x, y uint8) uint8 {
+		return uint8((int(x)*(256-t) + int(y)*t) / 256)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), 255}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,189 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/plot package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package plot
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// Cell states of a sparsity pattern, from empty to nonzero.
+const (
+	spyEmpty   = iota // no stored element
+	spyStored         // stored elements, all zero
+	spyNonzero        // at least one nonzero element
+)
+
+// Characters and colors of the cell states.
+var (
+	spyChars  = [3]byte{' ', '.', '*'}
+	spyColors = [3]color.RGBA{{255, 255, 255, 255}, {210, 210, 210, 255}, {0, 0, 0, 255}}
+)
+
+// Sparsity pattern of a matrix reduced to blocks.
+type spyPattern struct {
+	cells          []byte // nr by nc, row-major
+	nr, nc, bh, bw int
+	rows, cols     int
+	nnz            int
+	kl, ku         int // bandwidths of the nonzero elements
+}
+
+/*
+ Write sparsity pattern of A to w, one character per element.
+
+ Nonzero elements are drawn as '*'. For a *matops.BandMatrix, elements
+ inside the band that are zero are drawn as '.', which shows the room
+ left for fill, and elements outside the band as blank; for other
+ matrices zero elements are blank. If the matrix is larger than width
+ columns or height rows, blocks of elements are combined and a block is
+ drawn by its largest state. A legend after the pattern gives the size,
+ the number of nonzeros and the lower and upper bandwidth of the nonzero
+ elements.
+
+ OPTIONS
+  tol      float; elements with |a_ij| <= tol are zero. Default 0.
+  width    int; maximum number of columns, 0 for no limit. Default 80.
+  height   int; maximum number of rows, 0 for no limit. Default 0.
+*/
+func Spy(w io.Writer, A matrix.Matrix, opts ...linalg.Option) error {
+	width := linalg.GetIntOpt("width", 80, opts...)
+	height := linalg.GetIntOpt("height", 0, opts...)
+	p, err := blockPattern("Spy", A, width, height, opts...)
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(w)
+	for bi := 0; bi < p.nr; bi++ {
+		for bj := 0; bj < p.nc; bj++ {
+			out.WriteByte(spyChars[p.cells[bi*p.nc+bj]])
+		}
+		out.WriteByte('\n')
+	}
+	fmt.Fprintf(out, "%dx%d", p.rows, p.cols)
+	if p.bw > 1 || p.bh > 1 {
+		fmt.Fprintf(out, " in %dx%d blocks", p.bh, p.bw)
+	}
+	density := 0.0
+	if p.rows > 0 && p.cols > 0 {
+		density = 100.0 * float64(p.nnz) / (float64(p.rows) * float64(p.cols))
+	}
+	fmt.Fprintf(out, ", nnz %d (%.3g%%), bandwidth %d/%d\n", p.nnz, density, p.kl, p.ku)
+	return out.Flush()
+}
+
+/*
+ Write sparsity pattern of A to w as a PNG image.
+
+ Nonzero elements, or blocks with a nonzero element, are black squares of
+ scale by scale pixels, stored zero elements of a band matrix are light
+ gray and the rest is white. Option tol is as for Spy.
+
+ OPTIONS
+  width    int; maximum number of columns, 0 for no limit. Default 1024.
+  height   int; maximum number of rows, 0 for no limit. Default 1024.
+  scale    int; pixels per element or block. Default chosen so that the
+           longer side of the image is at least 256 pixels.
+*/
+func SpyPNG(w io.Writer, A matrix.Matrix, opts ...linalg.Option) error {
+	width := linalg.GetIntOpt("width", 1024, opts...)
+	height := linalg.GetIntOpt("height", 1024, opts...)
+	p, err := blockPattern("SpyPNG", A, width, height, opts...)
+	if err != nil {
+		return err
+	}
+	if p.nr == 0 || p.nc == 0 {
+		return linalg.NewError(linalg.ErrShape, "SpyPNG: empty matrix")
+	}
+	scale := linalg.GetIntOpt("scale", max(1, 256/max(p.nr, p.nc)), opts...)
+	if scale < 1 {
+		return linalg.NewError(linalg.ErrParameter, "SpyPNG: scale must be positive")
+	}
+	img := image.NewRGBA(image.Rect(0, 0, p.nc*scale, p.nr*scale))
+	for bi := 0; bi < p.nr; bi++ {
+		for bj := 0; bj < p.nc; bj++ {
+			c := spyColors[p.cells[bi*p.nc+bj]]
+			for y := bi * scale; y < (bi+1)*scale; y++ {
+				for x := bj * scale; x < (bj+1)*scale; x++ {
+					img.SetRGBA(x, y, c)
+				}
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// Reduce pattern of A to blocks of at most width columns and height rows,
+// zero for no limit. Band matrices are read from their band storage.
+func blockPattern(name string, A matrix.Matrix, width, height int, opts ...linalg.Option) (*spyPattern, error) {
+	if width < 0 || height < 0 {
+		return nil, linalg.NewError(linalg.ErrParameter, name+": negative width or height")
+	}
+	tol := linalg.GetFloatOpt("tol", 0.0, opts...)
+	p := &spyPattern{bh: 1, bw: 1}
+	var at func(i, j int) float64
+	// stored reports if element (i, j) is in the storage of A
+	stored := func(i, j int) bool { return true }
+	dense := true
+	if B, ok := A.(*matops.BandMatrix); ok {
+		S, err := elements(B.Matrix)
+		if err != nil {
+			return nil, linalg.NewError(linalg.ErrType, name+": unknown matrix type")
+		}
+		p.rows, p.cols = B.M, B.N
+		at = func(i, j int) float64 { return S(B.Ku+i-j, j) }
+		stored = func(i, j int) bool { return i-j <= B.Kl && j-i <= B.Ku }
+		dense = false
+	} else {
+		var err error
+		if at, err = elements(A); err != nil {
+			return nil, linalg.NewError(linalg.ErrType, name+": unknown matrix type")
+		}
+		p.rows, p.cols = A.Size()
+	}
+	if width > 0 && p.cols > width {
+		p.bw = (p.cols + width - 1) / width
+	}
+	if height > 0 && p.rows > height {
+		p.bh = (p.rows + height - 1) / height
+	}
+	p.nr, p.nc = (p.rows+p.bh-1)/p.bh, (p.cols+p.bw-1)/p.bw
+	p.cells = make([]byte, p.nr*p.nc)
+	for j := 0; j < p.cols; j++ {
+		for i := 0; i < p.rows; i++ {
+			if !stored(i, j) {
+				continue
+			}
+			state := byte(spyStored)
+			if v := at(i, j); !(math.Abs(v) <= tol) {
+				state = spyNonzero
+				p.nnz++
+				p.kl, p.ku = max(p.kl, i-j), max(p.ku, j-i)
+			} else if dense {
+				// zeros of a dense matrix are not drawn
+				continue
+			}
+			c := &p.cells[(i/p.bh)*p.nc+j/p.bw]
+			if state > *c {
+				*c = state
+			}
+		}
+	}
+	return p, nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
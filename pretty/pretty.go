@@ -0,0 +1,201 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/pretty package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+// Configurable printing of float and complex matrices.
+//
+// A Formatter renders a matrix as aligned rows with a chosen number format
+// and elides the middle rows and columns of large matrices, so that a
+// 5000 by 5000 matrix prints as a readable summary instead of megabytes of
+// text.
+//
+//	f := pretty.Formatter{Precision: 3, Verb: 'e', MaxRows: 6, MaxCols: 6}
+//	fmt.Println(f.Format(A))
+//
+// Wrap returns a value implementing fmt.Formatter, so the usual verbs,
+// width and precision apply to every element:
+//
+//	fmt.Printf("%8.2f\n", pretty.Wrap(A))
+//	fmt.Printf("%#v\n", pretty.Wrap(A))   // all rows and columns
+//
+// ToLaTeX and ToMarkdown render matrices as tables for papers and reports.
+package pretty
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Ellipsis shown in place of elided rows and columns.
+const Ellipsis = "..."
+
+// Number format and size limits for printing matrices.
+type Formatter struct {
+	// Verb is 'e', 'f' or 'g' as in strconv.FormatFloat; zero means 'g'.
+	Verb byte
+	// Precision is the number of digits, as in strconv.FormatFloat;
+	// negative means the smallest number necessary to represent the
+	// value exactly. The zero Formatter uses precision zero.
+	Precision int
+	// Width is the minimum width of a column; with zero all columns are
+	// padded to the widest printed element.
+	Width int
+	// MaxRows and MaxCols limit the number of rows and columns printed;
+	// the first and last halves are shown around an ellipsis. Zero means
+	// no limit.
+	MaxRows, MaxCols int
+}
+
+// Formatter used by Wrap; prints at most 10 rows and 8 columns with four
+// significant digits.
+var Default = Formatter{Verb: 'g', Precision: 4, MaxRows: 10, MaxCols: 8}
+
+// Return formatted matrix A, one row per line without trailing newline.
+func (f *Formatter) Format(A matrix.Matrix) string {
+	var b strings.Builder
+	f.Fprint(&b, A)
+	return b.String()
+}
+
+// Write formatted matrix A to w, one row per line without trailing newline.
+func (f *Formatter) Fprint(w io.Writer, A matrix.Matrix) error {
+	cells, _, _ := f.cells(A)
+	width := f.Width
+	for _, row := range cells {
+		for _, s := range row {
+			if len(s) > width && f.Width == 0 {
+				width = len(s)
+			}
+		}
+	}
+	for k, row := range cells {
+		line := make([]string, len(row))
+		for l, s := range row {
+			line[l] = strings.Repeat(" ", max(0, width-len(s))) + s
+		}
+		sep := "\n"
+		if k == len(cells)-1 {
+			sep = ""
+		}
+		if _, err := io.WriteString(w, "["+strings.Join(line, " ")+"]"+sep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Return formatted elements of the rows and columns of A to show and their
+// indexes; elided rows and columns have index -1 and cells Ellipsis.
+func (f *Formatter) cells(A matrix.Matrix) ([][]string, []int, []int) {
+	A = matops.Readable(A)
+	rows := shown(A.Rows(), f.MaxRows)
+	cols := shown(A.Cols(), f.MaxCols)
+	cells := make([][]string, len(rows))
+	for k, i := range rows {
+		cells[k] = make([]string, len(cols))
+		for l, j := range cols {
+			cells[k][l] = Ellipsis
+			if i >= 0 && j >= 0 {
+				cells[k][l] = f.element(A, i, j)
+			}
+		}
+	}
+	return cells, rows, cols
+}
+
+// Return formatted element (i, j) of float or complex matrix A.
+func (f *Formatter) element(A matrix.Matrix, i, j int) string {
+	verb := f.Verb
+	if verb == 0 {
+		verb = 'g'
+	}
+	switch M := A.(type) {
+	case *matrix.FloatMatrix:
+		return strconv.FormatFloat(M.GetAt(i, j), verb, f.Precision, 64)
+	case *matrix.ComplexMatrix:
+		z := M.GetAt(i, j)
+		im := strconv.FormatFloat(imag(z), verb, f.Precision, 64)
+		if im[0] != '-' && im[0] != '+' {
+			im = "+" + im
+		}
+		return strconv.FormatFloat(real(z), verb, f.Precision, 64) + im + "i"
+	}
+	return "?"
+}
+
+// Return indexes of n rows or columns to show with limit; -1 marks the
+// ellipsis.
+func shown(n, limit int) []int {
+	if limit <= 0 || n <= limit {
+		idx := make([]int, n)
+		for k := range idx {
+			idx[k] = k
+		}
+		return idx
+	}
+	head := (limit + 1) / 2
+	tail := limit - head
+	idx := make([]int, 0, limit+1)
+	for k := 0; k < head; k++ {
+		idx = append(idx, k)
+	}
+	idx = append(idx, -1)
+	for k := n - tail; k < n; k++ {
+		idx = append(idx, k)
+	}
+	return idx
+}
+
+// Matrix with a Format method for package fmt.
+type formatted struct {
+	A matrix.Matrix
+	f Formatter
+}
+
+// Return value printing A with formatter f in package fmt.
+func (f *Formatter) Wrap(A matrix.Matrix) fmt.Formatter {
+	return &formatted{A, *f}
+}
+
+// Return value printing A in package fmt. Verbs e, E, f, F, g and G with
+// width and precision apply to each element, v uses Default and the
+// '#' flag turns off eliding of rows and columns.
+func Wrap(A matrix.Matrix) fmt.Formatter {
+	return Default.Wrap(A)
+}
+
+// Implement fmt.Formatter.
+func (m *formatted) Format(s fmt.State, verb rune) {
+	f := m.f
+	switch verb {
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		f.Verb = byte(verb)
+		if verb == 'F' {
+			f.Verb = 'f'
+		}
+	case 'v', 's':
+	default:
+		fmt.Fprintf(s, "%%!%c(matrix)", verb)
+		return
+	}
+	if p, ok := s.Precision(); ok {
+		f.Precision = p
+	}
+	if w, ok := s.Width(); ok {
+		f.Width = w
+	}
+	if s.Flag('#') {
+		f.MaxRows, f.MaxCols = 0, 0
+	}
+	f.Fprint(s, m.A)
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
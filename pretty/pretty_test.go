@@ -0,0 +1,76 @@
+package pretty
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	A := matrix.FloatNew(2, 2, []float64{1, -2.5, 30, 4})
+	f := Formatter{Verb: 'f', Precision: 1}
+	s := f.Format(A)
+	t.Logf("\n%s\n", s)
+	if s != "[ 1.0 30.0]\n[-2.5  4.0]" {
+		t.Fail()
+	}
+	z := matrix.ComplexNew(1, 1, []complex128{complex(1, -2)})
+	if s = f.Format(z); s != "[1.0-2.0i]" {
+		t.Logf("complex: %s\n", s)
+		t.Fail()
+	}
+}
+
+func TestElide(t *testing.T) {
+	A := matrix.FloatZeros(100, 50)
+	A.SetAt(99, 49, 7)
+	s := fmt.Sprintf("%v", Wrap(A))
+	lines := strings.Split(s, "\n")
+	t.Logf("\n%s\n", s)
+	// 10 rows and an ellipsis row, 8 columns and an ellipsis column
+	if len(lines) != 11 || len(strings.Fields(strings.Trim(lines[0], "[]"))) != 9 || !strings.HasSuffix(lines[10], "7]") {
+		t.Fail()
+	}
+	if strings.Count(fmt.Sprintf("%#.1e", Wrap(A)), "\n") != 99 {
+		t.Logf("# flag did not print all rows\n")
+		t.Fail()
+	}
+	if s = fmt.Sprintf("%6.2f", Wrap(matrix.FloatVector([]float64{1}))); s != "[  1.00]" {
+		t.Logf("width: %q\n", s)
+		t.Fail()
+	}
+}
+
+func TestLaTeX(t *testing.T) {
+	A := matrix.FloatNew(2, 2, []float64{1, 1.5e-7, -2, 4})
+	s := ToLaTeX(A, linalg.StringOpt("format", "e"), linalg.IntOpt("precision", 1))
+	t.Logf("\n%s\n", s)
+	want := "\\begin{bmatrix}\n" +
+		"  1.0 \\times 10^{0} & -2.0 \\times 10^{0} \\\\\n" +
+		"  1.5 \\times 10^{-7} & 4.0 \\times 10^{0}\n" +
+		"\\end{bmatrix}"
+	if s != want {
+		t.Fail()
+	}
+	B := matrix.FloatZeros(5, 5)
+	s = ToLaTeX(B, linalg.IntOpt("maxrows", 2), linalg.IntOpt("maxcols", 2), linalg.StringOpt("env", "tabular"))
+	t.Logf("\n%s\n", s)
+	if !strings.Contains(s, `$\ddots$`) || !strings.HasPrefix(s, `\begin{tabular}{rrr}`) {
+		t.Fail()
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	A := matrix.FloatNew(2, 2, []float64{1, 3, 2, 4})
+	s := ToMarkdown(A, linalg.StringOpt("header", "x, y"))
+	t.Logf("\n%s\n", s)
+	if s != "| x | y |\n|---:|---:|\n| 1 | 2 |\n| 3 | 4 |" {
+		t.Fail()
+	}
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
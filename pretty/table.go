@@ -0,0 +1,154 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/pretty package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package pretty
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"strconv"
+	"strings"
+)
+
+// Return formatter for the number format options of ToLaTeX and
+// ToMarkdown.
+func tableFormatter(opts ...linalg.Option) *Formatter {
+	verb := linalg.GetStringOpt("format", "g", opts...)
+	if len(verb) != 1 || !strings.Contains("efg", verb) {
+		verb = "g"
+	}
+	return &Formatter{
+		Verb:      verb[0],
+		Precision: linalg.GetIntOpt("precision", 4, opts...),
+		MaxRows:   linalg.GetIntOpt("maxrows", 0, opts...),
+		MaxCols:   linalg.GetIntOpt("maxcols", 0, opts...),
+	}
+}
+
+/*
+ Render matrix as LaTeX.
+
+ Numbers in exponent form are written as mantissa times a power of ten.
+ Elided rows and columns are shown with \vdots, \cdots and \ddots.
+
+ OPTIONS
+  env       "bmatrix" (default), "pmatrix", "vmatrix", "matrix", "array" or
+            "tabular".  Matrix environments need the amsmath package.
+  format    "e", "f" or "g" (default), as in strconv.FormatFloat
+  precision number of digits, default 4
+  maxrows   maximum number of rows shown, default 0 for all
+  maxcols   maximum number of columns shown, default 0 for all
+
+*/
+func ToLaTeX(A matrix.Matrix, opts ...linalg.Option) string {
+	f := tableFormatter(opts...)
+	env := linalg.GetStringOpt("env", "bmatrix", opts...)
+	cells, rows, cols := f.cells(A)
+	mathMode := env != "tabular"
+	var b strings.Builder
+	switch env {
+	case "array", "tabular":
+		fmt.Fprintf(&b, "\\begin{%s}{%s}\n", env, strings.Repeat("r", len(cols)))
+	default:
+		fmt.Fprintf(&b, "\\begin{%s}\n", env)
+	}
+	for k, row := range cells {
+		line := make([]string, len(row))
+		for l, s := range row {
+			switch {
+			case rows[k] < 0 && cols[l] < 0:
+				s = `\ddots`
+			case rows[k] < 0:
+				s = `\vdots`
+			case cols[l] < 0:
+				s = `\cdots`
+			default:
+				s = latexNumber(s)
+			}
+			if !mathMode {
+				s = "$" + s + "$"
+			}
+			line[l] = s
+		}
+		b.WriteString("  " + strings.Join(line, " & "))
+		if k < len(cells)-1 {
+			b.WriteString(` \\`)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "\\end{%s}", env)
+	return b.String()
+}
+
+// Rewrite exponents of formatted number as powers of ten, eg. 1.5e-07
+// as 1.5 \times 10^{-7}. Complex numbers a+bi are handled part by part.
+func latexNumber(s string) string {
+	if strings.HasSuffix(s, "i") {
+		// split at the sign of the imaginary part, not of an exponent
+		for k := len(s) - 2; k > 0; k-- {
+			if (s[k] == '+' || s[k] == '-') && s[k-1] != 'e' {
+				return latexNumber(s[:k]) + string(s[k]) + latexNumber(s[k+1:len(s)-1]) + "i"
+			}
+		}
+	}
+	k := strings.IndexByte(s, 'e')
+	if k < 0 {
+		return s
+	}
+	exp, err := strconv.Atoi(s[k+1:])
+	if err != nil {
+		return s
+	}
+	return fmt.Sprintf(`%s \times 10^{%d}`, s[:k], exp)
+}
+
+/*
+ Render matrix as a Markdown table.
+
+ Columns are right aligned.  The header row holds the column names or the
+ column indexes.
+
+ OPTIONS
+  header    comma separated column names, default column indexes
+  format    "e", "f" or "g" (default), as in strconv.FormatFloat
+  precision number of digits, default 4
+  maxrows   maximum number of rows shown, default 0 for all
+  maxcols   maximum number of columns shown, default 0 for all
+
+*/
+func ToMarkdown(A matrix.Matrix, opts ...linalg.Option) string {
+	f := tableFormatter(opts...)
+	cells, _, cols := f.cells(A)
+	var names []string
+	if h := linalg.GetStringOpt("header", "", opts...); h != "" {
+		names = strings.Split(h, ",")
+	}
+	header := make([]string, len(cols))
+	rule := make([]string, len(cols))
+	for l, j := range cols {
+		switch {
+		case j < 0:
+			header[l] = Ellipsis
+		case j < len(names):
+			header[l] = strings.TrimSpace(names[j])
+		default:
+			header[l] = strconv.Itoa(j)
+		}
+		rule[l] = "---:"
+	}
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	b.WriteString("|" + strings.Join(rule, "|") + "|")
+	for _, row := range cells {
+		b.WriteString("\n| " + strings.Join(row, " | ") + " |")
+	}
+	return b.String()
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,154 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+package linalg
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+	"reflect"
+	"strconv"
+)
+
+// Kinds of Scalar values.
+const (
+	scalarNone = iota
+	scalarFloat
+	scalarComplex
+)
+
+/*
+ Scalar coefficient of a wrapper, either a float64 or a complex128.
+
+ The zero value is a missing scalar. Scalar has the Float and Complex
+ methods of matrix.Scalar and can be passed where one is expected. The
+ wrappers convert their scalar arguments with ScalarOf and read the value
+ with Real or Cmplx, which do all validation of scalar arguments: a missing
+ or NaN scalar, or a complex scalar given to a real routine, is an error.
+*/
+type Scalar struct {
+	kind int
+	val  complex128
+}
+
+// Float64 value as a Scalar.
+func FloatScalar(v float64) Scalar {
+	return Scalar{kind: scalarFloat, val: complex(v, 0.0)}
+}
+
+// Complex128 value as a Scalar.
+func ComplexScalar(v complex128) Scalar {
+	return Scalar{kind: scalarComplex, val: v}
+}
+
+/*
+ Convert v to a Scalar. Accepted are Scalar, float64, complex128, int and
+ any value with methods Float() float64 and Complex() complex128, as
+ matrix.FScalar and matrix.CScalar. Values of the last kind are complex if
+ their underlying type is complex, as for matrix.CScalar even with a zero
+ imaginary part, and real if it is float. For other underlying types the
+ value is complex if Float returns NaN for a non-NaN Complex. Nil and
+ values of other types give a missing Scalar.
+*/
+func ScalarOf(v interface{}) Scalar {
+	switch s := v.(type) {
+	case Scalar:
+		return s
+	case float64:
+		return FloatScalar(s)
+	case complex128:
+		return ComplexScalar(s)
+	case int:
+		return FloatScalar(float64(s))
+	case interface {
+		Float() float64
+		Complex() complex128
+	}:
+		switch reflect.ValueOf(s).Kind() {
+		case reflect.Float32, reflect.Float64:
+			return FloatScalar(s.Float())
+		case reflect.Complex64, reflect.Complex128:
+			return ComplexScalar(s.Complex())
+		}
+		if z := s.Complex(); math.IsNaN(s.Float()) && !cmplx.IsNaN(z) {
+			return ComplexScalar(z)
+		}
+		return FloatScalar(s.Float())
+	}
+	return Scalar{}
+}
+
+// True if s holds a value.
+func (s Scalar) Valid() bool {
+	return s.kind != scalarNone
+}
+
+// True if s holds a complex128 value.
+func (s Scalar) IsComplex() bool {
+	return s.kind == scalarComplex
+}
+
+// Value of real scalar s; NaN if s is complex or missing.
+func (s Scalar) Float() float64 {
+	if s.kind != scalarFloat {
+		return math.NaN()
+	}
+	return real(s.val)
+}
+
+// Value of s as complex128; NaN if s is missing.
+func (s Scalar) Complex() complex128 {
+	if s.kind == scalarNone {
+		return cmplx.NaN()
+	}
+	return s.val
+}
+
+// Check that s holds a number. Name is the argument name used in the error.
+func (s Scalar) Check(name string) error {
+	switch {
+	case s.kind == scalarNone:
+		return NewError(ErrParameter, name+" missing")
+	case cmplx.IsNaN(s.val):
+		return NewError(ErrParameter, name+" not a number")
+	}
+	return nil
+}
+
+// Value of s for a real routine. Error if s fails Check or is complex.
+func (s Scalar) Real(name string) (float64, error) {
+	if err := s.Check(name); err != nil {
+		return 0.0, err
+	}
+	if s.kind == scalarComplex {
+		return 0.0, NewError(ErrParameter, name+" complex for a real routine")
+	}
+	return real(s.val), nil
+}
+
+// Value of s for a complex routine, real values converted to complex. Error
+// if s fails Check.
+func (s Scalar) Cmplx(name string) (complex128, error) {
+	if err := s.Check(name); err != nil {
+		return 0.0, err
+	}
+	return s.val, nil
+}
+
+func (s Scalar) String() string {
+	switch s.kind {
+	case scalarFloat:
+		return strconv.FormatFloat(real(s.val), 'g', -1, 64)
+	case scalarComplex:
+		return fmt.Sprint(s.val)
+	}
+	return "<missing>"
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,326 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/skew package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+// Package skew implements real skew-symmetric matrices (A^T = -A).
+//
+// A skew-symmetric matrix is stored by its strictly lower triangle in packed
+// column-major order, n*(n-1)/2 elements. Tridiagonalize reduces the matrix
+// to skew-symmetric tridiagonal form T = Q^T*A*Q with Householder
+// reflections applied as rank-2 skew updates, which keep the iterate exactly
+// skew-symmetric. The reduction is the basis of Eigenvalues, which returns
+// the purely imaginary spectrum, and of Pfaffian.
+//
+// The package is pure Go and does not depend on BLAS or LAPACK.
+package skew
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"sort"
+)
+
+// Real skew-symmetric n*n matrix.
+type Matrix struct {
+	n int
+	a []float64 // strictly lower triangle, packed by columns
+}
+
+// Return new n*n zero skew-symmetric matrix.
+func New(n int) *Matrix {
+	if n < 0 {
+		n = 0
+	}
+	return &Matrix{n, make([]float64, n*(n-1)/2)}
+}
+
+/*
+ Return skew-symmetric matrix with elements of A. A must be square with
+ |a_ij + a_ji| <= tol*max|a_ij| and |a_ii| <= tol*max|a_ij| for all i, j;
+ the strictly lower triangle of A is stored.
+
+ OPTIONS
+  tol    float; relative tolerance for skew-symmetry. Default 0.
+*/
+func FromDense(A *matrix.FloatMatrix, opts ...linalg.Option) (*Matrix, error) {
+	n := A.Rows()
+	if A.Cols() != n {
+		return nil, linalg.NewError(linalg.ErrShape, "FromDense: matrix not square")
+	}
+	tol := linalg.GetFloatOpt("tol", 0.0, opts...)
+	amax := 0.0
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			amax = math.Max(amax, math.Abs(A.GetAt(i, j)))
+		}
+	}
+	S := New(n)
+	for j := 0; j < n; j++ {
+		if math.Abs(A.GetAt(j, j)) > tol*amax {
+			return nil, linalg.NewError(linalg.ErrParameter,
+				fmt.Sprintf("FromDense: non-zero diagonal element %d", j))
+		}
+		for i := j + 1; i < n; i++ {
+			if math.Abs(A.GetAt(i, j)+A.GetAt(j, i)) > tol*amax {
+				return nil, linalg.NewError(linalg.ErrParameter,
+					fmt.Sprintf("FromDense: matrix not skew-symmetric at (%d,%d)", i, j))
+			}
+			S.a[S.index(i, j)] = A.GetAt(i, j)
+		}
+	}
+	return S, nil
+}
+
+// Index of element (i, j), i > j, in packed storage.
+func (S *Matrix) index(i, j int) int {
+	return j*(2*S.n-j-1)/2 + i - j - 1
+}
+
+// Return order of matrix.
+func (S *Matrix) Size() int {
+	return S.n
+}
+
+// Return element (i, j).
+func (S *Matrix) At(i, j int) float64 {
+	switch {
+	case i > j:
+		return S.a[S.index(i, j)]
+	case i < j:
+		return -S.a[S.index(j, i)]
+	}
+	return 0.0
+}
+
+// Set element (i, j) to v and element (j, i) to -v. Diagonal elements are
+// always zero and setting them has no effect.
+func (S *Matrix) Set(i, j int, v float64) {
+	switch {
+	case i > j:
+		S.a[S.index(i, j)] = v
+	case i < j:
+		S.a[S.index(j, i)] = -v
+	}
+}
+
+// Return packed strictly lower triangle. The slice shares storage with S.
+func (S *Matrix) Packed() []float64 {
+	return S.a
+}
+
+// Return copy of S.
+func (S *Matrix) Copy() *Matrix {
+	return &Matrix{S.n, append([]float64{}, S.a...)}
+}
+
+// Return S as dense matrix.
+func (S *Matrix) Dense() *matrix.FloatMatrix {
+	A := matrix.FloatZeros(S.n, S.n)
+	for j := 0; j < S.n; j++ {
+		for i := j + 1; i < S.n; i++ {
+			v := S.a[S.index(i, j)]
+			A.SetAt(i, j, v)
+			A.SetAt(j, i, -v)
+		}
+	}
+	return A
+}
+
+/*
+ Reduce S to skew-symmetric tridiagonal form T = Q^T*S*Q.
+
+ Returns subdiagonal e of T, T(k+1,k) = e[k] = -T(k,k+1), and if wantQ is
+ true the orthogonal matrix Q. The second result is -1 if Q is a product of
+ an odd number of reflections (det(Q) = -1) and 1 otherwise. S is not
+ modified.
+*/
+func (S *Matrix) Tridiagonalize(wantQ bool) (e []float64, Q *matrix.FloatMatrix, detQ float64) {
+	n := S.n
+	A := S.Dense()
+	a := A.FloatArray()
+	at := func(i, j int) *float64 { return &a[j*n+i] }
+	detQ = 1.0
+	if wantQ {
+		Q = matrix.FloatIdentity(n)
+	}
+	u := make([]float64, n)
+	w := make([]float64, n)
+	for k := 0; k < n-2; k++ {
+		// Householder vector u, |u|^2 = 2, with (I - u*u^T)*x = alpha*e_1
+		// for x = A(k+1:n, k)
+		xnorm := 0.0
+		for i := k + 2; i < n; i++ {
+			xnorm = math.Hypot(xnorm, *at(i, k))
+		}
+		if xnorm == 0.0 {
+			continue
+		}
+		x0 := *at(k+1, k)
+		alpha := -math.Copysign(math.Hypot(x0, xnorm), x0)
+		unorm := math.Sqrt(alpha * (alpha - x0))
+		u[k+1] = (x0 - alpha) / unorm
+		for i := k + 2; i < n; i++ {
+			u[i] = *at(i, k) / unorm
+		}
+		// H*A*H = A + u*w^T - w*u^T with w = A*u on trailing block
+		for i := k + 1; i < n; i++ {
+			s := 0.0
+			for j := k + 1; j < n; j++ {
+				s += *at(i, j) * u[j]
+			}
+			w[i] = s
+		}
+		for j := k + 1; j < n; j++ {
+			for i := k + 1; i < n; i++ {
+				*at(i, j) += u[i]*w[j] - w[i]*u[j]
+			}
+		}
+		*at(k+1, k) = alpha
+		*at(k, k+1) = -alpha
+		for i := k + 2; i < n; i++ {
+			*at(i, k) = 0.0
+			*at(k, i) = 0.0
+		}
+		detQ = -detQ
+		if wantQ {
+			// Q = Q*H
+			for i := 0; i < n; i++ {
+				s := 0.0
+				for j := k + 1; j < n; j++ {
+					s += Q.GetAt(i, j) * u[j]
+				}
+				for j := k + 1; j < n; j++ {
+					Q.SetAt(i, j, Q.GetAt(i, j)-s*u[j])
+				}
+			}
+		}
+	}
+	if n > 0 {
+		e = make([]float64, n-1)
+	}
+	for k := range e {
+		e[k] = *at(k+1, k)
+	}
+	return
+}
+
+// Return the Pfaffian of S, Pf(S)^2 = det(S). Pfaffian of odd order matrix
+// is zero.
+func (S *Matrix) Pfaffian() float64 {
+	if S.n%2 == 1 {
+		return 0.0
+	}
+	e, _, detQ := S.Tridiagonalize(false)
+	// Pf(S) = det(Q)*Pf(T) and Pf(T) = T(0,1)*T(2,3)*...
+	pf := detQ
+	for k := 0; k < S.n; k += 2 {
+		pf *= -e[k]
+	}
+	return pf
+}
+
+/*
+ Compute eigenvalues of S. Eigenvalues of a real skew-symmetric matrix are
+ purely imaginary and come in conjugate pairs; returns w in ascending order
+ such that the eigenvalues are i*w[k]. For odd n one of w[k] is zero.
+
+ Eigenvalues of the tridiagonal form T are i times the eigenvalues of the
+ real symmetric tridiagonal matrix with zero diagonal and off-diagonal e,
+ to which -i*T is similar by a diagonal unitary scaling; these are computed
+ with implicit QL iteration.
+
+ If the iteration does not converge for some eigenvalue the error is
+ ErrNoConvergence and the partial result is returned: the converged values
+ in ascending order followed by NaN for the others, whose indices
+ linalg.Unconverged returns.
+
+ OPTIONS
+  maxiter  int; QL iterations allowed per eigenvalue. Default 30.
+*/
+func (S *Matrix) Eigenvalues(opts ...linalg.Option) ([]float64, error) {
+	n := S.n
+	maxiter := linalg.GetIntOpt("maxiter", 30, opts...)
+	if maxiter < 1 {
+		return nil, linalg.NewError(linalg.ErrParameter, "Eigenvalues: maxiter < 1")
+	}
+	e, _, _ := S.Tridiagonalize(false)
+	d := make([]float64, n)
+	ee := make([]float64, n)
+	copy(ee, e)
+	l := tqli(d, ee, maxiter)
+	sort.Float64s(d[:l])
+	if l < n {
+		unconverged := make([]int, 0, n-l)
+		for k := l; k < n; k++ {
+			d[k] = math.NaN()
+			unconverged = append(unconverged, k)
+		}
+		return d, linalg.ConvergenceError("Eigenvalues", n-l, unconverged)
+	}
+	return d, nil
+}
+
+// Eigenvalues of symmetric tridiagonal matrix with diagonal d and
+// off-diagonal e[0:n-1] by implicit QL iteration; e[n-1] is workspace.
+// On exit d holds the eigenvalues in no particular order. Returns the
+// number l of converged eigenvalues d[0:l], less than n if some eigenvalue
+// needed more than maxiter iterations.
+func tqli(d, e []float64, maxiter int) int {
+	n := len(d)
+	for l := 0; l < n; l++ {
+		for iter := 0; ; iter++ {
+			m := l
+			for ; m < n-1; m++ {
+				dd := math.Abs(d[m]) + math.Abs(d[m+1])
+				if math.Abs(e[m])+dd == dd {
+					break
+				}
+			}
+			if m == l {
+				break
+			}
+			if iter == maxiter {
+				return l
+			}
+			g := (d[l+1] - d[l]) / (2.0 * e[l])
+			r := math.Hypot(g, 1.0)
+			g = d[m] - d[l] + e[l]/(g+math.Copysign(r, g))
+			s, c, p := 1.0, 1.0, 0.0
+			i := m - 1
+			for ; i >= l; i-- {
+				f := s * e[i]
+				b := c * e[i]
+				r = math.Hypot(f, g)
+				e[i+1] = r
+				if r == 0.0 {
+					// recover from underflow
+					d[i+1] -= p
+					e[m] = 0.0
+					break
+				}
+				s = f / r
+				c = g / r
+				g = d[i+1] - p
+				r = (d[i]-g)*s + 2.0*c*b
+				p = s * r
+				d[i+1] = g + p
+				g = c*r - b
+			}
+			if r == 0.0 && i >= l {
+				continue
+			}
+			d[l] -= p
+			e[l] = g
+			e[m] = 0.0
+		}
+	}
+	return n
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
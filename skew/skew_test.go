@@ -0,0 +1,118 @@
+package skew
+
+import (
+	"errors"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func randSkew(n int, seed int64) *Matrix {
+	G := matops.RandNormal(n, n, rand.NewSource(seed))
+	S := New(n)
+	for j := 0; j < n; j++ {
+		for i := j + 1; i < n; i++ {
+			S.Set(i, j, G.GetAt(i, j))
+		}
+	}
+	return S
+}
+
+func TestStorage(t *testing.T) {
+	S := New(4)
+	S.Set(1, 3, 2.0)
+	S.Set(3, 0, -1.0)
+	S.Set(2, 2, 5.0)
+	A := S.Dense()
+	if A.GetAt(1, 3) != 2.0 || A.GetAt(3, 1) != -2.0 || A.GetAt(0, 3) != 1.0 || A.GetAt(2, 2) != 0.0 {
+		t.Logf("dense:\n%v\n", A)
+		t.Fail()
+	}
+	S2, err := FromDense(A)
+	if err != nil || S2.At(1, 3) != 2.0 {
+		t.Fail()
+	}
+	A.SetAt(0, 1, 1e-14)
+	if _, err = FromDense(A); !errors.Is(err, linalg.ErrParameter) {
+		t.Fail()
+	}
+	if _, err = FromDense(A, linalg.FloatOpt("tol", 1e-12)); err != nil {
+		t.Fail()
+	}
+}
+
+func TestTridiagonalize(t *testing.T) {
+	n := 7
+	S := randSkew(n, 1)
+	e, Q, _ := S.Tridiagonalize(true)
+	T := matrix.FloatZeros(n, n)
+	for k, v := range e {
+		T.SetAt(k+1, k, v)
+		T.SetAt(k, k+1, -v)
+	}
+	D := matrix.Minus(matrix.Times(matrix.Times(Q, T), Q.Transpose()), S.Dense())
+	for _, v := range D.FloatArray() {
+		if math.Abs(v) > 1e-12 {
+			t.Logf("Q*T*Q^T - S: %v\n", v)
+			t.Fail()
+			break
+		}
+	}
+}
+
+func TestEigenPfaffian(t *testing.T) {
+	S := New(2)
+	S.Set(1, 0, -3.0)
+	w, _ := S.Eigenvalues()
+	if math.Abs(w[0]+3.0) > 1e-15 || math.Abs(w[1]-3.0) > 1e-15 || S.Pfaffian() != 3.0 {
+		t.Logf("2x2: w=%v pf=%v\n", w, S.Pfaffian())
+		t.Fail()
+	}
+	// Pf = a01*a23 - a02*a13 + a03*a12
+	S = randSkew(4, 2)
+	pf := S.At(0, 1)*S.At(2, 3) - S.At(0, 2)*S.At(1, 3) + S.At(0, 3)*S.At(1, 2)
+	if math.Abs(S.Pfaffian()-pf) > 1e-12 {
+		t.Logf("Pfaffian %v, want %v\n", S.Pfaffian(), pf)
+		t.Fail()
+	}
+	// |lambda|^2 sums to ||S||_F^2, and eigenvalues pair up
+	S = randSkew(9, 3)
+	w, err := S.Eigenvalues()
+	if err != nil {
+		t.FailNow()
+	}
+	s2, f2 := 0.0, 0.0
+	for k, v := range w {
+		s2 += v * v
+		if math.Abs(v+w[len(w)-1-k]) > 1e-12 {
+			t.Logf("eigenvalues not paired: %v\n", w)
+			t.Fail()
+		}
+	}
+	for _, v := range S.Dense().FloatArray() {
+		f2 += v * v
+	}
+	if math.Abs(s2-f2) > 1e-10 || math.Abs(w[4]) > 1e-12 || S.Pfaffian() != 0.0 {
+		t.Logf("sum w^2 = %v, ||S||^2 = %v, w = %v\n", s2, f2, w)
+		t.Fail()
+	}
+}
+
+func TestEigenvaluesMaxiter(t *testing.T) {
+	S := randSkew(9, 3)
+	w, err := S.Eigenvalues(linalg.IntOpt("maxiter", 1))
+	u := linalg.Unconverged(err)
+	if !errors.Is(err, linalg.ErrNoConvergence) || len(u) == 0 || len(w) != 9 {
+		t.Fatalf("maxiter 1: w=%v err=%v\n", w, err)
+	}
+	t.Logf("w=%v unconverged=%v\n", w, u)
+	for k, v := range w {
+		if math.IsNaN(v) != (k >= u[0]) {
+			t.Logf("NaN at wrong index %d\n", k)
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/spectral package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+// Package spectral computes spectral projectors and invariant subspaces of
+// real matrices for eigenvalues in a region of the complex plane.
+//
+// The projector P onto the invariant subspace of the eigenvalues inside a
+// region, along the subspace of the other eigenvalues, satisfies P*P = P and
+// A*P = P*A. It is computed with the Newton iteration for the matrix sign
+// function, scaled by the determinant, which needs only real LU
+// factorizations and converges quadratically; a disk is first mapped onto
+// the left half-plane with a Moebius transformation. See Higham, Functions
+// of Matrices, ch. 5 (2008). No eigenvalue may lie on the boundary of the
+// region. Regions are symmetric about the real axis, so the projectors of
+// real matrices are real.
+package spectral
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/blas"
+	"github.com/nvcook42/linalg/lapack"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Region kinds.
+const (
+	leftHalfPlane = iota
+	rightHalfPlane
+	disk
+)
+
+// Region of the complex plane symmetric about the real axis.
+type Region struct {
+	kind   int
+	center float64
+	radius float64
+}
+
+// Return half-plane Re(z) < shift.
+func LeftHalfPlane(shift float64) Region {
+	return Region{kind: leftHalfPlane, center: shift}
+}
+
+// Return half-plane Re(z) > shift.
+func RightHalfPlane(shift float64) Region {
+	return Region{kind: rightHalfPlane, center: shift}
+}
+
+// Return open disk |z - center| < radius.
+func Disk(center, radius float64) Region {
+	return Region{kind: disk, center: center, radius: radius}
+}
+
+func (r Region) String() string {
+	switch r.kind {
+	case leftHalfPlane:
+		return fmt.Sprintf("Re(z) < %g", r.center)
+	case rightHalfPlane:
+		return fmt.Sprintf("Re(z) > %g", r.center)
+	}
+	return fmt.Sprintf("|z - %g| < %g", r.center, r.radius)
+}
+
+/*
+ Return spectral projector of square A onto the invariant subspace of the
+ eigenvalues in region. A is not modified.
+
+ Fails with ErrSingular if an eigenvalue is on or numerically too close to
+ the boundary of region, and with ErrNoConvergence if the sign iteration
+ does not converge.
+
+ OPTIONS
+  tol       float; relative change of the sign iterate at convergence.
+            Default 1e-12.
+  maxiter   int; maximum number of Newton iterations. Default 100.
+*/
+func SpectralProjector(A *matrix.FloatMatrix, region Region, opts ...linalg.Option) (*matrix.FloatMatrix, error) {
+	n := A.Rows()
+	if A.Cols() != n {
+		return nil, linalg.NewError(linalg.ErrShape, "SpectralProjector: A not square")
+	}
+	if region.kind == disk && !(region.radius > 0.0) {
+		return nil, linalg.NewError(linalg.ErrParameter, "SpectralProjector: radius not positive")
+	}
+	tol := linalg.GetFloatOpt("tol", 1e-12, opts...)
+	maxiter := linalg.GetIntOpt("maxiter", 100, opts...)
+
+	// B with eigenvalues of A in region mapped to the left half-plane
+	B := shifted(A, region.center)
+	sign := -1.0
+	switch region.kind {
+	case rightHalfPlane:
+		sign = 1.0
+	case disk:
+		// (A - (c+r)*I) * (A - (c-r)*I)^-1
+		D := shifted(A, region.center-region.radius)
+		if _, err := inverse(D); err != nil {
+			return nil, boundaryError(region)
+		}
+		B = shifted(A, region.center+region.radius)
+		W := matrix.FloatZeros(n, n)
+		if err := blas.Gemm(B, D, W, matrix.FScalar(1.0), matrix.FScalar(0.0)); err != nil {
+			return nil, err
+		}
+		B = W
+	}
+	S, err := matrixSign(B, tol, maxiter)
+	if err != nil {
+		if err == errBoundary {
+			return nil, boundaryError(region)
+		}
+		return nil, err
+	}
+	// P = (I + sign*S)/2
+	Sr := S.FloatArray()
+	for k := range Sr {
+		Sr[k] *= 0.5 * sign
+	}
+	for k := 0; k < n; k++ {
+		S.SetAt(k, k, S.GetAt(k, k)+0.5)
+	}
+	return S, nil
+}
+
+/*
+ Return matrix Q with orthonormal columns spanning the invariant subspace
+ of square A belonging to the eigenvalues in region. The dimension of the
+ subspace is the rounded trace of the spectral projector. Options as for
+ SpectralProjector.
+*/
+func InvariantSubspace(A *matrix.FloatMatrix, region Region, opts ...linalg.Option) (*matrix.FloatMatrix, error) {
+	P, err := SpectralProjector(A, region, opts...)
+	if err != nil {
+		return nil, err
+	}
+	n := P.Rows()
+	trace := 0.0
+	for k := 0; k < n; k++ {
+		trace += P.GetAt(k, k)
+	}
+	k := int(math.Floor(trace + 0.5))
+	if k <= 0 {
+		return matrix.FloatZeros(n, 0), nil
+	}
+	// range of P is spanned by its leading left singular vectors
+	s := matrix.FloatZeros(n, 1)
+	U := matrix.FloatZeros(n, n)
+	Vt := matrix.FloatZeros(n, n)
+	if err = lapack.Gesvd(P, s, U, Vt, linalg.OptJobuS, linalg.OptJobvtNo); err != nil {
+		return nil, err
+	}
+	return U.GetSubMatrix(0, 0, n, k), nil
+}
+
+var errBoundary = linalg.NewError(linalg.ErrSingular, "eigenvalue on boundary")
+
+func boundaryError(region Region) error {
+	return linalg.NewError(linalg.ErrSingular,
+		fmt.Sprintf("SpectralProjector: eigenvalue on boundary of %v", region))
+}
+
+// Return A - s*I as a new matrix.
+func shifted(A *matrix.FloatMatrix, s float64) *matrix.FloatMatrix {
+	B := A.Copy()
+	for k := 0; k < B.Rows(); k++ {
+		B.SetAt(k, k, B.GetAt(k, k)-s)
+	}
+	return B
+}
+
+// Invert X in place and return log|det(X)| of the original X.
+func inverse(X *matrix.FloatMatrix) (float64, error) {
+	n := X.Rows()
+	ipiv := make([]int32, n)
+	if err := lapack.Getrf(X, ipiv); err != nil {
+		return 0.0, err
+	}
+	logdet := 0.0
+	for k := 0; k < n; k++ {
+		logdet += math.Log(math.Abs(X.GetAt(k, k)))
+	}
+	return logdet, lapack.Getri(X, ipiv)
+}
+
+// Return sign(B) by the determinant scaled Newton iteration
+// X := (mu*X + (mu*X)^-1)/2.
+func matrixSign(B *matrix.FloatMatrix, tol float64, maxiter int) (*matrix.FloatMatrix, error) {
+	n := B.Rows()
+	X := B.Copy()
+	Xr := X.FloatArray()
+	scale := true
+	for iter := 0; iter < maxiter; iter++ {
+		Y := X.Copy()
+		logdet, err := inverse(Y)
+		if err != nil {
+			return nil, errBoundary
+		}
+		mu := 1.0
+		if scale {
+			mu = math.Exp(-logdet / float64(n))
+		}
+		Yr := Y.FloatArray()
+		diff, norm := 0.0, 0.0
+		for k := range Xr {
+			x := 0.5 * (mu*Xr[k] + Yr[k]/mu)
+			diff += math.Abs(x - Xr[k])
+			norm += math.Abs(x)
+			Xr[k] = x
+		}
+		if diff <= tol*norm {
+			return X, nil
+		}
+		// scaling only helps far from convergence
+		if diff <= 1e-2*norm {
+			scale = false
+		}
+	}
+	return nil, linalg.NewError(linalg.ErrNoConvergence,
+		fmt.Sprintf("SpectralProjector: no convergence in %d iterations", maxiter))
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,57 @@
+package spectral
+
+import (
+	"github.com/nvcook42/matrix"
+	"math"
+	"testing"
+)
+
+func maxAbs(A *matrix.FloatMatrix) float64 {
+	m := 0.0
+	for _, v := range A.FloatArray() {
+		m = math.Max(m, math.Abs(v))
+	}
+	return m
+}
+
+func TestSpectralProjector(t *testing.T) {
+	// upper triangular in column-major order, eigenvalues on the diagonal
+	A := matrix.FloatNew(4, 4, []float64{
+		-2, 0, 0, 0,
+		1, -1, 0, 0,
+		3, 2, 0.5, 0,
+		-1, 4, 1, 3})
+	for _, tc := range []struct {
+		region Region
+		rank   int
+	}{
+		{LeftHalfPlane(0.0), 2},
+		{RightHalfPlane(-1.5), 3},
+		{Disk(0.0, 1.5), 2},
+		{Disk(2.5, 1.0), 1},
+	} {
+		P, err := SpectralProjector(A, tc.region)
+		if err != nil {
+			t.Fatalf("%v: %v\n", tc.region, err)
+		}
+		trace := 0.0
+		for k := 0; k < 4; k++ {
+			trace += P.GetAt(k, k)
+		}
+		PP := matrix.Minus(matrix.Times(P, P), P)
+		AP := matrix.Minus(matrix.Times(A, P), matrix.Times(P, A))
+		if math.Abs(trace-float64(tc.rank)) > 1e-10 || maxAbs(PP) > 1e-10 || maxAbs(AP) > 1e-10 {
+			t.Logf("%v: trace %g, |P^2-P| %g, |AP-PA| %g\n", tc.region, trace, maxAbs(PP), maxAbs(AP))
+			t.Fail()
+		}
+		Q, err := InvariantSubspace(A, tc.region)
+		if err != nil || Q.Cols() != tc.rank {
+			t.Logf("%v: InvariantSubspace %v\n", tc.region, err)
+			t.Fail()
+		}
+	}
+	if _, err := SpectralProjector(A, LeftHalfPlane(-1.0)); err == nil {
+		t.Logf("eigenvalue on boundary accepted\n")
+		t.Fail()
+	}
+}
@@ -0,0 +1,294 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/stochastic package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+// Package stochastic provides helpers for row-stochastic matrices, ie. the
+// transition matrices of finite Markov chains.
+//
+// RowNormalize turns a nonnegative matrix of counts or weights into a
+// transition matrix and IsStochastic and IsIrreducible verify one.
+// Stationary computes the stationary distribution, SpectralGap the gap of
+// the chain from the partial symmetric eigensolver lapack.Syevr and
+// MixingTime the standard mixing time bounds derived from it; see
+// Levin, Peres and Wilmer, Markov Chains and Mixing Times, ch. 12, and
+// Fill, Eigenvalue bounds on convergence to stationarity for nonreversible
+// Markov chains, Ann. Appl. Probab. 1 (1991).
+package stochastic
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/lapack"
+	"github.com/nvcook42/matrix"
+	"math"
+)
+
+// Return copy of nonnegative A with each row scaled to sum to one. Fails with
+// ErrParameter if A has a negative element or a zero row.
+func RowNormalize(A *matrix.FloatMatrix) (*matrix.FloatMatrix, error) {
+	P := A.Copy()
+	for i := 0; i < P.Rows(); i++ {
+		s := 0.0
+		for j := 0; j < P.Cols(); j++ {
+			v := P.GetAt(i, j)
+			if v < 0.0 || math.IsNaN(v) {
+				return nil, linalg.NewError(linalg.ErrParameter, "RowNormalize: negative element")
+			}
+			s += v
+		}
+		if s == 0.0 {
+			return nil, linalg.NewError(linalg.ErrParameter, "RowNormalize: zero row")
+		}
+		for j := 0; j < P.Cols(); j++ {
+			P.SetAt(i, j, P.GetAt(i, j)/s)
+		}
+	}
+	return P, nil
+}
+
+/*
+ Test if P is square, nonnegative and has unit row sums.
+
+ OPTIONS
+  tol       float; allowed deviation of row sums from one. Default 1e-12
+            times the number of columns.
+*/
+func IsStochastic(P *matrix.FloatMatrix, opts ...linalg.Option) bool {
+	n := P.Rows()
+	if P.Cols() != n {
+		return false
+	}
+	tol := linalg.GetFloatOpt("tol", 1e-12*float64(n), opts...)
+	for i := 0; i < n; i++ {
+		s := 0.0
+		for j := 0; j < n; j++ {
+			v := P.GetAt(i, j)
+			if v < 0.0 || math.IsNaN(v) {
+				return false
+			}
+			s += v
+		}
+		if math.Abs(s-1.0) > tol {
+			return false
+		}
+	}
+	return true
+}
+
+// Test if the directed graph of nonzero elements of square P is strongly
+// connected, ie. every state of the chain can be reached from every other.
+func IsIrreducible(P *matrix.FloatMatrix) bool {
+	n := P.Rows()
+	if P.Cols() != n {
+		return false
+	}
+	// all states reachable from state 0 both forward and backward
+	reach := func(forward bool) bool {
+		seen := make([]bool, n)
+		stack := []int{0}
+		seen[0] = true
+		count := 1
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for j := 0; j < n; j++ {
+				v := P.GetAt(i, j)
+				if !forward {
+					v = P.GetAt(j, i)
+				}
+				if v != 0.0 && !seen[j] {
+					seen[j] = true
+					count++
+					stack = append(stack, j)
+				}
+			}
+		}
+		return count == n
+	}
+	return n == 0 || (reach(true) && reach(false))
+}
+
+// Return stationary distribution pi of irreducible stochastic P as a column
+// vector, the unique solution of pi^T*P = pi^T with sum(pi) = 1.
+func Stationary(P *matrix.FloatMatrix) (*matrix.FloatMatrix, error) {
+	if err := check("Stationary", P); err != nil {
+		return nil, err
+	}
+	// (I - P^T + 1*1^T)*pi = 1 is nonsingular for irreducible P
+	n := P.Rows()
+	A := matrix.FloatZeros(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			v := 1.0 - P.GetAt(j, i)
+			if i == j {
+				v += 1.0
+			}
+			A.SetAt(i, j, v)
+		}
+	}
+	pi := matrix.FloatOnes(n, 1)
+	if err := lapack.Gesv(A, pi, make([]int32, n)); err != nil {
+		return nil, err
+	}
+	return pi, nil
+}
+
+/*
+ Return spectral gap of irreducible stochastic P.
+
+ For a reversible chain, ie. pi[i]*P[i,j] = pi[j]*P[j,i], the result is the
+ absolute spectral gap 1 - max(|lambda_2|, |lambda_n|) of P, computed from
+ the symmetric matrix D^(1/2)*P*D^(-1/2) with D = diag(pi). Otherwise the
+ result is the spectral gap 1 - lambda_2 of the multiplicative
+ reversiblization P*P', where P' is the time reversal of P. Only the two
+ largest and the smallest eigenvalue are computed.
+
+ OPTIONS
+  tol       float; tolerance for the stochasticity and reversibility tests.
+            Default 1e-12 times the number of states.
+*/
+func SpectralGap(P *matrix.FloatMatrix, opts ...linalg.Option) (gap float64, reversible bool, err error) {
+	if err = check("SpectralGap", P, opts...); err != nil {
+		return
+	}
+	pi, err := Stationary(P)
+	if err != nil {
+		return
+	}
+	gap, reversible, err = spectralGap(P, pi, opts...)
+	return
+}
+
+func spectralGap(P, pi *matrix.FloatMatrix, opts ...linalg.Option) (float64, bool, error) {
+	n := P.Rows()
+	if n < 2 {
+		return 1.0, true, nil
+	}
+	tol := linalg.GetFloatOpt("tol", 1e-12*float64(n), opts...)
+	reversible := true
+	for i := 0; i < n && reversible; i++ {
+		for j := 0; j < i; j++ {
+			if math.Abs(pi.GetAt(i, 0)*P.GetAt(i, j)-pi.GetAt(j, 0)*P.GetAt(j, i)) > tol {
+				reversible = false
+				break
+			}
+		}
+	}
+	// B = D^(1/2)*P*D^(-1/2) is symmetric iff P is reversible
+	B := matrix.FloatZeros(n, n)
+	for j := 0; j < n; j++ {
+		for i := 0; i < n; i++ {
+			B.SetAt(i, j, math.Sqrt(pi.GetAt(i, 0)/pi.GetAt(j, 0))*P.GetAt(i, j))
+		}
+	}
+	S := B
+	if !reversible {
+		// D^(1/2)*P*P'*D^(-1/2) = B*B^T
+		S = matrix.FloatZeros(n, n)
+		for j := 0; j < n; j++ {
+			for i := j; i < n; i++ {
+				s := 0.0
+				for k := 0; k < n; k++ {
+					s += B.GetAt(i, k) * B.GetAt(j, k)
+				}
+				S.SetAt(i, j, s)
+			}
+		}
+	}
+	top, err := eigenvalues(S, n-1, n)
+	if err != nil {
+		return 0.0, reversible, err
+	}
+	lambda := top[0]
+	if reversible {
+		low, err := eigenvalues(S, 1, 1)
+		if err != nil {
+			return 0.0, reversible, err
+		}
+		lambda = math.Max(math.Abs(lambda), math.Abs(low[0]))
+	}
+	return 1.0 - lambda, reversible, nil
+}
+
+// Return eigenvalues il through iu (1-based, ascending) of symmetric S. Only
+// the lower triangle of S is referenced. S is not modified.
+func eigenvalues(S *matrix.FloatMatrix, il, iu int) ([]float64, error) {
+	n := S.Rows()
+	W := matrix.FloatZeros(n, 1)
+	err := lapack.SyevrFloat(S.Copy(), W, nil, 0.0, nil, []int{il, iu},
+		linalg.OptRangeInt, linalg.OptJobZNo, linalg.OptLower)
+	if err != nil {
+		return nil, err
+	}
+	return W.FloatArray()[:iu-il+1], nil
+}
+
+/*
+ Return lower and upper bounds for the mixing time of irreducible
+ stochastic P, the number of steps after which the distribution of the
+ chain is within total variation distance eps of stationarity from any
+ starting state.
+
+ For a reversible chain with absolute spectral gap g and relaxation time
+ 1/g the bounds are (1/g - 1)*log(1/(2*eps)) and log(1/(eps*pi_min))/g.
+ For a nonreversible chain the upper bound is log(1/(4*eps^2*pi_min))/g
+ with g the gap of P*P' and the lower bound is zero. The bounds are +Inf
+ if the gap is zero, eg. for a periodic reversible chain.
+
+ OPTIONS
+  tol       float; as for SpectralGap.
+*/
+func MixingTime(P *matrix.FloatMatrix, eps float64, opts ...linalg.Option) (lower, upper float64, err error) {
+	if !(eps > 0.0 && eps < 0.5) {
+		err = linalg.NewError(linalg.ErrParameter, "MixingTime: eps not in (0, 1/2)")
+		return
+	}
+	if err = check("MixingTime", P, opts...); err != nil {
+		return
+	}
+	pi, err := Stationary(P)
+	if err != nil {
+		return
+	}
+	gap, reversible, err := spectralGap(P, pi, opts...)
+	if err != nil {
+		return
+	}
+	pimin := math.Inf(1)
+	for _, v := range pi.FloatArray() {
+		pimin = math.Min(pimin, v)
+	}
+	if gap <= 0.0 {
+		upper = math.Inf(1)
+		if reversible {
+			lower = upper
+		}
+		return
+	}
+	if reversible {
+		lower = math.Max(0.0, (1.0/gap-1.0)*math.Log(1.0/(2.0*eps)))
+		upper = math.Log(1.0/(eps*pimin)) / gap
+	} else {
+		upper = math.Log(1.0/(4.0*eps*eps*pimin)) / gap
+	}
+	return
+}
+
+// Check that P is stochastic and irreducible.
+func check(name string, P *matrix.FloatMatrix, opts ...linalg.Option) error {
+	if P.Rows() != P.Cols() {
+		return linalg.NewError(linalg.ErrShape, name+": matrix not square")
+	}
+	if !IsStochastic(P, opts...) {
+		return linalg.NewError(linalg.ErrParameter, name+": matrix not stochastic")
+	}
+	if !IsIrreducible(P) {
+		return linalg.NewError(linalg.ErrParameter, name+": chain not irreducible")
+	}
+	return nil
+}
+
+// Local Variables:
+// tab-width: 4
+// End:
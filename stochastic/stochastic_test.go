@@ -0,0 +1,39 @@
+package stochastic
+
+import (
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/matrix"
+	"math"
+	"testing"
+)
+
+func TestStochastic(t *testing.T) {
+	C := matrix.FloatNew(2, 2, []float64{3, 1, 1, 1})
+	P, err := RowNormalize(C)
+	if err != nil || !IsStochastic(P) || !IsIrreducible(P) {
+		t.Logf("RowNormalize: %v\n%v\n", err, P)
+		t.Fail()
+	}
+	if IsIrreducible(matrix.FloatNew(2, 2, []float64{1, 0, 1, 1})) {
+		t.Logf("reducible chain reported irreducible\n")
+		t.Fail()
+	}
+	// two states, P = [1-a a; b 1-b], lambda_2 = 1-a-b, pi = (b, a)/(a+b)
+	a, b := 0.25, 0.5
+	P = matrix.FloatNew(2, 2, []float64{1 - a, b, a, 1 - b})
+	pi, err := Stationary(P)
+	if err != nil || math.Abs(pi.GetAt(0, 0)-b/(a+b)) > 1e-12 {
+		t.Logf("Stationary: %v\n%v\n", err, pi)
+		t.Fail()
+	}
+	gap, reversible, err := SpectralGap(P)
+	if err != nil || !reversible || math.Abs(gap-(a+b)) > 1e-12 {
+		t.Logf("SpectralGap: %g %v %v\n", gap, reversible, err)
+		t.Fail()
+	}
+	lower, upper, err := MixingTime(P, 0.25, linalg.FloatOpt("tol", 1e-10))
+	if err != nil || lower > upper || upper <= 0.0 {
+		t.Logf("MixingTime: %g %g %v\n", lower, upper, err)
+		t.Fail()
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/wasm package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+/*
+Package wasm provides JavaScript typed-array interop for matrices when
+compiled with GOOS=js GOARCH=wasm.
+
+Packages blas and lapack use cgo and are not available on js/wasm. The pure-Go
+packages (linalg, matops, fixed, gonumadapt) build unchanged; matops.Solve
+provides a pure-Go dense solver for use in place of lapack.Gesv.
+
+Matrix data is exchanged with JavaScript in column-major order. FloatFromArray
+and CopyToArray copy between a Float64Array and a FloatMatrix. Float64ArrayView
+returns a Float64Array aliasing the matrix storage in WebAssembly linear memory;
+the view is valid only until the Go heap grows or the matrix is collected and
+must not be retained across calls into Go.
+
+*/
+package wasm
+
+// Local Variables:
+// tab-width: 4
+// End:
@@ -0,0 +1,82 @@
+// Copyright (c) Harri Rautila, 2013
+
+// This file is part of github.com/nvcook42/linalg/wasm package.
+// It is free software, distributed under the terms of GNU Lesser General Public
+// License Version 3, or any later version. See the COPYING tile included in this archive.
+
+//go:build js && wasm
+// +build js,wasm
+
+package wasm
+
+import (
+	"fmt"
+	"github.com/nvcook42/linalg"
+	"github.com/nvcook42/linalg/matops"
+	"github.com/nvcook42/matrix"
+	"syscall/js"
+	"unsafe"
+)
+
+// Create a new rows*cols matrix from JavaScript Float64Array v holding
+// elements in column-major order.
+func FloatFromArray(v js.Value, rows, cols int) (*matrix.FloatMatrix, error) {
+	if v.Get("length").Int() != rows*cols {
+		return nil, linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("FloatFromArray: array length %d, want %d", v.Get("length").Int(), rows*cols))
+	}
+	A := matrix.FloatZeros(rows, cols)
+	ar := A.FloatArray()
+	js.CopyBytesToGo(float64Bytes(ar), bytesOf(v))
+	return A, nil
+}
+
+// Copy elements of A in column-major order to JavaScript Float64Array v.
+func CopyToArray(A *matrix.FloatMatrix, v js.Value) error {
+	n := A.NumElements()
+	if v.Get("length").Int() != n {
+		return linalg.NewError(linalg.ErrShape,
+			fmt.Sprintf("CopyToArray: array length %d, want %d", v.Get("length").Int(), n))
+	}
+	js.CopyBytesToJS(bytesOf(v), float64Bytes(matops.Ravel(A)))
+	return nil
+}
+
+// Create a new JavaScript Float64Array holding elements of A in column-major order.
+func ToArray(A *matrix.FloatMatrix) js.Value {
+	v := js.Global().Get("Float64Array").New(A.NumElements())
+	CopyToArray(A, v)
+	return v
+}
+
+// Return a Float64Array aliasing storage of A without copying. Argument mem is
+// the WebAssembly.Memory object of the running module (instance.exports.mem).
+// A must be stored contiguously (leading index equal to rows).
+func Float64ArrayView(mem js.Value, A *matrix.FloatMatrix) (js.Value, error) {
+	if A.LeadingIndex() != A.Rows() && A.Cols() > 1 {
+		return js.Undefined(), linalg.NewError(linalg.ErrShape, "Float64ArrayView: matrix not contiguous")
+	}
+	n := A.NumElements()
+	if n == 0 {
+		return js.Global().Get("Float64Array").New(0), nil
+	}
+	ar := A.FloatArray()
+	ptr := uintptr(unsafe.Pointer(&ar[0]))
+	return js.Global().Get("Float64Array").New(mem.Get("buffer"), ptr, n), nil
+}
+
+// Uint8Array over the bytes of typed array v.
+func bytesOf(v js.Value) js.Value {
+	return js.Global().Get("Uint8Array").New(v.Get("buffer"), v.Get("byteOffset"), v.Get("byteLength"))
+}
+
+func float64Bytes(ar []float64) []byte {
+	if len(ar) == 0 {
+		return nil
+	}
+	return unsafe.Slice((*byte)(unsafe.Pointer(&ar[0])), len(ar)*8)
+}
+
+// Local Variables:
+// tab-width: 4
+// End: